@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/stdlib"
@@ -22,12 +23,17 @@ import (
 //   - No simple-query protocol means pgx never checks for
 //     standard_conforming_strings in the server's ParameterStatus map, which
 //     the gateway does not advertise.
-func openDB(dsn string) (*sql.DB, error) {
+//
+// A non-zero connectTimeout overrides any connect_timeout in the DSN.
+func openDB(dsn string, connectTimeout time.Duration) (*sql.DB, error) {
 	config, err := pgx.ParseConfig(dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse DSN: %w", err)
 	}
 	config.DefaultQueryExecMode = pgx.QueryExecModeExec
+	if connectTimeout > 0 {
+		config.ConnectTimeout = connectTimeout
+	}
 
 	// Register a lenient date codec on every new connection so that Nexus
 	// gateway DATE columns sent as timestamp-like strings (e.g. "2026-04-27
@@ -47,6 +53,7 @@ func openDB(dsn string) (*sql.DB, error) {
 // It reads NEXUS_HOST (default "localhost"), NEXUS_PORT (default "5433"),
 // and NEXUS_DATABASE (default "lake") from the environment.
 // The connection is not pinged; the first query will surface any auth errors.
+// DB_CONNECT_TIMEOUT bounds how long that first connection attempt may take.
 func OpenWithCredentials(tenantID, token string) (*PortalDB, error) {
 	host := os.Getenv("NEXUS_HOST")
 	if host == "" {
@@ -62,7 +69,7 @@ func OpenWithCredentials(tenantID, token string) (*PortalDB, error) {
 	}
 	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
 		host, port, tenantID, token, database)
-	sqlDB, err := openDB(dsn)
+	sqlDB, err := openDB(dsn, PoolConfigFromEnv().ConnectTimeout)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open per-request database connection: %w", err)
 	}
@@ -74,7 +81,8 @@ func OpenWithCredentials(tenantID, token string) (*PortalDB, error) {
 // The connection DSN is read from the DATABASE_URL environment variable.
 // If DATABASE_URL is not set, individual NEXUS_HOST, NEXUS_PORT, NEXUS_USER,
 // NEXUS_PASSWORD, and NEXUS_DATABASE variables are used, defaulting to a
-// local Nexus instance on port 5433.
+// local Nexus instance on port 5433. Pool limits and the connect timeout come
+// from PoolConfigFromEnv.
 func Open() (*PortalDB, error) {
 	dsn := os.Getenv("DATABASE_URL")
 	if dsn == "" {
@@ -99,10 +107,12 @@ func Open() (*PortalDB, error) {
 			host, port, user, password, database)
 	}
 
-	sqlDB, err := openDB(dsn)
+	pool := PoolConfigFromEnv()
+	sqlDB, err := openDB(dsn, pool.ConnectTimeout)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
+	pool.apply(sqlDB)
 
 	if err := sqlDB.Ping(); err != nil {
 		return nil, fmt.Errorf("failed to connect to nexus gateway: %w", err)
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE transactions ADD COLUMN reconciled BOOLEAN DEFAULT false;
+
+-- +goose Down
+ALTER TABLE transactions DROP COLUMN reconciled;
@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS closed_periods (
+    id INTEGER NOT NULL,
+    closed_through DATE NOT NULL,
+    notes TEXT,
+    created_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS closed_periods;
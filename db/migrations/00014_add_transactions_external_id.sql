@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE transactions ADD COLUMN external_id TEXT;
+
+-- +goose Down
+ALTER TABLE transactions DROP COLUMN external_id;
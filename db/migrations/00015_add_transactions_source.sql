@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE transactions ADD COLUMN source TEXT;
+
+-- +goose Down
+ALTER TABLE transactions DROP COLUMN source;
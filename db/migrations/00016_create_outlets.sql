@@ -0,0 +1,23 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS outlets (
+    id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+ALTER TABLE payouts ADD COLUMN outlet_id INTEGER;
+
+-- Register each distinct outlet name already used by payouts, ignoring case
+-- as outlet lookups do and keeping the first spelling, then point the payouts
+-- at their outlet. Ids are left to the gateway, as for every other insert.
+INSERT INTO outlets (name, created_at, updated_at)
+SELECT MIN(TRIM(outlet_name)), CURRENT_TIMESTAMP, CURRENT_TIMESTAMP
+FROM payouts WHERE TRIM(outlet_name) <> ''
+GROUP BY LOWER(TRIM(outlet_name));
+
+UPDATE payouts SET outlet_id = (SELECT MIN(o.id) FROM outlets o WHERE LOWER(o.name) = LOWER(TRIM(payouts.outlet_name)));
+
+-- +goose Down
+ALTER TABLE payouts DROP COLUMN outlet_id;
+DROP TABLE IF EXISTS outlets;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE transaction_documents ADD COLUMN note TEXT;
+
+-- +goose Down
+ALTER TABLE transaction_documents DROP COLUMN note;
@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE accounts ADD COLUMN currency TEXT;
+
+-- Existing books are kept in rupees.
+UPDATE accounts SET currency = 'INR';
+
+-- Units of the destination currency per unit of the source currency, set on
+-- both legs of a transfer between accounts of different currencies.
+ALTER TABLE transactions ADD COLUMN exchange_rate DOUBLE;
+
+-- +goose Down
+ALTER TABLE transactions DROP COLUMN exchange_rate;
+ALTER TABLE accounts DROP COLUMN currency;
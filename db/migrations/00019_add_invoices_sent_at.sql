@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE invoices ADD COLUMN sent_at TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE invoices DROP COLUMN sent_at;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- Direction of an adjustment transaction: 1 adds its amount to the account
+-- balance, -1 subtracts it. NULL for every other type.
+ALTER TABLE transactions ADD COLUMN sign INTEGER;
+
+-- +goose Down
+ALTER TABLE transactions DROP COLUMN sign;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- User-configured defaults, one value per key, e.g. default_account.expense
+-- holding the id of the account expenses are entered against.
+CREATE TABLE IF NOT EXISTS defaults (
+    id INTEGER NOT NULL,
+    key TEXT NOT NULL,
+    value TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS defaults;
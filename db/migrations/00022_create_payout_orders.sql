@@ -0,0 +1,16 @@
+-- +goose Up
+-- Order-level detail of a platform payout, for disputes. Amounts are in paise.
+CREATE TABLE IF NOT EXISTS payout_orders (
+    id INTEGER NOT NULL,
+    payout_id INTEGER NOT NULL,
+    order_id TEXT NOT NULL,
+    order_date DATE,
+    gross_amt INTEGER NOT NULL DEFAULT 0,
+    commission_amt INTEGER NOT NULL DEFAULT 0,
+    net_amt INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS payout_orders;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- Whether the money has actually moved (a cheque can take days to clear) and
+-- when it did. NULL cleared counts as cleared, so existing rows are unaffected.
+ALTER TABLE transactions ADD COLUMN cleared BOOLEAN DEFAULT true;
+ALTER TABLE transactions ADD COLUMN cleared_date DATE;
+
+-- +goose Down
+ALTER TABLE transactions DROP COLUMN cleared_date;
+ALTER TABLE transactions DROP COLUMN cleared;
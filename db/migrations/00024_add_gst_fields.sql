@@ -0,0 +1,14 @@
+-- +goose Up
+-- GST details for the GSTR-1 sales register. A contact's GSTIN marks it as a
+-- registered business. An invoice's amount includes tax_amount, charged at
+-- tax_rate percent; place_of_supply is the two-digit GST state code.
+ALTER TABLE contacts ADD COLUMN gstin TEXT;
+ALTER TABLE invoices ADD COLUMN tax_rate DOUBLE;
+ALTER TABLE invoices ADD COLUMN tax_amount INTEGER;
+ALTER TABLE invoices ADD COLUMN place_of_supply TEXT;
+
+-- +goose Down
+ALTER TABLE invoices DROP COLUMN place_of_supply;
+ALTER TABLE invoices DROP COLUMN tax_amount;
+ALTER TABLE invoices DROP COLUMN tax_rate;
+ALTER TABLE contacts DROP COLUMN gstin;
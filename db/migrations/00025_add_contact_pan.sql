@@ -0,0 +1,6 @@
+-- +goose Up
+-- A contact's PAN, recorded for TDS and for holders not registered for GST.
+ALTER TABLE contacts ADD COLUMN pan TEXT;
+
+-- +goose Down
+ALTER TABLE contacts DROP COLUMN pan;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- When a payout was last changed, for GET /changes. Existing payouts take
+-- their creation time.
+ALTER TABLE payouts ADD COLUMN updated_at TIMESTAMP;
+UPDATE payouts SET updated_at = created_at;
+
+-- +goose Down
+ALTER TABLE payouts DROP COLUMN updated_at;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- Tombstones of deleted records, so sync clients polling GET /changes learn
+-- of deletions as well as changes.
+CREATE TABLE IF NOT EXISTS deletions (
+    id INTEGER NOT NULL,
+    resource TEXT NOT NULL,
+    resource_id INTEGER NOT NULL,
+    deleted_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS deletions;
@@ -0,0 +1,24 @@
+-- +goose Up
+-- Each contact's outstanding balance and allocated payments across its
+-- non-cancelled bills (vendors) or invoices (customers), kept up to date on
+-- document and link writes so GET /contacts need not sum them per row.
+ALTER TABLE contacts ADD COLUMN cached_balance INTEGER;
+ALTER TABLE contacts ADD COLUMN cached_allocated INTEGER;
+
+UPDATE contacts SET
+    cached_allocated = CASE
+        WHEN type = 'vendor' THEN COALESCE((SELECT SUM(td.amount) FROM transaction_documents td JOIN bills b ON td.document_id = b.id WHERE td.document_type = 'bill' AND b.contact_id = contacts.id), 0)
+        WHEN type = 'customer' THEN COALESCE((SELECT SUM(td.amount) FROM transaction_documents td JOIN invoices i ON td.document_id = i.id WHERE td.document_type = 'invoice' AND i.contact_id = contacts.id), 0)
+        ELSE 0
+    END,
+    cached_balance = CASE
+        WHEN type = 'vendor' THEN COALESCE((SELECT SUM(amount) FROM bills WHERE contact_id = contacts.id AND status <> 'cancelled'), 0)
+            - COALESCE((SELECT SUM(td.amount) FROM transaction_documents td JOIN bills b ON td.document_id = b.id WHERE td.document_type = 'bill' AND b.contact_id = contacts.id), 0)
+        WHEN type = 'customer' THEN COALESCE((SELECT SUM(amount) FROM invoices WHERE contact_id = contacts.id AND status <> 'cancelled'), 0)
+            - COALESCE((SELECT SUM(td.amount) FROM transaction_documents td JOIN invoices i ON td.document_id = i.id WHERE td.document_type = 'invoice' AND i.contact_id = contacts.id), 0)
+        ELSE 0
+    END;
+
+-- +goose Down
+ALTER TABLE contacts DROP COLUMN cached_allocated;
+ALTER TABLE contacts DROP COLUMN cached_balance;
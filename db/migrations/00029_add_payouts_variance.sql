@@ -0,0 +1,10 @@
+-- +goose Up
+-- A shortfall between a payout and the bank credits settling it, such as a
+-- platform adjustment or chargeback, recorded with the reason so the payout
+-- no longer reads as partially received.
+ALTER TABLE payouts ADD COLUMN variance_amount BIGINT DEFAULT 0;
+ALTER TABLE payouts ADD COLUMN variance_reason VARCHAR;
+
+-- +goose Down
+ALTER TABLE payouts DROP COLUMN variance_reason;
+ALTER TABLE payouts DROP COLUMN variance_amount;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- A locked transaction is frozen against edits and deletion, typically once
+-- it has been reconciled and verified, until it is explicitly unlocked.
+ALTER TABLE transactions ADD COLUMN locked BOOLEAN DEFAULT false;
+
+-- +goose Down
+ALTER TABLE transactions DROP COLUMN locked;
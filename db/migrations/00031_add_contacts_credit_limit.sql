@@ -0,0 +1,6 @@
+-- +goose Up
+-- The most a customer may owe on open invoices; NULL means no limit.
+ALTER TABLE contacts ADD COLUMN credit_limit BIGINT;
+
+-- +goose Down
+ALTER TABLE contacts DROP COLUMN credit_limit;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- Income and expense categories (rent, ingredients, salaries, utilities)
+-- that transactions and bills are tagged with for reporting.
+CREATE TABLE IF NOT EXISTS categories (
+    id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    type TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+ALTER TABLE transactions ADD COLUMN category_id INTEGER;
+ALTER TABLE bills ADD COLUMN category_id INTEGER;
+
+-- +goose Down
+ALTER TABLE bills DROP COLUMN category_id;
+ALTER TABLE transactions DROP COLUMN category_id;
+DROP TABLE IF EXISTS categories;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Per-line GST for invoices whose items are taxed at different rates, and
+-- the HSN code (SAC for services) each line is classified under. A NULL
+-- tax_rate leaves the line to the invoice's rate.
+ALTER TABLE invoice_items ADD COLUMN tax_rate DOUBLE;
+ALTER TABLE invoice_items ADD COLUMN hsn_code TEXT;
+
+-- +goose Down
+ALTER TABLE invoice_items DROP COLUMN hsn_code;
+ALTER TABLE invoice_items DROP COLUMN tax_rate;
@@ -12,11 +12,11 @@ import (
 )
 
 // totalMigrations is the number of SQL migration files in db/migrations/.
-const totalMigrations = 11
+const totalMigrations = 33
 
-// allApplicationTables lists every application table created by the migrations,
-// in the order they are created (one per SQL file).
-var allApplicationTables = []string{
+// migrationTables lists the application table created by each SQL file, in
+// migration order. Migrations that only alter an existing table have "".
+var migrationTables = []string{
 	"accounts",
 	"contacts",
 	"bills",
@@ -28,6 +28,28 @@ var allApplicationTables = []string{
 	"recurring_payment_occurrences",
 	"bill_items",
 	"invoice_items",
+	"", // 00012 adds transactions.reconciled
+	"closed_periods",
+	"", // 00014 adds transactions.external_id
+	"", // 00015 adds transactions.source
+	"outlets",
+	"", // 00017 adds transaction_documents.note
+	"", // 00018 adds accounts.currency and transactions.exchange_rate
+	"", // 00019 adds invoices.sent_at
+	"", // 00020 adds transactions.sign
+	"defaults",
+	"payout_orders",
+	"", // 00023 adds transactions.cleared and cleared_date
+	"", // 00024 adds contacts.gstin and invoice tax fields
+	"", // 00025 adds contacts.pan
+	"", // 00026 adds payouts.updated_at
+	"deletions",
+	"", // 00028 adds contacts.cached_balance and cached_allocated
+	"", // 00029 adds payouts.variance_amount and variance_reason
+	"", // 00030 adds transactions.locked
+	"", // 00031 adds contacts.credit_limit
+	"categories",
+	"", // 00033 adds invoice_items.tax_rate and hsn_code
 }
 
 // openTestDB opens an in-file DuckDB database suitable for migration tests.
@@ -120,7 +142,10 @@ func TestMigrateDB_AppliesAllMigrations(t *testing.T) {
 		t.Errorf("applied migrations = %d, want %d", got, totalMigrations)
 	}
 
-	for _, tbl := range allApplicationTables {
+	for _, tbl := range migrationTables {
+		if tbl == "" {
+			continue
+		}
 		if !tableExists(t, db, tbl) {
 			t.Errorf("expected table %q to exist after migration", tbl)
 		}
@@ -157,7 +182,10 @@ func TestRollbackDB_LastN(t *testing.T) {
 	}
 
 	// Tables for the last rollbackN migrations must no longer exist.
-	for _, tbl := range allApplicationTables[len(allApplicationTables)-rollbackN:] {
+	for _, tbl := range migrationTables[len(migrationTables)-rollbackN:] {
+		if tbl == "" {
+			continue
+		}
 		if tableExists(t, db, tbl) {
 			t.Errorf("table %q should not exist after rolling back last %d migrations", tbl, rollbackN)
 		}
@@ -175,7 +203,10 @@ func TestRollbackDB_All(t *testing.T) {
 		t.Errorf("expected 0 applied migrations after full rollback, got %d", got)
 	}
 
-	for _, tbl := range allApplicationTables {
+	for _, tbl := range migrationTables {
+		if tbl == "" {
+			continue
+		}
 		if tableExists(t, db, tbl) {
 			t.Errorf("table %q should not exist after full rollback", tbl)
 		}
@@ -196,7 +227,7 @@ func TestRollbackDB_ThenReapply(t *testing.T) {
 }
 
 // TestMigrateDB_VersionsAreCorrect verifies that every migration is recorded
-// with the expected version number (1–11) after a full apply.
+// with the expected version number (1–33) after a full apply.
 func TestMigrateDB_VersionsAreCorrect(t *testing.T) {
 	db := openTestDB(t)
 	migrateTestDB(t, db)
@@ -225,3 +256,53 @@ func TestMigrateDB_VersionsAreCorrect(t *testing.T) {
 		}
 	}
 }
+
+// TestMigrateDB_BackfillsOutlets verifies that the outlets migration registers
+// each distinct payout outlet name, ignoring case and surrounding spaces, and
+// points existing payouts at it.
+func TestMigrateDB_BackfillsOutlets(t *testing.T) {
+	db := openTestDB(t)
+	provider, err := newTestProvider(db)
+	if err != nil {
+		t.Fatalf("newTestProvider: %v", err)
+	}
+	if _, err := provider.UpTo(context.Background(), 15); err != nil {
+		t.Fatalf("provider.UpTo(15): %v", err)
+	}
+	// The gateway assigns ids on insert; create the table the migration fills
+	// with such a default first, which its CREATE TABLE IF NOT EXISTS keeps.
+	for _, stmt := range []string{
+		"CREATE SEQUENCE outlets_id_seq",
+		`CREATE TABLE outlets (id INTEGER NOT NULL DEFAULT nextval('outlets_id_seq'), name TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL, updated_at TIMESTAMP NOT NULL)`,
+	} {
+		if _, err := db.DB.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	for i, name := range []string{"Koramangala", " Koramangala ", "koramangala", "Indiranagar"} {
+		if _, err := db.DB.Exec(`INSERT INTO payouts (id, outlet_name, platform, created_at)
+			VALUES ($1, $2, 'swiggy', CURRENT_TIMESTAMP)`, i+1, name); err != nil {
+			t.Fatalf("insert payout: %v", err)
+		}
+	}
+	if _, err := provider.Up(context.Background()); err != nil {
+		t.Fatalf("provider.Up: %v", err)
+	}
+
+	var outlets int
+	if err := db.DB.QueryRow("SELECT COUNT(*) FROM outlets").Scan(&outlets); err != nil {
+		t.Fatalf("count outlets: %v", err)
+	}
+	if outlets != 2 {
+		t.Errorf("expected 2 outlets, got %d", outlets)
+	}
+	var unlinked, distinct int
+	if err := db.DB.QueryRow("SELECT COUNT(*) FILTER (WHERE outlet_id IS NULL), COUNT(DISTINCT outlet_id) FROM payouts").
+		Scan(&unlinked, &distinct); err != nil {
+		t.Fatalf("check payouts: %v", err)
+	}
+	if unlinked != 0 || distinct != 2 {
+		t.Errorf("expected all payouts linked to 2 outlets, got %d unlinked and %d distinct", unlinked, distinct)
+	}
+}
@@ -0,0 +1,81 @@
+package db
+
+import (
+	"database/sql"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+)
+
+// PoolConfig controls connection pooling and connection timeouts. It is read
+// from the environment by PoolConfigFromEnv; zero values keep the
+// database/sql and pgx defaults.
+type PoolConfig struct {
+	MaxOpenConns    int           // DB_MAX_OPEN_CONNS; 0 means unlimited
+	MaxIdleConns    int           // DB_MAX_IDLE_CONNS; 0 keeps the database/sql default of 2
+	ConnMaxLifetime time.Duration // DB_CONN_MAX_LIFETIME, e.g. "30m"; 0 means no limit
+	ConnMaxIdleTime time.Duration // DB_CONN_MAX_IDLE_TIME, e.g. "5m"; 0 means no limit
+	// ConnectTimeout (DB_CONNECT_TIMEOUT, e.g. "5s") bounds how long opening a
+	// connection to the gateway may take, so a busy gateway fails the request
+	// instead of hanging it.
+	ConnectTimeout time.Duration
+}
+
+// PoolConfigFromEnv reads the pool configuration from environment variables.
+// Invalid values are logged and ignored.
+func PoolConfigFromEnv() PoolConfig {
+	return PoolConfig{
+		MaxOpenConns:    envInt("DB_MAX_OPEN_CONNS"),
+		MaxIdleConns:    envInt("DB_MAX_IDLE_CONNS"),
+		ConnMaxLifetime: envDuration("DB_CONN_MAX_LIFETIME"),
+		ConnMaxIdleTime: envDuration("DB_CONN_MAX_IDLE_TIME"),
+		ConnectTimeout:  envDuration("DB_CONNECT_TIMEOUT"),
+	}
+}
+
+// apply sets the pool limits on db. Limits left at zero are not changed.
+func (p PoolConfig) apply(db *sql.DB) {
+	if p.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(p.MaxOpenConns)
+	}
+	if p.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(p.MaxIdleConns)
+	}
+	if p.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(p.ConnMaxLifetime)
+	}
+	if p.ConnMaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(p.ConnMaxIdleTime)
+	}
+}
+
+// envInt reads a non-negative integer environment variable, returning 0 when
+// it is unset or invalid.
+func envInt(key string) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		slog.Warn("ignoring invalid integer environment variable", "key", key, "value", v)
+		return 0
+	}
+	return n
+}
+
+// envDuration reads a non-negative duration environment variable such as "30s",
+// returning 0 when it is unset or invalid.
+func envDuration(key string) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		slog.Warn("ignoring invalid duration environment variable", "key", key, "value", v)
+		return 0
+	}
+	return d
+}
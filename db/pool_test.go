@@ -0,0 +1,24 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPoolConfigFromEnv(t *testing.T) {
+	t.Setenv("DB_MAX_OPEN_CONNS", "8")
+	t.Setenv("DB_MAX_IDLE_CONNS", "abc")
+	t.Setenv("DB_CONN_MAX_LIFETIME", "30m")
+	t.Setenv("DB_CONN_MAX_IDLE_TIME", "-1s")
+	t.Setenv("DB_CONNECT_TIMEOUT", "5s")
+
+	got := PoolConfigFromEnv()
+	want := PoolConfig{
+		MaxOpenConns:    8,
+		ConnMaxLifetime: 30 * time.Minute,
+		ConnectTimeout:  5 * time.Second,
+	}
+	if got != want {
+		t.Errorf("PoolConfigFromEnv() = %+v, want %+v", got, want)
+	}
+}
@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
@@ -83,6 +84,40 @@ func processCode(code string, n *int) string {
 	return res.String()
 }
 
+// QueryError is a database error annotated with the statement that failed and
+// its arguments, for logging. Its message is the underlying error's, so
+// clients that see it learn nothing new; Unwrap keeps errors.Is working.
+type QueryError struct {
+	Query string
+	Args  []any
+	Err   error
+}
+
+func (e *QueryError) Error() string { return e.Err.Error() }
+
+func (e *QueryError) Unwrap() error { return e.Err }
+
+// queryError wraps err, unless it is nil or sql.ErrNoRows, which callers
+// treat as an outcome rather than a failure.
+func queryError(err error, query string, args []any) error {
+	if err == nil || errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	return &QueryError{Query: query, Args: args, Err: err}
+}
+
+// Row is the result of QueryRow. Its Scan reports failures as *QueryError.
+type Row struct {
+	*sql.Row
+	query string
+	args  []any
+}
+
+// Scan copies the row's columns into dest, as sql.Row.Scan does.
+func (r *Row) Scan(dest ...any) error {
+	return queryError(r.Row.Scan(dest...), r.query, r.args)
+}
+
 // PortalDB wraps *sql.DB and automatically rebinds ? placeholders to $N
 // for compatibility with the PostgreSQL wire protocol used by the Nexus gateway.
 type PortalDB struct {
@@ -94,24 +129,29 @@ func WrapDB(db *sql.DB) *PortalDB {
 	return &PortalDB{db}
 }
 
-// Query rebinds ? placeholders before executing the query.
+// Query rebinds ? placeholders before executing the query. Errors are
+// reported as *QueryError.
 func (d *PortalDB) Query(query string, args ...any) (*sql.Rows, error) {
-	return d.DB.Query(rebind(query), args...)
+	rows, err := d.DB.Query(rebind(query), args...)
+	return rows, queryError(err, query, args)
 }
 
 // QueryRow rebinds ? placeholders before executing the query.
-func (d *PortalDB) QueryRow(query string, args ...any) *sql.Row {
-	return d.DB.QueryRow(rebind(query), args...)
+func (d *PortalDB) QueryRow(query string, args ...any) *Row {
+	return &Row{Row: d.DB.QueryRow(rebind(query), args...), query: query, args: args}
 }
 
-// Exec rebinds ? placeholders before executing the statement.
+// Exec rebinds ? placeholders before executing the statement. Errors are
+// reported as *QueryError.
 func (d *PortalDB) Exec(query string, args ...any) (sql.Result, error) {
-	return d.DB.Exec(rebind(query), args...)
+	res, err := d.DB.Exec(rebind(query), args...)
+	return res, queryError(err, query, args)
 }
 
 // Prepare rebinds ? placeholders before preparing the statement.
 func (d *PortalDB) Prepare(query string) (*sql.Stmt, error) {
-	return d.DB.Prepare(rebind(query))
+	stmt, err := d.DB.Prepare(rebind(query))
+	return stmt, queryError(err, query, nil)
 }
 
 // Begin starts a transaction and returns a PortalTx that also auto-rebinds.
@@ -138,22 +178,27 @@ type PortalTx struct {
 	*sql.Tx
 }
 
-// Query rebinds ? placeholders before executing the query.
+// Query rebinds ? placeholders before executing the query. Errors are
+// reported as *QueryError.
 func (t *PortalTx) Query(query string, args ...any) (*sql.Rows, error) {
-	return t.Tx.Query(rebind(query), args...)
+	rows, err := t.Tx.Query(rebind(query), args...)
+	return rows, queryError(err, query, args)
 }
 
 // QueryRow rebinds ? placeholders before executing the query.
-func (t *PortalTx) QueryRow(query string, args ...any) *sql.Row {
-	return t.Tx.QueryRow(rebind(query), args...)
+func (t *PortalTx) QueryRow(query string, args ...any) *Row {
+	return &Row{Row: t.Tx.QueryRow(rebind(query), args...), query: query, args: args}
 }
 
-// Exec rebinds ? placeholders before executing the statement.
+// Exec rebinds ? placeholders before executing the statement. Errors are
+// reported as *QueryError.
 func (t *PortalTx) Exec(query string, args ...any) (sql.Result, error) {
-	return t.Tx.Exec(rebind(query), args...)
+	res, err := t.Tx.Exec(rebind(query), args...)
+	return res, queryError(err, query, args)
 }
 
 // Prepare rebinds ? placeholders before preparing the statement.
 func (t *PortalTx) Prepare(query string) (*sql.Stmt, error) {
-	return t.Tx.Prepare(rebind(query))
+	stmt, err := t.Tx.Prepare(rebind(query))
+	return stmt, queryError(err, query, nil)
 }
@@ -69,7 +69,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a new bank account, cash or credit card.",
+                "description": "Create a new bank account, cash, credit card or loan. opening_balance is signed from the asset side: an amount owed on a card or loan is negative. A negative opening on a bank or cash account is accepted as an overdraft with a warning.\nAmounts are entered in whole units of the account's currency and kept in its smallest unit, e.g. 1000 yen is 1000 in a JPY account and 1.5 dinars is 1500 in a KWD one.",
                 "consumes": [
                     "application/json"
                 ],
@@ -103,7 +103,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.Account"
+                                            "$ref": "#/definitions/handlers.AccountResult"
                                         }
                                     }
                                 }
@@ -138,7 +138,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get details and current balance of a specific account.",
+                "description": "Get details and current balance of a specific account. cleared_balance leaves out pending (cleared: false) transactions; projected_balance includes them.",
                 "produces": [
                     "application/json"
                 ],
@@ -174,6 +174,24 @@ const docTemplate = `{
                             ]
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -184,8 +202,14 @@ const docTemplate = `{
                                 {
                                     "type": "object",
                                     "properties": {
+                                        "code": {
+                                            "type": "string"
+                                        },
                                         "error": {
                                             "type": "string"
+                                        },
+                                        "resource": {
+                                            "type": "string"
                                         }
                                     }
                                 }
@@ -200,7 +224,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update details of an existing account.",
+                "description": "Update details of an existing account. The currency of an account with transactions cannot change, as their amounts would not be converted.",
                 "consumes": [
                     "application/json"
                 ],
@@ -241,7 +265,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.Account"
+                                            "$ref": "#/definitions/handlers.AccountResult"
                                         }
                                     }
                                 }
@@ -283,6 +307,24 @@ const docTemplate = `{
                                 }
                             ]
                         }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
                     }
                 }
             },
@@ -331,6 +373,24 @@ const docTemplate = `{
                             ]
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -352,148 +412,227 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/login": {
-            "post": {
-                "description": "Authenticates with the Nexus gateway using email and password. Returns a JWT token.",
-                "consumes": [
-                    "application/json"
+        "/accounts/{id}/delete-impact": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Count the transactions, transfer legs, recurring payments, and default settings that reference the account. Deletion does not cascade, so these\nrows would be left pointing at a missing account; use the counts to confirm before deleting.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "accounts"
                 ],
-                "summary": "Login",
+                "summary": "Preview account deletion",
                 "parameters": [
                     {
-                        "description": "Login credentials",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.loginRequest"
-                        }
+                        "type": "integer",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handlers.AccountDeleteImpact"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/handlers.Response"
-                        }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/handlers.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
-                    "502": {
-                        "description": "Bad Gateway",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/handlers.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "code": {
+                                            "type": "string"
+                                        },
+                                        "error": {
+                                            "type": "string"
+                                        },
+                                        "resource": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
             }
         },
-        "/auth/register": {
+        "/accounts/{id}/import": {
             "post": {
-                "description": "Provisions a tenant via the Nexus gateway and initialises the portal schema. Requires NEXUS_CONTROL_URL and ADMIN_API_KEY to be configured.",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Import transactions from a bank statement file, sent as the request body or as the \"file\" field of a multipart form. Credits become income and debits expense. Entries are deduplicated by their bank id (OFX FITID) stored as external_id, so re-importing the same file is safe. Entries dated in a closed period are reported as errors.\nFor CSV, the header row and its date, debit, credit, amount, description, and reference columns are detected from common names (e.g. \"Withdrawal Amt.\" is debit); mapping, a JSON object of field to header name sent as a query parameter or form field, overrides the detection. The columns used are returned in columns. CSV entries are deduplicated by an id derived from their contents.",
                 "consumes": [
-                    "application/json"
+                    "application/x-ofx",
+                    "text/csv",
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "accounts"
                 ],
-                "summary": "Register a new tenant",
+                "summary": "Import bank statement",
                 "parameters": [
                     {
-                        "description": "Registration data",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.registerRequest"
-                        }
+                        "type": "integer",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "File format: ofx (or qfx) or csv",
+                        "name": "format",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "CSV column overrides: a JSON object of field to header name",
+                        "name": "mapping",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handlers.ImportResult"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/handlers.Response"
-                        }
-                    },
-                    "409": {
-                        "description": "Conflict",
-                        "schema": {
-                            "$ref": "#/definitions/handlers.Response"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/handlers.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
-                    "502": {
-                        "description": "Bad Gateway",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/handlers.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
             }
         },
-        "/bills": {
+        "/accounts/{id}/stats": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get a list of all payable bills, with current status and allocation info.",
+                "description": "Total inflow, outflow, and net of the account's transactions dated from to to, with the transaction count and the largest single income and expense.\nTransfers to and from other accounts are reported separately as transfers_in and transfers_out and do not count as inflow, outflow, or the largest income or expense.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "bills"
+                    "accounts"
                 ],
-                "summary": "List bills",
+                "summary": "Get account stats",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Filter by contact (vendor)",
-                        "name": "contact_id",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "from",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Search by bill number, notes, or vendor name",
-                        "name": "search",
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "to",
                         "in": "query"
                     }
                 ],
@@ -509,59 +648,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/models.Bill"
-                                            }
-                                        }
-                                    }
-                                }
-                            ]
-                        }
-                    }
-                }
-            },
-            "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Create a new payable bill.",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "bills"
-                ],
-                "summary": "Create bill",
-                "parameters": [
-                    {
-                        "description": "Bill contents",
-                        "name": "bill",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.BillInput"
-                        }
-                    }
-                ],
-                "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/handlers.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/models.Bill"
+                                            "$ref": "#/definitions/handlers.AccountStats"
                                         }
                                     }
                                 }
@@ -585,52 +672,6 @@ const docTemplate = `{
                                 }
                             ]
                         }
-                    }
-                }
-            }
-        },
-        "/bills/{id}": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get details and allocation status of a specific bill.",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "bills"
-                ],
-                "summary": "Get bill",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Bill ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/handlers.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/models.Bill"
-                                        }
-                                    }
-                                }
-                            ]
-                        }
                     },
                     "404": {
                         "description": "Not Found",
@@ -642,8 +683,14 @@ const docTemplate = `{
                                 {
                                     "type": "object",
                                     "properties": {
+                                        "code": {
+                                            "type": "string"
+                                        },
                                         "error": {
                                             "type": "string"
+                                        },
+                                        "resource": {
+                                            "type": "string"
                                         }
                                     }
                                 }
@@ -651,40 +698,30 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/accounts/{id}/verify": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update details of an existing bill.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Recompute the balance from the opening balance and each transaction on the account and compare it with the displayed balance.\nReports the discrepancy, the count and net sum of the transactions considered, and transactions that cannot be counted\nor transfer legs missing their counterpart on the other account. consistent is true when nothing is off.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "bills"
+                    "accounts"
                 ],
-                "summary": "Update bill",
+                "summary": "Verify account balance",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Bill ID",
+                        "description": "Account ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Updated bill contents",
-                        "name": "bill",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.BillInput"
-                        }
                     }
                 ],
                 "responses": {
@@ -699,7 +736,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.Bill"
+                                            "$ref": "#/definitions/handlers.AccountVerification"
                                         }
                                     }
                                 }
@@ -734,8 +771,14 @@ const docTemplate = `{
                                 {
                                     "type": "object",
                                     "properties": {
+                                        "code": {
+                                            "type": "string"
+                                        },
                                         "error": {
                                             "type": "string"
+                                        },
+                                        "resource": {
+                                            "type": "string"
                                         }
                                     }
                                 }
@@ -743,28 +786,29 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/admin/purge": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Remove a bill.",
+                "description": "Maintenance tool that hard-deletes rows left pointing at deleted records: occurrences of deleted recurring payments, items of deleted bills and invoices,\norders of deleted payouts, and payment links whose transaction or document is gone. Documents that lose links have their status and contact\nbalance worked out again. It also drops the deletion tombstones GET /changes reports once they are older than DELETIONS_RETENTION_DAYS\n(default 90, 0 keeps them). Returns the number of rows removed per table.\nWhen ADMIN_API_KEY is configured the request must carry it in the X-Admin-Key header.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "bills"
+                    "admin"
                 ],
-                "summary": "Delete bill",
+                "summary": "Purge orphaned rows",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Bill ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "type": "string",
+                        "description": "Admin API key",
+                        "name": "X-Admin-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -781,7 +825,7 @@ const docTemplate = `{
                                         "data": {
                                             "type": "object",
                                             "additionalProperties": {
-                                                "type": "string"
+                                                "type": "integer"
                                             }
                                         }
                                     }
@@ -789,8 +833,8 @@ const docTemplate = `{
                             ]
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "allOf": [
                                 {
@@ -810,28 +854,27 @@ const docTemplate = `{
                 }
             }
         },
-        "/bills/{id}/items": {
-            "get": {
+        "/admin/recalc-contacts": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get all line items for a specific bill.",
+                "description": "Maintenance tool that recomputes every contact's cached balance and allocated amount from its bills or invoices and their payment links. GET /contacts reads these caches, which bill, invoice, and link writes keep current;\nrebuild them after bulk imports or manual database edits. Returns the number of contacts updated.\nWhen ADMIN_API_KEY is configured the request must carry it in the X-Admin-Key header.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "bills"
+                    "admin"
                 ],
-                "summary": "List bill items",
+                "summary": "Recalculate contact balances",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Bill ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "type": "string",
+                        "description": "Admin API key",
+                        "name": "X-Admin-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -846,9 +889,9 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/models.BillItem"
+                                            "type": "object",
+                                            "additionalProperties": {
+                                                "type": "integer"
                                             }
                                         }
                                     }
@@ -856,8 +899,8 @@ const docTemplate = `{
                             ]
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "allOf": [
                                 {
@@ -875,45 +918,40 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/admin/recompute-statuses": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Add a new line item to an existing bill.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Maintenance tool for when stored statuses have drifted, e.g. after bulk imports or manual database edits. Re-derives every bill and invoice status from its payment allocations, the same way linking a payment does, and returns how many changed. Cancelled documents, and unpaid ones marked sent or overdue, are left as they are. Payouts have no stored status and are only counted.\nWhen ADMIN_API_KEY is configured the request must carry it in the X-Admin-Key header.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "bills"
+                    "admin"
                 ],
-                "summary": "Create bill item",
+                "summary": "Recompute document statuses",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Bill ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "type": "string",
+                        "description": "Admin API key",
+                        "name": "X-Admin-Key",
+                        "in": "header"
                     },
                     {
-                        "description": "Line item contents",
-                        "name": "item",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.BillItemInput"
-                        }
+                        "type": "integer",
+                        "description": "Documents per transaction (default 500)",
+                        "name": "batch_size",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "allOf": [
                                 {
@@ -923,7 +961,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.BillItem"
+                                            "$ref": "#/definitions/handlers.StatusRecomputeResult"
                                         }
                                     }
                                 }
@@ -948,8 +986,8 @@ const docTemplate = `{
                             ]
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "allOf": [
                                 {
@@ -969,14 +1007,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/bills/{id}/items/{itemId}": {
-            "put": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Update an existing line item in a bill.",
+        "/auth/login": {
+            "post": {
+                "description": "Authenticates with the Nexus gateway using email and password. Returns a short-lived access token (ACCESS_TOKEN_TTL, default 15m) and a refresh token (REFRESH_TOKEN_TTL, default 7 days) for POST /auth/refresh. After LOGIN_MAX_FAILURES (default 5) failed attempts for an email from one client IP within LOGIN_FAILURE_WINDOW (default 15m), attempts get 429 with Retry-After.",
                 "consumes": [
                     "application/json"
                 ],
@@ -984,31 +1017,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "bills"
+                    "auth"
                 ],
-                "summary": "Update bill item",
+                "summary": "Login",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Bill ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Item ID",
-                        "name": "itemId",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Updated line item contents",
-                        "name": "item",
+                        "description": "Login credentials",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.BillItemInput"
+                            "$ref": "#/definitions/handlers.loginRequest"
                         }
                     }
                 ],
@@ -1024,7 +1043,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.BillItem"
+                                            "$ref": "#/definitions/handlers.sessionTokens"
                                         }
                                     }
                                 }
@@ -1034,23 +1053,17 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/handlers.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "error": {
-                                            "type": "string"
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/handlers.Response"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Response"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
                         "schema": {
                             "allOf": [
                                 {
@@ -1066,37 +1079,37 @@ const docTemplate = `{
                                 }
                             ]
                         }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Response"
+                        }
                     }
                 }
-            },
-            "delete": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
+            }
+        },
+        "/auth/logout": {
+            "post": {
+                "description": "Ends the session owning the given refresh token, or the access token in the Authorization header. Both of its tokens stop working immediately.",
+                "consumes": [
+                    "application/json"
                 ],
-                "description": "Remove a line item from a bill.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "bills"
+                    "auth"
                 ],
-                "summary": "Delete bill item",
+                "summary": "Logout",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Bill ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Item ID",
-                        "name": "itemId",
-                        "in": "path",
-                        "required": true
+                        "description": "Refresh token",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.refreshRequest"
+                        }
                     }
                 ],
                 "responses": {
@@ -1121,49 +1134,37 @@ const docTemplate = `{
                             ]
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/handlers.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "error": {
-                                            "type": "string"
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/handlers.Response"
                         }
                     }
                 }
             }
         },
-        "/bills/{id}/links": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
+        "/auth/refresh": {
+            "post": {
+                "description": "Exchanges a refresh token from login for a new access token and refresh token. The refresh token sent is spent. Sessions end when the refresh token or the underlying Nexus login expires, whichever is first.",
+                "consumes": [
+                    "application/json"
                 ],
-                "description": "Get all payment transactions linked to a specific bill.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "bills"
+                    "auth"
                 ],
-                "summary": "Get bill links",
+                "summary": "Refresh access token",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Bill ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Refresh token",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.refreshRequest"
+                        }
                     }
                 ],
                 "responses": {
@@ -1178,113 +1179,147 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/handlers.BillLink"
-                                            }
+                                            "$ref": "#/definitions/handlers.sessionTokens"
                                         }
                                     }
                                 }
                             ]
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Response"
+                        }
                     }
                 }
             }
         },
-        "/bills/{id}/match-suggestions": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
+        "/auth/register": {
+            "post": {
+                "description": "Provisions a tenant via the Nexus gateway and initialises the portal schema. Requires NEXUS_CONTROL_URL and ADMIN_API_KEY to be configured. The password must be at least MIN_PASSWORD_LENGTH (default 8) characters.",
+                "consumes": [
+                    "application/json"
                 ],
-                "description": "Returns a ranked list of unallocated bank transactions that could match a bill, scored by amount, date, and description similarity. Already-linked transactions are excluded.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "bills"
+                    "auth"
                 ],
-                "summary": "Suggest transactions for a bill",
+                "summary": "Register a new tenant",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Bill ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Registration data",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.registerRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/handlers.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/handlers.TransactionSuggestion"
-                                            }
-                                        }
-                                    }
-                                }
-                            ]
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/handlers.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "error": {
-                                            "type": "string"
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/handlers.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Response"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Response"
                         }
                     }
                 }
             }
         },
-        "/contacts": {
+        "/bills": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get a list of all vendors and customers with financial summaries.",
+                "description": "Get a list of all payable bills, with current status and allocation info.\nSend Accept: text/csv for the same list as CSV.",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/csv"
                 ],
                 "tags": [
-                    "contacts"
+                    "bills"
                 ],
-                "summary": "List contacts",
+                "summary": "List bills",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Filter by contact (vendor)",
+                        "name": "contact_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by category",
+                        "name": "category_id",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
-                        "description": "Filter by type (vendor/customer)",
-                        "name": "type",
+                        "description": "Filter by issue date from (YYYY-MM-DD)",
+                        "name": "from",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Search by name, email, or phone",
+                        "description": "Filter by issue date to (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search by bill number, notes, or vendor name",
                         "name": "search",
                         "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include cancelled bills (excluded by default)",
+                        "name": "include_cancelled",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "CSV amounts as rupees (default), rupees_grouped, or paise",
+                        "name": "amount_format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -1301,13 +1336,31 @@ const docTemplate = `{
                                         "data": {
                                             "type": "array",
                                             "items": {
-                                                "$ref": "#/definitions/models.Contact"
+                                                "$ref": "#/definitions/models.Bill"
                                             }
                                         }
                                     }
                                 }
                             ]
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
                     }
                 }
             },
@@ -1317,7 +1370,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a new vendor or customer.",
+                "description": "Create a new payable bill.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1325,17 +1378,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "contacts"
+                    "bills"
                 ],
-                "summary": "Create contact",
+                "summary": "Create bill",
                 "parameters": [
                     {
-                        "description": "Contact contents",
-                        "name": "contact",
+                        "description": "Bill contents",
+                        "name": "bill",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.ContactInput"
+                            "$ref": "#/definitions/models.BillInput"
                         }
                     }
                 ],
@@ -1351,7 +1404,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.Contact"
+                                            "$ref": "#/definitions/models.Bill"
                                         }
                                     }
                                 }
@@ -1379,30 +1432,26 @@ const docTemplate = `{
                 }
             }
         },
-        "/contacts/{id}": {
-            "get": {
+        "/bills/ocr": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get details and financial summary of a specific contact.",
+                "description": "Read one or more receipt images with the configured OCR service (OCR_SERVICE_URL) and return a draft bill for each,\nprefilled with the detected vendor, date, amount, and bill number. Nothing is saved: confirm a draft by sending its bill to POST /bills.\nSend images as \"file\" fields of a multipart form, or a single image as the request body. Without an OCR service, drafts are empty for manual entry.",
+                "consumes": [
+                    "multipart/form-data",
+                    "image/jpeg",
+                    "image/png"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "contacts"
-                ],
-                "summary": "Get contact",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Contact ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "bills"
                 ],
+                "summary": "Draft bills from receipts",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -1415,15 +1464,15 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.Contact"
+                                            "$ref": "#/definitions/handlers.OCRResult"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "allOf": [
                                 {
@@ -1441,40 +1490,30 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/bills/{id}": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update details of an existing contact.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get details and allocation status of a specific bill.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "contacts"
+                    "bills"
                 ],
-                "summary": "Update contact",
+                "summary": "Get bill",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Contact ID",
+                        "description": "Bill ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Updated contact contents",
-                        "name": "contact",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.ContactInput"
-                        }
                     }
                 ],
                 "responses": {
@@ -1489,7 +1528,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.Contact"
+                                            "$ref": "#/definitions/models.Bill"
                                         }
                                     }
                                 }
@@ -1524,8 +1563,14 @@ const docTemplate = `{
                                 {
                                     "type": "object",
                                     "properties": {
+                                        "code": {
+                                            "type": "string"
+                                        },
                                         "error": {
                                             "type": "string"
+                                        },
+                                        "resource": {
+                                            "type": "string"
                                         }
                                     }
                                 }
@@ -1534,27 +1579,39 @@ const docTemplate = `{
                     }
                 }
             },
-            "delete": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Remove a contact.",
+                "description": "Update details of an existing bill.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "contacts"
+                    "bills"
                 ],
-                "summary": "Delete contact",
+                "summary": "Update bill",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Contact ID",
+                        "description": "Bill ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Updated bill contents",
+                        "name": "bill",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BillInput"
+                        }
                     }
                 ],
                 "responses": {
@@ -1569,18 +1626,15 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "object",
-                                            "additionalProperties": {
-                                                "type": "string"
-                                            }
+                                            "$ref": "#/definitions/models.Bill"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "allOf": [
                                 {
@@ -1596,28 +1650,9 @@ const docTemplate = `{
                                 }
                             ]
                         }
-                    }
-                }
-            }
-        },
-        "/dashboard": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get totals for accounts, contacts, bills, invoices, and recent transactions.",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "dashboard"
-                ],
-                "summary": "Get dashboard",
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "allOf": [
                                 {
@@ -1626,8 +1661,8 @@ const docTemplate = `{
                                 {
                                     "type": "object",
                                     "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/store.DashboardData"
+                                        "error": {
+                                            "type": "string"
                                         }
                                     }
                                 }
@@ -1635,35 +1670,28 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/invoices": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get a list of all receivable invoices, with current status and allocation info.",
+                "description": "Remove a bill.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "invoices"
+                    "bills"
                 ],
-                "summary": "List invoices",
+                "summary": "Delete bill",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Filter by contact (customer)",
-                        "name": "contact_id",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Search by invoice number, notes, or customer name",
-                        "name": "search",
-                        "in": "query"
+                        "description": "Bill ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -1678,49 +1706,18 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/models.Invoice"
+                                            "type": "object",
+                                            "additionalProperties": {
+                                                "type": "string"
                                             }
                                         }
                                     }
                                 }
                             ]
                         }
-                    }
-                }
-            },
-            "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Create a new receivable invoice.",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "invoices"
-                ],
-                "summary": "Create invoice",
-                "parameters": [
-                    {
-                        "description": "Invoice contents",
-                        "name": "invoice",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.InvoiceInput"
-                        }
-                    }
-                ],
-                "responses": {
-                    "201": {
-                        "description": "Created",
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "allOf": [
                                 {
@@ -1729,16 +1726,16 @@ const docTemplate = `{
                                 {
                                     "type": "object",
                                     "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/models.Invoice"
+                                        "error": {
+                                            "type": "string"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "allOf": [
                                 {
@@ -1758,25 +1755,25 @@ const docTemplate = `{
                 }
             }
         },
-        "/invoices/{id}": {
+        "/bills/{id}/items": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get details and allocation status of a specific invoice.",
+                "description": "Get all line items for a specific bill.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "invoices"
+                    "bills"
                 ],
-                "summary": "Get invoice",
+                "summary": "List bill items",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Invoice ID",
+                        "description": "Bill ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1794,7 +1791,28 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.Invoice"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.BillItem"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
                                         }
                                     }
                                 }
@@ -1821,13 +1839,13 @@ const docTemplate = `{
                     }
                 }
             },
-            "put": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update details of an existing invoice.",
+                "description": "Add a new line item to an existing bill.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1835,30 +1853,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "invoices"
+                    "bills"
                 ],
-                "summary": "Update invoice",
+                "summary": "Create bill item",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Invoice ID",
+                        "description": "Bill ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Updated invoice contents",
-                        "name": "invoice",
+                        "description": "Line item contents",
+                        "name": "item",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.InvoiceInput"
+                            "$ref": "#/definitions/models.BillItemInput"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
                             "allOf": [
                                 {
@@ -1868,7 +1886,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.Invoice"
+                                            "$ref": "#/definitions/models.BillItem"
                                         }
                                     }
                                 }
@@ -1912,28 +1930,49 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/bills/{id}/items/{itemId}": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Remove an invoice.",
+                "description": "Update an existing line item in a bill.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "invoices"
+                    "bills"
                 ],
-                "summary": "Delete invoice",
+                "summary": "Update bill item",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Invoice ID",
+                        "description": "Bill ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Item ID",
+                        "name": "itemId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated line item contents",
+                        "name": "item",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BillItemInput"
+                        }
                     }
                 ],
                 "responses": {
@@ -1948,10 +1987,25 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "object",
-                                            "additionalProperties": {
-                                                "type": "string"
-                                            }
+                                            "$ref": "#/definitions/models.BillItem"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
                                         }
                                     }
                                 }
@@ -1977,30 +2031,35 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/invoices/{id}/items": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get all line items for a specific invoice.",
+                "description": "Remove a line item from a bill.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "invoices"
+                    "bills"
                 ],
-                "summary": "List invoice items",
+                "summary": "Delete bill item",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Invoice ID",
+                        "description": "Bill ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Item ID",
+                        "name": "itemId",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -2015,9 +2074,9 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/models.InvoiceItem"
+                                            "type": "object",
+                                            "additionalProperties": {
+                                                "type": "string"
                                             }
                                         }
                                     }
@@ -2025,6 +2084,24 @@ const docTemplate = `{
                             ]
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -2044,45 +2121,53 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/bills/{id}/links": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Add a new line item to an existing invoice.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get payment transactions linked to a specific bill, oldest first unless order=desc. Supports limit/offset pagination; totals across all links are returned in headers.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "invoices"
+                    "bills"
                 ],
-                "summary": "Create invoice item",
+                "summary": "Get bill links",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Invoice ID",
+                        "description": "Bill ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Line item contents",
-                        "name": "item",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.InvoiceItemInput"
-                        }
+                        "type": "integer",
+                        "description": "Maximum number of links to return (default DEFAULT_PAGE_SIZE, or all; at most MAX_PAGE_SIZE)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of links to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort by created_at: asc (default) or desc",
+                        "name": "order",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "allOf": [
                                 {
@@ -2092,11 +2177,28 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.InvoiceItem"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/handlers.BillLink"
+                                            }
                                         }
                                     }
                                 }
                             ]
+                        },
+                        "headers": {
+                            "X-Page-Size-Clamped": {
+                                "type": "int",
+                                "description": "Limit applied when the requested page exceeded MAX_PAGE_SIZE"
+                            },
+                            "X-Total-Allocated": {
+                                "type": "int",
+                                "description": "Total allocated across all links, in paise"
+                            },
+                            "X-Total-Count": {
+                                "type": "int",
+                                "description": "Total number of links"
+                            }
                         }
                     },
                     "400": {
@@ -2116,69 +2218,32 @@ const docTemplate = `{
                                 }
                             ]
                         }
-                    },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/handlers.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "error": {
-                                            "type": "string"
-                                        }
-                                    }
-                                }
-                            ]
-                        }
                     }
                 }
             }
         },
-        "/invoices/{id}/items/{itemId}": {
-            "put": {
+        "/bills/{id}/match-suggestions": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update an existing line item in an invoice.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Returns a ranked list of unallocated bank transactions that could match a bill, scored by amount, date, and description similarity. Already-linked transactions are excluded.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "invoices"
+                    "bills"
                 ],
-                "summary": "Update invoice item",
+                "summary": "Suggest transactions for a bill",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Invoice ID",
+                        "description": "Bill ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Item ID",
-                        "name": "itemId",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Updated line item contents",
-                        "name": "item",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.InvoiceItemInput"
-                        }
                     }
                 ],
                 "responses": {
@@ -2193,7 +2258,10 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.InvoiceItem"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/handlers.TransactionSuggestion"
+                                            }
                                         }
                                     }
                                 }
@@ -2237,35 +2305,30 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/bills/{id}/void": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Remove a line item from an invoice.",
+                "description": "Mark a bill as cancelled, keeping it for the record. A voided bill no longer counts towards payables and is listed only with include_cancelled=true. Bills with payment allocations cannot be voided until those links are removed.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "invoices"
+                    "bills"
                 ],
-                "summary": "Delete invoice item",
+                "summary": "Void bill",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Invoice ID",
+                        "description": "Bill ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Item ID",
-                        "name": "itemId",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
@@ -2280,10 +2343,25 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "object",
-                                            "additionalProperties": {
-                                                "type": "string"
-                                            }
+                                            "$ref": "#/definitions/models.Bill"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
                                         }
                                     }
                                 }
@@ -2307,32 +2385,55 @@ const docTemplate = `{
                                 }
                             ]
                         }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
                     }
                 }
             }
         },
-        "/invoices/{id}/links": {
+        "/categories": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get all payment transactions linked to a specific invoice.",
+                "description": "Get a list of all income and expense categories with the number of transactions and bills tagged with each.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "invoices"
+                    "categories"
                 ],
-                "summary": "Get invoice links",
+                "summary": "List categories",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Invoice ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "type": "string",
+                        "description": "Filter by type (expense, income)",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search by name",
+                        "name": "search",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -2349,7 +2450,7 @@ const docTemplate = `{
                                         "data": {
                                             "type": "array",
                                             "items": {
-                                                "$ref": "#/definitions/handlers.InvoiceLink"
+                                                "$ref": "#/definitions/models.Category"
                                             }
                                         }
                                     }
@@ -2358,35 +2459,38 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/invoices/{id}/match-suggestions": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Returns a ranked list of unallocated bank transactions that could match an invoice, scored by amount, date, and description similarity. Already-linked transactions are excluded.",
+                "description": "Add an income or expense category (type defaults to expense). Names are unique, ignoring case.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "invoices"
+                    "categories"
                 ],
-                "summary": "Suggest transactions for an invoice",
+                "summary": "Create category",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Invoice ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Category contents",
+                        "name": "category",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CategoryInput"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
                             "allOf": [
                                 {
@@ -2396,18 +2500,15 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/handlers.TransactionSuggestion"
-                                            }
+                                            "$ref": "#/definitions/models.Category"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "allOf": [
                                 {
@@ -2423,54 +2524,9 @@ const docTemplate = `{
                                 }
                             ]
                         }
-                    }
-                }
-            }
-        },
-        "/payouts": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get a list of all platform payouts (Swiggy, Zomato, Swiggy-Dineout).",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "payouts"
-                ],
-                "summary": "List payouts",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Filter by platform (Swiggy, Zomato, Swiggy-Dineout)",
-                        "name": "platform",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by outlet name",
-                        "name": "outlet_name",
-                        "in": "query"
                     },
-                    {
-                        "type": "string",
-                        "description": "Filter by settlement date from (YYYY-MM-DD)",
-                        "name": "from",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by settlement date to (YYYY-MM-DD)",
-                        "name": "to",
-                        "in": "query"
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "allOf": [
                                 {
@@ -2479,11 +2535,8 @@ const docTemplate = `{
                                 {
                                     "type": "object",
                                     "properties": {
-                                        "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/models.Payout"
-                                            }
+                                        "error": {
+                                            "type": "string"
                                         }
                                     }
                                 }
@@ -2491,38 +2544,35 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/categories/{id}": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a new platform payout record.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get details of a specific category.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "payouts"
+                    "categories"
                 ],
-                "summary": "Create payout",
+                "summary": "Get category",
                 "parameters": [
                     {
-                        "description": "Payout contents",
-                        "name": "payout",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.PayoutInput"
-                        }
+                        "type": "integer",
+                        "description": "Category ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "allOf": [
                                 {
@@ -2532,7 +2582,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.Payout"
+                                            "$ref": "#/definitions/models.Category"
                                         }
                                     }
                                 }
@@ -2556,52 +2606,6 @@ const docTemplate = `{
                                 }
                             ]
                         }
-                    }
-                }
-            }
-        },
-        "/payouts/{id}": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get details of a specific platform payout.",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "payouts"
-                ],
-                "summary": "Get payout",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Payout ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/handlers.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/models.Payout"
-                                        }
-                                    }
-                                }
-                            ]
-                        }
                     },
                     "404": {
                         "description": "Not Found",
@@ -2629,7 +2633,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update details of an existing platform payout record.",
+                "description": "Rename a category or change its type. A category still tagged on transactions or bills keeps its type.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2637,24 +2641,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "payouts"
+                    "categories"
                 ],
-                "summary": "Update payout",
+                "summary": "Update category",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Payout ID",
+                        "description": "Category ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Updated payout contents",
-                        "name": "payout",
+                        "description": "Updated category contents",
+                        "name": "category",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.PayoutInput"
+                            "$ref": "#/definitions/models.CategoryInput"
                         }
                     }
                 ],
@@ -2670,7 +2674,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.Payout"
+                                            "$ref": "#/definitions/models.Category"
                                         }
                                     }
                                 }
@@ -2712,6 +2716,24 @@ const docTemplate = `{
                                 }
                             ]
                         }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
                     }
                 }
             },
@@ -2721,18 +2743,18 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Remove a platform payout record.",
+                "description": "Remove a category. Categories still tagged on transactions or bills cannot be deleted.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "payouts"
+                    "categories"
                 ],
-                "summary": "Delete payout",
+                "summary": "Delete category",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Payout ID",
+                        "description": "Category ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -2760,6 +2782,24 @@ const docTemplate = `{
                             ]
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -2777,37 +2817,9 @@ const docTemplate = `{
                                 }
                             ]
                         }
-                    }
-                }
-            }
-        },
-        "/payouts/{id}/links": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get all payment transactions linked to a specific payout.",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "payouts"
-                ],
-                "summary": "Get payout links",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Payout ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    },
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "allOf": [
                                 {
@@ -2816,11 +2828,8 @@ const docTemplate = `{
                                 {
                                     "type": "object",
                                     "properties": {
-                                        "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/handlers.PayoutLink"
-                                            }
+                                        "error": {
+                                            "type": "string"
                                         }
                                     }
                                 }
@@ -2830,28 +2839,27 @@ const docTemplate = `{
                 }
             }
         },
-        "/payouts/{id}/match-suggestions": {
+        "/changes": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Returns a ranked list of unallocated bank transactions that could match a payout, scored by amount, date, and UTR/description similarity. Already-linked transactions are excluded.",
+                "description": "Accounts, contacts, outlets, categories, bills, invoices, transactions, payouts, and recurring payments created or updated after since, grouped by type, plus tombstones of records deleted after it.\nLinking a payment to a document, or removing the link, counts as a change to the transaction, the document, and its contact's balance.\nWithout since, every record is returned. Pass the returned server_time as since on the next call. Records are stamped when their write began, so\nreading starts CHANGES_OVERLAP (default 1m) before since, to catch writes that were still committing when server_time was read; records changed in\nthat window are returned again, so clients should apply changes idempotently. A write that takes longer than the window to commit can be missed.\nTombstones are kept for DELETIONS_RETENTION_DAYS (default 90) and then dropped by POST /admin/purge; a client whose since is older than that must do a full sync.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "payouts"
+                    "changes"
                 ],
-                "summary": "Suggest transactions for a payout",
+                "summary": "List changes since",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Payout ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "type": "string",
+                        "description": "RFC 3339 timestamp, e.g. a previous server_time",
+                        "name": "since",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -2866,18 +2874,15 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/handlers.TransactionSuggestion"
-                                            }
+                                            "$ref": "#/definitions/handlers.Changes"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "allOf": [
                                 {
@@ -2897,38 +2902,75 @@ const docTemplate = `{
                 }
             }
         },
-        "/recurring-payments": {
+        "/config": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get a list of all scheduled recurring payments (income or expense).",
+                "description": "Get the books currency and the number of decimal places amounts are kept to. Every amount in the API is an integer in its currency's smallest unit:\ndivide it by 10^minor_units, or by 10^currency_minor_units[currency] for the currencies listed there, to get whole units.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "recurring_payments"
+                    "config"
                 ],
-                "summary": "List recurring payments",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Filter by status (active, paused, cancelled, completed)",
-                        "name": "status",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Filter by account",
-                        "name": "account_id",
+                "summary": "Get client configuration",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handlers.ClientConfig"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/contacts": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a list of all vendors and customers with financial summaries. Balances are read from a per-contact cache that bill, invoice, and payment link writes keep current; pass live=true to sum them from the documents instead.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "contacts"
+                ],
+                "summary": "List contacts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by type (vendor/customer)",
+                        "name": "type",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Filter by type (income, expense)",
-                        "name": "type",
+                        "description": "Search by name, email, or phone",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Sum balances from documents instead of reading the cached balances",
+                        "name": "live",
                         "in": "query"
                     }
                 ],
@@ -2946,7 +2988,7 @@ const docTemplate = `{
                                         "data": {
                                             "type": "array",
                                             "items": {
-                                                "$ref": "#/definitions/models.RecurringPayment"
+                                                "$ref": "#/definitions/models.Contact"
                                             }
                                         }
                                     }
@@ -2962,7 +3004,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a new scheduled recurring payment.",
+                "description": "Create a new vendor or customer.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2970,17 +3012,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "recurring_payments"
+                    "contacts"
                 ],
-                "summary": "Create recurring payment",
+                "summary": "Create contact",
                 "parameters": [
                     {
-                        "description": "Recurring payment details",
-                        "name": "recurring_payment",
+                        "description": "Contact contents",
+                        "name": "contact",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.RecurringPaymentInput"
+                            "$ref": "#/definitions/models.ContactInput"
                         }
                     }
                 ],
@@ -2996,7 +3038,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.RecurringPayment"
+                                            "$ref": "#/definitions/models.Contact"
                                         }
                                     }
                                 }
@@ -3024,28 +3066,43 @@ const docTemplate = `{
                 }
             }
         },
-        "/recurring-payments/{id}": {
-            "get": {
+        "/contacts/import": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get details of a specific recurring payment.",
+                "description": "Create contacts from a CSV file, sent as the request body or as the \"file\" field of a multipart form. The header row must have a name column;\ntype, email, phone, gstin, and pan columns are optional and detected from common names (e.g. \"Mobile No\" is phone). Each row is validated as\nfor POST /contacts. A row matching an existing contact by name (ignoring case) and type is skipped, or with duplicates=merge fills in the\nfields the existing contact lacks. All rows are written in one transaction; with all_or_nothing=true a file with any invalid row imports\nnothing and gets 422 listing the invalid rows, otherwise invalid rows are reported and the rest imported.",
+                "consumes": [
+                    "text/csv",
+                    "multipart/form-data"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "recurring_payments"
+                    "contacts"
                 ],
-                "summary": "Get recurring payment",
+                "summary": "Import contacts",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Recurring Payment ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "type": "string",
+                        "description": "Type for rows without one: vendor or customer",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "skip (default) or merge",
+                        "name": "duplicates",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Import nothing if any row is invalid",
+                        "name": "all_or_nothing",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -3060,15 +3117,33 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.RecurringPayment"
+                                            "$ref": "#/definitions/handlers.ContactImportResult"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
                         "schema": {
                             "allOf": [
                                 {
@@ -3077,6 +3152,9 @@ const docTemplate = `{
                                 {
                                     "type": "object",
                                     "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handlers.ContactImportResult"
+                                        },
                                         "error": {
                                             "type": "string"
                                         }
@@ -3086,40 +3164,30 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/contacts/{id}": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update details of an existing recurring payment.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get details and financial summary of a specific contact.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "recurring_payments"
+                    "contacts"
                 ],
-                "summary": "Update recurring payment",
+                "summary": "Get contact",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Recurring Payment ID",
+                        "description": "Contact ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Updated recurring payment details",
-                        "name": "recurring_payment",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.RecurringPaymentInput"
-                        }
                     }
                 ],
                 "responses": {
@@ -3134,7 +3202,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.RecurringPayment"
+                                            "$ref": "#/definitions/models.Contact"
                                         }
                                     }
                                 }
@@ -3169,8 +3237,14 @@ const docTemplate = `{
                                 {
                                     "type": "object",
                                     "properties": {
+                                        "code": {
+                                            "type": "string"
+                                        },
                                         "error": {
                                             "type": "string"
+                                        },
+                                        "resource": {
+                                            "type": "string"
                                         }
                                     }
                                 }
@@ -3179,27 +3253,45 @@ const docTemplate = `{
                     }
                 }
             },
-            "delete": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Remove a recurring payment.",
+                "description": "Update details of an existing contact. Changing the type is rejected with 409 while\nthe contact has documents of the old type (bills for a vendor, invoices for a customer),\nunless force=true is passed.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "recurring_payments"
+                    "contacts"
                 ],
-                "summary": "Delete recurring payment",
+                "summary": "Update contact",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Recurring Payment ID",
+                        "description": "Contact ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Change the type even if incompatible documents exist",
+                        "name": "force",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Updated contact contents",
+                        "name": "contact",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ContactInput"
+                        }
                     }
                 ],
                 "responses": {
@@ -3214,10 +3306,25 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "object",
-                                            "additionalProperties": {
-                                                "type": "string"
-                                            }
+                                            "$ref": "#/definitions/models.Contact"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
                                         }
                                     }
                                 }
@@ -3241,37 +3348,9 @@ const docTemplate = `{
                                 }
                             ]
                         }
-                    }
-                }
-            }
-        },
-        "/recurring-payments/{id}/links": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get all payment transactions linked to a specific recurring payment, including the due date and status of the occurrence each transaction covers.",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "recurring_payments"
-                ],
-                "summary": "Get recurring payment links",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Recurring Payment ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    },
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "allOf": [
                                 {
@@ -3283,8 +3362,11 @@ const docTemplate = `{
                                         "data": {
                                             "type": "array",
                                             "items": {
-                                                "$ref": "#/definitions/handlers.RecurringPaymentLink"
+                                                "$ref": "#/definitions/handlers.ContactDocument"
                                             }
+                                        },
+                                        "error": {
+                                            "type": "string"
                                         }
                                     }
                                 }
@@ -3292,27 +3374,25 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/recurring-payments/{id}/match-suggestions": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Returns a ranked list of bank transactions that could correspond to a recurring payment occurrence, scored by amount (±2% tolerance), date proximity to next_due_date, and description/reference similarity. Excludes transactions already linked to this recurring payment.",
+                "description": "Remove a contact.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "recurring_payments"
+                    "contacts"
                 ],
-                "summary": "Suggest transactions for a recurring payment",
+                "summary": "Delete contact",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Recurring Payment ID",
+                        "description": "Contact ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -3330,9 +3410,9 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/handlers.TransactionSuggestion"
+                                            "type": "object",
+                                            "additionalProperties": {
+                                                "type": "string"
                                             }
                                         }
                                     }
@@ -3340,8 +3420,8 @@ const docTemplate = `{
                             ]
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "allOf": [
                                 {
@@ -3357,38 +3437,50 @@ const docTemplate = `{
                                 }
                             ]
                         }
-                    }
-                }
-            }
-        },
-        "/recurring-payments/{id}/occurrences": {
-            "get": {
-                "security": [
-                    {
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/contacts/{id}/delete-impact": {
+            "get": {
+                "security": [
+                    {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Returns all scheduled occurrences (instances) for a recurring payment. Occurrences are auto-generated by the server for each due date up to today. Each occurrence tracks whether it has been paid via a linked bank transaction.",
+                "description": "Count the bills, invoices, transactions, and recurring payments that reference the contact. Deletion does not cascade, so these\nrows would be left pointing at a missing contact; use the counts to confirm before deleting.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "recurring_payments"
+                    "contacts"
                 ],
-                "summary": "List recurring payment occurrences",
+                "summary": "Preview contact deletion",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Recurring Payment ID",
+                        "description": "Contact ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by status (pending, paid, skipped)",
-                        "name": "status",
-                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -3403,10 +3495,25 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/models.RecurringPaymentOccurrence"
-                                            }
+                                            "$ref": "#/definitions/handlers.ContactDeleteImpact"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
                                         }
                                     }
                                 }
@@ -3423,8 +3530,14 @@ const docTemplate = `{
                                 {
                                     "type": "object",
                                     "properties": {
+                                        "code": {
+                                            "type": "string"
+                                        },
                                         "error": {
                                             "type": "string"
+                                        },
+                                        "resource": {
+                                            "type": "string"
                                         }
                                     }
                                 }
@@ -3434,32 +3547,51 @@ const docTemplate = `{
                 }
             }
         },
-        "/transactions": {
+        "/contacts/{id}/documents": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get a list of all bank transactions (income, expense, transfer) with allocation info.",
+                "description": "Get a contact together with their bills (vendors) or invoices (customers), including status and outstanding amounts, and the transactions tagged to them.\nstatus, from, and to filter the documents as on /bills and /invoices; from and to also filter transactions by date.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "transactions"
+                    "contacts"
                 ],
-                "summary": "List transactions",
+                "summary": "Get contact documents",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Filter by account",
-                        "name": "account_id",
+                        "description": "Contact ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter bills/invoices by status",
+                        "name": "status",
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "Filter by contact",
-                        "name": "contact_id",
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include cancelled bills/invoices (excluded by default)",
+                        "name": "include_cancelled",
                         "in": "query"
                     }
                 ],
@@ -3475,10 +3607,43 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/models.Transaction"
-                                            }
+                                            "$ref": "#/definitions/handlers.ContactDocuments"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
                                         }
                                     }
                                 }
@@ -3486,38 +3651,35 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/contacts/{id}/payments": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a new bank transaction (income, expense, or transfer).",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "List every transaction link to the contact's bills and invoices, oldest transaction first, with the document's number and date and the transaction's date, type, and account.\nA contact that is both a customer and a vendor gets one history covering both.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "transactions"
+                    "contacts"
                 ],
-                "summary": "Create transaction",
+                "summary": "Get contact payments",
                 "parameters": [
                     {
-                        "description": "Transaction contents",
-                        "name": "transaction",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.TransactionInput"
-                        }
+                        "type": "integer",
+                        "description": "Contact ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "allOf": [
                                 {
@@ -3527,7 +3689,46 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.Transaction"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/handlers.ContactPayment"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
                                         }
                                     }
                                 }
@@ -3537,28 +3738,27 @@ const docTemplate = `{
                 }
             }
         },
-        "/transactions/{id}": {
+        "/dashboard": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get details and allocation status of a specific transaction.",
+                "description": "Get totals for accounts, contacts, bills, invoices, and recent transactions, plus bill and invoice counts, amounts, and allocations per status.\nreconciliation lists, per account, the count and total of transactions not yet reconciled and the transaction date of the latest reconciled one.\nResults are cached in memory for DASHBOARD_CACHE_TTL (default 30s) and refreshed on any write.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "transactions"
+                    "dashboard"
                 ],
-                "summary": "Get transaction",
+                "summary": "Get dashboard",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Transaction ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "type": "boolean",
+                        "description": "Count cancelled bills and invoices in payables/receivables",
+                        "name": "include_cancelled",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -3573,15 +3773,34 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.Transaction"
+                                            "$ref": "#/definitions/store.DashboardData"
                                         }
                                     }
                                 }
                             ]
                         }
-                    },
-                    "404": {
-                        "description": "Not Found",
+                    }
+                }
+            }
+        },
+        "/defaults": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get every configured default. default_account.\u003ctype\u003e holds the id of the account used when a transaction of that type is created without account_id.\npayout.clearing_account holds the account POST /payouts/{id}/post books through, and payout.category.\u003centry\u003e the category it tags the gross, discount, commission, taxes, ads, or variance entry with.\nbusiness.gstin holds the GSTIN the business files GST returns under.\nfx_rate.\u003cCURRENCY\u003e holds how many units of the books currency one unit of CURRENCY is worth, for GET /reports/fx.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "defaults"
+                ],
+                "summary": "List defaults",
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "allOf": [
                                 {
@@ -3590,8 +3809,11 @@ const docTemplate = `{
                                 {
                                     "type": "object",
                                     "properties": {
-                                        "error": {
-                                            "type": "string"
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.Default"
+                                            }
                                         }
                                     }
                                 }
@@ -3599,14 +3821,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/defaults/{key}": {
             "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update details of an existing transaction.",
+                "description": "Set the value of a default, replacing any previous value. For default_account.\u003ctype\u003e and payout.clearing_account the value must be the id of an existing account;\nfor payout.category.\u003centry\u003e the id of an existing category, an income one for gross and an expense one for the other entries; for business.gstin a valid GSTIN; for fx_rate.\u003cCURRENCY\u003e, e.g. fx_rate.USD, a positive exchange rate in books-currency units per unit.",
                 "consumes": [
                     "application/json"
                 ],
@@ -3614,24 +3838,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "transactions"
+                    "defaults"
                 ],
-                "summary": "Update transaction",
+                "summary": "Set default",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Transaction ID",
-                        "name": "id",
+                        "type": "string",
+                        "description": "Default key, e.g. default_account.expense",
+                        "name": "key",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Updated transaction contents",
-                        "name": "transaction",
+                        "description": "Default value",
+                        "name": "default",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.TransactionInput"
+                            "$ref": "#/definitions/models.DefaultInput"
                         }
                     }
                 ],
@@ -3647,15 +3871,15 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.Transaction"
+                                            "$ref": "#/definitions/models.Default"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "allOf": [
                                 {
@@ -3680,19 +3904,19 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Remove a transaction.",
+                "description": "Clear a default.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "transactions"
+                    "defaults"
                 ],
-                "summary": "Delete transaction",
+                "summary": "Delete default",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Transaction ID",
-                        "name": "id",
+                        "type": "string",
+                        "description": "Default key",
+                        "name": "key",
                         "in": "path",
                         "required": true
                     }
@@ -3740,92 +3964,91 @@ const docTemplate = `{
                 }
             }
         },
-        "/transactions/{id}/auto-match": {
-            "post": {
+        "/events": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Finds the best matching bill, invoice, or payout for a bank statement entry and automatically creates a transaction link when confidence is at least 0.7. Returns the created link on success, or the top suggestion without linking when confidence is below the threshold.",
+                "description": "Server-sent event stream that emits a JSON payload {type, resource, id} whenever a transaction, bill, invoice, payout, account, or contact is created, updated, or deleted. A comment heartbeat is sent periodically. Browsers using EventSource may pass the token as the access_token query parameter.",
                 "produces": [
-                    "application/json"
+                    "text/event-stream"
                 ],
                 "tags": [
-                    "transactions"
+                    "events"
                 ],
-                "summary": "Auto-match a transaction to a document",
+                "summary": "Stream change events",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Transaction ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "type": "string",
+                        "description": "Bearer token, for clients that cannot set headers",
+                        "name": "access_token",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/handlers.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/handlers.AutoMatchResult"
-                                        }
-                                    }
-                                }
-                            ]
-                        }
-                    },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/handlers.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "error": {
-                                            "type": "string"
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/handlers.Event"
                         }
                     }
                 }
             }
         },
-        "/transactions/{id}/links": {
+        "/invoices": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get all bills and invoices linked (paid) by this transaction.",
+                "description": "Get a list of all receivable invoices, with current status and allocation info.\nSend Accept: text/csv for the same list as CSV.",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/csv"
                 ],
                 "tags": [
-                    "transactions"
+                    "invoices"
                 ],
-                "summary": "List transaction links",
+                "summary": "List invoices",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Transaction ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Filter by contact (customer)",
+                        "name": "contact_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by issue date from (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by issue date to (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search by invoice number, notes, or customer name",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include cancelled invoices (excluded by default)",
+                        "name": "include_cancelled",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "CSV amounts as rupees (default), rupees_grouped, or paise",
+                        "name": "amount_format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -3842,13 +4065,31 @@ const docTemplate = `{
                                         "data": {
                                             "type": "array",
                                             "items": {
-                                                "$ref": "#/definitions/models.TransactionDocument"
+                                                "$ref": "#/definitions/models.Invoice"
                                             }
                                         }
                                     }
                                 }
                             ]
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
                     }
                 }
             },
@@ -3858,7 +4099,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Allocate an amount from a transaction to a specific bill or invoice.",
+                "description": "Create a new receivable invoice. When the invoice would take the customer's outstanding balance above its\ncredit_limit it is created with a warning and the balance and limit under credit, or, with\nCREDIT_LIMIT_BLOCK=true, refused with 422.\nAn invoice with items takes its amount from them: each item's amount (quantity times unit_price when omitted) includes its GST.\nWhen an item has its own tax_rate, items without one take the invoice's, tax_amount is the sum of the items' GST, and the\ninvoice's tax_rate is their common rate, or null when they differ; otherwise the invoice's tax_rate applies to the total.",
                 "consumes": [
                     "application/json"
                 ],
@@ -3866,24 +4107,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "transactions"
+                    "invoices"
                 ],
-                "summary": "Create transaction link",
+                "summary": "Create invoice",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Transaction ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Link details",
-                        "name": "link",
+                        "description": "Invoice contents",
+                        "name": "invoice",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.TransactionDocumentInput"
+                            "$ref": "#/definitions/models.InvoiceInput"
                         }
                     }
                 ],
@@ -3899,7 +4133,43 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.TransactionDocument"
+                                            "$ref": "#/definitions/handlers.InvoiceCreateResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
                                         }
                                     }
                                 }
@@ -3909,35 +4179,27 @@ const docTemplate = `{
                 }
             }
         },
-        "/transactions/{id}/links/{linkId}": {
-            "delete": {
+        "/invoices/due-soon": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Deallocate an amount from a transaction to a bill or invoice.",
+                "description": "List sent and partly paid invoices due between today and days from now that still have an outstanding amount, grouped by customer with\nthe customer's email, phone, and total outstanding, for follow-up before they become overdue. Customers with the soonest due invoice come first.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "transactions"
+                    "invoices"
                 ],
-                "summary": "Delete transaction link",
+                "summary": "List invoices due soon",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Transaction ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Link ID",
-                        "name": "linkId",
-                        "in": "path",
-                        "required": true
+                        "description": "How many days ahead to look (default 7)",
+                        "name": "days",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -3952,38 +4214,56 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "object",
-                                            "additionalProperties": {
-                                                "type": "string"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/handlers.DueSoonCustomer"
                                             }
                                         }
                                     }
                                 }
                             ]
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
                     }
                 }
             }
         },
-        "/transactions/{id}/match-suggestions": {
+        "/invoices/{id}": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Returns a ranked list of bills, invoices, payouts, and recurring payments that could match a bank statement entry, scored by amount, date, and description similarity. Expense transactions match against bills; income transactions match against invoices and payouts. Recurring payments are suggested for both types as informational matches (linkable=false).",
+                "description": "Get details and allocation status of a specific invoice.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "transactions"
+                    "invoices"
                 ],
-                "summary": "Suggest document matches for a transaction",
+                "summary": "Get invoice",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Transaction ID",
+                        "description": "Invoice ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -4001,10 +4281,25 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/handlers.MatchSuggestion"
-                                            }
+                                            "$ref": "#/definitions/models.Invoice"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
                                         }
                                     }
                                 }
@@ -4021,8 +4316,14 @@ const docTemplate = `{
                                 {
                                     "type": "object",
                                     "properties": {
+                                        "code": {
+                                            "type": "string"
+                                        },
                                         "error": {
                                             "type": "string"
+                                        },
+                                        "resource": {
+                                            "type": "string"
                                         }
                                     }
                                 }
@@ -4030,168 +4331,9131 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "handlers.AutoMatchResult": {
-            "type": "object",
-            "properties": {
-                "link": {
-                    "$ref": "#/definitions/models.TransactionDocument"
-                },
-                "matched": {
-                    "type": "boolean"
-                },
-                "suggestion": {
-                    "$ref": "#/definitions/handlers.MatchSuggestion"
-                }
-            }
-        },
-        "handlers.BillLink": {
-            "type": "object",
-            "properties": {
-                "account_name": {
-                    "type": "string"
-                },
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update details of an existing invoice. Items replace the stored ones; omitting items keeps them, and the amount\nand tax are still worked out from them as on create. An empty items list removes them.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invoices"
+                ],
+                "summary": "Update invoice",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Invoice ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated invoice contents",
+                        "name": "invoice",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.InvoiceInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Invoice"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove an invoice.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invoices"
+                ],
+                "summary": "Delete invoice",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Invoice ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/invoices/{id}/clone": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Copy an invoice and its line items into a new draft issued today. The number continues the original's sequence\n(INV-0042 becomes the next free INV-00NN), the due date keeps the original's payment term, and the copy starts unsent with no allocations.\nThe copy's amount is checked against the customer's credit_limit as on POST /invoices: over the limit it is created with a\nwarning, or, with CREDIT_LIMIT_BLOCK=true, refused with 422.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invoices"
+                ],
+                "summary": "Clone invoice",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Invoice ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handlers.InvoiceCreateResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/invoices/{id}/einvoice-json": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Assemble the invoice in the IRP e-invoice schema (version 1.1) for an e-invoice generator: seller and buyer\nGSTINs, the items with their share of the taxable value and CGST/SGST or IGST, and the totals, in rupees.\nItems with their own tax_rate are taxed at it. The seller's GSTIN is read from the business.gstin default. Mandatory fields\nthe books do not hold, such as addresses and any item's missing hsn_code, are left empty and listed under missing; irn is null until IRN tracking exists.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invoices"
+                ],
+                "summary": "Get invoice e-invoice JSON",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Invoice ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handlers.EInvoiceExport"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "code": {
+                                            "type": "string"
+                                        },
+                                        "error": {
+                                            "type": "string"
+                                        },
+                                        "resource": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/invoices/{id}/items": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get all line items for a specific invoice.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invoices"
+                ],
+                "summary": "List invoice items",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Invoice ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.InvoiceItem"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add a new line item to an existing invoice. The invoice's amount and tax are worked out again from its items, and its\nstatus from the new amount. When the rise in amount takes the customer above its credit_limit the item is added with\na warning, or, with CREDIT_LIMIT_BLOCK=true, refused with 422, as on POST /invoices.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invoices"
+                ],
+                "summary": "Create invoice item",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Invoice ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Line item contents",
+                        "name": "item",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.InvoiceItemInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handlers.InvoiceItemResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/invoices/{id}/items/{itemId}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update an existing line item in an invoice. The invoice's amount, tax, and status are worked out again, and a rise\nin amount is checked against the customer's credit limit, as on create.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invoices"
+                ],
+                "summary": "Update invoice item",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Invoice ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Item ID",
+                        "name": "itemId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated line item contents",
+                        "name": "item",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.InvoiceItemInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handlers.InvoiceItemResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove a line item from an invoice. The invoice's amount and tax are worked out again from the items left, and its\nstatus from the new amount; removing the last item keeps the amount.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invoices"
+                ],
+                "summary": "Delete invoice item",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Invoice ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Item ID",
+                        "name": "itemId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/invoices/{id}/ledger": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Chronological history of an invoice: its creation on the issue date, its last send, and each transaction allocated to it, by transaction date, with the outstanding balance after each entry.\nAllocations from offsets and adjustments are reported as adjustment entries, other allocations as payments.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invoices"
+                ],
+                "summary": "Get invoice ledger",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Invoice ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handlers.InvoiceLedger"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "code": {
+                                            "type": "string"
+                                        },
+                                        "error": {
+                                            "type": "string"
+                                        },
+                                        "resource": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/invoices/{id}/links": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get payment transactions linked to a specific invoice, oldest first unless order=desc. Supports limit/offset pagination; totals across all links are returned in headers.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invoices"
+                ],
+                "summary": "Get invoice links",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Invoice ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of links to return (default DEFAULT_PAGE_SIZE, or all; at most MAX_PAGE_SIZE)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of links to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort by created_at: asc (default) or desc",
+                        "name": "order",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/handlers.InvoiceLink"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "X-Page-Size-Clamped": {
+                                "type": "int",
+                                "description": "Limit applied when the requested page exceeded MAX_PAGE_SIZE"
+                            },
+                            "X-Total-Allocated": {
+                                "type": "int",
+                                "description": "Total allocated across all links, in paise"
+                            },
+                            "X-Total-Count": {
+                                "type": "int",
+                                "description": "Total number of links"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/invoices/{id}/match-suggestions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns a ranked list of unallocated bank transactions that could match an invoice, scored by amount, date, and description similarity. Already-linked transactions are excluded.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invoices"
+                ],
+                "summary": "Suggest transactions for an invoice",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Invoice ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/handlers.TransactionSuggestion"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/invoices/{id}/send": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Record that the invoice was sent to the customer: a draft moves to sent and sent_at is set to now.\nRe-sending a sent, partial, or overdue invoice keeps its status and refreshes sent_at. Paid, received, and cancelled invoices cannot be sent.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invoices"
+                ],
+                "summary": "Send invoice",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Invoice ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Invoice"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/invoices/{id}/void": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mark an invoice as cancelled, keeping it for the record. A voided invoice no longer counts towards receivables and is listed only with include_cancelled=true. Invoices with payment allocations cannot be voided until those links are removed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invoices"
+                ],
+                "summary": "Void invoice",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Invoice ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Invoice"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/offsets": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Settle a bill and an invoice against each other without moving cash, for a party that is both a vendor and a customer.\nA synthetic offset transaction dated date (required) is recorded under account_id (its balance is unchanged) and linked to both\ndocuments for amount, which defaults to the smaller unallocated amount. The bill and invoice must belong to the same contact, or to\ncontacts with the same PAN (from the GSTIN when no PAN is recorded); a contact with neither is matched by name.\nThe account must be in BOOKS_CURRENCY. Delete the offset transaction to undo it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Create offset",
+                "parameters": [
+                    {
+                        "description": "Documents to offset",
+                        "name": "offset",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.OffsetInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handlers.OffsetResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/outlets": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a list of all outlets with the number of payouts recorded for each.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "outlets"
+                ],
+                "summary": "List outlets",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search by name",
+                        "name": "search",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.Outlet"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Register a new outlet. Names are unique, ignoring case.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "outlets"
+                ],
+                "summary": "Create outlet",
+                "parameters": [
+                    {
+                        "description": "Outlet contents",
+                        "name": "outlet",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.OutletInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Outlet"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/outlets/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get details of a specific outlet.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "outlets"
+                ],
+                "summary": "Get outlet",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Outlet ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Outlet"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Rename an outlet. The outlet_name shown on its payouts is updated too.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "outlets"
+                ],
+                "summary": "Update outlet",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Outlet ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated outlet contents",
+                        "name": "outlet",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.OutletInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Outlet"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove an outlet. Outlets that still have payouts cannot be deleted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "outlets"
+                ],
+                "summary": "Delete outlet",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Outlet ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/payouts": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a list of all platform payouts (Swiggy, Zomato, Swiggy-Dineout).\nSend Accept: text/csv for the same list as CSV.",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "payouts"
+                ],
+                "summary": "List payouts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by platform (Swiggy, Zomato, Swiggy-Dineout)",
+                        "name": "platform",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by outlet",
+                        "name": "outlet_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by outlet name",
+                        "name": "outlet_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by settlement date from (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by settlement date to (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only payouts not yet fully allocated to bank credits (within ALLOCATION_TOLERANCE_PAISE)",
+                        "name": "unmatched",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "CSV amounts as rupees (default), rupees_grouped, or paise",
+                        "name": "amount_format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.Payout"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new platform payout record. The outlet is given by outlet_id, which must exist, or by outlet_name, which is matched case-insensitively against existing outlets. A name with no match is refused with 400 unless create_outlet is true, in which case it is registered as a new outlet.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payouts"
+                ],
+                "summary": "Create payout",
+                "parameters": [
+                    {
+                        "description": "Payout contents",
+                        "name": "payout",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PayoutInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Payout"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/payouts/import": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create payouts from a platform settlement report, sent as the request body or as the \"file\" field of a multipart form, as CSV or XLSX (told apart by content).\nFor swiggy, the weekly settlement export of the partner portal: the outlet, payout period, settlement date, orders, gross sales, restaurant discount,\ncommission, TCS and TDS (added up), ads, net payout, and UTR columns are detected from common names. For zomato, the payout annexure, whose order rows\nare merged into one payout per outlet per payout cycle (or payout date and UTR), dated by the first and last order without cycle columns; a payout with\nan invalid order row is not imported. outlet_name names the outlet of a report without an outlet column. Outlets are found by name, ignoring case;\na row naming an unknown outlet is reported as an error unless create_outlets is true, in which case the outlet is registered. A payout already\nrecorded on the platform, matched by UTR or without one by outlet, period, and settlement date, is skipped, so re-importing a report is safe.\nInvalid rows are reported and the rest imported.",
+                "consumes": [
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payouts"
+                ],
+                "summary": "Import payouts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Platform of the report: swiggy or zomato",
+                        "name": "platform",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Outlet for a report without an outlet column",
+                        "name": "outlet_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Register outlets not already known",
+                        "name": "create_outlets",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handlers.PayoutImportResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/payouts/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get details of a specific platform payout, including the payments allocated to it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payouts"
+                ],
+                "summary": "Get payout",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Payout ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handlers.PayoutDetail"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "code": {
+                                            "type": "string"
+                                        },
+                                        "error": {
+                                            "type": "string"
+                                        },
+                                        "resource": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update details of an existing platform payout record. The outlet is resolved as on create.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payouts"
+                ],
+                "summary": "Update payout",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Payout ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated payout contents",
+                        "name": "payout",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PayoutInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Payout"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove a platform payout record.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payouts"
+                ],
+                "summary": "Delete payout",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Payout ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/payouts/{id}/auto-match": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Scans unreconciled income transactions on bank accounts in BOOKS_CURRENCY for an unallocated amount equal to the payout's unallocated amount (within ALLOCATION_TOLERANCE_PAISE), dated within 7 days of the settlement date. When exactly one candidate is found it is linked to the payout and marked reconciled; otherwise the candidates are returned for manual choice.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payouts"
+                ],
+                "summary": "Auto-match a payout to a bank credit",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Payout ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handlers.PayoutAutoMatchResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/payouts/{id}/links": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get payment transactions linked to a specific payout, oldest first unless order=desc. Supports limit/offset pagination; totals across all links are returned in headers.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payouts"
+                ],
+                "summary": "Get payout links",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Payout ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of links to return (default DEFAULT_PAGE_SIZE, or all; at most MAX_PAGE_SIZE)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of links to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort by created_at: asc (default) or desc",
+                        "name": "order",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/handlers.PayoutLink"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "X-Page-Size-Clamped": {
+                                "type": "int",
+                                "description": "Limit applied when the requested page exceeded MAX_PAGE_SIZE"
+                            },
+                            "X-Total-Allocated": {
+                                "type": "int",
+                                "description": "Total allocated across all links, in paise"
+                            },
+                            "X-Total-Count": {
+                                "type": "int",
+                                "description": "Total number of links"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/payouts/{id}/match-suggestions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns a ranked list of unallocated bank transactions that could match a payout, scored by amount, date, and UTR/description similarity. Already-linked transactions are excluded.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payouts"
+                ],
+                "summary": "Suggest transactions for a payout",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Payout ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/handlers.TransactionSuggestion"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/payouts/{id}/orders": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the per-order detail uploaded for a payout, by order date, with checks of the order sums against the payout's gross_sales_amt, platform_commission_amt, and final_payout_amt.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payouts"
+                ],
+                "summary": "List payout orders",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Payout ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handlers.PayoutOrders"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace the per-order detail of a payout with the given orders. The sums of the orders' gross, commission, and net amounts must match the payout's gross_sales_amt, platform_commission_amt, and final_payout_amt within PAYOUT_ORDER_TOLERANCE_PAISE (in the smallest unit, default one whole unit, e.g. 100 paise); otherwise nothing is stored and 422 is returned with the failed checks.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payouts"
+                ],
+                "summary": "Upload payout orders",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Payout ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Orders settled by the payout",
+                        "name": "orders",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PayoutOrdersInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handlers.PayoutOrders"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.PayoutOrderCheck"
+                                            }
+                                        },
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/payouts/{id}/post": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Record the payout's gross sales as income and its restaurant discount, platform commission, TCS/TDS, and marketing charges as expenses on a clearing account, dated on the settlement date.\nThe bank credit becomes the income leg of a transfer of the net from the clearing account, so the clearing account nets to zero and the bank balance is unchanged; it is linked to the payout for the net if not already.\nclearing_account_id defaults to the payout.clearing_account default and bank_transaction_id to the one transaction linked to the payout. The bank credit must be an income, not a transfer, of exactly the final payout amount\nless any recorded variance, which is booked as a further expense, and the gross less the deductions must equal the final payout amount. All entries are written in one transaction, and a payout can be posted once.\nA bank credit that is reconciled or locked is refused with 409.\nEach entry is tagged with the category its payout.category.\u003centry\u003e default names, e.g. payout.category.commission; entries without one are left untagged.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payouts"
+                ],
+                "summary": "Post a payout to the ledger",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Payout ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Accounts to post with",
+                        "name": "posting",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PayoutPostInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handlers.PayoutPosting"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/payouts/{id}/settle": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Record the shortfall between the payout's final amount and the transactions linked to it, such as a platform adjustment or chargeback, with the reason,\nso the payout stops reading as partially received. variance_amount defaults to the unallocated amount and must equal it within ALLOCATION_TOLERANCE_PAISE;\n0 clears a recorded variance. A posted payout can no longer be changed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payouts"
+                ],
+                "summary": "Settle a payout with a variance",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Payout ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Variance to record",
+                        "name": "variance",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PayoutSettleInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Payout"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/periods": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get all period closes, latest first. Dates on or before the latest closed_through are locked.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "periods"
+                ],
+                "summary": "List closed periods",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.ClosedPeriod"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/periods/close": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Close the books up to and including the given date. Transactions dated in a closed period can no longer be created, updated, or deleted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "periods"
+                ],
+                "summary": "Close period",
+                "parameters": [
+                    {
+                        "description": "Closing date",
+                        "name": "period",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PeriodInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.ClosedPeriod"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/periods/reopen": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Reopen the given date and everything after it; earlier dates stay closed. When ADMIN_API_KEY is configured the request must carry it in the X-Admin-Key header.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "periods"
+                ],
+                "summary": "Reopen period",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Admin API key",
+                        "name": "X-Admin-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "First date to reopen",
+                        "name": "period",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PeriodInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/recurring-payments": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a list of all scheduled recurring payments (income or expense).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "recurring_payments"
+                ],
+                "summary": "List recurring payments",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by status (active, paused, cancelled, completed)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by account",
+                        "name": "account_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by type (income, expense)",
+                        "name": "type",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.RecurringPayment"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new scheduled recurring payment.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "recurring_payments"
+                ],
+                "summary": "Create recurring payment",
+                "parameters": [
+                    {
+                        "description": "Recurring payment details",
+                        "name": "recurring_payment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RecurringPaymentInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.RecurringPayment"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/recurring-payments/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get details of a specific recurring payment.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "recurring_payments"
+                ],
+                "summary": "Get recurring payment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Recurring Payment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.RecurringPayment"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update details of an existing recurring payment.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "recurring_payments"
+                ],
+                "summary": "Update recurring payment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Recurring Payment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated recurring payment details",
+                        "name": "recurring_payment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RecurringPaymentInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.RecurringPayment"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove a recurring payment.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "recurring_payments"
+                ],
+                "summary": "Delete recurring payment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Recurring Payment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/recurring-payments/{id}/links": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get all payment transactions linked to a specific recurring payment, including the due date and status of the occurrence each transaction covers.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "recurring_payments"
+                ],
+                "summary": "Get recurring payment links",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Recurring Payment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/handlers.RecurringPaymentLink"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/recurring-payments/{id}/match-suggestions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns a ranked list of bank transactions that could correspond to a recurring payment occurrence, scored by amount (±2% tolerance), date proximity to next_due_date, and description/reference similarity. Excludes transactions already linked to this recurring payment.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "recurring_payments"
+                ],
+                "summary": "Suggest transactions for a recurring payment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Recurring Payment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/handlers.TransactionSuggestion"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/recurring-payments/{id}/occurrences": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns all scheduled occurrences (instances) for a recurring payment. Occurrences are auto-generated by the server for each due date up to today. Each occurrence tracks whether it has been paid via a linked bank transaction.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "recurring_payments"
+                ],
+                "summary": "List recurring payment occurrences",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Recurring Payment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by status (pending, paid, skipped)",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.RecurringPaymentOccurrence"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/allocation-health": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Count and total the allocation work outstanding: income and expense transactions with an unallocated balance\n(per account currency; transfer legs and payout postings are left out), bills and invoices part paid, and\npayouts not settled by bank credits and any variance. over_allocated_items lists bills, invoices, payouts, and\ntransactions allocated beyond their amount. Differences within ALLOCATION_TOLERANCE_PAISE count as settled.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Allocation health",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handlers.AllocationHealth"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/commission-trend": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sum platform commission and marketing/ads against gross sales per month over the last months calendar months (including the current one),\nwith the take rate as a percentage of gross sales. Payouts are dated by settlement_date, or period_end when unsettled. Months without payouts are omitted.\nWith allocation=by_period, each payout is instead pro-rated by days across the months from period_start to period_end.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Commission trend report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by platform (swiggy, zomato, swiggy-dineout)",
+                        "name": "platform",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by outlet ID",
+                        "name": "outlet",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of months to cover (default 12, at most 120)",
+                        "name": "months",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "settlement_date (default) or by_period",
+                        "name": "allocation",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/handlers.CommissionTrendRow"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/fx": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revalue the balance on as_of (default today) of every account not in the books currency at a rate given in\nrates, or else at the fx_rate.\u003cCURRENCY\u003e default, and compare it with its value at the rate it was recorded\nat: the amount-weighted average exchange_rate of the account's transfers to and from books-currency accounts.\nRates are books-currency units per unit; gain_loss is positive for a gain. Currencies without a rate are\nlisted under missing and not revalued.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "FX gain/loss report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Date (YYYY-MM-DD or DD-MM-YYYY)",
+                        "name": "as_of",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Rates overriding the stored ones, e.g. USD:83.1,EUR:90.2",
+                        "name": "rates",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handlers.FXReport"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/gstr1": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the invoices issued between from and to with the buyer's GSTIN, taxable value, rate, and tax, and\narrange them into the b2b, b2cl, and b2cs sections of the GSTR-1 JSON schema (amounts in rupees).\nThe business's own GSTIN is read from the business.gstin default. Draft and cancelled invoices are left\nout; invoices without a tax_rate are listed under excluded. format=csv returns the register as CSV.",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "GSTR-1 sales register",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD or DD-MM-YYYY)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD or DD-MM-YYYY)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "json (default) or csv",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "CSV amounts as rupees (default, e.g. 123456.78), rupees_grouped (1,23,456.78), or paise (12345678)",
+                        "name": "amount_format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handlers.GSTR1Report"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/outlets": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sum orders, sales, deductions, and net payouts per outlet, grouped by outlet_id.\nPayouts are dated by settlement_date, or period_end when unsettled.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Outlet performance report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD or DD-MM-YYYY)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD or DD-MM-YYYY)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by platform (swiggy, zomato, swiggy-dineout)",
+                        "name": "platform",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/handlers.OutletReportRow"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/payout-variances": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List payouts settled with a variance (POST /payouts/{id}/settle), with the reason, and total the variances per platform.\nPayouts are dated by settlement_date, or period_end when unsettled.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Payout variance report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD or DD-MM-YYYY)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD or DD-MM-YYYY)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by platform (swiggy, zomato, swiggy-dineout)",
+                        "name": "platform",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handlers.PayoutVarianceReport"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/tds": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sum taxes_tcs_tds_amt withheld from payouts per month, platform, and outlet, for filing and claiming credit.\nPayouts are dated by settlement_date, or period_end when unsettled. With allocation=by_period, each payout is instead pro-rated by days\nacross the months from period_start to period_end, and from and to bound those days.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "TDS/TCS report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD or DD-MM-YYYY)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD or DD-MM-YYYY)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by platform (swiggy, zomato, swiggy-dineout)",
+                        "name": "platform",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "settlement_date (default) or by_period",
+                        "name": "allocation",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handlers.TDSReport"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/trial-balance": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List every account's balance on as_of (default today) as a debit or credit, with accounts receivable\noutstanding on invoices and accounts payable outstanding on bills as control accounts. The books are\nsingle-entry, so equity is derived as the balancing line and the totals net to zero. Accounts in\ncurrencies other than the books currency (BOOKS_CURRENCY, default INR) are listed under excluded and not summed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Trial balance",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Date (YYYY-MM-DD or DD-MM-YYYY)",
+                        "name": "as_of",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handlers.TrialBalance"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/vendor-spend": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sum bill amounts and the payments allocated to them per vendor contact, sorted by amount billed, with each vendor's percentage of the total billed.\nBills are dated by issue_date and payments by transaction_date. Cancelled bills are excluded.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Vendor spend report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD or DD-MM-YYYY)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD or DD-MM-YYYY)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handlers.VendorSpendReport"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a list of all bank transactions (income, expense, transfer) with allocation info.\nSend Accept: text/csv for the same list as CSV, with each amount in the decimal places of its account's currency, named in the currency column.",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "List transactions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by type (income, expense, transfer)",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by account",
+                        "name": "account_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by contact",
+                        "name": "contact_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by category",
+                        "name": "category_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by transaction date from (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by transaction date to (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by reference (exact match)",
+                        "name": "reference",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Look up by external system id",
+                        "name": "external_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by external source",
+                        "name": "source",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "CSV amounts as rupees (default), rupees_grouped, or paise",
+                        "name": "amount_format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.Transaction"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new bank transaction (income, expense, or transfer). A transfer may carry a fee_amount,\nwhich is recorded as a separate expense on the source account while the transfer legs move the net amount.\nIf external_id is set and the account already has a transaction with the same source and external_id, that transaction is\nupdated instead and 200 is returned. Amounts above LARGE_TXN_THRESHOLD require confirmed_large: true.\nA transfer between accounts of different currencies requires exchange_rate (destination units per source unit);\nthe destination leg moves the net amount converted at that rate, and both legs store the rate. Amounts are in the account's currency\nand are never converted otherwise; currency, when given, must match it.\nWhen account_id is omitted, the default account for the type (default_account.\u003ctype\u003e, see /defaults) is used.\nAn adjustment corrects an account balance without being income or expense: sign 1 adds its amount, -1 subtracts it.\nSet cleared: false for money still in transit (e.g. an uncleared cheque); it counts towards the account's projected balance but not its cleared balance.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Create transaction",
+                "parameters": [
+                    {
+                        "description": "Transaction contents",
+                        "name": "transaction",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TransactionInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Transaction"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Transaction"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/calendar": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sum each day's transactions over a month: the count and totals of income and expense and their net, with transfer legs counted and summed separately.\nEvery day of the month is returned in order, including days without transactions. Adjustments are not included.\nWithout account_id, the accounts in currency (default the books currency) are summed; accounts in other currencies are left out rather than added in unconverted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Transaction calendar",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Month (YYYY-MM, default the current month)",
+                        "name": "month",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by account",
+                        "name": "account_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Currency of the accounts to sum without account_id (default the books currency)",
+                        "name": "currency",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/handlers.CalendarDay"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/duplicates": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Group income and expense transactions with the same account, type, amount, and date (and reference when\nmatch_reference=true), for reviewing double-posted bank entries. Within a group the earliest created comes first.\nPOST with auto_resolve=keep_oldest keeps one transaction of each group and deletes the rest, with their links, in one\ndatabase transaction, and returns what was removed. The kept one is the transaction that is linked to documents,\nreconciled, or locked, or else the earliest created. Groups with more than one such transaction refuse the run with\n409 and are returned in data; groups dated in a closed period also block it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Find duplicate transactions",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Also require the same reference",
+                        "name": "match_reference",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "keep_oldest (POST only)",
+                        "name": "auto_resolve",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handlers.DuplicateScanResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handlers.DuplicateScanResult"
+                                        },
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Group income and expense transactions with the same account, type, amount, and date (and reference when\nmatch_reference=true), for reviewing double-posted bank entries. Within a group the earliest created comes first.\nPOST with auto_resolve=keep_oldest keeps one transaction of each group and deletes the rest, with their links, in one\ndatabase transaction, and returns what was removed. The kept one is the transaction that is linked to documents,\nreconciled, or locked, or else the earliest created. Groups with more than one such transaction refuse the run with\n409 and are returned in data; groups dated in a closed period also block it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Find duplicate transactions",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Also require the same reference",
+                        "name": "match_reference",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "keep_oldest (POST only)",
+                        "name": "auto_resolve",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handlers.DuplicateScanResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handlers.DuplicateScanResult"
+                                        },
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/reconcile-by-reference": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Marks the transaction matching each bank reference as reconciled in one pass, locking it too when LOCK_RECONCILED=true. References with no match or with several matches are reported and left unchanged. Pass account_id to restrict matching to one account.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Reconcile transactions by reference",
+                "parameters": [
+                    {
+                        "description": "References to reconcile",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ReconcileByReferenceInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handlers.ReconcileResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/suspense": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List income and expense transactions with no linked documents that are at least days old (default SUSPENSE_DAYS, 30),\noldest first, for periodic review of unallocated money. Transfer legs are excluded.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "List suspense transactions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Minimum age in days",
+                        "name": "days",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/handlers.SuspenseTransaction"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get details and allocation status of a specific transaction.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Get transaction",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transaction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Transaction"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "code": {
+                                            "type": "string"
+                                        },
+                                        "error": {
+                                            "type": "string"
+                                        },
+                                        "resource": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update details of an existing transaction. Raising the amount above LARGE_TXN_THRESHOLD requires confirmed_large: true.\nOmitting cleared keeps the stored value; setting cleared_date marks the transaction cleared. Locked transactions are rejected with 409.\nA leg of a transfer between currencies keeps its exchange_rate, and changing its amount or rate is rejected with 409.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Update transaction",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transaction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated transaction contents",
+                        "name": "transaction",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TransactionInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Transaction"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove a transaction. Locked transactions are rejected with 409.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Delete transaction",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transaction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/{id}/allocation-graph": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the transaction, each document it is linked to with that document's amount, total allocation, and status,\nand the links from other transactions to the same documents. Use it to see why a document is partial or paid.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Get transaction allocation graph",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transaction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handlers.AllocationGraph"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/{id}/auto-match": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Finds the best matching bill, invoice, or payout for a bank statement entry and automatically creates a transaction link when confidence is at least 0.7. Returns the created link on success, or the top suggestion without linking when confidence is below the threshold. A transaction on an account outside BOOKS_CURRENCY is refused with 400.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Auto-match a transaction to a document",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transaction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handlers.AutoMatchResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/{id}/links": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get bills and invoices linked (paid) by this transaction, oldest first unless order=desc. Supports limit/offset pagination; totals across all links are returned in headers.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "List transaction links",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transaction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of links to return (default DEFAULT_PAGE_SIZE, or all; at most MAX_PAGE_SIZE)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of links to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort by created_at: asc (default) or desc",
+                        "name": "order",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.TransactionDocument"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "X-Page-Size-Clamped": {
+                                "type": "int",
+                                "description": "Limit applied when the requested page exceeded MAX_PAGE_SIZE"
+                            },
+                            "X-Total-Allocated": {
+                                "type": "int",
+                                "description": "Total allocated across all links, in paise"
+                            },
+                            "X-Total-Count": {
+                                "type": "int",
+                                "description": "Total number of links"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Allocate an amount from a transaction to a specific bill, invoice, payout or recurring payment occurrence. The amount may exceed the\ndocument's unallocated amount by up to ALLOCATION_TOLERANCE_PAISE to absorb rounding. Linking to a document\nalready marked paid/received (e.g. after its amount was raised) is allowed and returns a warning.\nAn optional note (up to 500 characters) records why the amount was allocated, e.g. a retention held back.\nDocuments are kept in BOOKS_CURRENCY, so a transaction on an account in another currency cannot be linked (400).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Create transaction link",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transaction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Link details",
+                        "name": "link",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TransactionDocumentInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handlers.TransactionLinkResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/{id}/links/{linkId}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deallocate an amount from a transaction to a bill, invoice, payout or recurring payment occurrence.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Delete transaction link",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transaction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Link ID",
+                        "name": "linkId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/{id}/lock": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lock a transaction so that updates and deletion are rejected with 409 until it is unlocked, e.g. once it has been reconciled and verified.\nLinks to bills and invoices can still be added and removed. Setting LOCK_RECONCILED=true locks transactions as they are reconciled.\nWhen ADMIN_API_KEY is configured the request must carry it in the X-Admin-Key header.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Lock transaction",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Admin API key",
+                        "name": "X-Admin-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Transaction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Transaction"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/{id}/match-suggestions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns a ranked list of bills, invoices, payouts, and recurring payments that could match a bank statement entry, scored by amount, date, and description similarity. Expense transactions match against bills; income transactions match against invoices and payouts. Recurring payments are suggested for both types as informational matches (linkable=false).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Suggest document matches for a transaction",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transaction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/handlers.MatchSuggestion"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/{id}/unlock": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Unlock a locked transaction so that it can be updated or deleted again. When ADMIN_API_KEY is configured the request must carry it in the X-Admin-Key header.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Unlock transaction",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Admin API key",
+                        "name": "X-Admin-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Transaction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Transaction"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handlers.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "handlers.AccountDeleteImpact": {
+            "type": "object",
+            "properties": {
+                "defaults": {
+                    "description": "default accounts pointing at it",
+                    "type": "integer"
+                },
+                "recurring_payments": {
+                    "description": "paid from the account",
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "transactions": {
+                    "description": "posted on the account",
+                    "type": "integer"
+                },
+                "transfer_legs": {
+                    "description": "legs on other accounts naming it as the transfer counterparty",
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.AccountResult": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "description": "Computed: opening + income - expense ± adjustments",
+                    "type": "integer"
+                },
+                "balance_type": {
+                    "description": "asset or liability, derived from Type",
+                    "type": "string"
+                },
+                "cleared_balance": {
+                    "description": "ClearedBalance leaves out pending (uncleared) transactions, and\nProjectedBalance, equal to Balance, includes them.",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "$ref": "#/definitions/models.Timestamp"
+                },
+                "currency": {
+                    "description": "ISO 4217 code, e.g. INR",
+                    "type": "string"
+                },
+                "display_balance": {
+                    "description": "Balance as the holder sees it; for liabilities, the amount owed",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "minor_units": {
+                    "description": "decimal places of Currency; amounts are in its smallest unit",
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "opening_balance": {
+                    "type": "integer"
+                },
+                "projected_balance": {
+                    "type": "integer"
+                },
+                "type": {
+                    "description": "bank, cash, credit_card, loan",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "$ref": "#/definitions/models.Timestamp"
+                },
+                "warnings": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "handlers.AccountStats": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "adjustments": {
+                    "description": "net of signed adjustments",
+                    "type": "integer"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "inflow": {
+                    "description": "income, excluding transfers in",
+                    "type": "integer"
+                },
+                "largest_expense": {
+                    "description": "null when there is none",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Transaction"
+                        }
+                    ]
+                },
+                "largest_income": {
+                    "description": "null when there is none",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Transaction"
+                        }
+                    ]
+                },
+                "net": {
+                    "description": "inflow minus outflow",
+                    "type": "integer"
+                },
+                "outflow": {
+                    "description": "expenses, excluding transfers out",
+                    "type": "integer"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "transaction_count": {
+                    "type": "integer"
+                },
+                "transfers_in": {
+                    "description": "income legs of transfers from other accounts",
+                    "type": "integer"
+                },
+                "transfers_out": {
+                    "description": "expense legs of transfers to other accounts",
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.AccountVerification": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "computed_balance": {
+                    "description": "opening balance plus TransactionSum",
+                    "type": "integer"
+                },
+                "consistent": {
+                    "description": "no discrepancy and no issues",
+                    "type": "boolean"
+                },
+                "discrepancy": {
+                    "description": "displayed minus computed",
+                    "type": "integer"
+                },
+                "displayed_balance": {
+                    "description": "Balance as returned by GET /accounts/{id}",
+                    "type": "integer"
+                },
+                "issues": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.BalanceIssue"
+                    }
+                },
+                "opening_balance": {
+                    "type": "integer"
+                },
+                "transaction_count": {
+                    "description": "transactions posted on the account",
+                    "type": "integer"
+                },
+                "transaction_sum": {
+                    "description": "their net effect on the balance",
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.AllocationGraph": {
+            "type": "object",
+            "properties": {
+                "links": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.AllocationGraphLink"
+                    }
+                },
+                "transaction": {
+                    "$ref": "#/definitions/models.Transaction"
+                }
+            }
+        },
+        "handlers.AllocationHealth": {
+            "type": "object",
+            "properties": {
+                "over_allocated": {
+                    "$ref": "#/definitions/store.AllocationBucket"
+                },
+                "over_allocated_items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.OverAllocation"
+                    }
+                },
+                "partial_bills": {
+                    "$ref": "#/definitions/store.AllocationBucket"
+                },
+                "partial_invoices": {
+                    "$ref": "#/definitions/store.AllocationBucket"
+                },
+                "unallocated_transactions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.CurrencyAllocationBucket"
+                    }
+                },
+                "unsettled_payouts": {
+                    "$ref": "#/definitions/store.AllocationBucket"
+                }
+            }
+        },
+        "handlers.AutoMatchResult": {
+            "type": "object",
+            "properties": {
+                "link": {
+                    "$ref": "#/definitions/models.TransactionDocument"
+                },
+                "matched": {
+                    "type": "boolean"
+                },
+                "suggestion": {
+                    "$ref": "#/definitions/handlers.MatchSuggestion"
+                }
+            }
+        },
+        "handlers.BillLink": {
+            "type": "object",
+            "properties": {
+                "account_name": {
+                    "type": "string"
+                },
+                "amount": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "$ref": "#/definitions/models.Timestamp"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "document_id": {
+                    "type": "integer"
+                },
+                "document_type": {
+                    "description": "bill, invoice, payout or recurring_payment_occurrence",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "note": {
+                    "description": "why this amount was allocated, e.g. a retention held back",
+                    "type": "string"
+                },
+                "reference": {
+                    "type": "string"
+                },
+                "transaction_date": {
+                    "type": "string"
+                },
+                "transaction_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.CalendarDay": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "description": "income and expense transactions",
+                    "type": "integer"
+                },
+                "date": {
+                    "$ref": "#/definitions/models.Date"
+                },
+                "expense": {
+                    "type": "integer"
+                },
+                "income": {
+                    "type": "integer"
+                },
+                "net": {
+                    "description": "income less expense",
+                    "type": "integer"
+                },
+                "transfers": {
+                    "type": "integer"
+                },
+                "transfers_in": {
+                    "type": "integer"
+                },
+                "transfers_out": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.Changes": {
+            "type": "object",
+            "properties": {
+                "accounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Account"
+                    }
+                },
+                "bills": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Bill"
+                    }
+                },
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Category"
+                    }
+                },
+                "contacts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Contact"
+                    }
+                },
+                "deleted": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.Deletion"
+                    }
+                },
+                "invoices": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Invoice"
+                    }
+                },
+                "outlets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Outlet"
+                    }
+                },
+                "payouts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Payout"
+                    }
+                },
+                "recurring_payments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RecurringPayment"
+                    }
+                },
+                "server_time": {
+                    "description": "pass as since on the next call",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Timestamp"
+                        }
+                    ]
+                },
+                "since": {
+                    "description": "null for a full sync",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Timestamp"
+                        }
+                    ]
+                },
+                "transactions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Transaction"
+                    }
+                }
+            }
+        },
+        "handlers.ClientConfig": {
+            "type": "object",
+            "properties": {
+                "books_currency": {
+                    "type": "string"
+                },
+                "currency_minor_units": {
+                    "description": "currencies with their own decimal places, such as 0 for JPY; others follow minor_units",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "minor_units": {
+                    "description": "decimal places amounts are kept to",
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.CommissionTrendRow": {
+            "type": "object",
+            "properties": {
+                "gross_sales_amt": {
+                    "type": "integer"
+                },
+                "marketing_ads_amt": {
+                    "type": "integer"
+                },
+                "month": {
+                    "description": "YYYY-MM",
+                    "type": "string"
+                },
+                "payouts": {
+                    "type": "integer"
+                },
+                "platform_commission_amt": {
+                    "type": "integer"
+                },
+                "take_rate_pct": {
+                    "description": "(commission + ads) / gross sales, in percent",
+                    "type": "number"
+                }
+            }
+        },
+        "handlers.ContactDeleteImpact": {
+            "type": "object",
+            "properties": {
+                "bills": {
+                    "type": "integer"
+                },
+                "invoices": {
+                    "type": "integer"
+                },
+                "recurring_payments": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "transactions": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.ContactDocument": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "document_type": {
+                    "description": "bill, invoice",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "number": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.ContactDocuments": {
+            "type": "object",
+            "properties": {
+                "bills": {
+                    "description": "vendors only",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Bill"
+                    }
+                },
+                "contact": {
+                    "$ref": "#/definitions/models.Contact"
+                },
+                "invoices": {
+                    "description": "customers only",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Invoice"
+                    }
+                },
+                "transactions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Transaction"
+                    }
+                }
+            }
+        },
+        "handlers.ContactImportResult": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer"
+                },
+                "errors": {
+                    "type": "integer"
+                },
+                "merged": {
+                    "description": "existing contacts whose blank fields were filled in",
+                    "type": "integer"
+                },
+                "rows": {
+                    "description": "every data row, in file order",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.ContactImportRow"
+                    }
+                },
+                "skipped": {
+                    "description": "rows matching an existing contact with nothing to add",
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.ContactPayment": {
+            "type": "object",
+            "properties": {
+                "account_name": {
+                    "type": "string"
+                },
+                "amount": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "$ref": "#/definitions/models.Timestamp"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "document_date": {
+                    "description": "issue date of the bill or invoice",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Date"
+                        }
+                    ]
+                },
+                "document_id": {
+                    "type": "integer"
+                },
+                "document_number": {
+                    "type": "string"
+                },
+                "document_type": {
+                    "description": "bill, invoice, payout or recurring_payment_occurrence",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "note": {
+                    "description": "why this amount was allocated, e.g. a retention held back",
+                    "type": "string"
+                },
+                "transaction_date": {
+                    "$ref": "#/definitions/models.Date"
+                },
+                "transaction_id": {
+                    "type": "integer"
+                },
+                "transaction_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.CreditCheck": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "integer"
+                },
+                "credit_limit": {
+                    "type": "integer"
+                },
+                "new_balance": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.DueSoonCustomer": {
+            "type": "object",
+            "properties": {
+                "contact_id": {
+                    "type": "integer"
+                },
+                "contact_name": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "invoices": {
+                    "description": "soonest due first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Invoice"
+                    }
+                },
+                "outstanding": {
+                    "description": "unallocated across Invoices",
+                    "type": "integer"
+                },
+                "phone": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.DuplicateGroup": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "amount": {
+                    "type": "integer"
+                },
+                "reference": {
+                    "description": "set only when references are matched",
+                    "type": "string"
+                },
+                "transaction_date": {
+                    "$ref": "#/definitions/models.Date"
+                },
+                "transactions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Transaction"
+                    }
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.DuplicateScanResult": {
+            "type": "object",
+            "properties": {
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.DuplicateGroup"
+                    }
+                },
+                "removed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Transaction"
+                    }
+                }
+            }
+        },
+        "handlers.EInvoiceExport": {
+            "type": "object",
+            "properties": {
+                "einvoice": {
+                    "$ref": "#/definitions/store.EInvoice"
+                },
+                "invoice_id": {
+                    "type": "integer"
+                },
+                "irn": {
+                    "description": "the IRP's reference once registered; not tracked yet, so always null",
+                    "type": "string"
+                },
+                "missing": {
+                    "description": "paths of mandatory fields left empty, e.g. SellerDtls.Addr1",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "handlers.Event": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "resource": {
+                    "description": "transaction, bill, invoice, payout, account, contact",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "created, updated, deleted",
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.FXReport": {
+            "type": "object",
+            "properties": {
+                "as_of": {
+                    "type": "string"
+                },
+                "books_currency": {
+                    "type": "string"
+                },
+                "missing": {
+                    "description": "currencies without a rate, whose accounts are not revalued",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.FXReportRow"
+                    }
+                },
+                "total_gain_loss": {
+                    "description": "in BooksCurrency",
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.GSTR1Report": {
+            "type": "object",
+            "properties": {
+                "excluded": {
+                    "description": "invoices with no tax_rate, left out of the return",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.GSTR1Row"
+                    }
+                },
+                "from": {
+                    "type": "string"
+                },
+                "gstr1": {
+                    "$ref": "#/definitions/store.GSTR1Return"
+                },
+                "register": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.GSTR1Row"
+                    }
+                },
+                "to": {
+                    "type": "string"
+                },
+                "total_tax": {
+                    "type": "integer"
+                },
+                "total_taxable_value": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.ImportResult": {
+            "type": "object",
+            "properties": {
+                "columns": {
+                    "description": "CSV only: the header each field was read from",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "errors": {
+                    "description": "lines that could not be imported",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ImportError"
+                    }
+                },
+                "imported": {
+                    "description": "transactions created",
+                    "type": "integer"
+                },
+                "skipped": {
+                    "description": "lines already imported, matched by external_id",
+                    "type": "integer"
+                },
+                "transaction_ids": {
+                    "description": "IDs of the created transactions",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "handlers.InvoiceCreateResult": {
+            "type": "object",
+            "properties": {
+                "allocated": {
+                    "type": "integer"
+                },
+                "allocated_pct": {
+                    "description": "allocated / amount * 100, 0 for a zero amount",
+                    "type": "number"
+                },
+                "amount": {
+                    "description": "the total of the items when the invoice has any",
+                    "type": "integer"
+                },
+                "contact_id": {
+                    "type": "integer"
+                },
+                "contact_name": {
+                    "description": "Computed fields",
+                    "type": "string"
+                },
+                "created_at": {
+                    "$ref": "#/definitions/models.Timestamp"
+                },
+                "credit": {
+                    "description": "set when the invoice exceeds the customer's credit limit",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/handlers.CreditCheck"
+                        }
+                    ]
+                },
+                "days_to_due": {
+                    "description": "days from today to due_date, negative when overdue, null without a due date",
+                    "type": "integer"
+                },
+                "due_date": {
+                    "$ref": "#/definitions/models.Date"
+                },
+                "file_url": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "invoice_number": {
+                    "type": "string"
+                },
+                "issue_date": {
+                    "$ref": "#/definitions/models.Date"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.InvoiceItem"
+                    }
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "place_of_supply": {
+                    "description": "two-digit GST state code",
+                    "type": "string"
+                },
+                "sent_at": {
+                    "description": "last time the invoice was sent to the customer, null if never",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Timestamp"
+                        }
+                    ]
+                },
+                "status": {
+                    "type": "string"
+                },
+                "tax_amount": {
+                    "description": "GST included in Amount",
+                    "type": "integer"
+                },
+                "tax_rate": {
+                    "description": "GST rate in percent, null when not recorded",
+                    "type": "number"
+                },
+                "unallocated": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "$ref": "#/definitions/models.Timestamp"
+                },
+                "warnings": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "handlers.InvoiceItemResult": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "including GST",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "$ref": "#/definitions/models.Timestamp"
+                },
+                "credit": {
+                    "description": "set when the item takes the customer over its credit limit",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/handlers.CreditCheck"
+                        }
+                    ]
+                },
+                "description": {
+                    "type": "string"
+                },
+                "hsn_code": {
+                    "description": "HSN code, or SAC for a service",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "invoice_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "tax_amount": {
+                    "description": "Computed fields",
+                    "type": "integer"
+                },
+                "tax_rate": {
+                    "description": "GST rate in percent; null when the invoice's tax_rate covers the item",
+                    "type": "number"
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "unit_price": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "$ref": "#/definitions/models.Timestamp"
+                },
+                "warnings": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "handlers.InvoiceLedger": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.InvoiceLedgerEntry"
+                    }
+                },
+                "invoice_id": {
+                    "type": "integer"
+                },
+                "invoice_number": {
+                    "type": "string"
+                },
+                "outstanding": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.InvoiceLink": {
+            "type": "object",
+            "properties": {
+                "account_name": {
+                    "type": "string"
+                },
+                "amount": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "$ref": "#/definitions/models.Timestamp"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "document_id": {
+                    "type": "integer"
+                },
+                "document_type": {
+                    "description": "bill, invoice, payout or recurring_payment_occurrence",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "note": {
+                    "description": "why this amount was allocated, e.g. a retention held back",
+                    "type": "string"
+                },
+                "reference": {
+                    "type": "string"
+                },
+                "transaction_date": {
+                    "type": "string"
+                },
+                "transaction_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.MatchSuggestion": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "total document amount",
+                    "type": "integer"
+                },
+                "confidence": {
+                    "description": "0.0 – 1.0",
+                    "type": "number"
+                },
+                "document_date": {
+                    "description": "relevant date for matching",
+                    "type": "string"
+                },
+                "document_id": {
+                    "type": "integer"
+                },
+                "document_ref": {
+                    "description": "reference number or name",
+                    "type": "string"
+                },
+                "document_type": {
+                    "description": "bill, invoice, payout, recurring_payment",
+                    "type": "string"
+                },
+                "linkable": {
+                    "description": "whether this suggestion can be auto-linked",
+                    "type": "boolean"
+                },
+                "match_reasons": {
+                    "description": "human-readable match reasons",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "unallocated": {
+                    "description": "unallocated amount (0 for recurring_payment)",
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.OCRBillDraft": {
+            "type": "object",
+            "properties": {
+                "bill": {
+                    "description": "contact_id is set when vendor matches a vendor contact",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.BillInput"
+                        }
+                    ]
+                },
+                "error": {
+                    "description": "extraction failed; bill is an empty draft",
+                    "type": "string"
+                },
+                "file": {
+                    "description": "uploaded file name, empty for a raw body",
+                    "type": "string"
+                },
+                "vendor": {
+                    "description": "vendor name as read from the receipt",
+                    "type": "string"
+                },
+                "warnings": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "handlers.OCRResult": {
+            "type": "object",
+            "properties": {
+                "drafts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.OCRBillDraft"
+                    }
+                },
+                "extractor": {
+                    "description": "\"manual\" when no OCR service is configured",
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.OffsetResult": {
+            "type": "object",
+            "properties": {
+                "bill_status": {
+                    "type": "string"
+                },
+                "invoice_status": {
+                    "type": "string"
+                },
+                "links": {
+                    "description": "the bill link, then the invoice link",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TransactionDocument"
+                    }
+                },
+                "transaction": {
+                    "$ref": "#/definitions/models.Transaction"
+                }
+            }
+        },
+        "handlers.OutletReportRow": {
+            "type": "object",
+            "properties": {
+                "final_payout_amt": {
+                    "type": "integer"
+                },
+                "gross_sales_amt": {
+                    "type": "integer"
+                },
+                "marketing_ads_amt": {
+                    "type": "integer"
+                },
+                "outlet_id": {
+                    "description": "nil for payouts not linked to an outlet",
+                    "type": "integer"
+                },
+                "outlet_name": {
+                    "type": "string"
+                },
+                "payouts": {
+                    "type": "integer"
+                },
+                "platform_commission_amt": {
+                    "type": "integer"
+                },
+                "restaurant_discount_amt": {
+                    "type": "integer"
+                },
+                "taxes_tcs_tds_amt": {
+                    "type": "integer"
+                },
+                "total_orders": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.PayoutAutoMatchResult": {
+            "type": "object",
+            "properties": {
+                "candidates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.TransactionSuggestion"
+                    }
+                },
+                "link": {
+                    "$ref": "#/definitions/models.TransactionDocument"
+                },
+                "matched": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "handlers.PayoutDetail": {
+            "type": "object",
+            "properties": {
+                "allocated": {
+                    "description": "Computed fields",
+                    "type": "integer"
+                },
+                "allocated_pct": {
+                    "description": "allocated / final_payout_amt * 100, 0 for a zero payout",
+                    "type": "number"
+                },
+                "created_at": {
+                    "$ref": "#/definitions/models.Timestamp"
+                },
+                "final_payout_amt": {
+                    "type": "integer"
+                },
+                "gross_sales_amt": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "marketing_ads_amt": {
+                    "type": "integer"
+                },
+                "outlet_id": {
+                    "type": "integer"
+                },
+                "outlet_name": {
+                    "description": "Denormalized from the outlet for display",
+                    "type": "string"
+                },
+                "payments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.PayoutLink"
+                    }
+                },
+                "period_end": {
+                    "$ref": "#/definitions/models.Date"
+                },
+                "period_start": {
+                    "$ref": "#/definitions/models.Date"
+                },
+                "platform": {
+                    "description": "swiggy, zomato, swiggy-dineout",
+                    "type": "string"
+                },
+                "platform_commission_amt": {
+                    "type": "integer"
+                },
+                "restaurant_discount_amt": {
+                    "type": "integer"
+                },
+                "settlement_date": {
+                    "$ref": "#/definitions/models.Date"
+                },
+                "taxes_tcs_tds_amt": {
+                    "type": "integer"
+                },
+                "total_orders": {
+                    "type": "integer"
+                },
+                "unallocated": {
+                    "description": "final_payout_amt less allocated and variance_amount",
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "$ref": "#/definitions/models.Timestamp"
+                },
+                "utr_number": {
+                    "type": "string"
+                },
+                "variance_amount": {
+                    "description": "shortfall written off when settling, see PayoutSettleInput",
+                    "type": "integer"
+                },
+                "variance_reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.PayoutImportResult": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer"
+                },
+                "errors": {
+                    "type": "integer"
+                },
+                "rows": {
+                    "description": "every data row, in file order",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.PayoutImportRow"
+                    }
+                },
+                "skipped": {
+                    "description": "rows matching a payout already recorded",
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.PayoutLink": {
+            "type": "object",
+            "properties": {
+                "account_name": {
+                    "type": "string"
+                },
+                "amount": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "$ref": "#/definitions/models.Timestamp"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "document_id": {
+                    "type": "integer"
+                },
+                "document_type": {
+                    "description": "bill, invoice, payout or recurring_payment_occurrence",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "note": {
+                    "description": "why this amount was allocated, e.g. a retention held back",
+                    "type": "string"
+                },
+                "reference": {
+                    "type": "string"
+                },
+                "transaction_date": {
+                    "type": "string"
+                },
+                "transaction_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.PayoutOrders": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PayoutOrderCheck"
+                    }
+                },
+                "orders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PayoutOrder"
+                    }
+                },
+                "reconciled": {
+                    "description": "every check within PAYOUT_ORDER_TOLERANCE_PAISE",
+                    "type": "boolean"
+                }
+            }
+        },
+        "handlers.PayoutPosting": {
+            "type": "object",
+            "properties": {
+                "bank_transaction_id": {
+                    "description": "the bank credit, now the income leg of the settlement transfer",
+                    "type": "integer"
+                },
+                "clearing_account_id": {
+                    "type": "integer"
+                },
+                "payout_id": {
+                    "type": "integer"
+                },
+                "transactions": {
+                    "description": "Transactions are the entries on the clearing account: gross sales as\nincome, each deduction as an expense, and the expense leg of the\ntransfer settling the net to the bank.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Transaction"
+                    }
+                }
+            }
+        },
+        "handlers.PayoutVarianceReport": {
+            "type": "object",
+            "properties": {
+                "by_platform": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.PayoutVarianceRow"
+                    }
+                },
+                "total_variance_amount": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.ReconcileResult": {
+            "type": "object",
+            "properties": {
+                "ambiguous": {
+                    "description": "references matching several transactions",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        }
+                    }
+                },
+                "reconciled": {
+                    "description": "IDs of transactions marked reconciled",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "unmatched": {
+                    "description": "references with no transaction",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "handlers.RecurringPaymentLink": {
+            "type": "object",
+            "properties": {
+                "account_name": {
+                    "type": "string"
+                },
+                "amount": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "$ref": "#/definitions/models.Timestamp"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "document_id": {
+                    "type": "integer"
+                },
+                "document_type": {
+                    "description": "bill, invoice, payout or recurring_payment_occurrence",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "note": {
+                    "description": "why this amount was allocated, e.g. a retention held back",
+                    "type": "string"
+                },
+                "occurrence_due_date": {
+                    "type": "string"
+                },
+                "occurrence_status": {
+                    "type": "string"
+                },
+                "reference": {
+                    "type": "string"
+                },
+                "transaction_date": {
+                    "type": "string"
+                },
+                "transaction_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.Response": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Code and Resource identify the error for clients that branch on it\nrather than on the message, e.g. NOT_FOUND for an \"account\".",
+                    "type": "string"
+                },
+                "data": {},
+                "error": {
+                    "type": "string"
+                },
+                "resource": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.StatusRecomputeResult": {
+            "type": "object",
+            "properties": {
+                "bills": {
+                    "$ref": "#/definitions/store.StatusRecount"
+                },
+                "invoices": {
+                    "$ref": "#/definitions/store.StatusRecount"
+                },
+                "payouts": {
+                    "description": "Payouts carry no stored status; they are counted but never changed.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/store.StatusRecount"
+                        }
+                    ]
+                }
+            }
+        },
+        "handlers.SuspenseTransaction": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "account_name": {
+                    "description": "Computed fields",
+                    "type": "string"
+                },
+                "age_days": {
+                    "description": "days since the transaction date (or creation, when undated)",
+                    "type": "integer"
+                },
+                "allocated": {
+                    "type": "integer"
+                },
+                "amount": {
+                    "type": "integer"
+                },
+                "category_id": {
+                    "type": "integer"
+                },
+                "category_name": {
+                    "type": "string"
+                },
+                "cleared": {
+                    "description": "false while the money is still in transit, e.g. an uncleared cheque",
+                    "type": "boolean"
+                },
+                "cleared_date": {
+                    "description": "when it cleared, if recorded",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Date"
+                        }
+                    ]
+                },
+                "contact_id": {
+                    "type": "integer"
+                },
+                "contact_name": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "$ref": "#/definitions/models.Timestamp"
+                },
+                "currency": {
+                    "description": "the account's currency, which Amount is in",
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "exchange_rate": {
+                    "description": "transfer legs between currencies: destination units per source unit",
+                    "type": "number"
+                },
+                "external_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "locked": {
+                    "description": "frozen against edits and deletion until unlocked",
+                    "type": "boolean"
+                },
+                "reconciled": {
+                    "type": "boolean"
+                },
+                "reference": {
+                    "type": "string"
+                },
+                "sign": {
+                    "description": "adjustments only: 1 adds to the balance, -1 subtracts",
+                    "type": "integer"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "transaction_date": {
+                    "$ref": "#/definitions/models.Date"
+                },
+                "transfer_account_id": {
+                    "type": "integer"
+                },
+                "transfer_account_name": {
+                    "type": "string"
+                },
+                "type": {
+                    "description": "income, expense, transfer, adjustment, offset",
+                    "type": "string"
+                },
+                "unallocated": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "$ref": "#/definitions/models.Timestamp"
+                }
+            }
+        },
+        "handlers.TDSReport": {
+            "type": "object",
+            "properties": {
+                "by_platform": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.TDSReportRow"
+                    }
+                },
+                "total_taxes_tcs_tds_amt": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.TransactionLinkResult": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "$ref": "#/definitions/models.Timestamp"
+                },
+                "document_id": {
+                    "type": "integer"
+                },
+                "document_type": {
+                    "description": "bill, invoice, payout or recurring_payment_occurrence",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "note": {
+                    "description": "why this amount was allocated, e.g. a retention held back",
+                    "type": "string"
+                },
+                "transaction_id": {
+                    "type": "integer"
+                },
+                "warnings": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "handlers.TransactionSuggestion": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "total transaction amount",
+                    "type": "integer"
+                },
+                "confidence": {
+                    "type": "number"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "linkable": {
+                    "type": "boolean"
+                },
+                "match_reasons": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "reference": {
+                    "type": "string"
+                },
+                "transaction_date": {
+                    "type": "string"
+                },
+                "transaction_id": {
+                    "type": "integer"
+                },
+                "unallocated": {
+                    "description": "unallocated amount remaining",
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.TrialBalance": {
+            "type": "object",
+            "properties": {
+                "as_of": {
+                    "type": "string"
+                },
+                "difference": {
+                    "description": "total_debit - total_credit",
+                    "type": "integer"
+                },
+                "excluded": {
+                    "description": "accounts in other currencies, left out of the totals",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.TrialBalanceRow"
+                    }
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.TrialBalanceRow"
+                    }
+                },
+                "total_credit": {
+                    "type": "integer"
+                },
+                "total_debit": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.VendorSpendReport": {
+            "type": "object",
+            "properties": {
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.VendorSpendRow"
+                    }
+                },
+                "total_billed": {
+                    "type": "integer"
+                },
+                "total_paid": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.loginRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.refreshRequest": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.registerRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "org_name": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.sessionTokens": {
+            "type": "object",
+            "properties": {
+                "expires_in": {
+                    "description": "seconds until token expires",
+                    "type": "integer"
+                },
+                "refresh_expires_in": {
+                    "description": "seconds until refresh_token expires",
+                    "type": "integer"
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
+                "token": {
+                    "description": "access token, sent as \"Authorization: Bearer \u003ctoken\u003e\"",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Account": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "description": "Computed: opening + income - expense ± adjustments",
+                    "type": "integer"
+                },
+                "balance_type": {
+                    "description": "asset or liability, derived from Type",
+                    "type": "string"
+                },
+                "cleared_balance": {
+                    "description": "ClearedBalance leaves out pending (uncleared) transactions, and\nProjectedBalance, equal to Balance, includes them.",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "$ref": "#/definitions/models.Timestamp"
+                },
+                "currency": {
+                    "description": "ISO 4217 code, e.g. INR",
+                    "type": "string"
+                },
+                "display_balance": {
+                    "description": "Balance as the holder sees it; for liabilities, the amount owed",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "minor_units": {
+                    "description": "decimal places of Currency; amounts are in its smallest unit",
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "opening_balance": {
+                    "type": "integer"
+                },
+                "projected_balance": {
+                    "type": "integer"
+                },
+                "type": {
+                    "description": "bank, cash, credit_card, loan",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "$ref": "#/definitions/models.Timestamp"
+                }
+            }
+        },
+        "models.AccountInput": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "description": "ISO 4217 code; empty keeps the stored currency, or INR for a new account",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "opening_balance": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Bill": {
+            "type": "object",
+            "properties": {
+                "allocated": {
+                    "description": "sum of linked transaction_documents amounts",
+                    "type": "integer"
+                },
+                "allocated_pct": {
+                    "description": "allocated / amount * 100, 0 for a zero amount",
+                    "type": "number"
+                },
+                "amount": {
+                    "type": "integer"
+                },
+                "bill_number": {
+                    "type": "string"
+                },
+                "category_id": {
+                    "type": "integer"
+                },
+                "category_name": {
+                    "type": "string"
+                },
+                "contact_id": {
+                    "type": "integer"
+                },
+                "contact_name": {
+                    "description": "Computed fields",
+                    "type": "string"
+                },
+                "created_at": {
+                    "$ref": "#/definitions/models.Timestamp"
+                },
+                "days_to_due": {
+                    "description": "days from today to due_date, negative when overdue, null without a due date",
+                    "type": "integer"
+                },
+                "due_date": {
+                    "$ref": "#/definitions/models.Date"
+                },
+                "file_url": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "issue_date": {
+                    "$ref": "#/definitions/models.Date"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BillItem"
+                    }
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "unallocated": {
+                    "description": "amount - allocated",
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "$ref": "#/definitions/models.Timestamp"
+                }
+            }
+        },
+        "models.BillInput": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "bill_number": {
+                    "type": "string"
+                },
+                "category_id": {
+                    "description": "an expense category; omitting it on update clears it",
+                    "type": "integer"
+                },
+                "contact_id": {
+                    "type": "integer"
+                },
+                "due_date": {
+                    "type": "string"
+                },
+                "file_url": {
+                    "type": "string"
+                },
+                "issue_date": {
+                    "type": "string"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BillItemInput"
+                    }
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BillItem": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "bill_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "$ref": "#/definitions/models.Timestamp"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "unit_price": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "$ref": "#/definitions/models.Timestamp"
+                }
+            }
+        },
+        "models.BillItemInput": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "unit_price": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Category": {
+            "type": "object",
+            "properties": {
+                "bills": {
+                    "description": "Computed: number of bills tagged with the category",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "$ref": "#/definitions/models.Timestamp"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "transactions": {
+                    "description": "Computed: number of transactions tagged with the category",
+                    "type": "integer"
+                },
+                "type": {
+                    "description": "expense or income",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "$ref": "#/definitions/models.Timestamp"
+                }
+            }
+        },
+        "models.CategoryInput": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "type": {
+                    "description": "expense (the default) or income",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ClosedPeriod": {
+            "type": "object",
+            "properties": {
+                "closed_through": {
+                    "$ref": "#/definitions/models.Date"
+                },
+                "created_at": {
+                    "$ref": "#/definitions/models.Timestamp"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "notes": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Contact": {
+            "type": "object",
+            "properties": {
+                "allocated_amount": {
+                    "description": "Computed: Sum of payments",
+                    "type": "integer"
+                },
+                "balance": {
+                    "description": "Computed: Total - Allocated",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "$ref": "#/definitions/models.Timestamp"
+                },
+                "credit_limit": {
+                    "description": "most a customer may owe on open invoices; null for no limit",
+                    "type": "integer"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "gstin": {
+                    "description": "set for GST-registered businesses",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "pan": {
+                    "description": "characters 3-12 of GSTIN when both are set",
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "total_amount": {
+                    "description": "Computed: Sum of bills/invoices",
+                    "type": "integer"
+                },
+                "type": {
+                    "description": "vendor, customer",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "$ref": "#/definitions/models.Timestamp"
+                }
+            }
+        },
+        "models.ContactInput": {
+            "type": "object",
+            "properties": {
+                "credit_limit": {
+                    "description": "CreditLimit caps the outstanding balance of a customer: creating an\ninvoice that would take the balance above it is warned about, or\nrefused when CREDIT_LIMIT_BLOCK is set. Null means no limit.",
+                    "type": "integer"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "gstin": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "pan": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Date": {
+            "type": "object",
+            "properties": {
+                "time.Time": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Default": {
+            "type": "object",
+            "properties": {
+                "key": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "$ref": "#/definitions/models.Timestamp"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DefaultInput": {
+            "type": "object",
+            "properties": {
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ImportError": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "external_id": {
+                    "type": "string"
+                },
+                "row": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Invoice": {
+            "type": "object",
+            "properties": {
+                "allocated": {
+                    "type": "integer"
+                },
+                "allocated_pct": {
+                    "description": "allocated / amount * 100, 0 for a zero amount",
+                    "type": "number"
+                },
+                "amount": {
+                    "description": "the total of the items when the invoice has any",
+                    "type": "integer"
+                },
+                "contact_id": {
+                    "type": "integer"
+                },
+                "contact_name": {
+                    "description": "Computed fields",
+                    "type": "string"
+                },
+                "created_at": {
+                    "$ref": "#/definitions/models.Timestamp"
+                },
+                "days_to_due": {
+                    "description": "days from today to due_date, negative when overdue, null without a due date",
+                    "type": "integer"
+                },
+                "due_date": {
+                    "$ref": "#/definitions/models.Date"
+                },
+                "file_url": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "invoice_number": {
+                    "type": "string"
+                },
+                "issue_date": {
+                    "$ref": "#/definitions/models.Date"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.InvoiceItem"
+                    }
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "place_of_supply": {
+                    "description": "two-digit GST state code",
+                    "type": "string"
+                },
+                "sent_at": {
+                    "description": "last time the invoice was sent to the customer, null if never",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Timestamp"
+                        }
+                    ]
+                },
+                "status": {
+                    "type": "string"
+                },
+                "tax_amount": {
+                    "description": "GST included in Amount",
+                    "type": "integer"
+                },
+                "tax_rate": {
+                    "description": "GST rate in percent, null when not recorded",
+                    "type": "number"
+                },
+                "unallocated": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "$ref": "#/definitions/models.Timestamp"
+                }
+            }
+        },
+        "models.InvoiceInput": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "contact_id": {
+                    "type": "integer"
+                },
+                "due_date": {
+                    "type": "string"
+                },
+                "file_url": {
+                    "type": "string"
+                },
+                "invoice_number": {
+                    "type": "string"
+                },
+                "issue_date": {
+                    "type": "string"
+                },
+                "items": {
+                    "description": "with any, Amount and TaxAmount come from them; see ApplyItems",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.InvoiceItemInput"
+                    }
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "place_of_supply": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "tax_amount": {
+                    "type": "integer"
+                },
+                "tax_rate": {
+                    "description": "TaxRate is the GST rate in percent and TaxAmount the GST included in\nAmount. With a rate and no amount, TaxAmount is worked out from Amount.",
+                    "type": "number"
+                }
+            }
+        },
+        "models.InvoiceItem": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "including GST",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "$ref": "#/definitions/models.Timestamp"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "hsn_code": {
+                    "description": "HSN code, or SAC for a service",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "invoice_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "tax_amount": {
+                    "description": "Computed fields",
+                    "type": "integer"
+                },
+                "tax_rate": {
+                    "description": "GST rate in percent; null when the invoice's tax_rate covers the item",
+                    "type": "number"
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "unit_price": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "$ref": "#/definitions/models.Timestamp"
+                }
+            }
+        },
+        "models.InvoiceItemInput": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Amount includes GST; omitted, it is Quantity times UnitPrice.",
+                    "type": "integer"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "hsn_code": {
+                    "description": "4, 6, or 8 digits",
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "tax_rate": {
+                    "type": "number"
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "unit_price": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.OffsetInput": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "description": "AccountID is the account the offset transaction is recorded under. Its\nbalance is unaffected.",
+                    "type": "integer"
+                },
+                "amount": {
+                    "description": "Amount is how much of each document to settle. Zero settles the smaller\nof the two unallocated amounts.",
+                    "type": "integer"
+                },
+                "bill_id": {
+                    "type": "integer"
+                },
+                "date": {
+                    "description": "required, and must fall in an open period",
+                    "type": "string"
+                },
+                "invoice_id": {
+                    "type": "integer"
+                },
+                "note": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Outlet": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "$ref": "#/definitions/models.Timestamp"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "payouts": {
+                    "description": "Computed: number of payouts referencing the outlet",
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "$ref": "#/definitions/models.Timestamp"
+                }
+            }
+        },
+        "models.OutletInput": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Payout": {
+            "type": "object",
+            "properties": {
+                "allocated": {
+                    "description": "Computed fields",
+                    "type": "integer"
+                },
+                "allocated_pct": {
+                    "description": "allocated / final_payout_amt * 100, 0 for a zero payout",
+                    "type": "number"
+                },
+                "created_at": {
+                    "$ref": "#/definitions/models.Timestamp"
+                },
+                "final_payout_amt": {
+                    "type": "integer"
+                },
+                "gross_sales_amt": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "marketing_ads_amt": {
+                    "type": "integer"
+                },
+                "outlet_id": {
+                    "type": "integer"
+                },
+                "outlet_name": {
+                    "description": "Denormalized from the outlet for display",
+                    "type": "string"
+                },
+                "period_end": {
+                    "$ref": "#/definitions/models.Date"
+                },
+                "period_start": {
+                    "$ref": "#/definitions/models.Date"
+                },
+                "platform": {
+                    "description": "swiggy, zomato, swiggy-dineout",
+                    "type": "string"
+                },
+                "platform_commission_amt": {
+                    "type": "integer"
+                },
+                "restaurant_discount_amt": {
+                    "type": "integer"
+                },
+                "settlement_date": {
+                    "$ref": "#/definitions/models.Date"
+                },
+                "taxes_tcs_tds_amt": {
+                    "type": "integer"
+                },
+                "total_orders": {
+                    "type": "integer"
+                },
+                "unallocated": {
+                    "description": "final_payout_amt less allocated and variance_amount",
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "$ref": "#/definitions/models.Timestamp"
+                },
+                "utr_number": {
+                    "type": "string"
+                },
+                "variance_amount": {
+                    "description": "shortfall written off when settling, see PayoutSettleInput",
+                    "type": "integer"
+                },
+                "variance_reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PayoutInput": {
+            "type": "object",
+            "properties": {
+                "create_outlet": {
+                    "description": "Register outlet_name as a new outlet when no outlet matches it",
+                    "type": "boolean"
+                },
+                "final_payout_amt": {
+                    "type": "integer"
+                },
+                "gross_sales_amt": {
+                    "type": "integer"
+                },
+                "marketing_ads_amt": {
+                    "type": "integer"
+                },
+                "outlet_id": {
+                    "description": "Preferred; must reference an existing outlet",
+                    "type": "integer"
+                },
+                "outlet_name": {
+                    "description": "Used to find the outlet when outlet_id is omitted",
+                    "type": "string"
+                },
+                "period_end": {
+                    "type": "string"
+                },
+                "period_start": {
+                    "type": "string"
+                },
+                "platform": {
+                    "type": "string"
+                },
+                "platform_commission_amt": {
+                    "type": "integer"
+                },
+                "restaurant_discount_amt": {
+                    "type": "integer"
+                },
+                "settlement_date": {
+                    "type": "string"
+                },
+                "taxes_tcs_tds_amt": {
+                    "type": "integer"
+                },
+                "total_orders": {
+                    "type": "integer"
+                },
+                "utr_number": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PayoutOrder": {
+            "type": "object",
+            "properties": {
+                "commission_amt": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "$ref": "#/definitions/models.Timestamp"
+                },
+                "gross_amt": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "net_amt": {
+                    "type": "integer"
+                },
+                "order_date": {
+                    "$ref": "#/definitions/models.Date"
+                },
+                "order_id": {
+                    "description": "the platform's order id",
+                    "type": "string"
+                },
+                "payout_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "$ref": "#/definitions/models.Timestamp"
+                }
+            }
+        },
+        "models.PayoutOrderCheck": {
+            "type": "object",
+            "properties": {
+                "difference": {
+                    "description": "orders - header",
+                    "type": "integer"
+                },
+                "field": {
+                    "description": "the payout header field checked",
+                    "type": "string"
+                },
+                "header": {
+                    "type": "integer"
+                },
+                "ok": {
+                    "description": "within the tolerance",
+                    "type": "boolean"
+                },
+                "orders": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PayoutOrderInput": {
+            "type": "object",
+            "properties": {
+                "commission_amt": {
+                    "type": "integer"
+                },
+                "gross_amt": {
+                    "type": "integer"
+                },
+                "net_amt": {
+                    "type": "integer"
+                },
+                "order_date": {
+                    "type": "string"
+                },
+                "order_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PayoutOrdersInput": {
+            "type": "object",
+            "properties": {
+                "orders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PayoutOrderInput"
+                    }
+                }
+            }
+        },
+        "models.PayoutPostInput": {
+            "type": "object",
+            "properties": {
+                "bank_transaction_id": {
+                    "description": "defaults to the one transaction linked to the payout",
+                    "type": "integer"
+                },
+                "clearing_account_id": {
+                    "description": "defaults to the payout.clearing_account default",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PayoutSettleInput": {
+            "type": "object",
+            "properties": {
+                "variance_amount": {
+                    "description": "defaults to the unallocated amount; 0 clears a recorded variance",
+                    "type": "integer"
+                },
+                "variance_reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PeriodInput": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "notes": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ReconcileByReferenceInput": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "references": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.RecurringPayment": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "account_name": {
+                    "description": "Computed fields",
+                    "type": "string"
+                },
                 "amount": {
                     "type": "integer"
                 },
+                "contact_id": {
+                    "type": "integer"
+                },
+                "contact_name": {
+                    "type": "string"
+                },
                 "created_at": {
                     "$ref": "#/definitions/models.Timestamp"
                 },
                 "description": {
                     "type": "string"
                 },
-                "document_id": {
-                    "type": "integer"
+                "end_date": {
+                    "$ref": "#/definitions/models.Date"
                 },
-                "document_type": {
-                    "description": "bill, invoice, payout or recurring_payment_occurrence",
+                "frequency": {
+                    "description": "daily, weekly, monthly, quarterly, yearly",
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
+                "interval": {
+                    "description": "every N frequencies (e.g. 2 = every 2 months)",
+                    "type": "integer"
+                },
+                "last_generated_date": {
+                    "$ref": "#/definitions/models.Date"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "next_due_date": {
+                    "$ref": "#/definitions/models.Date"
+                },
                 "reference": {
                     "type": "string"
                 },
-                "transaction_date": {
+                "start_date": {
+                    "$ref": "#/definitions/models.Date"
+                },
+                "status": {
+                    "description": "active, paused, cancelled, completed",
                     "type": "string"
                 },
-                "transaction_id": {
-                    "type": "integer"
+                "type": {
+                    "description": "income, expense",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "$ref": "#/definitions/models.Timestamp"
                 }
             }
         },
-        "handlers.InvoiceLink": {
+        "models.RecurringPaymentInput": {
             "type": "object",
             "properties": {
-                "account_name": {
-                    "type": "string"
+                "account_id": {
+                    "type": "integer"
                 },
                 "amount": {
                     "type": "integer"
                 },
-                "created_at": {
-                    "$ref": "#/definitions/models.Timestamp"
+                "contact_id": {
+                    "type": "integer"
                 },
                 "description": {
                     "type": "string"
                 },
-                "document_id": {
-                    "type": "integer"
+                "end_date": {
+                    "type": "string"
                 },
-                "document_type": {
-                    "description": "bill, invoice, payout or recurring_payment_occurrence",
+                "frequency": {
                     "type": "string"
                 },
-                "id": {
+                "interval": {
                     "type": "integer"
                 },
+                "last_generated_date": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "next_due_date": {
+                    "type": "string"
+                },
                 "reference": {
                     "type": "string"
                 },
-                "transaction_date": {
+                "start_date": {
                     "type": "string"
                 },
-                "transaction_id": {
-                    "type": "integer"
+                "status": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
                 }
             }
         },
-        "handlers.MatchSuggestion": {
+        "models.RecurringPaymentOccurrence": {
             "type": "object",
             "properties": {
+                "allocated": {
+                    "description": "Computed fields",
+                    "type": "integer"
+                },
                 "amount": {
-                    "description": "total document amount",
                     "type": "integer"
                 },
-                "confidence": {
-                    "description": "0.0 – 1.0",
-                    "type": "number"
+                "created_at": {
+                    "$ref": "#/definitions/models.Timestamp"
                 },
-                "document_date": {
-                    "description": "relevant date for matching",
-                    "type": "string"
+                "due_date": {
+                    "$ref": "#/definitions/models.Date"
                 },
-                "document_id": {
+                "id": {
                     "type": "integer"
                 },
-                "document_ref": {
-                    "description": "reference number or name",
-                    "type": "string"
-                },
-                "document_type": {
-                    "description": "bill, invoice, payout, recurring_payment",
+                "payment_name": {
                     "type": "string"
                 },
-                "linkable": {
-                    "description": "whether this suggestion can be auto-linked",
-                    "type": "boolean"
+                "recurring_payment_id": {
+                    "type": "integer"
                 },
-                "match_reasons": {
-                    "description": "human-readable match reasons",
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "status": {
+                    "description": "pending, paid, skipped",
+                    "type": "string"
                 },
                 "unallocated": {
-                    "description": "unallocated amount (0 for recurring_payment)",
                     "type": "integer"
+                },
+                "updated_at": {
+                    "$ref": "#/definitions/models.Timestamp"
                 }
             }
         },
-        "handlers.PayoutLink": {
+        "models.Timestamp": {
+            "type": "object",
+            "properties": {
+                "time.Time": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Transaction": {
             "type": "object",
             "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
                 "account_name": {
+                    "description": "Computed fields",
                     "type": "string"
                 },
+                "allocated": {
+                    "type": "integer"
+                },
                 "amount": {
                     "type": "integer"
                 },
+                "category_id": {
+                    "type": "integer"
+                },
+                "category_name": {
+                    "type": "string"
+                },
+                "cleared": {
+                    "description": "false while the money is still in transit, e.g. an uncleared cheque",
+                    "type": "boolean"
+                },
+                "cleared_date": {
+                    "description": "when it cleared, if recorded",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Date"
+                        }
+                    ]
+                },
+                "contact_id": {
+                    "type": "integer"
+                },
+                "contact_name": {
+                    "type": "string"
+                },
                 "created_at": {
                     "$ref": "#/definitions/models.Timestamp"
                 },
+                "currency": {
+                    "description": "the account's currency, which Amount is in",
+                    "type": "string"
+                },
                 "description": {
                     "type": "string"
                 },
-                "document_id": {
-                    "type": "integer"
+                "exchange_rate": {
+                    "description": "transfer legs between currencies: destination units per source unit",
+                    "type": "number"
                 },
-                "document_type": {
-                    "description": "bill, invoice, payout or recurring_payment_occurrence",
+                "external_id": {
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
+                "locked": {
+                    "description": "frozen against edits and deletion until unlocked",
+                    "type": "boolean"
+                },
+                "reconciled": {
+                    "type": "boolean"
+                },
                 "reference": {
                     "type": "string"
                 },
+                "sign": {
+                    "description": "adjustments only: 1 adds to the balance, -1 subtracts",
+                    "type": "integer"
+                },
+                "source": {
+                    "type": "string"
+                },
                 "transaction_date": {
+                    "$ref": "#/definitions/models.Date"
+                },
+                "transfer_account_id": {
+                    "type": "integer"
+                },
+                "transfer_account_name": {
+                    "type": "string"
+                },
+                "type": {
+                    "description": "income, expense, transfer, adjustment, offset",
+                    "type": "string"
+                },
+                "unallocated": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "$ref": "#/definitions/models.Timestamp"
+                }
+            }
+        },
+        "models.TransactionDocument": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "$ref": "#/definitions/models.Timestamp"
+                },
+                "document_id": {
+                    "type": "integer"
+                },
+                "document_type": {
+                    "description": "bill, invoice, payout or recurring_payment_occurrence",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "note": {
+                    "description": "why this amount was allocated, e.g. a retention held back",
                     "type": "string"
                 },
                 "transaction_id": {
@@ -4199,614 +13463,713 @@ const docTemplate = `{
                 }
             }
         },
-        "handlers.RecurringPaymentLink": {
+        "models.TransactionDocumentInput": {
             "type": "object",
             "properties": {
-                "account_name": {
+                "amount": {
+                    "type": "integer"
+                },
+                "document_id": {
+                    "type": "integer"
+                },
+                "document_type": {
+                    "type": "string"
+                },
+                "note": {
                     "type": "string"
+                }
+            }
+        },
+        "models.TransactionInput": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "integer"
                 },
                 "amount": {
                     "type": "integer"
                 },
-                "created_at": {
-                    "$ref": "#/definitions/models.Timestamp"
+                "category_id": {
+                    "description": "CategoryID tags an income or expense with a category of the same type\nfor reporting. Omitting it on update clears the category.",
+                    "type": "integer"
                 },
-                "description": {
+                "cleared": {
+                    "description": "Cleared marks whether the money has moved; false records a pending\ncheque or payment. Omitted means cleared on create and keeps the stored\nvalue on update. ClearedDate is when it cleared and implies Cleared.",
+                    "type": "boolean"
+                },
+                "cleared_date": {
                     "type": "string"
                 },
-                "document_id": {
+                "confirmed_large": {
+                    "description": "ConfirmedLarge acknowledges an amount above LARGE_TXN_THRESHOLD.",
+                    "type": "boolean"
+                },
+                "contact_id": {
                     "type": "integer"
                 },
-                "document_type": {
-                    "description": "bill, invoice, payout or recurring_payment_occurrence",
+                "currency": {
+                    "description": "optional ISO 4217 code of Amount; must be the account's (the source account's for a transfer)",
                     "type": "string"
                 },
-                "id": {
-                    "type": "integer"
-                },
-                "occurrence_due_date": {
+                "description": {
                     "type": "string"
                 },
-                "occurrence_status": {
+                "exchange_rate": {
+                    "description": "ExchangeRate converts a transfer between accounts of different\ncurrencies: units of the destination currency per unit of the source\ncurrency. It is required exactly when the currencies differ.",
+                    "type": "number"
+                },
+                "external_id": {
+                    "description": "ExternalID identifies the record in an external system (bank API, POS).\nIt is unique per account and Source: creating a transaction with an\nexisting (account_id, source, external_id) triple updates that\ntransaction instead. On update, omitting either field keeps the stored\nvalue.",
                     "type": "string"
                 },
+                "fee_amount": {
+                    "description": "FeeAmount is an optional charge lost on a transfer. When creating a\ntransfer it is posted as a separate expense on the source account and\nthe paired legs move Amount - FeeAmount.",
+                    "type": "integer"
+                },
                 "reference": {
                     "type": "string"
                 },
+                "sign": {
+                    "description": "Sign gives the direction of an adjustment: 1 adds Amount to the account\nbalance and -1 subtracts it. Adjustments correct a balance (e.g. a wrong\nopening balance) without being income or expense, so they are left out\nof income and expense figures. It is required for adjustments and not\nallowed otherwise.",
+                    "type": "integer"
+                },
+                "source": {
+                    "type": "string"
+                },
                 "transaction_date": {
                     "type": "string"
                 },
-                "transaction_id": {
+                "transfer_account_id": {
                     "type": "integer"
-                }
-            }
-        },
-        "handlers.Response": {
-            "type": "object",
-            "properties": {
-                "data": {},
-                "error": {
+                },
+                "type": {
                     "type": "string"
                 }
             }
         },
-        "handlers.TransactionSuggestion": {
+        "store.AccountReconciliation": {
             "type": "object",
             "properties": {
-                "amount": {
-                    "description": "total transaction amount",
+                "account_id": {
                     "type": "integer"
                 },
-                "confidence": {
-                    "type": "number"
-                },
-                "description": {
+                "account_name": {
                     "type": "string"
                 },
-                "linkable": {
-                    "type": "boolean"
-                },
-                "match_reasons": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
-                },
-                "reference": {
+                "currency": {
                     "type": "string"
                 },
-                "transaction_date": {
-                    "type": "string"
+                "last_reconciled": {
+                    "description": "null when nothing is reconciled yet",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Date"
+                        }
+                    ]
                 },
-                "transaction_id": {
+                "unreconciled": {
                     "type": "integer"
                 },
-                "unallocated": {
-                    "description": "unallocated amount remaining",
+                "unreconciled_amount": {
+                    "description": "summed regardless of direction",
                     "type": "integer"
                 }
             }
         },
-        "handlers.loginRequest": {
+        "store.AllocationBucket": {
             "type": "object",
             "properties": {
-                "email": {
-                    "type": "string"
+                "count": {
+                    "type": "integer"
                 },
-                "password": {
-                    "type": "string"
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "handlers.registerRequest": {
+        "store.AllocationGraphDocument": {
             "type": "object",
             "properties": {
-                "email": {
-                    "type": "string"
+                "allocated": {
+                    "description": "across all transactions",
+                    "type": "integer"
                 },
-                "org_name": {
-                    "type": "string"
+                "amount": {
+                    "type": "integer"
                 },
-                "password": {
+                "missing": {
+                    "type": "boolean"
+                },
+                "status": {
                     "type": "string"
                 }
             }
         },
-        "models.Account": {
+        "store.AllocationGraphLink": {
             "type": "object",
             "properties": {
-                "balance": {
-                    "description": "Computed",
+                "amount": {
                     "type": "integer"
                 },
                 "created_at": {
                     "$ref": "#/definitions/models.Timestamp"
                 },
-                "id": {
+                "document": {
+                    "$ref": "#/definitions/store.AllocationGraphDocument"
+                },
+                "document_id": {
                     "type": "integer"
                 },
-                "name": {
+                "document_type": {
+                    "description": "bill, invoice, payout or recurring_payment_occurrence",
                     "type": "string"
                 },
-                "opening_balance": {
+                "id": {
                     "type": "integer"
                 },
-                "type": {
-                    "description": "bank, cash, credit_card",
+                "note": {
+                    "description": "why this amount was allocated, e.g. a retention held back",
                     "type": "string"
                 },
-                "updated_at": {
-                    "$ref": "#/definitions/models.Timestamp"
-                }
-            }
-        },
-        "models.AccountInput": {
-            "type": "object",
-            "properties": {
-                "name": {
-                    "type": "string"
+                "other_links": {
+                    "description": "links from other transactions to the same document",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.AllocationGraphPeer"
+                    }
                 },
-                "opening_balance": {
+                "transaction_id": {
                     "type": "integer"
-                },
-                "type": {
-                    "type": "string"
                 }
             }
         },
-        "models.Bill": {
+        "store.AllocationGraphPeer": {
             "type": "object",
             "properties": {
-                "allocated": {
-                    "description": "sum of linked transaction_documents amounts",
-                    "type": "integer"
-                },
-                "amount": {
-                    "type": "integer"
-                },
-                "bill_number": {
+                "account_name": {
                     "type": "string"
                 },
-                "contact_id": {
+                "amount": {
                     "type": "integer"
                 },
-                "contact_name": {
-                    "description": "Computed fields",
-                    "type": "string"
-                },
                 "created_at": {
                     "$ref": "#/definitions/models.Timestamp"
                 },
-                "due_date": {
-                    "type": "string"
-                },
-                "file_url": {
+                "description": {
                     "type": "string"
                 },
-                "id": {
+                "document_id": {
                     "type": "integer"
                 },
-                "issue_date": {
+                "document_type": {
+                    "description": "bill, invoice, payout or recurring_payment_occurrence",
                     "type": "string"
                 },
-                "items": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.BillItem"
-                    }
+                "id": {
+                    "type": "integer"
                 },
-                "notes": {
+                "note": {
+                    "description": "why this amount was allocated, e.g. a retention held back",
                     "type": "string"
                 },
-                "status": {
-                    "type": "string"
+                "transaction_date": {
+                    "$ref": "#/definitions/models.Date"
                 },
-                "unallocated": {
-                    "description": "amount - allocated",
+                "transaction_id": {
                     "type": "integer"
-                },
-                "updated_at": {
-                    "$ref": "#/definitions/models.Timestamp"
                 }
             }
         },
-        "models.BillInput": {
+        "store.BalanceIssue": {
             "type": "object",
             "properties": {
-                "amount": {
-                    "type": "integer"
-                },
-                "bill_number": {
+                "problem": {
                     "type": "string"
                 },
+                "transaction_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.ContactImportRow": {
+            "type": "object",
+            "properties": {
                 "contact_id": {
+                    "description": "the created contact, or the existing one it matched",
                     "type": "integer"
                 },
-                "due_date": {
-                    "type": "string"
-                },
-                "file_url": {
+                "error": {
                     "type": "string"
                 },
-                "issue_date": {
+                "name": {
                     "type": "string"
                 },
-                "items": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.BillItemInput"
-                    }
+                "row": {
+                    "type": "integer"
                 },
-                "notes": {
+                "status": {
+                    "description": "created, merged, skipped, or error",
                     "type": "string"
+                }
+            }
+        },
+        "store.CurrencyAllocationBucket": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
                 },
-                "status": {
+                "currency": {
                     "type": "string"
+                },
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "models.BillItem": {
+        "store.DashboardData": {
             "type": "object",
             "properties": {
-                "amount": {
+                "bills_payable": {
                     "type": "integer"
                 },
-                "bill_id": {
+                "documents_by_status": {
+                    "$ref": "#/definitions/store.DocumentsByStatus"
+                },
+                "invoices_receivable": {
                     "type": "integer"
                 },
-                "created_at": {
-                    "$ref": "#/definitions/models.Timestamp"
+                "overdue_bills": {
+                    "type": "integer"
                 },
-                "description": {
-                    "type": "string"
+                "overdue_invoices": {
+                    "type": "integer"
                 },
-                "id": {
+                "payouts_received": {
                     "type": "integer"
                 },
-                "quantity": {
-                    "type": "number"
+                "recent_transactions": {
+                    "type": "array",
+                    "items": {
+                        "type": "object",
+                        "additionalProperties": {}
+                    }
                 },
-                "unit": {
-                    "type": "string"
+                "reconciliation": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.AccountReconciliation"
+                    }
                 },
-                "unit_price": {
+                "total_accounts": {
                     "type": "integer"
                 },
-                "updated_at": {
-                    "$ref": "#/definitions/models.Timestamp"
+                "total_bills": {
+                    "type": "integer"
+                },
+                "total_contacts": {
+                    "type": "integer"
+                },
+                "total_invoices": {
+                    "type": "integer"
+                },
+                "total_payouts": {
+                    "type": "integer"
+                },
+                "total_transactions": {
+                    "type": "integer"
                 }
             }
         },
-        "models.BillItemInput": {
+        "store.Deletion": {
             "type": "object",
             "properties": {
-                "amount": {
-                    "type": "integer"
-                },
-                "description": {
-                    "type": "string"
+                "deleted_at": {
+                    "$ref": "#/definitions/models.Timestamp"
                 },
-                "quantity": {
-                    "type": "number"
+                "id": {
+                    "type": "integer"
                 },
-                "unit": {
+                "resource": {
+                    "description": "account, contact, outlet, category, bill, invoice, transaction, payout, or recurring_payment",
                     "type": "string"
-                },
-                "unit_price": {
-                    "type": "integer"
                 }
             }
         },
-        "models.Contact": {
+        "store.DocumentsByStatus": {
             "type": "object",
             "properties": {
-                "allocated_amount": {
-                    "description": "Computed: Sum of payments",
-                    "type": "integer"
-                },
-                "balance": {
-                    "description": "Computed: Total - Allocated",
-                    "type": "integer"
+                "bills": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/store.StatusTotals"
+                    }
                 },
-                "created_at": {
-                    "$ref": "#/definitions/models.Timestamp"
+                "invoices": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/store.StatusTotals"
+                    }
+                }
+            }
+        },
+        "store.EInvoice": {
+            "type": "object",
+            "properties": {
+                "BuyerDtls": {
+                    "$ref": "#/definitions/store.EInvoiceParty"
                 },
-                "email": {
-                    "type": "string"
+                "DocDtls": {
+                    "$ref": "#/definitions/store.EInvoiceDocument"
                 },
-                "id": {
-                    "type": "integer"
+                "ItemList": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.EInvoiceItem"
+                    }
                 },
-                "name": {
-                    "type": "string"
+                "SellerDtls": {
+                    "$ref": "#/definitions/store.EInvoiceParty"
                 },
-                "phone": {
-                    "type": "string"
+                "TranDtls": {
+                    "$ref": "#/definitions/store.EInvoiceTransaction"
                 },
-                "total_amount": {
-                    "description": "Computed: Sum of bills/invoices",
-                    "type": "integer"
+                "ValDtls": {
+                    "$ref": "#/definitions/store.EInvoiceValues"
                 },
-                "type": {
-                    "description": "vendor, customer",
+                "Version": {
                     "type": "string"
-                },
-                "updated_at": {
-                    "$ref": "#/definitions/models.Timestamp"
                 }
             }
         },
-        "models.ContactInput": {
+        "store.EInvoiceDocument": {
             "type": "object",
             "properties": {
-                "email": {
-                    "type": "string"
-                },
-                "name": {
+                "Dt": {
+                    "description": "DD/MM/YYYY",
                     "type": "string"
                 },
-                "phone": {
+                "No": {
                     "type": "string"
                 },
-                "type": {
+                "Typ": {
+                    "description": "INV",
                     "type": "string"
                 }
             }
         },
-        "models.Invoice": {
+        "store.EInvoiceItem": {
             "type": "object",
             "properties": {
-                "allocated": {
-                    "type": "integer"
+                "AssAmt": {
+                    "type": "number"
                 },
-                "amount": {
-                    "type": "integer"
+                "CgstAmt": {
+                    "type": "number"
                 },
-                "contact_id": {
-                    "type": "integer"
+                "GstRt": {
+                    "type": "number"
                 },
-                "contact_name": {
-                    "description": "Computed fields",
+                "HsnCd": {
                     "type": "string"
                 },
-                "created_at": {
-                    "$ref": "#/definitions/models.Timestamp"
+                "IgstAmt": {
+                    "type": "number"
                 },
-                "due_date": {
+                "IsServc": {
+                    "description": "Y or N",
                     "type": "string"
                 },
-                "file_url": {
+                "PrdDesc": {
                     "type": "string"
                 },
-                "id": {
-                    "type": "integer"
+                "Qty": {
+                    "type": "number"
                 },
-                "invoice_number": {
-                    "type": "string"
+                "SgstAmt": {
+                    "type": "number"
                 },
-                "issue_date": {
+                "SlNo": {
                     "type": "string"
                 },
-                "items": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.InvoiceItem"
-                    }
+                "TotAmt": {
+                    "type": "number"
                 },
-                "notes": {
-                    "type": "string"
+                "TotItemVal": {
+                    "type": "number"
                 },
-                "status": {
+                "Unit": {
                     "type": "string"
                 },
-                "unallocated": {
-                    "type": "integer"
-                },
-                "updated_at": {
-                    "$ref": "#/definitions/models.Timestamp"
+                "UnitPrice": {
+                    "type": "number"
                 }
             }
         },
-        "models.InvoiceInput": {
+        "store.EInvoiceParty": {
             "type": "object",
             "properties": {
-                "amount": {
-                    "type": "integer"
-                },
-                "contact_id": {
-                    "type": "integer"
-                },
-                "due_date": {
+                "Addr1": {
                     "type": "string"
                 },
-                "file_url": {
+                "Gstin": {
                     "type": "string"
                 },
-                "invoice_number": {
+                "LglNm": {
                     "type": "string"
                 },
-                "issue_date": {
+                "Loc": {
                     "type": "string"
                 },
-                "items": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.InvoiceItemInput"
-                    }
+                "Pin": {
+                    "type": "integer"
                 },
-                "notes": {
+                "Pos": {
+                    "description": "buyer only: two-digit state code",
                     "type": "string"
                 },
-                "status": {
+                "Stcd": {
                     "type": "string"
                 }
             }
         },
-        "models.InvoiceItem": {
+        "store.EInvoiceTransaction": {
             "type": "object",
             "properties": {
-                "amount": {
-                    "type": "integer"
-                },
-                "created_at": {
-                    "$ref": "#/definitions/models.Timestamp"
-                },
-                "description": {
+                "RegRev": {
+                    "description": "Y or N",
                     "type": "string"
                 },
-                "id": {
-                    "type": "integer"
+                "SupTyp": {
+                    "description": "B2B",
+                    "type": "string"
                 },
-                "invoice_id": {
-                    "type": "integer"
+                "TaxSch": {
+                    "description": "always GST",
+                    "type": "string"
+                }
+            }
+        },
+        "store.EInvoiceValues": {
+            "type": "object",
+            "properties": {
+                "AssVal": {
+                    "type": "number"
                 },
-                "quantity": {
+                "CgstVal": {
                     "type": "number"
                 },
-                "unit": {
-                    "type": "string"
+                "IgstVal": {
+                    "type": "number"
                 },
-                "unit_price": {
-                    "type": "integer"
+                "SgstVal": {
+                    "type": "number"
                 },
-                "updated_at": {
-                    "$ref": "#/definitions/models.Timestamp"
+                "TotInvVal": {
+                    "type": "number"
                 }
             }
         },
-        "models.InvoiceItemInput": {
+        "store.FXReportRow": {
             "type": "object",
             "properties": {
-                "amount": {
+                "account_id": {
                     "type": "integer"
                 },
-                "description": {
+                "account_name": {
                     "type": "string"
                 },
-                "quantity": {
+                "balance": {
+                    "description": "in Currency",
+                    "type": "integer"
+                },
+                "book_value": {
+                    "description": "Balance at RecordedRate, or at Rate without one",
+                    "type": "integer"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "gain_loss": {
+                    "description": "RevaluedValue - BookValue; positive is a gain",
+                    "type": "integer"
+                },
+                "rate": {
+                    "description": "null when no rate is supplied or stored",
                     "type": "number"
                 },
-                "unit": {
+                "rate_source": {
+                    "description": "supplied, stored, or empty without a rate",
                     "type": "string"
                 },
-                "unit_price": {
+                "recorded_rate": {
+                    "description": "average rate of transfers to and from the books currency; null without any",
+                    "type": "number"
+                },
+                "revalued_value": {
+                    "description": "Balance at Rate",
                     "type": "integer"
                 }
             }
         },
-        "models.Payout": {
+        "store.GSTR1B2B": {
             "type": "object",
             "properties": {
-                "allocated": {
-                    "description": "Computed fields",
-                    "type": "integer"
-                },
-                "created_at": {
-                    "$ref": "#/definitions/models.Timestamp"
+                "ctin": {
+                    "type": "string"
                 },
-                "final_payout_amt": {
-                    "type": "integer"
+                "inv": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.GSTR1Invoice"
+                    }
+                }
+            }
+        },
+        "store.GSTR1B2CL": {
+            "type": "object",
+            "properties": {
+                "inv": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.GSTR1Invoice"
+                    }
                 },
-                "gross_sales_amt": {
-                    "type": "integer"
+                "pos": {
+                    "type": "string"
+                }
+            }
+        },
+        "store.GSTR1B2CS": {
+            "type": "object",
+            "properties": {
+                "camt": {
+                    "type": "number"
                 },
-                "id": {
-                    "type": "integer"
+                "csamt": {
+                    "type": "number"
                 },
-                "marketing_ads_amt": {
-                    "type": "integer"
+                "iamt": {
+                    "type": "number"
                 },
-                "outlet_name": {
+                "pos": {
                     "type": "string"
                 },
-                "period_end": {
-                    "type": "string"
+                "rt": {
+                    "type": "number"
                 },
-                "period_start": {
-                    "type": "string"
+                "samt": {
+                    "type": "number"
                 },
-                "platform": {
-                    "description": "swiggy, zomato, swiggy-dineout",
+                "sply_ty": {
                     "type": "string"
                 },
-                "platform_commission_amt": {
-                    "type": "integer"
+                "txval": {
+                    "type": "number"
                 },
-                "restaurant_discount_amt": {
-                    "type": "integer"
+                "typ": {
+                    "type": "string"
+                }
+            }
+        },
+        "store.GSTR1Invoice": {
+            "type": "object",
+            "properties": {
+                "idt": {
+                    "description": "DD-MM-YYYY",
+                    "type": "string"
                 },
-                "settlement_date": {
+                "inum": {
                     "type": "string"
                 },
-                "taxes_tcs_tds_amt": {
-                    "type": "integer"
+                "inv_typ": {
+                    "type": "string"
                 },
-                "total_orders": {
-                    "type": "integer"
+                "itms": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.GSTR1Item"
+                    }
                 },
-                "unallocated": {
-                    "type": "integer"
+                "pos": {
+                    "type": "string"
                 },
-                "utr_number": {
+                "rchrg": {
                     "type": "string"
+                },
+                "val": {
+                    "type": "number"
                 }
             }
         },
-        "models.PayoutInput": {
+        "store.GSTR1Item": {
             "type": "object",
             "properties": {
-                "final_payout_amt": {
-                    "type": "integer"
+                "itm_det": {
+                    "$ref": "#/definitions/store.GSTR1ItemDetail"
                 },
-                "gross_sales_amt": {
+                "num": {
                     "type": "integer"
+                }
+            }
+        },
+        "store.GSTR1ItemDetail": {
+            "type": "object",
+            "properties": {
+                "camt": {
+                    "type": "number"
                 },
-                "marketing_ads_amt": {
-                    "type": "integer"
+                "csamt": {
+                    "type": "number"
                 },
-                "outlet_name": {
-                    "type": "string"
+                "iamt": {
+                    "type": "number"
                 },
-                "period_end": {
-                    "type": "string"
+                "rt": {
+                    "type": "number"
                 },
-                "period_start": {
-                    "type": "string"
+                "samt": {
+                    "type": "number"
                 },
-                "platform": {
-                    "type": "string"
+                "txval": {
+                    "type": "number"
+                }
+            }
+        },
+        "store.GSTR1Return": {
+            "type": "object",
+            "properties": {
+                "b2b": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.GSTR1B2B"
+                    }
                 },
-                "platform_commission_amt": {
-                    "type": "integer"
+                "b2cl": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.GSTR1B2CL"
+                    }
                 },
-                "restaurant_discount_amt": {
-                    "type": "integer"
+                "b2cs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.GSTR1B2CS"
+                    }
                 },
-                "settlement_date": {
+                "fp": {
+                    "description": "MMYYYY, set when the range is one calendar month",
                     "type": "string"
                 },
-                "taxes_tcs_tds_amt": {
-                    "type": "integer"
-                },
-                "total_orders": {
-                    "type": "integer"
-                },
-                "utr_number": {
+                "gstin": {
                     "type": "string"
                 }
             }
         },
-        "models.RecurringPayment": {
+        "store.GSTR1Row": {
             "type": "object",
             "properties": {
-                "account_id": {
-                    "type": "integer"
-                },
-                "account_name": {
-                    "description": "Computed fields",
-                    "type": "string"
-                },
-                "amount": {
+                "cgst": {
                     "type": "integer"
                 },
                 "contact_id": {
@@ -4815,310 +14178,299 @@ const docTemplate = `{
                 "contact_name": {
                     "type": "string"
                 },
-                "created_at": {
-                    "$ref": "#/definitions/models.Timestamp"
-                },
-                "description": {
-                    "type": "string"
-                },
-                "end_date": {
-                    "type": "string"
-                },
-                "frequency": {
-                    "description": "daily, weekly, monthly, quarterly, yearly",
+                "gstin": {
+                    "description": "the buyer's, null for unregistered buyers",
                     "type": "string"
                 },
-                "id": {
+                "igst": {
                     "type": "integer"
                 },
-                "interval": {
-                    "description": "every N frequencies (e.g. 2 = every 2 months)",
+                "invoice_date": {
+                    "$ref": "#/definitions/models.Date"
+                },
+                "invoice_id": {
                     "type": "integer"
                 },
-                "last_generated_date": {
+                "invoice_number": {
                     "type": "string"
                 },
-                "name": {
-                    "type": "string"
+                "invoice_value": {
+                    "type": "integer"
                 },
-                "next_due_date": {
+                "place_of_supply": {
+                    "description": "two-digit state code",
                     "type": "string"
                 },
-                "reference": {
+                "section": {
+                    "description": "b2b, b2cl, or b2cs",
                     "type": "string"
                 },
-                "start_date": {
-                    "type": "string"
+                "sgst": {
+                    "type": "integer"
                 },
-                "status": {
-                    "description": "active, paused, cancelled, completed",
+                "supply_type": {
+                    "description": "INTRA or INTER",
                     "type": "string"
                 },
-                "type": {
-                    "description": "income, expense",
-                    "type": "string"
+                "tax_amount": {
+                    "type": "integer"
                 },
-                "updated_at": {
-                    "$ref": "#/definitions/models.Timestamp"
+                "tax_rate": {
+                    "type": "number"
+                },
+                "taxable_value": {
+                    "type": "integer"
                 }
             }
         },
-        "models.RecurringPaymentInput": {
+        "store.InvoiceLedgerEntry": {
             "type": "object",
             "properties": {
-                "account_id": {
-                    "type": "integer"
-                },
                 "amount": {
                     "type": "integer"
                 },
-                "contact_id": {
-                    "type": "integer"
+                "date": {
+                    "$ref": "#/definitions/models.Date"
                 },
                 "description": {
                     "type": "string"
                 },
-                "end_date": {
-                    "type": "string"
-                },
-                "frequency": {
+                "event": {
+                    "description": "created, sent, payment, or adjustment",
                     "type": "string"
                 },
-                "interval": {
+                "link_id": {
                     "type": "integer"
                 },
-                "last_generated_date": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string"
-                },
-                "next_due_date": {
-                    "type": "string"
-                },
-                "reference": {
-                    "type": "string"
-                },
-                "start_date": {
-                    "type": "string"
-                },
-                "status": {
-                    "type": "string"
+                "outstanding": {
+                    "description": "after this entry",
+                    "type": "integer"
                 },
-                "type": {
-                    "type": "string"
+                "transaction_id": {
+                    "type": "integer"
                 }
             }
         },
-        "models.RecurringPaymentOccurrence": {
+        "store.OverAllocation": {
             "type": "object",
             "properties": {
                 "allocated": {
-                    "description": "Computed fields",
                     "type": "integer"
                 },
                 "amount": {
+                    "description": "what can be allocated: a payout's net of its variance, twice an offset's amount",
                     "type": "integer"
                 },
-                "created_at": {
-                    "$ref": "#/definitions/models.Timestamp"
-                },
-                "due_date": {
-                    "type": "string"
+                "excess": {
+                    "type": "integer"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "payment_name": {
+                "reference": {
+                    "description": "bill or invoice number, UTR, or bank reference",
                     "type": "string"
                 },
-                "recurring_payment_id": {
-                    "type": "integer"
-                },
-                "status": {
-                    "description": "pending, paid, skipped",
+                "type": {
+                    "description": "bill, invoice, payout, or transaction",
                     "type": "string"
-                },
-                "unallocated": {
-                    "type": "integer"
-                },
-                "updated_at": {
-                    "$ref": "#/definitions/models.Timestamp"
                 }
             }
         },
-        "models.Timestamp": {
+        "store.PayoutImportRow": {
             "type": "object",
             "properties": {
-                "time.Time": {
+                "error": {
+                    "type": "string"
+                },
+                "outlet_name": {
+                    "type": "string"
+                },
+                "payout_id": {
+                    "description": "the created payout, or the existing one it matched",
+                    "type": "integer"
+                },
+                "row": {
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "created, skipped, or error",
+                    "type": "string"
+                },
+                "utr_number": {
                     "type": "string"
                 }
             }
         },
-        "models.Transaction": {
+        "store.PayoutLink": {
             "type": "object",
             "properties": {
-                "account_id": {
-                    "type": "integer"
-                },
                 "account_name": {
-                    "description": "Computed fields",
                     "type": "string"
                 },
-                "allocated": {
-                    "type": "integer"
-                },
                 "amount": {
                     "type": "integer"
                 },
-                "contact_id": {
-                    "type": "integer"
-                },
-                "contact_name": {
-                    "type": "string"
-                },
                 "created_at": {
                     "$ref": "#/definitions/models.Timestamp"
                 },
                 "description": {
                     "type": "string"
                 },
-                "id": {
+                "document_id": {
                     "type": "integer"
                 },
-                "reference": {
-                    "type": "string"
-                },
-                "transaction_date": {
+                "document_type": {
+                    "description": "bill, invoice, payout or recurring_payment_occurrence",
                     "type": "string"
                 },
-                "transfer_account_id": {
+                "id": {
                     "type": "integer"
                 },
-                "transfer_account_name": {
+                "note": {
+                    "description": "why this amount was allocated, e.g. a retention held back",
                     "type": "string"
                 },
-                "type": {
-                    "description": "income, expense, transfer",
+                "reference": {
                     "type": "string"
                 },
-                "unallocated": {
-                    "type": "integer"
+                "transaction_date": {
+                    "type": "string"
                 },
-                "updated_at": {
-                    "$ref": "#/definitions/models.Timestamp"
+                "transaction_id": {
+                    "type": "integer"
                 }
             }
         },
-        "models.TransactionDocument": {
+        "store.PayoutVarianceRow": {
             "type": "object",
             "properties": {
-                "amount": {
+                "date": {
+                    "description": "settlement date, or period end when unsettled",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Date"
+                        }
+                    ]
+                },
+                "final_payout_amt": {
                     "type": "integer"
                 },
-                "created_at": {
-                    "$ref": "#/definitions/models.Timestamp"
+                "outlet_name": {
+                    "type": "string"
                 },
-                "document_id": {
+                "payout_id": {
                     "type": "integer"
                 },
-                "document_type": {
-                    "description": "bill, invoice, payout or recurring_payment_occurrence",
+                "platform": {
                     "type": "string"
                 },
-                "id": {
-                    "type": "integer"
+                "utr_number": {
+                    "type": "string"
                 },
-                "transaction_id": {
+                "variance_amount": {
                     "type": "integer"
+                },
+                "variance_reason": {
+                    "type": "string"
                 }
             }
         },
-        "models.TransactionDocumentInput": {
+        "store.StatusRecount": {
             "type": "object",
             "properties": {
-                "amount": {
+                "changed": {
                     "type": "integer"
                 },
-                "document_id": {
+                "checked": {
                     "type": "integer"
-                },
-                "document_type": {
-                    "type": "string"
                 }
             }
         },
-        "models.TransactionInput": {
+        "store.StatusTotals": {
             "type": "object",
             "properties": {
-                "account_id": {
+                "allocated": {
                     "type": "integer"
                 },
                 "amount": {
                     "type": "integer"
                 },
-                "contact_id": {
+                "count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.TDSReportRow": {
+            "type": "object",
+            "properties": {
+                "gross_sales_amt": {
                     "type": "integer"
                 },
-                "description": {
-                    "type": "string"
-                },
-                "reference": {
+                "month": {
+                    "description": "YYYY-MM, empty for undated payouts",
                     "type": "string"
                 },
-                "transaction_date": {
+                "outlet_name": {
                     "type": "string"
                 },
-                "transfer_account_id": {
+                "payouts": {
                     "type": "integer"
                 },
-                "type": {
+                "platform": {
                     "type": "string"
+                },
+                "taxes_tcs_tds_amt": {
+                    "type": "integer"
                 }
             }
         },
-        "store.DashboardData": {
+        "store.TrialBalanceRow": {
             "type": "object",
             "properties": {
-                "bills_payable": {
-                    "type": "integer"
-                },
-                "invoices_receivable": {
+                "account_id": {
+                    "description": "nil for the derived receivables, payables, and equity lines",
                     "type": "integer"
                 },
-                "overdue_bills": {
+                "credit": {
                     "type": "integer"
                 },
-                "overdue_invoices": {
-                    "type": "integer"
+                "currency": {
+                    "type": "string"
                 },
-                "payouts_received": {
+                "debit": {
                     "type": "integer"
                 },
-                "recent_transactions": {
-                    "type": "array",
-                    "items": {
-                        "type": "object",
-                        "additionalProperties": {}
-                    }
+                "kind": {
+                    "description": "account, receivables, payables, or equity",
+                    "type": "string"
                 },
-                "total_accounts": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "store.VendorSpendRow": {
+            "type": "object",
+            "properties": {
+                "billed": {
                     "type": "integer"
                 },
-                "total_bills": {
+                "bills": {
                     "type": "integer"
                 },
-                "total_contacts": {
+                "contact_id": {
                     "type": "integer"
                 },
-                "total_invoices": {
-                    "type": "integer"
+                "contact_name": {
+                    "type": "string"
                 },
-                "total_payouts": {
+                "paid": {
                     "type": "integer"
                 },
-                "total_transactions": {
-                    "type": "integer"
+                "pct_of_total": {
+                    "description": "share of the total billed, in percent",
+                    "type": "number"
                 }
             }
         }
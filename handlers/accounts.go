@@ -4,11 +4,10 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
-	"strconv"
 
-	"github.com/go-chi/chi/v5"
 	"github.com/satheeshds/portal/models"
 	"github.com/satheeshds/portal/store"
 )
@@ -27,7 +26,7 @@ func ListAccounts(w http.ResponseWriter, r *http.Request) {
 	search := r.URL.Query().Get("search")
 	accounts, err := s.ListAccounts(search)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeInternalError(w, r, err)
 		return
 	}
 	slog.Debug("Accounts", "rowCount", len(accounts))
@@ -36,23 +35,27 @@ func ListAccounts(w http.ResponseWriter, r *http.Request) {
 
 // GetAccount retrieves a single account by ID
 //	@Summary		Get account
-//	@Description	Get details and current balance of a specific account.
+//	@Description	Get details and current balance of a specific account. cleared_balance leaves out pending (cleared: false) transactions; projected_balance includes them.
 //	@Tags			accounts
 //	@Produce		json
 //	@Param			id	path		int	true	"Account ID"
 //	@Success		200	{object}	Response{data=models.Account}
-//	@Failure		404	{object}	Response{error=string}
+//	@Failure		400	{object}	Response{error=string}
+//	@Failure		404	{object}	Response{error=string,code=string,resource=string}
 //	@Router			/accounts/{id} [get]
 //	@Security		BearerAuth
 func GetAccount(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
 	a, err := s.GetAccount(id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			writeError(w, http.StatusNotFound, "account not found")
+			writeNotFound(w, "account")
 		} else {
-			writeError(w, http.StatusInternalServerError, err.Error())
+			writeInternalError(w, r, err)
 		}
 		return
 	}
@@ -61,12 +64,13 @@ func GetAccount(w http.ResponseWriter, r *http.Request) {
 
 // CreateAccount creates a new account
 //	@Summary		Create account
-//	@Description	Create a new bank account, cash or credit card.
+//	@Description	Create a new bank account, cash, credit card or loan. opening_balance is signed from the asset side: an amount owed on a card or loan is negative. A negative opening on a bank or cash account is accepted as an overdraft with a warning.
+//	@Description	Amounts are entered in whole units of the account's currency and kept in its smallest unit, e.g. 1000 yen is 1000 in a JPY account and 1.5 dinars is 1500 in a KWD one.
 //	@Tags			accounts
 //	@Accept			json
 //	@Produce		json
 //	@Param			account	body		models.AccountInput	true	"Account contents"
-//	@Success		201		{object}	Response{data=models.Account}
+//	@Success		201		{object}	Response{data=AccountResult}
 //	@Failure		400		{object}	Response{error=string}
 //	@Router			/accounts [post]
 //	@Security		BearerAuth
@@ -81,31 +85,48 @@ func CreateAccount(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, msg)
 		return
 	}
+	if input.Currency == "" {
+		input.SetCurrency(models.DefaultCurrency)
+	} else {
+		input.SetCurrency(input.Currency)
+	}
 
 	a, err := s.CreateAccount(input)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeInternalError(w, r, err)
 		return
 	}
-	writeJSON(w, http.StatusCreated, a)
+	publishEvent(r, "created", "account", a.ID)
+	writeJSON(w, http.StatusCreated, AccountResult{Account: a, Warnings: input.Warnings()})
+}
+
+// AccountResult is a created or updated account plus any warnings about its
+// input, such as a negative opening balance on a bank or cash account.
+type AccountResult struct {
+	models.Account
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 // UpdateAccount updates an existing account
 //	@Summary		Update account
-//	@Description	Update details of an existing account.
+//	@Description	Update details of an existing account. The currency of an account with transactions cannot change, as their amounts would not be converted.
 //	@Tags			accounts
 //	@Accept			json
 //	@Produce		json
 //	@Param			id		path		int					true	"Account ID"
 //	@Param			account	body		models.AccountInput	true	"Updated account contents"
-//	@Success		200		{object}	Response{data=models.Account}
+//	@Success		200		{object}	Response{data=AccountResult}
 //	@Failure		400		{object}	Response{error=string}
 //	@Failure		404		{object}	Response{error=string}
+//	@Failure		409		{object}	Response{error=string}
 //	@Router			/accounts/{id} [put]
 //	@Security		BearerAuth
 func UpdateAccount(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
 	var input models.AccountInput
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid JSON")
@@ -115,17 +136,44 @@ func UpdateAccount(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, msg)
 		return
 	}
+	existing, err := s.GetAccount(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "account not found")
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+	if input.Currency == "" {
+		input.SetCurrency(existing.Currency)
+	} else {
+		input.SetCurrency(input.Currency)
+	}
+	if input.Currency != "" && existing.Currency != input.Currency {
+		impact, err := s.GetAccountDeleteImpact(id)
+		if err != nil {
+			writeInternalError(w, r, err)
+			return
+		}
+		if impact.Transactions > 0 {
+			writeError(w, http.StatusConflict, fmt.Sprintf("account has %d transactions in %s; its currency cannot change",
+				impact.Transactions, existing.Currency))
+			return
+		}
+	}
 
 	a, err := s.UpdateAccount(id, input)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			writeError(w, http.StatusNotFound, "account not found")
 		} else {
-			writeError(w, http.StatusInternalServerError, err.Error())
+			writeInternalError(w, r, err)
 		}
 		return
 	}
-	writeJSON(w, http.StatusOK, a)
+	publishEvent(r, "updated", "account", a.ID)
+	writeJSON(w, http.StatusOK, AccountResult{Account: a, Warnings: input.Warnings()})
 }
 
 // DeleteAccount deletes an account
@@ -135,19 +183,134 @@ func UpdateAccount(w http.ResponseWriter, r *http.Request) {
 //	@Produce		json
 //	@Param			id	path		int	true	"Account ID"
 //	@Success		200	{object}	Response{data=map[string]string}
+//	@Failure		400	{object}	Response{error=string}
 //	@Failure		404	{object}	Response{error=string}
 //	@Router			/accounts/{id} [delete]
 //	@Security		BearerAuth
 func DeleteAccount(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
 	if err := s.DeleteAccount(id); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			writeError(w, http.StatusNotFound, "account not found")
 		} else {
-			writeError(w, http.StatusInternalServerError, err.Error())
+			writeInternalError(w, r, err)
 		}
 		return
 	}
+	publishEvent(r, "deleted", "account", id)
 	writeJSON(w, http.StatusOK, map[string]string{"message": "deleted"})
 }
+
+// AccountDeleteImpact is an alias for store.AccountDeleteImpact kept here for Swagger doc references.
+type AccountDeleteImpact = store.AccountDeleteImpact
+
+// GetAccountDeleteImpact previews what deleting an account would affect
+//	@Summary		Preview account deletion
+//	@Description	Count the transactions, transfer legs, recurring payments, and default settings that reference the account. Deletion does not cascade, so these
+//	@Description	rows would be left pointing at a missing account; use the counts to confirm before deleting.
+//	@Tags			accounts
+//	@Produce		json
+//	@Param			id	path		int	true	"Account ID"
+//	@Success		200	{object}	Response{data=AccountDeleteImpact}
+//	@Failure		400	{object}	Response{error=string}
+//	@Failure		404	{object}	Response{error=string,code=string,resource=string}
+//	@Router			/accounts/{id}/delete-impact [get]
+//	@Security		BearerAuth
+func GetAccountDeleteImpact(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+	if _, err := s.GetAccount(id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeNotFound(w, "account")
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+	impact, err := s.GetAccountDeleteImpact(id)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, impact)
+}
+
+// AccountVerification is an alias for store.AccountVerification kept here for Swagger doc references.
+type AccountVerification = store.AccountVerification
+
+// VerifyAccount recomputes an account's balance to check it ties out
+//	@Summary		Verify account balance
+//	@Description	Recompute the balance from the opening balance and each transaction on the account and compare it with the displayed balance.
+//	@Description	Reports the discrepancy, the count and net sum of the transactions considered, and transactions that cannot be counted
+//	@Description	or transfer legs missing their counterpart on the other account. consistent is true when nothing is off.
+//	@Tags			accounts
+//	@Produce		json
+//	@Param			id	path		int	true	"Account ID"
+//	@Success		200	{object}	Response{data=AccountVerification}
+//	@Failure		400	{object}	Response{error=string}
+//	@Failure		404	{object}	Response{error=string,code=string,resource=string}
+//	@Router			/accounts/{id}/verify [get]
+//	@Security		BearerAuth
+func VerifyAccount(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+	v, err := s.VerifyAccountBalance(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeNotFound(w, "account")
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, v)
+}
+
+// AccountStats is an alias for store.AccountStats kept here for Swagger doc references.
+type AccountStats = store.AccountStats
+
+// GetAccountStats summarises an account's transactions over a period
+//	@Summary		Get account stats
+//	@Description	Total inflow, outflow, and net of the account's transactions dated from to to, with the transaction count and the largest single income and expense.
+//	@Description	Transfers to and from other accounts are reported separately as transfers_in and transfers_out and do not count as inflow, outflow, or the largest income or expense.
+//	@Tags			accounts
+//	@Produce		json
+//	@Param			id		path		int		true	"Account ID"
+//	@Param			from	query		string	false	"Start date (YYYY-MM-DD)"
+//	@Param			to		query		string	false	"End date (YYYY-MM-DD)"
+//	@Success		200		{object}	Response{data=AccountStats}
+//	@Failure		400		{object}	Response{error=string}
+//	@Failure		404		{object}	Response{error=string,code=string,resource=string}
+//	@Router			/accounts/{id}/stats [get]
+//	@Security		BearerAuth
+func GetAccountStats(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+	from, to, ok := reportRange(w, r)
+	if !ok {
+		return
+	}
+	stats, err := s.GetAccountStats(id, from, to)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeNotFound(w, "account")
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
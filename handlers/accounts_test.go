@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+// TestGetAccountDeleteImpact verifies that the delete preview counts the
+// transactions on an account, the transfer legs naming it, and the defaults
+// pointing at it.
+func TestGetAccountDeleteImpact(t *testing.T) {
+	r, cleanup := setupTestRouter(t)
+	defer cleanup()
+	r.Get("/api/v1/accounts/{id}/delete-impact", GetAccountDeleteImpact)
+	r.Put("/api/v1/defaults/{key}", SetDefault)
+
+	var accIDs []int
+	for _, name := range []string{"Current", "Savings"} {
+		_, resp := apiRequest(t, r, "POST", "/api/v1/accounts", map[string]interface{}{"name": name, "type": "bank"})
+		accIDs = append(accIDs, int(resp["data"].(map[string]interface{})["id"].(float64)))
+	}
+	for _, body := range []map[string]interface{}{
+		{"account_id": accIDs[0], "type": "income", "amount": 10.0, "transaction_date": "2024-01-15"},
+		{"account_id": accIDs[1], "type": "transfer", "amount": 20.0, "transaction_date": "2024-01-16", "transfer_account_id": accIDs[0]},
+	} {
+		if status, resp := apiRequest(t, r, "POST", "/api/v1/transactions", body); status != http.StatusCreated {
+			t.Fatalf("create transaction: status %d, error %v", status, resp["error"])
+		}
+	}
+	if status, resp := apiRequest(t, r, "PUT", "/api/v1/defaults/default_account.income", map[string]interface{}{
+		"value": fmt.Sprint(accIDs[0]),
+	}); status != http.StatusOK {
+		t.Fatalf("set default: status %d, error %v", status, resp["error"])
+	}
+
+	status, resp := apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/accounts/%d/delete-impact", accIDs[0]), nil)
+	if status != http.StatusOK {
+		t.Fatalf("delete impact: status %d, error %v", status, resp["error"])
+	}
+	impact := resp["data"].(map[string]interface{})
+	// The transfer posts a leg on each account, each naming the other.
+	want := map[string]float64{"transactions": 2, "transfer_legs": 1, "recurring_payments": 0, "defaults": 1, "total": 4}
+	for key, n := range want {
+		if impact[key] != n {
+			t.Errorf("%s = %v, want %v", key, impact[key], n)
+		}
+	}
+
+	if status, _ := apiRequest(t, r, "GET", "/api/v1/accounts/9999/delete-impact", nil); status != http.StatusNotFound {
+		t.Errorf("unknown account: expected 404, got %d", status)
+	}
+}
+
+// TestVerifyAccount verifies that a healthy account ties out, and that a
+// transfer leg whose counterpart was removed behind the API's back is flagged.
+func TestVerifyAccount(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+
+	current := createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": "Current", "type": "bank", "opening_balance": 1000.0})
+	savings := createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": "Savings", "type": "bank"})
+	createResource(t, r, "/api/v1/transactions", map[string]interface{}{"account_id": current, "type": "income", "amount": 250.0, "transaction_date": "2024-01-02"})
+	createResource(t, r, "/api/v1/transactions", map[string]interface{}{"account_id": current, "type": "expense", "amount": 100.0, "transaction_date": "2024-01-03"})
+	createResource(t, r, "/api/v1/transactions", map[string]interface{}{"account_id": current, "type": "adjustment", "amount": 5.0, "sign": -1, "transaction_date": "2024-01-04"})
+	createResource(t, r, "/api/v1/transactions", map[string]interface{}{"account_id": current, "type": "transfer", "amount": 300.0,
+		"transfer_account_id": savings, "transaction_date": "2024-01-05"})
+
+	verify := func(id int) map[string]interface{} {
+		t.Helper()
+		status, resp := apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/accounts/%d/verify", id), nil)
+		if status != http.StatusOK {
+			t.Fatalf("verify: status %d, error %v", status, resp["error"])
+		}
+		return resp["data"].(map[string]interface{})
+	}
+	v := verify(current)
+	// 1000 + 250 - 100 - 5 - 300, in paise.
+	if v["consistent"] != true || v["computed_balance"] != 84500.0 || v["displayed_balance"] != 84500.0 ||
+		v["transaction_count"] != 4.0 || v["transaction_sum"] != -15500.0 {
+		t.Errorf("unexpected verification: %v", v)
+	}
+
+	if _, err := DB.Exec("DELETE FROM transactions WHERE account_id = ?", savings); err != nil {
+		t.Fatalf("delete savings leg: %v", err)
+	}
+	v = verify(current)
+	issues := v["issues"].([]interface{})
+	if v["consistent"] != false || len(issues) != 1 {
+		t.Fatalf("expected the orphaned transfer leg to be flagged, got %v", v)
+	}
+
+	if status, _ := apiRequest(t, r, "GET", "/api/v1/accounts/9999/verify", nil); status != http.StatusNotFound {
+		t.Errorf("verify missing account: expected 404, got %d", status)
+	}
+}
+
+// TestGetAccountStats verifies that account stats total inflow and outflow
+// within the period, count transfers separately, and pick the largest income
+// and expense.
+func TestGetAccountStats(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+
+	current := createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": "Current", "type": "bank", "opening_balance": 1000.0})
+	savings := createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": "Savings", "type": "bank"})
+	txn := func(typ string, amount float64, date string) int {
+		return createResource(t, r, "/api/v1/transactions", map[string]interface{}{"account_id": current, "type": typ, "amount": amount, "transaction_date": date})
+	}
+	txn("income", 250.0, "2024-01-02")
+	bigIncome := txn("income", 400.0, "2024-01-10")
+	bigExpense := txn("expense", 120.0, "2024-01-03")
+	txn("expense", 30.0, "2024-01-04")
+	txn("income", 999.0, "2024-02-01")
+	createResource(t, r, "/api/v1/transactions", map[string]interface{}{"account_id": current, "type": "transfer", "amount": 500.0,
+		"transfer_account_id": savings, "transaction_date": "2024-01-05"})
+
+	status, resp := apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/accounts/%d/stats?from=2024-01-01&to=2024-01-31", current), nil)
+	if status != http.StatusOK {
+		t.Fatalf("stats: status %d, error %v", status, resp["error"])
+	}
+	stats := resp["data"].(map[string]interface{})
+	if stats["inflow"] != 65000.0 || stats["outflow"] != 15000.0 || stats["net"] != 50000.0 ||
+		stats["transfers_out"] != 50000.0 || stats["transfers_in"] != 0.0 || stats["transaction_count"] != 5.0 {
+		t.Errorf("unexpected stats: %v", stats)
+	}
+	if income := stats["largest_income"].(map[string]interface{}); int(income["id"].(float64)) != bigIncome {
+		t.Errorf("largest income: expected %d, got %v", bigIncome, income["id"])
+	}
+	if expense := stats["largest_expense"].(map[string]interface{}); int(expense["id"].(float64)) != bigExpense {
+		t.Errorf("largest expense: expected %d (not the transfer), got %v", bigExpense, expense["id"])
+	}
+
+	_, resp = apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/accounts/%d/stats", savings), nil)
+	stats = resp["data"].(map[string]interface{})
+	if stats["transfers_in"] != 50000.0 || stats["inflow"] != 0.0 || stats["largest_income"] != nil {
+		t.Errorf("unexpected savings stats: %v", stats)
+	}
+
+	if status, _ := apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/accounts/%d/stats?from=bad", current), nil); status != http.StatusBadRequest {
+		t.Errorf("bad from: expected 400, got %d", status)
+	}
+	if status, _ := apiRequest(t, r, "GET", "/api/v1/accounts/9999/stats", nil); status != http.StatusNotFound {
+		t.Errorf("missing account: expected 404, got %d", status)
+	}
+}
+
+// TestNegativeOpeningBalance verifies that a credit card opened with an
+// amount owed is a liability in the trial balance, and that a negative
+// opening on a bank account is accepted with a warning.
+func TestNegativeOpeningBalance(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+
+	status, resp := apiRequest(t, r, "POST", "/api/v1/accounts", map[string]interface{}{"name": "Card", "type": "credit_card", "opening_balance": -800.0})
+	if status != http.StatusCreated {
+		t.Fatalf("create card: status %d, error %v", status, resp["error"])
+	}
+	card := resp["data"].(map[string]interface{})
+	if card["warnings"] != nil {
+		t.Errorf("card: expected no warnings, got %v", card["warnings"])
+	}
+	createResource(t, r, "/api/v1/transactions", map[string]interface{}{"account_id": int(card["id"].(float64)), "type": "expense", "amount": 200.0, "transaction_date": "2024-01-05"})
+	createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": "Current", "type": "bank", "opening_balance": 1500.0})
+
+	status, resp = apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/accounts/%d", int(card["id"].(float64))), nil)
+	if status != http.StatusOK {
+		t.Fatalf("get card: status %d, error %v", status, resp["error"])
+	}
+	if got := resp["data"].(map[string]interface{}); got["balance"] != -100000.0 || got["display_balance"] != 100000.0 || got["balance_type"] != "liability" {
+		t.Errorf("card: balance %v display %v type %v, want -100000 100000 liability", got["balance"], got["display_balance"], got["balance_type"])
+	}
+
+	status, resp = apiRequest(t, r, "GET", "/api/v1/reports/trial-balance?as_of=2024-01-31", nil)
+	if status != http.StatusOK {
+		t.Fatalf("trial balance: status %d, error %v", status, resp["error"])
+	}
+	want := map[string][2]float64{
+		"Card":             {0, 100000},
+		"Current":          {150000, 0},
+		"Equity (derived)": {0, 50000},
+	}
+	for _, item := range resp["data"].(map[string]interface{})["rows"].([]interface{}) {
+		row := item.(map[string]interface{})
+		if w, ok := want[row["name"].(string)]; ok && (row["debit"] != w[0] || row["credit"] != w[1]) {
+			t.Errorf("row %s: debit %v credit %v, want %v", row["name"], row["debit"], row["credit"], w)
+		}
+	}
+
+	status, resp = apiRequest(t, r, "POST", "/api/v1/accounts", map[string]interface{}{"name": "Overdraft", "type": "bank", "opening_balance": -50.0})
+	if status != http.StatusCreated {
+		t.Fatalf("create overdrawn bank: status %d, error %v", status, resp["error"])
+	}
+	if warnings, _ := resp["data"].(map[string]interface{})["warnings"].([]interface{}); len(warnings) != 1 {
+		t.Errorf("overdrawn bank: expected one warning, got %v", warnings)
+	}
+}
+
+// TestAccountCurrencies verifies that USD and INR accounts keep their
+// balances in their own currency: a transaction must not claim another
+// currency, cannot be moved across currencies, and an account with
+// transactions keeps its currency.
+func TestAccountCurrencies(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+
+	inr := createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": "Current", "type": "bank", "opening_balance": 1000.0})
+	usd := createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": "US Dollar", "type": "bank", "currency": "usd", "opening_balance": 200.0})
+
+	usdTxn := createResource(t, r, "/api/v1/transactions", map[string]interface{}{
+		"account_id": usd, "type": "income", "amount": 50.0, "currency": "USD", "transaction_date": "2024-03-01",
+	})
+	createResource(t, r, "/api/v1/transactions", map[string]interface{}{
+		"account_id": inr, "type": "income", "amount": 300.0, "transaction_date": "2024-03-01",
+	})
+	if status, resp := apiRequest(t, r, "POST", "/api/v1/transactions", map[string]interface{}{
+		"account_id": usd, "type": "expense", "amount": 10.0, "currency": "INR", "transaction_date": "2024-03-02",
+	}); status != http.StatusBadRequest {
+		t.Errorf("INR amount on a USD account: expected 400, got %d (%v)", status, resp["error"])
+	}
+
+	balance := func(id int) interface{} {
+		_, resp := apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/accounts/%d", id), nil)
+		return resp["data"].(map[string]interface{})["balance"]
+	}
+	if got := balance(usd); got != 25000.0 {
+		t.Errorf("USD balance: expected 25000 cents, got %v", got)
+	}
+	if got := balance(inr); got != 130000.0 {
+		t.Errorf("INR balance: expected 130000 paise, got %v", got)
+	}
+
+	if status, _ := apiRequest(t, r, "PUT", fmt.Sprintf("/api/v1/transactions/%d", usdTxn), map[string]interface{}{
+		"account_id": inr, "type": "income", "amount": 50.0, "transaction_date": "2024-03-01",
+	}); status != http.StatusBadRequest {
+		t.Errorf("move a USD transaction to an INR account: expected 400, got %d", status)
+	}
+	if status, _ := apiRequest(t, r, "PUT", fmt.Sprintf("/api/v1/accounts/%d", usd), map[string]interface{}{
+		"name": "US Dollar", "type": "bank", "currency": "INR", "opening_balance": 200.0,
+	}); status != http.StatusConflict {
+		t.Errorf("change the currency of an account with transactions: expected 409, got %d", status)
+	}
+
+	for currency, want := range map[string]float64{"": 30000, "USD": 5000} {
+		_, resp := apiRequest(t, r, "GET", "/api/v1/transactions/calendar?month=2024-03&currency="+currency, nil)
+		if got := resp["data"].([]interface{})[0].(map[string]interface{})["income"]; got != want {
+			t.Errorf("calendar income in %q: expected %v, got %v", currency, want, got)
+		}
+	}
+}
+
+// TestAccountMinorUnits verifies that amounts on JPY and KWD accounts are read
+// in each currency's smallest unit: whole yen, and fils at three decimal
+// places.
+func TestAccountMinorUnits(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+
+	yen := createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": "Tokyo", "type": "bank", "currency": "JPY", "opening_balance": 5000.0})
+	dinar := createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": "Kuwait", "type": "bank", "currency": "KWD", "opening_balance": 1.234})
+	createResource(t, r, "/api/v1/transactions", map[string]interface{}{"account_id": yen, "type": "income", "amount": 1200.0, "transaction_date": "2024-03-01"})
+	createResource(t, r, "/api/v1/transactions", map[string]interface{}{"account_id": dinar, "type": "expense", "amount": "0.5", "transaction_date": "2024-03-01"})
+
+	for id, want := range map[int][3]float64{yen: {0, 5000, 6200}, dinar: {3, 1234, 734}} {
+		_, resp := apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/accounts/%d", id), nil)
+		a := resp["data"].(map[string]interface{})
+		if a["minor_units"] != want[0] || a["opening_balance"] != want[1] || a["balance"] != want[2] {
+			t.Errorf("account %v: minor_units %v opening %v balance %v, want %v", a["currency"], a["minor_units"], a["opening_balance"], a["balance"], want)
+		}
+	}
+
+	if status, resp := apiRequest(t, r, "PUT", fmt.Sprintf("/api/v1/accounts/%d", yen), map[string]interface{}{
+		"name": "Tokyo", "type": "bank", "opening_balance": 7000.0,
+	}); status != http.StatusOK || resp["data"].(map[string]interface{})["opening_balance"] != 7000.0 {
+		t.Errorf("update without currency: expected opening_balance 7000 yen, got %d %v", status, resp)
+	}
+}
+
+// TestLinksRequireBooksCurrency verifies that a USD transaction cannot be
+// allocated to INR documents by linking, auto-matching, offsetting, or payout
+// auto-matching, while an INR one can.
+func TestLinksRequireBooksCurrency(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+
+	inr := createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": "Current", "type": "bank"})
+	usd := createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": "US Dollar", "type": "bank", "currency": "USD"})
+	vendor := createResource(t, r, "/api/v1/contacts", map[string]interface{}{"name": "Fresh Farms", "type": "vendor"})
+	customer := createResource(t, r, "/api/v1/contacts", map[string]interface{}{"name": "fresh farms", "type": "customer"})
+	bill := createResource(t, r, "/api/v1/bills", map[string]interface{}{"contact_id": vendor, "bill_number": "B-1", "amount": 100.0, "status": "received"})
+	invoice := createResource(t, r, "/api/v1/invoices", map[string]interface{}{"contact_id": customer, "invoice_number": "I-1", "amount": 100.0, "status": "sent"})
+	usdTxn := createResource(t, r, "/api/v1/transactions", map[string]interface{}{
+		"account_id": usd, "type": "expense", "amount": 100.0, "description": "B-1", "transaction_date": "2024-03-01",
+	})
+
+	if status, resp := apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/transactions/%d/links", usdTxn), map[string]interface{}{
+		"document_type": "bill", "document_id": bill, "amount": 100.0,
+	}); status != http.StatusBadRequest {
+		t.Errorf("link a USD transaction to an INR bill: expected 400, got %d (%v)", status, resp)
+	}
+	if status, resp := apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/transactions/%d/auto-match", usdTxn), nil); status != http.StatusBadRequest {
+		t.Errorf("auto-match a USD transaction: expected 400, got %d (%v)", status, resp)
+	}
+	if status, resp := apiRequest(t, r, "POST", "/api/v1/offsets", map[string]interface{}{
+		"account_id": usd, "bill_id": bill, "invoice_id": invoice, "date": "2024-03-01",
+	}); status != http.StatusBadRequest {
+		t.Errorf("offset under a USD account: expected 400, got %d (%v)", status, resp)
+	}
+
+	payout := createResource(t, r, "/api/v1/payouts", map[string]interface{}{
+		"outlet_name": "Kitchen", "create_outlet": true, "platform": "swiggy", "final_payout_amt": 100.0, "settlement_date": "2024-03-01",
+	})
+	createResource(t, r, "/api/v1/transactions", map[string]interface{}{"account_id": usd, "type": "income", "amount": 100.0, "transaction_date": "2024-03-01"})
+	status, resp := apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/payouts/%d/auto-match", payout), nil)
+	if data := resp["data"].(map[string]interface{}); status != http.StatusOK || data["matched"] != false || len(data["candidates"].([]interface{})) != 0 {
+		t.Errorf("payout auto-match: expected no USD candidates, got %d %v", status, resp)
+	}
+
+	inrTxn := createResource(t, r, "/api/v1/transactions", map[string]interface{}{"account_id": inr, "type": "expense", "amount": 100.0, "transaction_date": "2024-03-01"})
+	if status, resp := apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/transactions/%d/links", inrTxn), map[string]interface{}{
+		"document_type": "bill", "document_id": bill, "amount": 100.0,
+	}); status != http.StatusCreated {
+		t.Errorf("link an INR transaction: expected 201, got %d (%v)", status, resp)
+	}
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+
+	"github.com/satheeshds/portal/store"
+)
+
+// requireAdmin writes a 403 and returns false unless the request carries
+// ADMIN_API_KEY in its X-Admin-Key header. Without a configured key every
+// caller passes.
+func requireAdmin(w http.ResponseWriter, r *http.Request) bool {
+	if key := cfg.AdminAPIKey; key != "" &&
+		subtle.ConstantTimeCompare([]byte(r.Header.Get("X-Admin-Key")), []byte(key)) != 1 {
+		writeError(w, http.StatusForbidden, "admin access required")
+		return false
+	}
+	return true
+}
+
+// defaultRecomputeBatchSize is the number of documents RecomputeStatuses
+// updates per transaction unless batch_size says otherwise.
+const defaultRecomputeBatchSize = 500
+
+// StatusRecomputeResult is an alias for store.StatusRecomputeResult kept here for Swagger doc references.
+type StatusRecomputeResult = store.StatusRecomputeResult
+
+// RecomputeStatuses re-derives every bill and invoice status from its allocations
+//	@Summary		Recompute document statuses
+//	@Description	Maintenance tool for when stored statuses have drifted, e.g. after bulk imports or manual database edits. Re-derives every bill and invoice status from its payment allocations, the same way linking a payment does, and returns how many changed. Cancelled documents, and unpaid ones marked sent or overdue, are left as they are. Payouts have no stored status and are only counted.
+//	@Description	When ADMIN_API_KEY is configured the request must carry it in the X-Admin-Key header.
+//	@Tags			admin
+//	@Produce		json
+//	@Param			X-Admin-Key	header		string	false	"Admin API key"
+//	@Param			batch_size	query		int		false	"Documents per transaction (default 500)"
+//	@Success		200			{object}	Response{data=StatusRecomputeResult}
+//	@Failure		400			{object}	Response{error=string}
+//	@Failure		403			{object}	Response{error=string}
+//	@Router			/admin/recompute-statuses [post]
+//	@Security		BearerAuth
+func RecomputeStatuses(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	s := store.New(getDB(r))
+	batchSize, ok := queryInt(w, r, "batch_size", defaultRecomputeBatchSize, 1)
+	if !ok {
+		return
+	}
+	result, err := s.RecomputeDocumentStatuses(batchSize)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	// Statuses change in bulk without per-document events.
+	dashboards.invalidate(getTenant(r))
+	writeJSON(w, http.StatusOK, result)
+}
+
+// PurgeOrphans removes rows whose parent record no longer exists and expired tombstones
+//	@Summary		Purge orphaned rows
+//	@Description	Maintenance tool that hard-deletes rows left pointing at deleted records: occurrences of deleted recurring payments, items of deleted bills and invoices,
+//	@Description	orders of deleted payouts, and payment links whose transaction or document is gone. Documents that lose links have their status and contact
+//	@Description	balance worked out again. It also drops the deletion tombstones GET /changes reports once they are older than DELETIONS_RETENTION_DAYS
+//	@Description	(default 90, 0 keeps them). Returns the number of rows removed per table.
+//	@Description	When ADMIN_API_KEY is configured the request must carry it in the X-Admin-Key header.
+//	@Tags			admin
+//	@Produce		json
+//	@Param			X-Admin-Key	header		string	false	"Admin API key"
+//	@Success		200			{object}	Response{data=map[string]int}
+//	@Failure		403			{object}	Response{error=string}
+//	@Router			/admin/purge [post]
+//	@Security		BearerAuth
+func PurgeOrphans(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	s := store.New(getDB(r))
+	purged, err := s.PurgeOrphans()
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	for table, n := range purged {
+		slog.InfoContext(r.Context(), "purged orphaned rows", "table", table, "rows", n)
+	}
+	dashboards.invalidate(getTenant(r))
+	writeJSON(w, http.StatusOK, purged)
+}
+
+// RecalcContacts rebuilds the cached contact balances
+//	@Summary		Recalculate contact balances
+//	@Description	Maintenance tool that recomputes every contact's cached balance and allocated amount from its bills or invoices and their payment links. GET /contacts reads these caches, which bill, invoice, and link writes keep current;
+//	@Description	rebuild them after bulk imports or manual database edits. Returns the number of contacts updated.
+//	@Description	When ADMIN_API_KEY is configured the request must carry it in the X-Admin-Key header.
+//	@Tags			admin
+//	@Produce		json
+//	@Param			X-Admin-Key	header		string	false	"Admin API key"
+//	@Success		200			{object}	Response{data=map[string]int}
+//	@Failure		403			{object}	Response{error=string}
+//	@Router			/admin/recalc-contacts [post]
+//	@Security		BearerAuth
+func RecalcContacts(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	s := store.New(getDB(r))
+	n, err := s.RecalcContactBalances()
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int{"contacts": n})
+}
@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestRecomputeStatuses verifies that drifted statuses are corrected across
+// batches while cancelled documents are left alone.
+func TestRecomputeStatuses(t *testing.T) {
+	r, cleanup := setupItemsTestRouter(t)
+	defer cleanup()
+	r.Post("/api/v1/admin/recompute-statuses", RecomputeStatuses)
+
+	// Marked paid without any payment: drifted.
+	var paid []int
+	for i := 0; i < 3; i++ {
+		_, resp := apiRequest(t, r, "POST", "/api/v1/bills", map[string]interface{}{
+			"bill_number": fmt.Sprintf("BILL-%d", i), "amount": 100.0, "status": "paid",
+		})
+		paid = append(paid, int(resp["data"].(map[string]interface{})["id"].(float64)))
+	}
+	_, resp := apiRequest(t, r, "POST", "/api/v1/bills", map[string]interface{}{
+		"bill_number": "VOID", "amount": 100.0, "status": "cancelled",
+	})
+	voided := int(resp["data"].(map[string]interface{})["id"].(float64))
+	apiRequest(t, r, "POST", "/api/v1/invoices", map[string]interface{}{
+		"invoice_number": "INV-1", "amount": 100.0, "status": "overdue",
+	})
+
+	status, resp := apiRequest(t, r, "POST", "/api/v1/admin/recompute-statuses?batch_size=2", nil)
+	if status != http.StatusOK {
+		t.Fatalf("recompute: status %d, error %v", status, resp["error"])
+	}
+	data := resp["data"].(map[string]interface{})
+	bills := data["bills"].(map[string]interface{})
+	if bills["checked"].(float64) != 4 || bills["changed"].(float64) != 3 {
+		t.Errorf("expected 4 bills checked and 3 changed, got %v", bills)
+	}
+	if invoices := data["invoices"].(map[string]interface{}); invoices["changed"].(float64) != 0 {
+		t.Errorf("expected overdue invoice left alone, got %v", invoices)
+	}
+
+	for _, id := range paid {
+		_, resp := apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/bills/%d", id), nil)
+		if got := resp["data"].(map[string]interface{})["status"]; got != "draft" {
+			t.Errorf("bill %d: expected draft, got %v", id, got)
+		}
+	}
+	_, resp = apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/bills/%d", voided), nil)
+	if got := resp["data"].(map[string]interface{})["status"]; got != "cancelled" {
+		t.Errorf("voided bill: expected cancelled, got %v", got)
+	}
+
+	if status, _ := apiRequest(t, r, "POST", "/api/v1/admin/recompute-statuses?batch_size=0", nil); status != http.StatusBadRequest {
+		t.Errorf("batch_size=0: expected 400, got %d", status)
+	}
+}
+
+// TestPurgeOrphans verifies that rows pointing at deleted records are removed
+// while links and items of live records are kept, and that documents that
+// lose links have their status worked out again.
+func TestPurgeOrphans(t *testing.T) {
+	r, cleanup := setupItemsTestRouter(t)
+	defer cleanup()
+	r.Post("/api/v1/admin/purge", PurgeOrphans)
+	r.Post("/api/v1/accounts", CreateAccount)
+	r.Post("/api/v1/transactions", CreateTransaction)
+	r.Post("/api/v1/transactions/{id}/links", CreateTransactionLink)
+
+	billID := createTestBill(t, r)
+	_, resp := apiRequest(t, r, "POST", "/api/v1/accounts", map[string]interface{}{"name": "Current", "type": "bank"})
+	accID := int(resp["data"].(map[string]interface{})["id"].(float64))
+	_, resp = apiRequest(t, r, "POST", "/api/v1/transactions", map[string]interface{}{
+		"account_id": accID, "type": "expense", "amount": 40.0, "transaction_date": "2024-01-15",
+	})
+	txnID := int(resp["data"].(map[string]interface{})["id"].(float64))
+	if status, resp := apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/transactions/%d/links", txnID), map[string]interface{}{
+		"document_type": "bill", "document_id": billID, "amount": 40.0,
+	}); status != http.StatusCreated {
+		t.Fatalf("create link: status %d, error %v", status, resp["error"])
+	}
+
+	for _, stmt := range []string{
+		fmt.Sprintf("INSERT INTO transaction_documents (transaction_id, document_type, document_id, amount) VALUES (999, 'bill', %d, 100)", billID),
+		fmt.Sprintf("INSERT INTO transaction_documents (transaction_id, document_type, document_id, amount) VALUES (%d, 'invoice', 999, 100)", txnID),
+		"INSERT INTO bill_items (bill_id, description, quantity, unit_price, amount) VALUES (999, 'Orphan', 1, 100, 100)",
+		"INSERT INTO recurring_payment_occurrences (recurring_payment_id, due_date, amount, status) VALUES (999, '2024-01-01', 100, 'pending')",
+		// The orphaned link made the bill look paid.
+		fmt.Sprintf("UPDATE bills SET status = 'paid' WHERE id = %d", billID),
+	} {
+		if _, err := DB.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	status, resp := apiRequest(t, r, "POST", "/api/v1/admin/purge", nil)
+	if status != http.StatusOK {
+		t.Fatalf("purge: status %d, error %v", status, resp["error"])
+	}
+	data := resp["data"].(map[string]interface{})
+	for table, want := range map[string]float64{"transaction_documents": 2, "bill_items": 1, "invoice_items": 0, "recurring_payment_occurrences": 1} {
+		if data[table] != want {
+			t.Errorf("%s: expected %v purged, got %v", table, want, data[table])
+		}
+	}
+
+	_, resp = apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/bills/%d", billID), nil)
+	bill := resp["data"].(map[string]interface{})
+	if bill["allocated"].(float64) != 4000 || len(bill["items"].([]interface{})) != 0 {
+		t.Errorf("expected the live link kept and no items, got %v", bill)
+	}
+	if bill["status"] != "partial" {
+		t.Errorf("expected the bill's status worked out from the live link, got %v", bill["status"])
+	}
+
+	_, resp = apiRequest(t, r, "POST", "/api/v1/admin/purge", nil)
+	if data := resp["data"].(map[string]interface{}); data["transaction_documents"].(float64) != 0 {
+		t.Errorf("second purge: expected nothing left, got %v", data)
+	}
+}
+
+// TestContactBalanceCache verifies that GET /contacts reads balances kept
+// current by bill and link writes, that live=true bypasses the cache, and
+// that POST /admin/recalc-contacts repairs a drifted cache.
+func TestContactBalanceCache(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+
+	balances := func(query string) map[string][2]float64 {
+		t.Helper()
+		status, resp := apiRequest(t, r, "GET", "/api/v1/contacts"+query, nil)
+		if status != http.StatusOK {
+			t.Fatalf("list contacts: status %d, error %v", status, resp["error"])
+		}
+		got := map[string][2]float64{}
+		for _, item := range resp["data"].([]interface{}) {
+			c := item.(map[string]interface{})
+			got[c["name"].(string)] = [2]float64{c["balance"].(float64), c["allocated_amount"].(float64)}
+		}
+		return got
+	}
+	check := func(query string, want map[string][2]float64) {
+		t.Helper()
+		got := balances(query)
+		for name, w := range want {
+			if got[name] != w {
+				t.Errorf("GET /contacts%s: %s balance/allocated = %v, want %v", query, name, got[name], w)
+			}
+		}
+	}
+
+	farms := createResource(t, r, "/api/v1/contacts", map[string]interface{}{"name": "Fresh Farms", "type": "vendor"})
+	metro := createResource(t, r, "/api/v1/contacts", map[string]interface{}{"name": "Metro", "type": "vendor"})
+	bank := createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": "Bank", "type": "bank"})
+	bill := createResource(t, r, "/api/v1/bills", map[string]interface{}{"contact_id": farms, "bill_number": "B-1", "amount": 100.0, "status": "received"})
+	payment := createResource(t, r, "/api/v1/transactions", map[string]interface{}{"account_id": bank, "type": "expense", "amount": 40.0, "transaction_date": "2024-01-10"})
+	createResource(t, r, fmt.Sprintf("/api/v1/transactions/%d/links", payment), map[string]interface{}{"document_type": "bill", "document_id": bill, "amount": 40.0})
+	check("", map[string][2]float64{"Fresh Farms": {6000, 4000}, "Metro": {0, 0}})
+
+	if status, resp := apiRequest(t, r, "PUT", fmt.Sprintf("/api/v1/bills/%d", bill), map[string]interface{}{
+		"contact_id": metro, "bill_number": "B-1", "amount": 150.0, "status": "received",
+	}); status != http.StatusOK {
+		t.Fatalf("update bill: status %d, error %v", status, resp["error"])
+	}
+	check("", map[string][2]float64{"Fresh Farms": {0, 0}, "Metro": {11000, 4000}})
+
+	if _, err := DB.Exec("UPDATE contacts SET cached_balance = 0, cached_allocated = 0"); err != nil {
+		t.Fatalf("clear cache: %v", err)
+	}
+	check("", map[string][2]float64{"Metro": {0, 0}})
+	check("?live=true", map[string][2]float64{"Metro": {11000, 4000}})
+
+	status, resp := apiRequest(t, r, "POST", "/api/v1/admin/recalc-contacts", nil)
+	if status != http.StatusOK {
+		t.Fatalf("recalc: status %d, error %v", status, resp["error"])
+	}
+	if n := resp["data"].(map[string]interface{})["contacts"]; n != 2.0 {
+		t.Errorf("expected 2 contacts recalculated, got %v", n)
+	}
+	check("", map[string][2]float64{"Fresh Farms": {0, 0}, "Metro": {11000, 4000}})
+}
+
+// TestAdminEndpointsRequireKey verifies that the maintenance endpoints refuse
+// callers without the configured admin key.
+func TestAdminEndpointsRequireKey(t *testing.T) {
+	r, cleanup := setupItemsTestRouter(t)
+	defer cleanup()
+	r.Post("/api/v1/admin/recompute-statuses", RecomputeStatuses)
+	r.Post("/api/v1/admin/purge", PurgeOrphans)
+	r.Post("/api/v1/admin/recalc-contacts", RecalcContacts)
+	withTestConfig(t, Config{AdminAPIKey: "secret"})
+
+	for _, path := range []string{"/api/v1/admin/recompute-statuses", "/api/v1/admin/purge", "/api/v1/admin/recalc-contacts"} {
+		if status, _ := apiRequest(t, r, "POST", path, nil); status != http.StatusForbidden {
+			t.Errorf("%s without key: expected 403, got %d", path, status)
+		}
+		req := httptest.NewRequest("POST", path, nil)
+		req.Header.Set("X-Admin-Key", "secret")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s with key: expected 200, got %d", path, rec.Code)
+		}
+	}
+}
+
+// TestPurgeExpiredDeletions verifies that purge drops tombstones older than
+// DELETIONS_RETENTION_DAYS and keeps recent ones.
+func TestPurgeExpiredDeletions(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+	withTestConfig(t, Config{DeletionsRetentionDays: 30})
+
+	for _, name := range []string{"Old", "Recent"} {
+		id := createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": name, "type": "bank"})
+		if status, resp := apiRequest(t, r, "DELETE", fmt.Sprintf("/api/v1/accounts/%d", id), nil); status != http.StatusOK {
+			t.Fatalf("delete %s: status %d, error %v", name, status, resp["error"])
+		}
+		if name == "Old" {
+			if _, err := DB.Exec("UPDATE deletions SET deleted_at = deleted_at - INTERVAL 31 DAY WHERE resource_id = ?", id); err != nil {
+				t.Fatalf("backdate tombstone: %v", err)
+			}
+		}
+	}
+
+	status, resp := apiRequest(t, r, "POST", "/api/v1/admin/purge", nil)
+	if status != http.StatusOK {
+		t.Fatalf("purge: status %d, error %v", status, resp["error"])
+	}
+	if n := resp["data"].(map[string]interface{})["deletions"]; n != 1.0 {
+		t.Errorf("expected 1 tombstone purged, got %v", n)
+	}
+	_, resp = apiRequest(t, r, "GET", "/api/v1/changes", nil)
+	if deleted := resp["data"].(map[string]interface{})["deleted"].([]interface{}); len(deleted) != 1 {
+		t.Errorf("expected the recent tombstone kept, got %v", deleted)
+	}
+}
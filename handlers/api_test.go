@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// setupAPI returns the full tenant API as main serves it under /api/v1, backed
+// by a fresh migrated database and the zero Config. Auth is left out, so
+// requests reach the handlers directly.
+func setupAPI(t *testing.T) (*chi.Mux, func()) {
+	t.Helper()
+	cleanup := openTestDB(t)
+	withTestConfig(t, Config{})
+
+	r := chi.NewRouter()
+	r.Route("/api/v1", func(r chi.Router) {
+		r.Use(DBRequired)
+		APIRoutes(r)
+	})
+	return r, cleanup
+}
+
+// createResource POSTs body to path, failing the test unless it returns 201,
+// and returns the new record's id.
+func createResource(t *testing.T, r http.Handler, path string, body map[string]interface{}) int {
+	t.Helper()
+	status, resp := apiRequest(t, r, "POST", path, body)
+	if status != http.StatusCreated {
+		t.Fatalf("POST %s: status %d, error %v", path, status, resp["error"])
+	}
+	return int(resp["data"].(map[string]interface{})["id"].(float64))
+}
+
+// TestAPICRUD runs create, get, list, update, and delete through the full
+// router for each core resource.
+func TestAPICRUD(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+
+	accID := createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": "Bank", "type": "bank"})
+	contactID := createResource(t, r, "/api/v1/contacts", map[string]interface{}{"name": "Acme", "type": "vendor"})
+
+	for _, tt := range []struct {
+		path   string
+		create map[string]interface{}
+		update map[string]interface{}
+		field  string // checked after the update
+		want   interface{}
+	}{
+		{
+			path:   "/api/v1/accounts",
+			create: map[string]interface{}{"name": "Cash", "type": "cash", "opening_balance": 10.0},
+			update: map[string]interface{}{"name": "Petty Cash", "type": "cash", "opening_balance": 10.0},
+			field:  "name", want: "Petty Cash",
+		},
+		{
+			path:   "/api/v1/contacts",
+			create: map[string]interface{}{"name": "Globex", "type": "customer"},
+			update: map[string]interface{}{"name": "Globex", "type": "customer", "email": "ap@globex.test"},
+			field:  "email", want: "ap@globex.test",
+		},
+		{
+			path:   "/api/v1/bills",
+			create: map[string]interface{}{"contact_id": contactID, "bill_number": "B-1", "amount": 100.0},
+			update: map[string]interface{}{"contact_id": contactID, "bill_number": "B-1", "amount": 150.0},
+			field:  "amount", want: float64(15000),
+		},
+		{
+			path:   "/api/v1/invoices",
+			create: map[string]interface{}{"invoice_number": "I-1", "amount": 100.0},
+			update: map[string]interface{}{"invoice_number": "I-1A", "amount": 100.0},
+			field:  "invoice_number", want: "I-1A",
+		},
+		{
+			path: "/api/v1/transactions",
+			create: map[string]interface{}{"account_id": accID, "type": "expense", "amount": 40.0,
+				"transaction_date": "2024-01-15"},
+			update: map[string]interface{}{"account_id": accID, "type": "expense", "amount": 40.0,
+				"transaction_date": "2024-01-15", "description": "Stationery"},
+			field: "description", want: "Stationery",
+		},
+		{
+			path:   "/api/v1/outlets",
+			create: map[string]interface{}{"name": "Indiranagar"},
+			update: map[string]interface{}{"name": "Koramangala"},
+			field:  "name", want: "Koramangala",
+		},
+		{
+			path:   "/api/v1/categories",
+			create: map[string]interface{}{"name": "Rent"},
+			update: map[string]interface{}{"name": "Rent & Lease"},
+			field:  "name", want: "Rent & Lease",
+		},
+	} {
+		id := createResource(t, r, tt.path, tt.create)
+		item := fmt.Sprintf("%s/%d", tt.path, id)
+
+		if status, resp := apiRequest(t, r, "GET", item, nil); status != http.StatusOK {
+			t.Errorf("GET %s: status %d, error %v", item, status, resp["error"])
+		}
+		status, resp := apiRequest(t, r, "GET", tt.path, nil)
+		if status != http.StatusOK {
+			t.Errorf("GET %s: status %d, error %v", tt.path, status, resp["error"])
+		} else if _, ok := resp["data"].([]interface{}); !ok {
+			t.Errorf("GET %s: expected a list, got %T", tt.path, resp["data"])
+		}
+
+		status, resp = apiRequest(t, r, "PUT", item, tt.update)
+		if status != http.StatusOK {
+			t.Errorf("PUT %s: status %d, error %v", item, status, resp["error"])
+		} else if got := resp["data"].(map[string]interface{})[tt.field]; got != tt.want {
+			t.Errorf("PUT %s: %s = %v, want %v", item, tt.field, got, tt.want)
+		}
+
+		if status, resp := apiRequest(t, r, "DELETE", item, nil); status != http.StatusOK {
+			t.Errorf("DELETE %s: status %d, error %v", item, status, resp["error"])
+		}
+		if status, _ := apiRequest(t, r, "GET", item, nil); status != http.StatusNotFound {
+			t.Errorf("GET %s after delete: expected 404, got %d", item, status)
+		}
+	}
+}
+
+// TestAPIAllocationFlow links a payment to an invoice in two parts through the
+// full router and checks the invoice status and allocation at each step,
+// including after a link is removed.
+func TestAPIAllocationFlow(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+
+	accID := createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": "Bank", "type": "bank"})
+	customerID := createResource(t, r, "/api/v1/contacts", map[string]interface{}{"name": "Globex", "type": "customer"})
+	invoiceID := createResource(t, r, "/api/v1/invoices", map[string]interface{}{
+		"contact_id": customerID, "invoice_number": "I-1", "amount": 100.0, "issue_date": "2024-01-01",
+	})
+	txnID := createResource(t, r, "/api/v1/transactions", map[string]interface{}{
+		"account_id": accID, "type": "income", "amount": 100.0, "transaction_date": "2024-01-20",
+	})
+
+	invoice := func() map[string]interface{} {
+		t.Helper()
+		status, resp := apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/invoices/%d", invoiceID), nil)
+		if status != http.StatusOK {
+			t.Fatalf("get invoice: status %d", status)
+		}
+		return resp["data"].(map[string]interface{})
+	}
+	link := func(amount float64) int {
+		t.Helper()
+		return createResource(t, r, fmt.Sprintf("/api/v1/transactions/%d/links", txnID), map[string]interface{}{
+			"document_type": "invoice", "document_id": invoiceID, "amount": amount,
+		})
+	}
+
+	first := link(60)
+	if inv := invoice(); inv["status"] != "partial" || inv["allocated"] != float64(6000) || inv["unallocated"] != float64(4000) {
+		t.Errorf("after partial link: status %v, allocated %v, unallocated %v", inv["status"], inv["allocated"], inv["unallocated"])
+	}
+
+	// Over-allocating the invoice is rejected.
+	status, _ := apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/transactions/%d/links", txnID), map[string]interface{}{
+		"document_type": "invoice", "document_id": invoiceID, "amount": 50.0,
+	})
+	if status != http.StatusBadRequest {
+		t.Errorf("over-allocation: expected 400, got %d", status)
+	}
+
+	link(40)
+	if inv := invoice(); inv["status"] != "received" || inv["unallocated"] != float64(0) {
+		t.Errorf("after full allocation: status %v, unallocated %v", inv["status"], inv["unallocated"])
+	}
+
+	status, resp := apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/invoices/%d/links", invoiceID), nil)
+	if status != http.StatusOK {
+		t.Fatalf("invoice links: status %d", status)
+	}
+	if n := len(resp["data"].([]interface{})); n != 2 {
+		t.Errorf("expected 2 invoice links, got %d", n)
+	}
+
+	status, resp = apiRequest(t, r, "DELETE", fmt.Sprintf("/api/v1/transactions/%d/links/%d", txnID, first), nil)
+	if status != http.StatusOK {
+		t.Fatalf("delete link: status %d, error %v", status, resp["error"])
+	}
+	if inv := invoice(); inv["status"] != "partial" || inv["allocated"] != float64(4000) {
+		t.Errorf("after removing a link: status %v, allocated %v", inv["status"], inv["allocated"])
+	}
+}
+
+// TestDisabledEndpoints verifies that route groups named in
+// DisabledEndpoints are not registered and return 404, while the rest of the
+// API is served.
+func TestDisabledEndpoints(t *testing.T) {
+	cleanup := openTestDB(t)
+	defer cleanup()
+	withTestConfig(t, Config{DisabledEndpoints: map[string]bool{"payouts": true, "reports": true}})
+
+	r := chi.NewRouter()
+	r.Route("/api/v1", func(r chi.Router) {
+		r.Use(DBRequired)
+		APIRoutes(r)
+	})
+	for _, path := range []string{"/api/v1/payouts", "/api/v1/payouts/1", "/api/v1/reports/trial-balance"} {
+		req := httptest.NewRequest("GET", path, nil)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("GET %s: expected 404, got %d", path, rec.Code)
+		}
+	}
+	if status, resp := apiRequest(t, r, "GET", "/api/v1/accounts", nil); status != http.StatusOK {
+		t.Errorf("GET /accounts: status %d, error %v", status, resp["error"])
+	}
+}
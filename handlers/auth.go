@@ -10,8 +10,10 @@ import (
 	"log/slog"
 	"net/http"
 	"time"
+	"unicode/utf8"
 
 	"github.com/satheeshds/portal/db"
+	"github.com/satheeshds/portal/store"
 )
 
 // nexusClient is a shared HTTP client. Timeouts are managed at the request level via context
@@ -38,7 +40,7 @@ var nexusRegisterClient = &http.Client{
 // portal schema and occurrence generation for that tenant.
 //
 //	@Summary		Register a new tenant
-//	@Description	Provisions a tenant via the Nexus gateway and initialises the portal schema. Requires NEXUS_CONTROL_URL and ADMIN_API_KEY to be configured.
+//	@Description	Provisions a tenant via the Nexus gateway and initialises the portal schema. Requires NEXUS_CONTROL_URL and ADMIN_API_KEY to be configured. The password must be at least MIN_PASSWORD_LENGTH (default 8) characters.
 //	@Tags			auth
 //	@Accept			json
 //	@Produce		json
@@ -66,6 +68,10 @@ func Register(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "org_name, email, and password are required")
 		return
 	}
+	if n := cfg.MinPasswordLength; n > 0 && utf8.RuneCountInString(req.Password) < n {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("password must be at least %d characters", n))
+		return
+	}
 
 	base := cfg.NexusControlURL
 	if base == "" {
@@ -146,23 +152,40 @@ func Register(w http.ResponseWriter, r *http.Request) {
 	if err := db.MigrateTenant(tenantDB, tenantID); err != nil {
 		slog.Error("schema migration failed after tenant creation", "tenant_id", tenantID, "error", err)
 		// Tenant was created; still return 201. The platform service will retry.
+	} else if cfg.DemoMode {
+		seedDemoData(tenantDB, tenantID)
 	}
 
 	slog.Info("tenant registered and portal schema initialised", "tenant_id", tenantID)
 	writeJSON(w, http.StatusCreated, map[string]string{"tenant_id": tenantID})
 }
 
-// Login proxies a login request to the Nexus gateway and returns the JWT token.
+// seedDemoData fills a new tenant's database with sample data when DEMO_MODE
+// is set. A failure is logged but does not fail the registration.
+func seedDemoData(tenantDB *db.PortalDB, tenantID string) {
+	seeded, err := store.New(tenantDB).SeedDemoData(time.Now())
+	switch {
+	case err != nil:
+		slog.Error("failed to seed demo data", "tenant_id", tenantID, "error", err)
+	case seeded:
+		slog.Info("DEMO_MODE: demo data created", "tenant_id", tenantID)
+	default:
+		slog.Info("DEMO_MODE: database not empty, demo data not created", "tenant_id", tenantID)
+	}
+}
+
+// Login proxies a login request to the Nexus gateway and starts a session for the JWT it returns.
 //
 //	@Summary		Login
-//	@Description	Authenticates with the Nexus gateway using email and password. Returns a JWT token.
+//	@Description	Authenticates with the Nexus gateway using email and password. Returns a short-lived access token (ACCESS_TOKEN_TTL, default 15m) and a refresh token (REFRESH_TOKEN_TTL, default 7 days) for POST /auth/refresh. After LOGIN_MAX_FAILURES (default 5) failed attempts for an email from one client IP within LOGIN_FAILURE_WINDOW (default 15m), attempts get 429 with Retry-After.
 //	@Tags			auth
 //	@Accept			json
 //	@Produce		json
 //	@Param			body	body		loginRequest	true	"Login credentials"
-//	@Success		200		{object}	map[string]string
+//	@Success		200		{object}	Response{data=sessionTokens}
 //	@Failure		400		{object}	Response
 //	@Failure		401		{object}	Response
+//	@Failure		429		{object}	Response{error=string}
 //	@Failure		502		{object}	Response
 //	@Router			/auth/login [post]
 func Login(w http.ResponseWriter, r *http.Request) {
@@ -178,6 +201,17 @@ func Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Failed attempts are counted per email and client IP; reaching
+	// LOGIN_MAX_FAILURES locks further attempts for a while.
+	var creds loginRequest
+	_ = json.Unmarshal(body, &creds)
+	keys := authKeys(r, creds.Email)
+	if wait := authFailures.retryAfter(time.Now(), keys...); wait > 0 {
+		slog.WarnContext(r.Context(), "login locked out", "email", creds.Email, "remote_addr", r.RemoteAddr)
+		writeLockedOut(w, wait)
+		return
+	}
+
 	target := fmt.Sprintf("%s/api/v1/login", base)
 	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
 	defer cancel()
@@ -196,17 +230,30 @@ func Login(w http.ResponseWriter, r *http.Request) {
 	}
 	defer resp.Body.Close()
 
-	// Nexus returns {"token": "..."} directly (not wrapped); re-wrap in our standard envelope.
+	// Nexus returns {"token": "..."} directly (not wrapped). The JWT is kept in a
+	// server-side session and the client gets session tokens in our envelope.
 	if resp.StatusCode == http.StatusOK {
 		var nexusResp map[string]string
 		if err := json.NewDecoder(resp.Body).Decode(&nexusResp); err != nil {
 			writeError(w, http.StatusBadGateway, "invalid response from nexus gateway")
 			return
 		}
-		writeJSON(w, http.StatusOK, nexusResp)
+		token := nexusResp["token"]
+		exp, ok := nexusTokenExpiry(token)
+		if !ok {
+			writeError(w, http.StatusBadGateway, "invalid token from nexus gateway")
+			return
+		}
+		if creds.Email != "" {
+			authFailures.reset(keys...)
+		}
+		writeJSON(w, http.StatusOK, sessions.create(token, exp))
 		return
 	}
 
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		authFailures.fail(time.Now(), keys...)
+	}
 	proxyResponse(w, resp)
 }
 
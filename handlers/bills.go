@@ -4,10 +4,9 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
-	"strconv"
 
-	"github.com/go-chi/chi/v5"
 	"github.com/satheeshds/portal/models"
 	"github.com/satheeshds/portal/store"
 )
@@ -15,27 +14,39 @@ import (
 // ListBills lists all bills
 //	@Summary		List bills
 //	@Description	Get a list of all payable bills, with current status and allocation info.
+//	@Description	Send Accept: text/csv for the same list as CSV.
 //	@Tags			bills
 //	@Produce		json
-//	@Param			contact_id	query		int		false	"Filter by contact (vendor)"
-//	@Param			search		query		string	false	"Search by bill number, notes, or vendor name"
-//	@Success		200			{object}	Response{data=[]models.Bill}
+//	@Produce		text/csv
+//	@Param			contact_id			query		int		false	"Filter by contact (vendor)"
+//	@Param			category_id			query		int		false	"Filter by category"
+//	@Param			from				query		string	false	"Filter by issue date from (YYYY-MM-DD)"
+//	@Param			to					query		string	false	"Filter by issue date to (YYYY-MM-DD)"
+//	@Param			search				query		string	false	"Search by bill number, notes, or vendor name"
+//	@Param			include_cancelled	query		bool	false	"Include cancelled bills (excluded by default)"
+//	@Param			amount_format		query		string	false	"CSV amounts as rupees (default), rupees_grouped, or paise"
+//	@Success		200					{object}	Response{data=[]models.Bill}
+//	@Failure		400					{object}	Response{error=string}
 //	@Router			/bills [get]
 //	@Security		BearerAuth
 func ListBills(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	bills, err := s.ListBills(
-		r.URL.Query().Get("status"),
-		r.URL.Query().Get("contact_id"),
-		r.URL.Query().Get("from"),
-		r.URL.Query().Get("to"),
-		r.URL.Query().Get("search"),
-	)
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+	q, ok := listQuery(w, r, []string{"contact_id", "category_id"}, []string{"from", "to"})
+	if !ok {
 		return
 	}
-	writeJSON(w, http.StatusOK, bills)
+	streamList(w, r, "bills.csv", billCSVHeader, billCSVRecord, func(yield func(models.Bill) error) error {
+		return s.EachBill(
+			q.Get("status"),
+			q.Get("contact_id"),
+			q.Get("category_id"),
+			q.Get("from"),
+			q.Get("to"),
+			q.Get("search"),
+			q.Get("include_cancelled") == "true",
+			yield,
+		)
+	})
 }
 
 // GetBill retrieves a single bill by ID
@@ -45,18 +56,22 @@ func ListBills(w http.ResponseWriter, r *http.Request) {
 //	@Produce		json
 //	@Param			id	path		int	true	"Bill ID"
 //	@Success		200	{object}	Response{data=models.Bill}
-//	@Failure		404	{object}	Response{error=string}
+//	@Failure		400	{object}	Response{error=string}
+//	@Failure		404	{object}	Response{error=string,code=string,resource=string}
 //	@Router			/bills/{id} [get]
 //	@Security		BearerAuth
 func GetBill(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
 	b, err := s.GetBill(id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			writeError(w, http.StatusNotFound, "bill not found")
+			writeNotFound(w, "bill")
 		} else {
-			writeError(w, http.StatusInternalServerError, err.Error())
+			writeInternalError(w, r, err)
 		}
 		return
 	}
@@ -85,11 +100,18 @@ func CreateBill(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, msg)
 		return
 	}
+	if !checkContactType(w, r, s, input.ContactID, "vendor", "bills") {
+		return
+	}
+	if !checkCategoryType(w, r, s, input.CategoryID, "expense", "bills") {
+		return
+	}
 	b, err := s.CreateBill(input)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeInternalError(w, r, err)
 		return
 	}
+	publishEvent(r, "created", "bill", b.ID)
 	writeJSON(w, http.StatusCreated, b)
 }
 
@@ -108,7 +130,10 @@ func CreateBill(w http.ResponseWriter, r *http.Request) {
 //	@Security		BearerAuth
 func UpdateBill(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
 	var input models.BillInput
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid JSON")
@@ -118,18 +143,73 @@ func UpdateBill(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, msg)
 		return
 	}
+	if !checkContactType(w, r, s, input.ContactID, "vendor", "bills") {
+		return
+	}
+	if !checkCategoryType(w, r, s, input.CategoryID, "expense", "bills") {
+		return
+	}
 	b, err := s.UpdateBill(id, input)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			writeError(w, http.StatusNotFound, "bill not found")
 		} else {
-			writeError(w, http.StatusInternalServerError, err.Error())
+			writeInternalError(w, r, err)
 		}
 		return
 	}
+	publishEvent(r, "updated", "bill", b.ID)
 	writeJSON(w, http.StatusOK, b)
 }
 
+// VoidBill cancels a bill without deleting it
+//	@Summary		Void bill
+//	@Description	Mark a bill as cancelled, keeping it for the record. A voided bill no longer counts towards payables and is listed only with include_cancelled=true. Bills with payment allocations cannot be voided until those links are removed.
+//	@Tags			bills
+//	@Produce		json
+//	@Param			id	path		int	true	"Bill ID"
+//	@Success		200	{object}	Response{data=models.Bill}
+//	@Failure		400	{object}	Response{error=string}
+//	@Failure		404	{object}	Response{error=string}
+//	@Failure		409	{object}	Response{error=string}
+//	@Router			/bills/{id}/void [post]
+//	@Security		BearerAuth
+func VoidBill(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+	if _, err := s.GetBill(id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "bill not found")
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+	totals, err := s.DocumentLinkTotals("bill", id)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	if totals.Count > 0 {
+		writeError(w, http.StatusConflict, fmt.Sprintf("bill has %d payment allocation(s) totalling %s; remove them before voiding", totals.Count, booksAmount(totals.Allocated)))
+		return
+	}
+	if err := s.VoidBill(id); err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	doc, err := s.GetBill(id)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	publishEvent(r, "updated", "bill", id)
+	writeJSON(w, http.StatusOK, doc)
+}
+
 // DeleteBill deletes a bill
 //	@Summary		Delete bill
 //	@Description	Remove a bill.
@@ -137,40 +217,66 @@ func UpdateBill(w http.ResponseWriter, r *http.Request) {
 //	@Produce		json
 //	@Param			id	path		int	true	"Bill ID"
 //	@Success		200	{object}	Response{data=map[string]string}
+//	@Failure		400	{object}	Response{error=string}
 //	@Failure		404	{object}	Response{error=string}
 //	@Router			/bills/{id} [delete]
 //	@Security		BearerAuth
 func DeleteBill(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
 	if err := s.DeleteBill(id); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			writeError(w, http.StatusNotFound, "bill not found")
 		} else {
-			writeError(w, http.StatusInternalServerError, err.Error())
+			writeInternalError(w, r, err)
 		}
 		return
 	}
+	publishEvent(r, "deleted", "bill", id)
 	writeJSON(w, http.StatusOK, map[string]string{"message": "deleted"})
 }
 
 // GetBillLinks retrieves all transactions associated with a bill
 //	@Summary		Get bill links
-//	@Description	Get all payment transactions linked to a specific bill.
+//	@Description	Get payment transactions linked to a specific bill, oldest first unless order=desc. Supports limit/offset pagination; totals across all links are returned in headers.
 //	@Tags			bills
 //	@Produce		json
-//	@Param			id	path		int	true	"Bill ID"
-//	@Success		200	{object}	Response{data=[]BillLink}
+//	@Param			id		path		int		true	"Bill ID"
+//	@Param			limit	query		int		false	"Maximum number of links to return (default DEFAULT_PAGE_SIZE, or all; at most MAX_PAGE_SIZE)"
+//	@Param			offset	query		int		false	"Number of links to skip"
+//	@Param			order	query		string	false	"Sort by created_at: asc (default) or desc"
+//	@Success		200		{object}	Response{data=[]BillLink}
+//	@Header			200		{int}		X-Total-Count		"Total number of links"
+//	@Header			200		{int}		X-Total-Allocated	"Total allocated across all links, in paise"
+//	@Header			200		{int}		X-Page-Size-Clamped	"Limit applied when the requested page exceeded MAX_PAGE_SIZE"
+//	@Failure		400		{object}	Response{error=string}
 //	@Router			/bills/{id}/links [get]
 //	@Security		BearerAuth
 func GetBillLinks(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
-	links, err := s.GetBillLinks(id)
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+	page, msg := parseLinkPage(w, r)
+	if msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	links, err := s.GetBillLinks(id, page)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeInternalError(w, r, err)
 		return
 	}
+	totals, err := s.DocumentLinkTotals("bill", id)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	setLinkTotalHeaders(w, totals)
 	writeJSON(w, http.StatusOK, links)
 }
 
@@ -184,16 +290,20 @@ type BillLink = store.BillLink
 //	@Produce		json
 //	@Param			id	path		int	true	"Bill ID"
 //	@Success		200	{object}	Response{data=[]models.BillItem}
+//	@Failure		400	{object}	Response{error=string}
 //	@Failure		404	{object}	Response{error=string}
 //	@Router			/bills/{id}/items [get]
 //	@Security		BearerAuth
 func ListBillItems(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
 
 	exists, err := s.BillExists(id)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeInternalError(w, r, err)
 		return
 	}
 	if !exists {
@@ -203,7 +313,7 @@ func ListBillItems(w http.ResponseWriter, r *http.Request) {
 
 	items, err := s.ListBillItems(id)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeInternalError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, items)
@@ -224,11 +334,14 @@ func ListBillItems(w http.ResponseWriter, r *http.Request) {
 //	@Security		BearerAuth
 func CreateBillItem(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	billID, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	billID, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
 
 	exists, err := s.BillExists(billID)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to verify bill existence: "+err.Error())
+		writeInternalError(w, r, err)
 		return
 	}
 	if !exists {
@@ -248,9 +361,10 @@ func CreateBillItem(w http.ResponseWriter, r *http.Request) {
 
 	item, err := s.CreateBillItem(billID, input)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeInternalError(w, r, err)
 		return
 	}
+	publishEvent(r, "updated", "bill", billID)
 	writeJSON(w, http.StatusCreated, item)
 }
 
@@ -270,8 +384,14 @@ func CreateBillItem(w http.ResponseWriter, r *http.Request) {
 //	@Security		BearerAuth
 func UpdateBillItem(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	billID, _ := strconv.Atoi(chi.URLParam(r, "id"))
-	itemID, _ := strconv.Atoi(chi.URLParam(r, "itemId"))
+	billID, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+	itemID, ok := pathInt(w, r, "itemId")
+	if !ok {
+		return
+	}
 
 	var input models.BillItemInput
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
@@ -288,10 +408,11 @@ func UpdateBillItem(w http.ResponseWriter, r *http.Request) {
 		if errors.Is(err, sql.ErrNoRows) {
 			writeError(w, http.StatusNotFound, "bill item not found")
 		} else {
-			writeError(w, http.StatusInternalServerError, err.Error())
+			writeInternalError(w, r, err)
 		}
 		return
 	}
+	publishEvent(r, "updated", "bill", billID)
 	writeJSON(w, http.StatusOK, item)
 }
 
@@ -303,21 +424,29 @@ func UpdateBillItem(w http.ResponseWriter, r *http.Request) {
 //	@Param			id		path		int	true	"Bill ID"
 //	@Param			itemId	path		int	true	"Item ID"
 //	@Success		200		{object}	Response{data=map[string]string}
+//	@Failure		400		{object}	Response{error=string}
 //	@Failure		404		{object}	Response{error=string}
 //	@Router			/bills/{id}/items/{itemId} [delete]
 //	@Security		BearerAuth
 func DeleteBillItem(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	billID, _ := strconv.Atoi(chi.URLParam(r, "id"))
-	itemID, _ := strconv.Atoi(chi.URLParam(r, "itemId"))
+	billID, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+	itemID, ok := pathInt(w, r, "itemId")
+	if !ok {
+		return
+	}
 
 	if err := s.DeleteBillItem(billID, itemID); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			writeError(w, http.StatusNotFound, "bill item not found")
 		} else {
-			writeError(w, http.StatusInternalServerError, err.Error())
+			writeInternalError(w, r, err)
 		}
 		return
 	}
+	publishEvent(r, "updated", "bill", billID)
 	writeJSON(w, http.StatusOK, map[string]string{"message": "deleted"})
 }
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestVoidDocuments(t *testing.T) {
+	r, cleanup := setupItemsTestRouter(t)
+	defer cleanup()
+	r.Get("/api/v1/bills", ListBills)
+	r.Post("/api/v1/bills/{id}/void", VoidBill)
+	r.Post("/api/v1/invoices/{id}/void", VoidInvoice)
+	r.Delete("/api/v1/transactions/{id}/links/{linkId}", DeleteTransactionLink)
+
+	billID := createTestBill(t, r)
+	invID := createTestInvoice(t, r)
+
+	_, resp := apiRequest(t, r, "POST", "/api/v1/accounts", map[string]interface{}{
+		"name": "Bank Account", "type": "bank", "opening_balance": 0,
+	})
+	accID := int(resp["data"].(map[string]interface{})["id"].(float64))
+	_, resp = apiRequest(t, r, "POST", "/api/v1/transactions", map[string]interface{}{
+		"account_id": accID, "type": "income", "amount": 50.0, "transaction_date": "2024-01-15",
+	})
+	txnID := int(resp["data"].(map[string]interface{})["id"].(float64))
+	_, resp = apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/transactions/%d/links", txnID), map[string]interface{}{
+		"document_type": "invoice", "document_id": invID, "amount": 50.0,
+	})
+	linkID := int(resp["data"].(map[string]interface{})["id"].(float64))
+
+	// An invoice with allocations cannot be voided.
+	if status, resp := apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/invoices/%d/void", invID), nil); status != http.StatusConflict {
+		t.Fatalf("void allocated invoice: expected 409, got %d (%v)", status, resp["error"])
+	}
+	if status, resp := apiRequest(t, r, "DELETE", fmt.Sprintf("/api/v1/transactions/%d/links/%d", txnID, linkID), nil); status != http.StatusOK {
+		t.Fatalf("delete link: status %d, error %v", status, resp["error"])
+	}
+	status, resp := apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/invoices/%d/void", invID), nil)
+	if status != http.StatusOK {
+		t.Fatalf("void invoice: status %d, error %v", status, resp["error"])
+	}
+	if got := resp["data"].(map[string]interface{})["status"]; got != "cancelled" {
+		t.Errorf("expected invoice status cancelled, got %v", got)
+	}
+
+	// A voided document takes no new allocations.
+	if status, resp := apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/transactions/%d/links", txnID), map[string]interface{}{
+		"document_type": "invoice", "document_id": invID, "amount": 10.0,
+	}); status != http.StatusConflict {
+		t.Errorf("link to voided invoice: expected 409, got %d (%v)", status, resp["error"])
+	}
+
+	if status, resp := apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/bills/%d/void", billID), nil); status != http.StatusOK {
+		t.Fatalf("void bill: status %d, error %v", status, resp["error"])
+	}
+	if status, _ := apiRequest(t, r, "POST", "/api/v1/bills/9999/void", nil); status != http.StatusNotFound {
+		t.Errorf("void missing bill: expected 404, got %d", status)
+	}
+
+	// The voided bill is kept but only listed with include_cancelled.
+	_, resp = apiRequest(t, r, "GET", "/api/v1/bills", nil)
+	if bills := resp["data"].([]interface{}); len(bills) != 0 {
+		t.Errorf("expected no bills by default, got %d", len(bills))
+	}
+	_, resp = apiRequest(t, r, "GET", "/api/v1/bills?include_cancelled=true", nil)
+	if bills := resp["data"].([]interface{}); len(bills) != 1 {
+		t.Errorf("expected 1 bill with include_cancelled, got %d", len(bills))
+	}
+}
+
+// TestListBillsHidesCancelled verifies that voided bills are left out of the
+// bill list by default and listed again with include_cancelled=true.
+func TestListBillsHidesCancelled(t *testing.T) {
+	r, cleanup := setupItemsTestRouter(t)
+	defer cleanup()
+	r.Get("/api/v1/bills", ListBills)
+	r.Post("/api/v1/bills/{id}/void", VoidBill)
+
+	keptID := createTestBill(t, r)
+	_, resp := apiRequest(t, r, "POST", "/api/v1/bills", map[string]interface{}{
+		"bill_number": "BILL-002", "amount": 50.0, "status": "draft",
+	})
+	voidedID := int(resp["data"].(map[string]interface{})["id"].(float64))
+	if status, resp := apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/bills/%d/void", voidedID), nil); status != http.StatusOK {
+		t.Fatalf("void bill: status %d, error %v", status, resp["error"])
+	}
+
+	listed := func(path string) map[int]string {
+		_, resp := apiRequest(t, r, "GET", path, nil)
+		statuses := map[int]string{}
+		for _, b := range resp["data"].([]interface{}) {
+			bill := b.(map[string]interface{})
+			statuses[int(bill["id"].(float64))] = bill["status"].(string)
+		}
+		return statuses
+	}
+	if got := listed("/api/v1/bills"); len(got) != 1 || got[keptID] != "draft" {
+		t.Errorf("default list: expected only bill %d, got %v", keptID, got)
+	}
+	if got := listed("/api/v1/bills?include_cancelled=false"); len(got) != 1 {
+		t.Errorf("include_cancelled=false: expected 1 bill, got %v", got)
+	}
+	if got := listed("/api/v1/bills?include_cancelled=true"); len(got) != 2 || got[voidedID] != "cancelled" {
+		t.Errorf("include_cancelled=true: expected both bills with %d cancelled, got %v", voidedID, got)
+	}
+}
+
+// TestDaysToDue verifies that bills and invoices report the days left until
+// their due date, negative once overdue and null without one.
+func TestDaysToDue(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+
+	day := func(offset int) string { return time.Now().AddDate(0, 0, offset).Format("2006-01-02") }
+	overdue := createResource(t, r, "/api/v1/bills", map[string]interface{}{
+		"bill_number": "B-1", "amount": 100.0, "status": "received", "due_date": day(-3),
+	})
+	undated := createResource(t, r, "/api/v1/bills", map[string]interface{}{
+		"bill_number": "B-2", "amount": 100.0, "status": "received",
+	})
+	invoice := createResource(t, r, "/api/v1/invoices", map[string]interface{}{
+		"invoice_number": "INV-1", "amount": 100.0, "status": "sent", "due_date": day(5),
+	})
+
+	tests := []struct {
+		path string
+		want interface{}
+	}{
+		{fmt.Sprintf("/api/v1/bills/%d", overdue), -3.0},
+		{fmt.Sprintf("/api/v1/bills/%d", undated), nil},
+		{fmt.Sprintf("/api/v1/invoices/%d", invoice), 5.0},
+	}
+	for _, tt := range tests {
+		_, resp := apiRequest(t, r, "GET", tt.path, nil)
+		data := resp["data"].(map[string]interface{})
+		if got, ok := data["days_to_due"]; !ok || got != tt.want {
+			t.Errorf("GET %s: days_to_due = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+// TestDocumentContactType verifies that bills must reference a vendor and
+// invoices a customer, on create and update, and that an unknown contact is
+// rejected.
+func TestDocumentContactType(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+
+	vendor := createResource(t, r, "/api/v1/contacts", map[string]interface{}{"name": "Fresh Farms", "type": "vendor"})
+	customer := createResource(t, r, "/api/v1/contacts", map[string]interface{}{"name": "Acme", "type": "customer"})
+	bill := createResource(t, r, "/api/v1/bills", map[string]interface{}{"contact_id": vendor, "bill_number": "B-1", "amount": 10.0, "status": "draft"})
+	invoice := createResource(t, r, "/api/v1/invoices", map[string]interface{}{"contact_id": customer, "invoice_number": "INV-1", "amount": 10.0, "status": "draft"})
+	createResource(t, r, "/api/v1/bills", map[string]interface{}{"bill_number": "B-2", "amount": 10.0, "status": "draft"})
+
+	tests := []struct {
+		method, path string
+		body         map[string]interface{}
+	}{
+		{"POST", "/api/v1/bills", map[string]interface{}{"contact_id": customer, "bill_number": "B-3", "amount": 10.0, "status": "draft"}},
+		{"PUT", fmt.Sprintf("/api/v1/bills/%d", bill), map[string]interface{}{"contact_id": customer, "bill_number": "B-1", "amount": 10.0, "status": "draft"}},
+		{"POST", "/api/v1/invoices", map[string]interface{}{"contact_id": vendor, "invoice_number": "INV-2", "amount": 10.0, "status": "draft"}},
+		{"PUT", fmt.Sprintf("/api/v1/invoices/%d", invoice), map[string]interface{}{"contact_id": vendor, "invoice_number": "INV-1", "amount": 10.0, "status": "draft"}},
+		{"POST", "/api/v1/bills", map[string]interface{}{"contact_id": 9999, "bill_number": "B-4", "amount": 10.0, "status": "draft"}},
+	}
+	for _, tt := range tests {
+		if status, resp := apiRequest(t, r, tt.method, tt.path, tt.body); status != http.StatusBadRequest {
+			t.Errorf("%s %s with contact %v: expected 400, got %d (%v)", tt.method, tt.path, tt.body["contact_id"], status, resp["error"])
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/satheeshds/portal/models"
+	"github.com/satheeshds/portal/store"
+)
+
+// ListCategories lists all categories
+//	@Summary		List categories
+//	@Description	Get a list of all income and expense categories with the number of transactions and bills tagged with each.
+//	@Tags			categories
+//	@Produce		json
+//	@Param			type	query		string	false	"Filter by type (expense, income)"
+//	@Param			search	query		string	false	"Search by name"
+//	@Success		200		{object}	Response{data=[]models.Category}
+//	@Router			/categories [get]
+//	@Security		BearerAuth
+func ListCategories(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	q := r.URL.Query()
+	categories, err := s.ListCategories(q.Get("type"), q.Get("search"))
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, categories)
+}
+
+// GetCategory retrieves a single category by ID
+//	@Summary		Get category
+//	@Description	Get details of a specific category.
+//	@Tags			categories
+//	@Produce		json
+//	@Param			id	path		int	true	"Category ID"
+//	@Success		200	{object}	Response{data=models.Category}
+//	@Failure		400	{object}	Response{error=string}
+//	@Failure		404	{object}	Response{error=string}
+//	@Router			/categories/{id} [get]
+//	@Security		BearerAuth
+func GetCategory(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+	c, err := s.GetCategory(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "category not found")
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, c)
+}
+
+// CreateCategory creates a new category
+//	@Summary		Create category
+//	@Description	Add an income or expense category (type defaults to expense). Names are unique, ignoring case.
+//	@Tags			categories
+//	@Accept			json
+//	@Produce		json
+//	@Param			category	body		models.CategoryInput	true	"Category contents"
+//	@Success		201			{object}	Response{data=models.Category}
+//	@Failure		400			{object}	Response{error=string}
+//	@Failure		409			{object}	Response{error=string}
+//	@Router			/categories [post]
+//	@Security		BearerAuth
+func CreateCategory(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	var input models.CategoryInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if msg := input.Validate(); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	if !checkCategoryNameFree(w, r, s, input.Name, 0) {
+		return
+	}
+	c, err := s.CreateCategory(input)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, c)
+}
+
+// UpdateCategory updates an existing category
+//	@Summary		Update category
+//	@Description	Rename a category or change its type. A category still tagged on transactions or bills keeps its type.
+//	@Tags			categories
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		int						true	"Category ID"
+//	@Param			category	body		models.CategoryInput	true	"Updated category contents"
+//	@Success		200			{object}	Response{data=models.Category}
+//	@Failure		400			{object}	Response{error=string}
+//	@Failure		404			{object}	Response{error=string}
+//	@Failure		409			{object}	Response{error=string}
+//	@Router			/categories/{id} [put]
+//	@Security		BearerAuth
+func UpdateCategory(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+	var input models.CategoryInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if msg := input.Validate(); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	existing, err := s.GetCategory(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "category not found")
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+	if input.Type != existing.Type && existing.Transactions+existing.Bills > 0 {
+		writeError(w, http.StatusConflict, fmt.Sprintf("category is used by %d transaction(s) and %d bill(s); its type cannot change",
+			existing.Transactions, existing.Bills))
+		return
+	}
+	if !checkCategoryNameFree(w, r, s, input.Name, id) {
+		return
+	}
+	c, err := s.UpdateCategory(id, input)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "category not found")
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, c)
+}
+
+// DeleteCategory deletes a category
+//	@Summary		Delete category
+//	@Description	Remove a category. Categories still tagged on transactions or bills cannot be deleted.
+//	@Tags			categories
+//	@Produce		json
+//	@Param			id	path		int	true	"Category ID"
+//	@Success		200	{object}	Response{data=map[string]string}
+//	@Failure		400	{object}	Response{error=string}
+//	@Failure		404	{object}	Response{error=string}
+//	@Failure		409	{object}	Response{error=string}
+//	@Router			/categories/{id} [delete]
+//	@Security		BearerAuth
+func DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+	c, err := s.GetCategory(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "category not found")
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+	if c.Transactions+c.Bills > 0 {
+		writeError(w, http.StatusConflict, fmt.Sprintf("category is used by %d transaction(s) and %d bill(s); recategorize them first",
+			c.Transactions, c.Bills))
+		return
+	}
+	if err := s.DeleteCategory(id); err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": "deleted"})
+}
+
+// checkCategoryNameFree writes a 409 and returns false when another category
+// than id already uses name.
+func checkCategoryNameFree(w http.ResponseWriter, r *http.Request, s *store.Store, name string, id int) bool {
+	existing, err := s.FindCategoryByName(name)
+	if err == nil && existing.ID != id {
+		writeError(w, http.StatusConflict, fmt.Sprintf("category %q already exists (id %d)", existing.Name, existing.ID))
+		return false
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		writeInternalError(w, r, err)
+		return false
+	}
+	return true
+}
+
+// checkCategoryType writes a 400 and returns false when categoryID is set and
+// is not an existing category of type want; document names what is tagged
+// in the message, e.g. "bills".
+func checkCategoryType(w http.ResponseWriter, r *http.Request, s *store.Store, categoryID *int, want, document string) bool {
+	if categoryID == nil {
+		return true
+	}
+	c, err := s.GetCategory(*categoryID)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("category %d does not exist", *categoryID))
+		return false
+	}
+	if err != nil {
+		writeInternalError(w, r, err)
+		return false
+	}
+	if c.Type != want {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("category %d is an %s category; %s must use an %s category", c.ID, c.Type, document, want))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+// TestCategories verifies that transactions and bills are tagged with a
+// category of the right type, that transactions and bills filter by it, and
+// that a category in use cannot be deleted or retyped.
+func TestCategories(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+
+	accID := createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": "Bank", "type": "bank"})
+	vendorID := createResource(t, r, "/api/v1/contacts", map[string]interface{}{"name": "Landlord", "type": "vendor"})
+
+	status, resp := apiRequest(t, r, "POST", "/api/v1/categories", map[string]interface{}{"name": " Rent "})
+	if status != http.StatusCreated {
+		t.Fatalf("create category: status %d, error %v", status, resp["error"])
+	}
+	rent := resp["data"].(map[string]interface{})
+	rentID := int(rent["id"].(float64))
+	if rent["name"] != "Rent" || rent["type"] != "expense" {
+		t.Errorf("expected a trimmed expense category, got %v", rent)
+	}
+	if status, _ := apiRequest(t, r, "POST", "/api/v1/categories", map[string]interface{}{"name": "rent"}); status != http.StatusConflict {
+		t.Errorf("duplicate category: expected 409, got %d", status)
+	}
+	if status, _ := apiRequest(t, r, "POST", "/api/v1/categories", map[string]interface{}{"name": "Tips", "type": "asset"}); status != http.StatusBadRequest {
+		t.Errorf("bad category type: expected 400, got %d", status)
+	}
+	salesID := createResource(t, r, "/api/v1/categories", map[string]interface{}{"name": "Dine-in sales", "type": "income"})
+
+	for _, tt := range []struct {
+		name string
+		body map[string]interface{}
+		want int
+	}{
+		{"unknown category", map[string]interface{}{"account_id": accID, "type": "expense", "amount": 10.0, "category_id": 9999}, http.StatusBadRequest},
+		{"income category on an expense", map[string]interface{}{"account_id": accID, "type": "expense", "amount": 10.0, "category_id": salesID}, http.StatusBadRequest},
+		{"category on an adjustment", map[string]interface{}{"account_id": accID, "type": "adjustment", "sign": 1, "amount": 10.0, "category_id": rentID}, http.StatusBadRequest},
+		{"expense", map[string]interface{}{"account_id": accID, "type": "expense", "amount": 500.0, "category_id": rentID}, http.StatusCreated},
+		{"income", map[string]interface{}{"account_id": accID, "type": "income", "amount": 800.0, "category_id": salesID}, http.StatusCreated},
+		{"uncategorized", map[string]interface{}{"account_id": accID, "type": "expense", "amount": 20.0}, http.StatusCreated},
+	} {
+		if status, resp := apiRequest(t, r, "POST", "/api/v1/transactions", tt.body); status != tt.want {
+			t.Errorf("%s: expected %d, got %d (%v)", tt.name, tt.want, status, resp["error"])
+		}
+	}
+
+	_, resp = apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/transactions?category_id=%d", rentID), nil)
+	txns := resp["data"].([]interface{})
+	if len(txns) != 1 {
+		t.Fatalf("expected 1 rent transaction, got %v", txns)
+	}
+	if txn := txns[0].(map[string]interface{}); txn["category_name"] != "Rent" || txn["amount"].(float64) != 50000 {
+		t.Errorf("unexpected rent transaction: %v", txn)
+	}
+	if status, _ := apiRequest(t, r, "GET", "/api/v1/transactions?category_id=rent", nil); status != http.StatusBadRequest {
+		t.Errorf("non-integer category_id: expected 400, got %d", status)
+	}
+
+	if status, _ := apiRequest(t, r, "POST", "/api/v1/bills", map[string]interface{}{
+		"contact_id": vendorID, "amount": 500.0, "category_id": salesID,
+	}); status != http.StatusBadRequest {
+		t.Errorf("income category on a bill: expected 400, got %d", status)
+	}
+	createResource(t, r, "/api/v1/bills", map[string]interface{}{"contact_id": vendorID, "amount": 500.0, "category_id": rentID})
+	_, resp = apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/bills?category_id=%d", rentID), nil)
+	if bills := resp["data"].([]interface{}); len(bills) != 1 || bills[0].(map[string]interface{})["category_name"] != "Rent" {
+		t.Errorf("expected 1 rent bill, got %v", bills)
+	}
+
+	_, resp = apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/categories/%d", rentID), nil)
+	if c := resp["data"].(map[string]interface{}); c["transactions"].(float64) != 1 || c["bills"].(float64) != 1 {
+		t.Errorf("expected rent used by 1 transaction and 1 bill, got %v", c)
+	}
+	item := fmt.Sprintf("/api/v1/categories/%d", rentID)
+	if status, _ := apiRequest(t, r, "PUT", item, map[string]interface{}{"name": "Rent", "type": "income"}); status != http.StatusConflict {
+		t.Errorf("retype category in use: expected 409, got %d", status)
+	}
+	if status, _ := apiRequest(t, r, "DELETE", item, nil); status != http.StatusConflict {
+		t.Errorf("delete category in use: expected 409, got %d", status)
+	}
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/satheeshds/portal/models"
+	"github.com/satheeshds/portal/store"
+)
+
+// Changes is an alias for store.Changes kept here for Swagger doc references.
+type Changes = store.Changes
+
+// ListChanges lists everything changed since a cursor, for incremental sync
+//	@Summary		List changes since
+//	@Description	Accounts, contacts, outlets, categories, bills, invoices, transactions, payouts, and recurring payments created or updated after since, grouped by type, plus tombstones of records deleted after it.
+//	@Description	Linking a payment to a document, or removing the link, counts as a change to the transaction, the document, and its contact's balance.
+//	@Description	Without since, every record is returned. Pass the returned server_time as since on the next call. Records are stamped when their write began, so
+//	@Description	reading starts CHANGES_OVERLAP (default 1m) before since, to catch writes that were still committing when server_time was read; records changed in
+//	@Description	that window are returned again, so clients should apply changes idempotently. A write that takes longer than the window to commit can be missed.
+//	@Description	Tombstones are kept for DELETIONS_RETENTION_DAYS (default 90) and then dropped by POST /admin/purge; a client whose since is older than that must do a full sync.
+//	@Tags			changes
+//	@Produce		json
+//	@Param			since	query		string	false	"RFC 3339 timestamp, e.g. a previous server_time"
+//	@Success		200		{object}	Response{data=Changes}
+//	@Failure		400		{object}	Response{error=string}
+//	@Router			/changes [get]
+//	@Security		BearerAuth
+func ListChanges(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	var since *models.Timestamp
+	if v := r.URL.Query().Get("since"); v != "" {
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "since must be an RFC 3339 timestamp")
+			return
+		}
+		since = &models.Timestamp{Time: t}
+	}
+	changes, err := s.ListChanges(since)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, changes)
+}
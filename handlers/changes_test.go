@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// TestListChanges verifies that a full sync returns every record, and that
+// syncing from the returned server_time returns only records changed since,
+// including a payout update and a tombstone for a deleted account, and that
+// CHANGES_OVERLAP reaches back before since.
+func TestListChanges(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+
+	account := createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": "Current", "type": "bank"})
+	contact := createResource(t, r, "/api/v1/contacts", map[string]interface{}{"name": "Acme", "type": "customer"})
+	payout := createResource(t, r, "/api/v1/payouts", map[string]interface{}{"outlet_name": "Kitchen", "create_outlet": true, "platform": "swiggy", "final_payout_amt": 100.0})
+	createResource(t, r, "/api/v1/contacts", map[string]interface{}{"name": "Untouched", "type": "vendor"})
+
+	status, resp := apiRequest(t, r, "GET", "/api/v1/changes", nil)
+	if status != http.StatusOK {
+		t.Fatalf("full sync: status %d, error %v", status, resp["error"])
+	}
+	full := resp["data"].(map[string]interface{})
+	if full["since"] != nil || len(full["accounts"].([]interface{})) != 1 || len(full["contacts"].([]interface{})) != 2 ||
+		len(full["payouts"].([]interface{})) != 1 || len(full["deleted"].([]interface{})) != 0 {
+		t.Errorf("unexpected full sync: %v", full)
+	}
+	cursor := full["server_time"].(string)
+
+	time.Sleep(10 * time.Millisecond)
+	if status, resp := apiRequest(t, r, "PUT", fmt.Sprintf("/api/v1/contacts/%d", contact), map[string]interface{}{"name": "Acme Ltd", "type": "customer"}); status != http.StatusOK {
+		t.Fatalf("update contact: status %d, error %v", status, resp["error"])
+	}
+	if status, resp := apiRequest(t, r, "PUT", fmt.Sprintf("/api/v1/payouts/%d", payout), map[string]interface{}{"outlet_name": "Kitchen", "platform": "swiggy", "final_payout_amt": 120.0}); status != http.StatusOK {
+		t.Fatalf("update payout: status %d, error %v", status, resp["error"])
+	}
+	if status, resp := apiRequest(t, r, "DELETE", fmt.Sprintf("/api/v1/accounts/%d", account), nil); status != http.StatusOK {
+		t.Fatalf("delete account: status %d, error %v", status, resp["error"])
+	}
+
+	status, resp = apiRequest(t, r, "GET", "/api/v1/changes?since="+url.QueryEscape(cursor), nil)
+	if status != http.StatusOK {
+		t.Fatalf("incremental sync: status %d, error %v", status, resp["error"])
+	}
+	changes := resp["data"].(map[string]interface{})
+	if len(changes["accounts"].([]interface{})) != 0 || len(changes["bills"].([]interface{})) != 0 {
+		t.Errorf("expected no changed accounts or bills, got %v", changes)
+	}
+	if contacts := changes["contacts"].([]interface{}); len(contacts) != 1 || contacts[0].(map[string]interface{})["name"] != "Acme Ltd" {
+		t.Errorf("expected the renamed contact, got %v", contacts)
+	}
+	if payouts := changes["payouts"].([]interface{}); len(payouts) != 1 || payouts[0].(map[string]interface{})["final_payout_amt"] != 12000.0 {
+		t.Errorf("expected the updated payout, got %v", payouts)
+	}
+	deleted := changes["deleted"].([]interface{})
+	if len(deleted) != 1 {
+		t.Fatalf("expected one tombstone, got %v", deleted)
+	}
+	if d := deleted[0].(map[string]interface{}); d["resource"] != "account" || int(d["id"].(float64)) != account {
+		t.Errorf("unexpected tombstone: %v", d)
+	}
+
+	// With an overlap, records changed shortly before since are reported again.
+	withTestConfig(t, Config{ChangesOverlap: time.Hour})
+	_, resp = apiRequest(t, r, "GET", "/api/v1/changes?since="+url.QueryEscape(cursor), nil)
+	if contacts := resp["data"].(map[string]interface{})["contacts"].([]interface{}); len(contacts) != 2 {
+		t.Errorf("with overlap: expected both contacts, got %v", contacts)
+	}
+
+	if status, _ := apiRequest(t, r, "GET", "/api/v1/changes?since=yesterday", nil); status != http.StatusBadRequest {
+		t.Errorf("invalid since: expected 400, got %d", status)
+	}
+}
+
+// TestListChangesReportsLinks verifies that linking a payment to a bill and
+// removing the link report the transaction, the bill, and the vendor, whose
+// balance they change, as changed.
+func TestListChangesReportsLinks(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+
+	account := createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": "Current", "type": "bank"})
+	vendor := createResource(t, r, "/api/v1/contacts", map[string]interface{}{"name": "Supplier", "type": "vendor"})
+	bill := createResource(t, r, "/api/v1/bills", map[string]interface{}{"contact_id": vendor, "bill_number": "B-1", "amount": 100.0, "status": "received"})
+	txn := createResource(t, r, "/api/v1/transactions", map[string]interface{}{"account_id": account, "type": "expense", "amount": 100.0, "contact_id": vendor})
+
+	sync := func() string {
+		_, resp := apiRequest(t, r, "GET", "/api/v1/changes", nil)
+		time.Sleep(10 * time.Millisecond)
+		return resp["data"].(map[string]interface{})["server_time"].(string)
+	}
+	changed := func(cursor, step string) {
+		t.Helper()
+		status, resp := apiRequest(t, r, "GET", "/api/v1/changes?since="+url.QueryEscape(cursor), nil)
+		if status != http.StatusOK {
+			t.Fatalf("%s: status %d, error %v", step, status, resp["error"])
+		}
+		c := resp["data"].(map[string]interface{})
+		for key, id := range map[string]int{"transactions": txn, "bills": bill, "contacts": vendor} {
+			list := c[key].([]interface{})
+			if len(list) != 1 || int(list[0].(map[string]interface{})["id"].(float64)) != id {
+				t.Errorf("%s: expected %s %d to be reported, got %v", step, key, id, list)
+			}
+		}
+	}
+
+	cursor := sync()
+	link := createResource(t, r, fmt.Sprintf("/api/v1/transactions/%d/links", txn), map[string]interface{}{
+		"document_type": "bill", "document_id": bill, "amount": 100.0,
+	})
+	changed(cursor, "link")
+
+	cursor = sync()
+	if status, resp := apiRequest(t, r, "DELETE", fmt.Sprintf("/api/v1/transactions/%d/links/%d", txn, link), nil); status != http.StatusOK {
+		t.Fatalf("unlink: status %d, error %v", status, resp["error"])
+	}
+	changed(cursor, "unlink")
+}
@@ -1,8 +1,16 @@
 package handlers
 
 import (
+	"log/slog"
+	"net/http"
 	"os"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/satheeshds/portal/models"
+	"github.com/satheeshds/portal/ocr"
+	"github.com/satheeshds/portal/store"
 )
 
 // Config holds all runtime configuration for the portal handlers.
@@ -19,6 +27,93 @@ type Config struct {
 	// when NEXUS_CONTROL_URL is not configured.
 	AuthUser string
 	AuthPass string
+	// AllocationTolerance is how far, in the books currency's smallest unit
+	// (paise for INR), a link may exceed a document's unallocated amount to
+	// absorb rounding differences. A document within this much of fully
+	// allocated is treated as paid. Zero keeps the check strict.
+	AllocationTolerance models.Money
+	// LargeTxnThreshold is the transaction amount above which create and
+	// update requests must set confirmed_large, guarding against amounts keyed
+	// 100x too large. Zero disables the check.
+	LargeTxnThreshold models.Money
+	// MinorUnits is the number of decimal places document amounts are kept
+	// to: 2 (the default) stores paise, 0 whole rupees. A books currency with
+	// its own number, such as JPY, overrides it. Nil leaves the models setting
+	// as it is. It must not change once a tenant has stored amounts.
+	MinorUnits *int
+	// BooksCurrency is the currency the books are kept in. The trial balance
+	// sums only accounts in it and GET /reports/fx revalues the others into it.
+	// Empty means models.DefaultCurrency.
+	BooksCurrency string
+	// CreditLimitBlock makes creating an invoice that would take a customer's
+	// outstanding balance above its credit_limit fail with 422 instead of
+	// succeeding with a warning.
+	CreditLimitBlock bool
+	// LockReconciled makes reconciling a transaction, by reference or by
+	// payout auto-match, also lock it against edits and deletion.
+	LockReconciled bool
+	// PayoutOrderTolerance is how far, in the books currency's smallest unit,
+	// the sums of a payout's uploaded orders may differ from its gross,
+	// commission, and net amounts. The default is one whole unit.
+	PayoutOrderTolerance models.Money
+	// BasePath is the prefix every route is mounted under, e.g. "/accounting"
+	// when served behind a gateway. Empty mounts at the root. It never has a
+	// trailing slash.
+	BasePath string
+	// AccessTokenTTL and RefreshTokenTTL bound the session tokens issued by
+	// login in JWT mode. Neither outlives the Nexus JWT behind the session.
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	// DashboardCacheTTL is how long a tenant's dashboard is served from memory
+	// before it is recomputed. Writes clear it sooner. Zero disables the cache.
+	DashboardCacheTTL time.Duration
+	// ChangesOverlap is how far before its since cursor GET /changes starts
+	// reading, to catch writes whose database transaction began before the
+	// previous server_time but committed after it. Zero reads from since.
+	ChangesOverlap time.Duration
+	// DeletionsRetentionDays is how long POST /admin/purge keeps the
+	// tombstones GET /changes reports for deleted records. A client whose
+	// since cursor is older than this must do a full sync. Zero keeps them
+	// forever.
+	DeletionsRetentionDays int
+	// DefaultPageSize is the page size of paginated listings when the request
+	// sets no limit, and MaxPageSize the largest page served; larger requests
+	// are clamped. Zero means no default (everything) and no maximum.
+	DefaultPageSize int
+	MaxPageSize     int
+	// SuspenseDays is the default minimum age, in days, of the unallocated
+	// transactions listed by GET /transactions/suspense.
+	SuspenseDays int
+	// DebugErrors returns the underlying error of a 500 to the client instead
+	// of a generic message. It is for local development: the message can
+	// expose SQL and schema details.
+	DebugErrors bool
+	// DemoMode seeds sample accounts, contacts, documents, payouts, and
+	// transactions into each newly registered tenant's empty database, so
+	// evaluators see populated dashboards and reports straight away.
+	DemoMode bool
+	// CompressLevel is the gzip level (1-9) used to compress JSON responses.
+	// Zero disables compression.
+	CompressLevel int
+	// LoginMaxFailures is how many failed sign-ins a username may make from
+	// one client IP within LoginFailureWindow before further attempts get 429
+	// until the oldest failure leaves the window. Zero disables the lockout.
+	LoginMaxFailures   int
+	LoginFailureWindow time.Duration
+	// TrustProxy takes the client IP from the X-Forwarded-For or X-Real-IP
+	// header instead of the connection, for deployments behind a reverse
+	// proxy. Without a proxy that sets them, clients could choose their IP.
+	TrustProxy bool
+	// MinPasswordLength is the shortest password accepted by register. Zero
+	// leaves the check to the Nexus gateway.
+	MinPasswordLength int
+	// DisabledEndpoints names the route groups (see routeGroups) APIRoutes
+	// leaves unregistered, so a deployment can run without, say, payouts or
+	// reports. Their paths return 404.
+	DisabledEndpoints map[string]bool
+	// OCR reads receipt images for POST /bills/ocr. It is an HTTP extraction
+	// service when OCR_SERVICE_URL is set; nil means ocr.Manual.
+	OCR ocr.Extractor
 }
 
 // cfg is the package-level portal configuration. It is set once at startup
@@ -30,16 +125,199 @@ var cfg Config
 // with request handling.
 func Configure(c Config) {
 	cfg = c
+	store.AllocationTolerance = c.AllocationTolerance
+	store.LockReconciled = c.LockReconciled
+	store.ChangesOverlap = c.ChangesOverlap
+	store.DeletionsRetentionDays = c.DeletionsRetentionDays
+	store.BooksCurrency = c.BooksCurrency
+	if store.BooksCurrency == "" {
+		store.BooksCurrency = models.DefaultCurrency
+	}
+	if c.MinorUnits != nil {
+		models.SetMinorUnits(*c.MinorUnits)
+	}
+}
+
+// ClientConfig is the part of the configuration clients need to read and
+// enter amounts.
+type ClientConfig struct {
+	BooksCurrency      string         `json:"books_currency"`
+	MinorUnits         int            `json:"minor_units"`          // decimal places amounts are kept to
+	CurrencyMinorUnits map[string]int `json:"currency_minor_units"` // currencies with their own decimal places, such as 0 for JPY; others follow minor_units
+}
+
+// GetClientConfig returns the configuration clients need to format amounts
+//	@Summary		Get client configuration
+//	@Description	Get the books currency and the number of decimal places amounts are kept to. Every amount in the API is an integer in its currency's smallest unit:
+//	@Description	divide it by 10^minor_units, or by 10^currency_minor_units[currency] for the currencies listed there, to get whole units.
+//	@Tags			config
+//	@Produce		json
+//	@Success		200	{object}	Response{data=ClientConfig}
+//	@Router			/config [get]
+//	@Security		BearerAuth
+func GetClientConfig(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, ClientConfig{
+		BooksCurrency:      store.BooksCurrency,
+		MinorUnits:         models.AmountMinorUnits(),
+		CurrencyMinorUnits: models.FixedMinorUnitsTable(),
+	})
 }
 
 // ConfigFromEnv reads the portal configuration from environment variables.
 // It is intended to be called once from main during startup.
 func ConfigFromEnv() Config {
+	booksCurrency := booksCurrencyFromEnv()
+	minorUnits := minorUnitsFromEnv(booksCurrency)
 	return Config{
 		NexusControlURL: strings.TrimRight(os.Getenv("NEXUS_CONTROL_URL"), "/"),
 		AdminAPIKey:     os.Getenv("ADMIN_API_KEY"),
 		NexusHost:       os.Getenv("NEXUS_HOST"),
 		AuthUser:        os.Getenv("AUTH_USER"),
 		AuthPass:        os.Getenv("AUTH_PASS"),
+
+		AllocationTolerance:    models.Money(envInt("ALLOCATION_TOLERANCE_PAISE", 0)),
+		LargeTxnThreshold:      models.Money(envInt("LARGE_TXN_THRESHOLD", 0) * models.UnitScale(minorUnits)), // whole rupees
+		MinorUnits:             &minorUnits,
+		BooksCurrency:          booksCurrency,
+		LockReconciled:         os.Getenv("LOCK_RECONCILED") == "true",
+		CreditLimitBlock:       os.Getenv("CREDIT_LIMIT_BLOCK") == "true",
+		PayoutOrderTolerance:   models.Money(envInt("PAYOUT_ORDER_TOLERANCE_PAISE", models.UnitScale(minorUnits))), // one whole rupee
+		BasePath:               NormalizeBasePath(os.Getenv("BASE_PATH")),
+		AccessTokenTTL:         envDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTokenTTL:        envDuration("REFRESH_TOKEN_TTL", 7*24*time.Hour),
+		DashboardCacheTTL:      envDuration("DASHBOARD_CACHE_TTL", 30*time.Second),
+		ChangesOverlap:         envDuration("CHANGES_OVERLAP", time.Minute),
+		DeletionsRetentionDays: int(envInt("DELETIONS_RETENTION_DAYS", 90)),
+		DefaultPageSize:        int(envInt("DEFAULT_PAGE_SIZE", 0)),
+		MaxPageSize:            int(envInt("MAX_PAGE_SIZE", 0)),
+		SuspenseDays:           int(envInt("SUSPENSE_DAYS", 30)),
+		CompressLevel:          compressLevelFromEnv(),
+		DebugErrors:            os.Getenv("DEBUG_ERRORS") == "true",
+		DemoMode:               os.Getenv("DEMO_MODE") == "true",
+		LoginMaxFailures:       int(envInt("LOGIN_MAX_FAILURES", 5)),
+		LoginFailureWindow:     envDuration("LOGIN_FAILURE_WINDOW", 15*time.Minute),
+		TrustProxy:             os.Getenv("TRUST_PROXY") == "true",
+		MinPasswordLength:      int(envInt("MIN_PASSWORD_LENGTH", 8)),
+		DisabledEndpoints:      disabledEndpointsFromEnv(),
+		OCR:                    ocrFromEnv(),
+	}
+}
+
+// ocrFromEnv returns the receipt extractor configured by OCR_SERVICE_URL,
+// OCR_API_KEY, and OCR_TIMEOUT (default 30s), or ocr.Manual when no service
+// is set.
+func ocrFromEnv() ocr.Extractor {
+	url := strings.TrimSpace(os.Getenv("OCR_SERVICE_URL"))
+	if url == "" {
+		return ocr.Manual{}
+	}
+	return ocr.NewHTTP(url, os.Getenv("OCR_API_KEY"), envDuration("OCR_TIMEOUT", 30*time.Second))
+}
+
+// compressLevelFromEnv reads COMPRESS_LEVEL (default 5, 0 to disable),
+// falling back to the default for levels gzip does not support.
+func compressLevelFromEnv() int {
+	level := int(envInt("COMPRESS_LEVEL", 5))
+	if level > 9 {
+		slog.Warn("ignoring invalid COMPRESS_LEVEL; must be 0-9", "value", level)
+		return 5
+	}
+	return level
+}
+
+// disabledEndpointsFromEnv reads DISABLE_ENDPOINTS, a comma-separated list of
+// route group names such as "payouts,reports", ignoring names that match no
+// group.
+func disabledEndpointsFromEnv() map[string]bool {
+	known := map[string]bool{}
+	for _, g := range routeGroups {
+		known[g.name] = true
+	}
+	disabled := map[string]bool{}
+	for _, name := range strings.Split(os.Getenv("DISABLE_ENDPOINTS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch {
+		case name == "":
+		case known[name]:
+			disabled[name] = true
+		default:
+			slog.Warn("ignoring unknown route group in DISABLE_ENDPOINTS", "group", name)
+		}
+	}
+	return disabled
+}
+
+// minorUnitsFromEnv reads MINOR_UNITS (default 2), falling back to the
+// default for negative values and more decimal places than any currency uses.
+// Documents are kept to MINOR_UNITS decimal places and the transactions paying
+// them to those of their account's currency, so when booksCurrency has a
+// fixed number, such as 0 for JPY, that number is used instead.
+func minorUnitsFromEnv(booksCurrency string) int {
+	units := int(envInt("MINOR_UNITS", models.DefaultMinorUnits))
+	if units < 0 || units > 3 {
+		slog.Warn("ignoring invalid MINOR_UNITS; must be 0-3", "value", units)
+		units = models.DefaultMinorUnits
+	}
+	if fixed, ok := models.FixedMinorUnits(booksCurrency); ok && fixed != units {
+		if os.Getenv("MINOR_UNITS") != "" {
+			slog.Warn("ignoring MINOR_UNITS that differs from the books currency's decimal places",
+				"value", units, "books_currency", booksCurrency, "minor_units", fixed)
+		}
+		units = fixed
+	}
+	return units
+}
+
+// booksCurrencyFromEnv reads BOOKS_CURRENCY (default INR), falling back to
+// the default for values that are not ISO 4217 codes.
+func booksCurrencyFromEnv() string {
+	v := strings.TrimSpace(os.Getenv("BOOKS_CURRENCY"))
+	if v == "" {
+		return models.DefaultCurrency
+	}
+	if code := strings.ToUpper(v); models.IsCurrencyCode(code) {
+		return code
+	}
+	slog.Warn("ignoring invalid BOOKS_CURRENCY; must be a three-letter currency code", "value", v)
+	return models.DefaultCurrency
+}
+
+// NormalizeBasePath returns p with a leading slash and no trailing slash, or ""
+// when p is empty or "/".
+func NormalizeBasePath(p string) string {
+	p = strings.Trim(strings.TrimSpace(p), "/")
+	if p == "" {
+		return ""
+	}
+	return "/" + p
+}
+
+// envInt reads a non-negative integer environment variable, returning def when
+// it is unset or invalid.
+func envInt(key string, def int64) int64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 0 {
+		slog.Warn("ignoring invalid integer environment variable", "key", key, "value", v)
+		return def
+	}
+	return n
+}
+
+// envDuration reads a positive duration environment variable such as "15m",
+// returning def when it is unset or invalid.
+func envDuration(key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		slog.Warn("ignoring invalid duration environment variable", "key", key, "value", v)
+		return def
 	}
+	return d
 }
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestNormalizeBasePath(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"", ""},
+		{"/", ""},
+		{"accounting", "/accounting"},
+		{"/accounting/", "/accounting"},
+		{" /gateway/accounting ", "/gateway/accounting"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeBasePath(tt.input); got != tt.want {
+			t.Errorf("NormalizeBasePath(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestDisabledEndpointsFromEnv(t *testing.T) {
+	t.Setenv("DISABLE_ENDPOINTS", " Payouts, reports,,nonsense ")
+	got := disabledEndpointsFromEnv()
+	if len(got) != 2 || !got["payouts"] || !got["reports"] {
+		t.Errorf("disabledEndpointsFromEnv() = %v, want payouts and reports", got)
+	}
+}
+
+func TestMinorUnitsFromEnv(t *testing.T) {
+	for value, want := range map[string]int{"": 2, "0": 0, "3": 3, "4": 2, "-1": 2} {
+		t.Setenv("MINOR_UNITS", value)
+		if got := minorUnitsFromEnv("INR"); got != want {
+			t.Errorf("MINOR_UNITS=%q: minorUnitsFromEnv() = %d, want %d", value, got, want)
+		}
+	}
+	for value, want := range map[string]int{"": 0, "2": 0} {
+		t.Setenv("MINOR_UNITS", value)
+		if got := minorUnitsFromEnv("JPY"); got != want {
+			t.Errorf("MINOR_UNITS=%q with JPY books: minorUnitsFromEnv() = %d, want %d", value, got, want)
+		}
+	}
+}
+
+func TestBooksCurrencyFromEnv(t *testing.T) {
+	for value, want := range map[string]string{"": "INR", " usd ": "USD", "dollars": "INR"} {
+		t.Setenv("BOOKS_CURRENCY", value)
+		if got := booksCurrencyFromEnv(); got != want {
+			t.Errorf("booksCurrencyFromEnv() with %q = %q, want %q", value, got, want)
+		}
+	}
+}
+
+func TestPayoutOrderToleranceDefault(t *testing.T) {
+	for value, want := range map[string]int64{"": 100, "0": 1, "3": 1000} {
+		t.Setenv("MINOR_UNITS", value)
+		if got := ConfigFromEnv().PayoutOrderTolerance; int64(got) != want {
+			t.Errorf("MINOR_UNITS=%q: PayoutOrderTolerance = %d, want %d", value, got, want)
+		}
+	}
+}
+
+func TestGetClientConfig(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+
+	code, resp := apiRequest(t, r, "GET", "/api/v1/config", nil)
+	if code != http.StatusOK {
+		t.Fatalf("GET /config: expected 200, got %d: %v", code, resp)
+	}
+	data := resp["data"].(map[string]interface{})
+	if data["books_currency"] != "INR" || data["minor_units"] != float64(2) {
+		t.Errorf("expected INR with 2 minor units, got %v", data)
+	}
+	units := data["currency_minor_units"].(map[string]interface{})
+	if units["JPY"] != float64(0) || units["KWD"] != float64(3) {
+		t.Errorf("expected JPY 0 and KWD 3, got %v", units)
+	}
+}
@@ -4,21 +4,22 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
-	"github.com/go-chi/chi/v5"
 	"github.com/satheeshds/portal/models"
 	"github.com/satheeshds/portal/store"
 )
 
 // ListContacts lists all contacts
 //	@Summary		List contacts
-//	@Description	Get a list of all vendors and customers with financial summaries.
+//	@Description	Get a list of all vendors and customers with financial summaries. Balances are read from a per-contact cache that bill, invoice, and payment link writes keep current; pass live=true to sum them from the documents instead.
 //	@Tags			contacts
 //	@Produce		json
 //	@Param			type	query		string	false	"Filter by type (vendor/customer)"
 //	@Param			search	query		string	false	"Search by name, email, or phone"
+//	@Param			live	query		bool	false	"Sum balances from documents instead of reading the cached balances"
 //	@Success		200		{object}	Response{data=[]models.Contact}
 //	@Router			/contacts [get]
 //	@Security		BearerAuth
@@ -26,9 +27,9 @@ func ListContacts(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
 	typeFilter := r.URL.Query().Get("type")
 	search := r.URL.Query().Get("search")
-	contacts, err := s.ListContacts(typeFilter, search)
+	contacts, err := s.ListContacts(typeFilter, search, r.URL.Query().Get("live") == "true")
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeInternalError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, contacts)
@@ -41,24 +42,132 @@ func ListContacts(w http.ResponseWriter, r *http.Request) {
 //	@Produce		json
 //	@Param			id	path		int	true	"Contact ID"
 //	@Success		200	{object}	Response{data=models.Contact}
-//	@Failure		404	{object}	Response{error=string}
+//	@Failure		400	{object}	Response{error=string}
+//	@Failure		404	{object}	Response{error=string,code=string,resource=string}
 //	@Router			/contacts/{id} [get]
 //	@Security		BearerAuth
 func GetContact(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
 	c, err := s.GetContact(id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			writeError(w, http.StatusNotFound, "contact not found")
+			writeNotFound(w, "contact")
 		} else {
-			writeError(w, http.StatusInternalServerError, err.Error())
+			writeInternalError(w, r, err)
 		}
 		return
 	}
 	writeJSON(w, http.StatusOK, c)
 }
 
+// ContactDocuments groups everything recorded against a contact.
+type ContactDocuments struct {
+	Contact      models.Contact       `json:"contact"`
+	Bills        []models.Bill        `json:"bills"`    // vendors only
+	Invoices     []models.Invoice     `json:"invoices"` // customers only
+	Transactions []models.Transaction `json:"transactions"`
+}
+
+// GetContactDocuments retrieves a contact's bills or invoices and transactions
+//	@Summary		Get contact documents
+//	@Description	Get a contact together with their bills (vendors) or invoices (customers), including status and outstanding amounts, and the transactions tagged to them.
+//	@Description	status, from, and to filter the documents as on /bills and /invoices; from and to also filter transactions by date.
+//	@Tags			contacts
+//	@Produce		json
+//	@Param			id					path		int		true	"Contact ID"
+//	@Param			status				query		string	false	"Filter bills/invoices by status"
+//	@Param			from				query		string	false	"Start date (YYYY-MM-DD)"
+//	@Param			to					query		string	false	"End date (YYYY-MM-DD)"
+//	@Param			include_cancelled	query		bool	false	"Include cancelled bills/invoices (excluded by default)"
+//	@Success		200					{object}	Response{data=ContactDocuments}
+//	@Failure		400					{object}	Response{error=string}
+//	@Failure		404					{object}	Response{error=string}
+//	@Router			/contacts/{id}/documents [get]
+//	@Security		BearerAuth
+func GetContactDocuments(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+	c, err := s.GetContact(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "contact not found")
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+
+	q, ok := listQuery(w, r, nil, []string{"from", "to"})
+	if !ok {
+		return
+	}
+	contactID := strconv.Itoa(id)
+	status, from, to := q.Get("status"), q.Get("from"), q.Get("to")
+	includeCancelled := q.Get("include_cancelled") == "true"
+
+	result := ContactDocuments{Contact: c, Bills: []models.Bill{}, Invoices: []models.Invoice{}}
+	switch c.Type {
+	case "vendor":
+		result.Bills, err = s.ListBills(status, contactID, "", from, to, "", includeCancelled)
+	case "customer":
+		result.Invoices, err = s.ListInvoices(status, contactID, from, to, "", includeCancelled)
+	}
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	result.Transactions, err = s.ListTransactions("", "", contactID, "", from, to, "", "", "")
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// ContactPayment is an alias for store.ContactPayment kept here for Swagger doc references.
+type ContactPayment = store.ContactPayment
+
+// GetContactPayments lists the payments against a contact's bills and invoices
+//	@Summary		Get contact payments
+//	@Description	List every transaction link to the contact's bills and invoices, oldest transaction first, with the document's number and date and the transaction's date, type, and account.
+//	@Description	A contact that is both a customer and a vendor gets one history covering both.
+//	@Tags			contacts
+//	@Produce		json
+//	@Param			id	path		int	true	"Contact ID"
+//	@Success		200	{object}	Response{data=[]ContactPayment}
+//	@Failure		400	{object}	Response{error=string}
+//	@Failure		404	{object}	Response{error=string}
+//	@Router			/contacts/{id}/payments [get]
+//	@Security		BearerAuth
+func GetContactPayments(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+	if _, err := s.GetContact(id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "contact not found")
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+	payments, err := s.ListContactPayments(id)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, payments)
+}
+
 // CreateContact creates a new contact
 //	@Summary		Create contact
 //	@Description	Create a new vendor or customer.
@@ -83,28 +192,36 @@ func CreateContact(w http.ResponseWriter, r *http.Request) {
 	}
 	c, err := s.CreateContact(input)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeInternalError(w, r, err)
 		return
 	}
+	publishEvent(r, "created", "contact", c.ID)
 	writeJSON(w, http.StatusCreated, c)
 }
 
 // UpdateContact updates an existing contact
 //	@Summary		Update contact
-//	@Description	Update details of an existing contact.
+//	@Description	Update details of an existing contact. Changing the type is rejected with 409 while
+//	@Description	the contact has documents of the old type (bills for a vendor, invoices for a customer),
+//	@Description	unless force=true is passed.
 //	@Tags			contacts
 //	@Accept			json
 //	@Produce		json
 //	@Param			id		path		int					true	"Contact ID"
+//	@Param			force	query		bool				false	"Change the type even if incompatible documents exist"
 //	@Param			contact	body		models.ContactInput	true	"Updated contact contents"
 //	@Success		200		{object}	Response{data=models.Contact}
 //	@Failure		400		{object}	Response{error=string}
 //	@Failure		404		{object}	Response{error=string}
+//	@Failure		409		{object}	Response{data=[]ContactDocument,error=string}
 //	@Router			/contacts/{id} [put]
 //	@Security		BearerAuth
 func UpdateContact(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
 	var input models.ContactInput
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid JSON")
@@ -114,15 +231,38 @@ func UpdateContact(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, msg)
 		return
 	}
+	existing, err := s.GetContact(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "contact not found")
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+	if existing.Type != input.Type && r.URL.Query().Get("force") != "true" {
+		docs, err := s.IncompatibleContactDocuments(id, input.Type)
+		if err != nil {
+			writeInternalError(w, r, err)
+			return
+		}
+		if len(docs) > 0 {
+			writeErrorData(w, http.StatusConflict,
+				fmt.Sprintf("contact has %d document(s) incompatible with type %s; pass force=true to change anyway", len(docs), input.Type),
+				docs)
+			return
+		}
+	}
 	c, err := s.UpdateContact(id, input)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			writeError(w, http.StatusNotFound, "contact not found")
 		} else {
-			writeError(w, http.StatusInternalServerError, err.Error())
+			writeInternalError(w, r, err)
 		}
 		return
 	}
+	publishEvent(r, "updated", "contact", c.ID)
 	writeJSON(w, http.StatusOK, c)
 }
 
@@ -133,19 +273,88 @@ func UpdateContact(w http.ResponseWriter, r *http.Request) {
 //	@Produce		json
 //	@Param			id	path		int	true	"Contact ID"
 //	@Success		200	{object}	Response{data=map[string]string}
+//	@Failure		400	{object}	Response{error=string}
 //	@Failure		404	{object}	Response{error=string}
 //	@Router			/contacts/{id} [delete]
 //	@Security		BearerAuth
 func DeleteContact(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
 	if err := s.DeleteContact(id); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			writeError(w, http.StatusNotFound, "contact not found")
 		} else {
-			writeError(w, http.StatusInternalServerError, err.Error())
+			writeInternalError(w, r, err)
 		}
 		return
 	}
+	publishEvent(r, "deleted", "contact", id)
 	writeJSON(w, http.StatusOK, map[string]string{"message": "deleted"})
 }
+
+// ContactDeleteImpact is an alias for store.ContactDeleteImpact kept here for Swagger doc references.
+type ContactDeleteImpact = store.ContactDeleteImpact
+
+// GetContactDeleteImpact previews what deleting a contact would affect
+//	@Summary		Preview contact deletion
+//	@Description	Count the bills, invoices, transactions, and recurring payments that reference the contact. Deletion does not cascade, so these
+//	@Description	rows would be left pointing at a missing contact; use the counts to confirm before deleting.
+//	@Tags			contacts
+//	@Produce		json
+//	@Param			id	path		int	true	"Contact ID"
+//	@Success		200	{object}	Response{data=ContactDeleteImpact}
+//	@Failure		400	{object}	Response{error=string}
+//	@Failure		404	{object}	Response{error=string,code=string,resource=string}
+//	@Router			/contacts/{id}/delete-impact [get]
+//	@Security		BearerAuth
+func GetContactDeleteImpact(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+	if _, err := s.GetContact(id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeNotFound(w, "contact")
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+	impact, err := s.GetContactDeleteImpact(id)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, impact)
+}
+
+// ContactDocument is an alias for store.ContactDocument kept here for Swagger doc references.
+type ContactDocument = store.ContactDocument
+
+// checkContactType reports whether contactID, when set, names a contact of
+// type want (vendor for bills, customer for invoices), writing a 400 if not.
+// Contact balances and documents branch on type, so a bill against a customer
+// would be counted on neither side.
+func checkContactType(w http.ResponseWriter, r *http.Request, s *store.Store, contactID *int, want, document string) bool {
+	if contactID == nil {
+		return true
+	}
+	c, err := s.GetContact(*contactID)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("contact %d does not exist", *contactID))
+		return false
+	}
+	if err != nil {
+		writeInternalError(w, r, err)
+		return false
+	}
+	if c.Type != want {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("contact %d is a %s; %s must reference a %s", c.ID, c.Type, document, want))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,343 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func setupContactsTestRouter(t *testing.T) (*chi.Mux, func()) {
+	t.Helper()
+	r, cleanup := setupTestRouter(t)
+
+	r.Post("/api/v1/contacts", CreateContact)
+	r.Get("/api/v1/contacts/{id}", GetContact)
+	r.Put("/api/v1/contacts/{id}", UpdateContact)
+	r.Post("/api/v1/bills", CreateBill)
+
+	return r, cleanup
+}
+
+// TestUpdateContactTypeWithBills verifies that flipping a vendor with bills to
+// customer is rejected with 409 listing the bills, and allowed with force=true.
+func TestUpdateContactTypeWithBills(t *testing.T) {
+	r, cleanup := setupContactsTestRouter(t)
+	defer cleanup()
+
+	status, resp := apiRequest(t, r, "POST", "/api/v1/contacts", map[string]interface{}{
+		"name": "Acme Supplies", "type": "vendor",
+	})
+	if status != http.StatusCreated {
+		t.Fatalf("create contact: status %d, error %v", status, resp["error"])
+	}
+	contactID := int(resp["data"].(map[string]interface{})["id"].(float64))
+
+	status, resp = apiRequest(t, r, "POST", "/api/v1/bills", map[string]interface{}{
+		"contact_id": contactID, "bill_number": "BILL-001", "amount": 100.0, "status": "draft",
+	})
+	if status != http.StatusCreated {
+		t.Fatalf("create bill: status %d, error %v", status, resp["error"])
+	}
+	billID := int(resp["data"].(map[string]interface{})["id"].(float64))
+
+	// A name-only update keeps the type and must succeed.
+	status, resp = apiRequest(t, r, "PUT", fmt.Sprintf("/api/v1/contacts/%d", contactID), map[string]interface{}{
+		"name": "Acme Supplies Ltd", "type": "vendor",
+	})
+	if status != http.StatusOK {
+		t.Fatalf("rename contact: status %d, error %v", status, resp["error"])
+	}
+
+	// Flipping to customer must be rejected and list the bill.
+	status, resp = apiRequest(t, r, "PUT", fmt.Sprintf("/api/v1/contacts/%d", contactID), map[string]interface{}{
+		"name": "Acme Supplies Ltd", "type": "customer",
+	})
+	if status != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", status)
+	}
+	docs := resp["data"].([]interface{})
+	if len(docs) != 1 {
+		t.Fatalf("expected 1 conflicting document, got %d", len(docs))
+	}
+	doc := docs[0].(map[string]interface{})
+	if doc["document_type"] != "bill" || int(doc["id"].(float64)) != billID || doc["number"] != "BILL-001" {
+		t.Errorf("unexpected conflicting document: %v", doc)
+	}
+
+	_, resp = apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/contacts/%d", contactID), nil)
+	if got := resp["data"].(map[string]interface{})["type"]; got != "vendor" {
+		t.Errorf("expected type to remain vendor, got %v", got)
+	}
+
+	// force=true applies the change anyway.
+	status, resp = apiRequest(t, r, "PUT", fmt.Sprintf("/api/v1/contacts/%d?force=true", contactID), map[string]interface{}{
+		"name": "Acme Supplies Ltd", "type": "customer",
+	})
+	if status != http.StatusOK {
+		t.Fatalf("forced update: status %d, error %v", status, resp["error"])
+	}
+	if got := resp["data"].(map[string]interface{})["type"]; got != "customer" {
+		t.Errorf("expected type customer after forced update, got %v", got)
+	}
+}
+
+// TestUpdateContactTypeWithoutDocuments verifies that a contact with no
+// documents can change type freely.
+func TestUpdateContactTypeWithoutDocuments(t *testing.T) {
+	r, cleanup := setupContactsTestRouter(t)
+	defer cleanup()
+
+	_, resp := apiRequest(t, r, "POST", "/api/v1/contacts", map[string]interface{}{
+		"name": "Walk-in", "type": "vendor",
+	})
+	contactID := int(resp["data"].(map[string]interface{})["id"].(float64))
+
+	status, resp := apiRequest(t, r, "PUT", fmt.Sprintf("/api/v1/contacts/%d", contactID), map[string]interface{}{
+		"name": "Walk-in", "type": "customer",
+	})
+	if status != http.StatusOK {
+		t.Fatalf("update contact: status %d, error %v", status, resp["error"])
+	}
+}
+
+// TestGetContactDocuments verifies that a vendor's bills and tagged
+// transactions are returned together and that the status filter applies.
+func TestGetContactDocuments(t *testing.T) {
+	r, cleanup := setupContactsTestRouter(t)
+	defer cleanup()
+	r.Get("/api/v1/contacts/{id}/documents", GetContactDocuments)
+
+	_, resp := apiRequest(t, r, "POST", "/api/v1/contacts", map[string]interface{}{
+		"name": "Acme Supplies", "type": "vendor",
+	})
+	contactID := int(resp["data"].(map[string]interface{})["id"].(float64))
+	_, resp = apiRequest(t, r, "POST", "/api/v1/accounts", map[string]interface{}{
+		"name": "Bank Account", "type": "bank", "opening_balance": 0,
+	})
+	accID := int(resp["data"].(map[string]interface{})["id"].(float64))
+
+	for _, status := range []string{"draft", "received"} {
+		apiRequest(t, r, "POST", "/api/v1/bills", map[string]interface{}{
+			"contact_id": contactID, "bill_number": "BILL-" + status, "amount": 100.0, "status": status,
+		})
+	}
+	apiRequest(t, r, "POST", "/api/v1/transactions", map[string]interface{}{
+		"account_id": accID, "type": "expense", "amount": 40.0, "transaction_date": "2024-01-15", "contact_id": contactID,
+	})
+	apiRequest(t, r, "POST", "/api/v1/transactions", map[string]interface{}{
+		"account_id": accID, "type": "expense", "amount": 10.0, "transaction_date": "2024-01-15",
+	})
+
+	status, resp := apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/contacts/%d/documents", contactID), nil)
+	if status != http.StatusOK {
+		t.Fatalf("get documents: status %d, error %v", status, resp["error"])
+	}
+	data := resp["data"].(map[string]interface{})
+	if got := len(data["bills"].([]interface{})); got != 2 {
+		t.Errorf("expected 2 bills, got %d", got)
+	}
+	if got := len(data["invoices"].([]interface{})); got != 0 {
+		t.Errorf("expected no invoices for a vendor, got %d", got)
+	}
+	if txns := data["transactions"].([]interface{}); len(txns) != 1 || txns[0].(map[string]interface{})["amount"].(float64) != 4000 {
+		t.Errorf("expected only the tagged transaction, got %v", txns)
+	}
+
+	_, resp = apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/contacts/%d/documents?status=draft", contactID), nil)
+	if bills := resp["data"].(map[string]interface{})["bills"].([]interface{}); len(bills) != 1 ||
+		bills[0].(map[string]interface{})["bill_number"] != "BILL-draft" {
+		t.Errorf("expected only the draft bill, got %v", bills)
+	}
+
+	if status, _ := apiRequest(t, r, "GET", "/api/v1/contacts/9999/documents", nil); status != http.StatusNotFound {
+		t.Errorf("unknown contact: expected 404, got %d", status)
+	}
+}
+
+// TestGetContactPayments verifies that a contact's payment history covers the
+// links to its documents, oldest transaction first, and leaves out other
+// contacts' documents.
+func TestGetContactPayments(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+
+	contactID := createResource(t, r, "/api/v1/contacts", map[string]interface{}{"name": "Acme Events", "type": "customer"})
+	otherID := createResource(t, r, "/api/v1/contacts", map[string]interface{}{"name": "Other Co", "type": "vendor"})
+	accID := createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": "Bank", "type": "bank", "opening_balance": 0})
+	invoiceID := createResource(t, r, "/api/v1/invoices", map[string]interface{}{
+		"contact_id": contactID, "invoice_number": "INV-7", "issue_date": "2024-02-01", "amount": 500.0, "status": "sent",
+	})
+	earlierID := createResource(t, r, "/api/v1/invoices", map[string]interface{}{
+		"contact_id": contactID, "invoice_number": "INV-3", "issue_date": "2024-01-05", "amount": 80.0, "status": "sent",
+	})
+	otherBillID := createResource(t, r, "/api/v1/bills", map[string]interface{}{
+		"contact_id": otherID, "bill_number": "B-9", "amount": 20.0, "status": "received",
+	})
+
+	link := func(typ, date string, amount float64, docType string, docID int) {
+		t.Helper()
+		txnID := createResource(t, r, "/api/v1/transactions", map[string]interface{}{
+			"account_id": accID, "type": typ, "amount": amount, "transaction_date": date,
+		})
+		status, resp := apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/transactions/%d/links", txnID), map[string]interface{}{
+			"document_type": docType, "document_id": docID, "amount": amount,
+		})
+		if status != http.StatusCreated {
+			t.Fatalf("link %s %d: status %d, error %v", docType, docID, status, resp["error"])
+		}
+	}
+	link("income", "2024-02-10", 300.0, "invoice", invoiceID)
+	link("income", "2024-01-20", 80.0, "invoice", earlierID)
+	link("expense", "2024-01-25", 20.0, "bill", otherBillID)
+
+	status, resp := apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/contacts/%d/payments", contactID), nil)
+	if status != http.StatusOK {
+		t.Fatalf("get payments: status %d, error %v", status, resp["error"])
+	}
+	payments := resp["data"].([]interface{})
+	if len(payments) != 2 {
+		t.Fatalf("expected 2 payments, got %v", payments)
+	}
+	first, second := payments[0].(map[string]interface{}), payments[1].(map[string]interface{})
+	if first["document_type"] != "invoice" || first["document_number"] != "INV-3" || first["document_date"] != "2024-01-05" ||
+		first["transaction_type"] != "income" || first["amount"].(float64) != 8000 || first["account_name"] != "Bank" {
+		t.Errorf("unexpected first payment: %v", first)
+	}
+	if second["document_type"] != "invoice" || second["document_number"] != "INV-7" || second["transaction_date"] != "2024-02-10" ||
+		second["amount"].(float64) != 30000 {
+		t.Errorf("unexpected invoice payment: %v", second)
+	}
+
+	if status, _ := apiRequest(t, r, "GET", "/api/v1/contacts/9999/payments", nil); status != http.StatusNotFound {
+		t.Errorf("unknown contact: expected 404, got %d", status)
+	}
+}
+
+// TestGetContactDeleteImpact verifies that the delete preview counts the
+// bills and transactions referencing a contact, and 404s for unknown ones.
+func TestGetContactDeleteImpact(t *testing.T) {
+	r, cleanup := setupContactsTestRouter(t)
+	defer cleanup()
+	r.Get("/api/v1/contacts/{id}/delete-impact", GetContactDeleteImpact)
+
+	_, resp := apiRequest(t, r, "POST", "/api/v1/contacts", map[string]interface{}{"name": "Acme Supplies", "type": "vendor"})
+	contactID := int(resp["data"].(map[string]interface{})["id"].(float64))
+	_, resp = apiRequest(t, r, "POST", "/api/v1/accounts", map[string]interface{}{"name": "Current", "type": "bank"})
+	accID := int(resp["data"].(map[string]interface{})["id"].(float64))
+
+	for _, number := range []string{"BILL-001", "BILL-002"} {
+		if status, resp := apiRequest(t, r, "POST", "/api/v1/bills", map[string]interface{}{
+			"contact_id": contactID, "bill_number": number, "amount": 100.0,
+		}); status != http.StatusCreated {
+			t.Fatalf("create bill: status %d, error %v", status, resp["error"])
+		}
+	}
+	if status, resp := apiRequest(t, r, "POST", "/api/v1/transactions", map[string]interface{}{
+		"account_id": accID, "contact_id": contactID, "type": "expense", "amount": 50.0, "transaction_date": "2024-01-15",
+	}); status != http.StatusCreated {
+		t.Fatalf("create transaction: status %d, error %v", status, resp["error"])
+	}
+
+	status, resp := apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/contacts/%d/delete-impact", contactID), nil)
+	if status != http.StatusOK {
+		t.Fatalf("delete impact: status %d, error %v", status, resp["error"])
+	}
+	impact := resp["data"].(map[string]interface{})
+	want := map[string]float64{"bills": 2, "invoices": 0, "transactions": 1, "recurring_payments": 0, "total": 3}
+	for key, n := range want {
+		if impact[key] != n {
+			t.Errorf("%s = %v, want %v", key, impact[key], n)
+		}
+	}
+
+	if status, _ := apiRequest(t, r, "GET", "/api/v1/contacts/9999/delete-impact", nil); status != http.StatusNotFound {
+		t.Errorf("unknown contact: expected 404, got %d", status)
+	}
+}
+
+// TestContactEmptyEmailStoredAsNull verifies that blank optional fields sent by
+// forms are stored as NULL, on create and on update, rather than as "".
+func TestContactEmptyEmailStoredAsNull(t *testing.T) {
+	r, cleanup := setupContactsTestRouter(t)
+	defer cleanup()
+
+	status, resp := apiRequest(t, r, "POST", "/api/v1/contacts", map[string]interface{}{
+		"name": "Acme Supplies", "type": "vendor", "email": "", "phone": "  ",
+	})
+	if status != http.StatusCreated {
+		t.Fatalf("create contact: status %d, error %v", status, resp["error"])
+	}
+	data := resp["data"].(map[string]interface{})
+	contactID := int(data["id"].(float64))
+	if data["email"] != nil || data["phone"] != nil {
+		t.Errorf("expected null email and phone, got %v and %v", data["email"], data["phone"])
+	}
+
+	countNull := func() int {
+		t.Helper()
+		var n int
+		if err := DB.QueryRow("SELECT COUNT(*) FROM contacts WHERE id = ? AND email IS NULL", contactID).Scan(&n); err != nil {
+			t.Fatalf("count: %v", err)
+		}
+		return n
+	}
+	if countNull() != 1 {
+		t.Error("expected email stored as NULL after create")
+	}
+
+	status, resp = apiRequest(t, r, "PUT", fmt.Sprintf("/api/v1/contacts/%d", contactID), map[string]interface{}{
+		"name": "Acme Supplies", "type": "vendor", "email": " accounts@acme.test ",
+	})
+	if status != http.StatusOK {
+		t.Fatalf("update contact: status %d, error %v", status, resp["error"])
+	}
+	if got := resp["data"].(map[string]interface{})["email"]; got != "accounts@acme.test" {
+		t.Errorf("expected trimmed email, got %v", got)
+	}
+
+	status, resp = apiRequest(t, r, "PUT", fmt.Sprintf("/api/v1/contacts/%d", contactID), map[string]interface{}{
+		"name": "Acme Supplies", "type": "vendor", "email": "",
+	})
+	if status != http.StatusOK {
+		t.Fatalf("clear email: status %d, error %v", status, resp["error"])
+	}
+	if countNull() != 1 {
+		t.Error("expected email stored as NULL after clearing it")
+	}
+}
+
+// TestContactTaxIDs verifies that gstin and pan are normalized and stored, and
+// that an invalid GSTIN is rejected with a gstin error.
+func TestContactTaxIDs(t *testing.T) {
+	r, cleanup := setupContactsTestRouter(t)
+	defer cleanup()
+
+	status, resp := apiRequest(t, r, "POST", "/api/v1/contacts", map[string]interface{}{
+		"name": "Acme Traders", "type": "customer", "gstin": " 27aapfu0939f1zv", "pan": "aapfu0939f",
+	})
+	if status != http.StatusCreated {
+		t.Fatalf("create contact: status %d, error %v", status, resp["error"])
+	}
+	contactID := int(resp["data"].(map[string]interface{})["id"].(float64))
+	status, resp = apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/contacts/%d", contactID), nil)
+	if status != http.StatusOK {
+		t.Fatalf("get contact: status %d", status)
+	}
+	data := resp["data"].(map[string]interface{})
+	if data["gstin"] != "27AAPFU0939F1ZV" || data["pan"] != "AAPFU0939F" {
+		t.Errorf("gstin %v pan %v, want 27AAPFU0939F1ZV and AAPFU0939F", data["gstin"], data["pan"])
+	}
+
+	status, resp = apiRequest(t, r, "PUT", fmt.Sprintf("/api/v1/contacts/%d", contactID), map[string]interface{}{
+		"name": "Acme Traders", "type": "customer", "gstin": "27AAPFU0939F1ZX",
+	})
+	if status != http.StatusBadRequest {
+		t.Fatalf("invalid gstin: expected 400, got %d", status)
+	}
+	if msg, _ := resp["error"].(string); !strings.HasPrefix(msg, "gstin:") {
+		t.Errorf("expected a gstin error, got %q", msg)
+	}
+}
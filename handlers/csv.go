@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/satheeshds/portal/models"
+)
+
+// wantsCSV reports whether the Accept header of r ranks text/csv above JSON.
+// Without one, or on a tie, JSON wins.
+func wantsCSV(r *http.Request) bool {
+	var csvQ, jsonQ float64
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		switch mediaType {
+		case "text/csv":
+			csvQ = max(csvQ, q)
+		case "application/json", "*/*":
+			jsonQ = max(jsonQ, q)
+		}
+	}
+	return csvQ > 0 && csvQ > jsonQ
+}
+
+// streamList writes the items each yields as streamJSON does, or, when the
+// client asks for text/csv, as a CSV attachment named filename with header
+// and one record per item. Money columns in CSV follow the amount_format
+// query parameter, in the decimal places of each amount's currency. Once CSV output has started an error can no longer be
+// reported, so the file is cut short and the error logged.
+func streamList[T any](w http.ResponseWriter, r *http.Request, filename string, header []string,
+	record func(item T, amount func(models.Money, string) string) []string, each func(yield func(T) error) error) {
+	w.Header().Add("Vary", "Accept")
+	if !wantsCSV(r) {
+		streamJSON(w, r, each)
+		return
+	}
+	amount, ok := csvAmountFormat(w, r)
+	if !ok {
+		return
+	}
+
+	cw := csv.NewWriter(w)
+	started := false
+	start := func() {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+		w.WriteHeader(http.StatusOK)
+		_ = cw.Write(header)
+		started = true
+	}
+	err := each(func(item T) error {
+		if !started {
+			start()
+		}
+		return cw.Write(record(item, amount))
+	})
+	if err != nil && !started {
+		writeInternalError(w, r, err)
+		return
+	}
+	if !started {
+		start()
+	}
+	cw.Flush()
+	if err != nil {
+		internalErrorMessage(r, err)
+	}
+}
+
+// eachOf yields the elements of list, for passing a loaded list to streamList.
+func eachOf[T any](list []T) func(yield func(T) error) error {
+	return func(yield func(T) error) error {
+		for _, item := range list {
+			if err := yield(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// csvString renders an optional text column.
+func csvString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// csvInt renders an optional id column.
+func csvInt(n *int) string {
+	if n == nil {
+		return ""
+	}
+	return strconv.Itoa(*n)
+}
+
+var transactionCSVHeader = []string{"id", "transaction_date", "account_id", "account_name", "type", "amount", "currency",
+	"description", "reference", "transfer_account_id", "contact_id", "contact_name", "reconciled", "allocated", "unallocated"}
+
+func transactionCSVRecord(t models.Transaction, amount func(models.Money, string) string) []string {
+	return []string{strconv.Itoa(t.ID), t.TransactionDate.String(), strconv.Itoa(t.AccountID), csvString(t.AccountName), t.Type,
+		amount(t.Amount, t.Currency), t.Currency, csvString(t.Description), csvString(t.Reference), csvInt(t.TransferAccountID),
+		csvInt(t.ContactID), csvString(t.ContactName), strconv.FormatBool(t.Reconciled), amount(t.Allocated, t.Currency),
+		amount(t.Unallocated, t.Currency)}
+}
+
+var billCSVHeader = []string{"id", "bill_number", "issue_date", "due_date", "contact_id", "contact_name", "amount",
+	"status", "allocated", "unallocated", "notes"}
+
+// Bills, invoices, and payouts are kept in the default currency.
+
+func billCSVRecord(b models.Bill, amount func(models.Money, string) string) []string {
+	inr := func(m models.Money) string { return amount(m, models.DefaultCurrency) }
+	return []string{strconv.Itoa(b.ID), b.BillNumber, b.IssueDate.String(), b.DueDate.String(), csvInt(b.ContactID),
+		csvString(b.ContactName), inr(b.Amount), b.Status, inr(b.Allocated), inr(b.Unallocated), csvString(b.Notes)}
+}
+
+var invoiceCSVHeader = []string{"id", "invoice_number", "issue_date", "due_date", "contact_id", "contact_name", "amount",
+	"tax_amount", "status", "allocated", "unallocated", "notes"}
+
+func invoiceCSVRecord(i models.Invoice, amount func(models.Money, string) string) []string {
+	inr := func(m models.Money) string { return amount(m, models.DefaultCurrency) }
+	return []string{strconv.Itoa(i.ID), i.InvoiceNumber, i.IssueDate.String(), i.DueDate.String(), csvInt(i.ContactID),
+		csvString(i.ContactName), inr(i.Amount), inr(i.TaxAmount), i.Status, inr(i.Allocated), inr(i.Unallocated),
+		csvString(i.Notes)}
+}
+
+var payoutCSVHeader = []string{"id", "platform", "outlet_id", "outlet_name", "period_start", "period_end", "settlement_date",
+	"total_orders", "gross_sales_amt", "restaurant_discount_amt", "platform_commission_amt", "taxes_tcs_tds_amt",
+	"marketing_ads_amt", "final_payout_amt", "utr_number", "allocated", "unallocated"}
+
+func payoutCSVRecord(p models.Payout, amount func(models.Money, string) string) []string {
+	inr := func(m models.Money) string { return amount(m, models.DefaultCurrency) }
+	return []string{strconv.Itoa(p.ID), p.Platform, csvInt(p.OutletID), p.OutletName, p.PeriodStart.String(), p.PeriodEnd.String(),
+		p.SettlementDate.String(), strconv.Itoa(p.TotalOrders), inr(p.GrossSalesAmt), inr(p.RestaurantDiscountAmt),
+		inr(p.PlatformCommissionAmt), inr(p.TaxesTcsTdsAmt), inr(p.MarketingAdsAmt), inr(p.FinalPayoutAmt),
+		p.UtrNumber, inr(p.Allocated), inr(p.Unallocated)}
+}
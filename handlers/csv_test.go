@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWantsCSV(t *testing.T) {
+	for _, tc := range []struct {
+		accept string
+		want   bool
+	}{
+		{"", false},
+		{"application/json", false},
+		{"text/csv", true},
+		{"text/csv; charset=utf-8", true},
+		{"application/json, text/csv", false},
+		{"application/json;q=0.5, text/csv", true},
+		{"*/*", false},
+		{"text/csv;q=0", false},
+	} {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Accept", tc.accept)
+		if got := wantsCSV(r); got != tc.want {
+			t.Errorf("wantsCSV(%q) = %v, want %v", tc.accept, got, tc.want)
+		}
+	}
+}
+
+// TestListCSV verifies that list endpoints return CSV with the same filters
+// when asked for text/csv, and JSON otherwise, with transaction amounts in
+// their account's currency.
+func TestListCSV(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+
+	acc := createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": "Current", "type": "bank"})
+	createResource(t, r, "/api/v1/transactions", map[string]interface{}{
+		"account_id": acc, "type": "income", "amount": 1234.5, "transaction_date": "2024-01-05", "description": "Sales, cash",
+	})
+	createResource(t, r, "/api/v1/transactions", map[string]interface{}{
+		"account_id": acc, "type": "expense", "amount": 10.0, "transaction_date": "2024-02-05",
+	})
+
+	get := func(path, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Accept", accept)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/api/v1/transactions?to=2024-01-31&amount_format=paise", "text/csv")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "text/csv" {
+		t.Fatalf("expected CSV, got %d %q: %s", w.Code, w.Header().Get("Content-Type"), w.Body.String())
+	}
+	records, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("parse CSV: %v", err)
+	}
+	if len(records) != 2 || records[0][0] != "id" || records[1][5] != "123450" || records[1][6] != "INR" || records[1][7] != "Sales, cash" {
+		t.Errorf("unexpected CSV: %v", records)
+	}
+
+	// An account kept in yen from before such currencies were refused has
+	// its amounts written with no decimal places.
+	var yen int
+	if err := DB.QueryRow("INSERT INTO accounts (name, type, currency, opening_balance) VALUES ('Yen', 'bank', 'JPY', 0) RETURNING id").Scan(&yen); err != nil {
+		t.Fatalf("insert yen account: %v", err)
+	}
+	if _, err := DB.Exec("INSERT INTO transactions (account_id, type, amount, transaction_date) VALUES (?, 'income', 1500, '2024-01-06')", yen); err != nil {
+		t.Fatalf("insert yen transaction: %v", err)
+	}
+	w = get(fmt.Sprintf("/api/v1/transactions?account_id=%d", yen), "text/csv")
+	records, err = csv.NewReader(w.Body).ReadAll()
+	if err != nil || len(records) != 2 || records[1][5] != "1500" || records[1][6] != "JPY" || records[1][14] != "1500" {
+		t.Errorf("yen account: unexpected CSV %v (%v)", records, err)
+	}
+
+	if w := get("/api/v1/transactions", "application/json"); !strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		t.Errorf("expected JSON by default, got %q", w.Header().Get("Content-Type"))
+	}
+	for _, path := range []string{"/api/v1/bills", "/api/v1/invoices", "/api/v1/payouts"} {
+		w := get(path, "text/csv")
+		records, err := csv.NewReader(w.Body).ReadAll()
+		if w.Code != http.StatusOK || err != nil || len(records) != 1 || records[0][0] != "id" {
+			t.Errorf("%s: expected a header-only CSV, got %d %v (%v)", path, w.Code, records, err)
+		}
+	}
+	if w := get("/api/v1/transactions?amount_format=dollars", "text/csv"); w.Code != http.StatusBadRequest {
+		t.Errorf("bad amount_format: expected 400, got %d", w.Code)
+	}
+}
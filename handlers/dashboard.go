@@ -2,24 +2,110 @@ package handlers
 
 import (
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/satheeshds/portal/store"
 )
 
+// dashboardKey identifies one cached dashboard payload.
+type dashboardKey struct {
+	tenant           string
+	includeCancelled bool
+}
+
+type dashboardEntry struct {
+	data    store.DashboardData
+	expires time.Time
+}
+
+// dashboardCache keeps recently computed dashboards per tenant so polling
+// clients do not re-run every aggregate query. Entries live for
+// cfg.DashboardCacheTTL and are dropped whenever the tenant publishes an event.
+type dashboardCache struct {
+	mu      sync.Mutex
+	entries map[dashboardKey]dashboardEntry
+	// gens counts invalidations per tenant, so a dashboard computed while a
+	// write was in flight is not cached after that write cleared the cache.
+	gens map[string]uint64
+}
+
+// dashboards is the process-wide dashboard cache.
+var dashboards = &dashboardCache{entries: map[dashboardKey]dashboardEntry{}, gens: map[string]uint64{}}
+
+// get returns the cached dashboard for key if it has not expired. On a miss
+// it returns the tenant's generation to pass to put.
+func (c *dashboardCache) get(key dashboardKey, now time.Time) (store.DashboardData, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok || !now.Before(e.expires) {
+		return store.DashboardData{}, c.gens[key.tenant], false
+	}
+	return e.data, 0, true
+}
+
+// put caches d for key until expires, unless the tenant was invalidated since
+// gen was read.
+func (c *dashboardCache) put(key dashboardKey, gen uint64, d store.DashboardData, expires time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.gens[key.tenant] != gen {
+		return
+	}
+	c.entries[key] = dashboardEntry{data: d, expires: expires}
+}
+
+// invalidate drops every cached dashboard of tenant.
+func (c *dashboardCache) invalidate(tenant string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gens[tenant]++
+	for key := range c.entries {
+		if key.tenant == tenant {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// onEvent is registered as an event bus listener: any change to a tenant's
+// data may move a dashboard figure.
+func (c *dashboardCache) onEvent(tenant string, _ Event) {
+	c.invalidate(tenant)
+}
+
 // GetDashboard retrieves dashboard summary statistics
 //	@Summary		Get dashboard
-//	@Description	Get totals for accounts, contacts, bills, invoices, and recent transactions.
+//	@Description	Get totals for accounts, contacts, bills, invoices, and recent transactions, plus bill and invoice counts, amounts, and allocations per status.
+//	@Description	reconciliation lists, per account, the count and total of transactions not yet reconciled and the transaction date of the latest reconciled one.
+//	@Description	Results are cached in memory for DASHBOARD_CACHE_TTL (default 30s) and refreshed on any write.
 //	@Tags			dashboard
 //	@Produce		json
+//	@Param			include_cancelled	query	bool	false	"Count cancelled bills and invoices in payables/receivables"
 //	@Success		200	{object}	Response{data=store.DashboardData}
 //	@Router			/dashboard [get]
 //	@Security		BearerAuth
 func GetDashboard(w http.ResponseWriter, r *http.Request) {
+	key := dashboardKey{tenant: getTenant(r), includeCancelled: r.URL.Query().Get("include_cancelled") == "true"}
+	now := time.Now()
+	var gen uint64
+	if cfg.DashboardCacheTTL > 0 {
+		d, g, ok := dashboards.get(key, now)
+		if ok {
+			writeJSON(w, http.StatusOK, d)
+			return
+		}
+		gen = g
+	}
+
 	s := store.New(getDB(r))
-	d, err := s.GetDashboard()
+	d, err := s.GetDashboard(key.includeCancelled)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeInternalError(w, r, err)
 		return
 	}
+	if cfg.DashboardCacheTTL > 0 {
+		dashboards.put(key, gen, d, now.Add(cfg.DashboardCacheTTL))
+	}
 	writeJSON(w, http.StatusOK, d)
 }
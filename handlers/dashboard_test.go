@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// TestGetDashboardCache verifies that the dashboard is served from cache until
+// a write publishes an event, and that a zero TTL disables caching.
+func TestGetDashboardCache(t *testing.T) {
+	r, cleanup := setupTestRouter(t)
+	defer cleanup()
+	r.Get("/api/v1/dashboard", GetDashboard)
+	withTestConfig(t, Config{DashboardCacheTTL: time.Minute})
+	dashboards.invalidate("")
+	t.Cleanup(func() { dashboards.invalidate("") })
+
+	totalAccounts := func() float64 {
+		status, resp := apiRequest(t, r, "GET", "/api/v1/dashboard", nil)
+		if status != http.StatusOK {
+			t.Fatalf("dashboard: status %d, error %v", status, resp["error"])
+		}
+		return resp["data"].(map[string]interface{})["total_accounts"].(float64)
+	}
+
+	if got := totalAccounts(); got != 0 {
+		t.Fatalf("expected 0 accounts, got %v", got)
+	}
+
+	// A write that bypasses the handlers publishes no event, so the cached
+	// dashboard is still served.
+	if _, err := DB.Exec("INSERT INTO accounts (name, type, opening_balance) VALUES ('Direct', 'cash', 0)"); err != nil {
+		t.Fatalf("insert account: %v", err)
+	}
+	if got := totalAccounts(); got != 0 {
+		t.Errorf("expected cached 0 accounts, got %v", got)
+	}
+
+	if status, resp := apiRequest(t, r, "POST", "/api/v1/accounts", map[string]interface{}{
+		"name": "Current", "type": "bank",
+	}); status != http.StatusCreated {
+		t.Fatalf("create account: status %d, error %v", status, resp["error"])
+	}
+	if got := totalAccounts(); got != 2 {
+		t.Errorf("expected 2 accounts after a write, got %v", got)
+	}
+
+	withTestConfig(t, Config{})
+	if _, err := DB.Exec("INSERT INTO accounts (name, type, opening_balance) VALUES ('Direct 2', 'cash', 0)"); err != nil {
+		t.Fatalf("insert account: %v", err)
+	}
+	if got := totalAccounts(); got != 3 {
+		t.Errorf("expected 3 accounts with caching disabled, got %v", got)
+	}
+}
+
+// TestGetDashboardDocumentsByStatus verifies that bills and invoices are
+// counted and summed per status, with their allocations.
+func TestGetDashboardDocumentsByStatus(t *testing.T) {
+	r, cleanup := setupItemsTestRouter(t)
+	defer cleanup()
+	r.Get("/api/v1/dashboard", GetDashboard)
+
+	_, resp := apiRequest(t, r, "POST", "/api/v1/accounts", map[string]interface{}{"name": "Current", "type": "bank"})
+	accID := int(resp["data"].(map[string]interface{})["id"].(float64))
+	for _, b := range []map[string]interface{}{
+		{"bill_number": "B-1", "amount": 100.0, "status": "draft"},
+		{"bill_number": "B-2", "amount": 50.0, "status": "draft"},
+		{"bill_number": "B-3", "amount": 80.0, "status": "cancelled"},
+	} {
+		if status, resp := apiRequest(t, r, "POST", "/api/v1/bills", b); status != http.StatusCreated {
+			t.Fatalf("create bill: status %d, error %v", status, resp["error"])
+		}
+	}
+	invoiceID := createTestInvoice(t, r)
+	_, resp = apiRequest(t, r, "POST", "/api/v1/transactions", map[string]interface{}{
+		"account_id": accID, "type": "income", "amount": 50.0, "transaction_date": "2024-01-10",
+	})
+	txnID := int(resp["data"].(map[string]interface{})["id"].(float64))
+	if status, resp := apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/transactions/%d/links", txnID), map[string]interface{}{
+		"document_type": "invoice", "document_id": invoiceID, "amount": 50.0,
+	}); status != http.StatusCreated {
+		t.Fatalf("create link: status %d, error %v", status, resp["error"])
+	}
+
+	status, resp := apiRequest(t, r, "GET", "/api/v1/dashboard", nil)
+	if status != http.StatusOK {
+		t.Fatalf("dashboard: status %d, error %v", status, resp["error"])
+	}
+	byStatus := resp["data"].(map[string]interface{})["documents_by_status"].(map[string]interface{})
+	bills := byStatus["bills"].(map[string]interface{})
+	if draft := bills["draft"].(map[string]interface{}); draft["count"].(float64) != 2 || draft["amount"].(float64) != 15000 {
+		t.Errorf("unexpected draft bills: %v", draft)
+	}
+	if cancelled := bills["cancelled"].(map[string]interface{}); cancelled["count"].(float64) != 1 || cancelled["amount"].(float64) != 8000 {
+		t.Errorf("unexpected cancelled bills: %v", cancelled)
+	}
+	invoices := byStatus["invoices"].(map[string]interface{})
+	partial, ok := invoices["partial"].(map[string]interface{})
+	if !ok || partial["count"].(float64) != 1 || partial["amount"].(float64) != 20000 || partial["allocated"].(float64) != 5000 {
+		t.Errorf("unexpected invoice breakdown: %v", invoices)
+	}
+}
+
+// TestGetDashboardReconciliation verifies the per-account count and total of
+// unreconciled transactions and the date of the latest reconciled one.
+func TestGetDashboardReconciliation(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+
+	current := createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": "Current", "type": "bank"})
+	createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": "Cash", "type": "cash"})
+	for _, txn := range []map[string]interface{}{
+		{"type": "income", "amount": 100.0, "transaction_date": "2024-01-05", "reference": "R1"},
+		{"type": "expense", "amount": 30.0, "transaction_date": "2024-01-20", "reference": "R2"},
+		{"type": "income", "amount": 40.0, "transaction_date": "2024-02-01"},
+		{"type": "expense", "amount": 5.0, "transaction_date": "2024-02-03"},
+	} {
+		txn["account_id"] = current
+		createResource(t, r, "/api/v1/transactions", txn)
+	}
+	if status, resp := apiRequest(t, r, "POST", "/api/v1/transactions/reconcile-by-reference", map[string]interface{}{
+		"references": []string{"R1", "R2"},
+	}); status != http.StatusOK {
+		t.Fatalf("reconcile: status %d, error %v", status, resp["error"])
+	}
+
+	status, resp := apiRequest(t, r, "GET", "/api/v1/dashboard", nil)
+	if status != http.StatusOK {
+		t.Fatalf("dashboard: status %d, error %v", status, resp["error"])
+	}
+	rows := resp["data"].(map[string]interface{})["reconciliation"].([]interface{})
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 accounts, got %v", rows)
+	}
+	cash, bank := rows[0].(map[string]interface{}), rows[1].(map[string]interface{})
+	if cash["account_name"] != "Cash" || cash["unreconciled"] != 0.0 || cash["last_reconciled"] != nil {
+		t.Errorf("unexpected cash row: %v", cash)
+	}
+	if int(bank["account_id"].(float64)) != current || bank["unreconciled"] != 2.0 ||
+		bank["unreconciled_amount"] != 4500.0 || bank["last_reconciled"] != "2024-01-20" {
+		t.Errorf("unexpected current account row: %v", bank)
+	}
+}
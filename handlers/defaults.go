@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/satheeshds/portal/models"
+	"github.com/satheeshds/portal/store"
+)
+
+// ListDefaults lists configured defaults
+//	@Summary		List defaults
+//	@Description	Get every configured default. default_account.<type> holds the id of the account used when a transaction of that type is created without account_id.
+//	@Description	payout.clearing_account holds the account POST /payouts/{id}/post books through, and payout.category.<entry> the category it tags the gross, discount, commission, taxes, ads, or variance entry with.
+//	@Description	business.gstin holds the GSTIN the business files GST returns under.
+//	@Description	fx_rate.<CURRENCY> holds how many units of the books currency one unit of CURRENCY is worth, for GET /reports/fx.
+//	@Tags			defaults
+//	@Produce		json
+//	@Success		200	{object}	Response{data=[]models.Default}
+//	@Router			/defaults [get]
+//	@Security		BearerAuth
+func ListDefaults(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	defaults, err := s.ListDefaults()
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, defaults)
+}
+
+// SetDefault sets a default
+//	@Summary		Set default
+//	@Description	Set the value of a default, replacing any previous value. For default_account.<type> and payout.clearing_account the value must be the id of an existing account;
+//	@Description	for payout.category.<entry> the id of an existing category, an income one for gross and an expense one for the other entries; for business.gstin a valid GSTIN; for fx_rate.<CURRENCY>, e.g. fx_rate.USD, a positive exchange rate in books-currency units per unit.
+//	@Tags			defaults
+//	@Accept			json
+//	@Produce		json
+//	@Param			key		path		string				true	"Default key, e.g. default_account.expense"
+//	@Param			default	body		models.DefaultInput	true	"Default value"
+//	@Success		200		{object}	Response{data=models.Default}
+//	@Failure		400		{object}	Response{error=string}
+//	@Router			/defaults/{key} [put]
+//	@Security		BearerAuth
+func SetDefault(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	key := chi.URLParam(r, "key")
+	var input models.DefaultInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	accountID, msg := input.Validate(key)
+	if msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	if entry, txnType, ok := models.PayoutCategoryEntry(key); ok {
+		categoryID, _ := strconv.Atoi(input.Value)
+		if !checkCategoryType(w, r, s, &categoryID, txnType, "payout "+entry+" entries") {
+			return
+		}
+	}
+	if accountID != 0 {
+		if _, err := s.GetAccount(accountID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				writeError(w, http.StatusBadRequest, "account not found")
+			} else {
+				writeInternalError(w, r, err)
+			}
+			return
+		}
+	}
+	d, err := s.SetDefault(key, input.Value)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, d)
+}
+
+// DeleteDefault clears a default
+//	@Summary		Delete default
+//	@Description	Clear a default.
+//	@Tags			defaults
+//	@Produce		json
+//	@Param			key	path		string	true	"Default key"
+//	@Success		200	{object}	Response{data=map[string]string}
+//	@Failure		404	{object}	Response{error=string}
+//	@Router			/defaults/{key} [delete]
+//	@Security		BearerAuth
+func DeleteDefault(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	if err := s.DeleteDefault(chi.URLParam(r, "key")); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "default not found")
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": "deleted"})
+}
+
+// applyDefaultAccount fills input.AccountID from the default account for its
+// type when the request omits it. A default naming an account that has since
+// been deleted is ignored, leaving validation to report the missing
+// account_id. It writes a 500 and returns false on a lookup failure.
+func applyDefaultAccount(w http.ResponseWriter, r *http.Request, s *store.Store, input *models.TransactionInput) bool {
+	if input.AccountID != 0 {
+		return true
+	}
+	d, err := s.GetDefault(models.DefaultAccountKey(input.Type))
+	if errors.Is(err, sql.ErrNoRows) {
+		return true
+	}
+	if err != nil {
+		writeInternalError(w, r, err)
+		return false
+	}
+	id, err := strconv.Atoi(d.Value)
+	if err != nil {
+		return true
+	}
+	if _, err := s.GetAccount(id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return true
+		}
+		writeInternalError(w, r, err)
+		return false
+	}
+	input.AccountID = id
+	return true
+}
+
+// payoutCategories returns the categories the payout.category.<entry>
+// defaults name, keyed by entry. A default naming a category that has since
+// been deleted, or changed type, is ignored, leaving that entry untagged. It
+// writes a 500 and returns false on a lookup failure.
+func payoutCategories(w http.ResponseWriter, r *http.Request, s *store.Store) (map[string]int, bool) {
+	defaults, err := s.ListDefaults()
+	if err != nil {
+		writeInternalError(w, r, err)
+		return nil, false
+	}
+	categories := map[string]int{}
+	for _, d := range defaults {
+		entry, txnType, ok := models.PayoutCategoryEntry(d.Key)
+		if !ok {
+			continue
+		}
+		id, err := strconv.Atoi(d.Value)
+		if err != nil {
+			continue
+		}
+		c, err := s.GetCategory(id)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			writeInternalError(w, r, err)
+			return nil, false
+		}
+		if c.Type == txnType {
+			categories[entry] = id
+		}
+	}
+	return categories, true
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+// TestDefaultAccount verifies that a per-type default account is validated
+// when set and fills account_id for transactions created without one.
+func TestDefaultAccount(t *testing.T) {
+	r, cleanup := setupTestRouter(t)
+	defer cleanup()
+	r.Get("/api/v1/defaults", ListDefaults)
+	r.Put("/api/v1/defaults/{key}", SetDefault)
+	r.Delete("/api/v1/defaults/{key}", DeleteDefault)
+
+	_, resp := apiRequest(t, r, "POST", "/api/v1/accounts", map[string]interface{}{"name": "HDFC Current", "type": "bank"})
+	accID := int(resp["data"].(map[string]interface{})["id"].(float64))
+
+	expense := map[string]interface{}{"type": "expense", "amount": 10.0, "transaction_date": "2024-01-15"}
+	if status, _ := apiRequest(t, r, "POST", "/api/v1/transactions", expense); status != http.StatusBadRequest {
+		t.Errorf("no account and no default: expected 400, got %d", status)
+	}
+
+	for _, tc := range []struct {
+		key, value string
+	}{
+		{"default_account.refund", fmt.Sprint(accID)},
+		{"default_account.expense", "abc"},
+		{"default_account.expense", "9999"},
+	} {
+		if status, _ := apiRequest(t, r, "PUT", "/api/v1/defaults/"+tc.key, map[string]interface{}{"value": tc.value}); status != http.StatusBadRequest {
+			t.Errorf("set %s=%s: expected 400, got %d", tc.key, tc.value, status)
+		}
+	}
+
+	status, resp := apiRequest(t, r, "PUT", "/api/v1/defaults/default_account.expense", map[string]interface{}{"value": fmt.Sprint(accID)})
+	if status != http.StatusOK {
+		t.Fatalf("set default: status %d, error %v", status, resp["error"])
+	}
+	// Setting it again replaces the value rather than adding a second row.
+	apiRequest(t, r, "PUT", "/api/v1/defaults/default_account.expense", map[string]interface{}{"value": fmt.Sprint(accID)})
+	_, resp = apiRequest(t, r, "GET", "/api/v1/defaults", nil)
+	if defaults := resp["data"].([]interface{}); len(defaults) != 1 || defaults[0].(map[string]interface{})["key"] != "default_account.expense" {
+		t.Errorf("expected one default_account.expense, got %v", defaults)
+	}
+
+	status, resp = apiRequest(t, r, "POST", "/api/v1/transactions", expense)
+	if status != http.StatusCreated {
+		t.Fatalf("create with default account: status %d, error %v", status, resp["error"])
+	}
+	if got := resp["data"].(map[string]interface{})["account_id"]; got != float64(accID) {
+		t.Errorf("expected account_id %d, got %v", accID, got)
+	}
+	if status, _ := apiRequest(t, r, "POST", "/api/v1/transactions", map[string]interface{}{
+		"type": "income", "amount": 10.0, "transaction_date": "2024-01-15",
+	}); status != http.StatusBadRequest {
+		t.Errorf("income without its own default: expected 400, got %d", status)
+	}
+
+	if status, _ := apiRequest(t, r, "DELETE", "/api/v1/defaults/default_account.expense", nil); status != http.StatusOK {
+		t.Errorf("delete default: expected 200, got %d", status)
+	}
+	if status, _ := apiRequest(t, r, "DELETE", "/api/v1/defaults/default_account.expense", nil); status != http.StatusNotFound {
+		t.Errorf("delete missing default: expected 404, got %d", status)
+	}
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// sseHeartbeatInterval is how often an idle /events stream sends a comment
+// line so proxies and browsers keep the connection open.
+const sseHeartbeatInterval = 25 * time.Second
+
+// Event describes a change to a resource. It is deliberately small: clients
+// use it as a signal to refetch rather than as a copy of the record.
+type Event struct {
+	Type     string `json:"type"`     // created, updated, deleted
+	Resource string `json:"resource"` // transaction, bill, invoice, payout, account, contact
+	ID       int    `json:"id"`
+}
+
+// eventBus fans out events to subscribers of the same tenant. It backs the
+// /events stream and is the hook point for other consumers such as webhooks.
+type eventBus struct {
+	mu   sync.Mutex
+	subs map[chan Event]string // subscriber channel → tenant
+	// listeners are called synchronously for every event of every tenant,
+	// before publish returns, so caches they clear are never stale once the
+	// publishing request has responded.
+	listeners []func(tenant string, e Event)
+}
+
+// events is the process-wide event bus.
+var events = &eventBus{
+	subs:      make(map[chan Event]string),
+	listeners: []func(string, Event){dashboards.onEvent},
+}
+
+// subscribe registers a buffered channel that receives events for tenant.
+func (b *eventBus) subscribe(tenant string) chan Event {
+	ch := make(chan Event, 16)
+	b.mu.Lock()
+	b.subs[ch] = tenant
+	b.mu.Unlock()
+	return ch
+}
+
+// unsubscribe removes and closes a channel returned by subscribe.
+func (b *eventBus) unsubscribe(ch chan Event) {
+	b.mu.Lock()
+	if _, ok := b.subs[ch]; ok {
+		delete(b.subs, ch)
+		close(ch)
+	}
+	b.mu.Unlock()
+}
+
+// publish delivers e to every subscriber of tenant. Slow subscribers whose
+// buffer is full miss the event instead of blocking the publishing request.
+func (b *eventBus) publish(tenant string, e Event) {
+	for _, fn := range b.listeners {
+		fn(tenant, e)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch, t := range b.subs {
+		if t != tenant {
+			continue
+		}
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// publishEvent emits a change event scoped to the tenant of the request.
+func publishEvent(r *http.Request, eventType, resource string, id int) {
+	events.publish(getTenant(r), Event{Type: eventType, Resource: resource, ID: id})
+}
+
+// StreamEvents streams change events as server-sent events
+//	@Summary		Stream change events
+//	@Description	Server-sent event stream that emits a JSON payload {type, resource, id} whenever a transaction, bill, invoice, payout, account, or contact is created, updated, or deleted. A comment heartbeat is sent periodically. Browsers using EventSource may pass the token as the access_token query parameter.
+//	@Tags			events
+//	@Produce		text/event-stream
+//	@Param			access_token	query		string	false	"Bearer token, for clients that cannot set headers"
+//	@Success		200				{object}	Event
+//	@Router			/events [get]
+//	@Security		BearerAuth
+func StreamEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+
+	ch := events.subscribe(getTenant(r))
+	defer events.unsubscribe(ch)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case e := <-ch:
+			data, _ := json.Marshal(e)
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// eventsPath is the /events stream as the router sees it, below any BASE_PATH.
+const eventsPath = "/api/v1/events"
+
+// TokenFromQuery removes an access_token query parameter from the request, so
+// request logs never record the credential, and on the /events stream copies
+// it into the Authorization header when none is set, since EventSource clients
+// cannot send headers. main installs it ahead of the request logger.
+func TokenFromQuery(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if !q.Has("access_token") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		token := q.Get("access_token")
+		q.Del("access_token")
+		r.URL.RawQuery = q.Encode()
+		if u, err := url.ParseRequestURI(r.RequestURI); err == nil {
+			uq := u.Query()
+			uq.Del("access_token")
+			u.RawQuery = uq.Encode()
+			r.RequestURI = u.RequestURI()
+		} else {
+			r.RequestURI = r.URL.RequestURI()
+		}
+		if token != "" && r.URL.Path == eventsPath && r.Header.Get("Authorization") == "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestStreamEventsReceivesChange verifies that a subscriber to /events gets a
+// change event when a payout is created, and that it is unsubscribed on disconnect.
+func TestStreamEventsReceivesChange(t *testing.T) {
+	r, cleanup := setupTestRouter(t)
+	defer cleanup()
+	r.Get("/api/v1/events", StreamEvents)
+
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", srv.URL+"/api/v1/events", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("open stream: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected text/event-stream, got %q", ct)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	// The first comment confirms the subscription is registered.
+	if line, err := reader.ReadString('\n'); err != nil || !strings.HasPrefix(line, ":") {
+		t.Fatalf("expected connect comment, got %q (%v)", line, err)
+	}
+
+	status, body := apiRequest(t, r, "POST", "/api/v1/payouts", map[string]interface{}{
+		"outlet_name": "Test Restaurant", "create_outlet": true, "platform": "swiggy", "final_payout_amt": 100.0,
+	})
+	if status != http.StatusCreated {
+		t.Fatalf("create payout: status %d, error %v", status, body["error"])
+	}
+	payoutID := int(body["data"].(map[string]interface{})["id"].(float64))
+
+	var got Event
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read stream: %v", err)
+		}
+		if data, ok := strings.CutPrefix(line, "data: "); ok {
+			if err := json.Unmarshal([]byte(data), &got); err != nil {
+				t.Fatalf("decode event: %v", err)
+			}
+			break
+		}
+	}
+	if got != (Event{Type: "created", Resource: "payout", ID: payoutID}) {
+		t.Errorf("unexpected event: %+v", got)
+	}
+
+	cancel()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		events.mu.Lock()
+		n := len(events.subs)
+		events.mu.Unlock()
+		if n == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected subscriber to be removed after disconnect, %d remain", n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestEventBusScopesByTenant verifies that events are only delivered to
+// subscribers of the same tenant.
+func TestEventBusScopesByTenant(t *testing.T) {
+	bus := &eventBus{subs: make(map[chan Event]string)}
+	a := bus.subscribe("tenant-a")
+	b := bus.subscribe("tenant-b")
+	defer bus.unsubscribe(a)
+	defer bus.unsubscribe(b)
+
+	bus.publish("tenant-a", Event{Type: "updated", Resource: "bill", ID: 1})
+
+	select {
+	case e := <-a:
+		if e.ID != 1 {
+			t.Errorf("unexpected event for tenant-a: %+v", e)
+		}
+	default:
+		t.Error("expected tenant-a to receive the event")
+	}
+	select {
+	case e := <-b:
+		t.Errorf("tenant-b should not receive tenant-a events, got %+v", e)
+	default:
+	}
+}
+
+// TestTokenFromQuery verifies that an access_token query parameter is removed
+// from the URL before anything logs it, and becomes the Authorization header
+// only on the /events stream.
+func TestTokenFromQuery(t *testing.T) {
+	var got *http.Request
+	h := TokenFromQuery(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { got = r }))
+
+	for path, wantAuth := range map[string]string{"/api/v1/events": "Bearer secret", "/api/v1/accounts": ""} {
+		req := httptest.NewRequest("GET", path+"?since=5&access_token=secret", nil)
+		h.ServeHTTP(httptest.NewRecorder(), req)
+		if strings.Contains(got.RequestURI, "secret") || strings.Contains(got.URL.String(), "secret") {
+			t.Errorf("%s: token left in URL: RequestURI %q, URL %q", path, got.RequestURI, got.URL)
+		}
+		if got.URL.Query().Get("since") != "5" {
+			t.Errorf("%s: other query parameters should be kept, got %q", path, got.URL.RawQuery)
+		}
+		if auth := got.Header.Get("Authorization"); auth != wantAuth {
+			t.Errorf("%s: Authorization = %q, want %q", path, auth, wantAuth)
+		}
+	}
+}
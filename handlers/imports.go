@@ -0,0 +1,315 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/satheeshds/portal/importer"
+	"github.com/satheeshds/portal/models"
+	"github.com/satheeshds/portal/store"
+)
+
+// maxImportSize caps the statement file accepted by the import endpoint.
+const maxImportSize = 10 << 20
+
+// ImportResult is an alias for store.ImportResult kept here for Swagger doc references.
+type ImportResult = store.ImportResult
+
+// ImportAccountTransactions imports a bank statement file into an account
+//	@Summary		Import bank statement
+//	@Description	Import transactions from a bank statement file, sent as the request body or as the "file" field of a multipart form. Credits become income and debits expense. Entries are deduplicated by their bank id (OFX FITID) stored as external_id, so re-importing the same file is safe. Entries dated in a closed period are reported as errors.
+//	@Description	For CSV, the header row and its date, debit, credit, amount, description, and reference columns are detected from common names (e.g. "Withdrawal Amt." is debit); mapping, a JSON object of field to header name sent as a query parameter or form field, overrides the detection. The columns used are returned in columns. CSV entries are deduplicated by an id derived from their contents.
+//	@Tags			accounts
+//	@Accept			application/x-ofx
+//	@Accept			text/csv
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Param			id		path		int		true	"Account ID"
+//	@Param			format	query		string	true	"File format: ofx (or qfx) or csv"
+//	@Param			mapping	query		string	false	"CSV column overrides: a JSON object of field to header name"
+//	@Success		200		{object}	Response{data=ImportResult}
+//	@Failure		400		{object}	Response{error=string}
+//	@Failure		404		{object}	Response{error=string}
+//	@Router			/accounts/{id}/import [post]
+//	@Security		BearerAuth
+func ImportAccountTransactions(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format != "ofx" && format != "qfx" && format != "csv" {
+		writeError(w, http.StatusBadRequest, "format must be ofx or csv")
+		return
+	}
+	account, err := s.GetAccount(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "account not found")
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+
+	file, err := importFile(w, r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	defer file.Close()
+
+	var lines []models.StatementLine
+	var lineErrs []models.ImportError
+	var columns map[string]string
+	source := "ofx"
+	if format == "csv" {
+		source = "csv"
+		var mapping map[string]string
+		if v := r.FormValue("mapping"); v != "" {
+			if err := json.Unmarshal([]byte(v), &mapping); err != nil {
+				writeError(w, http.StatusBadRequest, "mapping must be a JSON object of field to column name")
+				return
+			}
+		}
+		lines, lineErrs, columns, err = importer.ParseCSV(file, mapping, account.Currency)
+	} else {
+		lines, lineErrs, err = importer.ParseOFX(file, account.Currency)
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	closed, err := s.ClosedThrough()
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	if through := closed.String(); through != "" {
+		open := lines[:0]
+		for _, l := range lines {
+			if l.Date <= through {
+				lineErrs = append(lineErrs, models.ImportError{Row: l.Row, ExternalID: l.ExternalID,
+					Error: fmt.Sprintf("date %s falls in a closed period (closed through %s)", l.Date, through)})
+				continue
+			}
+			open = append(open, l)
+		}
+		lines = open
+	}
+
+	result, err := s.ImportStatement(id, source, lines)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	result.Errors = append(result.Errors, lineErrs...)
+	result.Columns = columns
+	for _, txnID := range result.TransactionIDs {
+		publishEvent(r, "created", "transaction", txnID)
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// importFile returns the uploaded statement: the "file" field of a multipart
+// form, or otherwise the raw request body.
+func importFile(w http.ResponseWriter, r *http.Request) (io.ReadCloser, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		f, _, err := r.FormFile("file")
+		if err != nil {
+			return nil, errors.New("file is required")
+		}
+		return f, nil
+	}
+	return r.Body, nil
+}
+
+// ContactImportResult is an alias for store.ContactImportResult kept here for Swagger doc references.
+type ContactImportResult = store.ContactImportResult
+
+// ImportContacts imports vendors and customers from a CSV file
+//	@Summary		Import contacts
+//	@Description	Create contacts from a CSV file, sent as the request body or as the "file" field of a multipart form. The header row must have a name column;
+//	@Description	type, email, phone, gstin, and pan columns are optional and detected from common names (e.g. "Mobile No" is phone). Each row is validated as
+//	@Description	for POST /contacts. A row matching an existing contact by name (ignoring case) and type is skipped, or with duplicates=merge fills in the
+//	@Description	fields the existing contact lacks. All rows are written in one transaction; with all_or_nothing=true a file with any invalid row imports
+//	@Description	nothing and gets 422 listing the invalid rows, otherwise invalid rows are reported and the rest imported.
+//	@Tags			contacts
+//	@Accept			text/csv
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Param			type			query		string	false	"Type for rows without one: vendor or customer"
+//	@Param			duplicates		query		string	false	"skip (default) or merge"
+//	@Param			all_or_nothing	query		bool	false	"Import nothing if any row is invalid"
+//	@Success		200				{object}	Response{data=ContactImportResult}
+//	@Failure		400				{object}	Response{error=string}
+//	@Failure		422				{object}	Response{data=ContactImportResult,error=string}
+//	@Router			/contacts/import [post]
+//	@Security		BearerAuth
+func ImportContacts(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	q := r.URL.Query()
+	duplicates := strings.ToLower(q.Get("duplicates"))
+	if duplicates != "" && duplicates != "skip" && duplicates != "merge" {
+		writeError(w, http.StatusBadRequest, "duplicates must be skip or merge")
+		return
+	}
+
+	file, err := importFile(w, r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	defer file.Close()
+	lines, err := importer.ParseContactsCSV(file, strings.ToLower(strings.TrimSpace(q.Get("type"))))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Rows are reported in file order, so invalid rows keep their place
+	// among the imported ones.
+	rows := make([]store.ContactImportRow, len(lines))
+	var valid []models.ContactLine
+	var validAt []int
+	invalid := 0
+	for i := range lines {
+		line := &lines[i]
+		if msg := line.Input.Validate(); msg != "" {
+			rows[i] = store.ContactImportRow{Row: line.Row, Name: line.Input.Name, Status: "error", Error: msg}
+			invalid++
+			continue
+		}
+		valid = append(valid, *line)
+		validAt = append(validAt, i)
+	}
+
+	result := ContactImportResult{Rows: []store.ContactImportRow{}}
+	if invalid > 0 && q.Get("all_or_nothing") == "true" {
+		for _, row := range rows {
+			if row.Status == "error" {
+				result.Add(row)
+			}
+		}
+		writeErrorData(w, http.StatusUnprocessableEntity,
+			fmt.Sprintf("%d row(s) are invalid; nothing was imported", invalid), result)
+		return
+	}
+
+	imported, err := s.ImportContacts(valid, duplicates == "merge")
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	for j, row := range imported {
+		rows[validAt[j]] = row
+	}
+	for _, row := range rows {
+		result.Add(row)
+		switch row.Status {
+		case "created":
+			publishEvent(r, "created", "contact", *row.ContactID)
+		case "merged":
+			publishEvent(r, "updated", "contact", *row.ContactID)
+		}
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// payoutReportParsers parse the settlement report of each platform whose
+// reports can be imported.
+var payoutReportParsers = map[string]func(io.Reader, string) ([]models.PayoutLine, []models.ImportError, error){
+	"swiggy": importer.ParseSwiggyPayouts,
+	"zomato": importer.ParseZomatoPayouts,
+}
+
+// PayoutImportResult is an alias for store.PayoutImportResult kept here for Swagger doc references.
+type PayoutImportResult = store.PayoutImportResult
+
+// ImportPayouts imports payouts from a platform settlement report
+//	@Summary		Import payouts
+//	@Description	Create payouts from a platform settlement report, sent as the request body or as the "file" field of a multipart form, as CSV or XLSX (told apart by content).
+//	@Description	For swiggy, the weekly settlement export of the partner portal: the outlet, payout period, settlement date, orders, gross sales, restaurant discount,
+//	@Description	commission, TCS and TDS (added up), ads, net payout, and UTR columns are detected from common names. For zomato, the payout annexure, whose order rows
+//	@Description	are merged into one payout per outlet per payout cycle (or payout date and UTR), dated by the first and last order without cycle columns; a payout with
+//	@Description	an invalid order row is not imported. outlet_name names the outlet of a report without an outlet column. Outlets are found by name, ignoring case;
+//	@Description	a row naming an unknown outlet is reported as an error unless create_outlets is true, in which case the outlet is registered. A payout already
+//	@Description	recorded on the platform, matched by UTR or without one by outlet, period, and settlement date, is skipped, so re-importing a report is safe.
+//	@Description	Invalid rows are reported and the rest imported.
+//	@Tags			payouts
+//	@Accept			text/csv
+//	@Accept			application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Param			platform		query		string	true	"Platform of the report: swiggy or zomato"
+//	@Param			outlet_name		query		string	false	"Outlet for a report without an outlet column"
+//	@Param			create_outlets	query		bool	false	"Register outlets not already known"
+//	@Success		200				{object}	Response{data=PayoutImportResult}
+//	@Failure		400				{object}	Response{error=string}
+//	@Router			/payouts/import [post]
+//	@Security		BearerAuth
+func ImportPayouts(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	parse, ok := payoutReportParsers[strings.ToLower(r.URL.Query().Get("platform"))]
+	if !ok {
+		writeError(w, http.StatusBadRequest, "platform must be swiggy or zomato")
+		return
+	}
+
+	file, err := importFile(w, r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	defer file.Close()
+	lines, lineErrs, err := parse(file, r.FormValue("outlet_name"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result := PayoutImportResult{Rows: []store.PayoutImportRow{}}
+	rows := make([]store.PayoutImportRow, 0, len(lines)+len(lineErrs))
+	for _, e := range lineErrs {
+		rows = append(rows, store.PayoutImportRow{Row: e.Row, Status: "error", Error: e.Error})
+	}
+	var valid []models.PayoutLine
+	createOutlets := r.FormValue("create_outlets") == "true"
+	for i := range lines {
+		line := &lines[i]
+		line.Input.CreateOutlet = createOutlets
+		if msg := line.Input.Validate(); msg != "" {
+			rows = append(rows, store.PayoutImportRow{Row: line.Row, OutletName: line.Input.OutletName,
+				UtrNumber: line.Input.UtrNumber, Status: "error", Error: msg})
+			continue
+		}
+		valid = append(valid, *line)
+	}
+
+	imported, err := s.ImportPayouts(valid)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	rows = append(rows, imported...)
+	// Rows are reported in file order, so invalid rows keep their place
+	// among the imported ones.
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].Row < rows[j].Row })
+	for _, row := range rows {
+		result.Add(row)
+		if row.Status == "created" {
+			publishEvent(r, "created", "payout", *row.PayoutID)
+		}
+	}
+	writeJSON(w, http.StatusOK, result)
+}
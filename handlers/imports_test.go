@@ -0,0 +1,322 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+const testOFX = `OFXHEADER:100
+<OFX><BANKMSGSRSV1><STMTTRNRS><STMTRS><BANKTRANLIST>
+<STMTTRN><TRNTYPE>CREDIT<DTPOSTED>20240115<TRNAMT>1500.00<FITID>F1<NAME>Swiggy settlement</STMTTRN>
+<STMTTRN><TRNTYPE>DEBIT<DTPOSTED>20240116<TRNAMT>-250.00<FITID>F2<NAME>Electricity</STMTTRN>
+<STMTTRN><TRNTYPE>DEBIT<DTPOSTED>bad<TRNAMT>-1.00<FITID>F3</STMTTRN>
+</BANKTRANLIST></STMTRS></STMTTRNRS></BANKMSGSRSV1></OFX>`
+
+// TestImportOFXIsIdempotent verifies that an OFX import creates income and
+// expense transactions, reports bad entries, and skips them on re-import.
+func TestImportOFXIsIdempotent(t *testing.T) {
+	r, cleanup := setupTestRouter(t)
+	defer cleanup()
+	r.Post("/api/v1/accounts/{id}/import", ImportAccountTransactions)
+	r.Get("/api/v1/transactions/{id}", GetTransaction)
+
+	_, resp := apiRequest(t, r, "POST", "/api/v1/accounts", map[string]interface{}{
+		"name": "Bank Account", "type": "bank", "opening_balance": 0,
+	})
+	accID := int(resp["data"].(map[string]interface{})["id"].(float64))
+
+	importOFX := func() ImportResult {
+		t.Helper()
+		req := httptest.NewRequest("POST", fmt.Sprintf("/api/v1/accounts/%d/import?format=ofx", accID), strings.NewReader(testOFX))
+		req.Header.Set("Content-Type", "application/x-ofx")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("import: status %d, body %s", w.Code, w.Body.String())
+		}
+		var body struct {
+			Data ImportResult `json:"data"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return body.Data
+	}
+
+	first := importOFX()
+	if first.Imported != 2 || first.Skipped != 0 || len(first.Errors) != 1 || first.Errors[0].ExternalID != "F3" {
+		t.Fatalf("unexpected first import result: %+v", first)
+	}
+	_, resp = apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/transactions/%d", first.TransactionIDs[1]), nil)
+	txn := resp["data"].(map[string]interface{})
+	if txn["type"] != "expense" || txn["amount"].(float64) != 25000 || txn["transaction_date"] != "2024-01-16" {
+		t.Errorf("unexpected imported debit: %v", txn)
+	}
+
+	second := importOFX()
+	if second.Imported != 0 || second.Skipped != 2 {
+		t.Errorf("expected re-import to skip both entries, got %+v", second)
+	}
+
+	if status, _ := apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/accounts/%d/import?format=xls", accID), nil); status != http.StatusBadRequest {
+		t.Errorf("unsupported format: expected 400, got %d", status)
+	}
+	if status, _ := apiRequest(t, r, "POST", "/api/v1/accounts/9999/import?format=ofx", nil); status != http.StatusNotFound {
+		t.Errorf("unknown account: expected 404, got %d", status)
+	}
+}
+
+// TestImportCSVDetectsColumns verifies that a CSV import maps bank-specific
+// headers, reports the columns it used, honours a mapping override, and skips
+// rows already imported.
+func TestImportCSVDetectsColumns(t *testing.T) {
+	r, cleanup := setupTestRouter(t)
+	defer cleanup()
+	r.Post("/api/v1/accounts/{id}/import", ImportAccountTransactions)
+
+	_, resp := apiRequest(t, r, "POST", "/api/v1/accounts", map[string]interface{}{"name": "Bank Account", "type": "bank"})
+	accID := int(resp["data"].(map[string]interface{})["id"].(float64))
+
+	importCSV := func(query, data string) (int, map[string]interface{}) {
+		t.Helper()
+		req := httptest.NewRequest("POST", fmt.Sprintf("/api/v1/accounts/%d/import?format=csv%s", accID, query), strings.NewReader(data))
+		req.Header.Set("Content-Type", "text/csv")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var body map[string]interface{}
+		_ = json.NewDecoder(w.Body).Decode(&body)
+		return w.Code, body
+	}
+
+	const statement = "Txn Date,Particulars,Withdrawal Amt.,Deposit Amt.\n15-01-2024,Swiggy settlement,,1500.00\n16-01-2024,Electricity,250.00,\n"
+	status, body := importCSV("", statement)
+	if status != http.StatusOK {
+		t.Fatalf("import: status %d, error %v", status, body["error"])
+	}
+	data := body["data"].(map[string]interface{})
+	if data["imported"].(float64) != 2 {
+		t.Errorf("expected 2 imported, got %v", data)
+	}
+	columns := data["columns"].(map[string]interface{})
+	if columns["date"] != "Txn Date" || columns["debit"] != "Withdrawal Amt." || columns["credit"] != "Deposit Amt." {
+		t.Errorf("unexpected detected columns %v", columns)
+	}
+
+	status, body = importCSV("", statement)
+	if data := body["data"].(map[string]interface{}); status != http.StatusOK || data["skipped"].(float64) != 2 {
+		t.Errorf("re-import: expected both rows skipped, got status %d, %v", status, body)
+	}
+
+	status, body = importCSV("&mapping="+url.QueryEscape(`{"credit":"Received"}`), "Date,Received\n2024-02-01,99.99\n")
+	if status != http.StatusOK || body["data"].(map[string]interface{})["imported"].(float64) != 1 {
+		t.Errorf("mapped import: status %d, %v", status, body)
+	}
+	if status, _ := importCSV("&mapping=nonsense", statement); status != http.StatusBadRequest {
+		t.Errorf("invalid mapping: expected 400, got %d", status)
+	}
+	if status, _ := importCSV("", "a,b\n1,2\n"); status != http.StatusBadRequest {
+		t.Errorf("undetectable header: expected 400, got %d", status)
+	}
+}
+
+// TestImportContacts verifies that a contact import validates each row,
+// skips or merges contacts matching by name and type, and with
+// all_or_nothing imports nothing when any row is invalid.
+func TestImportContacts(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+
+	existing := createResource(t, r, "/api/v1/contacts", map[string]interface{}{"name": "Fresh Farms", "type": "vendor"})
+
+	importCSV := func(query, csv string) (int, ContactImportResult, string) {
+		t.Helper()
+		req := httptest.NewRequest("POST", "/api/v1/contacts/import"+query, strings.NewReader(csv))
+		req.Header.Set("Content-Type", "text/csv")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var body struct {
+			Data  ContactImportResult `json:"data"`
+			Error string              `json:"error"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return w.Code, body.Data, body.Error
+	}
+
+	const contacts = "Vendor Name,Type,Mobile No,GSTIN\n" +
+		" fresh farms ,vendor,98450 12345,\n" +
+		"Acme Technologies,customer,,27AAPFU0939F1ZV\n" +
+		"Bad GSTIN Ltd,customer,,27AAPFU0939F1ZX\n" +
+		"Metro Packaging,,,\n" +
+		"acme technologies,customer,,\n"
+
+	status, result, errMsg := importCSV("?type=vendor&all_or_nothing=true", contacts)
+	if status != http.StatusUnprocessableEntity || result.Errors != 1 || len(result.Rows) != 1 || result.Rows[0].Row != 4 {
+		t.Fatalf("all_or_nothing: status %d, error %q, result %+v", status, errMsg, result)
+	}
+	if _, resp := apiRequest(t, r, "GET", "/api/v1/contacts?search=Metro", nil); len(resp["data"].([]interface{})) != 0 {
+		t.Errorf("all_or_nothing: expected nothing imported, got %v", resp["data"])
+	}
+
+	status, result, errMsg = importCSV("?type=vendor", contacts)
+	if status != http.StatusOK {
+		t.Fatalf("import: status %d, error %q", status, errMsg)
+	}
+	if result.Created != 2 || result.Skipped != 2 || result.Errors != 1 || len(result.Rows) != 5 {
+		t.Fatalf("unexpected import result: %+v", result)
+	}
+	wantStatus := []string{"skipped", "created", "error", "created", "skipped"}
+	for i, row := range result.Rows {
+		if row.Status != wantStatus[i] || row.Row != i+2 {
+			t.Errorf("row %d: got %+v, want status %s", i, row, wantStatus[i])
+		}
+	}
+	if id := result.Rows[0].ContactID; id == nil || *id != existing {
+		t.Errorf("duplicate row: expected contact %d, got %v", existing, id)
+	}
+	if !strings.HasPrefix(result.Rows[2].Error, "gstin:") {
+		t.Errorf("invalid row: unexpected error %q", result.Rows[2].Error)
+	}
+
+	status, result, _ = importCSV("?duplicates=merge", contacts)
+	if status != http.StatusOK || result.Merged != 1 || result.Skipped != 2 || result.Created != 0 || result.Errors != 2 {
+		t.Fatalf("merge: status %d, result %+v", status, result)
+	}
+	_, resp := apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/contacts/%d", existing), nil)
+	if phone := resp["data"].(map[string]interface{})["phone"]; phone != "98450 12345" {
+		t.Errorf("merge: expected phone filled in, got %v", phone)
+	}
+
+	if status, _, _ := importCSV("", "Email\nx@example.com\n"); status != http.StatusBadRequest {
+		t.Errorf("no name column: expected 400, got %d", status)
+	}
+	if status, _, _ := importCSV("?duplicates=bogus", contacts); status != http.StatusBadRequest {
+		t.Errorf("bad duplicates: expected 400, got %d", status)
+	}
+}
+
+// TestImportSwiggyPayouts verifies that a Swiggy settlement report creates
+// payouts under their outlets, registering unknown outlets only when asked,
+// reports invalid rows in file order, and skips rows already imported.
+func TestImportSwiggyPayouts(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+
+	const report = "Restaurant Name,Payout Period,Settlement Date,Total Orders,Item Total,Swiggy Commission,TCS,Net Payout,UTR No\n" +
+		"Koramangala,01-Jan-2024 - 07-Jan-2024,10/01/2024,120,5000,-900,-25,4075,UTR0001\n" +
+		"Koramangala,08-Jan-2024 - 14-Jan-2024,17/01/2024,80,3000,-540,-15,2445,\n" +
+		"Indiranagar,08-Jan-2024 - 14-Jan-2024,2024-02-30,10,100,0,0,100,UTR0003\n"
+
+	importReport := func(query string) (int, PayoutImportResult, string) {
+		t.Helper()
+		req := httptest.NewRequest("POST", "/api/v1/payouts/import"+query, strings.NewReader(report))
+		req.Header.Set("Content-Type", "text/csv")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var body struct {
+			Data  PayoutImportResult `json:"data"`
+			Error string             `json:"error"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return w.Code, body.Data, body.Error
+	}
+
+	status, unknown, errMsg := importReport("?platform=swiggy")
+	if status != http.StatusOK {
+		t.Fatalf("import: status %d, error %q", status, errMsg)
+	}
+	if unknown.Created != 0 || unknown.Errors != 3 || !strings.Contains(unknown.Rows[0].Error, "create_outlets") {
+		t.Fatalf("expected unknown outlets to be reported, got %+v", unknown)
+	}
+	_, resp := apiRequest(t, r, "GET", "/api/v1/outlets", nil)
+	if outlets := resp["data"].([]interface{}); len(outlets) != 0 {
+		t.Errorf("expected no outlets registered without create_outlets, got %v", outlets)
+	}
+
+	status, result, errMsg := importReport("?platform=Swiggy&create_outlets=true")
+	if status != http.StatusOK {
+		t.Fatalf("import: status %d, error %q", status, errMsg)
+	}
+	if result.Created != 2 || result.Errors != 1 || len(result.Rows) != 3 || result.Rows[2].Status != "error" || result.Rows[2].Row != 4 {
+		t.Fatalf("unexpected import result: %+v", result)
+	}
+	_, resp = apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/payouts/%d", *result.Rows[0].PayoutID), nil)
+	p := resp["data"].(map[string]interface{})
+	if p["platform"] != "swiggy" || p["outlet_name"] != "Koramangala" || p["outlet_id"] == nil || p["utr_number"] != "UTR0001" ||
+		p["period_end"] != "2024-01-07" || p["platform_commission_amt"].(float64) != 90000 || p["final_payout_amt"].(float64) != 407500 {
+		t.Errorf("unexpected imported payout: %v", p)
+	}
+
+	_, again, _ := importReport("?platform=swiggy")
+	if again.Created != 0 || again.Skipped != 2 || *again.Rows[1].PayoutID != *result.Rows[1].PayoutID {
+		t.Errorf("expected re-import to skip both payouts, got %+v", again)
+	}
+
+	if status, _, _ := importReport("?platform=ubereats"); status != http.StatusBadRequest {
+		t.Errorf("unknown platform: expected 400, got %d", status)
+	}
+}
+
+// TestImportZomatoPayouts verifies that a multi-outlet Zomato annexure
+// creates one payout per outlet per cycle, reporting outlets that are not
+// registered unless create_outlets is set.
+func TestImportZomatoPayouts(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+
+	const annexure = "Res. name,Order date,Subtotal (items total),Commission value,Order level Payout,Payout date,UTR no.\n" +
+		"Koramangala,02/01/2024,500,-90,410,10/01/2024,ZUTR1\n" +
+		"Indiranagar,03/01/2024,300,-54,246,10/01/2024,ZUTR2\n" +
+		"Koramangala,06/01/2024,200,-36,164,10/01/2024,ZUTR1\n"
+	importAnnexure := func(query string) PayoutImportResult {
+		t.Helper()
+		req := httptest.NewRequest("POST", "/api/v1/payouts/import?platform=zomato"+query, strings.NewReader(annexure))
+		req.Header.Set("Content-Type", "text/csv")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var body struct {
+			Data PayoutImportResult `json:"data"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&body); err != nil || w.Code != http.StatusOK {
+			t.Fatalf("import: status %d, body %s", w.Code, w.Body.String())
+		}
+		return body.Data
+	}
+
+	createResource(t, r, "/api/v1/outlets", map[string]interface{}{"name": "Koramangala"})
+	known := importAnnexure("")
+	if known.Created != 1 || known.Errors != 1 || len(known.Rows) != 2 {
+		t.Fatalf("expected Koramangala imported and Indiranagar reported, got %+v", known)
+	}
+	for _, row := range known.Rows {
+		if row.OutletName == "Indiranagar" && (row.Status != "error" || !strings.Contains(row.Error, "not found")) {
+			t.Errorf("expected unknown outlet error for Indiranagar, got %+v", row)
+		}
+	}
+
+	all := importAnnexure("&create_outlets=true")
+	if all.Created != 1 || all.Skipped != 1 || len(all.Rows) != 2 {
+		t.Fatalf("expected Indiranagar created and Koramangala skipped, got %+v", all)
+	}
+
+	_, resp := apiRequest(t, r, "GET", "/api/v1/payouts?platform=zomato", nil)
+	payouts := resp["data"].([]interface{})
+	if len(payouts) != 2 {
+		t.Fatalf("expected 2 zomato payouts, got %v", payouts)
+	}
+	for _, item := range payouts {
+		p := item.(map[string]interface{})
+		if p["outlet_name"] == "Koramangala" && (p["total_orders"].(float64) != 2 || p["final_payout_amt"].(float64) != 57400 ||
+			p["period_start"] != "2024-01-02" || p["period_end"] != "2024-01-06") {
+			t.Errorf("unexpected Koramangala payout: %v", p)
+		}
+	}
+}
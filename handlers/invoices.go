@@ -4,10 +4,10 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
-	"strconv"
+	"time"
 
-	"github.com/go-chi/chi/v5"
 	"github.com/satheeshds/portal/models"
 	"github.com/satheeshds/portal/store"
 )
@@ -15,27 +15,37 @@ import (
 // ListInvoices lists all invoices
 //	@Summary		List invoices
 //	@Description	Get a list of all receivable invoices, with current status and allocation info.
+//	@Description	Send Accept: text/csv for the same list as CSV.
 //	@Tags			invoices
 //	@Produce		json
-//	@Param			contact_id	query		int		false	"Filter by contact (customer)"
-//	@Param			search		query		string	false	"Search by invoice number, notes, or customer name"
-//	@Success		200			{object}	Response{data=[]models.Invoice}
+//	@Produce		text/csv
+//	@Param			contact_id			query		int		false	"Filter by contact (customer)"
+//	@Param			from				query		string	false	"Filter by issue date from (YYYY-MM-DD)"
+//	@Param			to					query		string	false	"Filter by issue date to (YYYY-MM-DD)"
+//	@Param			search				query		string	false	"Search by invoice number, notes, or customer name"
+//	@Param			include_cancelled	query		bool	false	"Include cancelled invoices (excluded by default)"
+//	@Param			amount_format		query		string	false	"CSV amounts as rupees (default), rupees_grouped, or paise"
+//	@Success		200					{object}	Response{data=[]models.Invoice}
+//	@Failure		400					{object}	Response{error=string}
 //	@Router			/invoices [get]
 //	@Security		BearerAuth
 func ListInvoices(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	invoices, err := s.ListInvoices(
-		r.URL.Query().Get("status"),
-		r.URL.Query().Get("contact_id"),
-		r.URL.Query().Get("from"),
-		r.URL.Query().Get("to"),
-		r.URL.Query().Get("search"),
-	)
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+	q, ok := listQuery(w, r, []string{"contact_id"}, []string{"from", "to"})
+	if !ok {
 		return
 	}
-	writeJSON(w, http.StatusOK, invoices)
+	streamList(w, r, "invoices.csv", invoiceCSVHeader, invoiceCSVRecord, func(yield func(models.Invoice) error) error {
+		return s.EachInvoice(
+			q.Get("status"),
+			q.Get("contact_id"),
+			q.Get("from"),
+			q.Get("to"),
+			q.Get("search"),
+			q.Get("include_cancelled") == "true",
+			yield,
+		)
+	})
 }
 
 // GetInvoice retrieves a single invoice by ID
@@ -45,18 +55,22 @@ func ListInvoices(w http.ResponseWriter, r *http.Request) {
 //	@Produce		json
 //	@Param			id	path		int	true	"Invoice ID"
 //	@Success		200	{object}	Response{data=models.Invoice}
-//	@Failure		404	{object}	Response{error=string}
+//	@Failure		400	{object}	Response{error=string}
+//	@Failure		404	{object}	Response{error=string,code=string,resource=string}
 //	@Router			/invoices/{id} [get]
 //	@Security		BearerAuth
 func GetInvoice(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
 	inv, err := s.GetInvoice(id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			writeError(w, http.StatusNotFound, "invoice not found")
+			writeNotFound(w, "invoice")
 		} else {
-			writeError(w, http.StatusInternalServerError, err.Error())
+			writeInternalError(w, r, err)
 		}
 		return
 	}
@@ -65,13 +79,19 @@ func GetInvoice(w http.ResponseWriter, r *http.Request) {
 
 // CreateInvoice creates a new invoice
 //	@Summary		Create invoice
-//	@Description	Create a new receivable invoice.
+//	@Description	Create a new receivable invoice. When the invoice would take the customer's outstanding balance above its
+//	@Description	credit_limit it is created with a warning and the balance and limit under credit, or, with
+//	@Description	CREDIT_LIMIT_BLOCK=true, refused with 422.
+//	@Description	An invoice with items takes its amount from them: each item's amount (quantity times unit_price when omitted) includes its GST.
+//	@Description	When an item has its own tax_rate, items without one take the invoice's, tax_amount is the sum of the items' GST, and the
+//	@Description	invoice's tax_rate is their common rate, or null when they differ; otherwise the invoice's tax_rate applies to the total.
 //	@Tags			invoices
 //	@Accept			json
 //	@Produce		json
 //	@Param			invoice	body		models.InvoiceInput	true	"Invoice contents"
-//	@Success		201		{object}	Response{data=models.Invoice}
+//	@Success		201		{object}	Response{data=InvoiceCreateResult}
 //	@Failure		400		{object}	Response{error=string}
+//	@Failure		422		{object}	Response{error=string}
 //	@Router			/invoices [post]
 //	@Security		BearerAuth
 func CreateInvoice(w http.ResponseWriter, r *http.Request) {
@@ -85,17 +105,75 @@ func CreateInvoice(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, msg)
 		return
 	}
-	inv, err := s.CreateInvoice(input)
+	if !checkContactType(w, r, s, input.ContactID, "customer", "invoices") {
+		return
+	}
+	credit, err := creditLimitCheck(s, input)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeInternalError(w, r, err)
 		return
 	}
-	writeJSON(w, http.StatusCreated, inv)
+	result := InvoiceCreateResult{Credit: credit}
+	if credit != nil {
+		msg := credit.message("invoice", *input.ContactID)
+		if cfg.CreditLimitBlock {
+			writeError(w, http.StatusUnprocessableEntity, msg)
+			return
+		}
+		result.Warnings = append(result.Warnings, msg)
+	}
+	result.Invoice, err = s.CreateInvoice(input)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	publishEvent(r, "created", "invoice", result.ID)
+	writeJSON(w, http.StatusCreated, result)
+}
+
+// InvoiceCreateResult is a created invoice plus any warnings, such as the
+// customer going over its credit limit.
+type InvoiceCreateResult struct {
+	models.Invoice
+	Warnings []string     `json:"warnings,omitempty"`
+	Credit   *CreditCheck `json:"credit,omitempty"` // set when the invoice exceeds the customer's credit limit
+}
+
+// CreditCheck is a customer's outstanding balance before and after a new
+// invoice, against its credit limit.
+type CreditCheck struct {
+	Balance     models.Money `json:"balance"`
+	NewBalance  models.Money `json:"new_balance"`
+	CreditLimit models.Money `json:"credit_limit"`
+}
+
+// message describes what takes contact contactID over its credit limit.
+func (c *CreditCheck) message(what string, contactID int) string {
+	return fmt.Sprintf("%s takes contact %d's outstanding balance from %s to %s, above its credit limit of %s",
+		what, contactID, booksAmount(c.Balance), booksAmount(c.NewBalance), booksAmount(c.CreditLimit))
+}
+
+// creditLimitCheck returns the credit position of the invoice's customer
+// when the invoice would take its outstanding balance above its credit limit,
+// or nil when it stays within it, has no limit, or the invoice is cancelled.
+func creditLimitCheck(s *store.Store, input models.InvoiceInput) (*CreditCheck, error) {
+	if input.ContactID == nil || input.Status == "cancelled" {
+		return nil, nil
+	}
+	c, err := s.GetContact(*input.ContactID)
+	if err != nil {
+		return nil, err
+	}
+	if c.CreditLimit == nil || c.Balance+input.Amount <= *c.CreditLimit {
+		return nil, nil
+	}
+	return &CreditCheck{Balance: c.Balance, NewBalance: c.Balance + input.Amount, CreditLimit: *c.CreditLimit}, nil
 }
 
 // UpdateInvoice updates an existing invoice
 //	@Summary		Update invoice
-//	@Description	Update details of an existing invoice.
+//	@Description	Update details of an existing invoice. Items replace the stored ones; omitting items keeps them, and the amount
+//	@Description	and tax are still worked out from them as on create. An empty items list removes them.
 //	@Tags			invoices
 //	@Accept			json
 //	@Produce		json
@@ -108,7 +186,10 @@ func CreateInvoice(w http.ResponseWriter, r *http.Request) {
 //	@Security		BearerAuth
 func UpdateInvoice(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
 	var input models.InvoiceInput
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid JSON")
@@ -118,18 +199,174 @@ func UpdateInvoice(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, msg)
 		return
 	}
+	if !checkContactType(w, r, s, input.ContactID, "customer", "invoices") {
+		return
+	}
 	inv, err := s.UpdateInvoice(id, input)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			writeError(w, http.StatusNotFound, "invoice not found")
 		} else {
-			writeError(w, http.StatusInternalServerError, err.Error())
+			writeInternalError(w, r, err)
 		}
 		return
 	}
+	publishEvent(r, "updated", "invoice", inv.ID)
 	writeJSON(w, http.StatusOK, inv)
 }
 
+// VoidInvoice cancels an invoice without deleting it
+//	@Summary		Void invoice
+//	@Description	Mark an invoice as cancelled, keeping it for the record. A voided invoice no longer counts towards receivables and is listed only with include_cancelled=true. Invoices with payment allocations cannot be voided until those links are removed.
+//	@Tags			invoices
+//	@Produce		json
+//	@Param			id	path		int	true	"Invoice ID"
+//	@Success		200	{object}	Response{data=models.Invoice}
+//	@Failure		400	{object}	Response{error=string}
+//	@Failure		404	{object}	Response{error=string}
+//	@Failure		409	{object}	Response{error=string}
+//	@Router			/invoices/{id}/void [post]
+//	@Security		BearerAuth
+func VoidInvoice(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+	if _, err := s.GetInvoice(id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "invoice not found")
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+	totals, err := s.DocumentLinkTotals("invoice", id)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	if totals.Count > 0 {
+		writeError(w, http.StatusConflict, fmt.Sprintf("invoice has %d payment allocation(s) totalling %s; remove them before voiding", totals.Count, booksAmount(totals.Allocated)))
+		return
+	}
+	if err := s.VoidInvoice(id); err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	doc, err := s.GetInvoice(id)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	publishEvent(r, "updated", "invoice", id)
+	writeJSON(w, http.StatusOK, doc)
+}
+
+// CloneInvoice copies an invoice into a new draft
+//	@Summary		Clone invoice
+//	@Description	Copy an invoice and its line items into a new draft issued today. The number continues the original's sequence
+//	@Description	(INV-0042 becomes the next free INV-00NN), the due date keeps the original's payment term, and the copy starts unsent with no allocations.
+//	@Description	The copy's amount is checked against the customer's credit_limit as on POST /invoices: over the limit it is created with a
+//	@Description	warning, or, with CREDIT_LIMIT_BLOCK=true, refused with 422.
+//	@Tags			invoices
+//	@Produce		json
+//	@Param			id	path		int	true	"Invoice ID"
+//	@Success		201	{object}	Response{data=InvoiceCreateResult}
+//	@Failure		400	{object}	Response{error=string}
+//	@Failure		404	{object}	Response{error=string}
+//	@Failure		422	{object}	Response{error=string}
+//	@Router			/invoices/{id}/clone [post]
+//	@Security		BearerAuth
+func CloneInvoice(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+	src, err := s.GetInvoice(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "invoice not found")
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+	credit, err := creditLimitCheck(s, models.InvoiceInput{ContactID: src.ContactID, Status: "draft", Amount: src.Amount})
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	result := InvoiceCreateResult{Credit: credit}
+	if credit != nil {
+		msg := credit.message("invoice", *src.ContactID)
+		if cfg.CreditLimitBlock {
+			writeError(w, http.StatusUnprocessableEntity, msg)
+			return
+		}
+		result.Warnings = append(result.Warnings, msg)
+	}
+	inv, err := s.CloneInvoice(id, time.Now().Format("2006-01-02"))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "invoice not found")
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+	result.Invoice = inv
+	publishEvent(r, "created", "invoice", inv.ID)
+	writeJSON(w, http.StatusCreated, result)
+}
+
+// SendInvoice marks an invoice as sent
+//	@Summary		Send invoice
+//	@Description	Record that the invoice was sent to the customer: a draft moves to sent and sent_at is set to now.
+//	@Description	Re-sending a sent, partial, or overdue invoice keeps its status and refreshes sent_at. Paid, received, and cancelled invoices cannot be sent.
+//	@Tags			invoices
+//	@Produce		json
+//	@Param			id	path		int	true	"Invoice ID"
+//	@Success		200	{object}	Response{data=models.Invoice}
+//	@Failure		400	{object}	Response{error=string}
+//	@Failure		404	{object}	Response{error=string}
+//	@Failure		409	{object}	Response{error=string}
+//	@Router			/invoices/{id}/send [post]
+//	@Security		BearerAuth
+func SendInvoice(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+	inv, err := s.GetInvoice(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "invoice not found")
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+	switch inv.Status {
+	case "paid", "received", "cancelled":
+		writeError(w, http.StatusConflict, fmt.Sprintf("invoice is %s and cannot be sent", inv.Status))
+		return
+	}
+	if err := s.SendInvoice(id); err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	doc, err := s.GetInvoice(id)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	publishEvent(r, "updated", "invoice", id)
+	writeJSON(w, http.StatusOK, doc)
+}
+
 // DeleteInvoice deletes an invoice
 //	@Summary		Delete invoice
 //	@Description	Remove an invoice.
@@ -137,46 +374,200 @@ func UpdateInvoice(w http.ResponseWriter, r *http.Request) {
 //	@Produce		json
 //	@Param			id	path		int	true	"Invoice ID"
 //	@Success		200	{object}	Response{data=map[string]string}
+//	@Failure		400	{object}	Response{error=string}
 //	@Failure		404	{object}	Response{error=string}
 //	@Router			/invoices/{id} [delete]
 //	@Security		BearerAuth
 func DeleteInvoice(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
 	if err := s.DeleteInvoice(id); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			writeError(w, http.StatusNotFound, "invoice not found")
 		} else {
-			writeError(w, http.StatusInternalServerError, err.Error())
+			writeInternalError(w, r, err)
 		}
 		return
 	}
+	publishEvent(r, "deleted", "invoice", id)
 	writeJSON(w, http.StatusOK, map[string]string{"message": "deleted"})
 }
 
 // GetInvoiceLinks retrieves all transactions associated with an invoice
 //	@Summary		Get invoice links
-//	@Description	Get all payment transactions linked to a specific invoice.
+//	@Description	Get payment transactions linked to a specific invoice, oldest first unless order=desc. Supports limit/offset pagination; totals across all links are returned in headers.
 //	@Tags			invoices
 //	@Produce		json
-//	@Param			id	path		int	true	"Invoice ID"
-//	@Success		200	{object}	Response{data=[]InvoiceLink}
+//	@Param			id		path		int		true	"Invoice ID"
+//	@Param			limit	query		int		false	"Maximum number of links to return (default DEFAULT_PAGE_SIZE, or all; at most MAX_PAGE_SIZE)"
+//	@Param			offset	query		int		false	"Number of links to skip"
+//	@Param			order	query		string	false	"Sort by created_at: asc (default) or desc"
+//	@Success		200		{object}	Response{data=[]InvoiceLink}
+//	@Header			200		{int}		X-Total-Count		"Total number of links"
+//	@Header			200		{int}		X-Total-Allocated	"Total allocated across all links, in paise"
+//	@Header			200		{int}		X-Page-Size-Clamped	"Limit applied when the requested page exceeded MAX_PAGE_SIZE"
+//	@Failure		400		{object}	Response{error=string}
 //	@Router			/invoices/{id}/links [get]
 //	@Security		BearerAuth
 func GetInvoiceLinks(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
-	links, err := s.GetInvoiceLinks(id)
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+	page, msg := parseLinkPage(w, r)
+	if msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	links, err := s.GetInvoiceLinks(id, page)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeInternalError(w, r, err)
 		return
 	}
+	totals, err := s.DocumentLinkTotals("invoice", id)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	setLinkTotalHeaders(w, totals)
 	writeJSON(w, http.StatusOK, links)
 }
 
 // InvoiceLink is an alias for store.InvoiceLink kept here for Swagger doc references.
 type InvoiceLink = store.InvoiceLink
 
+// DueSoonCustomer is an alias for store.DueSoonCustomer kept here for Swagger doc references.
+type DueSoonCustomer = store.DueSoonCustomer
+
+// ListInvoicesDueSoon lists unpaid invoices falling due in the coming days
+//	@Summary		List invoices due soon
+//	@Description	List sent and partly paid invoices due between today and days from now that still have an outstanding amount, grouped by customer with
+//	@Description	the customer's email, phone, and total outstanding, for follow-up before they become overdue. Customers with the soonest due invoice come first.
+//	@Tags			invoices
+//	@Produce		json
+//	@Param			days	query		int	false	"How many days ahead to look (default 7)"
+//	@Success		200		{object}	Response{data=[]DueSoonCustomer}
+//	@Failure		400		{object}	Response{error=string}
+//	@Router			/invoices/due-soon [get]
+//	@Security		BearerAuth
+func ListInvoicesDueSoon(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	days, ok := queryInt(w, r, "days", 7, 1)
+	if !ok {
+		return
+	}
+	customers, err := s.ListInvoicesDueSoon(time.Now(), days)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, customers)
+}
+
+// InvoiceLedger is an alias for store.InvoiceLedger kept here for Swagger doc references.
+type InvoiceLedger = store.InvoiceLedger
+
+// GetInvoiceLedger retrieves the history of an invoice with its running balance
+//	@Summary		Get invoice ledger
+//	@Description	Chronological history of an invoice: its creation on the issue date, its last send, and each transaction allocated to it, by transaction date, with the outstanding balance after each entry.
+//	@Description	Allocations from offsets and adjustments are reported as adjustment entries, other allocations as payments.
+//	@Tags			invoices
+//	@Produce		json
+//	@Param			id	path		int	true	"Invoice ID"
+//	@Success		200	{object}	Response{data=InvoiceLedger}
+//	@Failure		400	{object}	Response{error=string}
+//	@Failure		404	{object}	Response{error=string,code=string,resource=string}
+//	@Router			/invoices/{id}/ledger [get]
+//	@Security		BearerAuth
+func GetInvoiceLedger(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+	ledger, err := s.GetInvoiceLedger(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeNotFound(w, "invoice")
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, ledger)
+}
+
+// EInvoiceExport is an alias for store.EInvoiceExport kept here for Swagger doc references.
+type EInvoiceExport = store.EInvoiceExport
+
+// GetInvoiceEInvoice exports an invoice as e-invoice JSON
+//	@Summary		Get invoice e-invoice JSON
+//	@Description	Assemble the invoice in the IRP e-invoice schema (version 1.1) for an e-invoice generator: seller and buyer
+//	@Description	GSTINs, the items with their share of the taxable value and CGST/SGST or IGST, and the totals, in rupees.
+//	@Description	Items with their own tax_rate are taxed at it. The seller's GSTIN is read from the business.gstin default. Mandatory fields
+//	@Description	the books do not hold, such as addresses and any item's missing hsn_code, are left empty and listed under missing; irn is null until IRN tracking exists.
+//	@Tags			invoices
+//	@Produce		json
+//	@Param			id	path		int	true	"Invoice ID"
+//	@Success		200	{object}	Response{data=EInvoiceExport}
+//	@Failure		400	{object}	Response{error=string}
+//	@Failure		404	{object}	Response{error=string,code=string,resource=string}
+//	@Failure		409	{object}	Response{error=string}
+//	@Failure		422	{object}	Response{error=string}
+//	@Router			/invoices/{id}/einvoice-json [get]
+//	@Security		BearerAuth
+func GetInvoiceEInvoice(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+	inv, err := s.GetInvoice(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeNotFound(w, "invoice")
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+	if inv.Status == "cancelled" {
+		writeError(w, http.StatusConflict, "cancelled invoices cannot be e-invoiced")
+		return
+	}
+	if inv.TaxRate == nil && !inv.ItemTaxRates() {
+		writeError(w, http.StatusUnprocessableEntity, "invoice has no tax_rate")
+		return
+	}
+	if inv.ContactID == nil {
+		writeError(w, http.StatusUnprocessableEntity, "e-invoices are for registered buyers; set the invoice's contact_id")
+		return
+	}
+	buyer, err := s.GetContact(*inv.ContactID)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	if buyer.GSTIN == nil {
+		writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("e-invoices are for registered buyers; contact %d has no gstin", buyer.ID))
+		return
+	}
+	seller, err := s.GetDefault(models.BusinessGSTINKey)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusBadRequest, "set the business's GSTIN with PUT /defaults/"+models.BusinessGSTINKey)
+		return
+	}
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, store.EInvoiceFor(inv, buyer, seller.Value))
+}
+
 // ListInvoiceItems lists all line items for an invoice
 //	@Summary		List invoice items
 //	@Description	Get all line items for a specific invoice.
@@ -184,16 +575,20 @@ type InvoiceLink = store.InvoiceLink
 //	@Produce		json
 //	@Param			id	path		int	true	"Invoice ID"
 //	@Success		200	{object}	Response{data=[]models.InvoiceItem}
+//	@Failure		400	{object}	Response{error=string}
 //	@Failure		404	{object}	Response{error=string}
 //	@Router			/invoices/{id}/items [get]
 //	@Security		BearerAuth
 func ListInvoiceItems(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
 
 	exists, err := s.InvoiceExists(id)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeInternalError(w, r, err)
 		return
 	}
 	if !exists {
@@ -203,32 +598,93 @@ func ListInvoiceItems(w http.ResponseWriter, r *http.Request) {
 
 	items, err := s.ListInvoiceItems(id)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeInternalError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, items)
 }
 
+// InvoiceItemResult is a created or updated invoice item plus any warnings,
+// such as the customer going over its credit limit.
+type InvoiceItemResult struct {
+	models.InvoiceItem
+	Warnings []string     `json:"warnings,omitempty"`
+	Credit   *CreditCheck `json:"credit,omitempty"` // set when the item takes the customer over its credit limit
+}
+
+// checkItemCreditLimit runs creditLimitCheck on the rise in invoice
+// invoiceID's amount when the item itemID (0 for a new item) takes amount,
+// adding a warning to result when the customer goes over its credit limit. It
+// writes an error response and returns false on failure, or when
+// CREDIT_LIMIT_BLOCK refuses the rise.
+func checkItemCreditLimit(w http.ResponseWriter, r *http.Request, s *store.Store, invoiceID, itemID int, amount models.Money, result *InvoiceItemResult) bool {
+	inv, err := s.GetInvoice(invoiceID)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeNotFound(w, "invoice")
+		return false
+	}
+	if err != nil {
+		writeInternalError(w, r, err)
+		return false
+	}
+	items, err := s.ListInvoiceItems(invoiceID)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return false
+	}
+	newAmount := amount
+	for _, item := range items {
+		if item.ID != itemID {
+			newAmount += item.Amount
+		}
+	}
+	if newAmount <= inv.Amount {
+		return true
+	}
+	credit, err := creditLimitCheck(s, models.InvoiceInput{ContactID: inv.ContactID, Status: inv.Status, Amount: newAmount - inv.Amount})
+	if err != nil {
+		writeInternalError(w, r, err)
+		return false
+	}
+	if credit == nil {
+		return true
+	}
+	msg := credit.message("item", *inv.ContactID)
+	if cfg.CreditLimitBlock {
+		writeError(w, http.StatusUnprocessableEntity, msg)
+		return false
+	}
+	result.Credit = credit
+	result.Warnings = append(result.Warnings, msg)
+	return true
+}
+
 // CreateInvoiceItem creates a new line item for an invoice
 //	@Summary		Create invoice item
-//	@Description	Add a new line item to an existing invoice.
+//	@Description	Add a new line item to an existing invoice. The invoice's amount and tax are worked out again from its items, and its
+//	@Description	status from the new amount. When the rise in amount takes the customer above its credit_limit the item is added with
+//	@Description	a warning, or, with CREDIT_LIMIT_BLOCK=true, refused with 422, as on POST /invoices.
 //	@Tags			invoices
 //	@Accept			json
 //	@Produce		json
 //	@Param			id		path		int						true	"Invoice ID"
 //	@Param			item	body		models.InvoiceItemInput	true	"Line item contents"
-//	@Success		201		{object}	Response{data=models.InvoiceItem}
+//	@Success		201		{object}	Response{data=InvoiceItemResult}
 //	@Failure		400		{object}	Response{error=string}
 //	@Failure		404		{object}	Response{error=string}
+//	@Failure		422		{object}	Response{error=string}
 //	@Router			/invoices/{id}/items [post]
 //	@Security		BearerAuth
 func CreateInvoiceItem(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	invoiceID, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	invoiceID, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
 
 	exists, err := s.InvoiceExists(invoiceID)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to verify invoice existence: "+err.Error())
+		writeInternalError(w, r, err)
 		return
 	}
 	if !exists {
@@ -246,32 +702,45 @@ func CreateInvoiceItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	item, err := s.CreateInvoiceItem(invoiceID, input)
+	var result InvoiceItemResult
+	if !checkItemCreditLimit(w, r, s, invoiceID, 0, input.Amount, &result) {
+		return
+	}
+	result.InvoiceItem, err = s.CreateInvoiceItem(invoiceID, input)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeInternalError(w, r, err)
 		return
 	}
-	writeJSON(w, http.StatusCreated, item)
+	publishEvent(r, "updated", "invoice", invoiceID)
+	writeJSON(w, http.StatusCreated, result)
 }
 
 // UpdateInvoiceItem updates a line item for an invoice
 //	@Summary		Update invoice item
-//	@Description	Update an existing line item in an invoice.
+//	@Description	Update an existing line item in an invoice. The invoice's amount, tax, and status are worked out again, and a rise
+//	@Description	in amount is checked against the customer's credit limit, as on create.
 //	@Tags			invoices
 //	@Accept			json
 //	@Produce		json
 //	@Param			id		path		int						true	"Invoice ID"
 //	@Param			itemId	path		int						true	"Item ID"
 //	@Param			item	body		models.InvoiceItemInput	true	"Updated line item contents"
-//	@Success		200		{object}	Response{data=models.InvoiceItem}
+//	@Success		200		{object}	Response{data=InvoiceItemResult}
 //	@Failure		400		{object}	Response{error=string}
 //	@Failure		404		{object}	Response{error=string}
+//	@Failure		422		{object}	Response{error=string}
 //	@Router			/invoices/{id}/items/{itemId} [put]
 //	@Security		BearerAuth
 func UpdateInvoiceItem(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	invoiceID, _ := strconv.Atoi(chi.URLParam(r, "id"))
-	itemID, _ := strconv.Atoi(chi.URLParam(r, "itemId"))
+	invoiceID, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+	itemID, ok := pathInt(w, r, "itemId")
+	if !ok {
+		return
+	}
 
 	var input models.InvoiceItemInput
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
@@ -283,41 +752,56 @@ func UpdateInvoiceItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	item, err := s.UpdateInvoiceItem(invoiceID, itemID, input)
+	var result InvoiceItemResult
+	if !checkItemCreditLimit(w, r, s, invoiceID, itemID, input.Amount, &result) {
+		return
+	}
+	var err error
+	result.InvoiceItem, err = s.UpdateInvoiceItem(invoiceID, itemID, input)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			writeError(w, http.StatusNotFound, "invoice item not found")
 		} else {
-			writeError(w, http.StatusInternalServerError, err.Error())
+			writeInternalError(w, r, err)
 		}
 		return
 	}
-	writeJSON(w, http.StatusOK, item)
+	publishEvent(r, "updated", "invoice", invoiceID)
+	writeJSON(w, http.StatusOK, result)
 }
 
 // DeleteInvoiceItem deletes a line item from an invoice
 //	@Summary		Delete invoice item
-//	@Description	Remove a line item from an invoice.
+//	@Description	Remove a line item from an invoice. The invoice's amount and tax are worked out again from the items left, and its
+//	@Description	status from the new amount; removing the last item keeps the amount.
 //	@Tags			invoices
 //	@Produce		json
 //	@Param			id		path		int	true	"Invoice ID"
 //	@Param			itemId	path		int	true	"Item ID"
 //	@Success		200		{object}	Response{data=map[string]string}
+//	@Failure		400		{object}	Response{error=string}
 //	@Failure		404		{object}	Response{error=string}
 //	@Router			/invoices/{id}/items/{itemId} [delete]
 //	@Security		BearerAuth
 func DeleteInvoiceItem(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	invoiceID, _ := strconv.Atoi(chi.URLParam(r, "id"))
-	itemID, _ := strconv.Atoi(chi.URLParam(r, "itemId"))
+	invoiceID, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+	itemID, ok := pathInt(w, r, "itemId")
+	if !ok {
+		return
+	}
 
 	if err := s.DeleteInvoiceItem(invoiceID, itemID); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			writeError(w, http.StatusNotFound, "invoice item not found")
 		} else {
-			writeError(w, http.StatusInternalServerError, err.Error())
+			writeInternalError(w, r, err)
 		}
 		return
 	}
+	publishEvent(r, "updated", "invoice", invoiceID)
 	writeJSON(w, http.StatusOK, map[string]string{"message": "deleted"})
 }
@@ -0,0 +1,413 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// TestSendInvoice verifies that sending moves a draft to sent and records
+// sent_at, and that paid or cancelled invoices cannot be sent.
+func TestSendInvoice(t *testing.T) {
+	r, cleanup := setupItemsTestRouter(t)
+	defer cleanup()
+	r.Post("/api/v1/invoices/{id}/send", SendInvoice)
+	r.Post("/api/v1/invoices/{id}/void", VoidInvoice)
+
+	invID := createTestInvoice(t, r)
+	_, resp := apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/invoices/%d", invID), nil)
+	if sentAt := resp["data"].(map[string]interface{})["sent_at"]; sentAt != nil {
+		t.Fatalf("new invoice: expected null sent_at, got %v", sentAt)
+	}
+
+	status, resp := apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/invoices/%d/send", invID), nil)
+	if status != http.StatusOK {
+		t.Fatalf("send invoice: status %d, error %v", status, resp["error"])
+	}
+	inv := resp["data"].(map[string]interface{})
+	if inv["status"] != "sent" || inv["sent_at"] == nil {
+		t.Errorf("expected sent invoice with sent_at, got %v", inv)
+	}
+	if status, _ := apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/invoices/%d/send", invID), nil); status != http.StatusOK {
+		t.Errorf("re-send: expected 200, got %d", status)
+	}
+
+	if status, _ := apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/invoices/%d/void", invID), nil); status != http.StatusOK {
+		t.Fatalf("void invoice: expected 200, got %d", status)
+	}
+	if status, _ := apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/invoices/%d/send", invID), nil); status != http.StatusConflict {
+		t.Errorf("send cancelled invoice: expected 409, got %d", status)
+	}
+	if status, _ := apiRequest(t, r, "POST", "/api/v1/invoices/9999/send", nil); status != http.StatusNotFound {
+		t.Errorf("send missing invoice: expected 404, got %d", status)
+	}
+}
+
+// TestListInvoicesHidesCancelled verifies that voided invoices are left out
+// of the invoice list by default and listed again with include_cancelled=true.
+func TestListInvoicesHidesCancelled(t *testing.T) {
+	r, cleanup := setupItemsTestRouter(t)
+	defer cleanup()
+	r.Get("/api/v1/invoices", ListInvoices)
+	r.Post("/api/v1/invoices/{id}/void", VoidInvoice)
+
+	keptID := createTestInvoice(t, r)
+	_, resp := apiRequest(t, r, "POST", "/api/v1/invoices", map[string]interface{}{
+		"invoice_number": "INV-002", "amount": 80.0, "status": "draft",
+	})
+	voidedID := int(resp["data"].(map[string]interface{})["id"].(float64))
+	if status, resp := apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/invoices/%d/void", voidedID), nil); status != http.StatusOK {
+		t.Fatalf("void invoice: status %d, error %v", status, resp["error"])
+	}
+
+	listed := func(path string) map[int]string {
+		_, resp := apiRequest(t, r, "GET", path, nil)
+		statuses := map[int]string{}
+		for _, i := range resp["data"].([]interface{}) {
+			inv := i.(map[string]interface{})
+			statuses[int(inv["id"].(float64))] = inv["status"].(string)
+		}
+		return statuses
+	}
+	if got := listed("/api/v1/invoices"); len(got) != 1 || got[keptID] != "draft" {
+		t.Errorf("default list: expected only invoice %d, got %v", keptID, got)
+	}
+	if got := listed("/api/v1/invoices?include_cancelled=false"); len(got) != 1 {
+		t.Errorf("include_cancelled=false: expected 1 invoice, got %v", got)
+	}
+	if got := listed("/api/v1/invoices?include_cancelled=true"); len(got) != 2 || got[voidedID] != "cancelled" {
+		t.Errorf("include_cancelled=true: expected both invoices with %d cancelled, got %v", voidedID, got)
+	}
+}
+
+// TestCloneInvoice verifies that a clone is a dated-today draft with the next
+// number in the sequence, the original's items and payment term, and none of
+// its allocations.
+func TestCloneInvoice(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+
+	account := createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": "Current", "type": "bank"})
+	src := createResource(t, r, "/api/v1/invoices", map[string]interface{}{
+		"invoice_number": "INV-0042", "issue_date": "2024-03-01", "due_date": "2024-03-31", "amount": 500.0, "status": "sent",
+		"items": []map[string]interface{}{{"description": "Catering", "quantity": 2, "unit_price": 250.0, "amount": 500.0}},
+	})
+	createResource(t, r, "/api/v1/invoices", map[string]interface{}{"invoice_number": "INV-0043", "amount": 10.0})
+	txn := createResource(t, r, "/api/v1/transactions", map[string]interface{}{"account_id": account, "type": "income", "amount": 500.0, "transaction_date": "2024-03-05"})
+	createResource(t, r, fmt.Sprintf("/api/v1/transactions/%d/links", txn), map[string]interface{}{"document_type": "invoice", "document_id": src, "amount": 500.0})
+
+	status, resp := apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/invoices/%d/clone", src), nil)
+	if status != http.StatusCreated {
+		t.Fatalf("clone: status %d, error %v", status, resp["error"])
+	}
+	inv := resp["data"].(map[string]interface{})
+	today := time.Now()
+	if inv["invoice_number"] != "INV-0044" || inv["status"] != "draft" || inv["sent_at"] != nil {
+		t.Errorf("unexpected clone: number %v status %v sent_at %v", inv["invoice_number"], inv["status"], inv["sent_at"])
+	}
+	if inv["issue_date"] != today.Format("2006-01-02") || inv["due_date"] != today.AddDate(0, 0, 30).Format("2006-01-02") {
+		t.Errorf("issue_date %v due_date %v, want today and 30 days on", inv["issue_date"], inv["due_date"])
+	}
+	if inv["amount"] != 50000.0 || inv["allocated"] != 0.0 {
+		t.Errorf("amount %v allocated %v, want 50000 and 0", inv["amount"], inv["allocated"])
+	}
+	if items := inv["items"].([]interface{}); len(items) != 1 || items[0].(map[string]interface{})["description"] != "Catering" {
+		t.Errorf("expected the line item to be copied, got %v", items)
+	}
+
+	if status, _ := apiRequest(t, r, "POST", "/api/v1/invoices/9999/clone", nil); status != http.StatusNotFound {
+		t.Errorf("clone missing invoice: expected 404, got %d", status)
+	}
+}
+
+// TestGetInvoiceLedger verifies that the ledger lists an invoice's creation,
+// send, payments, and offsets in date order with the running outstanding
+// balance.
+func TestGetInvoiceLedger(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+
+	day := func(offset int) string { return time.Now().AddDate(0, 0, offset).Format("2006-01-02") }
+	bank := createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": "Bank", "type": "bank", "opening_balance": 0})
+	contact := createResource(t, r, "/api/v1/contacts", map[string]interface{}{"name": "Acme", "type": "customer"})
+	vendor := createResource(t, r, "/api/v1/contacts", map[string]interface{}{"name": "Acme", "type": "vendor"})
+	invID := createResource(t, r, "/api/v1/invoices", map[string]interface{}{
+		"contact_id": contact, "invoice_number": "INV-1", "issue_date": day(-10), "amount": 1000.0, "status": "draft",
+	})
+	billID := createResource(t, r, "/api/v1/bills", map[string]interface{}{
+		"contact_id": vendor, "bill_number": "B-1", "issue_date": day(-8), "amount": 150.0, "status": "received",
+	})
+	payment := createResource(t, r, "/api/v1/transactions", map[string]interface{}{
+		"account_id": bank, "type": "income", "amount": 600.0, "transaction_date": day(-5),
+	})
+	createResource(t, r, fmt.Sprintf("/api/v1/transactions/%d/links", payment), map[string]interface{}{
+		"document_type": "invoice", "document_id": invID, "amount": 600.0,
+	})
+	if status, resp := apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/invoices/%d/send", invID), nil); status != http.StatusOK {
+		t.Fatalf("send: status %d, error %v", status, resp["error"])
+	}
+	if status, resp := apiRequest(t, r, "POST", "/api/v1/offsets", map[string]interface{}{
+		"bill_id": billID, "invoice_id": invID, "account_id": bank, "date": day(1),
+	}); status != http.StatusCreated {
+		t.Fatalf("offset: status %d, error %v", status, resp["error"])
+	}
+
+	status, resp := apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/invoices/%d/ledger", invID), nil)
+	if status != http.StatusOK {
+		t.Fatalf("ledger: status %d, error %v", status, resp["error"])
+	}
+	ledger := resp["data"].(map[string]interface{})
+	if ledger["outstanding"] != 25000.0 {
+		t.Errorf("expected 25000 outstanding, got %v", ledger["outstanding"])
+	}
+	want := []struct {
+		event       string
+		date        string
+		amount      float64
+		outstanding float64
+	}{
+		{"created", day(-10), 100000, 100000},
+		{"payment", day(-5), -60000, 40000},
+		{"sent", day(0), 0, 40000},
+		{"adjustment", day(1), -15000, 25000},
+	}
+	entries := ledger["entries"].([]interface{})
+	if len(entries) != len(want) {
+		t.Fatalf("expected %d entries, got %v", len(want), entries)
+	}
+	for i, w := range want {
+		e := entries[i].(map[string]interface{})
+		if e["event"] != w.event || e["date"] != w.date || e["amount"] != w.amount || e["outstanding"] != w.outstanding {
+			t.Errorf("entry %d: got %v, want %+v", i, e, w)
+		}
+	}
+
+	if status, _ := apiRequest(t, r, "GET", "/api/v1/invoices/9999/ledger", nil); status != http.StatusNotFound {
+		t.Errorf("missing invoice: expected 404, got %d", status)
+	}
+}
+
+// TestListInvoicesDueSoon verifies that due-soon lists the outstanding sent
+// invoices due within the window, grouped by customer, and leaves out paid,
+// draft, overdue, and later invoices.
+func TestListInvoicesDueSoon(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+
+	day := func(offset int) string { return time.Now().AddDate(0, 0, offset).Format("2006-01-02") }
+	acme := createResource(t, r, "/api/v1/contacts", map[string]interface{}{"name": "Acme", "type": "customer", "email": "ap@acme.example"})
+	beta := createResource(t, r, "/api/v1/contacts", map[string]interface{}{"name": "Beta", "type": "customer"})
+	invoice := func(contact int, number, status string, due int) int {
+		return createResource(t, r, "/api/v1/invoices", map[string]interface{}{
+			"contact_id": contact, "invoice_number": number, "amount": 100.0, "status": status, "due_date": day(due),
+		})
+	}
+	invoice(acme, "A-1", "sent", 5)
+	invoice(acme, "A-2", "sent", 2)
+	invoice(beta, "B-1", "sent", 1)
+	invoice(beta, "B-2", "draft", 3)
+	invoice(beta, "B-3", "sent", -1)
+	invoice(beta, "B-4", "sent", 10)
+	paid := invoice(acme, "A-3", "sent", 4)
+	bank := createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": "Bank", "type": "bank", "opening_balance": 0})
+	txn := createResource(t, r, "/api/v1/transactions", map[string]interface{}{
+		"account_id": bank, "type": "income", "amount": 100.0, "transaction_date": day(0),
+	})
+	createResource(t, r, fmt.Sprintf("/api/v1/transactions/%d/links", txn), map[string]interface{}{
+		"document_type": "invoice", "document_id": paid, "amount": 100.0,
+	})
+
+	status, resp := apiRequest(t, r, "GET", "/api/v1/invoices/due-soon", nil)
+	if status != http.StatusOK {
+		t.Fatalf("due-soon: status %d, error %v", status, resp["error"])
+	}
+	customers := resp["data"].([]interface{})
+	if len(customers) != 2 {
+		t.Fatalf("expected 2 customers, got %v", customers)
+	}
+	first, second := customers[0].(map[string]interface{}), customers[1].(map[string]interface{})
+	if first["contact_name"] != "Beta" || len(first["invoices"].([]interface{})) != 1 || first["outstanding"] != 10000.0 {
+		t.Errorf("expected Beta first with B-1 only, got %v", first)
+	}
+	invoices := second["invoices"].([]interface{})
+	if second["contact_name"] != "Acme" || second["email"] != "ap@acme.example" || second["outstanding"] != 20000.0 || len(invoices) != 2 ||
+		invoices[0].(map[string]interface{})["invoice_number"] != "A-2" {
+		t.Errorf("expected Acme with A-2 then A-1, got %v", second)
+	}
+
+	if _, resp := apiRequest(t, r, "GET", "/api/v1/invoices/due-soon?days=14", nil); len(resp["data"].([]interface{})) != 2 ||
+		len(resp["data"].([]interface{})[0].(map[string]interface{})["invoices"].([]interface{})) != 2 {
+		t.Errorf("days=14: expected B-4 to join Beta, got %v", resp["data"])
+	}
+	for _, days := range []string{"0", "-3", "soon"} {
+		if status, _ := apiRequest(t, r, "GET", "/api/v1/invoices/due-soon?days="+days, nil); status != http.StatusBadRequest {
+			t.Errorf("days=%s: expected 400, got %d", days, status)
+		}
+	}
+}
+
+// TestCreateInvoiceCreditLimit verifies that an invoice taking a customer over
+// its credit limit is created with a warning, or refused when
+// CREDIT_LIMIT_BLOCK is set, and that a negative limit is rejected.
+func TestCreateInvoiceCreditLimit(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+
+	if status, _ := apiRequest(t, r, "POST", "/api/v1/contacts", map[string]interface{}{"name": "Bad", "type": "customer", "credit_limit": -1.0}); status != http.StatusBadRequest {
+		t.Errorf("negative credit_limit: expected 400, got %d", status)
+	}
+	customer := createResource(t, r, "/api/v1/contacts", map[string]interface{}{"name": "Acme", "type": "customer", "credit_limit": 1000.0})
+	invoice := func(number string, amount float64) (int, map[string]interface{}) {
+		return apiRequest(t, r, "POST", "/api/v1/invoices", map[string]interface{}{"invoice_number": number, "contact_id": customer, "amount": amount, "status": "sent"})
+	}
+
+	status, resp := invoice("INV-1", 800)
+	if status != http.StatusCreated {
+		t.Fatalf("within limit: status %d, error %v", status, resp["error"])
+	}
+	if data := resp["data"].(map[string]interface{}); data["warnings"] != nil || data["credit"] != nil {
+		t.Errorf("within limit: unexpected warning %v", data)
+	}
+
+	status, resp = invoice("INV-2", 300)
+	if status != http.StatusCreated {
+		t.Fatalf("over limit: status %d, error %v", status, resp["error"])
+	}
+	data := resp["data"].(map[string]interface{})
+	credit, _ := data["credit"].(map[string]interface{})
+	if data["invoice_number"] != "INV-2" || len(data["warnings"].([]interface{})) != 1 || credit == nil ||
+		credit["balance"] != 80000.0 || credit["new_balance"] != 110000.0 || credit["credit_limit"] != 100000.0 {
+		t.Errorf("over limit: expected a warning with the credit position, got %v", data)
+	}
+
+	withTestConfig(t, Config{CreditLimitBlock: true})
+	if status, resp := invoice("INV-3", 1); status != http.StatusUnprocessableEntity {
+		t.Errorf("blocked: expected 422, got %d (%v)", status, resp)
+	}
+	_, resp = apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/contacts/%d", customer), nil)
+	if c := resp["data"].(map[string]interface{}); c["credit_limit"] != 100000.0 || c["balance"] != 110000.0 {
+		t.Errorf("contact = %v, want credit_limit 100000 and balance 110000", c)
+	}
+
+	src := int(data["id"].(float64))
+	if status, resp := apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/invoices/%d/clone", src), nil); status != http.StatusUnprocessableEntity {
+		t.Errorf("blocked clone: expected 422, got %d (%v)", status, resp)
+	}
+	withTestConfig(t, Config{})
+	status, resp = apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/invoices/%d/clone", src), nil)
+	if status != http.StatusCreated {
+		t.Fatalf("clone over limit: status %d, error %v", status, resp["error"])
+	}
+	if data := resp["data"].(map[string]interface{}); data["status"] != "draft" || len(data["warnings"].([]interface{})) != 1 || data["credit"] == nil {
+		t.Errorf("clone over limit: expected a draft with a warning, got %v", data)
+	}
+}
+
+// TestGetInvoiceEInvoice verifies that an invoice is exported in the
+// e-invoice schema with its tax spread over the items, and that invoices
+// without a registered buyer or a tax rate are refused.
+func TestGetInvoiceEInvoice(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+
+	buyer := createResource(t, r, "/api/v1/contacts", map[string]interface{}{"name": "Local Traders", "type": "customer", "gstin": "27AABCS1429B1ZU"})
+	walkIn := createResource(t, r, "/api/v1/contacts", map[string]interface{}{"name": "Walk-in", "type": "customer"})
+	id := createResource(t, r, "/api/v1/invoices", map[string]interface{}{"contact_id": buyer, "invoice_number": "INV-1", "issue_date": "2024-07-05",
+		"amount": 1180.0, "tax_rate": 18.0, "status": "sent", "items": []map[string]interface{}{
+			{"description": "Widgets", "quantity": 2, "unit": "pcs", "unit_price": 400.0, "amount": 800.0},
+			{"description": "Fitting", "quantity": 1, "unit_price": 380.0, "amount": 380.0},
+		}})
+	path := fmt.Sprintf("/api/v1/invoices/%d/einvoice-json", id)
+
+	if status, _ := apiRequest(t, r, "GET", path, nil); status != http.StatusBadRequest {
+		t.Errorf("without business.gstin: expected 400, got %d", status)
+	}
+	if status, resp := apiRequest(t, r, "PUT", "/api/v1/defaults/business.gstin", map[string]interface{}{"value": "27AAPFU0939F1ZV"}); status != http.StatusOK {
+		t.Fatalf("set business.gstin: status %d, error %v", status, resp["error"])
+	}
+
+	status, resp := apiRequest(t, r, "GET", path, nil)
+	if status != http.StatusOK {
+		t.Fatalf("einvoice: status %d, error %v", status, resp["error"])
+	}
+	data := resp["data"].(map[string]interface{})
+	e := data["einvoice"].(map[string]interface{})
+	doc := e["DocDtls"].(map[string]interface{})
+	seller := e["SellerDtls"].(map[string]interface{})
+	buyerDtls := e["BuyerDtls"].(map[string]interface{})
+	if doc["No"] != "INV-1" || doc["Dt"] != "05/07/2024" || seller["Gstin"] != "27AAPFU0939F1ZV" ||
+		buyerDtls["Gstin"] != "27AABCS1429B1ZU" || buyerDtls["LglNm"] != "Local Traders" || buyerDtls["Pos"] != "27" {
+		t.Errorf("unexpected document or parties: %v %v %v", doc, seller, buyerDtls)
+	}
+	items := e["ItemList"].([]interface{})
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %v", items)
+	}
+	first := items[0].(map[string]interface{})
+	if first["Unit"] != "PCS" || first["AssAmt"] != 677.98 || first["UnitPrice"] != 338.99 || first["CgstAmt"] != 61.01 ||
+		first["SgstAmt"] != 61.01 || first["IgstAmt"] != 0.0 || first["TotItemVal"] != 800.0 || first["GstRt"] != 18.0 {
+		t.Errorf("first item = %v", first)
+	}
+	values := e["ValDtls"].(map[string]interface{})
+	if values["AssVal"] != 1000.0 || values["CgstVal"] != 90.0 || values["SgstVal"] != 90.0 || values["TotInvVal"] != 1180.0 {
+		t.Errorf("ValDtls = %v", values)
+	}
+	missing := data["missing"].([]interface{})
+	if len(missing) != 9 || missing[len(missing)-1] != "ItemList[1].HsnCd" || data["irn"] != nil {
+		t.Errorf("missing = %v, irn = %v", missing, data["irn"])
+	}
+
+	for _, body := range []map[string]interface{}{
+		{"contact_id": walkIn, "invoice_number": "INV-2", "amount": 100.0, "tax_rate": 18.0},
+		{"contact_id": buyer, "invoice_number": "INV-3", "amount": 100.0},
+	} {
+		id := createResource(t, r, "/api/v1/invoices", body)
+		if status, _ := apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/invoices/%d/einvoice-json", id), nil); status != http.StatusUnprocessableEntity {
+			t.Errorf("%s: expected 422, got %d", body["invoice_number"], status)
+		}
+	}
+	if status, _ := apiRequest(t, r, "GET", "/api/v1/invoices/999999/einvoice-json", nil); status != http.StatusNotFound {
+		t.Errorf("missing invoice: expected 404, got %d", status)
+	}
+}
+
+// TestGetInvoiceEInvoice_ItemRates verifies that items with their own tax
+// rates and HSN codes are exported at those rates and codes, with SAC-coded
+// items marked as services.
+func TestGetInvoiceEInvoice_ItemRates(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+
+	buyer := createResource(t, r, "/api/v1/contacts", map[string]interface{}{"name": "Local Traders", "type": "customer", "gstin": "27AABCS1429B1ZU"})
+	if status, resp := apiRequest(t, r, "PUT", "/api/v1/defaults/business.gstin", map[string]interface{}{"value": "27AAPFU0939F1ZV"}); status != http.StatusOK {
+		t.Fatalf("set business.gstin: status %d, error %v", status, resp["error"])
+	}
+	id := createResource(t, r, "/api/v1/invoices", map[string]interface{}{"contact_id": buyer, "invoice_number": "INV-1", "issue_date": "2024-07-05",
+		"status": "sent", "items": []map[string]interface{}{
+			{"description": "Rice", "quantity": 1, "unit_price": 105.0, "tax_rate": 5.0, "hsn_code": "1006"},
+			{"description": "Catering", "quantity": 1, "unit_price": 118.0, "tax_rate": 18.0, "hsn_code": "996331"},
+		}})
+
+	status, resp := apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/invoices/%d/einvoice-json", id), nil)
+	if status != http.StatusOK {
+		t.Fatalf("einvoice: status %d, error %v", status, resp["error"])
+	}
+	data := resp["data"].(map[string]interface{})
+	items := data["einvoice"].(map[string]interface{})["ItemList"].([]interface{})
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %v", items)
+	}
+	rice, catering := items[0].(map[string]interface{}), items[1].(map[string]interface{})
+	if rice["HsnCd"] != "1006" || rice["IsServc"] != "N" || rice["GstRt"] != 5.0 || rice["AssAmt"] != 100.0 ||
+		rice["CgstAmt"] != 2.5 || rice["SgstAmt"] != 2.5 {
+		t.Errorf("rice = %v", rice)
+	}
+	if catering["HsnCd"] != "996331" || catering["IsServc"] != "Y" || catering["GstRt"] != 18.0 || catering["AssAmt"] != 100.0 ||
+		catering["CgstAmt"] != 9.0 || catering["SgstAmt"] != 9.0 {
+		t.Errorf("catering = %v", catering)
+	}
+	if missing := data["missing"].([]interface{}); len(missing) != 7 {
+		t.Errorf("expected only the address fields missing, got %v", missing)
+	}
+}
@@ -463,6 +463,73 @@ func TestInvoiceCreateAndUpdateWithItems(t *testing.T) {
 	}
 }
 
+// TestInvoiceAmountFromItems verifies that an invoice's amount and tax are
+// worked out from its items, at their own rates where they have one, and are
+// kept in step when items are added or the invoice is updated without them.
+func TestInvoiceAmountFromItems(t *testing.T) {
+	r, cleanup := setupItemsTestRouter(t)
+	defer cleanup()
+
+	status, resp := apiRequest(t, r, "POST", "/api/v1/invoices", map[string]interface{}{
+		"invoice_number": "INV-GST", "amount": 1.0, "status": "draft",
+		"items": []map[string]interface{}{
+			{"description": "Biryani", "quantity": 2.0, "unit_price": 236.0, "tax_rate": 5.0, "hsn_code": "1006"},
+			{"description": "Catering", "quantity": 1.0, "unit_price": 1180.0, "amount": 1180.0, "tax_rate": 18.0, "hsn_code": " 996331 "},
+		},
+	})
+	if status != http.StatusCreated {
+		t.Fatalf("create invoice: status %d, error %v", status, resp["error"])
+	}
+	inv := resp["data"].(map[string]interface{})
+	invoiceID := int(inv["id"].(float64))
+	if inv["amount"] != 165200.0 || inv["tax_amount"] != 20248.0 || inv["tax_rate"] != nil {
+		t.Errorf("amount %v tax_amount %v tax_rate %v, want 165200, 20248, and null for mixed rates", inv["amount"], inv["tax_amount"], inv["tax_rate"])
+	}
+	first := inv["items"].([]interface{})[0].(map[string]interface{})
+	if first["amount"] != 47200.0 || first["tax_amount"] != 2248.0 || first["hsn_code"] != "1006" {
+		t.Errorf("first item = %v", first)
+	}
+	if code := inv["items"].([]interface{})[1].(map[string]interface{})["hsn_code"]; code != "996331" {
+		t.Errorf("expected a trimmed hsn_code, got %v", code)
+	}
+
+	createResource(t, r, fmt.Sprintf("/api/v1/invoices/%d/items", invoiceID), map[string]interface{}{
+		"description": "Delivery", "quantity": 1.0, "unit_price": 100.0,
+	})
+	status, resp = apiRequest(t, r, "PUT", fmt.Sprintf("/api/v1/invoices/%d", invoiceID), map[string]interface{}{
+		"invoice_number": "INV-GST", "amount": 1.0, "status": "sent",
+	})
+	if status != http.StatusOK {
+		t.Fatalf("update invoice: status %d, error %v", status, resp["error"])
+	}
+	inv = resp["data"].(map[string]interface{})
+	if inv["amount"] != 175200.0 || inv["tax_amount"] != 20248.0 || len(inv["items"].([]interface{})) != 3 {
+		t.Errorf("after adding an untaxed item: amount %v tax_amount %v items %v", inv["amount"], inv["tax_amount"], inv["items"])
+	}
+
+	status, resp = apiRequest(t, r, "POST", "/api/v1/invoices", map[string]interface{}{
+		"invoice_number": "INV-18", "tax_rate": 18.0,
+		"items": []map[string]interface{}{
+			{"description": "Thali", "quantity": 1.0, "unit_price": 118.0},
+			{"description": "Dessert", "quantity": 1.0, "unit_price": 59.0, "tax_rate": 18.0},
+		},
+	})
+	if status != http.StatusCreated {
+		t.Fatalf("create invoice at one rate: status %d, error %v", status, resp["error"])
+	}
+	inv = resp["data"].(map[string]interface{})
+	if inv["amount"] != 17700.0 || inv["tax_amount"] != 2700.0 || inv["tax_rate"] != 18.0 ||
+		inv["items"].([]interface{})[0].(map[string]interface{})["tax_rate"] != 18.0 {
+		t.Errorf("expected the invoice's rate on the unrated item and as the common rate, got %v", inv)
+	}
+
+	if status, _ := apiRequest(t, r, "POST", "/api/v1/invoices", map[string]interface{}{
+		"items": []map[string]interface{}{{"description": "Thali", "quantity": 1.0, "unit_price": 118.0, "hsn_code": "10A6"}},
+	}); status != http.StatusBadRequest {
+		t.Errorf("bad hsn_code: expected 400, got %d", status)
+	}
+}
+
 // TestBillItemNotFoundWhenBillMissing verifies 404 for items on non-existent bill.
 func TestBillItemNotFoundWhenBillMissing(t *testing.T) {
 	r, cleanup := setupItemsTestRouter(t)
@@ -480,3 +547,73 @@ func TestBillItemNotFoundWhenBillMissing(t *testing.T) {
 		t.Errorf("expected 404 creating item on non-existent bill, got %d", status)
 	}
 }
+
+// TestInvoiceItemsStatusAndCreditLimit verifies that item changes re-derive
+// the invoice's status from its new amount and check a rise in amount against
+// the customer's credit limit.
+func TestInvoiceItemsStatusAndCreditLimit(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+
+	bank := createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": "Bank", "type": "bank", "opening_balance": 0})
+	customer := createResource(t, r, "/api/v1/contacts", map[string]interface{}{"name": "Acme", "type": "customer", "credit_limit": 1000.0})
+	invID := createResource(t, r, "/api/v1/invoices", map[string]interface{}{
+		"contact_id": customer, "invoice_number": "INV-1", "status": "sent",
+		"items": []map[string]interface{}{{"description": "Catering", "quantity": 1.0, "unit_price": 500.0}},
+	})
+	payment := createResource(t, r, "/api/v1/transactions", map[string]interface{}{
+		"account_id": bank, "type": "income", "amount": 500.0, "transaction_date": "2024-01-10",
+	})
+	createResource(t, r, fmt.Sprintf("/api/v1/transactions/%d/links", payment), map[string]interface{}{
+		"document_type": "invoice", "document_id": invID, "amount": 500.0,
+	})
+	invoiceStatus := func() interface{} {
+		t.Helper()
+		_, resp := apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/invoices/%d", invID), nil)
+		return resp["data"].(map[string]interface{})["status"]
+	}
+	if s := invoiceStatus(); s != "received" {
+		t.Fatalf("expected fully paid invoice to be received, got %v", s)
+	}
+
+	status, resp := apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/invoices/%d/items", invID), map[string]interface{}{
+		"description": "Delivery", "quantity": 1.0, "unit_price": 100.0,
+	})
+	if status != http.StatusCreated {
+		t.Fatalf("create item: status %d, error %v", status, resp["error"])
+	}
+	item := resp["data"].(map[string]interface{})
+	itemID := int(item["id"].(float64))
+	if item["warnings"] != nil {
+		t.Errorf("within limit: unexpected warning %v", item)
+	}
+	if s := invoiceStatus(); s != "partial" {
+		t.Errorf("after adding an item: expected partial, got %v", s)
+	}
+
+	status, resp = apiRequest(t, r, "PUT", fmt.Sprintf("/api/v1/invoices/%d/items/%d", invID, itemID), map[string]interface{}{
+		"description": "Delivery", "quantity": 1.0, "unit_price": 1200.0,
+	})
+	if status != http.StatusOK {
+		t.Fatalf("update item: status %d, error %v", status, resp["error"])
+	}
+	item = resp["data"].(map[string]interface{})
+	credit, _ := item["credit"].(map[string]interface{})
+	if len(item["warnings"].([]interface{})) != 1 || credit == nil || credit["balance"] != 10000.0 || credit["new_balance"] != 120000.0 {
+		t.Errorf("over limit: expected a warning with the credit position, got %v", item)
+	}
+
+	withTestConfig(t, Config{CreditLimitBlock: true})
+	if status, resp := apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/invoices/%d/items", invID), map[string]interface{}{
+		"description": "Extra", "quantity": 1.0, "unit_price": 1.0,
+	}); status != http.StatusUnprocessableEntity {
+		t.Errorf("blocked: expected 422, got %d (%v)", status, resp)
+	}
+
+	if status, resp := apiRequest(t, r, "DELETE", fmt.Sprintf("/api/v1/invoices/%d/items/%d", invID, itemID), nil); status != http.StatusOK {
+		t.Fatalf("delete item: status %d, error %v", status, resp["error"])
+	}
+	if s := invoiceStatus(); s != "received" {
+		t.Errorf("after removing the item: expected received, got %v", s)
+	}
+}
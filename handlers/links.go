@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/satheeshds/portal/store"
+)
+
+// pageClampedHeader is set, to the limit applied, when a page was cut down to
+// MAX_PAGE_SIZE.
+const pageClampedHeader = "X-Page-Size-Clamped"
+
+// parseLinkPage reads the limit, offset, and order query parameters shared by
+// the link listing endpoints. It returns a non-empty message when one is invalid.
+// Without a limit, DEFAULT_PAGE_SIZE applies; a limit above MAX_PAGE_SIZE (or
+// none at all when there is no default) is clamped to it and reported in the
+// X-Page-Size-Clamped header.
+func parseLinkPage(w http.ResponseWriter, r *http.Request) (store.LinkPage, string) {
+	page := store.LinkPage{Limit: cfg.DefaultPageSize}
+	q := r.URL.Query()
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return page, "limit must be a positive integer"
+		}
+		page.Limit = n
+	}
+	if cfg.MaxPageSize > 0 && (page.Limit == 0 || page.Limit > cfg.MaxPageSize) {
+		page.Limit = cfg.MaxPageSize
+		w.Header().Set(pageClampedHeader, strconv.Itoa(page.Limit))
+	}
+	if v := q.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return page, "offset must be a non-negative integer"
+		}
+		page.Offset = n
+	}
+	switch q.Get("order") {
+	case "", "asc":
+	case "desc":
+		page.Desc = true
+	default:
+		return page, "order must be asc or desc"
+	}
+	return page, ""
+}
+
+// setLinkTotalHeaders reports the unpaginated link count and total allocated
+// amount (in paise) so clients can page through links without summing them.
+func setLinkTotalHeaders(w http.ResponseWriter, t store.LinkTotals) {
+	w.Header().Set("X-Total-Count", strconv.Itoa(t.Count))
+	w.Header().Set("X-Total-Allocated", strconv.FormatInt(int64(t.Allocated), 10))
+}
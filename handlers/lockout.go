@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxTrackedAuthKeys bounds the failure tracker; once exceeded, expired
+// entries are swept on the next failure.
+const maxTrackedAuthKeys = 10000
+
+// authLimiter counts failed sign-ins per key (see authKeys) in memory. A key with LoginMaxFailures failures inside LoginFailureWindow is
+// locked until the oldest of them leaves the window. Like sessions, the counts
+// do not survive a restart and are not shared between instances.
+type authLimiter struct {
+	mu       sync.Mutex
+	failures map[string][]time.Time
+}
+
+// authFailures is the process-wide tracker used by Login and BearerAuth.
+var authFailures = newAuthLimiter()
+
+func newAuthLimiter() *authLimiter {
+	return &authLimiter{failures: map[string][]time.Time{}}
+}
+
+// recent returns the failures of key still inside the window at now,
+// dropping older ones. The caller holds l.mu.
+func (l *authLimiter) recent(key string, now time.Time) []time.Time {
+	times := l.failures[key]
+	i := 0
+	for i < len(times) && now.Sub(times[i]) >= cfg.LoginFailureWindow {
+		i++
+	}
+	if i == len(times) {
+		delete(l.failures, key)
+		return nil
+	}
+	times = times[i:]
+	l.failures[key] = times
+	return times
+}
+
+// retryAfter returns how long the most locked of keys stays locked at now, or
+// zero when none is locked or the lockout is disabled.
+func (l *authLimiter) retryAfter(now time.Time, keys ...string) time.Duration {
+	max := cfg.LoginMaxFailures
+	if max <= 0 {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var wait time.Duration
+	for _, key := range keys {
+		times := l.recent(key, now)
+		if len(times) < max {
+			continue
+		}
+		if d := times[len(times)-max].Add(cfg.LoginFailureWindow).Sub(now); d > wait {
+			wait = d
+		}
+	}
+	return wait
+}
+
+// fail records a failed sign-in against each of keys.
+func (l *authLimiter) fail(now time.Time, keys ...string) {
+	if cfg.LoginMaxFailures <= 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.failures) > maxTrackedAuthKeys {
+		for key := range l.failures {
+			l.recent(key, now)
+		}
+	}
+	for _, key := range keys {
+		l.failures[key] = append(l.recent(key, now), now)
+	}
+}
+
+// reset forgets the failures of keys after a successful sign-in.
+func (l *authLimiter) reset(keys ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, key := range keys {
+		delete(l.failures, key)
+	}
+}
+
+// authKeys names the tracker entries for a sign-in as username from the
+// client address of r. Failures are counted per username and address
+// together, so neither a shared proxy address nor guesses from elsewhere lock
+// a user out; attempts without a username are counted per address. The
+// address is r.RemoteAddr, which middleware.RealIP rewrites from the
+// forwarding headers when TRUST_PROXY is set.
+func authKeys(r *http.Request, username string) []string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if username = strings.ToLower(strings.TrimSpace(username)); username == "" {
+		return []string{"ip:" + host}
+	}
+	return []string{"user:" + username + "@" + host}
+}
+
+// writeLockedOut rejects a sign-in attempt made during a lockout, telling the
+// client when to retry.
+func writeLockedOut(w http.ResponseWriter, wait time.Duration) {
+	secs := int(math.Ceil(wait.Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(secs))
+	writeError(w, http.StatusTooManyRequests, fmt.Sprintf("too many failed sign-in attempts; try again in %d seconds", secs))
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// TestLoginLockout verifies that repeated failed logins lock out the email
+// from that client IP with 429 and Retry-After, that clients behind a proxy
+// are told apart by RealIP, and that the lockout lifts once the failures
+// leave the window.
+func TestLoginLockout(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/login", func(w http.ResponseWriter, r *http.Request) {
+		var req loginRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		if req.Password != "right" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"invalid credentials"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"token":"` + makeJWT("tenant_abc", 7200) + `"}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	withTestConfig(t, Config{NexusControlURL: srv.URL, AccessTokenTTL: time.Minute, RefreshTokenTTL: time.Hour,
+		LoginMaxFailures: 2, LoginFailureWindow: 100 * time.Millisecond})
+	prev := authFailures
+	authFailures = newAuthLimiter()
+	t.Cleanup(func() { authFailures = prev })
+
+	login := func(email, password, remoteAddr string) *httptest.ResponseRecorder {
+		payload, _ := json.Marshal(map[string]string{"email": email, "password": password})
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(payload))
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		Login(rec, req)
+		return rec
+	}
+
+	for i := 0; i < 2; i++ {
+		if rec := login("a@example.com", "wrong", "192.0.2.1:1000"); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d: expected 401, got %d", i+1, rec.Code)
+		}
+	}
+	rec := login("a@example.com", "right", "192.0.2.1:1000")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("locked out: expected 429, got %d", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("expected a Retry-After header")
+	}
+	// Only the email from that address is locked: the same email elsewhere,
+	// and other emails from the same address, can still sign in.
+	if rec := login("A@Example.com", "wrong", "192.0.2.1:2000"); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("same email and IP: expected 429, got %d", rec.Code)
+	}
+	if rec := login("a@example.com", "right", "198.51.100.7:2000"); rec.Code != http.StatusOK {
+		t.Errorf("same email, other IP: expected 200, got %d", rec.Code)
+	}
+	if rec := login("b@example.com", "right", "192.0.2.1:3000"); rec.Code != http.StatusOK {
+		t.Errorf("same IP, other email: expected 200, got %d", rec.Code)
+	}
+
+	// Behind RealIP the forwarded client address is the one keyed.
+	realIP := middleware.RealIP(http.HandlerFunc(Login))
+	proxied := func(password, clientIP string) int {
+		payload, _ := json.Marshal(map[string]string{"email": "c@example.com", "password": password})
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(payload))
+		req.RemoteAddr = "10.0.0.1:4000"
+		req.Header.Set("X-Forwarded-For", clientIP)
+		rec := httptest.NewRecorder()
+		realIP.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	proxied("wrong", "203.0.113.5")
+	proxied("wrong", "203.0.113.5")
+	if code := proxied("right", "203.0.113.5"); code != http.StatusTooManyRequests {
+		t.Errorf("proxied client after failures: expected 429, got %d", code)
+	}
+	if code := proxied("right", "203.0.113.6"); code != http.StatusOK {
+		t.Errorf("other client behind the same proxy: expected 200, got %d", code)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	if rec := login("a@example.com", "right", "192.0.2.1:1000"); rec.Code != http.StatusOK {
+		t.Errorf("after the window: expected 200, got %d", rec.Code)
+	}
+}
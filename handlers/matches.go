@@ -1,14 +1,14 @@
 package handlers
 
 import (
+	"database/sql"
+	"errors"
 	"math"
 	"net/http"
 	"sort"
-	"strconv"
 	"strings"
 	"time"
 
-	"github.com/go-chi/chi/v5"
 	"github.com/satheeshds/portal/models"
 	"github.com/satheeshds/portal/store"
 )
@@ -47,15 +47,23 @@ type AutoMatchResult struct {
 //	@Produce		json
 //	@Param			id	path		int	true	"Transaction ID"
 //	@Success		200	{object}	Response{data=[]MatchSuggestion}
+//	@Failure		400	{object}	Response{error=string}
 //	@Failure		404	{object}	Response{error=string}
 //	@Router			/transactions/{id}/match-suggestions [get]
 //	@Security		BearerAuth
 func SuggestMatches(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	txnID, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	txnID, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
 	txn, err := s.GetTransaction(txnID)
 	if err != nil {
-		writeError(w, http.StatusNotFound, "transaction not found")
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "transaction not found")
+		} else {
+			writeInternalError(w, r, err)
+		}
 		return
 	}
 
@@ -87,20 +95,31 @@ func SuggestMatches(w http.ResponseWriter, r *http.Request) {
 
 // AutoMatch automatically links a transaction to the best matching document.
 //	@Summary		Auto-match a transaction to a document
-//	@Description	Finds the best matching bill, invoice, or payout for a bank statement entry and automatically creates a transaction link when confidence is at least 0.7. Returns the created link on success, or the top suggestion without linking when confidence is below the threshold.
+//	@Description	Finds the best matching bill, invoice, or payout for a bank statement entry and automatically creates a transaction link when confidence is at least 0.7. Returns the created link on success, or the top suggestion without linking when confidence is below the threshold. A transaction on an account outside BOOKS_CURRENCY is refused with 400.
 //	@Tags			transactions
 //	@Produce		json
 //	@Param			id	path		int	true	"Transaction ID"
 //	@Success		200	{object}	Response{data=AutoMatchResult}
+//	@Failure		400	{object}	Response{error=string}
 //	@Failure		404	{object}	Response{error=string}
 //	@Router			/transactions/{id}/auto-match [post]
 //	@Security		BearerAuth
 func AutoMatch(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	txnID, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	txnID, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
 	txn, err := s.GetTransaction(txnID)
 	if err != nil {
-		writeError(w, http.StatusNotFound, "transaction not found")
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "transaction not found")
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+	if !checkBooksCurrency(w, txn.Currency, txn.AccountID) {
 		return
 	}
 
@@ -152,11 +171,13 @@ func AutoMatch(w http.ResponseWriter, r *http.Request) {
 	linkAmount := txn.Unallocated
 	td, err := s.CreateTransactionDocumentLink(txnID, bestLinkable.DocumentType, bestLinkable.DocumentID, linkAmount)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeInternalError(w, r, err)
 		return
 	}
 
 	s.UpdateDocumentStatus(bestLinkable.DocumentType, bestLinkable.DocumentID)
+	publishEvent(r, "updated", "transaction", txnID)
+	publishEvent(r, "updated", bestLinkable.DocumentType, bestLinkable.DocumentID)
 	writeJSON(w, http.StatusOK, AutoMatchResult{Matched: true, Link: &td, Suggestion: bestLinkable})
 }
 
@@ -272,7 +293,7 @@ func suggestBills(s *store.Store, amount models.Money, txnDate time.Time, txnSea
 
 		var confidence float64
 		var reasons []string
-		linkable := amount <= unallocated
+		linkable := amount <= unallocated+cfg.AllocationTolerance
 
 		if amount == unallocated {
 			confidence += 0.5
@@ -330,7 +351,7 @@ func suggestInvoices(s *store.Store, amount models.Money, txnDate time.Time, txn
 
 		var confidence float64
 		var reasons []string
-		linkable := amount <= unallocated
+		linkable := amount <= unallocated+cfg.AllocationTolerance
 
 		if amount == unallocated {
 			confidence += 0.5
@@ -388,7 +409,7 @@ func suggestPayouts(s *store.Store, amount models.Money, txnDate time.Time, txnS
 
 		var confidence float64
 		var reasons []string
-		linkable := amount <= unallocated
+		linkable := amount <= unallocated+cfg.AllocationTolerance
 
 		if amount == unallocated {
 			confidence += 0.5
@@ -514,16 +535,24 @@ func getDocumentAllocated(s *store.Store, docType string, docID int) models.Mone
 //	@Produce		json
 //	@Param			id	path		int	true	"Bill ID"
 //	@Success		200	{object}	Response{data=[]TransactionSuggestion}
+//	@Failure		400	{object}	Response{error=string}
 //	@Failure		404	{object}	Response{error=string}
 //	@Router			/bills/{id}/match-suggestions [get]
 //	@Security		BearerAuth
 func SuggestTransactionsForBill(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	billID, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	billID, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
 
 	info, err := s.GetBillForMatching(billID)
 	if err != nil {
-		writeError(w, http.StatusNotFound, "bill not found")
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "bill not found")
+		} else {
+			writeInternalError(w, r, err)
+		}
 		return
 	}
 
@@ -557,16 +586,24 @@ func SuggestTransactionsForBill(w http.ResponseWriter, r *http.Request) {
 //	@Produce		json
 //	@Param			id	path		int	true	"Invoice ID"
 //	@Success		200	{object}	Response{data=[]TransactionSuggestion}
+//	@Failure		400	{object}	Response{error=string}
 //	@Failure		404	{object}	Response{error=string}
 //	@Router			/invoices/{id}/match-suggestions [get]
 //	@Security		BearerAuth
 func SuggestTransactionsForInvoice(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	invoiceID, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	invoiceID, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
 
 	info, err := s.GetInvoiceForMatching(invoiceID)
 	if err != nil {
-		writeError(w, http.StatusNotFound, "invoice not found")
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "invoice not found")
+		} else {
+			writeInternalError(w, r, err)
+		}
 		return
 	}
 
@@ -600,16 +637,24 @@ func SuggestTransactionsForInvoice(w http.ResponseWriter, r *http.Request) {
 //	@Produce		json
 //	@Param			id	path		int	true	"Payout ID"
 //	@Success		200	{object}	Response{data=[]TransactionSuggestion}
+//	@Failure		400	{object}	Response{error=string}
 //	@Failure		404	{object}	Response{error=string}
 //	@Router			/payouts/{id}/match-suggestions [get]
 //	@Security		BearerAuth
 func SuggestTransactionsForPayout(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	payoutID, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	payoutID, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
 
 	info, err := s.GetPayoutForMatching(payoutID)
 	if err != nil {
-		writeError(w, http.StatusNotFound, "payout not found")
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "payout not found")
+		} else {
+			writeInternalError(w, r, err)
+		}
 		return
 	}
 
@@ -634,6 +679,110 @@ func SuggestTransactionsForPayout(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, suggestions)
 }
 
+// payoutAutoMatchWindowDays is how far either side of the settlement date a bank
+// credit may fall and still be auto-matched to a payout.
+const payoutAutoMatchWindowDays = 7
+
+// PayoutAutoMatchResult is the result of auto-matching a payout to a bank credit.
+type PayoutAutoMatchResult struct {
+	Matched    bool                        `json:"matched"`
+	Link       *models.TransactionDocument `json:"link,omitempty"`
+	Candidates []TransactionSuggestion     `json:"candidates"`
+}
+
+// AutoMatchPayout links a payout to its bank credit when exactly one candidate exists.
+//	@Summary		Auto-match a payout to a bank credit
+//	@Description	Scans unreconciled income transactions on bank accounts in BOOKS_CURRENCY for an unallocated amount equal to the payout's unallocated amount (within ALLOCATION_TOLERANCE_PAISE), dated within 7 days of the settlement date. When exactly one candidate is found it is linked to the payout and marked reconciled; otherwise the candidates are returned for manual choice.
+//	@Tags			payouts
+//	@Produce		json
+//	@Param			id	path		int	true	"Payout ID"
+//	@Success		200	{object}	Response{data=PayoutAutoMatchResult}
+//	@Failure		400	{object}	Response{error=string}
+//	@Failure		404	{object}	Response{error=string}
+//	@Router			/payouts/{id}/auto-match [post]
+//	@Security		BearerAuth
+func AutoMatchPayout(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	payoutID, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+
+	info, err := s.GetPayoutForMatching(payoutID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "payout not found")
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+
+	unallocated := models.Money(int64(info.Amount) - int64(getDocumentAllocated(s, "payout", payoutID)))
+	if unallocated <= 0 {
+		writeJSON(w, http.StatusOK, PayoutAutoMatchResult{Matched: false, Candidates: []TransactionSuggestion{}})
+		return
+	}
+
+	var from, to string
+	if !info.SettlementDate.IsZero() {
+		from = info.SettlementDate.AddDate(0, 0, -payoutAutoMatchWindowDays).Format("2006-01-02")
+		to = info.SettlementDate.AddDate(0, 0, payoutAutoMatchWindowDays).Format("2006-01-02")
+	}
+	candidates, err := s.PayoutAutoMatchCandidates(unallocated, cfg.AllocationTolerance, from, to)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	suggestions := make([]TransactionSuggestion, 0, len(candidates))
+	for _, c := range candidates {
+		txnUnallocated := models.Money(int64(c.Amount) - int64(c.Allocated))
+		confidence, reasons := 0.5, []string{"exact_amount_match"}
+		if txnUnallocated != unallocated {
+			confidence, reasons = 0.45, []string{"amount_within_tolerance"}
+		}
+		if ds, reason := matchDateScoreTime(c.Date.Time, info.SettlementDate.Time, payoutAutoMatchWindowDays); ds > 0 {
+			confidence += ds
+			reasons = append(reasons, reason)
+		}
+		txnSearchText := strings.ToLower(c.Description + " " + c.Reference)
+		if ds, reason := matchDescScore(txnSearchText, info.UtrNumber, info.OutletName); ds > 0 {
+			confidence += ds
+			reasons = append(reasons, reason)
+		}
+		suggestions = append(suggestions, TransactionSuggestion{
+			TransactionID:   c.ID,
+			TransactionDate: c.Date.String(),
+			Description:     c.Description,
+			Reference:       c.Reference,
+			Amount:          c.Amount,
+			Unallocated:     txnUnallocated,
+			Confidence:      math.Min(1.0, math.Round(confidence*100)/100),
+			MatchReasons:    reasons,
+			Linkable:        true,
+		})
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Confidence > suggestions[j].Confidence
+	})
+
+	if len(suggestions) != 1 {
+		writeJSON(w, http.StatusOK, PayoutAutoMatchResult{Matched: false, Candidates: suggestions})
+		return
+	}
+
+	match := suggestions[0]
+	td, err := s.MatchPayout(match.TransactionID, payoutID, match.Unallocated)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	publishEvent(r, "updated", "transaction", match.TransactionID)
+	publishEvent(r, "updated", "payout", payoutID)
+	writeJSON(w, http.StatusOK, PayoutAutoMatchResult{Matched: true, Link: &td, Candidates: suggestions})
+}
+
 // suggestTransactionsForDocumentStore queries transactions that could match the given document using the store.
 func suggestTransactionsForDocumentStore(s *store.Store, txnType, docType string, docID int, docUnallocated models.Money,
 	docDate string, windowDays int, docRef, docContext string) []TransactionSuggestion {
@@ -709,16 +858,24 @@ func suggestTransactionsForDocumentStore(s *store.Store, txnType, docType string
 //	@Produce		json
 //	@Param			id	path		int	true	"Recurring Payment ID"
 //	@Success		200	{object}	Response{data=[]TransactionSuggestion}
+//	@Failure		400	{object}	Response{error=string}
 //	@Failure		404	{object}	Response{error=string}
 //	@Router			/recurring-payments/{id}/match-suggestions [get]
 //	@Security		BearerAuth
 func SuggestTransactionsForRecurringPayment(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	rpID, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	rpID, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
 
 	info, err := s.GetRecurringPaymentForMatching(rpID)
 	if err != nil {
-		writeError(w, http.StatusNotFound, "recurring payment not found")
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "recurring payment not found")
+		} else {
+			writeInternalError(w, r, err)
+		}
 		return
 	}
 
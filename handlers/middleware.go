@@ -6,6 +6,7 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -14,6 +15,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/satheeshds/portal/db"
 )
 
@@ -24,6 +26,10 @@ const maxBodyLog = 64 * 1024 // 64 KB
 type Response struct {
 	Data  any    `json:"data"`
 	Error string `json:"error,omitempty"`
+	// Code and Resource identify the error for clients that branch on it
+	// rather than on the message, e.g. NOT_FOUND for an "account".
+	Code     string `json:"code,omitempty"`
+	Resource string `json:"resource,omitempty"`
 }
 
 // DB is the shared database connection used by all handlers.
@@ -31,7 +37,10 @@ var DB *db.PortalDB
 
 type contextKey int
 
-const dbKey contextKey = 0
+const (
+	dbKey contextKey = iota
+	tenantKey
+)
 
 // withDB stores a per-request PortalDB in the context.
 func withDB(ctx context.Context, d *db.PortalDB) context.Context {
@@ -47,6 +56,18 @@ func getDB(r *http.Request) *db.PortalDB {
 	return DB
 }
 
+// withTenant stores the authenticated tenant (or service account) in the context.
+func withTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey, tenant)
+}
+
+// getTenant returns the tenant stored by BearerAuth, or "" for single-tenant
+// deployments without nexus-control.
+func getTenant(r *http.Request) string {
+	t, _ := r.Context().Value(tenantKey).(string)
+	return t
+}
+
 // extractTenantID parses the JWT payload (second dot-separated part) and returns
 // the tenant_id claim value, or ("", false) if it cannot be extracted.
 func extractTenantID(token string) (string, bool) {
@@ -81,6 +102,95 @@ func writeError(w http.ResponseWriter, status int, msg string) {
 	json.NewEncoder(w).Encode(Response{Error: msg})
 }
 
+// writeInternalError logs err and writes a 500 with the message from
+// internalErrorMessage.
+func writeInternalError(w http.ResponseWriter, r *http.Request, err error) {
+	writeError(w, http.StatusInternalServerError, internalErrorMessage(r, err))
+}
+
+// internalErrorMessage logs err with the request id, and with the failing
+// query and its arguments when err came from the database. It returns what the
+// client is told: err itself only when DEBUG_ERRORS is set, since it can
+// expose SQL, and otherwise a generic message naming the request id.
+func internalErrorMessage(r *http.Request, err error) string {
+	reqID := middleware.GetReqID(r.Context())
+	attrs := []any{"method", r.Method, "path", r.URL.Path, "request_id", reqID, "error", err}
+	var qe *db.QueryError
+	if errors.As(err, &qe) {
+		attrs = append(attrs, "query", qe.Query, "args", qe.Args)
+	}
+	slog.ErrorContext(r.Context(), "request failed", attrs...)
+
+	if cfg.DebugErrors {
+		return err.Error()
+	}
+	if reqID != "" {
+		return "internal server error; request id " + reqID
+	}
+	return "internal server error"
+}
+
+// writeNotFound writes a 404 naming the missing resource, e.g. "account".
+func writeNotFound(w http.ResponseWriter, resource string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNotFound)
+	json.NewEncoder(w).Encode(Response{Error: resource + " not found", Code: "NOT_FOUND", Resource: resource})
+}
+
+// writeErrorData writes a JSON error response that also carries data, such as
+// the records responsible for a conflict.
+func writeErrorData(w http.ResponseWriter, status int, msg string, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(Response{Data: data, Error: msg})
+}
+
+// streamJSON writes a Response envelope whose data array is filled by each,
+// encoding items to w as they are yielded rather than building the whole list
+// first. If each fails before yielding anything, a normal 500 error response is
+// written; once output has started, the array is closed and the error is
+// reported in the envelope's error field. Either way the error is logged and
+// shown as writeInternalError would.
+func streamJSON[T any](w http.ResponseWriter, r *http.Request, each func(yield func(T) error) error) {
+	started := false
+	start := func() {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, `{"data":[`)
+		started = true
+	}
+
+	n := 0
+	err := each(func(item T) error {
+		b, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+		if !started {
+			start()
+		}
+		if n > 0 {
+			io.WriteString(w, ",")
+		}
+		n++
+		_, err = w.Write(b)
+		return err
+	})
+	if err != nil && !started {
+		writeInternalError(w, r, err)
+		return
+	}
+	if !started {
+		start()
+	}
+	io.WriteString(w, "]")
+	if err != nil {
+		msg, _ := json.Marshal(internalErrorMessage(r, err))
+		fmt.Fprintf(w, `,"error":%s`, msg)
+	}
+	io.WriteString(w, "}\n")
+}
+
 // DBRequired is middleware that returns 503 Service Unavailable when no database
 // connection has been configured.
 func DBRequired(next http.Handler) http.Handler {
@@ -148,12 +258,25 @@ func BearerAuth(next http.Handler) http.Handler {
 			// Accept both "Bearer <token>" and a raw token (e.g. from Swagger UI
 			// apiKey auth which sends the header value as-is without the prefix).
 			// Basic auth headers are left to the service-account path below.
+			// A session access token from /auth/login stands in for the
+			// Nexus JWT kept server-side.
 			token := strings.TrimPrefix(authHeader, "Bearer ")
 			if token != "" && !strings.HasPrefix(authHeader, "Basic ") {
+				if strings.HasPrefix(token, accessTokenPrefix) {
+					nexusToken, ok := sessions.lookup(token)
+					if !ok {
+						writeError(w, http.StatusUnauthorized, "unauthorized")
+						return
+					}
+					token = nexusToken
+				}
 				if !validateNexusToken(token) {
 					writeError(w, http.StatusUnauthorized, "unauthorized")
 					return
 				}
+				if tenantID, ok := extractTenantID(token); ok {
+					r = r.WithContext(withTenant(r.Context(), tenantID))
+				}
 
 				// Open a per-request DB connection to the Nexus gateway when
 				// NEXUS_HOST is configured, using tenant_id as the PostgreSQL
@@ -207,7 +330,7 @@ func BearerAuth(next http.Handler) http.Handler {
 					return
 				}
 
-				r = r.WithContext(withDB(r.Context(), opened))
+				r = r.WithContext(withTenant(withDB(r.Context(), opened), serviceID))
 				next.ServeHTTP(w, r)
 				opened.Close()
 				return
@@ -217,9 +340,18 @@ func BearerAuth(next http.Handler) http.Handler {
 			return
 		}
 
-		// Fall back to Basic Auth when AUTH_USER/AUTH_PASS are set.
+		// Fall back to Basic Auth when AUTH_USER/AUTH_PASS are set. Failed
+		// attempts feed the same lockout as login.
 		u, p, ok := r.BasicAuth()
+		keys := authKeys(r, u)
+		if wait := authFailures.retryAfter(time.Now(), keys...); wait > 0 {
+			writeLockedOut(w, wait)
+			return
+		}
 		if !ok || u != authUser || p != authPass {
+			if ok {
+				authFailures.fail(time.Now(), keys...)
+			}
 			w.Header().Set("WWW-Authenticate", `Basic realm="portal"`)
 			writeError(w, http.StatusUnauthorized, "unauthorized")
 			return
@@ -325,6 +457,29 @@ func redactHeaders(h http.Header) http.Header {
 	return clone
 }
 
+// compressibleTypes are the response content types Compress encodes. File
+// downloads such as PDF, CSV, and ZIP attachments are already compact or
+// compressed and pass through unchanged.
+var compressibleTypes = []string{
+	"application/json",
+	"text/html",
+	"text/css",
+	"text/javascript",
+	"application/javascript",
+	"image/svg+xml",
+}
+
+// Compress is middleware that gzip- or deflate-encodes JSON and UI asset
+// responses for clients that send a matching Accept-Encoding, at
+// cfg.CompressLevel. Responses that already set Content-Encoding are left
+// alone. It is a no-op when CompressLevel is zero.
+func Compress(next http.Handler) http.Handler {
+	if cfg.CompressLevel <= 0 {
+		return next
+	}
+	return middleware.Compress(cfg.CompressLevel, compressibleTypes...)(next)
+}
+
 // RequestLogger is middleware that logs the full request and response bodies at debug level.
 // It is a no-op when debug logging is not enabled.
 // WARNING: enabling debug logging will expose full request and response bodies in logs,
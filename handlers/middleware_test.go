@@ -1,13 +1,19 @@
 package handlers
 
 import (
+	"bytes"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
 )
 
 // makeJWT builds a minimal JWT with the given tenant_id claim and expiry
@@ -216,3 +222,133 @@ func TestBearerAuth_ServiceAccount_EmptyCredentials_Returns401(t *testing.T) {
 		t.Fatalf("expected 401 for empty api key, got %d", rec.Code)
 	}
 }
+
+func TestStreamJSON(t *testing.T) {
+	withTestConfig(t, Config{DebugErrors: true})
+	stream := func(items []int, failAfter int) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		streamJSON(w, httptest.NewRequest(http.MethodGet, "/", nil), func(yield func(int) error) error {
+			for i, v := range items {
+				if i == failAfter {
+					return errors.New("boom")
+				}
+				if err := yield(v); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		return w
+	}
+
+	tests := []struct {
+		name      string
+		items     []int
+		failAfter int
+		status    int
+		body      string
+	}{
+		{"empty list", nil, -1, http.StatusOK, `{"data":[]}`},
+		{"items", []int{1, 2, 3}, -1, http.StatusOK, `{"data":[1,2,3]}`},
+		{"error before first item", []int{1, 2}, 0, http.StatusInternalServerError, `{"data":null,"error":"boom"}`},
+		{"error mid-stream", []int{1, 2, 3}, 2, http.StatusOK, `{"data":[1,2],"error":"boom"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := stream(tt.items, tt.failAfter)
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+			if got := strings.TrimSpace(w.Body.String()); got != tt.body {
+				t.Errorf("body = %s, want %s", got, tt.body)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("Content-Type = %q", ct)
+			}
+		})
+	}
+}
+
+func TestCompress(t *testing.T) {
+	handler := func(contentType string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", contentType)
+			w.Write([]byte(strings.Repeat("portal ", 200)))
+		})
+	}
+	encoding := func(contentType, acceptEncoding string) string {
+		req := httptest.NewRequest("GET", "/", nil)
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		w := httptest.NewRecorder()
+		Compress(handler(contentType)).ServeHTTP(w, req)
+		return w.Header().Get("Content-Encoding")
+	}
+
+	withTestConfig(t, Config{CompressLevel: 5})
+	if got := encoding("application/json", "gzip"); got != "gzip" {
+		t.Errorf("JSON with Accept-Encoding gzip: Content-Encoding = %q, want gzip", got)
+	}
+	if got := encoding("application/json", ""); got != "" {
+		t.Errorf("JSON without Accept-Encoding: Content-Encoding = %q, want none", got)
+	}
+	for _, contentType := range []string{"text/csv", "application/pdf", "application/zip"} {
+		if got := encoding(contentType, "gzip"); got != "" {
+			t.Errorf("%s download: Content-Encoding = %q, want none", contentType, got)
+		}
+	}
+
+	withTestConfig(t, Config{})
+	if got := encoding("application/json", "gzip"); got != "" {
+		t.Errorf("compression disabled: Content-Encoding = %q, want none", got)
+	}
+}
+
+// TestInternalErrorLogsQuery verifies that a failing query is logged with its
+// SQL and the request id while the client gets a generic message, unless
+// DEBUG_ERRORS is set.
+func TestInternalErrorLogsQuery(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+	h := middleware.RequestID(r)
+
+	var logs bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	if _, err := DB.Exec("DROP TABLE outlets"); err != nil {
+		t.Fatalf("drop table: %v", err)
+	}
+	get := func() map[string]interface{} {
+		t.Helper()
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/outlets/1", nil))
+		if w.Code != http.StatusInternalServerError {
+			t.Fatalf("expected 500, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp map[string]interface{}
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return resp
+	}
+
+	msg, _ := get()["error"].(string)
+	if !strings.HasPrefix(msg, "internal server error; request id ") || strings.Contains(msg, "outlets") {
+		t.Errorf("expected a sanitized message with the request id, got %q", msg)
+	}
+	reqID := strings.TrimPrefix(msg, "internal server error; request id ")
+	logged := logs.String()
+	for _, want := range []string{"request failed", "request_id=" + reqID, "FROM outlets", "path=/api/v1/outlets/1"} {
+		if !strings.Contains(logged, want) {
+			t.Errorf("log missing %q:\n%s", want, logged)
+		}
+	}
+
+	withTestConfig(t, Config{DebugErrors: true})
+	if msg, _ := get()["error"].(string); !strings.Contains(msg, "outlets") {
+		t.Errorf("DEBUG_ERRORS: expected the raw database error, got %q", msg)
+	}
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/satheeshds/portal/models"
+	"github.com/satheeshds/portal/ocr"
+	"github.com/satheeshds/portal/store"
+)
+
+// maxOCRUploadSize caps the receipt images accepted in one OCR request.
+const maxOCRUploadSize = 25 << 20
+
+// OCRBillDraft is a bill prefilled from one receipt, for the user to confirm
+// and submit to POST /bills.
+type OCRBillDraft struct {
+	File     string           `json:"file"`   // uploaded file name, empty for a raw body
+	Vendor   string           `json:"vendor"` // vendor name as read from the receipt
+	Bill     models.BillInput `json:"bill"`   // contact_id is set when vendor matches a vendor contact
+	Warnings []string         `json:"warnings,omitempty"`
+	Error    string           `json:"error,omitempty"` // extraction failed; bill is an empty draft
+}
+
+// OCRResult lists the drafts read from the uploaded receipts.
+type OCRResult struct {
+	Extractor string         `json:"extractor"` // "manual" when no OCR service is configured
+	Drafts    []OCRBillDraft `json:"drafts"`
+}
+
+// OCRBills reads receipt images into draft bills
+//	@Summary		Draft bills from receipts
+//	@Description	Read one or more receipt images with the configured OCR service (OCR_SERVICE_URL) and return a draft bill for each,
+//	@Description	prefilled with the detected vendor, date, amount, and bill number. Nothing is saved: confirm a draft by sending its bill to POST /bills.
+//	@Description	Send images as "file" fields of a multipart form, or a single image as the request body. Without an OCR service, drafts are empty for manual entry.
+//	@Tags			bills
+//	@Accept			multipart/form-data
+//	@Accept			image/jpeg
+//	@Accept			image/png
+//	@Produce		json
+//	@Success		200	{object}	Response{data=OCRResult}
+//	@Failure		400	{object}	Response{error=string}
+//	@Router			/bills/ocr [post]
+//	@Security		BearerAuth
+func OCRBills(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	extractor := cfg.OCR
+	if extractor == nil {
+		extractor = ocr.Manual{}
+	}
+
+	uploads, err := receiptUploads(w, r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	vendors, err := s.ListContacts("vendor", "", false)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	result := OCRResult{Extractor: extractor.Name(), Drafts: make([]OCRBillDraft, 0, len(uploads))}
+	for _, u := range uploads {
+		draft := OCRBillDraft{File: u.name, Bill: models.BillInput{Status: "draft"}}
+		rec, err := extractor.Extract(r.Context(), u.data, u.contentType)
+		if err != nil {
+			draft.Error = err.Error()
+			result.Drafts = append(result.Drafts, draft)
+			continue
+		}
+		draft.Vendor = rec.Vendor
+		draft.Bill.BillNumber = rec.BillNumber
+		draft.Bill.Amount = rec.Amount
+		if rec.Date != "" {
+			date := rec.Date
+			if err := models.NormalizeDate(&date); err != nil {
+				draft.Warnings = append(draft.Warnings, fmt.Sprintf("could not read date %q", rec.Date))
+			} else {
+				draft.Bill.IssueDate = &date
+			}
+		}
+		if rec.Vendor != "" {
+			for _, c := range vendors {
+				if strings.EqualFold(strings.TrimSpace(c.Name), rec.Vendor) {
+					id := c.ID
+					draft.Bill.ContactID = &id
+					break
+				}
+			}
+			if draft.Bill.ContactID == nil {
+				draft.Warnings = append(draft.Warnings, fmt.Sprintf("no vendor contact named %q", rec.Vendor))
+			}
+		}
+		result.Drafts = append(result.Drafts, draft)
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// receiptUpload is one uploaded receipt image.
+type receiptUpload struct {
+	name        string
+	contentType string
+	data        []byte
+}
+
+// receiptUploads returns the images in the "file" fields of a multipart form,
+// or the raw request body as a single image. Each must be an image or PDF.
+func receiptUploads(w http.ResponseWriter, r *http.Request) ([]receiptUpload, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxOCRUploadSize)
+
+	var uploads []receiptUpload
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(maxOCRUploadSize); err != nil {
+			return nil, fmt.Errorf("invalid multipart form: %w", err)
+		}
+		for _, fh := range r.MultipartForm.File["file"] {
+			f, err := fh.Open()
+			if err != nil {
+				return nil, err
+			}
+			data, err := io.ReadAll(f)
+			f.Close()
+			if err != nil {
+				return nil, err
+			}
+			uploads = append(uploads, receiptUpload{name: fh.Filename, data: data})
+		}
+	} else {
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) > 0 {
+			uploads = append(uploads, receiptUpload{data: data})
+		}
+	}
+	if len(uploads) == 0 {
+		return nil, errors.New("file is required")
+	}
+
+	for i := range uploads {
+		ct := http.DetectContentType(uploads[i].data)
+		if !strings.HasPrefix(ct, "image/") && ct != "application/pdf" {
+			name := uploads[i].name
+			if name == "" {
+				name = "body"
+			}
+			return nil, fmt.Errorf("%s is not an image (detected %s)", name, ct)
+		}
+		uploads[i].contentType = ct
+	}
+	return uploads, nil
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/satheeshds/portal/ocr"
+)
+
+// pngHeader is enough of a PNG file for content sniffing.
+const pngHeader = "\x89PNG\r\n\x1a\n"
+
+// stubExtractor returns receipts keyed by the image contents.
+type stubExtractor map[string]ocr.Receipt
+
+func (stubExtractor) Name() string { return "stub" }
+
+func (s stubExtractor) Extract(_ context.Context, image []byte, _ string) (ocr.Receipt, error) {
+	rec, ok := s[string(image)]
+	if !ok {
+		return ocr.Receipt{}, errors.New("unreadable receipt")
+	}
+	return rec, nil
+}
+
+// TestOCRBills verifies that each uploaded receipt becomes a draft bill with
+// the vendor matched to a contact, and that non-images are rejected.
+func TestOCRBills(t *testing.T) {
+	r, cleanup := setupContactsTestRouter(t)
+	defer cleanup()
+	r.Post("/api/v1/bills/ocr", OCRBills)
+
+	_, resp := apiRequest(t, r, "POST", "/api/v1/contacts", map[string]interface{}{"name": "Acme Supplies", "type": "vendor"})
+	acmeID := int(resp["data"].(map[string]interface{})["id"].(float64))
+
+	acme, unknown, broken := pngHeader+"acme", pngHeader+"unknown", pngHeader+"broken"
+	withTestConfig(t, Config{OCR: stubExtractor{
+		acme:    {Vendor: "acme supplies", Date: "05-01-2024", Amount: 123450, BillNumber: "A-77"},
+		unknown: {Vendor: "Corner Shop", Date: "sometime", Amount: 5000},
+	}})
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for name, data := range map[string]string{"acme.png": acme, "shop.png": unknown, "broken.png": broken} {
+		fw, _ := mw.CreateFormFile("file", name)
+		fw.Write([]byte(data))
+	}
+	mw.Close()
+	req := httptest.NewRequest("POST", "/api/v1/bills/ocr", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("ocr: status %d, body %s", w.Code, w.Body.String())
+	}
+
+	var result map[string]interface{}
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	data := result["data"].(map[string]interface{})
+	if data["extractor"] != "stub" || len(data["drafts"].([]interface{})) != 3 {
+		t.Fatalf("unexpected result: %+v", data)
+	}
+	drafts := map[string]map[string]interface{}{}
+	for _, d := range data["drafts"].([]interface{}) {
+		draft := d.(map[string]interface{})
+		drafts[draft["file"].(string)] = draft
+	}
+
+	a := drafts["acme.png"]["bill"].(map[string]interface{})
+	if a["contact_id"] != float64(acmeID) || a["bill_number"] != "A-77" || a["amount"] != float64(123450) ||
+		a["issue_date"] != "2024-01-05" || a["status"] != "draft" {
+		t.Errorf("unexpected acme draft: %+v", drafts["acme.png"])
+	}
+	shop := drafts["shop.png"]
+	if bill := shop["bill"].(map[string]interface{}); bill["contact_id"] != nil || bill["issue_date"] != nil {
+		t.Errorf("expected no contact or date for shop.png: %+v", shop)
+	}
+	if warnings, _ := shop["warnings"].([]interface{}); len(warnings) != 2 {
+		t.Errorf("expected unmatched vendor and unreadable date warnings: %+v", shop)
+	}
+	if drafts["broken.png"]["error"] == nil || drafts["broken.png"]["error"] == "" {
+		t.Errorf("expected an extraction error for broken.png: %+v", drafts["broken.png"])
+	}
+
+	req = httptest.NewRequest("POST", "/api/v1/bills/ocr", bytes.NewReader([]byte("plain text")))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("non-image body: expected 400, got %d", w.Code)
+	}
+
+	// Without an OCR service the draft is left for manual entry.
+	withTestConfig(t, Config{})
+	req = httptest.NewRequest("POST", "/api/v1/bills/ocr", bytes.NewReader([]byte(acme)))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	var manual map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&manual)
+	if w.Code != http.StatusOK {
+		t.Fatalf("manual fallback: status %d, body %v", w.Code, manual)
+	}
+	if data := manual["data"].(map[string]interface{}); data["extractor"] != "manual" || len(data["drafts"].([]interface{})) != 1 {
+		t.Errorf("manual fallback: unexpected result %+v", data)
+	}
+}
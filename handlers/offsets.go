@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/satheeshds/portal/models"
+	"github.com/satheeshds/portal/store"
+)
+
+// OffsetResult is an alias for store.OffsetResult kept here for Swagger doc references.
+type OffsetResult = store.OffsetResult
+
+// CreateOffset nets a bill against an invoice of the same party
+//	@Summary		Create offset
+//	@Description	Settle a bill and an invoice against each other without moving cash, for a party that is both a vendor and a customer.
+//	@Description	A synthetic offset transaction dated date (required) is recorded under account_id (its balance is unchanged) and linked to both
+//	@Description	documents for amount, which defaults to the smaller unallocated amount. The bill and invoice must belong to the same contact, or to
+//	@Description	contacts with the same PAN (from the GSTIN when no PAN is recorded); a contact with neither is matched by name.
+//	@Description	The account must be in BOOKS_CURRENCY. Delete the offset transaction to undo it.
+//	@Tags			transactions
+//	@Accept			json
+//	@Produce		json
+//	@Param			offset	body		models.OffsetInput	true	"Documents to offset"
+//	@Success		201		{object}	Response{data=OffsetResult}
+//	@Failure		400		{object}	Response{error=string}
+//	@Failure		404		{object}	Response{error=string}
+//	@Failure		409		{object}	Response{error=string}
+//	@Router			/offsets [post]
+//	@Security		BearerAuth
+func CreateOffset(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	var input models.OffsetInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if msg := input.Validate(); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	bill, err := s.GetBill(input.BillID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeNotFound(w, "bill")
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+	invoice, err := s.GetInvoice(input.InvoiceID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeNotFound(w, "invoice")
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+	account, err := s.GetAccount(input.AccountID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeNotFound(w, "account")
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+	if !checkBooksCurrency(w, account.Currency, account.ID) {
+		return
+	}
+	if bill.Status == "cancelled" || invoice.Status == "cancelled" {
+		writeError(w, http.StatusConflict, "voided documents cannot be offset")
+		return
+	}
+	same, err := sameParty(s, bill.ContactID, invoice.ContactID)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	if !same {
+		writeError(w, http.StatusBadRequest, "bill and invoice must belong to the same contact")
+		return
+	}
+
+	available := min(bill.Unallocated, invoice.Unallocated)
+	if input.Amount == 0 {
+		input.Amount = available
+	}
+	if input.Amount <= 0 {
+		writeError(w, http.StatusBadRequest, "nothing left to offset: the bill or invoice is fully allocated")
+		return
+	}
+	if input.Amount > available {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("at most %s can be offset (bill unallocated %s, invoice unallocated %s)",
+			booksAmount(available), booksAmount(bill.Unallocated), booksAmount(invoice.Unallocated)))
+		return
+	}
+	if !checkPeriodOpen(w, r, s, stringValue(input.Date)) {
+		return
+	}
+
+	result, err := s.CreateOffset(input, bill.ContactID,
+		fmt.Sprintf("Offset: bill %s against invoice %s", bill.BillNumber, invoice.InvoiceNumber))
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	publishEvent(r, "created", "transaction", result.Transaction.ID)
+	publishEvent(r, "updated", "bill", bill.ID)
+	publishEvent(r, "updated", "invoice", invoice.ID)
+	writeJSON(w, http.StatusCreated, result)
+}
+
+// sameParty reports whether two document contacts are one party: the same
+// contact, or a vendor and a customer contact with the same PAN, taken from
+// the GSTIN when no PAN is recorded. Only when either contact has neither are
+// they matched by name.
+func sameParty(s *store.Store, billContactID, invoiceContactID *int) (bool, error) {
+	if billContactID == nil || invoiceContactID == nil {
+		return false, nil
+	}
+	if *billContactID == *invoiceContactID {
+		return true, nil
+	}
+	vendor, err := s.GetContact(*billContactID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	customer, err := s.GetContact(*invoiceContactID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	if vendorPAN, customerPAN := contactPAN(vendor), contactPAN(customer); vendorPAN != "" && customerPAN != "" {
+		return vendorPAN == customerPAN, nil
+	}
+	return strings.EqualFold(strings.TrimSpace(vendor.Name), strings.TrimSpace(customer.Name)), nil
+}
+
+// contactPAN returns the PAN of c, which is characters 3-12 of its GSTIN, or
+// "" when it has neither.
+func contactPAN(c models.Contact) string {
+	switch {
+	case c.PAN != nil:
+		return *c.PAN
+	case c.GSTIN != nil && len(*c.GSTIN) >= 12:
+		return (*c.GSTIN)[2:12]
+	}
+	return ""
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/satheeshds/portal/store"
+)
+
+// TestCreateOffset verifies that a bill and an invoice of the same party are
+// settled against each other by one offset transaction that leaves the
+// account balance alone, and that unrelated parties and undated offsets are
+// rejected.
+func TestCreateOffset(t *testing.T) {
+	r, cleanup := setupContactsTestRouter(t)
+	defer cleanup()
+	r.Post("/api/v1/invoices", CreateInvoice)
+	r.Get("/api/v1/bills/{id}", GetBill)
+	r.Get("/api/v1/accounts/{id}", GetAccount)
+	r.Get("/api/v1/transactions/{id}", GetTransaction)
+	r.Post("/api/v1/offsets", CreateOffset)
+
+	_, resp := apiRequest(t, r, "POST", "/api/v1/accounts", map[string]interface{}{"name": "Current", "type": "bank", "opening_balance": 500.0})
+	accID := int(resp["data"].(map[string]interface{})["id"].(float64))
+	contact := func(name, contactType string) int {
+		_, resp := apiRequest(t, r, "POST", "/api/v1/contacts", map[string]interface{}{"name": name, "type": contactType})
+		return int(resp["data"].(map[string]interface{})["id"].(float64))
+	}
+	vendor, customer, other := contact("Fresh Farms", "vendor"), contact("fresh farms ", "customer"), contact("Other Co", "customer")
+
+	_, resp = apiRequest(t, r, "POST", "/api/v1/bills", map[string]interface{}{"bill_number": "B-1", "contact_id": vendor, "amount": 300.0, "status": "draft"})
+	billID := int(resp["data"].(map[string]interface{})["id"].(float64))
+	invoice := func(number string, contactID int) int {
+		_, resp := apiRequest(t, r, "POST", "/api/v1/invoices", map[string]interface{}{"invoice_number": number, "contact_id": contactID, "amount": 100.0, "status": "sent"})
+		return int(resp["data"].(map[string]interface{})["id"].(float64))
+	}
+	invoiceID, otherInvoiceID := invoice("I-1", customer), invoice("I-2", other)
+
+	offset := func(body map[string]interface{}) (int, map[string]interface{}) {
+		body["account_id"] = accID
+		if _, ok := body["date"]; !ok {
+			body["date"] = "2024-03-01"
+		}
+		return apiRequest(t, r, "POST", "/api/v1/offsets", body)
+	}
+	if status, _ := offset(map[string]interface{}{"bill_id": billID, "invoice_id": otherInvoiceID}); status != http.StatusBadRequest {
+		t.Errorf("different parties: expected 400, got %d", status)
+	}
+	if status, _ := offset(map[string]interface{}{"bill_id": billID, "invoice_id": invoiceID, "date": nil}); status != http.StatusBadRequest {
+		t.Errorf("no date: expected 400, got %d", status)
+	}
+	if status, _ := offset(map[string]interface{}{"bill_id": billID, "invoice_id": invoiceID, "amount": 150.0}); status != http.StatusBadRequest {
+		t.Errorf("amount above the invoice's unallocated: expected 400, got %d", status)
+	}
+	if status, _ := offset(map[string]interface{}{"bill_id": 999, "invoice_id": invoiceID}); status != http.StatusNotFound {
+		t.Errorf("missing bill: expected 404, got %d", status)
+	}
+
+	status, resp := offset(map[string]interface{}{"bill_id": billID, "invoice_id": invoiceID, "date": "2024-03-01", "note": "netted"})
+	if status != http.StatusCreated {
+		t.Fatalf("create offset: status %d, error %v", status, resp["error"])
+	}
+	data := resp["data"].(map[string]interface{})
+	if data["bill_status"] != "partial" || data["invoice_status"] != "received" {
+		t.Errorf("expected bill partial and invoice received, got %v / %v", data["bill_status"], data["invoice_status"])
+	}
+	links := data["links"].([]interface{})
+	if len(links) != 2 || links[0].(map[string]interface{})["document_type"] != "bill" ||
+		links[1].(map[string]interface{})["amount"].(float64) != 10000 || links[1].(map[string]interface{})["note"] != "netted" {
+		t.Errorf("unexpected links: %v", links)
+	}
+	txn := data["transaction"].(map[string]interface{})
+	if txn["type"] != "offset" || txn["amount"].(float64) != 10000 || txn["unallocated"].(float64) != 0 {
+		t.Errorf("unexpected offset transaction: %v", txn)
+	}
+	txnID := int(txn["id"].(float64))
+
+	_, resp = apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/bills/%d", billID), nil)
+	if unallocated := resp["data"].(map[string]interface{})["unallocated"].(float64); unallocated != 20000 {
+		t.Errorf("expected bill unallocated 20000, got %v", unallocated)
+	}
+	_, resp = apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/accounts/%d", accID), nil)
+	if balance := resp["data"].(map[string]interface{})["balance"].(float64); balance != 50000 {
+		t.Errorf("expected the account balance to stay 50000, got %v", balance)
+	}
+
+	if status, _ := apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/transactions/%d/links", txnID), map[string]interface{}{
+		"document_type": "bill", "document_id": billID, "amount": 1.0,
+	}); status != http.StatusBadRequest {
+		t.Errorf("linking more to an offset: expected 400, got %d", status)
+	}
+	if status, _ := offset(map[string]interface{}{"bill_id": billID, "invoice_id": invoiceID}); status != http.StatusBadRequest {
+		t.Errorf("offsetting a settled invoice again: expected 400, got %d", status)
+	}
+
+	if status, _ := apiRequest(t, r, "DELETE", fmt.Sprintf("/api/v1/transactions/%d", txnID), nil); status != http.StatusOK {
+		t.Fatalf("delete offset: expected 200, got %d", status)
+	}
+	_, resp = apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/bills/%d", billID), nil)
+	if bill := resp["data"].(map[string]interface{}); bill["status"] != "draft" || bill["unallocated"].(float64) != 30000 {
+		t.Errorf("expected the bill restored to draft with 30000 unallocated, got %v", bill)
+	}
+}
+
+// TestSamePartyByPAN verifies that contacts with a PAN, given directly or as
+// part of a GSTIN, are matched by it rather than by name.
+func TestSamePartyByPAN(t *testing.T) {
+	r, cleanup := setupContactsTestRouter(t)
+	defer cleanup()
+	s := store.New(DB)
+
+	contact := func(name, contactType string, extra map[string]interface{}) *int {
+		body := map[string]interface{}{"name": name, "type": contactType}
+		for k, v := range extra {
+			body[k] = v
+		}
+		id := createResource(t, r, "/api/v1/contacts", body)
+		return &id
+	}
+	vendor := contact("Fresh Farms", "vendor", map[string]interface{}{"gstin": "27AAPFU0939F1ZV"})
+	for _, tc := range []struct {
+		name     string
+		customer *int
+		want     bool
+	}{
+		{"same name, other PAN", contact("Fresh Farms", "customer", map[string]interface{}{"pan": "ABCPE1234F"}), false},
+		{"other name, same PAN", contact("FF Retail", "customer", map[string]interface{}{"pan": "AAPFU0939F"}), true},
+		{"same name, no PAN", contact("fresh farms ", "customer", nil), true},
+		{"other name, no PAN", contact("Other Co", "customer", nil), false},
+	} {
+		got, err := sameParty(s, vendor, tc.customer)
+		if err != nil || got != tc.want {
+			t.Errorf("%s: sameParty = %v (%v), want %v", tc.name, got, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/satheeshds/portal/models"
+	"github.com/satheeshds/portal/store"
+)
+
+// ListOutlets lists all outlets
+//	@Summary		List outlets
+//	@Description	Get a list of all outlets with the number of payouts recorded for each.
+//	@Tags			outlets
+//	@Produce		json
+//	@Param			search	query		string	false	"Search by name"
+//	@Success		200		{object}	Response{data=[]models.Outlet}
+//	@Router			/outlets [get]
+//	@Security		BearerAuth
+func ListOutlets(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	outlets, err := s.ListOutlets(r.URL.Query().Get("search"))
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, outlets)
+}
+
+// GetOutlet retrieves a single outlet by ID
+//	@Summary		Get outlet
+//	@Description	Get details of a specific outlet.
+//	@Tags			outlets
+//	@Produce		json
+//	@Param			id	path		int	true	"Outlet ID"
+//	@Success		200	{object}	Response{data=models.Outlet}
+//	@Failure		400	{object}	Response{error=string}
+//	@Failure		404	{object}	Response{error=string}
+//	@Router			/outlets/{id} [get]
+//	@Security		BearerAuth
+func GetOutlet(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+	o, err := s.GetOutlet(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "outlet not found")
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, o)
+}
+
+// CreateOutlet creates a new outlet
+//	@Summary		Create outlet
+//	@Description	Register a new outlet. Names are unique, ignoring case.
+//	@Tags			outlets
+//	@Accept			json
+//	@Produce		json
+//	@Param			outlet	body		models.OutletInput	true	"Outlet contents"
+//	@Success		201		{object}	Response{data=models.Outlet}
+//	@Failure		400		{object}	Response{error=string}
+//	@Failure		409		{object}	Response{error=string}
+//	@Router			/outlets [post]
+//	@Security		BearerAuth
+func CreateOutlet(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	var input models.OutletInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if msg := input.Validate(); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	if !checkOutletNameFree(w, r, s, input.Name, 0) {
+		return
+	}
+	o, err := s.CreateOutlet(input)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, o)
+}
+
+// UpdateOutlet updates an existing outlet
+//	@Summary		Update outlet
+//	@Description	Rename an outlet. The outlet_name shown on its payouts is updated too.
+//	@Tags			outlets
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int					true	"Outlet ID"
+//	@Param			outlet	body		models.OutletInput	true	"Updated outlet contents"
+//	@Success		200		{object}	Response{data=models.Outlet}
+//	@Failure		400		{object}	Response{error=string}
+//	@Failure		404		{object}	Response{error=string}
+//	@Failure		409		{object}	Response{error=string}
+//	@Router			/outlets/{id} [put]
+//	@Security		BearerAuth
+func UpdateOutlet(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+	var input models.OutletInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if msg := input.Validate(); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	if !checkOutletNameFree(w, r, s, input.Name, id) {
+		return
+	}
+	o, err := s.UpdateOutlet(id, input)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "outlet not found")
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, o)
+}
+
+// DeleteOutlet deletes an outlet
+//	@Summary		Delete outlet
+//	@Description	Remove an outlet. Outlets that still have payouts cannot be deleted.
+//	@Tags			outlets
+//	@Produce		json
+//	@Param			id	path		int	true	"Outlet ID"
+//	@Success		200	{object}	Response{data=map[string]string}
+//	@Failure		400	{object}	Response{error=string}
+//	@Failure		404	{object}	Response{error=string}
+//	@Failure		409	{object}	Response{error=string}
+//	@Router			/outlets/{id} [delete]
+//	@Security		BearerAuth
+func DeleteOutlet(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+	o, err := s.GetOutlet(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "outlet not found")
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+	if o.Payouts > 0 {
+		writeError(w, http.StatusConflict, fmt.Sprintf("outlet has %d payout(s); move or delete them first", o.Payouts))
+		return
+	}
+	if err := s.DeleteOutlet(id); err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": "deleted"})
+}
+
+// checkOutletNameFree writes a 409 and returns false when another outlet than
+// id already uses name.
+func checkOutletNameFree(w http.ResponseWriter, r *http.Request, s *store.Store, name string, id int) bool {
+	existing, err := s.FindOutletByName(name)
+	if err == nil && existing.ID != id {
+		writeError(w, http.StatusConflict, fmt.Sprintf("outlet %q already exists (id %d)", existing.Name, existing.ID))
+		return false
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		writeInternalError(w, r, err)
+		return false
+	}
+	return true
+}
+
+// resolvePayoutOutlet fills in both outlet fields of a payout. An outlet_id
+// must refer to an existing outlet; otherwise outlet_name is matched against
+// existing outlets, ignoring case. A name that matches no outlet is rejected
+// unless create_outlet is set, in which case it is registered as a new outlet.
+// The outlet's stored name replaces the given one. It writes an error response
+// and returns false on failure.
+func resolvePayoutOutlet(w http.ResponseWriter, r *http.Request, s *store.Store, input *models.PayoutInput) bool {
+	var o models.Outlet
+	var err error
+	if input.OutletID != nil {
+		o, err = s.GetOutlet(*input.OutletID)
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("outlet %d not found", *input.OutletID))
+			return false
+		}
+	} else {
+		o, err = s.FindOutletByName(input.OutletName)
+		if errors.Is(err, sql.ErrNoRows) {
+			if !input.CreateOutlet {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("outlet %q not found; register it under /outlets or set create_outlet", input.OutletName))
+				return false
+			}
+			o, err = s.CreateOutlet(models.OutletInput{Name: input.OutletName})
+		}
+	}
+	if err != nil {
+		writeInternalError(w, r, err)
+		return false
+	}
+	input.OutletID, input.OutletName = &o.ID, o.Name
+	return true
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+// TestPayoutOutlets verifies that payouts resolve to a stable outlet, that an
+// unknown outlet_id is rejected, and that the outlet report groups by outlet.
+func TestPayoutOutlets(t *testing.T) {
+	r, cleanup := setupTestRouter(t)
+	defer cleanup()
+	r.Get("/api/v1/outlets", ListOutlets)
+	r.Post("/api/v1/outlets", CreateOutlet)
+	r.Put("/api/v1/outlets/{id}", UpdateOutlet)
+	r.Delete("/api/v1/outlets/{id}", DeleteOutlet)
+	r.Get("/api/v1/reports/outlets", GetOutletReport)
+
+	status, resp := apiRequest(t, r, "POST", "/api/v1/outlets", map[string]interface{}{"name": " Koramangala "})
+	if status != http.StatusCreated {
+		t.Fatalf("create outlet: status %d, error %v", status, resp["error"])
+	}
+	outlet := resp["data"].(map[string]interface{})
+	outletID := int(outlet["id"].(float64))
+	if outlet["name"] != "Koramangala" {
+		t.Errorf("expected trimmed name, got %q", outlet["name"])
+	}
+	if status, _ := apiRequest(t, r, "POST", "/api/v1/outlets", map[string]interface{}{"name": "koramangala"}); status != http.StatusConflict {
+		t.Errorf("duplicate outlet: expected 409, got %d", status)
+	}
+
+	if status, _ := apiRequest(t, r, "POST", "/api/v1/payouts", map[string]interface{}{
+		"outlet_id": 9999, "platform": "swiggy", "final_payout_amt": 100.0,
+	}); status != http.StatusBadRequest {
+		t.Errorf("unknown outlet_id: expected 400, got %d", status)
+	}
+
+	if status, _ := apiRequest(t, r, "POST", "/api/v1/payouts", map[string]interface{}{
+		"outlet_name": "Indiranagar", "platform": "swiggy", "final_payout_amt": 30.0,
+	}); status != http.StatusBadRequest {
+		t.Errorf("unknown outlet_name without create_outlet: expected 400, got %d", status)
+	}
+
+	for _, p := range []map[string]interface{}{
+		{"outlet_id": outletID, "platform": "swiggy", "final_payout_amt": 100.0},
+		{"outlet_name": "KORAMANGALA", "platform": "zomato", "final_payout_amt": 50.0},
+		{"outlet_name": "Indiranagar", "platform": "swiggy", "final_payout_amt": 30.0, "create_outlet": true},
+	} {
+		status, resp := apiRequest(t, r, "POST", "/api/v1/payouts", p)
+		if status != http.StatusCreated {
+			t.Fatalf("create payout: status %d, error %v", status, resp["error"])
+		}
+		if p["outlet_name"] == "KORAMANGALA" {
+			data := resp["data"].(map[string]interface{})
+			if int(data["outlet_id"].(float64)) != outletID || data["outlet_name"] != "Koramangala" {
+				t.Errorf("expected payout resolved to outlet %d, got %v/%v", outletID, data["outlet_id"], data["outlet_name"])
+			}
+		}
+	}
+
+	_, resp = apiRequest(t, r, "GET", "/api/v1/outlets", nil)
+	if outlets := resp["data"].([]interface{}); len(outlets) != 2 {
+		t.Errorf("expected Indiranagar to be registered alongside Koramangala, got %v", outlets)
+	}
+
+	if status, resp := apiRequest(t, r, "PUT", fmt.Sprintf("/api/v1/outlets/%d", outletID), map[string]interface{}{"name": "Koramangala 5th Block"}); status != http.StatusOK {
+		t.Fatalf("rename outlet: status %d, error %v", status, resp["error"])
+	}
+
+	status, resp = apiRequest(t, r, "GET", "/api/v1/reports/outlets", nil)
+	if status != http.StatusOK {
+		t.Fatalf("outlet report: status %d, error %v", status, resp["error"])
+	}
+	rows := resp["data"].([]interface{})
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 outlet rows, got %v", rows)
+	}
+	row := rows[1].(map[string]interface{})
+	if row["outlet_name"] != "Koramangala 5th Block" || row["payouts"].(float64) != 2 || row["final_payout_amt"].(float64) != 15000 {
+		t.Errorf("unexpected Koramangala row: %v", row)
+	}
+
+	if status, _ := apiRequest(t, r, "DELETE", fmt.Sprintf("/api/v1/outlets/%d", outletID), nil); status != http.StatusConflict {
+		t.Errorf("delete outlet with payouts: expected 409, got %d", status)
+	}
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/satheeshds/portal/models"
+)
+
+// pathInt parses the integer URL parameter name, such as a record's {id}. It
+// writes a 400 naming the parameter and returns false when it is not one, so
+// /transactions/abc is rejected rather than looked up as id 0 and reported
+// missing.
+func pathInt(w http.ResponseWriter, r *http.Request, name string) (int, bool) {
+	n, err := strconv.Atoi(chi.URLParam(r, name))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, name+" must be an integer")
+		return 0, false
+	}
+	return n, true
+}
+
+// queryInt parses the integer query parameter name, returning def when it is
+// absent. It writes a 400 naming the parameter and returns false when it is
+// not an integer of at least min, which must be 0 or 1.
+func queryInt(w http.ResponseWriter, r *http.Request, name string, def, min int) (int, bool) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return def, true
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < min {
+		kind := "a non-negative"
+		if min > 0 {
+			kind = "a positive"
+		}
+		writeError(w, http.StatusBadRequest, name+" must be "+kind+" integer")
+		return 0, false
+	}
+	return n, true
+}
+
+// listQuery reads the query string of a list endpoint, checking up front that
+// each parameter named in ints is an integer and normalizing each named in
+// dates to YYYY-MM-DD, so filters reach the store already valid rather than
+// failing there as a 500. It writes a 400 naming the first invalid parameter
+// and returns false when one is not valid. Absent parameters are left empty.
+func listQuery(w http.ResponseWriter, r *http.Request, ints, dates []string) (url.Values, bool) {
+	q := r.URL.Query()
+	for _, name := range ints {
+		if v := q.Get(name); v != "" {
+			if _, err := strconv.Atoi(v); err != nil {
+				writeError(w, http.StatusBadRequest, name+" must be an integer")
+				return nil, false
+			}
+		}
+	}
+	for _, name := range dates {
+		v := q.Get(name)
+		if err := normalizeQueryDate(&v); err != nil {
+			writeError(w, http.StatusBadRequest, name+": "+err.Error())
+			return nil, false
+		}
+		if v != "" {
+			q.Set(name, v)
+		}
+	}
+	return q, true
+}
+
+// normalizeQueryDate normalizes a date query parameter like
+// models.NormalizeDate and also rejects dates that do not exist, such as
+// 2024-02-30, which the format check alone lets through.
+func normalizeQueryDate(s *string) error {
+	if err := models.NormalizeDate(s); err != nil {
+		return err
+	}
+	if *s == "" {
+		return nil
+	}
+	if _, err := time.Parse("2006-01-02", *s); err != nil {
+		return fmt.Errorf("invalid date %q", *s)
+	}
+	return nil
+}
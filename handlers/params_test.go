@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestListQueryValidation verifies that list endpoints reject malformed
+// integer and date filters with a 400 naming the parameter, and accept
+// DD-MM-YYYY dates.
+func TestListQueryValidation(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+
+	bad := []struct {
+		path, param string
+	}{
+		{"/api/v1/transactions?account_id=abc", "account_id"},
+		{"/api/v1/transactions?contact_id=1.5", "contact_id"},
+		{"/api/v1/transactions?from=notadate", "from"},
+		{"/api/v1/bills?to=2024-02-30", "to"},
+		{"/api/v1/invoices?contact_id=x", "contact_id"},
+		{"/api/v1/payouts?outlet_id=x", "outlet_id"},
+		{"/api/v1/payouts?from=2024-13-01", "from"},
+		{"/api/v1/recurring-payments?account_id=x", "account_id"},
+		{"/api/v1/reports/tds?from=31-02-2024", "from"},
+	}
+	for _, tc := range bad {
+		status, resp := apiRequest(t, r, "GET", tc.path, nil)
+		if status != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", tc.path, status)
+			continue
+		}
+		if msg, _ := resp["error"].(string); !strings.HasPrefix(msg, tc.param) {
+			t.Errorf("%s: expected error naming %s, got %q", tc.path, tc.param, msg)
+		}
+	}
+
+	for _, path := range []string{
+		"/api/v1/transactions?account_id=1&from=01-01-2024&to=2024-12-31",
+		"/api/v1/bills?from=01/01/2024",
+		"/api/v1/payouts?outlet_id=1&to=2024-12-31",
+	} {
+		if status, _ := apiRequest(t, r, "GET", path, nil); status != http.StatusOK {
+			t.Errorf("%s: expected 200, got %d", path, status)
+		}
+	}
+}
+
+// TestPathIntValidation verifies that non-integer path IDs and numeric query
+// parameters are rejected with a 400 naming the parameter rather than being
+// read as 0.
+func TestPathIntValidation(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+
+	bad := []struct {
+		method, path, param string
+	}{
+		{"GET", "/api/v1/transactions/abc", "id"},
+		{"DELETE", "/api/v1/bills/1x", "id"},
+		{"GET", "/api/v1/invoices/abc/ledger", "id"},
+		{"PUT", "/api/v1/accounts/abc", "id"},
+		{"GET", "/api/v1/transactions/suspense?days=x", "days"},
+		{"GET", "/api/v1/invoices/due-soon?days=0", "days"},
+	}
+	for _, tc := range bad {
+		status, resp := apiRequest(t, r, tc.method, tc.path, nil)
+		if status != http.StatusBadRequest {
+			t.Errorf("%s %s: expected 400, got %d", tc.method, tc.path, status)
+			continue
+		}
+		if msg, _ := resp["error"].(string); !strings.HasPrefix(msg, tc.param+" must be") {
+			t.Errorf("%s %s: expected error naming %s, got %q", tc.method, tc.path, tc.param, msg)
+		}
+	}
+
+	if status, _ := apiRequest(t, r, "GET", "/api/v1/transactions/999", nil); status != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown id, got %d", status)
+	}
+}
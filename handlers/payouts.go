@@ -4,10 +4,10 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
-	"github.com/go-chi/chi/v5"
 	"github.com/satheeshds/portal/models"
 	"github.com/satheeshds/portal/store"
 )
@@ -15,49 +15,65 @@ import (
 // ListPayouts lists all payouts
 //	@Summary		List payouts
 //	@Description	Get a list of all platform payouts (Swiggy, Zomato, Swiggy-Dineout).
+//	@Description	Send Accept: text/csv for the same list as CSV.
 //	@Tags			payouts
 //	@Produce		json
-//	@Param			platform	query		string	false	"Filter by platform (Swiggy, Zomato, Swiggy-Dineout)"
-//	@Param			outlet_name	query		string	false	"Filter by outlet name"
-//	@Param			from		query		string	false	"Filter by settlement date from (YYYY-MM-DD)"
-//	@Param			to			query		string	false	"Filter by settlement date to (YYYY-MM-DD)"
-//	@Success		200			{object}	Response{data=[]models.Payout}
+//	@Produce		text/csv
+//	@Param			platform		query		string	false	"Filter by platform (Swiggy, Zomato, Swiggy-Dineout)"
+//	@Param			outlet_id		query		int		false	"Filter by outlet"
+//	@Param			outlet_name		query		string	false	"Filter by outlet name"
+//	@Param			from			query		string	false	"Filter by settlement date from (YYYY-MM-DD)"
+//	@Param			to				query		string	false	"Filter by settlement date to (YYYY-MM-DD)"
+//	@Param			unmatched		query		bool	false	"Only payouts not yet fully allocated to bank credits (within ALLOCATION_TOLERANCE_PAISE)"
+//	@Param			amount_format	query		string	false	"CSV amounts as rupees (default), rupees_grouped, or paise"
+//	@Success		200				{object}	Response{data=[]models.Payout}
+//	@Failure		400				{object}	Response{error=string}
 //	@Router			/payouts [get]
 //	@Security		BearerAuth
 func ListPayouts(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
+	q, ok := listQuery(w, r, []string{"outlet_id"}, []string{"from", "to"})
+	if !ok {
+		return
+	}
 	payouts, err := s.ListPayouts(
-		r.URL.Query().Get("platform"),
-		r.URL.Query().Get("outlet_name"),
-		r.URL.Query().Get("from"),
-		r.URL.Query().Get("to"),
+		q.Get("platform"),
+		q.Get("outlet_id"),
+		q.Get("outlet_name"),
+		q.Get("from"),
+		q.Get("to"),
+		q.Get("unmatched") == "true",
 	)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeInternalError(w, r, err)
 		return
 	}
-	writeJSON(w, http.StatusOK, payouts)
+	streamList(w, r, "payouts.csv", payoutCSVHeader, payoutCSVRecord, eachOf(payouts))
 }
 
 // GetPayout retrieves a single payout by ID
 //	@Summary		Get payout
-//	@Description	Get details of a specific platform payout.
+//	@Description	Get details of a specific platform payout, including the payments allocated to it.
 //	@Tags			payouts
 //	@Produce		json
 //	@Param			id	path		int	true	"Payout ID"
-//	@Success		200	{object}	Response{data=models.Payout}
-//	@Failure		404	{object}	Response{error=string}
+//	@Success		200	{object}	Response{data=PayoutDetail}
+//	@Failure		400	{object}	Response{error=string}
+//	@Failure		404	{object}	Response{error=string,code=string,resource=string}
 //	@Router			/payouts/{id} [get]
 //	@Security		BearerAuth
 func GetPayout(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
 	p, err := s.GetPayout(id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			writeError(w, http.StatusNotFound, "payout not found")
+			writeNotFound(w, "payout")
 		} else {
-			writeError(w, http.StatusInternalServerError, err.Error())
+			writeInternalError(w, r, err)
 		}
 		return
 	}
@@ -66,30 +82,54 @@ func GetPayout(w http.ResponseWriter, r *http.Request) {
 
 // GetPayoutLinks retrieves all transactions associated with a payout
 //	@Summary		Get payout links
-//	@Description	Get all payment transactions linked to a specific payout.
+//	@Description	Get payment transactions linked to a specific payout, oldest first unless order=desc. Supports limit/offset pagination; totals across all links are returned in headers.
 //	@Tags			payouts
 //	@Produce		json
-//	@Param			id	path		int	true	"Payout ID"
-//	@Success		200	{object}	Response{data=[]PayoutLink}
+//	@Param			id		path		int		true	"Payout ID"
+//	@Param			limit	query		int		false	"Maximum number of links to return (default DEFAULT_PAGE_SIZE, or all; at most MAX_PAGE_SIZE)"
+//	@Param			offset	query		int		false	"Number of links to skip"
+//	@Param			order	query		string	false	"Sort by created_at: asc (default) or desc"
+//	@Success		200		{object}	Response{data=[]PayoutLink}
+//	@Header			200		{int}		X-Total-Count		"Total number of links"
+//	@Header			200		{int}		X-Total-Allocated	"Total allocated across all links, in paise"
+//	@Header			200		{int}		X-Page-Size-Clamped	"Limit applied when the requested page exceeded MAX_PAGE_SIZE"
+//	@Failure		400		{object}	Response{error=string}
 //	@Router			/payouts/{id}/links [get]
 //	@Security		BearerAuth
 func GetPayoutLinks(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
-	links, err := s.GetPayoutLinks(id)
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+	page, msg := parseLinkPage(w, r)
+	if msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	links, err := s.GetPayoutLinks(id, page)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	totals, err := s.DocumentLinkTotals("payout", id)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeInternalError(w, r, err)
 		return
 	}
+	setLinkTotalHeaders(w, totals)
 	writeJSON(w, http.StatusOK, links)
 }
 
 // PayoutLink is an alias for store.PayoutLink kept here for Swagger doc references.
 type PayoutLink = store.PayoutLink
 
+// PayoutDetail is an alias for store.PayoutDetail kept here for Swagger doc references.
+type PayoutDetail = store.PayoutDetail
+
 // CreatePayout creates a new payout record
 //	@Summary		Create payout
-//	@Description	Create a new platform payout record.
+//	@Description	Create a new platform payout record. The outlet is given by outlet_id, which must exist, or by outlet_name, which is matched case-insensitively against existing outlets. A name with no match is refused with 400 unless create_outlet is true, in which case it is registered as a new outlet.
 //	@Tags			payouts
 //	@Accept			json
 //	@Produce		json
@@ -109,17 +149,21 @@ func CreatePayout(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, msg)
 		return
 	}
+	if !resolvePayoutOutlet(w, r, s, &input) {
+		return
+	}
 	p, err := s.CreatePayout(input)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeInternalError(w, r, err)
 		return
 	}
+	publishEvent(r, "created", "payout", p.ID)
 	writeJSON(w, http.StatusCreated, p)
 }
 
 // UpdatePayout updates an existing payout record
 //	@Summary		Update payout
-//	@Description	Update details of an existing platform payout record.
+//	@Description	Update details of an existing platform payout record. The outlet is resolved as on create.
 //	@Tags			payouts
 //	@Accept			json
 //	@Produce		json
@@ -132,7 +176,10 @@ func CreatePayout(w http.ResponseWriter, r *http.Request) {
 //	@Security		BearerAuth
 func UpdatePayout(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
 	var input models.PayoutInput
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid JSON")
@@ -142,15 +189,19 @@ func UpdatePayout(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, msg)
 		return
 	}
+	if !resolvePayoutOutlet(w, r, s, &input) {
+		return
+	}
 	p, err := s.UpdatePayout(id, input)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			writeError(w, http.StatusNotFound, "payout not found")
 		} else {
-			writeError(w, http.StatusInternalServerError, err.Error())
+			writeInternalError(w, r, err)
 		}
 		return
 	}
+	publishEvent(r, "updated", "payout", p.ID)
 	writeJSON(w, http.StatusOK, p)
 }
 
@@ -161,19 +212,351 @@ func UpdatePayout(w http.ResponseWriter, r *http.Request) {
 //	@Produce		json
 //	@Param			id	path		int	true	"Payout ID"
 //	@Success		200	{object}	Response{data=map[string]string}
+//	@Failure		400	{object}	Response{error=string}
 //	@Failure		404	{object}	Response{error=string}
 //	@Router			/payouts/{id} [delete]
 //	@Security		BearerAuth
 func DeletePayout(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
 	if err := s.DeletePayout(r.Context(), id); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			writeError(w, http.StatusNotFound, "payout not found")
 		} else {
-			writeError(w, http.StatusInternalServerError, err.Error())
+			writeInternalError(w, r, err)
 		}
 		return
 	}
+	publishEvent(r, "deleted", "payout", id)
 	writeJSON(w, http.StatusOK, map[string]string{"message": "deleted"})
 }
+
+// PayoutOrders is the order-level detail of a payout, with how its sums
+// compare to the payout header.
+type PayoutOrders struct {
+	Orders     []models.PayoutOrder      `json:"orders"`
+	Checks     []models.PayoutOrderCheck `json:"checks"`
+	Reconciled bool                      `json:"reconciled"` // every check within PAYOUT_ORDER_TOLERANCE_PAISE
+}
+
+// ListPayoutOrders lists the orders settled by a payout
+//	@Summary		List payout orders
+//	@Description	List the per-order detail uploaded for a payout, by order date, with checks of the order sums against the payout's gross_sales_amt, platform_commission_amt, and final_payout_amt.
+//	@Tags			payouts
+//	@Produce		json
+//	@Param			id	path		int	true	"Payout ID"
+//	@Success		200	{object}	Response{data=PayoutOrders}
+//	@Failure		400	{object}	Response{error=string}
+//	@Failure		404	{object}	Response{error=string}
+//	@Router			/payouts/{id}/orders [get]
+//	@Security		BearerAuth
+func ListPayoutOrders(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+	p, err := s.GetPayout(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeNotFound(w, "payout")
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+	orders, err := s.ListPayoutOrders(id)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	inputs := make([]models.PayoutOrderInput, len(orders))
+	for i, o := range orders {
+		inputs[i] = models.PayoutOrderInput{GrossAmt: o.GrossAmt, CommissionAmt: o.CommissionAmt, NetAmt: o.NetAmt}
+	}
+	checks, ok := models.ReconcilePayoutOrders(p.Payout, inputs, cfg.PayoutOrderTolerance)
+	writeJSON(w, http.StatusOK, PayoutOrders{Orders: orders, Checks: checks, Reconciled: ok})
+}
+
+// CreatePayoutOrders uploads the orders settled by a payout
+//	@Summary		Upload payout orders
+//	@Description	Replace the per-order detail of a payout with the given orders. The sums of the orders' gross, commission, and net amounts must match the payout's gross_sales_amt, platform_commission_amt, and final_payout_amt within PAYOUT_ORDER_TOLERANCE_PAISE (in the smallest unit, default one whole unit, e.g. 100 paise); otherwise nothing is stored and 422 is returned with the failed checks.
+//	@Tags			payouts
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int							true	"Payout ID"
+//	@Param			orders	body		models.PayoutOrdersInput	true	"Orders settled by the payout"
+//	@Success		201		{object}	Response{data=PayoutOrders}
+//	@Failure		400		{object}	Response{error=string}
+//	@Failure		404		{object}	Response{error=string}
+//	@Failure		422		{object}	Response{error=string,data=[]models.PayoutOrderCheck}
+//	@Router			/payouts/{id}/orders [post]
+//	@Security		BearerAuth
+func CreatePayoutOrders(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+	var input models.PayoutOrdersInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if msg := input.Validate(); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	p, err := s.GetPayout(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeNotFound(w, "payout")
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+	checks, ok := models.ReconcilePayoutOrders(p.Payout, input.Orders, cfg.PayoutOrderTolerance)
+	if !ok {
+		writeErrorData(w, http.StatusUnprocessableEntity, "order totals do not match the payout", checks)
+		return
+	}
+	orders, err := s.ReplacePayoutOrders(id, input.Orders)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	publishEvent(r, "updated", "payout", id)
+	writeJSON(w, http.StatusCreated, PayoutOrders{Orders: orders, Checks: checks, Reconciled: ok})
+}
+
+// SettlePayout records the variance between a payout and its bank credits
+//	@Summary		Settle a payout with a variance
+//	@Description	Record the shortfall between the payout's final amount and the transactions linked to it, such as a platform adjustment or chargeback, with the reason,
+//	@Description	so the payout stops reading as partially received. variance_amount defaults to the unallocated amount and must equal it within ALLOCATION_TOLERANCE_PAISE;
+//	@Description	0 clears a recorded variance. A posted payout can no longer be changed.
+//	@Tags			payouts
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		int							true	"Payout ID"
+//	@Param			variance	body		models.PayoutSettleInput	true	"Variance to record"
+//	@Success		200			{object}	Response{data=models.Payout}
+//	@Failure		400			{object}	Response{error=string}
+//	@Failure		404			{object}	Response{error=string}
+//	@Failure		409			{object}	Response{error=string}
+//	@Failure		422			{object}	Response{error=string}
+//	@Router			/payouts/{id}/settle [post]
+//	@Security		BearerAuth
+func SettlePayout(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+	var input models.PayoutSettleInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if msg := input.Validate(); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	p, err := s.GetPayout(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "payout not found")
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+	if posted, err := s.PayoutPosted(id); err != nil {
+		writeInternalError(w, r, err)
+		return
+	} else if posted {
+		writeError(w, http.StatusConflict, "payout is already posted")
+		return
+	}
+
+	shortfall := p.FinalPayoutAmt - p.Allocated
+	amount := shortfall
+	if input.VarianceAmount != nil {
+		amount = *input.VarianceAmount
+	}
+	if amount != 0 {
+		switch {
+		case amount < 0:
+			writeError(w, http.StatusUnprocessableEntity, "payout is over-allocated; there is no shortfall to record")
+			return
+		case len(p.Payments) == 0:
+			writeError(w, http.StatusUnprocessableEntity, "payout has no linked transactions; link the bank credit before settling")
+			return
+		case amount > shortfall+cfg.AllocationTolerance || amount+cfg.AllocationTolerance < shortfall:
+			writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("variance_amount %s does not equal the unallocated %s",
+				booksAmount(amount), booksAmount(shortfall)))
+			return
+		}
+	} else {
+		input.VarianceReason = ""
+	}
+
+	updated, err := s.SetPayoutVariance(id, amount, input.VarianceReason)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	publishEvent(r, "updated", "payout", id)
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// PayoutPosting is an alias for store.PayoutPosting kept here for Swagger doc references.
+type PayoutPosting = store.PayoutPosting
+
+// PostPayout books a payout's sales and deductions as ledger entries
+//	@Summary		Post a payout to the ledger
+//	@Description	Record the payout's gross sales as income and its restaurant discount, platform commission, TCS/TDS, and marketing charges as expenses on a clearing account, dated on the settlement date.
+//	@Description	The bank credit becomes the income leg of a transfer of the net from the clearing account, so the clearing account nets to zero and the bank balance is unchanged; it is linked to the payout for the net if not already.
+//	@Description	clearing_account_id defaults to the payout.clearing_account default and bank_transaction_id to the one transaction linked to the payout. The bank credit must be an income, not a transfer, of exactly the final payout amount
+//	@Description	less any recorded variance, which is booked as a further expense, and the gross less the deductions must equal the final payout amount. All entries are written in one transaction, and a payout can be posted once.
+//	@Description	A bank credit that is reconciled or locked is refused with 409.
+//	@Description	Each entry is tagged with the category its payout.category.<entry> default names, e.g. payout.category.commission; entries without one are left untagged.
+//	@Tags			payouts
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int						true	"Payout ID"
+//	@Param			posting	body		models.PayoutPostInput	true	"Accounts to post with"
+//	@Success		201		{object}	Response{data=PayoutPosting}
+//	@Failure		400		{object}	Response{error=string}
+//	@Failure		404		{object}	Response{error=string}
+//	@Failure		409		{object}	Response{error=string}
+//	@Failure		422		{object}	Response{error=string}
+//	@Router			/payouts/{id}/post [post]
+//	@Security		BearerAuth
+func PostPayout(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+	var input models.PayoutPostInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	p, err := s.GetPayout(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "payout not found")
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+	// PostPayout checks this again inside its transaction; checking here
+	// first reports a repost as such rather than as a bank credit that is
+	// already a transfer.
+	if posted, err := s.PayoutPosted(id); err != nil {
+		writeInternalError(w, r, err)
+		return
+	} else if posted {
+		writeError(w, http.StatusConflict, "payout is already posted")
+		return
+	}
+	if !store.PayoutBalances(p.Payout) {
+		writeError(w, http.StatusUnprocessableEntity, "gross sales less discount, commission, taxes, and marketing must equal final_payout_amt")
+		return
+	}
+
+	if input.ClearingAccountID == nil {
+		d, err := s.GetDefault(models.PayoutClearingAccountKey)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			writeInternalError(w, r, err)
+			return
+		}
+		if clearingID, err := strconv.Atoi(d.Value); err == nil {
+			input.ClearingAccountID = &clearingID
+		}
+	}
+	if input.ClearingAccountID == nil {
+		writeError(w, http.StatusBadRequest, "clearing_account_id is required when the "+models.PayoutClearingAccountKey+" default is not set")
+		return
+	}
+	if _, err := s.GetAccount(*input.ClearingAccountID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusBadRequest, "clearing account not found")
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+
+	linked := map[int]bool{}
+	for _, link := range p.Payments {
+		linked[link.TransactionID] = true
+	}
+	if input.BankTransactionID == nil {
+		if len(linked) != 1 {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("bank_transaction_id is required: the payout has %d linked transactions", len(linked)))
+			return
+		}
+		for txnID := range linked {
+			input.BankTransactionID = &txnID
+		}
+	}
+	bank, err := s.GetTransaction(*input.BankTransactionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusBadRequest, "bank transaction not found")
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+	switch {
+	case bank.Type != "income" || bank.TransferAccountID != nil:
+		writeError(w, http.StatusUnprocessableEntity, "bank transaction must be an income that is not a transfer")
+		return
+	case bank.AccountID == *input.ClearingAccountID:
+		writeError(w, http.StatusBadRequest, "bank transaction must be on an account other than the clearing account")
+		return
+	case bank.Amount != p.FinalPayoutAmt-p.VarianceAmount:
+		writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("bank transaction amount %s does not equal final_payout_amt %s less variance_amount %s",
+			bank.Amount.Format(""), p.FinalPayoutAmt.Format(""), p.VarianceAmount.Format("")))
+		return
+	case !linked[bank.ID] && bank.Unallocated < bank.Amount:
+		writeError(w, http.StatusUnprocessableEntity, "bank transaction is already allocated to other documents")
+		return
+	}
+	if !checkTransactionUnlocked(w, bank) {
+		return
+	}
+	if !checkPeriodOpen(w, r, s, p.SettlementDate.String(), bank.TransactionDate.String()) {
+		return
+	}
+
+	categories, ok := payoutCategories(w, r, s)
+	if !ok {
+		return
+	}
+
+	posting, err := s.PostPayout(p.Payout, *input.ClearingAccountID, bank, categories)
+	var conflict store.PostingConflict
+	if errors.As(err, &conflict) {
+		writeError(w, http.StatusConflict, conflict.Error())
+		return
+	}
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	for _, t := range posting.Transactions {
+		publishEvent(r, "created", "transaction", t.ID)
+	}
+	publishEvent(r, "updated", "transaction", bank.ID)
+	publishEvent(r, "updated", "payout", id)
+	writeJSON(w, http.StatusCreated, posting)
+}
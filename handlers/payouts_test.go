@@ -8,6 +8,8 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 
 	_ "github.com/duckdb/duckdb-go/v2"
@@ -17,9 +19,34 @@ import (
 
 func setupTestRouter(t *testing.T) (*chi.Mux, func()) {
 	t.Helper()
+	cleanup := openTestDB(t)
+
+	r := chi.NewRouter()
+	r.Post("/api/v1/accounts", CreateAccount)
+	r.Post("/api/v1/transactions", CreateTransaction)
+	r.Get("/api/v1/transactions/{id}/links", ListTransactionLinks)
+	r.Post("/api/v1/transactions/{id}/links", CreateTransactionLink)
+	r.Delete("/api/v1/transactions/{id}/links/{linkId}", DeleteTransactionLink)
+	r.Delete("/api/v1/transactions/{id}", DeleteTransaction)
+	r.Post("/api/v1/payouts", CreatePayout)
+	r.Get("/api/v1/payouts/{id}", GetPayout)
+	r.Get("/api/v1/payouts/{id}/links", GetPayoutLinks)
+	r.Delete("/api/v1/payouts/{id}", DeletePayout)
+	r.Get("/api/v1/payouts/{id}/orders", ListPayoutOrders)
+	r.Post("/api/v1/payouts/{id}/orders", CreatePayoutOrders)
+
+	return r, cleanup
+}
+
+// openTestDB creates a migrated DuckDB database in a temp dir and installs it
+// as the package DB. The returned func restores the previous DB and closes it.
+func openTestDB(t *testing.T) func() {
+	t.Helper()
 
 	dir := t.TempDir()
-	dbPath := filepath.Join(dir, fmt.Sprintf("test_portal_%s.db", t.Name()))
+	// Name the file "lake" so the catalog matches the lake. prefix added by
+	// the query rebinder.
+	dbPath := filepath.Join(dir, "lake.db")
 
 	rawDB, err := sql.Open("duckdb", dbPath)
 	if err != nil {
@@ -29,27 +56,48 @@ func setupTestRouter(t *testing.T) (*chi.Mux, func()) {
 	if err := db.MigrateDB(database); err != nil {
 		t.Fatalf("migrate: %v", err)
 	}
+	emulateGatewayDefaults(t, rawDB)
 
 	// Save and restore the global DB variable.
 	prevDB := DB
 	DB = database
 
-	r := chi.NewRouter()
-	r.Post("/api/v1/accounts", CreateAccount)
-	r.Post("/api/v1/transactions", CreateTransaction)
-	r.Get("/api/v1/transactions/{id}/links", ListTransactionLinks)
-	r.Post("/api/v1/transactions/{id}/links", CreateTransactionLink)
-	r.Delete("/api/v1/transactions/{id}", DeleteTransaction)
-	r.Post("/api/v1/payouts", CreatePayout)
-	r.Get("/api/v1/payouts/{id}", GetPayout)
-	r.Get("/api/v1/payouts/{id}/links", GetPayoutLinks)
-	r.Delete("/api/v1/payouts/{id}", DeletePayout)
-
-	cleanup := func() {
+	return func() {
 		DB = prevDB
 		database.Close()
 	}
-	return r, cleanup
+}
+
+// emulateGatewayDefaults adds the column defaults that the Nexus gateway
+// supplies on DuckLake: an auto-incrementing id and created_at/updated_at
+// timestamps. DuckLake tables are created without them.
+func emulateGatewayDefaults(t *testing.T, rawDB *sql.DB) {
+	t.Helper()
+	rows, err := rawDB.Query(`SELECT table_name, column_name FROM information_schema.columns
+		WHERE column_name IN ('id', 'created_at', 'updated_at') AND table_name <> 'goose_db_version'`)
+	if err != nil {
+		t.Fatalf("list columns: %v", err)
+	}
+	var stmts []string
+	for rows.Next() {
+		var table, column string
+		if err := rows.Scan(&table, &column); err != nil {
+			t.Fatalf("scan column: %v", err)
+		}
+		if column == "id" {
+			stmts = append(stmts,
+				fmt.Sprintf("CREATE SEQUENCE IF NOT EXISTS %s_id_seq", table),
+				fmt.Sprintf("ALTER TABLE %s ALTER COLUMN id SET DEFAULT nextval('%s_id_seq')", table, table))
+		} else {
+			stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET DEFAULT CURRENT_TIMESTAMP", table, column))
+		}
+	}
+	rows.Close()
+	for _, stmt := range stmts {
+		if _, err := rawDB.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
 }
 
 func apiRequest(t *testing.T, r http.Handler, method, path string, body interface{}) (int, map[string]interface{}) {
@@ -89,7 +137,7 @@ func TestPayoutLinksReturnedAfterLinking(t *testing.T) {
 
 	// Create a payout.
 	status, resp = apiRequest(t, r, "POST", "/api/v1/payouts", map[string]interface{}{
-		"outlet_name": "Test Restaurant", "platform": "swiggy",
+		"outlet_name": "Test Restaurant", "create_outlet": true, "platform": "swiggy",
 		"final_payout_amt": 100.0, "total_orders": 5,
 		"gross_sales_amt": 120.0, "restaurant_discount_amt": 5.0,
 		"platform_commission_amt": 10.0, "taxes_tcs_tds_amt": 5.0,
@@ -129,6 +177,76 @@ func TestPayoutLinksReturnedAfterLinking(t *testing.T) {
 	}
 }
 
+// TestPayoutLinkValidation verifies that payouts are linked through the same
+// input checks as bills and invoices: an unknown document_type is rejected,
+// the payout's unallocated amount caps the link, and deleting the link frees
+// the payout's allocation again.
+func TestPayoutLinkValidation(t *testing.T) {
+	r, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	_, resp := apiRequest(t, r, "POST", "/api/v1/accounts", map[string]interface{}{
+		"name": "Bank Account", "type": "bank", "opening_balance": 0,
+	})
+	accID := int(resp["data"].(map[string]interface{})["id"].(float64))
+
+	_, resp = apiRequest(t, r, "POST", "/api/v1/payouts", map[string]interface{}{
+		"outlet_name": "Test Restaurant", "create_outlet": true, "platform": "swiggy",
+		"final_payout_amt": 100.0, "total_orders": 5, "gross_sales_amt": 120.0,
+	})
+	payoutID := int(resp["data"].(map[string]interface{})["id"].(float64))
+
+	_, resp = apiRequest(t, r, "POST", "/api/v1/transactions", map[string]interface{}{
+		"account_id": accID, "type": "income", "amount": 500.0,
+		"transaction_date": "2024-01-15", "description": "Swiggy payout",
+	})
+	txnID := int(resp["data"].(map[string]interface{})["id"].(float64))
+	linksPath := fmt.Sprintf("/api/v1/transactions/%d/links", txnID)
+
+	status, resp := apiRequest(t, r, "POST", linksPath, map[string]interface{}{
+		"document_type": "settlement", "document_id": payoutID, "amount": 10.0,
+	})
+	if status != http.StatusBadRequest {
+		t.Fatalf("unknown document_type: expected 400, got %d", status)
+	}
+	if msg, _ := resp["error"].(string); !strings.Contains(msg, "payout") {
+		t.Errorf("expected the error to list payout as a document type, got %q", msg)
+	}
+
+	status, _ = apiRequest(t, r, "POST", linksPath, map[string]interface{}{
+		"document_type": "payout", "document_id": payoutID, "amount": 150.0,
+	})
+	if status != http.StatusBadRequest {
+		t.Errorf("over-allocating payout: expected 400, got %d", status)
+	}
+
+	status, resp = apiRequest(t, r, "POST", linksPath, map[string]interface{}{
+		"document_type": "payout", "document_id": payoutID, "amount": 100.0,
+	})
+	if status != http.StatusCreated {
+		t.Fatalf("create link: status %d, error %v", status, resp["error"])
+	}
+	link := resp["data"].(map[string]interface{})
+	if link["document_type"] != "payout" || int(link["document_id"].(float64)) != payoutID {
+		t.Errorf("unexpected link %v", link)
+	}
+	linkID := int(link["id"].(float64))
+
+	_, resp = apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/payouts/%d", payoutID), nil)
+	if allocated := int(resp["data"].(map[string]interface{})["allocated"].(float64)); allocated != 10000 {
+		t.Errorf("expected payout allocated 10000 paise, got %d", allocated)
+	}
+
+	status, resp = apiRequest(t, r, "DELETE", fmt.Sprintf("%s/%d", linksPath, linkID), nil)
+	if status != http.StatusOK {
+		t.Fatalf("delete link: status %d, error %v", status, resp["error"])
+	}
+	_, resp = apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/payouts/%d", payoutID), nil)
+	if allocated := int(resp["data"].(map[string]interface{})["allocated"].(float64)); allocated != 0 {
+		t.Errorf("expected payout allocated 0 after deleting the link, got %d", allocated)
+	}
+}
+
 // TestPayoutLinksEmptyAfterTransactionDeleted verifies that GET /payouts/{id}/links
 // returns an empty list after the linked transaction is deleted, and that the
 // payout's allocated amount is reset to zero (no stale allocation).
@@ -144,7 +262,7 @@ func TestPayoutLinksEmptyAfterTransactionDeleted(t *testing.T) {
 
 	// Create a payout.
 	_, resp = apiRequest(t, r, "POST", "/api/v1/payouts", map[string]interface{}{
-		"outlet_name": "Test Restaurant", "platform": "swiggy",
+		"outlet_name": "Test Restaurant", "create_outlet": true, "platform": "swiggy",
 		"final_payout_amt": 100.0, "total_orders": 5,
 		"gross_sales_amt": 120.0, "restaurant_discount_amt": 5.0,
 		"platform_commission_amt": 10.0, "taxes_tcs_tds_amt": 5.0,
@@ -209,7 +327,7 @@ func TestPayoutLinksEmptyAfterPayoutDeleted(t *testing.T) {
 	accID := int(resp["data"].(map[string]interface{})["id"].(float64))
 
 	_, resp = apiRequest(t, r, "POST", "/api/v1/payouts", map[string]interface{}{
-		"outlet_name": "Test Restaurant", "platform": "swiggy",
+		"outlet_name": "Test Restaurant", "create_outlet": true, "platform": "swiggy",
 		"final_payout_amt": 100.0, "total_orders": 5,
 		"gross_sales_amt": 120.0, "restaurant_discount_amt": 5.0,
 		"platform_commission_amt": 10.0, "taxes_tcs_tds_amt": 5.0,
@@ -249,3 +367,586 @@ func TestPayoutLinksEmptyAfterPayoutDeleted(t *testing.T) {
 		t.Errorf("expected 0 transaction links after payout deletion, got %d", len(links))
 	}
 }
+
+// TestGetPayoutIncludesPayments verifies that GET /payouts/{id} embeds the
+// linked transactions in a payments array matching the /links shape.
+func TestGetPayoutIncludesPayments(t *testing.T) {
+	r, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	_, resp := apiRequest(t, r, "POST", "/api/v1/accounts", map[string]interface{}{
+		"name": "Bank Account", "type": "bank", "opening_balance": 0,
+	})
+	accID := int(resp["data"].(map[string]interface{})["id"].(float64))
+
+	_, resp = apiRequest(t, r, "POST", "/api/v1/payouts", map[string]interface{}{
+		"outlet_name": "Test Restaurant", "create_outlet": true, "platform": "swiggy",
+		"final_payout_amt": 100.0, "total_orders": 5,
+	})
+	payoutID := int(resp["data"].(map[string]interface{})["id"].(float64))
+
+	// Before linking, payments must be an empty array rather than null.
+	status, resp := apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/payouts/%d", payoutID), nil)
+	if status != http.StatusOK {
+		t.Fatalf("get payout: status %d, error %v", status, resp["error"])
+	}
+	payments, ok := resp["data"].(map[string]interface{})["payments"].([]interface{})
+	if !ok || len(payments) != 0 {
+		t.Fatalf("expected empty payments array, got %v", resp["data"].(map[string]interface{})["payments"])
+	}
+
+	_, resp = apiRequest(t, r, "POST", "/api/v1/transactions", map[string]interface{}{
+		"account_id": accID, "type": "income", "amount": 60.0,
+		"transaction_date": "2024-01-15", "description": "Swiggy payout", "reference": "UTR1",
+	})
+	txnID := int(resp["data"].(map[string]interface{})["id"].(float64))
+
+	apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/transactions/%d/links", txnID), map[string]interface{}{
+		"document_type": "payout", "document_id": payoutID, "amount": 60.0,
+	})
+
+	status, resp = apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/payouts/%d", payoutID), nil)
+	if status != http.StatusOK {
+		t.Fatalf("get payout: status %d, error %v", status, resp["error"])
+	}
+	data := resp["data"].(map[string]interface{})
+	if data["unallocated"].(float64) != 4000 {
+		t.Errorf("expected unallocated 4000, got %v", data["unallocated"])
+	}
+	if data["allocated_pct"].(float64) != 60 {
+		t.Errorf("expected allocated_pct 60, got %v", data["allocated_pct"])
+	}
+	payments = data["payments"].([]interface{})
+	if len(payments) != 1 {
+		t.Fatalf("expected 1 payment, got %d", len(payments))
+	}
+	p := payments[0].(map[string]interface{})
+	if int(p["transaction_id"].(float64)) != txnID {
+		t.Errorf("expected transaction_id %d, got %v", txnID, p["transaction_id"])
+	}
+	if p["account_name"] != "Bank Account" || p["reference"] != "UTR1" {
+		t.Errorf("unexpected payment details: %v", p)
+	}
+}
+
+// TestAutoMatchPayout verifies that a payout with a single matching bank credit
+// is linked and reconciled, and that multiple matches are returned as candidates.
+func TestAutoMatchPayout(t *testing.T) {
+	r, cleanup := setupTestRouter(t)
+	defer cleanup()
+	r.Get("/api/v1/transactions/{id}", GetTransaction)
+	r.Post("/api/v1/payouts/{id}/auto-match", AutoMatchPayout)
+
+	_, resp := apiRequest(t, r, "POST", "/api/v1/accounts", map[string]interface{}{
+		"name": "Bank Account", "type": "bank", "opening_balance": 0,
+	})
+	accID := int(resp["data"].(map[string]interface{})["id"].(float64))
+	createPayout := func() int {
+		_, resp := apiRequest(t, r, "POST", "/api/v1/payouts", map[string]interface{}{
+			"outlet_name": "Test Restaurant", "create_outlet": true, "platform": "swiggy",
+			"final_payout_amt": 100.0, "settlement_date": "2024-01-15",
+		})
+		return int(resp["data"].(map[string]interface{})["id"].(float64))
+	}
+	createCredit := func(date string) int {
+		_, resp := apiRequest(t, r, "POST", "/api/v1/transactions", map[string]interface{}{
+			"account_id": accID, "type": "income", "amount": 100.0, "transaction_date": date,
+		})
+		return int(resp["data"].(map[string]interface{})["id"].(float64))
+	}
+
+	txnID := createCredit("2024-01-16")
+	createCredit("2024-03-01") // outside the settlement window
+	payoutID := createPayout()
+
+	status, resp := apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/payouts/%d/auto-match", payoutID), nil)
+	if status != http.StatusOK {
+		t.Fatalf("auto-match: status %d, error %v", status, resp["error"])
+	}
+	data := resp["data"].(map[string]interface{})
+	if data["matched"] != true {
+		t.Fatalf("expected a match, got %v", data)
+	}
+	if link := data["link"].(map[string]interface{}); int(link["transaction_id"].(float64)) != txnID || link["amount"].(float64) != 10000 {
+		t.Errorf("unexpected link: %v", link)
+	}
+	_, resp = apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/transactions/%d", txnID), nil)
+	if resp["data"].(map[string]interface{})["reconciled"] != true {
+		t.Errorf("expected transaction %d to be reconciled", txnID)
+	}
+
+	// The first credit is now reconciled, so two fresh ones are ambiguous.
+	createCredit("2024-01-14")
+	createCredit("2024-01-17")
+	status, resp = apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/payouts/%d/auto-match", createPayout()), nil)
+	if status != http.StatusOK {
+		t.Fatalf("auto-match: status %d, error %v", status, resp["error"])
+	}
+	data = resp["data"].(map[string]interface{})
+	if data["matched"] != false || data["link"] != nil {
+		t.Errorf("expected no match, got %v", data)
+	}
+	if c := data["candidates"].([]interface{}); len(c) != 2 {
+		t.Errorf("expected 2 candidates, got %d", len(c))
+	}
+
+	if status, _ := apiRequest(t, r, "POST", "/api/v1/payouts/9999/auto-match", nil); status != http.StatusNotFound {
+		t.Errorf("unknown payout: expected 404, got %d", status)
+	}
+}
+
+// TestPayoutLinksPagination verifies limit/offset/order on GET /payouts/{id}/links
+// and that the total headers cover every link rather than the returned page.
+func TestPayoutLinksPagination(t *testing.T) {
+	r, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	_, resp := apiRequest(t, r, "POST", "/api/v1/accounts", map[string]interface{}{
+		"name": "Bank Account", "type": "bank", "opening_balance": 0,
+	})
+	accID := int(resp["data"].(map[string]interface{})["id"].(float64))
+	_, resp = apiRequest(t, r, "POST", "/api/v1/payouts", map[string]interface{}{
+		"outlet_name": "Test Restaurant", "create_outlet": true, "platform": "swiggy", "final_payout_amt": 100.0,
+	})
+	payoutID := int(resp["data"].(map[string]interface{})["id"].(float64))
+
+	var txnIDs []int
+	for i := 0; i < 3; i++ {
+		_, resp = apiRequest(t, r, "POST", "/api/v1/transactions", map[string]interface{}{
+			"account_id": accID, "type": "income", "amount": 10.0, "transaction_date": "2024-01-15",
+		})
+		txnID := int(resp["data"].(map[string]interface{})["id"].(float64))
+		txnIDs = append(txnIDs, txnID)
+		apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/transactions/%d/links", txnID), map[string]interface{}{
+			"document_type": "payout", "document_id": payoutID, "amount": 10.0,
+		})
+	}
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("/api/v1/payouts/%d/links?limit=2&offset=0&order=desc", payoutID), nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("list links: status %d, body %s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("X-Total-Count"); got != "3" {
+		t.Errorf("expected X-Total-Count 3, got %q", got)
+	}
+	if got := w.Header().Get("X-Total-Allocated"); got != "3000" {
+		t.Errorf("expected X-Total-Allocated 3000, got %q", got)
+	}
+	var body struct {
+		Data []PayoutLink `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(body.Data) != 2 {
+		t.Fatalf("expected 2 links, got %d", len(body.Data))
+	}
+	if body.Data[0].TransactionID != txnIDs[2] || body.Data[1].TransactionID != txnIDs[1] {
+		t.Errorf("expected newest links first, got transactions %d, %d", body.Data[0].TransactionID, body.Data[1].TransactionID)
+	}
+
+	status, resp := apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/payouts/%d/links?offset=2", payoutID), nil)
+	if links := resp["data"].([]interface{}); status != http.StatusOK || len(links) != 1 ||
+		int(links[0].(map[string]interface{})["transaction_id"].(float64)) != txnIDs[2] {
+		t.Errorf("offset=2: expected the last link, got status %d, %v", status, resp["data"])
+	}
+	if status, _ := apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/payouts/%d/links?limit=0", payoutID), nil); status != http.StatusBadRequest {
+		t.Errorf("limit=0: expected 400, got %d", status)
+	}
+}
+
+// TestLinkPageSizeConfig verifies that DEFAULT_PAGE_SIZE applies without a
+// limit and that limits above MAX_PAGE_SIZE are clamped and reported.
+func TestLinkPageSizeConfig(t *testing.T) {
+	r, cleanup := setupTestRouter(t)
+	defer cleanup()
+	withTestConfig(t, Config{DefaultPageSize: 1, MaxPageSize: 2})
+
+	_, resp := apiRequest(t, r, "POST", "/api/v1/accounts", map[string]interface{}{
+		"name": "Bank Account", "type": "bank", "opening_balance": 0,
+	})
+	accID := int(resp["data"].(map[string]interface{})["id"].(float64))
+	_, resp = apiRequest(t, r, "POST", "/api/v1/payouts", map[string]interface{}{
+		"outlet_name": "Test Restaurant", "create_outlet": true, "platform": "swiggy", "final_payout_amt": 100.0,
+	})
+	payoutID := int(resp["data"].(map[string]interface{})["id"].(float64))
+	for i := 0; i < 3; i++ {
+		_, resp = apiRequest(t, r, "POST", "/api/v1/transactions", map[string]interface{}{
+			"account_id": accID, "type": "income", "amount": 10.0, "transaction_date": "2024-01-15",
+		})
+		txnID := int(resp["data"].(map[string]interface{})["id"].(float64))
+		apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/transactions/%d/links", txnID), map[string]interface{}{
+			"document_type": "payout", "document_id": payoutID, "amount": 10.0,
+		})
+	}
+
+	for _, tt := range []struct {
+		query   string
+		want    int
+		clamped string
+	}{
+		{"", 1, ""},
+		{"?limit=2", 2, ""},
+		{"?limit=50", 2, "2"},
+	} {
+		req := httptest.NewRequest("GET", fmt.Sprintf("/api/v1/payouts/%d/links%s", payoutID, tt.query), nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var body struct {
+			Data []PayoutLink `json:"data"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if len(body.Data) != tt.want {
+			t.Errorf("%q: expected %d links, got %d", tt.query, tt.want, len(body.Data))
+		}
+		if got := w.Header().Get("X-Page-Size-Clamped"); got != tt.clamped {
+			t.Errorf("%q: X-Page-Size-Clamped = %q, want %q", tt.query, got, tt.clamped)
+		}
+		if got := w.Header().Get("X-Total-Count"); got != "3" {
+			t.Errorf("%q: expected X-Total-Count 3, got %q", tt.query, got)
+		}
+	}
+}
+
+// TestPayoutOrders verifies that order-level detail is stored only when its
+// sums reconcile with the payout header within PayoutOrderTolerance, that an
+// upload replaces earlier orders, and that deleting the payout removes them.
+func TestPayoutOrders(t *testing.T) {
+	r, cleanup := setupTestRouter(t)
+	defer cleanup()
+	withTestConfig(t, Config{PayoutOrderTolerance: 100})
+
+	_, resp := apiRequest(t, r, "POST", "/api/v1/payouts", map[string]interface{}{
+		"outlet_name": "Test Restaurant", "create_outlet": true, "platform": "swiggy",
+		"gross_sales_amt": 300.0, "platform_commission_amt": 30.0, "final_payout_amt": 270.0,
+	})
+	payoutID := int(resp["data"].(map[string]interface{})["id"].(float64))
+	path := fmt.Sprintf("/api/v1/payouts/%d/orders", payoutID)
+
+	order := func(id, date string, gross, commission, net float64) map[string]interface{} {
+		return map[string]interface{}{"order_id": id, "order_date": date,
+			"gross_amt": gross, "commission_amt": commission, "net_amt": net}
+	}
+
+	// Net is 5 rupees short of the header: rejected with the failed check.
+	status, resp := apiRequest(t, r, "POST", path, map[string]interface{}{"orders": []interface{}{
+		order("A1", "2024-01-02", 100, 10, 90),
+		order("A2", "2024-01-01", 200, 20, 175),
+	}})
+	if status != http.StatusUnprocessableEntity {
+		t.Fatalf("mismatched orders: expected 422, got %d", status)
+	}
+	checks := resp["data"].([]interface{})
+	net := checks[2].(map[string]interface{})
+	if net["field"] != "final_payout_amt" || net["ok"] != false || net["difference"] != float64(-500) {
+		t.Errorf("unexpected net check %v", net)
+	}
+	_, resp = apiRequest(t, r, "GET", path, nil)
+	if n := len(resp["data"].(map[string]interface{})["orders"].([]interface{})); n != 0 {
+		t.Fatalf("expected no orders stored after 422, got %d", n)
+	}
+
+	for _, tt := range []struct {
+		name string
+		body map[string]interface{}
+	}{
+		{"empty", map[string]interface{}{"orders": []interface{}{}}},
+		{"missing order_id", map[string]interface{}{"orders": []interface{}{order("", "2024-01-01", 1, 0, 1)}}},
+		{"duplicate order_id", map[string]interface{}{"orders": []interface{}{
+			order("A1", "2024-01-01", 1, 0, 1), order("A1", "2024-01-01", 1, 0, 1)}}},
+		{"negative commission", map[string]interface{}{"orders": []interface{}{order("A1", "2024-01-01", 1, -1, 1)}}},
+	} {
+		if status, _ := apiRequest(t, r, "POST", path, tt.body); status != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", tt.name, status)
+		}
+	}
+
+	// Within the one-rupee tolerance: stored, oldest order first.
+	status, resp = apiRequest(t, r, "POST", path, map[string]interface{}{"orders": []interface{}{
+		order("A1", "2024-01-02", 100, 10, 90),
+		order("A2", "2024-01-01", 200, 20, 179.5),
+	}})
+	if status != http.StatusCreated {
+		t.Fatalf("create orders: status %d, error %v", status, resp["error"])
+	}
+	data := resp["data"].(map[string]interface{})
+	if data["reconciled"] != true {
+		t.Errorf("expected reconciled, got %v", data["checks"])
+	}
+	orders := data["orders"].([]interface{})
+	if len(orders) != 2 || orders[0].(map[string]interface{})["order_id"] != "A2" {
+		t.Fatalf("expected orders A2, A1, got %v", orders)
+	}
+	if got := orders[0].(map[string]interface{})["net_amt"]; got != float64(17950) {
+		t.Errorf("expected net_amt 17950 paise, got %v", got)
+	}
+
+	// A second upload replaces the first.
+	status, _ = apiRequest(t, r, "POST", path, map[string]interface{}{"orders": []interface{}{
+		order("B1", "2024-01-03", 300, 30, 270),
+	}})
+	if status != http.StatusCreated {
+		t.Fatalf("replace orders: status %d", status)
+	}
+	_, resp = apiRequest(t, r, "GET", path, nil)
+	data = resp["data"].(map[string]interface{})
+	if orders := data["orders"].([]interface{}); len(orders) != 1 || orders[0].(map[string]interface{})["order_id"] != "B1" {
+		t.Errorf("expected only order B1, got %v", orders)
+	}
+	if data["reconciled"] != true {
+		t.Errorf("expected reconciled, got %v", data["checks"])
+	}
+
+	if status, _ := apiRequest(t, r, "GET", "/api/v1/payouts/9999/orders", nil); status != http.StatusNotFound {
+		t.Errorf("unknown payout: expected 404, got %d", status)
+	}
+
+	apiRequest(t, r, "DELETE", fmt.Sprintf("/api/v1/payouts/%d", payoutID), nil)
+	var remaining int
+	if err := DB.QueryRow("SELECT COUNT(*) FROM payout_orders WHERE payout_id = ?", payoutID).Scan(&remaining); err != nil {
+		t.Fatalf("count orders: %v", err)
+	}
+	if remaining != 0 {
+		t.Errorf("expected orders deleted with the payout, got %d", remaining)
+	}
+}
+
+// TestPostPayout verifies that posting a payout books its gross and
+// deductions on the clearing account, tagged with their default categories,
+// turns the bank credit into a transfer from it, leaves both balances as they
+// should be, and runs only once.
+func TestPostPayout(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+
+	bank := createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": "Bank", "type": "bank", "opening_balance": 0})
+	clearing := createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": "Swiggy Clearing", "type": "bank", "opening_balance": 0})
+	payout := func(final float64) int {
+		return createResource(t, r, "/api/v1/payouts", map[string]interface{}{
+			"outlet_name": "Main", "create_outlet": true, "platform": "swiggy", "settlement_date": "2024-03-05",
+			"gross_sales_amt": 1000.0, "restaurant_discount_amt": 50.0, "platform_commission_amt": 180.0,
+			"taxes_tcs_tds_amt": 10.0, "marketing_ads_amt": 0, "final_payout_amt": final,
+		})
+	}
+	payoutID := payout(760.0)
+	credit := createResource(t, r, "/api/v1/transactions", map[string]interface{}{
+		"account_id": bank, "type": "income", "amount": 760.0, "transaction_date": "2024-03-06",
+	})
+	createResource(t, r, fmt.Sprintf("/api/v1/transactions/%d/links", credit), map[string]interface{}{
+		"document_type": "payout", "document_id": payoutID, "amount": 760.0,
+	})
+
+	path := fmt.Sprintf("/api/v1/payouts/%d/post", payoutID)
+	if status, _ := apiRequest(t, r, "POST", path, map[string]interface{}{}); status != http.StatusBadRequest {
+		t.Errorf("no clearing account: expected 400, got %d", status)
+	}
+	if status, resp := apiRequest(t, r, "PUT", "/api/v1/defaults/payout.clearing_account",
+		map[string]interface{}{"value": strconv.Itoa(clearing)}); status != http.StatusOK {
+		t.Fatalf("set default: status %d, error %v", status, resp["error"])
+	}
+	commission := createResource(t, r, "/api/v1/categories", map[string]interface{}{"name": "Platform commission", "type": "expense"})
+	if status, _ := apiRequest(t, r, "PUT", "/api/v1/defaults/payout.category.gross",
+		map[string]interface{}{"value": strconv.Itoa(commission)}); status != http.StatusBadRequest {
+		t.Errorf("expense category for gross sales: expected 400, got %d", status)
+	}
+	if status, resp := apiRequest(t, r, "PUT", "/api/v1/defaults/payout.category.commission",
+		map[string]interface{}{"value": strconv.Itoa(commission)}); status != http.StatusOK {
+		t.Fatalf("set commission category: status %d, error %v", status, resp["error"])
+	}
+
+	status, resp := apiRequest(t, r, "POST", path, map[string]interface{}{})
+	if status != http.StatusCreated {
+		t.Fatalf("post: status %d, error %v", status, resp["error"])
+	}
+	posting := resp["data"].(map[string]interface{})
+	txns := posting["transactions"].([]interface{})
+	if int(posting["bank_transaction_id"].(float64)) != credit || len(txns) != 5 {
+		t.Fatalf("expected gross, discount, commission, taxes, and settlement less the zero ads entry, got %v", posting)
+	}
+	gross, settlement := txns[0].(map[string]interface{}), txns[4].(map[string]interface{})
+	if gross["type"] != "income" || gross["amount"] != 100000.0 || gross["transaction_date"] != "2024-03-05" {
+		t.Errorf("unexpected gross entry: %v", gross)
+	}
+	if settlement["type"] != "expense" || settlement["amount"] != 76000.0 || int(settlement["transfer_account_id"].(float64)) != bank {
+		t.Errorf("unexpected settlement leg: %v", settlement)
+	}
+	if c := txns[2].(map[string]interface{})["category_id"]; c != float64(commission) {
+		t.Errorf("commission entry should be tagged with category %d, got %v", commission, c)
+	}
+	if c := gross["category_id"]; c != nil {
+		t.Errorf("gross entry without a category default should be untagged, got %v", c)
+	}
+
+	balance := func(id int) float64 {
+		_, resp := apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/accounts/%d", id), nil)
+		return resp["data"].(map[string]interface{})["balance"].(float64)
+	}
+	if got := balance(clearing); got != 0 {
+		t.Errorf("clearing account should net to zero, got %v", got)
+	}
+	if got := balance(bank); got != 76000 {
+		t.Errorf("bank balance should be unchanged at 76000, got %v", got)
+	}
+	_, resp = apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/accounts/%d/verify", clearing), nil)
+	if v := resp["data"].(map[string]interface{}); v["consistent"] != true {
+		t.Errorf("clearing account transfer legs should pair up, got %v", v)
+	}
+	_, resp = apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/payouts/%d/links", payoutID), nil)
+	if links := resp["data"].([]interface{}); len(links) != 1 {
+		t.Errorf("expected the existing link to be kept, got %v", links)
+	}
+
+	if status, _ := apiRequest(t, r, "POST", path, map[string]interface{}{}); status != http.StatusConflict {
+		t.Errorf("repost: expected 409, got %d", status)
+	}
+	unbalanced := payout(700.0)
+	if status, _ := apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/payouts/%d/post", unbalanced),
+		map[string]interface{}{"bank_transaction_id": credit}); status != http.StatusUnprocessableEntity {
+		t.Errorf("unbalanced payout: expected 422, got %d", status)
+	}
+	if status, _ := apiRequest(t, r, "POST", "/api/v1/payouts/9999/post", map[string]interface{}{}); status != http.StatusNotFound {
+		t.Errorf("missing payout: expected 404, got %d", status)
+	}
+
+	reconciledPayout := payout(760.0)
+	reconciled := createResource(t, r, "/api/v1/transactions", map[string]interface{}{
+		"account_id": bank, "type": "income", "amount": 760.0, "transaction_date": "2024-03-06",
+	})
+	if _, err := DB.Exec("UPDATE transactions SET reconciled = true WHERE id = ?", reconciled); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if status, _ := apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/payouts/%d/post", reconciledPayout),
+		map[string]interface{}{"bank_transaction_id": reconciled}); status != http.StatusConflict {
+		t.Errorf("reconciled bank credit: expected 409, got %d", status)
+	}
+	_, resp = apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/transactions/%d", reconciled), nil)
+	if leg := resp["data"].(map[string]interface{})["transfer_account_id"]; leg != nil {
+		t.Errorf("refused post should leave the credit alone, got transfer_account_id %v", leg)
+	}
+
+	lockedPayout := payout(760.0)
+	locked := createResource(t, r, "/api/v1/transactions", map[string]interface{}{
+		"account_id": bank, "type": "income", "amount": 760.0, "transaction_date": "2024-03-06",
+	})
+	if status, resp := apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/transactions/%d/lock", locked), nil); status != http.StatusOK {
+		t.Fatalf("lock: status %d, error %v", status, resp["error"])
+	}
+	if status, _ := apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/payouts/%d/post", lockedPayout),
+		map[string]interface{}{"bank_transaction_id": locked}); status != http.StatusConflict {
+		t.Errorf("locked bank credit: expected 409, got %d", status)
+	}
+	_, resp = apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/transactions/%d", locked), nil)
+	if leg := resp["data"].(map[string]interface{})["transfer_account_id"]; leg != nil {
+		t.Errorf("refused post should leave the locked credit alone, got transfer_account_id %v", leg)
+	}
+}
+
+// TestListUnmatchedPayouts verifies that unmatched=true lists only payouts
+// not yet fully allocated to bank credits.
+func TestListUnmatchedPayouts(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+
+	bank := createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": "Bank", "type": "bank", "opening_balance": 0})
+	payout := func(utr string) int {
+		return createResource(t, r, "/api/v1/payouts", map[string]interface{}{
+			"outlet_name": "Main", "create_outlet": true, "platform": "zomato", "final_payout_amt": 500.0, "utr_number": utr,
+		})
+	}
+	matched, partial, open := payout("UTR-1"), payout("UTR-2"), payout("UTR-3")
+	for id, amount := range map[int]float64{matched: 500.0, partial: 200.0} {
+		credit := createResource(t, r, "/api/v1/transactions", map[string]interface{}{
+			"account_id": bank, "type": "income", "amount": amount, "transaction_date": "2024-03-06",
+		})
+		createResource(t, r, fmt.Sprintf("/api/v1/transactions/%d/links", credit), map[string]interface{}{
+			"document_type": "payout", "document_id": id, "amount": amount,
+		})
+	}
+
+	_, resp := apiRequest(t, r, "GET", "/api/v1/payouts?unmatched=true", nil)
+	got := map[int]bool{}
+	for _, p := range resp["data"].([]interface{}) {
+		got[int(p.(map[string]interface{})["id"].(float64))] = true
+	}
+	if len(got) != 2 || !got[partial] || !got[open] {
+		t.Errorf("expected payouts %d and %d, got %v", partial, open, got)
+	}
+	if _, resp := apiRequest(t, r, "GET", "/api/v1/payouts", nil); len(resp["data"].([]interface{})) != 3 {
+		t.Errorf("expected all 3 payouts without the filter, got %v", resp["data"])
+	}
+}
+
+// TestSettlePayoutVariance verifies that settling a short-paid payout records
+// the variance, clears it from the unmatched list, reports it per platform,
+// and is booked as an expense when the payout is posted.
+func TestSettlePayoutVariance(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+
+	bank := createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": "Bank", "type": "bank", "opening_balance": 0})
+	clearing := createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": "Zomato Clearing", "type": "bank", "opening_balance": 0})
+	payoutID := createResource(t, r, "/api/v1/payouts", map[string]interface{}{
+		"outlet_name": "Main", "create_outlet": true, "platform": "zomato", "settlement_date": "2024-03-05",
+		"gross_sales_amt": 1000.0, "platform_commission_amt": 200.0, "final_payout_amt": 800.0,
+	})
+	path := fmt.Sprintf("/api/v1/payouts/%d/settle", payoutID)
+	if status, _ := apiRequest(t, r, "POST", path, map[string]interface{}{"variance_reason": "chargeback"}); status != http.StatusUnprocessableEntity {
+		t.Errorf("settle without a link: expected 422, got %d", status)
+	}
+
+	credit := createResource(t, r, "/api/v1/transactions", map[string]interface{}{
+		"account_id": bank, "type": "income", "amount": 785.0, "transaction_date": "2024-03-06",
+	})
+	createResource(t, r, fmt.Sprintf("/api/v1/transactions/%d/links", credit), map[string]interface{}{
+		"document_type": "payout", "document_id": payoutID, "amount": 785.0,
+	})
+
+	if status, _ := apiRequest(t, r, "POST", path, map[string]interface{}{}); status != http.StatusBadRequest {
+		t.Errorf("settle without a reason: expected 400, got %d", status)
+	}
+	if status, _ := apiRequest(t, r, "POST", path, map[string]interface{}{"variance_amount": 10.0, "variance_reason": "chargeback"}); status != http.StatusUnprocessableEntity {
+		t.Errorf("variance below the shortfall: expected 422, got %d", status)
+	}
+	status, resp := apiRequest(t, r, "POST", path, map[string]interface{}{"variance_reason": "chargeback"})
+	if status != http.StatusOK {
+		t.Fatalf("settle: status %d, error %v", status, resp["error"])
+	}
+	p := resp["data"].(map[string]interface{})
+	if p["variance_amount"] != 1500.0 || p["variance_reason"] != "chargeback" || p["unallocated"] != 0.0 {
+		t.Errorf("unexpected settled payout: %v", p)
+	}
+
+	_, resp = apiRequest(t, r, "GET", "/api/v1/payouts?unmatched=true", nil)
+	if list := resp["data"].([]interface{}); len(list) != 0 {
+		t.Errorf("settled payout should not be unmatched, got %v", list)
+	}
+
+	status, resp = apiRequest(t, r, "GET", "/api/v1/reports/payout-variances?from=2024-03-01", nil)
+	if status != http.StatusOK {
+		t.Fatalf("variance report: status %d, error %v", status, resp["error"])
+	}
+	report := resp["data"].(map[string]interface{})
+	rows := report["rows"].([]interface{})
+	if len(rows) != 1 || report["by_platform"].(map[string]interface{})["zomato"] != 1500.0 || report["total_variance_amount"] != 1500.0 {
+		t.Errorf("unexpected variance report: %v", report)
+	}
+
+	status, resp = apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/payouts/%d/post", payoutID), map[string]interface{}{"clearing_account_id": clearing})
+	if status != http.StatusCreated {
+		t.Fatalf("post: status %d, error %v", status, resp["error"])
+	}
+	if txns := resp["data"].(map[string]interface{})["transactions"].([]interface{}); len(txns) != 4 {
+		t.Errorf("expected gross, commission, variance, and settlement entries, got %v", txns)
+	}
+	_, resp = apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/accounts/%d", clearing), nil)
+	if got := resp["data"].(map[string]interface{})["balance"]; got != 0.0 {
+		t.Errorf("clearing account should net to zero, got %v", got)
+	}
+	if status, _ := apiRequest(t, r, "POST", path, map[string]interface{}{"variance_amount": 0}); status != http.StatusConflict {
+		t.Errorf("settle a posted payout: expected 409, got %d", status)
+	}
+}
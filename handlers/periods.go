@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/satheeshds/portal/models"
+	"github.com/satheeshds/portal/store"
+)
+
+// ListClosedPeriods lists period closes
+//	@Summary		List closed periods
+//	@Description	Get all period closes, latest first. Dates on or before the latest closed_through are locked.
+//	@Tags			periods
+//	@Produce		json
+//	@Success		200	{object}	Response{data=[]models.ClosedPeriod}
+//	@Router			/periods [get]
+//	@Security		BearerAuth
+func ListClosedPeriods(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	periods, err := s.ListClosedPeriods()
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, periods)
+}
+
+// ClosePeriod closes the books through a date
+//	@Summary		Close period
+//	@Description	Close the books up to and including the given date. Transactions dated in a closed period can no longer be created, updated, or deleted.
+//	@Tags			periods
+//	@Accept			json
+//	@Produce		json
+//	@Param			period	body		models.PeriodInput	true	"Closing date"
+//	@Success		201		{object}	Response{data=models.ClosedPeriod}
+//	@Failure		400		{object}	Response{error=string}
+//	@Router			/periods/close [post]
+//	@Security		BearerAuth
+func ClosePeriod(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	var input models.PeriodInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if msg := input.Validate(); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	p, err := s.ClosePeriod(input)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, p)
+}
+
+// ReopenPeriod reopens the books from a date
+//	@Summary		Reopen period
+//	@Description	Reopen the given date and everything after it; earlier dates stay closed. When ADMIN_API_KEY is configured the request must carry it in the X-Admin-Key header.
+//	@Tags			periods
+//	@Accept			json
+//	@Produce		json
+//	@Param			X-Admin-Key	header		string				false	"Admin API key"
+//	@Param			period		body		models.PeriodInput	true	"First date to reopen"
+//	@Success		200			{object}	Response{data=map[string]string}
+//	@Failure		400			{object}	Response{error=string}
+//	@Failure		403			{object}	Response{error=string}
+//	@Router			/periods/reopen [post]
+//	@Security		BearerAuth
+func ReopenPeriod(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+
+	s := store.New(getDB(r))
+	var input models.PeriodInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if msg := input.Validate(); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	if err := s.ReopenPeriod(*input.Date, input.Notes); err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": "reopened"})
+}
+
+// checkPeriodOpen writes a 409 and returns false when any of the given dates
+// (YYYY-MM-DD, empty for undated) falls in a closed period.
+func checkPeriodOpen(w http.ResponseWriter, r *http.Request, s *store.Store, dates ...string) bool {
+	closed, err := s.ClosedThrough()
+	if err != nil {
+		writeInternalError(w, r, err)
+		return false
+	}
+	if closed.IsZero() {
+		return true
+	}
+	through := closed.String()
+	for _, d := range dates {
+		if d != "" && d <= through {
+			writeError(w, http.StatusConflict, fmt.Sprintf("date %s falls in a closed period (closed through %s)", d, through))
+			return false
+		}
+	}
+	return true
+}
+
+// stringValue returns *p, or "" when p is nil.
+func stringValue(p *string) string {
+	if p == nil {
+		return ""
+	}
+	return *p
+}
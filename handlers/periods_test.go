@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+// TestClosedPeriodBlocksTransactionWrites verifies that transactions dated in a
+// closed period cannot be created, updated, or deleted until the period is reopened.
+func TestClosedPeriodBlocksTransactionWrites(t *testing.T) {
+	r, cleanup := setupTestRouter(t)
+	defer cleanup()
+	r.Put("/api/v1/transactions/{id}", UpdateTransaction)
+	r.Post("/api/v1/periods/close", ClosePeriod)
+	r.Post("/api/v1/periods/reopen", ReopenPeriod)
+
+	_, resp := apiRequest(t, r, "POST", "/api/v1/accounts", map[string]interface{}{
+		"name": "Bank Account", "type": "bank", "opening_balance": 0,
+	})
+	accID := int(resp["data"].(map[string]interface{})["id"].(float64))
+	txn := func(date string) map[string]interface{} {
+		return map[string]interface{}{
+			"account_id": accID, "type": "expense", "amount": 10.0, "transaction_date": date,
+		}
+	}
+
+	_, resp = apiRequest(t, r, "POST", "/api/v1/transactions", txn("2024-03-15"))
+	marchID := int(resp["data"].(map[string]interface{})["id"].(float64))
+
+	status, resp := apiRequest(t, r, "POST", "/api/v1/periods/close", map[string]interface{}{"date": "31-03-2024"})
+	if status != http.StatusCreated {
+		t.Fatalf("close period: status %d, error %v", status, resp["error"])
+	}
+	if got := resp["data"].(map[string]interface{})["closed_through"]; got != "2024-03-31" {
+		t.Errorf("expected closed_through 2024-03-31, got %v", got)
+	}
+
+	if status, _ := apiRequest(t, r, "POST", "/api/v1/transactions", txn("2024-03-31")); status != http.StatusConflict {
+		t.Errorf("create in closed period: expected 409, got %d", status)
+	}
+	if status, _ := apiRequest(t, r, "PUT", fmt.Sprintf("/api/v1/transactions/%d", marchID), txn("2024-04-02")); status != http.StatusConflict {
+		t.Errorf("move out of closed period: expected 409, got %d", status)
+	}
+	if status, _ := apiRequest(t, r, "DELETE", fmt.Sprintf("/api/v1/transactions/%d", marchID), nil); status != http.StatusConflict {
+		t.Errorf("delete in closed period: expected 409, got %d", status)
+	}
+
+	status, resp = apiRequest(t, r, "POST", "/api/v1/transactions", txn("2024-04-01"))
+	if status != http.StatusCreated {
+		t.Fatalf("create in open period: status %d, error %v", status, resp["error"])
+	}
+	aprilID := int(resp["data"].(map[string]interface{})["id"].(float64))
+	if status, _ := apiRequest(t, r, "PUT", fmt.Sprintf("/api/v1/transactions/%d", aprilID), txn("2024-03-01")); status != http.StatusConflict {
+		t.Errorf("move into closed period: expected 409, got %d", status)
+	}
+
+	// Reopen from March 1: March becomes writable, February stays closed.
+	withTestConfig(t, Config{AdminAPIKey: "secret"})
+	if status, _ := apiRequest(t, r, "POST", "/api/v1/periods/reopen", map[string]interface{}{"date": "2024-03-01"}); status != http.StatusForbidden {
+		t.Fatalf("reopen without admin key: expected 403, got %d", status)
+	}
+	withTestConfig(t, Config{})
+	if status, resp := apiRequest(t, r, "POST", "/api/v1/periods/reopen", map[string]interface{}{"date": "2024-03-01"}); status != http.StatusOK {
+		t.Fatalf("reopen: status %d, error %v", status, resp["error"])
+	}
+
+	if status, resp := apiRequest(t, r, "DELETE", fmt.Sprintf("/api/v1/transactions/%d", marchID), nil); status != http.StatusOK {
+		t.Errorf("delete after reopen: status %d, error %v", status, resp["error"])
+	}
+	if status, _ := apiRequest(t, r, "POST", "/api/v1/transactions", txn("2024-02-29")); status != http.StatusConflict {
+		t.Errorf("create before reopened date: expected 409, got %d", status)
+	}
+}
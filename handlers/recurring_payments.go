@@ -5,9 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
-	"strconv"
 
-	"github.com/go-chi/chi/v5"
 	"github.com/satheeshds/portal/models"
 	"github.com/satheeshds/portal/store"
 )
@@ -21,25 +19,23 @@ import (
 //	@Param			account_id	query		int		false	"Filter by account"
 //	@Param			type		query		string	false	"Filter by type (income, expense)"
 //	@Success		200			{object}	Response{data=[]models.RecurringPayment}
+//	@Failure		400			{object}	Response{error=string}
 //	@Router			/recurring-payments [get]
 //	@Security		BearerAuth
 func ListRecurringPayments(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	accountID := r.URL.Query().Get("account_id")
-	if accountID != "" {
-		if _, err := strconv.Atoi(accountID); err != nil {
-			writeError(w, http.StatusBadRequest, "invalid account_id")
-			return
-		}
+	q, ok := listQuery(w, r, []string{"account_id"}, nil)
+	if !ok {
+		return
 	}
 
 	payments, err := s.ListRecurringPayments(
-		r.URL.Query().Get("status"),
-		accountID,
-		r.URL.Query().Get("type"),
+		q.Get("status"),
+		q.Get("account_id"),
+		q.Get("type"),
 	)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeInternalError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, payments)
@@ -52,18 +48,22 @@ func ListRecurringPayments(w http.ResponseWriter, r *http.Request) {
 //	@Produce		json
 //	@Param			id	path		int	true	"Recurring Payment ID"
 //	@Success		200	{object}	Response{data=models.RecurringPayment}
+//	@Failure		400	{object}	Response{error=string}
 //	@Failure		404	{object}	Response{error=string}
 //	@Router			/recurring-payments/{id} [get]
 //	@Security		BearerAuth
 func GetRecurringPayment(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
 	p, err := s.GetRecurringPayment(id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			writeError(w, http.StatusNotFound, "recurring payment not found")
 		} else {
-			writeError(w, http.StatusInternalServerError, err.Error())
+			writeInternalError(w, r, err)
 		}
 		return
 	}
@@ -94,7 +94,7 @@ func CreateRecurringPayment(w http.ResponseWriter, r *http.Request) {
 	}
 	p, err := s.CreateRecurringPayment(input)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeInternalError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusCreated, p)
@@ -115,7 +115,10 @@ func CreateRecurringPayment(w http.ResponseWriter, r *http.Request) {
 //	@Security		BearerAuth
 func UpdateRecurringPayment(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
 	var input models.RecurringPaymentInput
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid JSON")
@@ -130,7 +133,7 @@ func UpdateRecurringPayment(w http.ResponseWriter, r *http.Request) {
 		if errors.Is(err, sql.ErrNoRows) {
 			writeError(w, http.StatusNotFound, "recurring payment not found")
 		} else {
-			writeError(w, http.StatusInternalServerError, err.Error())
+			writeInternalError(w, r, err)
 		}
 		return
 	}
@@ -144,17 +147,21 @@ func UpdateRecurringPayment(w http.ResponseWriter, r *http.Request) {
 //	@Produce		json
 //	@Param			id	path		int	true	"Recurring Payment ID"
 //	@Success		200	{object}	Response{data=map[string]string}
+//	@Failure		400	{object}	Response{error=string}
 //	@Failure		404	{object}	Response{error=string}
 //	@Router			/recurring-payments/{id} [delete]
 //	@Security		BearerAuth
 func DeleteRecurringPayment(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
 	if err := s.DeleteRecurringPayment(id); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			writeError(w, http.StatusNotFound, "recurring payment not found")
 		} else {
-			writeError(w, http.StatusInternalServerError, err.Error())
+			writeInternalError(w, r, err)
 		}
 		return
 	}
@@ -172,10 +179,13 @@ func DeleteRecurringPayment(w http.ResponseWriter, r *http.Request) {
 //	@Security		BearerAuth
 func GetRecurringPaymentLinks(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
 	links, err := s.GetRecurringPaymentLinks(id)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeInternalError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, links)
@@ -192,16 +202,20 @@ type RecurringPaymentLink = store.RecurringPaymentLink
 //	@Param			id		path		int		true	"Recurring Payment ID"
 //	@Param			status	query		string	false	"Filter by status (pending, paid, skipped)"
 //	@Success		200		{object}	Response{data=[]models.RecurringPaymentOccurrence}
+//	@Failure		400		{object}	Response{error=string}
 //	@Failure		404		{object}	Response{error=string}
 //	@Router			/recurring-payments/{id}/occurrences [get]
 //	@Security		BearerAuth
 func GetRecurringPaymentOccurrences(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	rpID, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	rpID, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
 
 	exists, err := s.RecurringPaymentExists(rpID)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeInternalError(w, r, err)
 		return
 	}
 	if !exists {
@@ -211,7 +225,7 @@ func GetRecurringPaymentOccurrences(w http.ResponseWriter, r *http.Request) {
 
 	occurrences, err := s.ListRecurringPaymentOccurrences(rpID, r.URL.Query().Get("status"))
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeInternalError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, occurrences)
@@ -7,6 +7,8 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+
+	"github.com/satheeshds/portal/models"
 )
 
 // stubNexusServer builds a minimal httptest.Server that simulates the nexus-control
@@ -128,6 +130,20 @@ func TestRegister_MissingFields(t *testing.T) {
 	}
 }
 
+func TestRegister_ShortPassword(t *testing.T) {
+	withTestConfig(t, Config{NexusControlURL: "http://nexus.invalid", AdminAPIKey: "key", MinPasswordLength: 8})
+
+	rec := postRegister(t, "/api/auth/register", map[string]string{
+		"org_name": "Acme", "email": "a@b.com", "password": "short",
+	})
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "at least 8 characters") {
+		t.Errorf("expected a minimum length error, got %s", rec.Body.String())
+	}
+}
+
 func TestRegister_InvalidJSON(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPost, "/api/auth/register", strings.NewReader("not-json"))
 	req.Header.Set("Content-Type", "application/json")
@@ -205,3 +221,62 @@ func TestRegister_RotationFailure(t *testing.T) {
 	}
 }
 
+// TestSeedDemoData verifies that demo data lands in an empty database with
+// its allocations applied, and that seeding again adds nothing.
+func TestSeedDemoData(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+
+	seedDemoData(DB, "demo")
+	count := func(path string) int {
+		t.Helper()
+		status, resp := apiRequest(t, r, "GET", path, nil)
+		if status != http.StatusOK {
+			t.Fatalf("GET %s: status %d, error %v", path, status, resp["error"])
+		}
+		return len(resp["data"].([]interface{}))
+	}
+	want := map[string]int{"/api/v1/accounts": 3, "/api/v1/contacts": 5, "/api/v1/bills": 3,
+		"/api/v1/invoices": 3, "/api/v1/payouts": 3, "/api/v1/transactions": 10}
+	for path, n := range want {
+		if got := count(path); got != n {
+			t.Errorf("GET %s: %d rows, want %d", path, got, n)
+		}
+	}
+
+	_, resp := apiRequest(t, r, "GET", "/api/v1/invoices?status=received", nil)
+	if invoices := resp["data"].([]interface{}); len(invoices) != 1 || invoices[0].(map[string]interface{})["invoice_number"] != "INV-0001" {
+		t.Errorf("expected INV-0001 to be received after its allocation, got %v", invoices)
+	}
+	_, resp = apiRequest(t, r, "GET", "/api/v1/bills?status=partial", nil)
+	if bills := resp["data"].([]interface{}); len(bills) != 1 {
+		t.Errorf("expected one partially paid bill, got %v", bills)
+	}
+
+	seedDemoData(DB, "demo")
+	if got := count("/api/v1/accounts"); got != 3 {
+		t.Errorf("after seeding twice: %d accounts, want 3", got)
+	}
+}
+
+// TestSeedDemoDataWholeRupees verifies that demo amounts are given in rupees,
+// so a deployment keeping whole rupees gets the same figures.
+func TestSeedDemoDataWholeRupees(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+	models.SetMinorUnits(0)
+	defer models.SetMinorUnits(models.DefaultMinorUnits)
+
+	seedDemoData(DB, "demo")
+	_, resp := apiRequest(t, r, "GET", "/api/v1/accounts", nil)
+	for _, item := range resp["data"].([]interface{}) {
+		a := item.(map[string]interface{})
+		if a["name"] == "HDFC Current Account" && a["opening_balance"] != 250000.0 {
+			t.Errorf("expected an opening balance of 250000 rupees, got %v", a["opening_balance"])
+		}
+	}
+	_, resp = apiRequest(t, r, "GET", "/api/v1/invoices?status=received", nil)
+	if invoices := resp["data"].([]interface{}); len(invoices) != 1 || invoices[0].(map[string]interface{})["tax_amount"] != 6864.0 {
+		t.Errorf("expected INV-0001 received with tax worked out in rupees, got %v", invoices)
+	}
+}
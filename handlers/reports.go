@@ -0,0 +1,433 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/satheeshds/portal/models"
+	"github.com/satheeshds/portal/store"
+)
+
+// TDSReport is an alias for store.TDSReport kept here for Swagger doc references.
+type TDSReport = store.TDSReport
+
+// GetTDSReport reports TDS/TCS withheld from platform payouts
+//	@Summary		TDS/TCS report
+//	@Description	Sum taxes_tcs_tds_amt withheld from payouts per month, platform, and outlet, for filing and claiming credit.
+//	@Description	Payouts are dated by settlement_date, or period_end when unsettled. With allocation=by_period, each payout is instead pro-rated by days
+//	@Description	across the months from period_start to period_end, and from and to bound those days.
+//	@Tags			reports
+//	@Produce		json
+//	@Param			from		query		string	false	"Start date (YYYY-MM-DD or DD-MM-YYYY)"
+//	@Param			to			query		string	false	"End date (YYYY-MM-DD or DD-MM-YYYY)"
+//	@Param			platform	query		string	false	"Filter by platform (swiggy, zomato, swiggy-dineout)"
+//	@Param			allocation	query		string	false	"settlement_date (default) or by_period"
+//	@Success		200			{object}	Response{data=TDSReport}
+//	@Failure		400			{object}	Response{error=string}
+//	@Router			/reports/tds [get]
+//	@Security		BearerAuth
+func GetTDSReport(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	from, to, ok := reportRange(w, r)
+	if !ok {
+		return
+	}
+	byPeriod, ok := payoutAllocation(w, r)
+	if !ok {
+		return
+	}
+	report, err := s.GetTDSReport(from, to, strings.ToLower(r.URL.Query().Get("platform")), byPeriod)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// OutletReportRow is an alias for store.OutletReportRow kept here for Swagger doc references.
+type OutletReportRow = store.OutletReportRow
+
+// GetOutletReport reports payout performance per outlet
+//	@Summary		Outlet performance report
+//	@Description	Sum orders, sales, deductions, and net payouts per outlet, grouped by outlet_id.
+//	@Description	Payouts are dated by settlement_date, or period_end when unsettled.
+//	@Tags			reports
+//	@Produce		json
+//	@Param			from		query		string	false	"Start date (YYYY-MM-DD or DD-MM-YYYY)"
+//	@Param			to			query		string	false	"End date (YYYY-MM-DD or DD-MM-YYYY)"
+//	@Param			platform	query		string	false	"Filter by platform (swiggy, zomato, swiggy-dineout)"
+//	@Success		200			{object}	Response{data=[]OutletReportRow}
+//	@Failure		400			{object}	Response{error=string}
+//	@Router			/reports/outlets [get]
+//	@Security		BearerAuth
+func GetOutletReport(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	from, to, ok := reportRange(w, r)
+	if !ok {
+		return
+	}
+	report, err := s.GetOutletReport(from, to, strings.ToLower(r.URL.Query().Get("platform")))
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// PayoutVarianceReport is an alias for store.PayoutVarianceReport kept here for Swagger doc references.
+type PayoutVarianceReport = store.PayoutVarianceReport
+
+// GetPayoutVarianceReport reports the variances recorded when settling payouts
+//	@Summary		Payout variance report
+//	@Description	List payouts settled with a variance (POST /payouts/{id}/settle), with the reason, and total the variances per platform.
+//	@Description	Payouts are dated by settlement_date, or period_end when unsettled.
+//	@Tags			reports
+//	@Produce		json
+//	@Param			from		query		string	false	"Start date (YYYY-MM-DD or DD-MM-YYYY)"
+//	@Param			to			query		string	false	"End date (YYYY-MM-DD or DD-MM-YYYY)"
+//	@Param			platform	query		string	false	"Filter by platform (swiggy, zomato, swiggy-dineout)"
+//	@Success		200			{object}	Response{data=PayoutVarianceReport}
+//	@Failure		400			{object}	Response{error=string}
+//	@Router			/reports/payout-variances [get]
+//	@Security		BearerAuth
+func GetPayoutVarianceReport(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	from, to, ok := reportRange(w, r)
+	if !ok {
+		return
+	}
+	report, err := s.GetPayoutVarianceReport(from, to, strings.ToLower(r.URL.Query().Get("platform")))
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// VendorSpendReport is an alias for store.VendorSpendReport kept here for Swagger doc references.
+type VendorSpendReport = store.VendorSpendReport
+
+// GetVendorSpendReport reports billed and paid amounts per vendor
+//	@Summary		Vendor spend report
+//	@Description	Sum bill amounts and the payments allocated to them per vendor contact, sorted by amount billed, with each vendor's percentage of the total billed.
+//	@Description	Bills are dated by issue_date and payments by transaction_date. Cancelled bills are excluded.
+//	@Tags			reports
+//	@Produce		json
+//	@Param			from	query		string	false	"Start date (YYYY-MM-DD or DD-MM-YYYY)"
+//	@Param			to		query		string	false	"End date (YYYY-MM-DD or DD-MM-YYYY)"
+//	@Success		200		{object}	Response{data=VendorSpendReport}
+//	@Failure		400		{object}	Response{error=string}
+//	@Router			/reports/vendor-spend [get]
+//	@Security		BearerAuth
+func GetVendorSpendReport(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	from, to, ok := reportRange(w, r)
+	if !ok {
+		return
+	}
+	report, err := s.GetVendorSpendReport(from, to)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// CommissionTrendRow is an alias for store.CommissionTrendRow kept here for Swagger doc references.
+type CommissionTrendRow = store.CommissionTrendRow
+
+// GetCommissionTrend reports the platforms' monthly take rate
+//	@Summary		Commission trend report
+//	@Description	Sum platform commission and marketing/ads against gross sales per month over the last months calendar months (including the current one),
+//	@Description	with the take rate as a percentage of gross sales. Payouts are dated by settlement_date, or period_end when unsettled. Months without payouts are omitted.
+//	@Description	With allocation=by_period, each payout is instead pro-rated by days across the months from period_start to period_end.
+//	@Tags			reports
+//	@Produce		json
+//	@Param			platform	query		string	false	"Filter by platform (swiggy, zomato, swiggy-dineout)"
+//	@Param			outlet		query		int		false	"Filter by outlet ID"
+//	@Param			months		query		int		false	"Number of months to cover (default 12, at most 120)"
+//	@Param			allocation	query		string	false	"settlement_date (default) or by_period"
+//	@Success		200			{object}	Response{data=[]CommissionTrendRow}
+//	@Failure		400			{object}	Response{error=string}
+//	@Router			/reports/commission-trend [get]
+//	@Security		BearerAuth
+func GetCommissionTrend(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	q := r.URL.Query()
+	months := 12
+	if v := q.Get("months"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 120 {
+			writeError(w, http.StatusBadRequest, "months must be between 1 and 120")
+			return
+		}
+		months = n
+	}
+	outlet := q.Get("outlet")
+	if outlet != "" {
+		if id, err := strconv.Atoi(outlet); err != nil || id <= 0 {
+			writeError(w, http.StatusBadRequest, "outlet must be an outlet ID")
+			return
+		}
+	}
+	byPeriod, ok := payoutAllocation(w, r)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	since := time.Date(now.Year(), now.Month()-time.Month(months-1), 1, 0, 0, 0, 0, time.UTC).Format("2006-01-02")
+	trend, err := s.GetCommissionTrend(since, strings.ToLower(q.Get("platform")), outlet, byPeriod)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, trend)
+}
+
+// TrialBalance is an alias for store.TrialBalance kept here for Swagger doc references.
+type TrialBalance = store.TrialBalance
+
+// GetTrialBalance reports a trial balance synthesized from the single-entry books
+//	@Summary		Trial balance
+//	@Description	List every account's balance on as_of (default today) as a debit or credit, with accounts receivable
+//	@Description	outstanding on invoices and accounts payable outstanding on bills as control accounts. The books are
+//	@Description	single-entry, so equity is derived as the balancing line and the totals net to zero. Accounts in
+//	@Description	currencies other than the books currency (BOOKS_CURRENCY, default INR) are listed under excluded and not summed.
+//	@Tags			reports
+//	@Produce		json
+//	@Param			as_of	query		string	false	"Date (YYYY-MM-DD or DD-MM-YYYY)"
+//	@Success		200		{object}	Response{data=TrialBalance}
+//	@Failure		400		{object}	Response{error=string}
+//	@Router			/reports/trial-balance [get]
+//	@Security		BearerAuth
+func GetTrialBalance(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	asOf := r.URL.Query().Get("as_of")
+	if err := normalizeQueryDate(&asOf); err != nil {
+		writeError(w, http.StatusBadRequest, "as_of: "+err.Error())
+		return
+	}
+	if asOf == "" {
+		asOf = time.Now().Format("2006-01-02")
+	}
+	tb, err := s.GetTrialBalance(asOf)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, tb)
+}
+
+// FXReport is an alias for store.FXReport kept here for Swagger doc references.
+type FXReport = store.FXReport
+
+// GetFXReport reports unrealized exchange gain or loss on foreign-currency accounts
+//	@Summary		FX gain/loss report
+//	@Description	Revalue the balance on as_of (default today) of every account not in the books currency at a rate given in
+//	@Description	rates, or else at the fx_rate.<CURRENCY> default, and compare it with its value at the rate it was recorded
+//	@Description	at: the amount-weighted average exchange_rate of the account's transfers to and from books-currency accounts.
+//	@Description	Rates are books-currency units per unit; gain_loss is positive for a gain. Currencies without a rate are
+//	@Description	listed under missing and not revalued.
+//	@Tags			reports
+//	@Produce		json
+//	@Param			as_of	query		string	false	"Date (YYYY-MM-DD or DD-MM-YYYY)"
+//	@Param			rates	query		string	false	"Rates overriding the stored ones, e.g. USD:83.1,EUR:90.2"
+//	@Success		200		{object}	Response{data=FXReport}
+//	@Failure		400		{object}	Response{error=string}
+//	@Router			/reports/fx [get]
+//	@Security		BearerAuth
+func GetFXReport(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	q := r.URL.Query()
+	asOf := q.Get("as_of")
+	if err := normalizeQueryDate(&asOf); err != nil {
+		writeError(w, http.StatusBadRequest, "as_of: "+err.Error())
+		return
+	}
+	if asOf == "" {
+		asOf = time.Now().Format("2006-01-02")
+	}
+	rates, msg := parseFXRates(q.Get("rates"))
+	if msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	report, err := s.GetFXReport(asOf, rates)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// parseFXRates parses a comma-separated list of CURRENCY:rate pairs, returning
+// an error message naming the first invalid pair.
+func parseFXRates(v string) (map[string]float64, string) {
+	rates := map[string]float64{}
+	for _, pair := range strings.Split(v, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		code, value, _ := strings.Cut(pair, ":")
+		code = strings.ToUpper(strings.TrimSpace(code))
+		rate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if !models.IsCurrencyCode(code) || err != nil || !(rate > 0) || math.IsInf(rate, 0) {
+			return nil, "rates: invalid rate " + strconv.Quote(pair) + "; want CURRENCY:rate, e.g. USD:83.1"
+		}
+		rates[code] = rate
+	}
+	return rates, ""
+}
+
+// AllocationHealth is an alias for store.AllocationHealth kept here for Swagger doc references.
+type AllocationHealth = store.AllocationHealth
+
+// GetAllocationHealth summarizes the allocations needing attention
+//	@Summary		Allocation health
+//	@Description	Count and total the allocation work outstanding: income and expense transactions with an unallocated balance
+//	@Description	(per account currency; transfer legs and payout postings are left out), bills and invoices part paid, and
+//	@Description	payouts not settled by bank credits and any variance. over_allocated_items lists bills, invoices, payouts, and
+//	@Description	transactions allocated beyond their amount. Differences within ALLOCATION_TOLERANCE_PAISE count as settled.
+//	@Tags			reports
+//	@Produce		json
+//	@Success		200	{object}	Response{data=AllocationHealth}
+//	@Router			/reports/allocation-health [get]
+//	@Security		BearerAuth
+func GetAllocationHealth(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	h, err := s.GetAllocationHealth()
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, h)
+}
+
+// GSTR1Report is an alias for store.GSTR1Report kept here for Swagger doc references.
+type GSTR1Report = store.GSTR1Report
+
+// gstr1CSVHeader names the CSV columns after the GST offline tool's invoice sheets.
+var gstr1CSVHeader = []string{"GSTIN/UIN of Recipient", "Receiver Name", "Invoice Number", "Invoice date",
+	"Invoice Value", "Place Of Supply", "Supply Type", "Section", "Rate", "Taxable Value",
+	"Integrated Tax Amount", "Central Tax Amount", "State/UT Tax Amount"}
+
+// GetGSTR1Report reports the sales register for GSTR-1 filing
+//	@Summary		GSTR-1 sales register
+//	@Description	List the invoices issued between from and to with the buyer's GSTIN, taxable value, rate, and tax, and
+//	@Description	arrange them into the b2b, b2cl, and b2cs sections of the GSTR-1 JSON schema (amounts in rupees).
+//	@Description	The business's own GSTIN is read from the business.gstin default. Draft and cancelled invoices are left
+//	@Description	out; invoices without a tax_rate are listed under excluded. format=csv returns the register as CSV.
+//	@Tags			reports
+//	@Produce		json
+//	@Produce		text/csv
+//	@Param			from			query		string	false	"Start date (YYYY-MM-DD or DD-MM-YYYY)"
+//	@Param			to				query		string	false	"End date (YYYY-MM-DD or DD-MM-YYYY)"
+//	@Param			format			query		string	false	"json (default) or csv"
+//	@Param			amount_format	query		string	false	"CSV amounts as rupees (default, e.g. 123456.78), rupees_grouped (1,23,456.78), or paise (12345678)"
+//	@Success		200				{object}	Response{data=GSTR1Report}
+//	@Failure		400				{object}	Response{error=string}
+//	@Router			/reports/gstr1 [get]
+//	@Security		BearerAuth
+func GetGSTR1Report(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	from, to, ok := reportRange(w, r)
+	if !ok {
+		return
+	}
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format != "" && format != "json" && format != "csv" {
+		writeError(w, http.StatusBadRequest, "format must be json or csv")
+		return
+	}
+	amountFormat, ok := csvAmountFormat(w, r)
+	if !ok {
+		return
+	}
+	amount := func(m models.Money) string { return amountFormat(m, models.DefaultCurrency) }
+	d, err := s.GetDefault(models.BusinessGSTINKey)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusBadRequest, "set the business's GSTIN with PUT /defaults/"+models.BusinessGSTINKey)
+		return
+	}
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	report, err := s.GetGSTR1(from, to, d.Value)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	if format != "csv" {
+		writeJSON(w, http.StatusOK, report)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="gstr1.csv"`)
+	cw := csv.NewWriter(w)
+	_ = cw.Write(gstr1CSVHeader)
+	for _, row := range report.Register {
+		gstin := ""
+		if row.GSTIN != nil {
+			gstin = *row.GSTIN
+		}
+		_ = cw.Write([]string{gstin, row.ContactName, row.InvoiceNumber, row.InvoiceDate.Format("02-Jan-2006"),
+			amount(row.InvoiceValue), row.PlaceOfSupply, row.SupplyType, row.Section,
+			strconv.FormatFloat(*row.TaxRate, 'f', -1, 64), amount(row.TaxableValue),
+			amount(row.IGST), amount(row.CGST), amount(row.SGST)})
+	}
+	cw.Flush()
+}
+
+// csvAmountFormat returns the renderer for Money columns of a CSV export named
+// by the amount_format query parameter: rupees (the default) as a plain
+// decimal, rupees_grouped with Indian digit grouping, or paise as the stored
+// integer. The renderer is given each amount's currency, whose decimal places
+// the first two follow. It writes a 400 and returns false for other values.
+// The csv package quotes grouped amounts, which contain commas.
+func csvAmountFormat(w http.ResponseWriter, r *http.Request) (func(m models.Money, currency string) string, bool) {
+	switch strings.ToLower(r.URL.Query().Get("amount_format")) {
+	case "", "rupees":
+		return models.Money.Format, true
+	case "rupees_grouped":
+		return models.Money.FormatIndian, true
+	case "paise":
+		return func(m models.Money, _ string) string { return strconv.FormatInt(int64(m), 10) }, true
+	}
+	writeError(w, http.StatusBadRequest, "amount_format must be rupees, rupees_grouped, or paise")
+	return nil, false
+}
+
+// reportRange reads and normalizes the from/to query parameters shared by
+// reports. It writes a 400 and returns false when either is not a valid date.
+func reportRange(w http.ResponseWriter, r *http.Request) (from, to string, ok bool) {
+	q, ok := listQuery(w, r, nil, []string{"from", "to"})
+	if !ok {
+		return "", "", false
+	}
+	return q.Get("from"), q.Get("to"), true
+}
+
+// payoutAllocation reads the allocation query parameter of the monthly payout
+// reports, reporting whether payouts are apportioned across their period
+// rather than dated by settlement. It writes a 400 and returns false for
+// other values.
+func payoutAllocation(w http.ResponseWriter, r *http.Request) (byPeriod, ok bool) {
+	switch strings.ToLower(r.URL.Query().Get("allocation")) {
+	case "", "settlement_date":
+		return false, true
+	case "by_period":
+		return true, true
+	}
+	writeError(w, http.StatusBadRequest, "allocation must be settlement_date or by_period")
+	return false, false
+}
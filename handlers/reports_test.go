@@ -0,0 +1,643 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestGetTDSReport verifies that TDS is grouped by month, platform, and outlet,
+// and that the date range and platform filters apply.
+func TestGetTDSReport(t *testing.T) {
+	r, cleanup := setupTestRouter(t)
+	defer cleanup()
+	r.Get("/api/v1/reports/tds", GetTDSReport)
+
+	for _, p := range []map[string]interface{}{
+		{"outlet_name": "Koramangala", "create_outlet": true, "platform": "swiggy", "settlement_date": "2024-01-10", "taxes_tcs_tds_amt": 10.0},
+		{"outlet_name": "Koramangala", "create_outlet": true, "platform": "swiggy", "settlement_date": "2024-01-20", "taxes_tcs_tds_amt": 5.0},
+		{"outlet_name": "Koramangala", "create_outlet": true, "platform": "zomato", "period_end": "2024-01-31", "taxes_tcs_tds_amt": 7.0},
+		{"outlet_name": "Indiranagar", "create_outlet": true, "platform": "swiggy", "settlement_date": "2024-02-05", "taxes_tcs_tds_amt": 3.0},
+	} {
+		p["final_payout_amt"] = 100.0
+		if status, resp := apiRequest(t, r, "POST", "/api/v1/payouts", p); status != http.StatusCreated {
+			t.Fatalf("create payout: status %d, error %v", status, resp["error"])
+		}
+	}
+
+	status, resp := apiRequest(t, r, "GET", "/api/v1/reports/tds?from=01-01-2024&to=2024-01-31", nil)
+	if status != http.StatusOK {
+		t.Fatalf("tds report: status %d, error %v", status, resp["error"])
+	}
+	data := resp["data"].(map[string]interface{})
+	rows := data["rows"].([]interface{})
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows for January, got %v", rows)
+	}
+	first := rows[0].(map[string]interface{})
+	if first["month"] != "2024-01" || first["platform"] != "swiggy" || first["payouts"].(float64) != 2 || first["taxes_tcs_tds_amt"].(float64) != 1500 {
+		t.Errorf("unexpected swiggy row: %v", first)
+	}
+	if data["total_taxes_tcs_tds_amt"].(float64) != 2200 {
+		t.Errorf("expected total 2200, got %v", data["total_taxes_tcs_tds_amt"])
+	}
+	if byPlatform := data["by_platform"].(map[string]interface{}); byPlatform["zomato"].(float64) != 700 {
+		t.Errorf("expected zomato 700, got %v", byPlatform)
+	}
+
+	_, resp = apiRequest(t, r, "GET", "/api/v1/reports/tds?platform=Swiggy", nil)
+	if total := resp["data"].(map[string]interface{})["total_taxes_tcs_tds_amt"].(float64); total != 1800 {
+		t.Errorf("expected swiggy total 1800, got %v", total)
+	}
+
+	if status, _ := apiRequest(t, r, "GET", "/api/v1/reports/tds?from=nonsense", nil); status != http.StatusBadRequest {
+		t.Errorf("invalid from: expected 400, got %d", status)
+	}
+}
+
+// TestGetCommissionTrend verifies that commission and ads are summed per month
+// as a share of gross sales, within the requested number of months.
+func TestGetCommissionTrend(t *testing.T) {
+	r, cleanup := setupTestRouter(t)
+	defer cleanup()
+	r.Get("/api/v1/reports/commission-trend", GetCommissionTrend)
+
+	now := time.Now()
+	month := func(back int) time.Time {
+		return time.Date(now.Year(), now.Month()-time.Month(back), 10, 0, 0, 0, 0, time.UTC)
+	}
+	var outletID float64
+	for _, p := range []map[string]interface{}{
+		{"outlet_name": "Koramangala", "create_outlet": true, "platform": "swiggy", "settlement_date": month(0).Format("2006-01-02"),
+			"gross_sales_amt": 1000.0, "platform_commission_amt": 180.0, "marketing_ads_amt": 20.0},
+		{"outlet_name": "Indiranagar", "create_outlet": true, "platform": "swiggy", "settlement_date": month(0).Format("2006-01-02"),
+			"gross_sales_amt": 1000.0, "platform_commission_amt": 200.0, "marketing_ads_amt": 50.0},
+		{"outlet_name": "Koramangala", "create_outlet": true, "platform": "zomato", "settlement_date": month(1).Format("2006-01-02"),
+			"gross_sales_amt": 500.0, "platform_commission_amt": 100.0},
+		{"outlet_name": "Koramangala", "create_outlet": true, "platform": "swiggy", "settlement_date": month(14).Format("2006-01-02"),
+			"gross_sales_amt": 100.0, "platform_commission_amt": 10.0},
+	} {
+		p["final_payout_amt"] = 100.0
+		status, resp := apiRequest(t, r, "POST", "/api/v1/payouts", p)
+		if status != http.StatusCreated {
+			t.Fatalf("create payout: status %d, error %v", status, resp["error"])
+		}
+		if p["outlet_name"] == "Koramangala" {
+			outletID = resp["data"].(map[string]interface{})["outlet_id"].(float64)
+		}
+	}
+
+	trend := func(query string) []interface{} {
+		status, resp := apiRequest(t, r, "GET", "/api/v1/reports/commission-trend"+query, nil)
+		if status != http.StatusOK {
+			t.Fatalf("commission trend%s: status %d, error %v", query, status, resp["error"])
+		}
+		return resp["data"].([]interface{})
+	}
+
+	rows := trend("")
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 months in the default 12, got %v", rows)
+	}
+	last, current := rows[0].(map[string]interface{}), rows[1].(map[string]interface{})
+	if last["month"] != month(1).Format("2006-01") || last["take_rate_pct"].(float64) != 20 {
+		t.Errorf("unexpected previous month: %v", last)
+	}
+	if current["month"] != now.Format("2006-01") || current["payouts"].(float64) != 2 ||
+		current["gross_sales_amt"].(float64) != 200000 || current["take_rate_pct"].(float64) != 22.5 {
+		t.Errorf("unexpected current month: %v", current)
+	}
+
+	if rows := trend("?platform=Swiggy&outlet=" + fmt.Sprint(outletID)); len(rows) != 1 || rows[0].(map[string]interface{})["take_rate_pct"].(float64) != 20 {
+		t.Errorf("swiggy at Koramangala: expected one month at 20%%, got %v", rows)
+	}
+	if rows := trend("?months=24"); len(rows) != 3 {
+		t.Errorf("months=24: expected 3 months, got %v", rows)
+	}
+	for _, q := range []string{"?months=0", "?months=x", "?outlet=abc"} {
+		if status, _ := apiRequest(t, r, "GET", "/api/v1/reports/commission-trend"+q, nil); status != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", q, status)
+		}
+	}
+}
+
+// TestPayoutReportsByPeriod verifies that allocation=by_period pro-rates a
+// payout whose period straddles a month boundary across both months by days,
+// while the default keeps it whole in its settlement month.
+func TestPayoutReportsByPeriod(t *testing.T) {
+	r, cleanup := setupTestRouter(t)
+	defer cleanup()
+	r.Get("/api/v1/reports/tds", GetTDSReport)
+	r.Get("/api/v1/reports/commission-trend", GetCommissionTrend)
+
+	// 21 Jan to 9 Feb is 20 days: 11 in January and 9 in February.
+	now := time.Now()
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for _, p := range []map[string]interface{}{
+		{"period_start": "2024-01-21", "period_end": "2024-02-09", "settlement_date": "2024-02-12",
+			"gross_sales_amt": 1000.0, "taxes_tcs_tds_amt": 20.0},
+		{"period_start": thisMonth.AddDate(0, 0, -2).Format("2006-01-02"), "period_end": thisMonth.AddDate(0, 0, 1).Format("2006-01-02"),
+			"settlement_date": thisMonth.AddDate(0, 0, 3).Format("2006-01-02"), "gross_sales_amt": 400.0, "platform_commission_amt": 80.0},
+	} {
+		p["outlet_name"], p["platform"], p["final_payout_amt"], p["create_outlet"] = "Koramangala", "swiggy", 100.0, true
+		if status, resp := apiRequest(t, r, "POST", "/api/v1/payouts", p); status != http.StatusCreated {
+			t.Fatalf("create payout: status %d, error %v", status, resp["error"])
+		}
+	}
+
+	tds := func(query string) []interface{} {
+		status, resp := apiRequest(t, r, "GET", "/api/v1/reports/tds"+query, nil)
+		if status != http.StatusOK {
+			t.Fatalf("tds report%s: status %d, error %v", query, status, resp["error"])
+		}
+		return resp["data"].(map[string]interface{})["rows"].([]interface{})
+	}
+	if rows := tds("?from=2024-01-01&to=2024-01-31"); len(rows) != 0 {
+		t.Errorf("settlement dating: expected no January rows, got %v", rows)
+	}
+	rows := tds("?from=2024-01-01&to=2024-02-29&allocation=by_period")
+	if len(rows) != 2 {
+		t.Fatalf("by_period: expected January and February rows, got %v", rows)
+	}
+	jan, feb := rows[0].(map[string]interface{}), rows[1].(map[string]interface{})
+	if jan["month"] != "2024-01" || jan["taxes_tcs_tds_amt"].(float64) != 1100 || jan["gross_sales_amt"].(float64) != 55000 {
+		t.Errorf("unexpected January share: %v", jan)
+	}
+	if feb["month"] != "2024-02" || feb["taxes_tcs_tds_amt"].(float64) != 900 || feb["payouts"].(float64) != 1 {
+		t.Errorf("unexpected February share: %v", feb)
+	}
+	// The range bounds the days apportioned, not just the months.
+	if rows := tds("?from=2024-02-01&to=2024-02-04&allocation=by_period"); len(rows) != 1 ||
+		rows[0].(map[string]interface{})["taxes_tcs_tds_amt"].(float64) != 400 {
+		t.Errorf("1-4 Feb: expected 4 of 20 days, got %v", rows)
+	}
+
+	status, resp := apiRequest(t, r, "GET", "/api/v1/reports/commission-trend?months=1", nil)
+	if rows := resp["data"].([]interface{}); status != http.StatusOK || len(rows) != 1 ||
+		rows[0].(map[string]interface{})["gross_sales_amt"].(float64) != 40000 {
+		t.Errorf("settlement dating: expected the whole payout this month, got %d %v", status, resp)
+	}
+	status, resp = apiRequest(t, r, "GET", "/api/v1/reports/commission-trend?months=2&allocation=by_period", nil)
+	if status != http.StatusOK {
+		t.Fatalf("commission trend by_period: status %d, error %v", status, resp["error"])
+	}
+	if rows := resp["data"].([]interface{}); len(rows) != 2 ||
+		rows[0].(map[string]interface{})["gross_sales_amt"].(float64) != 20000 ||
+		rows[1].(map[string]interface{})["take_rate_pct"].(float64) != 20 {
+		t.Errorf("by_period: expected the payout split evenly over two months, got %v", rows)
+	}
+
+	if status, _ := apiRequest(t, r, "GET", "/api/v1/reports/tds?allocation=weekly", nil); status != http.StatusBadRequest {
+		t.Errorf("invalid allocation: expected 400, got %d", status)
+	}
+}
+
+// TestGetVendorSpendReport verifies that bills and their allocated payments are
+// summed per vendor, ranked by amount billed, and filtered by date.
+func TestGetVendorSpendReport(t *testing.T) {
+	r, cleanup := setupContactsTestRouter(t)
+	defer cleanup()
+	r.Get("/api/v1/reports/vendor-spend", GetVendorSpendReport)
+
+	status, resp := apiRequest(t, r, "POST", "/api/v1/accounts", map[string]interface{}{
+		"name": "Current", "type": "bank",
+	})
+	if status != http.StatusCreated {
+		t.Fatalf("create account: status %d, error %v", status, resp["error"])
+	}
+	accID := int(resp["data"].(map[string]interface{})["id"].(float64))
+
+	vendor := func(name string) int {
+		status, resp := apiRequest(t, r, "POST", "/api/v1/contacts", map[string]interface{}{"name": name, "type": "vendor"})
+		if status != http.StatusCreated {
+			t.Fatalf("create contact: status %d, error %v", status, resp["error"])
+		}
+		return int(resp["data"].(map[string]interface{})["id"].(float64))
+	}
+	bill := func(contactID int, number, date string, amount float64) int {
+		status, resp := apiRequest(t, r, "POST", "/api/v1/bills", map[string]interface{}{
+			"contact_id": contactID, "bill_number": number, "issue_date": date, "amount": amount, "status": "draft",
+		})
+		if status != http.StatusCreated {
+			t.Fatalf("create bill: status %d, error %v", status, resp["error"])
+		}
+		return int(resp["data"].(map[string]interface{})["id"].(float64))
+	}
+	pay := func(billID int, date string, amount float64) {
+		status, resp := apiRequest(t, r, "POST", "/api/v1/transactions", map[string]interface{}{
+			"account_id": accID, "type": "expense", "amount": amount, "transaction_date": date,
+		})
+		if status != http.StatusCreated {
+			t.Fatalf("create transaction: status %d, error %v", status, resp["error"])
+		}
+		txnID := int(resp["data"].(map[string]interface{})["id"].(float64))
+		status, resp = apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/transactions/%d/links", txnID), map[string]interface{}{
+			"document_type": "bill", "document_id": billID, "amount": amount,
+		})
+		if status != http.StatusCreated {
+			t.Fatalf("create link: status %d, error %v", status, resp["error"])
+		}
+	}
+
+	acme, beta := vendor("Acme Supplies"), vendor("Beta Foods")
+	pay(bill(acme, "A-1", "2024-01-05", 100.0), "2024-01-10", 60.0)
+	bill(beta, "B-1", "2024-01-07", 300.0)
+	pay(bill(beta, "B-2", "2023-12-20", 50.0), "2024-01-15", 50.0)
+
+	status, resp = apiRequest(t, r, "GET", "/api/v1/reports/vendor-spend?from=2024-01-01&to=31-01-2024", nil)
+	if status != http.StatusOK {
+		t.Fatalf("vendor spend: status %d, error %v", status, resp["error"])
+	}
+	data := resp["data"].(map[string]interface{})
+	rows := data["rows"].([]interface{})
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 vendors, got %v", rows)
+	}
+	first, second := rows[0].(map[string]interface{}), rows[1].(map[string]interface{})
+	if first["contact_name"] != "Beta Foods" || first["billed"].(float64) != 30000 || first["paid"].(float64) != 5000 || first["pct_of_total"].(float64) != 75 {
+		t.Errorf("unexpected first row: %v", first)
+	}
+	if second["contact_name"] != "Acme Supplies" || second["bills"].(float64) != 1 || second["paid"].(float64) != 6000 || second["pct_of_total"].(float64) != 25 {
+		t.Errorf("unexpected second row: %v", second)
+	}
+	if data["total_billed"].(float64) != 40000 || data["total_paid"].(float64) != 11000 {
+		t.Errorf("unexpected totals: %v", data)
+	}
+
+	if status, _ := apiRequest(t, r, "GET", "/api/v1/reports/vendor-spend?to=nonsense", nil); status != http.StatusBadRequest {
+		t.Errorf("invalid to: expected 400, got %d", status)
+	}
+}
+
+// TestGetTrialBalance verifies that account balances, receivables, and
+// payables on as_of are split into debits and credits, that foreign-currency
+// accounts are left out, and that derived equity balances the totals.
+func TestGetTrialBalance(t *testing.T) {
+	r, cleanup := setupContactsTestRouter(t)
+	defer cleanup()
+	r.Post("/api/v1/invoices", CreateInvoice)
+	r.Get("/api/v1/reports/trial-balance", GetTrialBalance)
+
+	account := func(body map[string]interface{}) int {
+		status, resp := apiRequest(t, r, "POST", "/api/v1/accounts", body)
+		if status != http.StatusCreated {
+			t.Fatalf("create account: status %d, error %v", status, resp["error"])
+		}
+		return int(resp["data"].(map[string]interface{})["id"].(float64))
+	}
+	create := func(path string, body map[string]interface{}) int {
+		status, resp := apiRequest(t, r, "POST", path, body)
+		if status != http.StatusCreated {
+			t.Fatalf("POST %s: status %d, error %v", path, status, resp["error"])
+		}
+		return int(resp["data"].(map[string]interface{})["id"].(float64))
+	}
+	current := account(map[string]interface{}{"name": "Current", "type": "bank", "opening_balance": 1000.0})
+	card := account(map[string]interface{}{"name": "Card", "type": "credit_card"})
+	account(map[string]interface{}{"name": "Dollars", "type": "bank", "currency": "USD", "opening_balance": 500.0})
+
+	income := create("/api/v1/transactions", map[string]interface{}{"account_id": current, "type": "income", "amount": 200.0, "transaction_date": "2024-01-10"})
+	create("/api/v1/transactions", map[string]interface{}{"account_id": current, "type": "expense", "amount": 50.0, "transaction_date": "2024-02-10"})
+	create("/api/v1/transactions", map[string]interface{}{"account_id": card, "type": "expense", "amount": 300.0, "transaction_date": "2024-01-05"})
+	invoice := create("/api/v1/invoices", map[string]interface{}{"invoice_number": "INV-1", "issue_date": "2024-01-01", "amount": 400.0, "status": "sent"})
+	create("/api/v1/bills", map[string]interface{}{"bill_number": "BILL-1", "issue_date": "2024-01-02", "amount": 150.0, "status": "draft"})
+	create(fmt.Sprintf("/api/v1/transactions/%d/links", income), map[string]interface{}{"document_type": "invoice", "document_id": invoice, "amount": 100.0})
+
+	status, resp := apiRequest(t, r, "GET", "/api/v1/reports/trial-balance?as_of=31-01-2024", nil)
+	if status != http.StatusOK {
+		t.Fatalf("trial balance: status %d, error %v", status, resp["error"])
+	}
+	data := resp["data"].(map[string]interface{})
+	if data["as_of"] != "2024-01-31" {
+		t.Errorf("as_of = %v, want 2024-01-31", data["as_of"])
+	}
+	want := map[string][2]float64{
+		"Card":                {0, 30000},
+		"Current":             {120000, 0},
+		"Accounts receivable": {30000, 0},
+		"Accounts payable":    {0, 15000},
+		"Equity (derived)":    {0, 105000},
+	}
+	rows := data["rows"].([]interface{})
+	if len(rows) != len(want) {
+		t.Fatalf("expected %d rows, got %v", len(want), rows)
+	}
+	for _, item := range rows {
+		row := item.(map[string]interface{})
+		name := row["name"].(string)
+		if w, ok := want[name]; !ok || row["debit"] != w[0] || row["credit"] != w[1] {
+			t.Errorf("row %s: debit %v credit %v, want %v", name, row["debit"], row["credit"], want[name])
+		}
+	}
+	if excluded := data["excluded"].([]interface{}); len(excluded) != 1 || excluded[0].(map[string]interface{})["currency"] != "USD" {
+		t.Errorf("expected the USD account to be excluded, got %v", excluded)
+	}
+	if data["total_debit"] != 150000.0 || data["total_credit"] != 150000.0 || data["difference"] != 0.0 {
+		t.Errorf("unexpected totals: debit %v credit %v difference %v", data["total_debit"], data["total_credit"], data["difference"])
+	}
+
+	if status, _ := apiRequest(t, r, "GET", "/api/v1/reports/trial-balance?as_of=nonsense", nil); status != http.StatusBadRequest {
+		t.Errorf("invalid as_of: expected 400, got %d", status)
+	}
+}
+
+// TestGetGSTR1Report verifies that invoices are split into the b2b, b2cl, and
+// b2cs sections with CGST/SGST or IGST by place of supply, that untaxed
+// invoices are excluded, and that format=csv returns the register.
+func TestGetGSTR1Report(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+
+	if status, _ := apiRequest(t, r, "GET", "/api/v1/reports/gstr1", nil); status != http.StatusBadRequest {
+		t.Errorf("without business.gstin: expected 400, got %d", status)
+	}
+	if status, _ := apiRequest(t, r, "PUT", "/api/v1/defaults/business.gstin", map[string]interface{}{"value": "27AAPFU0939"}); status != http.StatusBadRequest {
+		t.Errorf("invalid business.gstin: expected 400, got %d", status)
+	}
+	if status, resp := apiRequest(t, r, "PUT", "/api/v1/defaults/business.gstin", map[string]interface{}{"value": "27aapfu0939f1zv"}); status != http.StatusOK {
+		t.Fatalf("set business.gstin: status %d, error %v", status, resp["error"])
+	}
+	if status, _ := apiRequest(t, r, "POST", "/api/v1/contacts", map[string]interface{}{"name": "Bad", "type": "customer", "gstin": "27AABCS1429"}); status != http.StatusBadRequest {
+		t.Errorf("invalid contact gstin: expected 400, got %d", status)
+	}
+
+	local := createResource(t, r, "/api/v1/contacts", map[string]interface{}{"name": "Local Traders", "type": "customer", "gstin": "27AABCS1429B1ZU"})
+	remote := createResource(t, r, "/api/v1/contacts", map[string]interface{}{"name": "Remote Retail", "type": "customer", "gstin": "29AAGCR4375J1ZU"})
+	walkIn := createResource(t, r, "/api/v1/contacts", map[string]interface{}{"name": "Walk-in", "type": "customer"})
+	invoice := func(body map[string]interface{}) {
+		if body["status"] == nil {
+			body["status"] = "sent"
+		}
+		createResource(t, r, "/api/v1/invoices", body)
+	}
+	invoice(map[string]interface{}{"contact_id": local, "invoice_number": "INV-1", "issue_date": "2024-07-05", "amount": 1180.0, "tax_rate": 18.0})
+	invoice(map[string]interface{}{"contact_id": remote, "invoice_number": "INV-2", "issue_date": "2024-07-06", "amount": 2360.0, "tax_rate": 18.0, "tax_amount": 360.0})
+	invoice(map[string]interface{}{"contact_id": walkIn, "invoice_number": "INV-3", "issue_date": "2024-07-20", "amount": 300000.0, "tax_rate": 12.0, "place_of_supply": "29"})
+	invoice(map[string]interface{}{"invoice_number": "INV-4", "issue_date": "2024-07-21", "amount": 590.0, "tax_rate": 18.0})
+	invoice(map[string]interface{}{"invoice_number": "INV-5", "issue_date": "2024-07-22", "amount": 100.0})
+	invoice(map[string]interface{}{"invoice_number": "INV-6", "issue_date": "2024-07-23", "amount": 100.0, "tax_rate": 18.0, "status": "draft"})
+	invoice(map[string]interface{}{"invoice_number": "INV-7", "issue_date": "2024-08-02", "amount": 100.0, "tax_rate": 18.0})
+
+	status, resp := apiRequest(t, r, "GET", "/api/v1/reports/gstr1?from=2024-07-01&to=31-07-2024", nil)
+	if status != http.StatusOK {
+		t.Fatalf("gstr1: status %d, error %v", status, resp["error"])
+	}
+	data := resp["data"].(map[string]interface{})
+	ret := data["gstr1"].(map[string]interface{})
+	if ret["gstin"] != "27AAPFU0939F1ZV" || ret["fp"] != "072024" {
+		t.Errorf("gstin %v fp %v, want 27AAPFU0939F1ZV and 072024", ret["gstin"], ret["fp"])
+	}
+	if n := len(data["register"].([]interface{})); n != 4 {
+		t.Errorf("expected 4 register rows, got %d", n)
+	}
+	if excluded := data["excluded"].([]interface{}); len(excluded) != 1 || excluded[0].(map[string]interface{})["invoice_number"] != "INV-5" {
+		t.Errorf("expected INV-5 to be excluded, got %v", excluded)
+	}
+	// 18000 + 36000 + 3214286 + 9000 paise.
+	if data["total_tax"] != 3277286.0 {
+		t.Errorf("total_tax = %v, want 3277286", data["total_tax"])
+	}
+
+	b2b := ret["b2b"].([]interface{})
+	if len(b2b) != 2 {
+		t.Fatalf("expected 2 b2b buyers, got %v", b2b)
+	}
+	inv := b2b[0].(map[string]interface{})["inv"].([]interface{})[0].(map[string]interface{})
+	det := inv["itms"].([]interface{})[0].(map[string]interface{})["itm_det"].(map[string]interface{})
+	if inv["inum"] != "INV-1" || inv["idt"] != "05-07-2024" || inv["pos"] != "27" ||
+		det["txval"] != 1000.0 || det["camt"] != 90.0 || det["samt"] != 90.0 || det["iamt"] != 0.0 {
+		t.Errorf("unexpected intra-state b2b invoice: %v", inv)
+	}
+	inv = b2b[1].(map[string]interface{})["inv"].([]interface{})[0].(map[string]interface{})
+	det = inv["itms"].([]interface{})[0].(map[string]interface{})["itm_det"].(map[string]interface{})
+	if inv["pos"] != "29" || det["iamt"] != 360.0 || det["camt"] != 0.0 {
+		t.Errorf("unexpected inter-state b2b invoice: %v", inv)
+	}
+	b2cl := ret["b2cl"].([]interface{})
+	if len(b2cl) != 1 || b2cl[0].(map[string]interface{})["pos"] != "29" {
+		t.Errorf("expected INV-3 under b2cl for state 29, got %v", b2cl)
+	}
+	b2cs := ret["b2cs"].([]interface{})
+	if len(b2cs) != 1 {
+		t.Fatalf("expected 1 b2cs line, got %v", b2cs)
+	}
+	if line := b2cs[0].(map[string]interface{}); line["sply_ty"] != "INTRA" || line["pos"] != "27" || line["txval"] != 500.0 || line["camt"] != 45.0 {
+		t.Errorf("unexpected b2cs line: %v", line)
+	}
+
+	req := httptest.NewRequest("GET", "/api/v1/reports/gstr1?from=2024-07-01&to=2024-07-31&format=csv", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "text/csv" {
+		t.Fatalf("csv: status %d, content type %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("expected a header and 4 rows, got %q", lines)
+	}
+	if want := "27AABCS1429B1ZU,Local Traders,INV-1,05-Jul-2024,1180.00,27,INTRA,b2b,18,1000.00,0.00,90.00,90.00"; lines[1] != want {
+		t.Errorf("csv row = %q, want %q", lines[1], want)
+	}
+
+	csvRow := func(amountFormat string) (int, string) {
+		t.Helper()
+		req := httptest.NewRequest("GET", "/api/v1/reports/gstr1?from=2024-07-01&to=2024-07-31&format=csv&amount_format="+amountFormat, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		if w.Code != http.StatusOK || len(lines) < 2 || !strings.HasPrefix(lines[0], "GSTIN/UIN of Recipient,") {
+			return w.Code, ""
+		}
+		return w.Code, lines[1]
+	}
+	if _, row := csvRow("paise"); row != "27AABCS1429B1ZU,Local Traders,INV-1,05-Jul-2024,118000,27,INTRA,b2b,18,100000,0,9000,9000" {
+		t.Errorf("paise csv row = %q", row)
+	}
+	if _, row := csvRow("rupees_grouped"); row != `27AABCS1429B1ZU,Local Traders,INV-1,05-Jul-2024,"1,180.00",27,INTRA,b2b,18,"1,000.00",0.00,90.00,90.00` {
+		t.Errorf("grouped csv row = %q", row)
+	}
+	if status, _ := csvRow("cents"); status != http.StatusBadRequest {
+		t.Errorf("unknown amount_format: expected 400, got %d", status)
+	}
+}
+
+// TestGetFXReport verifies that foreign-currency balances are revalued at a
+// supplied or stored rate against the rate recorded on transfers from the
+// books currency, and that currencies without a rate are reported missing.
+func TestGetFXReport(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+
+	current := createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": "Current", "type": "bank", "opening_balance": 10000.0})
+	dollars := createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": "Dollars", "type": "bank", "currency": "USD"})
+	createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": "Euros", "type": "bank", "currency": "EUR", "opening_balance": 100.0})
+	createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": "Pounds", "type": "bank", "currency": "GBP", "opening_balance": 10.0})
+
+	// 8,300 rupees buys 100 dollars, recording a rate of 83; 40 dollars are spent.
+	createResource(t, r, "/api/v1/transactions", map[string]interface{}{"account_id": current, "type": "transfer", "transfer_account_id": dollars,
+		"amount": 8300.0, "exchange_rate": 1.0 / 83, "transaction_date": "2024-02-01"})
+	createResource(t, r, "/api/v1/transactions", map[string]interface{}{"account_id": dollars, "type": "expense", "amount": 40.0, "transaction_date": "2024-02-05"})
+
+	if status, resp := apiRequest(t, r, "PUT", "/api/v1/defaults/fx_rate.EUR", map[string]interface{}{"value": "90"}); status != http.StatusOK {
+		t.Fatalf("set stored rate: status %d, error %v", status, resp["error"])
+	}
+	if status, _ := apiRequest(t, r, "PUT", "/api/v1/defaults/fx_rate.EUR", map[string]interface{}{"value": "-1"}); status != http.StatusBadRequest {
+		t.Errorf("negative stored rate: expected 400, got %d", status)
+	}
+
+	status, resp := apiRequest(t, r, "GET", "/api/v1/reports/fx?as_of=31-03-2024&rates=usd:85", nil)
+	if status != http.StatusOK {
+		t.Fatalf("fx report: status %d, error %v", status, resp["error"])
+	}
+	data := resp["data"].(map[string]interface{})
+	if data["as_of"] != "2024-03-31" || data["books_currency"] != "INR" {
+		t.Errorf("as_of %v books_currency %v, want 2024-03-31 and INR", data["as_of"], data["books_currency"])
+	}
+	rows := data["rows"].([]interface{})
+	if len(rows) != 3 {
+		t.Fatalf("expected a row per foreign account, got %v", rows)
+	}
+	want := []struct {
+		currency                      string
+		balance, book, revalued, gain float64
+		recorded                      interface{}
+		source                        string
+	}{
+		{"EUR", 10000, 900000, 900000, 0, nil, "stored"},
+		{"GBP", 1000, 0, 0, 0, nil, ""},
+		{"USD", 6000, 498000, 510000, 12000, 83.0, "supplied"},
+	}
+	for i, w := range want {
+		row := rows[i].(map[string]interface{})
+		if row["currency"] != w.currency || row["balance"] != w.balance || row["book_value"] != w.book ||
+			row["revalued_value"] != w.revalued || row["gain_loss"] != w.gain || row["recorded_rate"] != w.recorded || row["rate_source"] != w.source {
+			t.Errorf("row %d = %v, want %+v", i, row, w)
+		}
+	}
+	if data["total_gain_loss"] != 12000.0 {
+		t.Errorf("total_gain_loss = %v, want 12000", data["total_gain_loss"])
+	}
+	if missing := data["missing"].([]interface{}); len(missing) != 1 || missing[0] != "GBP" {
+		t.Errorf("missing = %v, want [GBP]", missing)
+	}
+
+	// Before the transfer the dollar account is empty and has no recorded rate.
+	status, resp = apiRequest(t, r, "GET", "/api/v1/reports/fx?as_of=2024-01-31&rates=USD:85", nil)
+	if status != http.StatusOK {
+		t.Fatalf("fx report before transfer: status %d, error %v", status, resp["error"])
+	}
+	usd := resp["data"].(map[string]interface{})["rows"].([]interface{})[2].(map[string]interface{})
+	if usd["balance"] != 0.0 || usd["recorded_rate"] != nil {
+		t.Errorf("USD row before transfer = %v, want an empty balance without a recorded rate", usd)
+	}
+
+	for _, path := range []string{"/api/v1/reports/fx?rates=USD", "/api/v1/reports/fx?rates=USD:0", "/api/v1/reports/fx?as_of=nonsense"} {
+		if status, _ := apiRequest(t, r, "GET", path, nil); status != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", path, status)
+		}
+	}
+}
+
+// TestGetFXReportMinorUnits verifies that recorded rates and book values are
+// worked out per whole unit for a currency without decimal places.
+func TestGetFXReportMinorUnits(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+
+	current := createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": "Current", "type": "bank", "opening_balance": 10000.0})
+	var yen int
+	if err := DB.QueryRow("INSERT INTO accounts (name, type, currency, opening_balance) VALUES ('Yen', 'bank', 'JPY', 0) RETURNING id").Scan(&yen); err != nil {
+		t.Fatalf("insert yen account: %v", err)
+	}
+	// 500 rupees buys 1000 yen at 2 yen a rupee, recording 0.5 rupees a yen.
+	createResource(t, r, "/api/v1/transactions", map[string]interface{}{"account_id": current, "type": "transfer", "transfer_account_id": yen,
+		"amount": 500.0, "exchange_rate": 2.0, "transaction_date": "2024-02-01"})
+
+	status, resp := apiRequest(t, r, "GET", "/api/v1/reports/fx?as_of=2024-03-31&rates=JPY:0.6", nil)
+	if status != http.StatusOK {
+		t.Fatalf("fx report: status %d, error %v", status, resp["error"])
+	}
+	row := resp["data"].(map[string]interface{})["rows"].([]interface{})[0].(map[string]interface{})
+	if row["balance"] != 1000.0 || row["recorded_rate"] != 0.5 || row["book_value"] != 50000.0 ||
+		row["revalued_value"] != 60000.0 || row["gain_loss"] != 10000.0 {
+		t.Errorf("yen row = %v, want balance 1000, recorded_rate 0.5, book 50000, revalued 60000, gain 10000", row)
+	}
+}
+
+// TestGetAllocationHealth verifies the counts and totals of unallocated
+// transactions per currency, part-paid bills, unsettled payouts, and
+// over-allocated documents.
+func TestGetAllocationHealth(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+
+	current := createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": "Current", "type": "bank"})
+	savings := createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": "Savings", "type": "bank"})
+	dollars := createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": "Dollars", "type": "bank", "currency": "USD"})
+	txn := func(account int, txnType string, amount float64) int {
+		return createResource(t, r, "/api/v1/transactions", map[string]interface{}{"account_id": account, "type": txnType, "amount": amount, "transaction_date": "2024-03-01"})
+	}
+	link := func(txnID int, docType string, docID int, amount float64) {
+		createResource(t, r, fmt.Sprintf("/api/v1/transactions/%d/links", txnID), map[string]interface{}{"document_type": docType, "document_id": docID, "amount": amount})
+	}
+
+	bill := createResource(t, r, "/api/v1/bills", map[string]interface{}{"bill_number": "B-1", "amount": 100.0, "status": "received"})
+	link(txn(current, "expense", 60), "bill", bill, 60)
+	createResource(t, r, "/api/v1/invoices", map[string]interface{}{"invoice_number": "I-1", "amount": 200.0, "status": "sent"})
+	over := createResource(t, r, "/api/v1/invoices", map[string]interface{}{"invoice_number": "I-2", "amount": 50.0, "status": "sent"})
+	link(txn(current, "income", 80), "invoice", over, 50)
+	txn(current, "income", 50)
+	txn(dollars, "income", 10)
+	createResource(t, r, "/api/v1/transactions", map[string]interface{}{"account_id": current, "type": "transfer", "transfer_account_id": savings, "amount": 20.0})
+	createResource(t, r, "/api/v1/payouts", map[string]interface{}{"outlet_name": "Kitchen", "create_outlet": true, "platform": "swiggy", "final_payout_amt": 300.0})
+
+	// Links are checked against the document, so over-allocation only comes
+	// from data written before the checks.
+	if _, err := DB.Exec("UPDATE transaction_documents SET amount = 7000 WHERE document_type = 'invoice' AND document_id = ?", over); err != nil {
+		t.Fatalf("over-allocate invoice: %v", err)
+	}
+
+	status, resp := apiRequest(t, r, "GET", "/api/v1/reports/allocation-health", nil)
+	if status != http.StatusOK {
+		t.Fatalf("allocation health: status %d, error %v", status, resp["error"])
+	}
+	data := resp["data"].(map[string]interface{})
+	bucket := func(name string) [2]float64 {
+		b := data[name].(map[string]interface{})
+		return [2]float64{b["count"].(float64), b["total"].(float64)}
+	}
+	for name, want := range map[string][2]float64{
+		"partial_bills":     {1, 4000},
+		"partial_invoices":  {0, 0},
+		"unsettled_payouts": {1, 30000},
+		"over_allocated":    {1, 2000},
+	} {
+		if got := bucket(name); got != want {
+			t.Errorf("%s = %v, want %v", name, got, want)
+		}
+	}
+
+	unallocated := data["unallocated_transactions"].([]interface{})
+	want := map[string][2]float64{"INR": {2, 6000}, "USD": {1, 1000}}
+	if len(unallocated) != len(want) {
+		t.Fatalf("expected a bucket per currency, got %v", unallocated)
+	}
+	for _, item := range unallocated {
+		b := item.(map[string]interface{})
+		if w := want[b["currency"].(string)]; b["count"] != w[0] || b["total"] != w[1] {
+			t.Errorf("unallocated %v = %v, want %v", b["currency"], b, w)
+		}
+	}
+
+	items := data["over_allocated_items"].([]interface{})
+	if len(items) != 1 {
+		t.Fatalf("expected one over-allocated item, got %v", items)
+	}
+	if item := items[0].(map[string]interface{}); item["type"] != "invoice" || item["id"] != float64(over) ||
+		item["reference"] != "I-2" || item["allocated"] != 7000.0 || item["excess"] != 2000.0 {
+		t.Errorf("over-allocated item = %v", item)
+	}
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import "github.com/go-chi/chi/v5"
+
+// routeGroup is a named set of API routes. DISABLE_ENDPOINTS lists the names
+// of groups a deployment leaves unregistered, so their paths return 404.
+type routeGroup struct {
+	name     string
+	register func(r chi.Router)
+}
+
+// routeGroups are the tenant API routes, in registration order. Adding a
+// group here makes it switchable by DISABLE_ENDPOINTS.
+var routeGroups = []routeGroup{
+	{"accounts", func(r chi.Router) {
+		r.Get("/accounts", ListAccounts)
+		r.Post("/accounts", CreateAccount)
+		r.Get("/accounts/{id}", GetAccount)
+		r.Put("/accounts/{id}", UpdateAccount)
+		r.Delete("/accounts/{id}", DeleteAccount)
+		r.Get("/accounts/{id}/delete-impact", GetAccountDeleteImpact)
+		r.Get("/accounts/{id}/verify", VerifyAccount)
+		r.Get("/accounts/{id}/stats", GetAccountStats)
+		r.Post("/accounts/{id}/import", ImportAccountTransactions)
+	}},
+	{"contacts", func(r chi.Router) {
+		r.Get("/contacts", ListContacts)
+		r.Post("/contacts", CreateContact)
+		r.Post("/contacts/import", ImportContacts)
+		r.Get("/contacts/{id}", GetContact)
+		r.Put("/contacts/{id}", UpdateContact)
+		r.Delete("/contacts/{id}", DeleteContact)
+		r.Get("/contacts/{id}/delete-impact", GetContactDeleteImpact)
+		r.Get("/contacts/{id}/documents", GetContactDocuments)
+		r.Get("/contacts/{id}/payments", GetContactPayments)
+	}},
+	{"bills", func(r chi.Router) {
+		r.Get("/bills", ListBills)
+		r.Post("/bills", CreateBill)
+		r.Post("/bills/ocr", OCRBills)
+		r.Get("/bills/{id}", GetBill)
+		r.Put("/bills/{id}", UpdateBill)
+		r.Delete("/bills/{id}", DeleteBill)
+		r.Post("/bills/{id}/void", VoidBill)
+		r.Get("/bills/{id}/links", GetBillLinks)
+		r.Get("/bills/{id}/match-suggestions", SuggestTransactionsForBill)
+		r.Get("/bills/{id}/items", ListBillItems)
+		r.Post("/bills/{id}/items", CreateBillItem)
+		r.Put("/bills/{id}/items/{itemId}", UpdateBillItem)
+		r.Delete("/bills/{id}/items/{itemId}", DeleteBillItem)
+	}},
+	{"invoices", func(r chi.Router) {
+		r.Get("/invoices", ListInvoices)
+		r.Post("/invoices", CreateInvoice)
+		r.Get("/invoices/due-soon", ListInvoicesDueSoon)
+		r.Get("/invoices/{id}", GetInvoice)
+		r.Put("/invoices/{id}", UpdateInvoice)
+		r.Delete("/invoices/{id}", DeleteInvoice)
+		r.Post("/invoices/{id}/void", VoidInvoice)
+		r.Post("/invoices/{id}/send", SendInvoice)
+		r.Post("/invoices/{id}/clone", CloneInvoice)
+		r.Get("/invoices/{id}/links", GetInvoiceLinks)
+		r.Get("/invoices/{id}/ledger", GetInvoiceLedger)
+		r.Get("/invoices/{id}/einvoice-json", GetInvoiceEInvoice)
+		r.Get("/invoices/{id}/match-suggestions", SuggestTransactionsForInvoice)
+		r.Get("/invoices/{id}/items", ListInvoiceItems)
+		r.Post("/invoices/{id}/items", CreateInvoiceItem)
+		r.Put("/invoices/{id}/items/{itemId}", UpdateInvoiceItem)
+		r.Delete("/invoices/{id}/items/{itemId}", DeleteInvoiceItem)
+	}},
+	{"transactions", func(r chi.Router) {
+		r.Get("/transactions", ListTransactions)
+		r.Post("/transactions", CreateTransaction)
+		r.Post("/transactions/reconcile-by-reference", ReconcileByReference)
+		r.Get("/transactions/suspense", ListSuspenseTransactions)
+		r.Get("/transactions/calendar", GetTransactionCalendar)
+		r.Get("/transactions/duplicates", ListDuplicateTransactions)
+		r.Post("/transactions/duplicates", ListDuplicateTransactions)
+		r.Get("/transactions/{id}", GetTransaction)
+		r.Put("/transactions/{id}", UpdateTransaction)
+		r.Delete("/transactions/{id}", DeleteTransaction)
+
+		// Transaction document links
+		r.Get("/transactions/{id}/links", ListTransactionLinks)
+		r.Get("/transactions/{id}/allocation-graph", GetAllocationGraph)
+		r.Post("/transactions/{id}/links", CreateTransactionLink)
+		r.Delete("/transactions/{id}/links/{linkId}", DeleteTransactionLink)
+		r.Post("/offsets", CreateOffset)
+
+		// Payment matching
+		r.Get("/transactions/{id}/match-suggestions", SuggestMatches)
+		r.Post("/transactions/{id}/auto-match", AutoMatch)
+	}},
+	{"defaults", func(r chi.Router) {
+		r.Get("/defaults", ListDefaults)
+		r.Put("/defaults/{key}", SetDefault)
+		r.Delete("/defaults/{key}", DeleteDefault)
+	}},
+	{"categories", func(r chi.Router) {
+		r.Get("/categories", ListCategories)
+		r.Post("/categories", CreateCategory)
+		r.Get("/categories/{id}", GetCategory)
+		r.Put("/categories/{id}", UpdateCategory)
+		r.Delete("/categories/{id}", DeleteCategory)
+	}},
+	{"outlets", func(r chi.Router) {
+		r.Get("/outlets", ListOutlets)
+		r.Post("/outlets", CreateOutlet)
+		r.Get("/outlets/{id}", GetOutlet)
+		r.Put("/outlets/{id}", UpdateOutlet)
+		r.Delete("/outlets/{id}", DeleteOutlet)
+	}},
+	{"payouts", func(r chi.Router) {
+		r.Get("/payouts", ListPayouts)
+		r.Post("/payouts", CreatePayout)
+		r.Post("/payouts/import", ImportPayouts)
+		r.Get("/payouts/{id}", GetPayout)
+		r.Put("/payouts/{id}", UpdatePayout)
+		r.Delete("/payouts/{id}", DeletePayout)
+		r.Get("/payouts/{id}/links", GetPayoutLinks)
+		r.Get("/payouts/{id}/orders", ListPayoutOrders)
+		r.Post("/payouts/{id}/orders", CreatePayoutOrders)
+		r.Get("/payouts/{id}/match-suggestions", SuggestTransactionsForPayout)
+		r.Post("/payouts/{id}/auto-match", AutoMatchPayout)
+		r.Post("/payouts/{id}/settle", SettlePayout)
+		r.Post("/payouts/{id}/post", PostPayout)
+	}},
+	{"recurring-payments", func(r chi.Router) {
+		r.Get("/recurring-payments", ListRecurringPayments)
+		r.Post("/recurring-payments", CreateRecurringPayment)
+		r.Get("/recurring-payments/{id}", GetRecurringPayment)
+		r.Put("/recurring-payments/{id}", UpdateRecurringPayment)
+		r.Delete("/recurring-payments/{id}", DeleteRecurringPayment)
+		r.Get("/recurring-payments/{id}/links", GetRecurringPaymentLinks)
+		r.Get("/recurring-payments/{id}/occurrences", GetRecurringPaymentOccurrences)
+		r.Get("/recurring-payments/{id}/match-suggestions", SuggestTransactionsForRecurringPayment)
+	}},
+	{"periods", func(r chi.Router) {
+		r.Get("/periods", ListClosedPeriods)
+		r.Post("/periods/close", ClosePeriod)
+		r.Post("/periods/reopen", ReopenPeriod)
+	}},
+	{"changes", func(r chi.Router) {
+		r.Get("/changes", ListChanges)
+	}},
+	{"dashboard", func(r chi.Router) {
+		r.Get("/dashboard", GetDashboard)
+	}},
+	{"reports", func(r chi.Router) {
+		r.Get("/reports/tds", GetTDSReport)
+		r.Get("/reports/outlets", GetOutletReport)
+		r.Get("/reports/payout-variances", GetPayoutVarianceReport)
+		r.Get("/reports/vendor-spend", GetVendorSpendReport)
+		r.Get("/reports/commission-trend", GetCommissionTrend)
+		r.Get("/reports/trial-balance", GetTrialBalance)
+		r.Get("/reports/fx", GetFXReport)
+		r.Get("/reports/allocation-health", GetAllocationHealth)
+		r.Get("/reports/gstr1", GetGSTR1Report)
+	}},
+	{"admin", func(r chi.Router) {
+		r.Post("/admin/recompute-statuses", RecomputeStatuses)
+		r.Post("/admin/purge", PurgeOrphans)
+		r.Post("/admin/recalc-contacts", RecalcContacts)
+		r.Post("/transactions/{id}/lock", LockTransaction)
+		r.Post("/transactions/{id}/unlock", UnlockTransaction)
+	}},
+	{"events", func(r chi.Router) {
+		r.Get("/events", StreamEvents)
+	}},
+}
+
+// APIRoutes registers the tenant API on r, leaving out the groups named in
+// cfg.DisabledEndpoints. GET /config is always registered, since the UI
+// needs it to read amounts. main mounts it at /api/v1 behind BearerAuth and
+// DBRequired; tests mount it the same way without auth.
+func APIRoutes(r chi.Router) {
+	r.Get("/config", GetClientConfig)
+	for _, g := range routeGroups {
+		if cfg.DisabledEndpoints[g.name] {
+			continue
+		}
+		g.register(r)
+	}
+}
@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Prefixes that tell portal-issued session tokens apart from Nexus JWTs.
+const (
+	accessTokenPrefix  = "pat_"
+	refreshTokenPrefix = "prt_"
+)
+
+// session is a login kept server-side. The Nexus JWT it wraps is never handed
+// to the client; the client holds a short-lived access token and a refresh
+// token instead, both stored here only as SHA-256 hashes. A session never
+// outlives its Nexus JWT, since every database connection is opened with it.
+type session struct {
+	nexusToken string
+	nexusExp   time.Time
+
+	accessHash  string
+	accessExp   time.Time
+	refreshHash string
+	refreshExp  time.Time
+}
+
+// sessionStore holds active sessions in memory. Sessions do not survive a
+// restart and are not shared between instances; clients then sign in again.
+type sessionStore struct {
+	mu        sync.Mutex
+	byAccess  map[string]*session
+	byRefresh map[string]*session
+}
+
+// sessions is the process-wide session store used by Login, Refresh, Logout,
+// and BearerAuth.
+var sessions = &sessionStore{byAccess: map[string]*session{}, byRefresh: map[string]*session{}}
+
+// sessionTokens is the token pair returned by login and refresh.
+type sessionTokens struct {
+	Token            string `json:"token"` // access token, sent as "Authorization: Bearer <token>"
+	RefreshToken     string `json:"refresh_token"`
+	ExpiresIn        int64  `json:"expires_in"`         // seconds until token expires
+	RefreshExpiresIn int64  `json:"refresh_expires_in"` // seconds until refresh_token expires
+}
+
+// hashToken returns the hex SHA-256 of a session token.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// newToken returns a random token with the given prefix.
+func newToken(prefix string) string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic("crypto/rand: " + err.Error())
+	}
+	return prefix + base64.RawURLEncoding.EncodeToString(b)
+}
+
+// earliest returns the earlier of a and b.
+func earliest(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
+
+// issue gives sess a fresh access and refresh token, replacing any it had.
+// The caller must hold st.mu.
+func (st *sessionStore) issue(sess *session, now time.Time) sessionTokens {
+	delete(st.byAccess, sess.accessHash)
+	delete(st.byRefresh, sess.refreshHash)
+
+	access, refresh := newToken(accessTokenPrefix), newToken(refreshTokenPrefix)
+	sess.accessHash, sess.refreshHash = hashToken(access), hashToken(refresh)
+	sess.accessExp = earliest(now.Add(cfg.AccessTokenTTL), sess.nexusExp)
+	sess.refreshExp = earliest(now.Add(cfg.RefreshTokenTTL), sess.nexusExp)
+	st.byAccess[sess.accessHash] = sess
+	st.byRefresh[sess.refreshHash] = sess
+
+	return sessionTokens{
+		Token:            access,
+		RefreshToken:     refresh,
+		ExpiresIn:        int64(sess.accessExp.Sub(now).Seconds()),
+		RefreshExpiresIn: int64(sess.refreshExp.Sub(now).Seconds()),
+	}
+}
+
+// remove drops sess from the store. The caller must hold st.mu.
+func (st *sessionStore) remove(sess *session) {
+	delete(st.byAccess, sess.accessHash)
+	delete(st.byRefresh, sess.refreshHash)
+}
+
+// sweep drops sessions whose refresh token has expired. The caller must hold st.mu.
+func (st *sessionStore) sweep(now time.Time) {
+	for _, sess := range st.byRefresh {
+		if !now.Before(sess.refreshExp) {
+			st.remove(sess)
+		}
+	}
+}
+
+// create starts a session for a Nexus JWT and returns its first token pair.
+func (st *sessionStore) create(nexusToken string, nexusExp time.Time) sessionTokens {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	now := time.Now()
+	st.sweep(now)
+	return st.issue(&session{nexusToken: nexusToken, nexusExp: nexusExp}, now)
+}
+
+// refresh exchanges a refresh token for a new token pair. The old refresh
+// token is spent, so a stolen copy stops working once the client refreshes.
+func (st *sessionStore) refresh(refreshToken string) (sessionTokens, bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	now := time.Now()
+	sess, ok := st.byRefresh[hashToken(refreshToken)]
+	if !ok {
+		return sessionTokens{}, false
+	}
+	if !now.Before(sess.refreshExp) {
+		st.remove(sess)
+		return sessionTokens{}, false
+	}
+	return st.issue(sess, now), true
+}
+
+// lookup returns the Nexus JWT behind a live access token.
+func (st *sessionStore) lookup(accessToken string) (nexusToken string, ok bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	sess, ok := st.byAccess[hashToken(accessToken)]
+	if !ok || !time.Now().Before(sess.accessExp) {
+		return "", false
+	}
+	return sess.nexusToken, true
+}
+
+// revoke ends the session owning token, which may be its access or refresh
+// token. It reports whether a session was found.
+func (st *sessionStore) revoke(token string) bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	h := hashToken(token)
+	sess, ok := st.byRefresh[h]
+	if !ok {
+		sess, ok = st.byAccess[h]
+	}
+	if ok {
+		st.remove(sess)
+	}
+	return ok
+}
+
+// nexusTokenExpiry returns the exp claim of a JWT.
+func nexusTokenExpiry(token string) (time.Time, bool) {
+	parts := strings.SplitN(token, ".", 3)
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(claims.Exp, 0), true
+}
+
+// Refresh exchanges a refresh token for a new access token.
+//
+//	@Summary		Refresh access token
+//	@Description	Exchanges a refresh token from login for a new access token and refresh token. The refresh token sent is spent. Sessions end when the refresh token or the underlying Nexus login expires, whichever is first.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		refreshRequest	true	"Refresh token"
+//	@Success		200		{object}	Response{data=sessionTokens}
+//	@Failure		400		{object}	Response
+//	@Failure		401		{object}	Response
+//	@Router			/auth/refresh [post]
+func Refresh(w http.ResponseWriter, r *http.Request) {
+	var req refreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		writeError(w, http.StatusBadRequest, "refresh_token is required")
+		return
+	}
+	tokens, ok := sessions.refresh(req.RefreshToken)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "invalid or expired refresh token")
+		return
+	}
+	writeJSON(w, http.StatusOK, tokens)
+}
+
+// Logout revokes a session.
+//
+//	@Summary		Logout
+//	@Description	Ends the session owning the given refresh token, or the access token in the Authorization header. Both of its tokens stop working immediately.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		refreshRequest	false	"Refresh token"
+//	@Success		200		{object}	Response{data=map[string]string}
+//	@Failure		400		{object}	Response
+//	@Router			/auth/logout [post]
+func Logout(w http.ResponseWriter, r *http.Request) {
+	var req refreshRequest
+	_ = json.NewDecoder(r.Body).Decode(&req)
+	token := req.RefreshToken
+	if token == "" {
+		token = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	}
+	if token == "" {
+		writeError(w, http.StatusBadRequest, "refresh_token or Authorization header is required")
+		return
+	}
+	sessions.revoke(token)
+	writeJSON(w, http.StatusOK, map[string]string{"message": "logged out"})
+}
+
+// refreshRequest documents the fields accepted by the refresh and logout endpoints.
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
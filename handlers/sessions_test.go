@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// stubNexusLogin serves a nexus-control /api/v1/login that returns token.
+func stubNexusLogin(t *testing.T, token string) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/login", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"token":"` + token + `"}`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// postAuth calls an auth handler with a JSON body and decodes the session tokens.
+func postAuth(t *testing.T, h http.HandlerFunc, body any) (int, sessionTokens) {
+	t.Helper()
+	payload, _ := json.Marshal(body)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(payload)))
+	var resp struct {
+		Data sessionTokens `json:"data"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+	return rec.Code, resp.Data
+}
+
+// authStatus returns the status BearerAuth gives a request carrying token.
+func authStatus(token string) int {
+	rec := httptest.NewRecorder()
+	BearerAuth(okHandler).ServeHTTP(rec, bearerRequest(token))
+	return rec.Code
+}
+
+func TestSessionLoginRefreshLogout(t *testing.T) {
+	srv := stubNexusLogin(t, makeJWT("tenant_abc", 7200))
+	withTestConfig(t, Config{NexusControlURL: srv.URL, AccessTokenTTL: time.Minute, RefreshTokenTTL: time.Hour})
+
+	status, login := postAuth(t, Login, map[string]string{"email": "a@example.com", "password": "pw"})
+	if status != http.StatusOK || login.Token == "" || login.RefreshToken == "" {
+		t.Fatalf("login: status %d, tokens %+v", status, login)
+	}
+	if login.ExpiresIn != 60 || login.RefreshExpiresIn != 3600 {
+		t.Errorf("expected 60s/3600s lifetimes, got %d/%d", login.ExpiresIn, login.RefreshExpiresIn)
+	}
+	if code := authStatus(login.Token); code != http.StatusOK {
+		t.Fatalf("access token: expected 200, got %d", code)
+	}
+
+	status, refreshed := postAuth(t, Refresh, map[string]string{"refresh_token": login.RefreshToken})
+	if status != http.StatusOK || refreshed.Token == login.Token {
+		t.Fatalf("refresh: status %d, tokens %+v", status, refreshed)
+	}
+	// Refreshing rotates both tokens.
+	if status, _ := postAuth(t, Refresh, map[string]string{"refresh_token": login.RefreshToken}); status != http.StatusUnauthorized {
+		t.Errorf("reused refresh token: expected 401, got %d", status)
+	}
+	if code := authStatus(login.Token); code != http.StatusUnauthorized {
+		t.Errorf("replaced access token: expected 401, got %d", code)
+	}
+	if code := authStatus(refreshed.Token); code != http.StatusOK {
+		t.Errorf("new access token: expected 200, got %d", code)
+	}
+
+	if status, _ := postAuth(t, Logout, map[string]string{"refresh_token": refreshed.RefreshToken}); status != http.StatusOK {
+		t.Fatalf("logout: expected 200, got %d", status)
+	}
+	if code := authStatus(refreshed.Token); code != http.StatusUnauthorized {
+		t.Errorf("access token after logout: expected 401, got %d", code)
+	}
+	if status, _ := postAuth(t, Refresh, map[string]string{"refresh_token": refreshed.RefreshToken}); status != http.StatusUnauthorized {
+		t.Errorf("refresh after logout: expected 401, got %d", status)
+	}
+}
+
+func TestSessionAccessTokenExpires(t *testing.T) {
+	// The Nexus JWT expires in 30s, so it caps the hour-long refresh TTL.
+	srv := stubNexusLogin(t, makeJWT("tenant_abc", 30))
+	withTestConfig(t, Config{NexusControlURL: srv.URL, AccessTokenTTL: 10 * time.Millisecond, RefreshTokenTTL: time.Hour})
+
+	_, login := postAuth(t, Login, map[string]string{"email": "a@example.com", "password": "pw"})
+	if login.RefreshExpiresIn > 30 {
+		t.Errorf("expected refresh lifetime capped at 30s, got %d", login.RefreshExpiresIn)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if code := authStatus(login.Token); code != http.StatusUnauthorized {
+		t.Errorf("expired access token: expected 401, got %d", code)
+	}
+	if status, _ := postAuth(t, Refresh, map[string]string{"refresh_token": login.RefreshToken}); status != http.StatusOK {
+		t.Errorf("refresh after access expiry: expected 200, got %d", status)
+	}
+}
@@ -5,10 +5,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
-	"strconv"
+	"strings"
+	"time"
 
-	"github.com/go-chi/chi/v5"
 	"github.com/satheeshds/portal/models"
 	"github.com/satheeshds/portal/store"
 )
@@ -16,28 +17,202 @@ import (
 // ListTransactions lists all transactions
 //	@Summary		List transactions
 //	@Description	Get a list of all bank transactions (income, expense, transfer) with allocation info.
+//	@Description	Send Accept: text/csv for the same list as CSV, with each amount in the decimal places of its account's currency, named in the currency column.
 //	@Tags			transactions
 //	@Produce		json
-//	@Param			account_id	query		int	false	"Filter by account"
-//	@Param			contact_id	query		int	false	"Filter by contact"
-//	@Success		200			{object}	Response{data=[]models.Transaction}
+//	@Produce		text/csv
+//	@Param			type			query		string	false	"Filter by type (income, expense, transfer)"
+//	@Param			account_id		query		int		false	"Filter by account"
+//	@Param			contact_id		query		int		false	"Filter by contact"
+//	@Param			category_id		query		int		false	"Filter by category"
+//	@Param			from			query		string	false	"Filter by transaction date from (YYYY-MM-DD)"
+//	@Param			to				query		string	false	"Filter by transaction date to (YYYY-MM-DD)"
+//	@Param			reference		query		string	false	"Filter by reference (exact match)"
+//	@Param			external_id		query		string	false	"Look up by external system id"
+//	@Param			source			query		string	false	"Filter by external source"
+//	@Param			amount_format	query		string	false	"CSV amounts as rupees (default), rupees_grouped, or paise"
+//	@Success		200				{object}	Response{data=[]models.Transaction}
+//	@Failure		400				{object}	Response{error=string}
 //	@Router			/transactions [get]
 //	@Security		BearerAuth
 func ListTransactions(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	txns, err := s.ListTransactions(
-		r.URL.Query().Get("type"),
-		r.URL.Query().Get("account_id"),
-		r.URL.Query().Get("from"),
-		r.URL.Query().Get("to"),
-	)
+	q, ok := listQuery(w, r, []string{"account_id", "contact_id", "category_id"}, []string{"from", "to"})
+	if !ok {
+		return
+	}
+	streamList(w, r, "transactions.csv", transactionCSVHeader, transactionCSVRecord, func(yield func(models.Transaction) error) error {
+		return s.EachTransaction(
+			q.Get("type"),
+			q.Get("account_id"),
+			q.Get("contact_id"),
+			q.Get("category_id"),
+			q.Get("from"),
+			q.Get("to"),
+			q.Get("external_id"),
+			q.Get("source"),
+			q.Get("reference"),
+			yield,
+		)
+	})
+}
+
+// SuspenseTransaction is an alias for store.SuspenseTransaction kept here for Swagger doc references.
+type SuspenseTransaction = store.SuspenseTransaction
+
+// ListSuspenseTransactions lists stale unallocated transactions
+//	@Summary		List suspense transactions
+//	@Description	List income and expense transactions with no linked documents that are at least days old (default SUSPENSE_DAYS, 30),
+//	@Description	oldest first, for periodic review of unallocated money. Transfer legs are excluded.
+//	@Tags			transactions
+//	@Produce		json
+//	@Param			days	query		int	false	"Minimum age in days"
+//	@Success		200		{object}	Response{data=[]SuspenseTransaction}
+//	@Failure		400		{object}	Response{error=string}
+//	@Router			/transactions/suspense [get]
+//	@Security		BearerAuth
+func ListSuspenseTransactions(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	days, ok := queryInt(w, r, "days", cfg.SuspenseDays, 0)
+	if !ok {
+		return
+	}
+	txns, err := s.ListSuspenseTransactions(time.Now(), days)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeInternalError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, txns)
 }
 
+// CalendarDay is an alias for store.CalendarDay kept here for Swagger doc references.
+type CalendarDay = store.CalendarDay
+
+// GetTransactionCalendar sums transactions per day for a calendar view
+//	@Summary		Transaction calendar
+//	@Description	Sum each day's transactions over a month: the count and totals of income and expense and their net, with transfer legs counted and summed separately.
+//	@Description	Every day of the month is returned in order, including days without transactions. Adjustments are not included.
+//	@Description	Without account_id, the accounts in currency (default the books currency) are summed; accounts in other currencies are left out rather than added in unconverted.
+//	@Tags			transactions
+//	@Produce		json
+//	@Param			month		query		string	false	"Month (YYYY-MM, default the current month)"
+//	@Param			account_id	query		int		false	"Filter by account"
+//	@Param			currency	query		string	false	"Currency of the accounts to sum without account_id (default the books currency)"
+//	@Success		200			{object}	Response{data=[]CalendarDay}
+//	@Failure		400			{object}	Response{error=string}
+//	@Router			/transactions/calendar [get]
+//	@Security		BearerAuth
+func GetTransactionCalendar(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	q, ok := listQuery(w, r, []string{"account_id"}, nil)
+	if !ok {
+		return
+	}
+	month := time.Now()
+	if v := q.Get("month"); v != "" {
+		m, err := time.Parse("2006-01", v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "month must be YYYY-MM")
+			return
+		}
+		month = m
+	}
+	currency := strings.ToUpper(q.Get("currency"))
+	if currency == "" {
+		currency = store.BooksCurrency
+	}
+	days, err := s.GetTransactionCalendar(month, q.Get("account_id"), currency)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, days)
+}
+
+// DuplicateGroup is an alias for store.DuplicateGroup kept here for Swagger doc references.
+type DuplicateGroup = store.DuplicateGroup
+
+// DuplicateScanResult lists duplicate transaction groups and, after an
+// auto_resolve run, the transactions that were deleted.
+type DuplicateScanResult struct {
+	Groups  []DuplicateGroup     `json:"groups"`
+	Removed []models.Transaction `json:"removed,omitempty"`
+}
+
+// ListDuplicateTransactions finds transactions that were likely posted twice
+//	@Summary		Find duplicate transactions
+//	@Description	Group income and expense transactions with the same account, type, amount, and date (and reference when
+//	@Description	match_reference=true), for reviewing double-posted bank entries. Within a group the earliest created comes first.
+//	@Description	POST with auto_resolve=keep_oldest keeps one transaction of each group and deletes the rest, with their links, in one
+//	@Description	database transaction, and returns what was removed. The kept one is the transaction that is linked to documents,
+//	@Description	reconciled, or locked, or else the earliest created. Groups with more than one such transaction refuse the run with
+//	@Description	409 and are returned in data; groups dated in a closed period also block it.
+//	@Tags			transactions
+//	@Produce		json
+//	@Param			match_reference	query		bool	false	"Also require the same reference"
+//	@Param			auto_resolve	query		string	false	"keep_oldest (POST only)"
+//	@Success		200				{object}	Response{data=DuplicateScanResult}
+//	@Failure		400				{object}	Response{error=string}
+//	@Failure		405				{object}	Response{error=string}
+//	@Failure		409				{object}	Response{error=string,data=DuplicateScanResult}
+//	@Router			/transactions/duplicates [get]
+//	@Router			/transactions/duplicates [post]
+//	@Security		BearerAuth
+func ListDuplicateTransactions(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	q := r.URL.Query()
+	resolve := q.Get("auto_resolve")
+	if resolve != "" && resolve != "keep_oldest" {
+		writeError(w, http.StatusBadRequest, "auto_resolve must be keep_oldest")
+		return
+	}
+	if resolve != "" && r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "auto_resolve deletes transactions and requires POST")
+		return
+	}
+
+	groups, err := s.ListDuplicateTransactions(q.Get("match_reference") == "true")
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	result := DuplicateScanResult{Groups: groups}
+	if resolve == "" {
+		writeJSON(w, http.StatusOK, result)
+		return
+	}
+
+	dates := make([]string, 0, len(groups))
+	for _, g := range groups {
+		dates = append(dates, g.TransactionDate.String())
+	}
+	if !checkPeriodOpen(w, r, s, dates...) {
+		return
+	}
+	var ambiguous []DuplicateGroup
+	for _, g := range groups {
+		if _, ok := g.Survivor(); !ok {
+			ambiguous = append(ambiguous, g)
+		}
+	}
+	if len(ambiguous) > 0 {
+		writeErrorData(w, http.StatusConflict, fmt.Sprintf("%d duplicate group(s) have more than one linked, reconciled, or locked transaction; resolve them by hand",
+			len(ambiguous)), DuplicateScanResult{Groups: ambiguous})
+		return
+	}
+	result.Removed, err = s.ResolveDuplicates(groups)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	for _, t := range result.Removed {
+		slog.InfoContext(r.Context(), "audit: duplicate transaction deleted", "tenant_id", getTenant(r),
+			"transaction_id", t.ID, "account_id", t.AccountID, "amount", t.Amount, "transaction_date", t.TransactionDate.String())
+		publishEvent(r, "deleted", "transaction", t.ID)
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
 // GetTransaction retrieves a single transaction by ID
 //	@Summary		Get transaction
 //	@Description	Get details and allocation status of a specific transaction.
@@ -45,15 +220,23 @@ func ListTransactions(w http.ResponseWriter, r *http.Request) {
 //	@Produce		json
 //	@Param			id	path		int	true	"Transaction ID"
 //	@Success		200	{object}	Response{data=models.Transaction}
-//	@Failure		404	{object}	Response{error=string}
+//	@Failure		400	{object}	Response{error=string}
+//	@Failure		404	{object}	Response{error=string,code=string,resource=string}
 //	@Router			/transactions/{id} [get]
 //	@Security		BearerAuth
 func GetTransaction(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
 	t, err := s.GetTransaction(id)
 	if err != nil {
-		writeError(w, http.StatusNotFound, "transaction not found")
+		if errors.Is(err, sql.ErrNoRows) {
+			writeNotFound(w, "transaction")
+		} else {
+			writeInternalError(w, r, err)
+		}
 		return
 	}
 	writeJSON(w, http.StatusOK, t)
@@ -61,12 +244,23 @@ func GetTransaction(w http.ResponseWriter, r *http.Request) {
 
 // CreateTransaction creates a new transaction
 //	@Summary		Create transaction
-//	@Description	Create a new bank transaction (income, expense, or transfer).
+//	@Description	Create a new bank transaction (income, expense, or transfer). A transfer may carry a fee_amount,
+//	@Description	which is recorded as a separate expense on the source account while the transfer legs move the net amount.
+//	@Description	If external_id is set and the account already has a transaction with the same source and external_id, that transaction is
+//	@Description	updated instead and 200 is returned. Amounts above LARGE_TXN_THRESHOLD require confirmed_large: true.
+//	@Description	A transfer between accounts of different currencies requires exchange_rate (destination units per source unit);
+//	@Description	the destination leg moves the net amount converted at that rate, and both legs store the rate. Amounts are in the account's currency
+//	@Description	and are never converted otherwise; currency, when given, must match it.
+//	@Description	When account_id is omitted, the default account for the type (default_account.<type>, see /defaults) is used.
+//	@Description	An adjustment corrects an account balance without being income or expense: sign 1 adds its amount, -1 subtracts it.
+//	@Description	Set cleared: false for money still in transit (e.g. an uncleared cheque); it counts towards the account's projected balance but not its cleared balance.
 //	@Tags			transactions
 //	@Accept			json
 //	@Produce		json
 //	@Param			transaction	body		models.TransactionInput	true	"Transaction contents"
+//	@Success		200			{object}	Response{data=models.Transaction}
 //	@Success		201			{object}	Response{data=models.Transaction}
+//	@Failure		409			{object}	Response{error=string}
 //	@Router			/transactions [post]
 //	@Security		BearerAuth
 func CreateTransaction(w http.ResponseWriter, r *http.Request) {
@@ -76,114 +270,526 @@ func CreateTransaction(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "invalid JSON")
 		return
 	}
+	if !applyDefaultAccount(w, r, s, &input) {
+		return
+	}
+	currency, ok := inAccountCurrency(w, r, s, &input)
+	if !ok {
+		return
+	}
 	if msg := input.Validate(); msg != "" {
 		writeError(w, http.StatusBadRequest, msg)
 		return
 	}
-	t, err := s.CreateTransaction(input)
+	if !checkLargeAmount(w, input, currency) {
+		return
+	}
+	if !checkTransactionCurrency(w, r, s, input, nil) {
+		return
+	}
+	if !checkCategoryType(w, r, s, input.CategoryID, input.Type, input.Type+" transactions") {
+		return
+	}
+	if input.Type == "transfer" && !checkTransferCurrencies(w, r, s, input) {
+		return
+	}
+	if !checkPeriodOpen(w, r, s, stringValue(input.TransactionDate)) {
+		return
+	}
+	t, created, err := s.CreateExternalTransaction(input)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeInternalError(w, r, err)
+		return
+	}
+	if !created {
+		// Re-sync of a known external record: update it in place.
+		if !checkTransactionUnlocked(w, t) {
+			return
+		}
+		if !checkPeriodOpen(w, r, s, t.TransactionDate.String()) {
+			return
+		}
+		t, err = s.UpdateTransaction(t.ID, input)
+		if err != nil {
+			writeInternalError(w, r, err)
+			return
+		}
+		publishEvent(r, "updated", "transaction", t.ID)
+		writeJSON(w, http.StatusOK, t)
 		return
 	}
+	publishEvent(r, "created", "transaction", t.ID)
 	writeJSON(w, http.StatusCreated, t)
 }
 
 // UpdateTransaction updates an existing transaction
 //	@Summary		Update transaction
-//	@Description	Update details of an existing transaction.
+//	@Description	Update details of an existing transaction. Raising the amount above LARGE_TXN_THRESHOLD requires confirmed_large: true.
+//	@Description	Omitting cleared keeps the stored value; setting cleared_date marks the transaction cleared. Locked transactions are rejected with 409.
+//	@Description	A leg of a transfer between currencies keeps its exchange_rate, and changing its amount or rate is rejected with 409.
 //	@Tags			transactions
 //	@Accept			json
 //	@Produce		json
 //	@Param			id			path		int						true	"Transaction ID"
 //	@Param			transaction	body		models.TransactionInput	true	"Updated transaction contents"
 //	@Success		200			{object}	Response{data=models.Transaction}
+//	@Failure		400			{object}	Response{error=string}
 //	@Failure		404			{object}	Response{error=string}
+//	@Failure		409			{object}	Response{error=string}
 //	@Router			/transactions/{id} [put]
 //	@Security		BearerAuth
 func UpdateTransaction(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
 	var input models.TransactionInput
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid JSON")
 		return
 	}
+	currency, ok := inAccountCurrency(w, r, s, &input)
+	if !ok {
+		return
+	}
 	if msg := input.Validate(); msg != "" {
 		writeError(w, http.StatusBadRequest, msg)
 		return
 	}
+	existing, err := s.GetTransaction(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "transaction not found")
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+	if existing.Type == "offset" {
+		writeError(w, http.StatusConflict, "offsets cannot be edited; delete the offset and create it again")
+		return
+	}
+	if !checkTransactionUnlocked(w, existing) {
+		return
+	}
+	if existing.ExchangeRate != nil && (input.Amount != existing.Amount ||
+		(input.ExchangeRate != nil && *input.ExchangeRate != *existing.ExchangeRate)) {
+		writeError(w, http.StatusConflict, "the amount and exchange_rate of a transfer between currencies cannot be edited, as its other leg would no longer match; delete the transfer and record it again")
+		return
+	}
+	if input.Amount != existing.Amount && !checkLargeAmount(w, input, currency) {
+		return
+	}
+	if !checkTransactionCurrency(w, r, s, input, &existing) {
+		return
+	}
+	if !checkCategoryType(w, r, s, input.CategoryID, input.Type, input.Type+" transactions") {
+		return
+	}
+	if input.ExternalID != nil {
+		source := stringValue(input.Source)
+		if input.Source == nil {
+			source = stringValue(existing.Source)
+		}
+		other, err := s.FindTransactionByExternalID(input.AccountID, source, *input.ExternalID)
+		if err == nil && other.ID != id {
+			writeError(w, http.StatusConflict, fmt.Sprintf("external_id already used by transaction %d", other.ID))
+			return
+		}
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			writeInternalError(w, r, err)
+			return
+		}
+	}
+	if !checkPeriodOpen(w, r, s, existing.TransactionDate.String(), stringValue(input.TransactionDate)) {
+		return
+	}
 	t, err := s.UpdateTransaction(id, input)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			writeError(w, http.StatusNotFound, "transaction not found")
 		} else {
-			writeError(w, http.StatusInternalServerError, err.Error())
+			writeInternalError(w, r, err)
 		}
 		return
 	}
+	publishEvent(r, "updated", "transaction", t.ID)
 	writeJSON(w, http.StatusOK, t)
 }
 
+// checkTransactionUnlocked writes a 409 and returns false when t is locked.
+func checkTransactionUnlocked(w http.ResponseWriter, t models.Transaction) bool {
+	if !t.Locked {
+		return true
+	}
+	writeError(w, http.StatusConflict, fmt.Sprintf("transaction %d is locked; unlock it before changing it", t.ID))
+	return false
+}
+
+// checkLargeAmount writes a 400 and returns false when the amount, in
+// currency, exceeds LARGE_TXN_THRESHOLD and the request has not set
+// confirmed_large.
+func checkLargeAmount(w http.ResponseWriter, input models.TransactionInput, currency string) bool {
+	threshold := cfg.LargeTxnThreshold.InCurrency(currency)
+	if threshold <= 0 || input.ConfirmedLarge || input.Amount <= threshold {
+		return true
+	}
+	writeError(w, http.StatusBadRequest, fmt.Sprintf(
+		"amount %s %s exceeds the large transaction threshold of %s %s; resend with confirmed_large: true to confirm",
+		input.Amount.Format(currency), currency, threshold.Format(currency), currency))
+	return false
+}
+
+// inAccountCurrency reads input's amounts in the currency of its account,
+// which it returns, writing a 400 and returning false when the account does
+// not exist. Without an account_id it leaves the amounts for Validate to
+// report.
+func inAccountCurrency(w http.ResponseWriter, r *http.Request, s *store.Store, input *models.TransactionInput) (string, bool) {
+	if input.AccountID <= 0 {
+		return "", true
+	}
+	a, err := s.GetAccount(input.AccountID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("account %d not found", input.AccountID))
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return "", false
+	}
+	input.SetCurrency(a.Currency)
+	return a.Currency, true
+}
+
+// checkTransactionCurrency writes a 400 and returns false when input states a
+// currency other than its account's, or, for an update of existing, moves the
+// transaction to an account of another currency, which would reinterpret its
+// amount without converting it.
+func checkTransactionCurrency(w http.ResponseWriter, r *http.Request, s *store.Store, input models.TransactionInput, existing *models.Transaction) bool {
+	moved := existing != nil && existing.AccountID != input.AccountID
+	if input.Currency == "" && !moved {
+		return true
+	}
+	ids := []int{input.AccountID}
+	if moved {
+		ids = append(ids, existing.AccountID)
+	}
+	currencies := make([]string, len(ids))
+	for i, id := range ids {
+		a, err := s.GetAccount(id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("account %d not found", id))
+			} else {
+				writeInternalError(w, r, err)
+			}
+			return false
+		}
+		currencies[i] = a.Currency
+	}
+	if input.Currency != "" && input.Currency != currencies[0] {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("currency %s does not match the %s of account %d", input.Currency, currencies[0], input.AccountID))
+		return false
+	}
+	if moved && currencies[0] != currencies[1] {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("cannot move a %s transaction to a %s account; record a transfer with an exchange_rate instead",
+			currencies[1], currencies[0]))
+		return false
+	}
+	return true
+}
+
+// checkBooksCurrency writes a 400 and returns false when an amount in
+// currency, the currency of account accountID, would be allocated to bills,
+// invoices, and payouts, which are kept in BOOKS_CURRENCY.
+func checkBooksCurrency(w http.ResponseWriter, currency string, accountID int) bool {
+	if currency == "" || currency == store.BooksCurrency {
+		return true
+	}
+	writeError(w, http.StatusBadRequest, fmt.Sprintf("account %d is in %s; only %s amounts can be allocated to documents",
+		accountID, currency, store.BooksCurrency))
+	return false
+}
+
+// booksAmount renders m, an amount in BOOKS_CURRENCY, for a message, e.g.
+// "1234.56 INR".
+func booksAmount(m models.Money) string {
+	return m.Format(store.BooksCurrency) + " " + store.BooksCurrency
+}
+
+// checkTransferCurrencies writes a 400 and returns false when a transfer is
+// between accounts of different currencies without an exchange_rate, carries
+// one between accounts of the same currency, or converts to nothing.
+func checkTransferCurrencies(w http.ResponseWriter, r *http.Request, s *store.Store, input models.TransactionInput) bool {
+	var currencies [2]string
+	for i, id := range []int{input.AccountID, *input.TransferAccountID} {
+		a, err := s.GetAccount(id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("account %d not found", id))
+			} else {
+				writeInternalError(w, r, err)
+			}
+			return false
+		}
+		currencies[i] = a.Currency
+	}
+	from, to := currencies[0], currencies[1]
+	if from != to && input.ExchangeRate == nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("exchange_rate is required for a transfer from %s to %s", from, to))
+		return false
+	}
+	if from == to && input.ExchangeRate != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("exchange_rate is only allowed between accounts of different currencies (both are %s)", from))
+		return false
+	}
+	if _, dest := input.TransferAmounts(from, to); dest <= 0 {
+		writeError(w, http.StatusBadRequest, "exchange_rate is too small: the destination amount rounds to zero")
+		return false
+	}
+	return true
+}
+
+// ReconcileByReference marks transactions reconciled by bank reference
+//	@Summary		Reconcile transactions by reference
+//	@Description	Marks the transaction matching each bank reference as reconciled in one pass, locking it too when LOCK_RECONCILED=true. References with no match or with several matches are reported and left unchanged. Pass account_id to restrict matching to one account.
+//	@Tags			transactions
+//	@Accept			json
+//	@Produce		json
+//	@Param			input	body		models.ReconcileByReferenceInput	true	"References to reconcile"
+//	@Success		200		{object}	Response{data=ReconcileResult}
+//	@Failure		400		{object}	Response{error=string}
+//	@Router			/transactions/reconcile-by-reference [post]
+//	@Security		BearerAuth
+func ReconcileByReference(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	var input models.ReconcileByReferenceInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if msg := input.Validate(); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	result, err := s.ReconcileByReference(input.AccountID, input.References)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	for _, id := range result.Reconciled {
+		publishEvent(r, "updated", "transaction", id)
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// ReconcileResult is an alias for store.ReconcileResult kept here for Swagger doc references.
+type ReconcileResult = store.ReconcileResult
+
 // DeleteTransaction deletes a transaction
 //	@Summary		Delete transaction
-//	@Description	Remove a transaction.
+//	@Description	Remove a transaction. Locked transactions are rejected with 409.
 //	@Tags			transactions
 //	@Produce		json
 //	@Param			id	path		int	true	"Transaction ID"
 //	@Success		200	{object}	Response{data=map[string]string}
+//	@Failure		400	{object}	Response{error=string}
 //	@Failure		404	{object}	Response{error=string}
+//	@Failure		409	{object}	Response{error=string}
 //	@Router			/transactions/{id} [delete]
 //	@Security		BearerAuth
 func DeleteTransaction(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+	existing, err := s.GetTransaction(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "transaction not found")
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+	if !checkTransactionUnlocked(w, existing) {
+		return
+	}
+	if !checkPeriodOpen(w, r, s, existing.TransactionDate.String()) {
+		return
+	}
 	if err := s.DeleteTransaction(id); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			writeError(w, http.StatusNotFound, "transaction not found")
 		} else {
-			writeError(w, http.StatusInternalServerError, err.Error())
+			writeInternalError(w, r, err)
 		}
 		return
 	}
+	publishEvent(r, "deleted", "transaction", id)
 	writeJSON(w, http.StatusOK, map[string]string{"message": "deleted"})
 }
 
+// LockTransaction freezes a transaction against edits
+//	@Summary		Lock transaction
+//	@Description	Lock a transaction so that updates and deletion are rejected with 409 until it is unlocked, e.g. once it has been reconciled and verified.
+//	@Description	Links to bills and invoices can still be added and removed. Setting LOCK_RECONCILED=true locks transactions as they are reconciled.
+//	@Description	When ADMIN_API_KEY is configured the request must carry it in the X-Admin-Key header.
+//	@Tags			transactions
+//	@Produce		json
+//	@Param			X-Admin-Key	header		string	false	"Admin API key"
+//	@Param			id			path		int		true	"Transaction ID"
+//	@Success		200			{object}	Response{data=models.Transaction}
+//	@Failure		400			{object}	Response{error=string}
+//	@Failure		403			{object}	Response{error=string}
+//	@Failure		404			{object}	Response{error=string}
+//	@Router			/transactions/{id}/lock [post]
+//	@Security		BearerAuth
+func LockTransaction(w http.ResponseWriter, r *http.Request) {
+	setTransactionLocked(w, r, true)
+}
+
+// UnlockTransaction lifts the lock on a transaction
+//	@Summary		Unlock transaction
+//	@Description	Unlock a locked transaction so that it can be updated or deleted again. When ADMIN_API_KEY is configured the request must carry it in the X-Admin-Key header.
+//	@Tags			transactions
+//	@Produce		json
+//	@Param			X-Admin-Key	header		string	false	"Admin API key"
+//	@Param			id			path		int		true	"Transaction ID"
+//	@Success		200			{object}	Response{data=models.Transaction}
+//	@Failure		400			{object}	Response{error=string}
+//	@Failure		403			{object}	Response{error=string}
+//	@Failure		404			{object}	Response{error=string}
+//	@Router			/transactions/{id}/unlock [post]
+//	@Security		BearerAuth
+func UnlockTransaction(w http.ResponseWriter, r *http.Request) {
+	setTransactionLocked(w, r, false)
+}
+
+// setTransactionLocked sets the locked flag of the transaction named by the
+// id URL parameter and writes the updated transaction. Only admins may lock
+// or unlock.
+func setTransactionLocked(w http.ResponseWriter, r *http.Request, locked bool) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	s := store.New(getDB(r))
+	id, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+	t, err := s.SetTransactionLocked(id, locked)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "transaction not found")
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+	publishEvent(r, "updated", "transaction", t.ID)
+	writeJSON(w, http.StatusOK, t)
+}
+
 // --- Transaction Document Linking ---
 
 // ListTransactionLinks lists all documents linked to a transaction
 //	@Summary		List transaction links
-//	@Description	Get all bills and invoices linked (paid) by this transaction.
+//	@Description	Get bills and invoices linked (paid) by this transaction, oldest first unless order=desc. Supports limit/offset pagination; totals across all links are returned in headers.
 //	@Tags			transactions
 //	@Produce		json
-//	@Param			id	path		int	true	"Transaction ID"
-//	@Success		200	{object}	Response{data=[]models.TransactionDocument}
+//	@Param			id		path		int		true	"Transaction ID"
+//	@Param			limit	query		int		false	"Maximum number of links to return (default DEFAULT_PAGE_SIZE, or all; at most MAX_PAGE_SIZE)"
+//	@Param			offset	query		int		false	"Number of links to skip"
+//	@Param			order	query		string	false	"Sort by created_at: asc (default) or desc"
+//	@Success		200		{object}	Response{data=[]models.TransactionDocument}
+//	@Header			200		{int}		X-Total-Count		"Total number of links"
+//	@Header			200		{int}		X-Total-Allocated	"Total allocated across all links, in paise"
+//	@Header			200		{int}		X-Page-Size-Clamped	"Limit applied when the requested page exceeded MAX_PAGE_SIZE"
+//	@Failure		400		{object}	Response{error=string}
 //	@Router			/transactions/{id}/links [get]
 //	@Security		BearerAuth
 func ListTransactionLinks(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	txnID, _ := strconv.Atoi(chi.URLParam(r, "id"))
-	docs, err := s.ListTransactionLinks(txnID)
+	txnID, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+	page, msg := parseLinkPage(w, r)
+	if msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	docs, err := s.ListTransactionLinks(txnID, page)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeInternalError(w, r, err)
 		return
 	}
+	totals, err := s.TransactionLinkTotals(txnID)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	setLinkTotalHeaders(w, totals)
 	writeJSON(w, http.StatusOK, docs)
 }
 
-// CreateTransactionLink links a transaction to a bill or invoice
+// AllocationGraph is an alias for store.AllocationGraph kept here for Swagger doc references.
+type AllocationGraph = store.AllocationGraph
+
+// GetAllocationGraph returns the full allocation picture of a transaction
+//	@Summary		Get transaction allocation graph
+//	@Description	Get the transaction, each document it is linked to with that document's amount, total allocation, and status,
+//	@Description	and the links from other transactions to the same documents. Use it to see why a document is partial or paid.
+//	@Tags			transactions
+//	@Produce		json
+//	@Param			id	path		int	true	"Transaction ID"
+//	@Success		200	{object}	Response{data=AllocationGraph}
+//	@Failure		400	{object}	Response{error=string}
+//	@Failure		404	{object}	Response{error=string}
+//	@Router			/transactions/{id}/allocation-graph [get]
+//	@Security		BearerAuth
+func GetAllocationGraph(w http.ResponseWriter, r *http.Request) {
+	s := store.New(getDB(r))
+	txnID, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+	g, err := s.GetAllocationGraph(txnID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "transaction not found")
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, g)
+}
+
+// CreateTransactionLink links a transaction to a bill, invoice, payout or recurring payment occurrence
 //	@Summary		Create transaction link
-//	@Description	Allocate an amount from a transaction to a specific bill or invoice.
+//	@Description	Allocate an amount from a transaction to a specific bill, invoice, payout or recurring payment occurrence. The amount may exceed the
+//	@Description	document's unallocated amount by up to ALLOCATION_TOLERANCE_PAISE to absorb rounding. Linking to a document
+//	@Description	already marked paid/received (e.g. after its amount was raised) is allowed and returns a warning.
+//	@Description	An optional note (up to 500 characters) records why the amount was allocated, e.g. a retention held back.
+//	@Description	Documents are kept in BOOKS_CURRENCY, so a transaction on an account in another currency cannot be linked (400).
 //	@Tags			transactions
 //	@Accept			json
 //	@Produce		json
 //	@Param			id		path		int								true	"Transaction ID"
 //	@Param			link	body		models.TransactionDocumentInput	true	"Link details"
-//	@Success		201		{object}	Response{data=models.TransactionDocument}
+//	@Success		201		{object}	Response{data=TransactionLinkResult}
 //	@Router			/transactions/{id}/links [post]
 //	@Security		BearerAuth
 func CreateTransactionLink(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	txnID, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	txnID, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
 
 	var input models.TransactionDocumentInput
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
@@ -198,43 +804,95 @@ func CreateTransactionLink(w http.ResponseWriter, r *http.Request) {
 	// Check transaction exists and get its unallocated balance
 	txn, err := s.GetTransaction(txnID)
 	if err != nil {
-		writeError(w, http.StatusNotFound, "transaction not found")
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "transaction not found")
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+	if txn.Type == "adjustment" || txn.Type == "offset" {
+		writeError(w, http.StatusBadRequest, txn.Type+"s cannot be linked to documents")
+		return
+	}
+	if !checkBooksCurrency(w, txn.Currency, txn.AccountID) {
 		return
 	}
 	if input.Amount > txn.Unallocated {
-		writeError(w, http.StatusBadRequest, fmt.Sprintf("transaction only has %d paise unallocated (requested %d)", txn.Unallocated, input.Amount))
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("transaction only has %s unallocated (requested %s)", booksAmount(txn.Unallocated), booksAmount(input.Amount)))
 		return
 	}
 
 	// Check document exists and get its unallocated balance
 	docAmount, docAllocated, err := s.GetDocumentAmountAndAllocated(input.DocumentType, input.DocumentID)
 	if err != nil {
-		if input.DocumentType == "bill" || input.DocumentType == "invoice" || input.DocumentType == "payout" || input.DocumentType == "recurring_payment_occurrence" {
+		if errors.Is(err, sql.ErrNoRows) {
 			writeError(w, http.StatusNotFound, fmt.Sprintf("%s not found", input.DocumentType))
 		} else {
-			writeError(w, http.StatusBadRequest, "invalid document type")
+			writeInternalError(w, r, err)
 		}
 		return
 	}
 	docUnallocated := models.Money(int64(docAmount) - int64(docAllocated))
-	if input.Amount > docUnallocated {
-		writeError(w, http.StatusBadRequest, fmt.Sprintf("%s only has %d paise unallocated (requested %d)", input.DocumentType, docUnallocated, input.Amount))
+	if input.Amount > docUnallocated+cfg.AllocationTolerance {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("%s only has %s unallocated (requested %s)", input.DocumentType, booksAmount(docUnallocated), booksAmount(input.Amount)))
+		return
+	}
+
+	prevStatus, err := s.GetDocumentStatus(input.DocumentType, input.DocumentID)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	if prevStatus == "cancelled" && (input.DocumentType == "bill" || input.DocumentType == "invoice") {
+		writeError(w, http.StatusConflict, fmt.Sprintf("%s is voided and cannot take allocations", input.DocumentType))
 		return
 	}
 
 	td, err := s.CreateTransactionLink(txnID, input)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeInternalError(w, r, err)
 		return
 	}
 
+	note := ""
+	if td.Note != nil {
+		note = *td.Note
+	}
+	slog.InfoContext(r.Context(), "audit: transaction link created", "tenant_id", getTenant(r), "link_id", td.ID,
+		"transaction_id", txnID, "document_type", td.DocumentType, "document_id", td.DocumentID,
+		"amount", td.Amount, "note", note)
+
 	s.UpdateDocumentStatus(input.DocumentType, input.DocumentID)
-	writeJSON(w, http.StatusCreated, td)
+	result := TransactionLinkResult{TransactionDocument: td}
+	// A document already marked settled can still have room when its amount
+	// was raised afterwards (e.g. a late adjustment). Allow the link, but say
+	// that the status was recomputed.
+	if prevStatus == "paid" || prevStatus == "received" {
+		newStatus, err := s.GetDocumentStatus(input.DocumentType, input.DocumentID)
+		if err != nil {
+			writeInternalError(w, r, err)
+			return
+		}
+		result.Warnings = append(result.Warnings, fmt.Sprintf(
+			"%s was marked %s with %s unallocated; status recomputed to %s",
+			input.DocumentType, prevStatus, booksAmount(docUnallocated), newStatus))
+	}
+	publishEvent(r, "updated", "transaction", txnID)
+	publishEvent(r, "updated", input.DocumentType, input.DocumentID)
+	writeJSON(w, http.StatusCreated, result)
+}
+
+// TransactionLinkResult is a created link plus any warnings about the
+// linked document, such as its status having been recomputed.
+type TransactionLinkResult struct {
+	models.TransactionDocument
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 // DeleteTransactionLink removes a link between a transaction and a document
 //	@Summary		Delete transaction link
-//	@Description	Deallocate an amount from a transaction to a bill or invoice.
+//	@Description	Deallocate an amount from a transaction to a bill, invoice, payout or recurring payment occurrence.
 //	@Tags			transactions
 //	@Produce		json
 //	@Param			id		path		int	true	"Transaction ID"
@@ -244,17 +902,24 @@ func CreateTransactionLink(w http.ResponseWriter, r *http.Request) {
 //	@Security		BearerAuth
 func DeleteTransactionLink(w http.ResponseWriter, r *http.Request) {
 	s := store.New(getDB(r))
-	txnID, _ := strconv.Atoi(chi.URLParam(r, "id"))
-	linkID, _ := strconv.Atoi(chi.URLParam(r, "linkId"))
+	txnID, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+	linkID, ok := pathInt(w, r, "linkId")
+	if !ok {
+		return
+	}
 
 	if err := s.DeleteTransactionLink(txnID, linkID); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			writeError(w, http.StatusNotFound, "link not found")
 		} else {
-			writeError(w, http.StatusInternalServerError, err.Error())
+			writeInternalError(w, r, err)
 		}
 		return
 	}
 
+	publishEvent(r, "updated", "transaction", txnID)
 	writeJSON(w, http.StatusOK, map[string]string{"message": "deleted"})
 }
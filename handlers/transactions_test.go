@@ -0,0 +1,1112 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestReconcileByReference verifies that unique references are reconciled and
+// that unmatched and ambiguous references are reported without changes.
+func TestReconcileByReference(t *testing.T) {
+	r, cleanup := setupTestRouter(t)
+	defer cleanup()
+	r.Get("/api/v1/transactions/{id}", GetTransaction)
+	r.Post("/api/v1/transactions/reconcile-by-reference", ReconcileByReference)
+
+	_, resp := apiRequest(t, r, "POST", "/api/v1/accounts", map[string]interface{}{
+		"name": "Bank Account", "type": "bank", "opening_balance": 0,
+	})
+	accID := int(resp["data"].(map[string]interface{})["id"].(float64))
+
+	createTxn := func(ref string) int {
+		status, resp := apiRequest(t, r, "POST", "/api/v1/transactions", map[string]interface{}{
+			"account_id": accID, "type": "income", "amount": 10.0,
+			"transaction_date": "2024-01-15", "reference": ref,
+		})
+		if status != http.StatusCreated {
+			t.Fatalf("create transaction: status %d, error %v", status, resp["error"])
+		}
+		return int(resp["data"].(map[string]interface{})["id"].(float64))
+	}
+	uniqueID := createTxn("REF-1")
+	dupA := createTxn("REF-2")
+	createTxn("REF-2")
+
+	status, resp := apiRequest(t, r, "POST", "/api/v1/transactions/reconcile-by-reference", map[string]interface{}{
+		"references": []string{"REF-1", "REF-2", "REF-404"},
+	})
+	if status != http.StatusOK {
+		t.Fatalf("reconcile: status %d, error %v", status, resp["error"])
+	}
+	data := resp["data"].(map[string]interface{})
+
+	reconciled := data["reconciled"].([]interface{})
+	if len(reconciled) != 1 || int(reconciled[0].(float64)) != uniqueID {
+		t.Errorf("expected reconciled [%d], got %v", uniqueID, reconciled)
+	}
+	unmatched := data["unmatched"].([]interface{})
+	if len(unmatched) != 1 || unmatched[0] != "REF-404" {
+		t.Errorf("expected unmatched [REF-404], got %v", unmatched)
+	}
+	ambiguous := data["ambiguous"].(map[string]interface{})
+	if ids, ok := ambiguous["REF-2"].([]interface{}); !ok || len(ids) != 2 {
+		t.Errorf("expected REF-2 to be ambiguous with 2 matches, got %v", ambiguous)
+	}
+
+	_, resp = apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/transactions/%d", uniqueID), nil)
+	if resp["data"].(map[string]interface{})["reconciled"] != true {
+		t.Error("expected unique match to be reconciled")
+	}
+	_, resp = apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/transactions/%d", dupA), nil)
+	if resp["data"].(map[string]interface{})["reconciled"] != false {
+		t.Error("expected ambiguous match to stay unreconciled")
+	}
+
+	status, _ = apiRequest(t, r, "POST", "/api/v1/transactions/reconcile-by-reference", map[string]interface{}{
+		"references": []string{},
+	})
+	if status != http.StatusBadRequest {
+		t.Errorf("expected 400 for empty references, got %d", status)
+	}
+}
+
+// TestCreateTransferWithFee verifies that a transfer fee is booked as a
+// separate expense on the source account and the legs move the net amount.
+func TestCreateTransferWithFee(t *testing.T) {
+	r, cleanup := setupTestRouter(t)
+	defer cleanup()
+	r.Get("/api/v1/transactions", ListTransactions)
+
+	_, resp := apiRequest(t, r, "POST", "/api/v1/accounts", map[string]interface{}{
+		"name": "Source", "type": "bank", "opening_balance": 0,
+	})
+	srcID := int(resp["data"].(map[string]interface{})["id"].(float64))
+	_, resp = apiRequest(t, r, "POST", "/api/v1/accounts", map[string]interface{}{
+		"name": "Destination", "type": "bank", "opening_balance": 0,
+	})
+	dstID := int(resp["data"].(map[string]interface{})["id"].(float64))
+
+	status, resp := apiRequest(t, r, "POST", "/api/v1/transactions", map[string]interface{}{
+		"account_id": srcID, "type": "transfer", "amount": 100.0, "fee_amount": 5.0,
+		"transfer_account_id": dstID, "transaction_date": "2024-01-15",
+	})
+	if status != http.StatusCreated {
+		t.Fatalf("create transfer: status %d, error %v", status, resp["error"])
+	}
+	if got := resp["data"].(map[string]interface{})["amount"].(float64); got != 9500 {
+		t.Errorf("expected transfer leg amount 9500, got %v", got)
+	}
+
+	amounts := func(accountID int) []float64 {
+		_, resp := apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/transactions?account_id=%d", accountID), nil)
+		var out []float64
+		for _, item := range resp["data"].([]interface{}) {
+			out = append(out, item.(map[string]interface{})["amount"].(float64))
+		}
+		return out
+	}
+	src := amounts(srcID)
+	if len(src) != 2 || src[0]+src[1] != 10000 {
+		t.Errorf("expected source to carry 9500 leg and 500 fee, got %v", src)
+	}
+	if dst := amounts(dstID); len(dst) != 1 || dst[0] != 9500 {
+		t.Errorf("expected destination leg 9500, got %v", dst)
+	}
+
+	status, _ = apiRequest(t, r, "POST", "/api/v1/transactions", map[string]interface{}{
+		"account_id": srcID, "type": "transfer", "amount": 5.0, "fee_amount": 5.0,
+		"transfer_account_id": dstID,
+	})
+	if status != http.StatusBadRequest {
+		t.Errorf("expected 400 for fee equal to amount, got %d", status)
+	}
+	status, _ = apiRequest(t, r, "POST", "/api/v1/transactions", map[string]interface{}{
+		"account_id": srcID, "type": "expense", "amount": 5.0, "fee_amount": 1.0,
+	})
+	if status != http.StatusBadRequest {
+		t.Errorf("expected 400 for fee on a non-transfer, got %d", status)
+	}
+}
+
+// TestCreateCrossCurrencyTransfer verifies that a transfer between accounts of
+// different currencies requires an exchange rate, converts the destination
+// leg by the rate and the currencies' minor units, stores the rate on both
+// legs, and refuses edits to a leg's amount.
+func TestCreateCrossCurrencyTransfer(t *testing.T) {
+	r, cleanup := setupTestRouter(t)
+	defer cleanup()
+	r.Get("/api/v1/transactions", ListTransactions)
+	r.Put("/api/v1/transactions/{id}", UpdateTransaction)
+
+	account := func(name, currency string) int {
+		status, resp := apiRequest(t, r, "POST", "/api/v1/accounts", map[string]interface{}{
+			"name": name, "type": "bank", "currency": currency,
+		})
+		if status != http.StatusCreated {
+			t.Fatalf("create account: status %d, error %v", status, resp["error"])
+		}
+		return int(resp["data"].(map[string]interface{})["id"].(float64))
+	}
+	usdID, inrID, inr2ID := account("USD Wallet", "usd"), account("Current", ""), account("Savings", "INR")
+
+	transfer := func(from, to int, rate interface{}) (int, map[string]interface{}) {
+		body := map[string]interface{}{
+			"account_id": from, "type": "transfer", "amount": 100.0, "fee_amount": 1.0,
+			"transfer_account_id": to, "transaction_date": "2024-01-15",
+		}
+		if rate != nil {
+			body["exchange_rate"] = rate
+		}
+		return apiRequest(t, r, "POST", "/api/v1/transactions", body)
+	}
+
+	if status, _ := transfer(usdID, inrID, nil); status != http.StatusBadRequest {
+		t.Errorf("cross-currency transfer without rate: expected 400, got %d", status)
+	}
+	if status, _ := transfer(inrID, inr2ID, 1.5); status != http.StatusBadRequest {
+		t.Errorf("same-currency transfer with rate: expected 400, got %d", status)
+	}
+	if status, _ := transfer(usdID, inrID, -2.0); status != http.StatusBadRequest {
+		t.Errorf("negative rate: expected 400, got %d", status)
+	}
+
+	status, resp := transfer(usdID, inrID, 83.25)
+	if status != http.StatusCreated {
+		t.Fatalf("create transfer: status %d, error %v", status, resp["error"])
+	}
+	leg := resp["data"].(map[string]interface{})
+	if leg["amount"].(float64) != 9900 || leg["exchange_rate"].(float64) != 83.25 {
+		t.Errorf("unexpected source leg: %v", leg)
+	}
+
+	_, resp = apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/transactions?account_id=%d", inrID), nil)
+	legs := resp["data"].([]interface{})
+	if len(legs) != 1 {
+		t.Fatalf("expected one destination leg, got %v", legs)
+	}
+	// 99.00 USD (net of the fee) at 83.25 is 8241.75 INR.
+	if dst := legs[0].(map[string]interface{}); dst["amount"].(float64) != 824175 || dst["exchange_rate"].(float64) != 83.25 {
+		t.Errorf("unexpected destination leg: %v", dst)
+	}
+
+	if status, _ := apiRequest(t, r, "PUT", fmt.Sprintf("/api/v1/transactions/%d", int(leg["id"].(float64))), map[string]interface{}{
+		"account_id": usdID, "type": "expense", "amount": 50.0, "transfer_account_id": inrID, "transaction_date": "2024-01-15",
+	}); status != http.StatusConflict {
+		t.Errorf("editing the amount of a cross-currency leg: expected 409, got %d", status)
+	}
+
+	// Amounts are read in each account's minor units: 1000 yen is 1000, and
+	// at 0.56 rupees a yen converts to 560 rupees.
+	yenID := account("Yen", "JPY")
+	status, resp = apiRequest(t, r, "POST", "/api/v1/transactions", map[string]interface{}{
+		"account_id": yenID, "type": "transfer", "amount": 1000.0, "transfer_account_id": inr2ID,
+		"exchange_rate": 0.56, "transaction_date": "2024-01-16",
+	})
+	if status != http.StatusCreated {
+		t.Fatalf("yen transfer: status %d, error %v", status, resp["error"])
+	}
+	if src := resp["data"].(map[string]interface{}); src["amount"].(float64) != 1000 {
+		t.Errorf("yen transfer source leg = %v, want 1000 yen", src["amount"])
+	}
+	_, resp = apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/transactions?account_id=%d", inr2ID), nil)
+	if dst := resp["data"].([]interface{})[0].(map[string]interface{}); dst["amount"].(float64) != 56000 {
+		t.Errorf("yen transfer destination leg = %v, want 56000 paise", dst["amount"])
+	}
+}
+
+// TestCreateTransferAutoReference verifies that both legs of a transfer without
+// a reference share one derived from the first leg's id.
+func TestCreateTransferAutoReference(t *testing.T) {
+	r, cleanup := setupTestRouter(t)
+	defer cleanup()
+	r.Get("/api/v1/transactions", ListTransactions)
+
+	var accIDs []int
+	for _, name := range []string{"Source", "Destination"} {
+		_, resp := apiRequest(t, r, "POST", "/api/v1/accounts", map[string]interface{}{
+			"name": name, "type": "bank", "opening_balance": 0,
+		})
+		accIDs = append(accIDs, int(resp["data"].(map[string]interface{})["id"].(float64)))
+	}
+
+	status, resp := apiRequest(t, r, "POST", "/api/v1/transactions", map[string]interface{}{
+		"account_id": accIDs[0], "type": "transfer", "amount": 50.0, "transfer_account_id": accIDs[1],
+	})
+	if status != http.StatusCreated {
+		t.Fatalf("create transfer: status %d, error %v", status, resp["error"])
+	}
+	first := resp["data"].(map[string]interface{})
+	want := fmt.Sprintf("TRF-%d", int(first["id"].(float64)))
+	if first["reference"] != want {
+		t.Errorf("expected reference %s, got %v", want, first["reference"])
+	}
+
+	_, resp = apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/transactions?account_id=%d", accIDs[1]), nil)
+	legs := resp["data"].([]interface{})
+	if len(legs) != 1 || legs[0].(map[string]interface{})["reference"] != want {
+		t.Errorf("expected destination leg with reference %s, got %v", want, legs)
+	}
+}
+
+// TestCreateAdjustment verifies that adjustments move the account balance in
+// the direction of their sign and cannot be allocated to documents.
+func TestCreateAdjustment(t *testing.T) {
+	r, cleanup := setupTestRouter(t)
+	defer cleanup()
+	r.Get("/api/v1/accounts/{id}", GetAccount)
+	r.Post("/api/v1/bills", CreateBill)
+
+	_, resp := apiRequest(t, r, "POST", "/api/v1/accounts", map[string]interface{}{
+		"name": "Current", "type": "bank", "opening_balance": 1000.0,
+	})
+	accID := int(resp["data"].(map[string]interface{})["id"].(float64))
+
+	adjust := func(amount float64, sign interface{}) (int, map[string]interface{}) {
+		body := map[string]interface{}{
+			"account_id": accID, "type": "adjustment", "amount": amount, "transaction_date": "2024-01-15",
+		}
+		if sign != nil {
+			body["sign"] = sign
+		}
+		return apiRequest(t, r, "POST", "/api/v1/transactions", body)
+	}
+
+	if status, _ := adjust(10, nil); status != http.StatusBadRequest {
+		t.Errorf("adjustment without sign: expected 400, got %d", status)
+	}
+	if status, _ := adjust(10, 2); status != http.StatusBadRequest {
+		t.Errorf("adjustment with sign 2: expected 400, got %d", status)
+	}
+	if status, _ := apiRequest(t, r, "POST", "/api/v1/transactions", map[string]interface{}{
+		"account_id": accID, "type": "income", "amount": 10.0, "sign": 1,
+	}); status != http.StatusBadRequest {
+		t.Errorf("income with sign: expected 400, got %d", status)
+	}
+
+	status, resp := adjust(250, -1)
+	if status != http.StatusCreated {
+		t.Fatalf("create adjustment: status %d, error %v", status, resp["error"])
+	}
+	down := resp["data"].(map[string]interface{})
+	if down["type"] != "adjustment" || down["sign"] != float64(-1) {
+		t.Errorf("unexpected adjustment: %v", down)
+	}
+	if status, resp = adjust(50, 1); status != http.StatusCreated {
+		t.Fatalf("create adjustment: status %d, error %v", status, resp["error"])
+	}
+
+	_, resp = apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/accounts/%d", accID), nil)
+	if balance := resp["data"].(map[string]interface{})["balance"]; balance != float64(80000) {
+		t.Errorf("expected balance 80000 paise (1000 - 250 + 50), got %v", balance)
+	}
+
+	bill := createTestBill(t, r)
+	status, resp = apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/transactions/%d/links", int(down["id"].(float64))), map[string]interface{}{
+		"document_type": "bill", "document_id": bill, "amount": 10.0,
+	})
+	if status != http.StatusBadRequest {
+		t.Errorf("linking an adjustment: expected 400, got %d (%v)", status, resp["error"])
+	}
+}
+
+// TestPendingTransactions verifies that uncleared transactions count towards
+// an account's projected balance but not its cleared balance, and that
+// clearing one on update brings the two back together.
+func TestPendingTransactions(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+
+	accID := createResource(t, r, "/api/v1/accounts", map[string]interface{}{
+		"name": "Current", "type": "bank", "opening_balance": 1000.0,
+	})
+	createResource(t, r, "/api/v1/transactions", map[string]interface{}{
+		"account_id": accID, "type": "income", "amount": 200.0, "transaction_date": "2024-01-10",
+	})
+	chequeID := createResource(t, r, "/api/v1/transactions", map[string]interface{}{
+		"account_id": accID, "type": "expense", "amount": 300.0, "transaction_date": "2024-01-12",
+		"reference": "CHQ-001", "cleared": false,
+	})
+
+	if status, _ := apiRequest(t, r, "POST", "/api/v1/transactions", map[string]interface{}{
+		"account_id": accID, "type": "expense", "amount": 1.0, "cleared": false, "cleared_date": "2024-01-12",
+	}); status != http.StatusBadRequest {
+		t.Errorf("pending with cleared_date: expected 400, got %d", status)
+	}
+
+	balances := func() (cleared, projected interface{}) {
+		t.Helper()
+		_, resp := apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/accounts/%d", accID), nil)
+		data := resp["data"].(map[string]interface{})
+		return data["cleared_balance"], data["projected_balance"]
+	}
+	if cleared, projected := balances(); cleared != float64(120000) || projected != float64(90000) {
+		t.Errorf("with a pending cheque: cleared %v, projected %v; want 120000 and 90000", cleared, projected)
+	}
+
+	_, resp := apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/transactions?account_id=%d", accID), nil)
+	for _, item := range resp["data"].([]interface{}) {
+		txn := item.(map[string]interface{})
+		if want := int(txn["id"].(float64)) != chequeID; txn["cleared"] != want {
+			t.Errorf("transaction %v: cleared = %v, want %v", txn["id"], txn["cleared"], want)
+		}
+	}
+
+	// An update that does not mention cleared keeps the cheque pending.
+	status, resp := apiRequest(t, r, "PUT", fmt.Sprintf("/api/v1/transactions/%d", chequeID), map[string]interface{}{
+		"account_id": accID, "type": "expense", "amount": 300.0, "transaction_date": "2024-01-12",
+		"reference": "CHQ-001", "description": "Rent",
+	})
+	if status != http.StatusOK || resp["data"].(map[string]interface{})["cleared"] != false {
+		t.Fatalf("update without cleared: status %d, data %v", status, resp["data"])
+	}
+
+	status, resp = apiRequest(t, r, "PUT", fmt.Sprintf("/api/v1/transactions/%d", chequeID), map[string]interface{}{
+		"account_id": accID, "type": "expense", "amount": 300.0, "transaction_date": "2024-01-12",
+		"reference": "CHQ-001", "description": "Rent", "cleared_date": "15-01-2024",
+	})
+	if status != http.StatusOK {
+		t.Fatalf("clear cheque: status %d, error %v", status, resp["error"])
+	}
+	data := resp["data"].(map[string]interface{})
+	if data["cleared"] != true || data["cleared_date"] != "2024-01-15" {
+		t.Errorf("after clearing: cleared %v, cleared_date %v", data["cleared"], data["cleared_date"])
+	}
+	if cleared, projected := balances(); cleared != float64(90000) || projected != float64(90000) {
+		t.Errorf("after clearing: cleared %v, projected %v; want 90000 for both", cleared, projected)
+	}
+}
+
+// TestListSuspenseTransactions verifies that only unlinked income and expense
+// transactions older than the configured age are listed for review.
+func TestListSuspenseTransactions(t *testing.T) {
+	r, cleanup := setupTestRouter(t)
+	defer cleanup()
+	r.Post("/api/v1/bills", CreateBill)
+	r.Get("/api/v1/transactions/suspense", ListSuspenseTransactions)
+	withTestConfig(t, Config{SuspenseDays: 30})
+
+	var accIDs []int
+	for _, name := range []string{"Current", "Savings"} {
+		_, resp := apiRequest(t, r, "POST", "/api/v1/accounts", map[string]interface{}{"name": name, "type": "bank"})
+		accIDs = append(accIDs, int(resp["data"].(map[string]interface{})["id"].(float64)))
+	}
+	create := func(body map[string]interface{}) int {
+		body["account_id"] = accIDs[0]
+		status, resp := apiRequest(t, r, "POST", "/api/v1/transactions", body)
+		if status != http.StatusCreated {
+			t.Fatalf("create transaction: status %d, error %v", status, resp["error"])
+		}
+		return int(resp["data"].(map[string]interface{})["id"].(float64))
+	}
+	today := time.Now().Format("2006-01-02")
+	stale := create(map[string]interface{}{"type": "income", "amount": 10.0, "transaction_date": "2020-01-01"})
+	linked := create(map[string]interface{}{"type": "expense", "amount": 20.0, "transaction_date": "2020-01-02"})
+	recent := create(map[string]interface{}{"type": "expense", "amount": 30.0, "transaction_date": today})
+	create(map[string]interface{}{"type": "transfer", "amount": 40.0, "transaction_date": "2020-01-03", "transfer_account_id": accIDs[1]})
+	create(map[string]interface{}{"type": "adjustment", "amount": 50.0, "transaction_date": "2020-01-04", "sign": 1})
+
+	if status, resp := apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/transactions/%d/links", linked), map[string]interface{}{
+		"document_type": "bill", "document_id": createTestBill(t, r), "amount": 20.0,
+	}); status != http.StatusCreated {
+		t.Fatalf("create link: status %d, error %v", status, resp["error"])
+	}
+
+	ids := func(query string) []int {
+		status, resp := apiRequest(t, r, "GET", "/api/v1/transactions/suspense"+query, nil)
+		if status != http.StatusOK {
+			t.Fatalf("suspense%s: status %d, error %v", query, status, resp["error"])
+		}
+		var got []int
+		for _, item := range resp["data"].([]interface{}) {
+			txn := item.(map[string]interface{})
+			if txn["account_name"] != "Current" {
+				t.Errorf("expected account_name Current, got %v", txn["account_name"])
+			}
+			got = append(got, int(txn["id"].(float64)))
+		}
+		return got
+	}
+
+	if got := ids(""); len(got) != 1 || got[0] != stale {
+		t.Errorf("default age: expected [%d], got %v", stale, got)
+	}
+	if got := ids("?days=0"); len(got) != 2 || got[0] != stale || got[1] != recent {
+		t.Errorf("days=0: expected [%d %d], got %v", stale, recent, got)
+	}
+	if status, _ := apiRequest(t, r, "GET", "/api/v1/transactions/suspense?days=-1", nil); status != http.StatusBadRequest {
+		t.Errorf("days=-1: expected 400, got %d", status)
+	}
+}
+
+// TestDuplicateTransactions verifies that transactions sharing account, type,
+// amount, and date are grouped, that match_reference splits them by reference,
+// and that auto_resolve=keep_oldest deletes all but the earliest created.
+func TestDuplicateTransactions(t *testing.T) {
+	r, cleanup := setupTestRouter(t)
+	defer cleanup()
+	r.Get("/api/v1/transactions/duplicates", ListDuplicateTransactions)
+	r.Post("/api/v1/transactions/duplicates", ListDuplicateTransactions)
+	r.Get("/api/v1/transactions/{id}", GetTransaction)
+
+	_, resp := apiRequest(t, r, "POST", "/api/v1/accounts", map[string]interface{}{"name": "Current", "type": "bank"})
+	accID := int(resp["data"].(map[string]interface{})["id"].(float64))
+	create := func(body map[string]interface{}) int {
+		body["account_id"] = accID
+		status, resp := apiRequest(t, r, "POST", "/api/v1/transactions", body)
+		if status != http.StatusCreated {
+			t.Fatalf("create transaction: status %d, error %v", status, resp["error"])
+		}
+		return int(resp["data"].(map[string]interface{})["id"].(float64))
+	}
+	first := create(map[string]interface{}{"type": "expense", "amount": 10.0, "transaction_date": "2024-03-01", "reference": "A"})
+	second := create(map[string]interface{}{"type": "expense", "amount": 10.0, "transaction_date": "2024-03-01", "reference": "B"})
+	third := create(map[string]interface{}{"type": "expense", "amount": 10.0, "transaction_date": "2024-03-01", "reference": "A"})
+	create(map[string]interface{}{"type": "income", "amount": 10.0, "transaction_date": "2024-03-01"})
+	create(map[string]interface{}{"type": "expense", "amount": 10.0, "transaction_date": "2024-03-02"})
+
+	groupIDs := func(method, query string) ([][]int, map[string]interface{}) {
+		status, resp := apiRequest(t, r, method, "/api/v1/transactions/duplicates"+query, nil)
+		if status != http.StatusOK {
+			t.Fatalf("duplicates%s: status %d, error %v", query, status, resp["error"])
+		}
+		data := resp["data"].(map[string]interface{})
+		var got [][]int
+		for _, g := range data["groups"].([]interface{}) {
+			var ids []int
+			for _, txn := range g.(map[string]interface{})["transactions"].([]interface{}) {
+				ids = append(ids, int(txn.(map[string]interface{})["id"].(float64)))
+			}
+			got = append(got, ids)
+		}
+		return got, data
+	}
+
+	if got, _ := groupIDs("GET", ""); len(got) != 1 || fmt.Sprint(got[0]) != fmt.Sprint([]int{first, second, third}) {
+		t.Errorf("expected one group [%d %d %d], got %v", first, second, third, got)
+	}
+	if got, _ := groupIDs("GET", "?match_reference=true"); len(got) != 1 || fmt.Sprint(got[0]) != fmt.Sprint([]int{first, third}) {
+		t.Errorf("match_reference: expected one group [%d %d], got %v", first, third, got)
+	}
+	if status, _ := apiRequest(t, r, "GET", "/api/v1/transactions/duplicates?auto_resolve=keep_oldest", nil); status != http.StatusMethodNotAllowed {
+		t.Errorf("auto_resolve over GET: expected 405, got %d", status)
+	}
+	if status, _ := apiRequest(t, r, "POST", "/api/v1/transactions/duplicates?auto_resolve=newest", nil); status != http.StatusBadRequest {
+		t.Errorf("unknown auto_resolve: expected 400, got %d", status)
+	}
+
+	_, data := groupIDs("POST", "?auto_resolve=keep_oldest")
+	removed := data["removed"].([]interface{})
+	if len(removed) != 2 {
+		t.Fatalf("expected 2 removed transactions, got %d", len(removed))
+	}
+	for _, id := range []int{second, third} {
+		if status, _ := apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/transactions/%d", id), nil); status != http.StatusNotFound {
+			t.Errorf("transaction %d: expected 404 after resolving, got %d", id, status)
+		}
+	}
+	if status, _ := apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/transactions/%d", first), nil); status != http.StatusOK {
+		t.Errorf("oldest transaction %d: expected it to be kept, got %d", first, status)
+	}
+	if got, _ := groupIDs("GET", ""); len(got) != 0 {
+		t.Errorf("expected no duplicates after resolving, got %v", got)
+	}
+}
+
+// TestCreateTransactionLinkAllocationTolerance verifies that a link may exceed
+// the document's unallocated amount by the configured tolerance, and that the
+// document is then treated as paid.
+func TestCreateTransactionLinkAllocationTolerance(t *testing.T) {
+	r, cleanup := setupTestRouter(t)
+	defer cleanup()
+	r.Post("/api/v1/bills", CreateBill)
+	r.Get("/api/v1/bills/{id}", GetBill)
+
+	_, resp := apiRequest(t, r, "POST", "/api/v1/accounts", map[string]interface{}{
+		"name": "Bank Account", "type": "bank", "opening_balance": 0,
+	})
+	accID := int(resp["data"].(map[string]interface{})["id"].(float64))
+
+	newBill := func(number string) int {
+		_, resp := apiRequest(t, r, "POST", "/api/v1/bills", map[string]interface{}{
+			"bill_number": number, "amount": 100.0, "status": "draft",
+		})
+		return int(resp["data"].(map[string]interface{})["id"].(float64))
+	}
+	newTxn := func(amount float64) int {
+		_, resp := apiRequest(t, r, "POST", "/api/v1/transactions", map[string]interface{}{
+			"account_id": accID, "type": "expense", "amount": amount,
+		})
+		return int(resp["data"].(map[string]interface{})["id"].(float64))
+	}
+	link := func(txnID, billID int, amount float64) int {
+		status, _ := apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/transactions/%d/links", txnID), map[string]interface{}{
+			"document_type": "bill", "document_id": billID, "amount": amount,
+		})
+		return status
+	}
+
+	// Strict by default: one paisa over is rejected.
+	billID := newBill("BILL-STRICT")
+	if status := link(newTxn(100.01), billID, 100.01); status != http.StatusBadRequest {
+		t.Fatalf("expected 400 without tolerance, got %d", status)
+	}
+
+	withTestConfig(t, Config{AllocationTolerance: 2})
+
+	if status := link(newTxn(100.03), billID, 100.03); status != http.StatusBadRequest {
+		t.Fatalf("expected 400 beyond tolerance, got %d", status)
+	}
+	if status := link(newTxn(100.02), billID, 100.02); status != http.StatusCreated {
+		t.Fatalf("expected link within tolerance to succeed, got %d", status)
+	}
+
+	// Under-allocating by less than the tolerance also rounds to paid.
+	shortID := newBill("BILL-SHORT")
+	if status := link(newTxn(99.99), shortID, 99.99); status != http.StatusCreated {
+		t.Fatalf("expected short link to succeed, got %d", status)
+	}
+	for _, id := range []int{billID, shortID} {
+		_, resp = apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/bills/%d", id), nil)
+		if got := resp["data"].(map[string]interface{})["status"]; got != "paid" {
+			t.Errorf("bill %d: expected status paid, got %v", id, got)
+		}
+	}
+}
+
+// TestTransactionLinkNote verifies that a link's note is stored, trimmed, and
+// returned by the link listings, and that an overlong note is rejected.
+func TestTransactionLinkNote(t *testing.T) {
+	r, cleanup := setupTestRouter(t)
+	defer cleanup()
+	r.Post("/api/v1/bills", CreateBill)
+	r.Get("/api/v1/bills/{id}/links", GetBillLinks)
+
+	_, resp := apiRequest(t, r, "POST", "/api/v1/accounts", map[string]interface{}{
+		"name": "Bank Account", "type": "bank", "opening_balance": 0,
+	})
+	accID := int(resp["data"].(map[string]interface{})["id"].(float64))
+	_, resp = apiRequest(t, r, "POST", "/api/v1/bills", map[string]interface{}{
+		"bill_number": "BILL-RET", "amount": 100.0, "status": "draft",
+	})
+	billID := int(resp["data"].(map[string]interface{})["id"].(float64))
+	_, resp = apiRequest(t, r, "POST", "/api/v1/transactions", map[string]interface{}{
+		"account_id": accID, "type": "expense", "amount": 90.0, "transaction_date": "2024-03-01",
+	})
+	txnID := int(resp["data"].(map[string]interface{})["id"].(float64))
+	linksPath := fmt.Sprintf("/api/v1/transactions/%d/links", txnID)
+
+	status, resp := apiRequest(t, r, "POST", linksPath, map[string]interface{}{
+		"document_type": "bill", "document_id": billID, "amount": 10.0, "note": strings.Repeat("x", 501),
+	})
+	if status != http.StatusBadRequest {
+		t.Fatalf("overlong note: expected 400, got %d", status)
+	}
+
+	status, resp = apiRequest(t, r, "POST", linksPath, map[string]interface{}{
+		"document_type": "bill", "document_id": billID, "amount": 90.0, "note": "  holding back 10% retention ",
+	})
+	if status != http.StatusCreated {
+		t.Fatalf("create link: status %d, error %v", status, resp["error"])
+	}
+	if note := resp["data"].(map[string]interface{})["note"]; note != "holding back 10% retention" {
+		t.Errorf("created link note = %v", note)
+	}
+
+	for _, path := range []string{linksPath, fmt.Sprintf("/api/v1/bills/%d/links", billID)} {
+		status, resp = apiRequest(t, r, "GET", path, nil)
+		if status != http.StatusOK {
+			t.Fatalf("%s: status %d, error %v", path, status, resp["error"])
+		}
+		links := resp["data"].([]interface{})
+		if len(links) != 1 || links[0].(map[string]interface{})["note"] != "holding back 10% retention" {
+			t.Errorf("%s: unexpected links %v", path, links)
+		}
+	}
+}
+
+// TestGetAllocationGraph verifies that the graph lists each document linked
+// to the transaction with its allocation status and the other transactions
+// that paid it.
+func TestGetAllocationGraph(t *testing.T) {
+	r, cleanup := setupItemsTestRouter(t)
+	defer cleanup()
+	r.Get("/api/v1/transactions/{id}/allocation-graph", GetAllocationGraph)
+
+	_, resp := apiRequest(t, r, "POST", "/api/v1/accounts", map[string]interface{}{"name": "Current", "type": "bank"})
+	accID := int(resp["data"].(map[string]interface{})["id"].(float64))
+	payment := func(amount float64) int {
+		_, resp := apiRequest(t, r, "POST", "/api/v1/transactions", map[string]interface{}{
+			"account_id": accID, "type": "expense", "amount": amount, "transaction_date": "2024-02-01",
+		})
+		return int(resp["data"].(map[string]interface{})["id"].(float64))
+	}
+	link := func(txnID, billID int, amount float64) {
+		status, resp := apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/transactions/%d/links", txnID), map[string]interface{}{
+			"document_type": "bill", "document_id": billID, "amount": amount,
+		})
+		if status != http.StatusCreated {
+			t.Fatalf("create link: status %d, error %v", status, resp["error"])
+		}
+	}
+
+	billID := createTestBill(t, r)
+	first, second := payment(30.0), payment(40.0)
+	link(first, billID, 30.0)
+	link(second, billID, 40.0)
+
+	status, resp := apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/transactions/%d/allocation-graph", first), nil)
+	if status != http.StatusOK {
+		t.Fatalf("allocation graph: status %d, error %v", status, resp["error"])
+	}
+	data := resp["data"].(map[string]interface{})
+	if id := data["transaction"].(map[string]interface{})["id"].(float64); int(id) != first {
+		t.Errorf("expected transaction %d, got %v", first, id)
+	}
+	links := data["links"].([]interface{})
+	if len(links) != 1 {
+		t.Fatalf("expected 1 link, got %v", links)
+	}
+	l := links[0].(map[string]interface{})
+	doc := l["document"].(map[string]interface{})
+	if doc["amount"].(float64) != 10000 || doc["allocated"].(float64) != 7000 || doc["status"] != "partial" {
+		t.Errorf("unexpected document summary: %v", doc)
+	}
+	others := l["other_links"].([]interface{})
+	if len(others) != 1 {
+		t.Fatalf("expected 1 other link, got %v", others)
+	}
+	if other := others[0].(map[string]interface{}); int(other["transaction_id"].(float64)) != second || other["amount"].(float64) != 4000 || other["account_name"] != "Current" {
+		t.Errorf("unexpected other link: %v", other)
+	}
+
+	if status, _ := apiRequest(t, r, "GET", "/api/v1/transactions/9999/allocation-graph", nil); status != http.StatusNotFound {
+		t.Errorf("missing transaction: expected 404, got %d", status)
+	}
+}
+
+// TestGetTransactionDistinguishesNotFound verifies that a missing record is a
+// 404 with the NOT_FOUND payload while other query failures surface as a 500
+// with the real error.
+func TestGetTransactionDistinguishesNotFound(t *testing.T) {
+	r, cleanup := setupTestRouter(t)
+	defer cleanup()
+	r.Get("/api/v1/transactions/{id}", GetTransaction)
+	r.Get("/api/v1/contacts/{id}", GetContact)
+	r.Get("/api/v1/accounts/{id}", GetAccount)
+	r.Get("/api/v1/bills/{id}", GetBill)
+	r.Get("/api/v1/invoices/{id}", GetInvoice)
+
+	for _, resource := range []string{"transaction", "contact", "account", "bill", "invoice", "payout"} {
+		path := "/api/v1/" + resource + "s/999"
+		status, resp := apiRequest(t, r, "GET", path, nil)
+		if status != http.StatusNotFound {
+			t.Errorf("GET %s: expected 404, got %d (%v)", path, status, resp["error"])
+		}
+		if resp["error"] != resource+" not found" || resp["code"] != "NOT_FOUND" || resp["resource"] != resource {
+			t.Errorf("GET %s: unexpected not-found payload %v", path, resp)
+		}
+	}
+
+	DB.Close()
+	for _, path := range []string{"/api/v1/transactions/1", "/api/v1/contacts/1"} {
+		status, resp := apiRequest(t, r, "GET", path, nil)
+		if status != http.StatusInternalServerError {
+			t.Errorf("GET %s on closed DB: expected 500, got %d", path, status)
+		}
+		if msg, _ := resp["error"].(string); msg == "" || msg == "transaction not found" || msg == "contact not found" {
+			t.Errorf("GET %s on closed DB: expected underlying error, got %q", path, msg)
+		}
+	}
+}
+
+// TestCreateTransactionExternalIDUpserts verifies that creating a transaction
+// with a known (account, source, external_id) updates it, that it can be
+// looked up by external_id, that another transaction cannot take the same id,
+// and that the same id on another account creates a new transaction.
+func TestCreateTransactionExternalIDUpserts(t *testing.T) {
+	r, cleanup := setupTestRouter(t)
+	defer cleanup()
+	r.Get("/api/v1/transactions", ListTransactions)
+	r.Put("/api/v1/transactions/{id}", UpdateTransaction)
+
+	_, resp := apiRequest(t, r, "POST", "/api/v1/accounts", map[string]interface{}{
+		"name": "Bank Account", "type": "bank", "opening_balance": 0,
+	})
+	accID := int(resp["data"].(map[string]interface{})["id"].(float64))
+	txn := func(amount float64, externalID string) map[string]interface{} {
+		return map[string]interface{}{
+			"account_id": accID, "type": "income", "amount": amount, "transaction_date": "2024-01-15",
+			"external_id": externalID, "source": "pos",
+		}
+	}
+
+	status, resp := apiRequest(t, r, "POST", "/api/v1/transactions", txn(100.0, "E1"))
+	if status != http.StatusCreated {
+		t.Fatalf("create: status %d, error %v", status, resp["error"])
+	}
+	id := int(resp["data"].(map[string]interface{})["id"].(float64))
+
+	status, resp = apiRequest(t, r, "POST", "/api/v1/transactions", txn(120.0, "E1"))
+	if status != http.StatusOK {
+		t.Fatalf("re-sync: expected 200, got %d (%v)", status, resp["error"])
+	}
+	data := resp["data"].(map[string]interface{})
+	if int(data["id"].(float64)) != id || data["amount"].(float64) != 12000 {
+		t.Errorf("expected transaction %d updated to 12000, got %v", id, data)
+	}
+
+	_, resp = apiRequest(t, r, "GET", "/api/v1/transactions?external_id=E1", nil)
+	if list := resp["data"].([]interface{}); len(list) != 1 || list[0].(map[string]interface{})["source"] != "pos" {
+		t.Errorf("expected one pos transaction for E1, got %v", list)
+	}
+
+	_, resp = apiRequest(t, r, "POST", "/api/v1/transactions", txn(50.0, "E2"))
+	otherID := int(resp["data"].(map[string]interface{})["id"].(float64))
+	if status, _ := apiRequest(t, r, "PUT", fmt.Sprintf("/api/v1/transactions/%d", otherID), txn(50.0, "E1")); status != http.StatusConflict {
+		t.Errorf("update to a used external_id: expected 409, got %d", status)
+	}
+
+	// Omitting external_id on update keeps the stored one.
+	status, resp = apiRequest(t, r, "PUT", fmt.Sprintf("/api/v1/transactions/%d", otherID), map[string]interface{}{
+		"account_id": accID, "type": "income", "amount": 55.0, "transaction_date": "2024-01-15",
+	})
+	if status != http.StatusOK || resp["data"].(map[string]interface{})["external_id"] != "E2" {
+		t.Errorf("expected external_id E2 to be kept, got status %d, %v", status, resp["data"])
+	}
+
+	// The same external_id on another account is a different record.
+	_, resp = apiRequest(t, r, "POST", "/api/v1/accounts", map[string]interface{}{
+		"name": "Cash", "type": "cash", "opening_balance": 0,
+	})
+	cashID := int(resp["data"].(map[string]interface{})["id"].(float64))
+	other := txn(30.0, "E1")
+	other["account_id"] = cashID
+	status, resp = apiRequest(t, r, "POST", "/api/v1/transactions", other)
+	if status != http.StatusCreated || int(resp["data"].(map[string]interface{})["id"].(float64)) == id {
+		t.Errorf("E1 on another account: expected a new transaction, got status %d, %v", status, resp["data"])
+	}
+}
+
+// TestLargeTransactionRequiresConfirmation verifies that amounts above
+// LARGE_TXN_THRESHOLD are rejected unless confirmed_large is set, and that
+// updates leaving a confirmed amount unchanged need no confirmation.
+func TestLargeTransactionRequiresConfirmation(t *testing.T) {
+	r, cleanup := setupTestRouter(t)
+	defer cleanup()
+	r.Put("/api/v1/transactions/{id}", UpdateTransaction)
+	withTestConfig(t, Config{LargeTxnThreshold: 100000}) // ₹1,000
+
+	_, resp := apiRequest(t, r, "POST", "/api/v1/accounts", map[string]interface{}{
+		"name": "Bank Account", "type": "bank", "opening_balance": 0,
+	})
+	accID := int(resp["data"].(map[string]interface{})["id"].(float64))
+	txn := func(amount float64, confirmed bool) map[string]interface{} {
+		return map[string]interface{}{
+			"account_id": accID, "type": "expense", "amount": amount, "transaction_date": "2024-01-15",
+			"description": "Rent", "confirmed_large": confirmed,
+		}
+	}
+
+	if status, _ := apiRequest(t, r, "POST", "/api/v1/transactions", txn(1000.0, false)); status != http.StatusCreated {
+		t.Errorf("amount at threshold: expected 201, got %d", status)
+	}
+	status, resp := apiRequest(t, r, "POST", "/api/v1/transactions", txn(50000.0, false))
+	if status != http.StatusBadRequest {
+		t.Fatalf("unconfirmed large amount: expected 400, got %d", status)
+	}
+	if msg, _ := resp["error"].(string); !strings.Contains(msg, "confirmed_large") {
+		t.Errorf("expected error to mention confirmed_large, got %q", msg)
+	}
+
+	status, resp = apiRequest(t, r, "POST", "/api/v1/transactions", txn(50000.0, true))
+	if status != http.StatusCreated {
+		t.Fatalf("confirmed large amount: status %d, error %v", status, resp["error"])
+	}
+	id := int(resp["data"].(map[string]interface{})["id"].(float64))
+
+	if status, _ := apiRequest(t, r, "PUT", fmt.Sprintf("/api/v1/transactions/%d", id), txn(50000.0, false)); status != http.StatusOK {
+		t.Errorf("update with unchanged amount: expected 200, got %d", status)
+	}
+	if status, _ := apiRequest(t, r, "PUT", fmt.Sprintf("/api/v1/transactions/%d", id), txn(60000.0, false)); status != http.StatusBadRequest {
+		t.Errorf("update to another large amount: expected 400, got %d", status)
+	}
+}
+
+// TestCreateTransactionLinkOnSettledDocumentWarns verifies that linking to an
+// invoice already marked received, whose amount was later raised, succeeds
+// with a warning that its status was recomputed.
+func TestCreateTransactionLinkOnSettledDocumentWarns(t *testing.T) {
+	r, cleanup := setupTestRouter(t)
+	defer cleanup()
+	r.Post("/api/v1/invoices", CreateInvoice)
+	r.Put("/api/v1/invoices/{id}", UpdateInvoice)
+	r.Get("/api/v1/invoices/{id}", GetInvoice)
+
+	_, resp := apiRequest(t, r, "POST", "/api/v1/accounts", map[string]interface{}{
+		"name": "Bank Account", "type": "bank", "opening_balance": 0,
+	})
+	accID := int(resp["data"].(map[string]interface{})["id"].(float64))
+	_, resp = apiRequest(t, r, "POST", "/api/v1/invoices", map[string]interface{}{
+		"invoice_number": "INV-001", "amount": 100.0, "status": "received",
+	})
+	invID := int(resp["data"].(map[string]interface{})["id"].(float64))
+
+	// Late adjustment: the invoice grows but keeps its received status.
+	if status, resp := apiRequest(t, r, "PUT", fmt.Sprintf("/api/v1/invoices/%d", invID), map[string]interface{}{
+		"invoice_number": "INV-001", "amount": 150.0, "status": "received",
+	}); status != http.StatusOK {
+		t.Fatalf("update invoice: status %d, error %v", status, resp["error"])
+	}
+
+	_, resp = apiRequest(t, r, "POST", "/api/v1/transactions", map[string]interface{}{
+		"account_id": accID, "type": "income", "amount": 50.0, "transaction_date": "2024-01-15",
+	})
+	txnID := int(resp["data"].(map[string]interface{})["id"].(float64))
+
+	status, resp := apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/transactions/%d/links", txnID), map[string]interface{}{
+		"document_type": "invoice", "document_id": invID, "amount": 50.0,
+	})
+	if status != http.StatusCreated {
+		t.Fatalf("create link: status %d, error %v", status, resp["error"])
+	}
+	data := resp["data"].(map[string]interface{})
+	if data["amount"].(float64) != 5000 {
+		t.Errorf("expected link amount 5000, got %v", data["amount"])
+	}
+	warnings, _ := data["warnings"].([]interface{})
+	if len(warnings) != 1 || !strings.Contains(warnings[0].(string), "status recomputed to partial") {
+		t.Errorf("expected a status-recomputed warning, got %v", data["warnings"])
+	}
+	_, resp = apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/invoices/%d", invID), nil)
+	if got := resp["data"].(map[string]interface{})["status"]; got != "partial" {
+		t.Errorf("expected invoice status partial, got %v", got)
+	}
+
+	// An ordinary link carries no warnings.
+	_, resp = apiRequest(t, r, "POST", "/api/v1/transactions", map[string]interface{}{
+		"account_id": accID, "type": "income", "amount": 10.0, "transaction_date": "2024-01-15",
+	})
+	txnID = int(resp["data"].(map[string]interface{})["id"].(float64))
+	_, resp = apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/transactions/%d/links", txnID), map[string]interface{}{
+		"document_type": "invoice", "document_id": invID, "amount": 10.0,
+	})
+	if w, ok := resp["data"].(map[string]interface{})["warnings"]; ok {
+		t.Errorf("expected no warnings, got %v", w)
+	}
+}
+
+// TestListTransactionsByContactAndReference verifies the contact_id and
+// exact-match reference filters.
+func TestListTransactionsByContactAndReference(t *testing.T) {
+	r, cleanup := setupContactsTestRouter(t)
+	defer cleanup()
+	r.Get("/api/v1/transactions", ListTransactions)
+
+	_, resp := apiRequest(t, r, "POST", "/api/v1/contacts", map[string]interface{}{
+		"name": "Acme Supplies", "type": "vendor",
+	})
+	contactID := int(resp["data"].(map[string]interface{})["id"].(float64))
+	_, resp = apiRequest(t, r, "POST", "/api/v1/accounts", map[string]interface{}{
+		"name": "Bank Account", "type": "bank", "opening_balance": 0,
+	})
+	accID := int(resp["data"].(map[string]interface{})["id"].(float64))
+
+	for _, txn := range []map[string]interface{}{
+		{"contact_id": contactID, "reference": "UTR123"},
+		{"contact_id": contactID, "reference": "UTR1234"},
+		{"reference": "UTR123"},
+	} {
+		txn["account_id"], txn["type"], txn["amount"], txn["transaction_date"] = accID, "expense", 10.0, "2024-01-15"
+		if status, resp := apiRequest(t, r, "POST", "/api/v1/transactions", txn); status != http.StatusCreated {
+			t.Fatalf("create transaction: status %d, error %v", status, resp["error"])
+		}
+	}
+
+	for query, want := range map[string]int{
+		fmt.Sprintf("contact_id=%d", contactID):                  2,
+		"reference=UTR123":                                       2,
+		fmt.Sprintf("contact_id=%d&reference=UTR123", contactID): 1,
+	} {
+		_, resp := apiRequest(t, r, "GET", "/api/v1/transactions?"+query, nil)
+		if got := len(resp["data"].([]interface{})); got != want {
+			t.Errorf("%s: expected %d transactions, got %d", query, want, got)
+		}
+	}
+}
+
+// TestGetTransactionCalendar verifies that every day of the month is
+// returned, with income, expense, and net per day and transfers kept apart.
+func TestGetTransactionCalendar(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+
+	current := createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": "Current", "type": "bank"})
+	savings := createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": "Savings", "type": "bank"})
+	for _, txn := range []map[string]interface{}{
+		{"account_id": current, "type": "income", "amount": 100.0, "transaction_date": "2024-02-03"},
+		{"account_id": current, "type": "expense", "amount": 30.0, "transaction_date": "2024-02-03"},
+		{"account_id": savings, "type": "expense", "amount": 5.0, "transaction_date": "2024-02-03"},
+		{"account_id": current, "type": "transfer", "amount": 20.0, "transaction_date": "2024-02-29", "transfer_account_id": savings},
+		{"account_id": current, "type": "income", "amount": 50.0, "transaction_date": "2024-03-01"},
+	} {
+		createResource(t, r, "/api/v1/transactions", txn)
+	}
+
+	status, resp := apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/transactions/calendar?month=2024-02&account_id=%d", current), nil)
+	if status != http.StatusOK {
+		t.Fatalf("calendar: status %d, error %v", status, resp["error"])
+	}
+	days := resp["data"].([]interface{})
+	if len(days) != 29 {
+		t.Fatalf("expected 29 days in February 2024, got %d", len(days))
+	}
+	first, third, last := days[0].(map[string]interface{}), days[2].(map[string]interface{}), days[28].(map[string]interface{})
+	if first["date"] != "2024-02-01" || first["count"] != 0.0 || first["net"] != 0.0 {
+		t.Errorf("unexpected empty day: %v", first)
+	}
+	if third["date"] != "2024-02-03" || third["count"] != 2.0 || third["income"] != 10000.0 || third["expense"] != 3000.0 || third["net"] != 7000.0 {
+		t.Errorf("unexpected 3 February: %v", third)
+	}
+	if last["date"] != "2024-02-29" || last["count"] != 0.0 || last["transfers"] != 1.0 || last["transfers_out"] != 2000.0 || last["net"] != 0.0 {
+		t.Errorf("unexpected 29 February: %v", last)
+	}
+
+	_, resp = apiRequest(t, r, "GET", "/api/v1/transactions/calendar?month=2024-02", nil)
+	if third := resp["data"].([]interface{})[2].(map[string]interface{}); third["count"] != 3.0 || third["net"] != 6500.0 {
+		t.Errorf("all accounts, 3 February: %v", third)
+	}
+	if status, _ := apiRequest(t, r, "GET", "/api/v1/transactions/calendar?month=2024-2", nil); status != http.StatusBadRequest {
+		t.Errorf("bad month: expected 400, got %d", status)
+	}
+}
+
+// TestLockTransaction verifies that a locked transaction cannot be updated or
+// deleted until it is unlocked, that locking and unlocking need the admin key
+// when one is set, and that LOCK_RECONCILED locks transactions as they are
+// reconciled.
+func TestLockTransaction(t *testing.T) {
+	r, cleanup := setupAPI(t)
+	defer cleanup()
+	withTestConfig(t, Config{LockReconciled: true})
+
+	account := createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": "Current", "type": "bank"})
+	body := map[string]interface{}{"account_id": account, "type": "expense", "amount": 100.0, "transaction_date": "2024-03-01", "reference": "UTR-1"}
+	id := createResource(t, r, "/api/v1/transactions", body)
+	path := fmt.Sprintf("/api/v1/transactions/%d", id)
+
+	// With ADMIN_API_KEY set, locking and unlocking need the key.
+	withTestConfig(t, Config{LockReconciled: true, AdminAPIKey: "secret"})
+	for _, action := range []string{"/lock", "/unlock"} {
+		if status, _ := apiRequest(t, r, "POST", path+action, nil); status != http.StatusForbidden {
+			t.Errorf("%s without admin key: expected 403, got %d", action, status)
+		}
+	}
+	req := httptest.NewRequest("POST", path+"/lock", nil)
+	req.Header.Set("X-Admin-Key", "secret")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("lock with admin key: expected 200, got %d", rec.Code)
+	}
+	withTestConfig(t, Config{LockReconciled: true})
+
+	status, resp := apiRequest(t, r, "POST", path+"/lock", nil)
+	if status != http.StatusOK || resp["data"].(map[string]interface{})["locked"] != true {
+		t.Fatalf("lock: status %d, response %v", status, resp)
+	}
+	body["amount"] = 150.0
+	if status, _ := apiRequest(t, r, "PUT", path, body); status != http.StatusConflict {
+		t.Errorf("update locked: expected 409, got %d", status)
+	}
+	if status, _ := apiRequest(t, r, "DELETE", path, nil); status != http.StatusConflict {
+		t.Errorf("delete locked: expected 409, got %d", status)
+	}
+
+	status, resp = apiRequest(t, r, "POST", path+"/unlock", nil)
+	if status != http.StatusOK || resp["data"].(map[string]interface{})["locked"] != false {
+		t.Fatalf("unlock: status %d, response %v", status, resp)
+	}
+	if status, resp := apiRequest(t, r, "PUT", path, body); status != http.StatusOK {
+		t.Errorf("update unlocked: status %d, error %v", status, resp["error"])
+	}
+	if status, _ := apiRequest(t, r, "POST", "/api/v1/transactions/999999/lock", nil); status != http.StatusNotFound {
+		t.Errorf("lock missing: expected 404, got %d", status)
+	}
+
+	status, resp = apiRequest(t, r, "POST", "/api/v1/transactions/reconcile-by-reference", map[string]interface{}{"references": []string{"UTR-1"}})
+	if status != http.StatusOK {
+		t.Fatalf("reconcile: status %d, error %v", status, resp["error"])
+	}
+	_, resp = apiRequest(t, r, "GET", path, nil)
+	if txn := resp["data"].(map[string]interface{}); txn["reconciled"] != true || txn["locked"] != true {
+		t.Errorf("expected the reconciled transaction to be locked, got %v", txn)
+	}
+	if status, _ := apiRequest(t, r, "DELETE", path, nil); status != http.StatusConflict {
+		t.Errorf("delete reconciled: expected 409, got %d", status)
+	}
+}
+
+// TestResolveDuplicatesKeepsLinked verifies that auto_resolve keeps the
+// duplicate that pays a document rather than the earliest created, and that a
+// group with two linked or locked transactions is refused.
+func TestResolveDuplicatesKeepsLinked(t *testing.T) {
+	r, cleanup := setupTestRouter(t)
+	defer cleanup()
+	r.Post("/api/v1/transactions/duplicates", ListDuplicateTransactions)
+	r.Get("/api/v1/transactions/{id}", GetTransaction)
+	r.Post("/api/v1/transactions/{id}/lock", LockTransaction)
+	r.Post("/api/v1/bills", CreateBill)
+	r.Get("/api/v1/bills/{id}", GetBill)
+
+	accID := createResource(t, r, "/api/v1/accounts", map[string]interface{}{"name": "Current", "type": "bank"})
+	txn := func(date string) int {
+		return createResource(t, r, "/api/v1/transactions", map[string]interface{}{
+			"account_id": accID, "type": "expense", "amount": 100.0, "transaction_date": date,
+		})
+	}
+	billID := createResource(t, r, "/api/v1/bills", map[string]interface{}{"bill_number": "B-1", "amount": 100.0, "status": "received"})
+
+	oldest, linked := txn("2024-03-01"), txn("2024-03-01")
+	createResource(t, r, fmt.Sprintf("/api/v1/transactions/%d/links", linked), map[string]interface{}{
+		"document_type": "bill", "document_id": billID, "amount": 100.0,
+	})
+	status, resp := apiRequest(t, r, "POST", "/api/v1/transactions/duplicates?auto_resolve=keep_oldest", nil)
+	if status != http.StatusOK {
+		t.Fatalf("resolve: status %d, error %v", status, resp["error"])
+	}
+	if status, _ := apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/transactions/%d", oldest), nil); status != http.StatusNotFound {
+		t.Errorf("unlinked oldest transaction: expected 404 after resolving, got %d", status)
+	}
+	if status, _ := apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/transactions/%d", linked), nil); status != http.StatusOK {
+		t.Errorf("linked transaction: expected it to be kept, got %d", status)
+	}
+	_, resp = apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/bills/%d", billID), nil)
+	if st := resp["data"].(map[string]interface{})["status"]; st != "paid" {
+		t.Errorf("bill status = %v, want paid", st)
+	}
+
+	a, b := txn("2024-03-05"), txn("2024-03-05")
+	createResource(t, r, fmt.Sprintf("/api/v1/transactions/%d/links", a), map[string]interface{}{
+		"document_type": "bill", "document_id": createResource(t, r, "/api/v1/bills", map[string]interface{}{"bill_number": "B-2", "amount": 100.0}),
+		"amount": 100.0,
+	})
+	if status, resp := apiRequest(t, r, "POST", fmt.Sprintf("/api/v1/transactions/%d/lock", b), nil); status != http.StatusOK {
+		t.Fatalf("lock: status %d, error %v", status, resp["error"])
+	}
+	status, resp = apiRequest(t, r, "POST", "/api/v1/transactions/duplicates?auto_resolve=keep_oldest", nil)
+	if status != http.StatusConflict {
+		t.Fatalf("resolve linked and locked pair: expected 409, got %d", status)
+	}
+	if groups := resp["data"].(map[string]interface{})["groups"].([]interface{}); len(groups) != 1 {
+		t.Errorf("expected the refused group in data, got %v", groups)
+	}
+	for _, id := range []int{a, b} {
+		if status, _ := apiRequest(t, r, "GET", fmt.Sprintf("/api/v1/transactions/%d", id), nil); status != http.StatusOK {
+			t.Errorf("transaction %d: expected it to be kept after a refused run, got %d", id, status)
+		}
+	}
+}
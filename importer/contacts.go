@@ -0,0 +1,93 @@
+package importer
+
+import (
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/satheeshds/portal/models"
+)
+
+// ErrNoContactHeader is returned when the first row of a contact file has no
+// name column.
+var ErrNoContactHeader = errors.New("no CSV header with a name column found")
+
+// contactHeaderVariants lists the header names other tools export for each
+// contact field, normalized by normalizeHeader.
+var contactHeaderVariants = map[string][]string{
+	"name":  {"name", "contactname", "displayname", "companyname", "vendorname", "customername", "businessname"},
+	"type":  {"type", "contacttype", "category"},
+	"email": {"email", "emailaddress", "emailid", "mail"},
+	"phone": {"phone", "phonenumber", "mobile", "mobilenumber", "mobileno", "contactnumber", "telephone"},
+	"gstin": {"gstin", "gstinuin", "gstno", "gstnumber"},
+	"pan":   {"pan", "panno", "pannumber"},
+}
+
+// ParseContactsCSV reads contacts from a CSV file whose first row is a header
+// naming at least a name column; type, email, phone, gstin, and pan columns
+// are optional and matched by common name variants. Rows are numbered by
+// their line in the file and blank rows are skipped. Values are passed on as
+// written for ContactInput.Validate to check, except that type is lowercased
+// and defaultType fills an empty one.
+func ParseContactsCSV(r io.Reader, defaultType string) ([]models.ContactLine, error) {
+	records, lineNumbers, err := readCSV(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, ErrNoContactHeader
+	}
+
+	byName := map[string]int{}
+	for col, name := range records[0] {
+		if n := normalizeHeader(name); n != "" {
+			if _, dup := byName[n]; !dup {
+				byName[n] = col
+			}
+		}
+	}
+	columns := map[string]int{}
+	for field, variants := range contactHeaderVariants {
+		for _, v := range variants {
+			if col, ok := byName[v]; ok {
+				columns[field] = col
+				break
+			}
+		}
+	}
+	if _, ok := columns["name"]; !ok {
+		return nil, ErrNoContactHeader
+	}
+
+	var lines []models.ContactLine
+	for i := 1; i < len(records); i++ {
+		rec := records[i]
+		if blankRecord(rec) {
+			continue
+		}
+		cell := func(field string) string {
+			col, ok := columns[field]
+			if !ok || col >= len(rec) {
+				return ""
+			}
+			return strings.TrimSpace(rec[col])
+		}
+		optional := func(field string) *string {
+			v := cell(field)
+			return &v
+		}
+		typ := strings.ToLower(cell("type"))
+		if typ == "" {
+			typ = defaultType
+		}
+		lines = append(lines, models.ContactLine{Row: lineNumbers[i], Input: models.ContactInput{
+			Name:  cell("name"),
+			Type:  typ,
+			Email: optional("email"),
+			Phone: optional("phone"),
+			GSTIN: optional("gstin"),
+			PAN:   optional("pan"),
+		}})
+	}
+	return lines, nil
+}
@@ -0,0 +1,315 @@
+package importer
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/satheeshds/portal/models"
+)
+
+// ErrNoCSVHeader is returned when no row of the file can be read as a header
+// with at least a date column and an amount column.
+var ErrNoCSVHeader = errors.New("no CSV header with a date and an amount column found; set a column mapping")
+
+// maxCSVPreamble is how many rows before the header are skipped, since many
+// banks print account details above the table.
+const maxCSVPreamble = 20
+
+// CSVFields are the canonical statement fields a CSV column can map to. A
+// statement has either debit and/or credit columns or one signed amount column.
+var CSVFields = []string{"date", "debit", "credit", "amount", "description", "reference"}
+
+// csvHeaderVariants lists the header names banks use for each field, most
+// preferred first, normalized by normalizeHeader.
+var csvHeaderVariants = map[string][]string{
+	"date": {"date", "transactiondate", "txndate", "trandate", "postingdate", "posteddate", "bookingdate",
+		"valuedate", "valuedt", "txndt"},
+	"debit": {"debit", "debitamount", "debitamt", "withdrawal", "withdrawals", "withdrawalamt", "withdrawalamount",
+		"dr", "paidout", "moneyout"},
+	"credit": {"credit", "creditamount", "creditamt", "deposit", "deposits", "depositamt", "depositamount",
+		"cr", "paidin", "moneyin"},
+	"amount": {"amount", "transactionamount", "txnamount", "amt"},
+	"description": {"description", "narration", "particulars", "transactiondetails", "details", "remarks",
+		"memo", "payee"},
+	"reference": {"reference", "referenceno", "referencenumber", "refno", "chqrefno", "chequeno", "chqno",
+		"utr", "utrno", "transactionid"},
+}
+
+// csvDateLayouts are the dates accepted in a date column. Day-first layouts
+// come before month-first ones, as Indian banks write dates day first.
+var csvDateLayouts = []string{
+	"2006-01-02", "02/01/2006", "02-01-2006", "02.01.2006", "02/01/06", "02-01-06",
+	"02-Jan-2006", "02 Jan 2006", "02-Jan-06", "02 Jan 06", "2006/01/02",
+}
+
+// ParseCSV reads statement lines from a bank CSV export. The header row is
+// found among the first rows of the file and its columns are mapped to
+// CSVFields by common name variants (e.g. "Withdrawal Amt." is debit);
+// mapping overrides the detection with field → header name. It returns the
+// mapping used. Rows are numbered by their line in the file. Each line gets
+// an external_id derived from its contents, so re-importing the same rows is
+// skipped like an OFX FITID. Amounts are read in currency, the statement
+// account's.
+func ParseCSV(r io.Reader, mapping map[string]string, currency string) ([]models.StatementLine, []models.ImportError, map[string]string, error) {
+	for field := range mapping {
+		if _, ok := csvHeaderVariants[field]; !ok {
+			return nil, nil, nil, fmt.Errorf("unknown mapping field %q; must be one of %s", field, strings.Join(CSVFields, ", "))
+		}
+	}
+
+	records, lineNumbers, err := readCSV(r)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	header, columns, err := findCSVHeader(records, mapping)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	detected := make(map[string]string, len(columns))
+	for field, col := range columns {
+		detected[field] = strings.TrimSpace(records[header][col])
+	}
+
+	var lines []models.StatementLine
+	var errs []models.ImportError
+	seen := map[string]int{}
+	for i := header + 1; i < len(records); i++ {
+		rec := records[i]
+		if blankRecord(rec) {
+			continue
+		}
+		row := lineNumbers[i]
+		line, err := csvLine(row, rec, columns, currency)
+		if err != nil {
+			errs = append(errs, models.ImportError{Row: row, Error: err.Error()})
+			continue
+		}
+		// Identical rows in one file are genuine repeats, told apart by
+		// their position among themselves.
+		key := strings.Join([]string{line.Date, strconv.FormatInt(int64(line.Amount), 10), line.Description, line.Reference}, "\x1f")
+		seen[key]++
+		sum := sha256.Sum256([]byte(key + "\x1f" + strconv.Itoa(seen[key])))
+		line.ExternalID = "csv-" + hex.EncodeToString(sum[:8])
+		lines = append(lines, line)
+	}
+	return lines, errs, detected, nil
+}
+
+// readCSV reads every record of a CSV file, tolerating ragged rows and stray
+// quotes, and returns them with the file line each starts on, as blank lines
+// are skipped. A UTF-8 byte order mark is dropped from the first cell.
+func readCSV(r io.Reader) ([][]string, []int, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+	var records [][]string
+	var lineNumbers []int
+	for {
+		rec, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		records = append(records, rec)
+		lineNumbers = append(lineNumbers, line)
+	}
+	if len(records) > 0 && len(records[0]) > 0 {
+		records[0][0] = strings.TrimPrefix(records[0][0], "\ufeff")
+	}
+	return records, lineNumbers, nil
+}
+
+// findCSVHeader returns the index of the header row and the column of each
+// mapped field. The header is the first row in which the date and an amount
+// column resolve.
+func findCSVHeader(records [][]string, mapping map[string]string) (int, map[string]int, error) {
+	for i := 0; i < len(records) && i <= maxCSVPreamble; i++ {
+		columns := csvColumns(records[i], mapping)
+		_, hasDate := columns["date"]
+		_, hasDebit := columns["debit"]
+		_, hasCredit := columns["credit"]
+		_, hasAmount := columns["amount"]
+		if !hasDate || !(hasDebit || hasCredit || hasAmount) {
+			continue
+		}
+		missing := false
+		for field := range mapping {
+			if _, ok := columns[field]; !ok {
+				missing = true
+			}
+		}
+		if !missing {
+			return i, columns, nil
+		}
+	}
+	if len(mapping) > 0 {
+		fields := make([]string, 0, len(mapping))
+		for field, name := range mapping {
+			fields = append(fields, fmt.Sprintf("%s=%q", field, name))
+		}
+		sort.Strings(fields)
+		return 0, nil, fmt.Errorf("no CSV header contains the mapped columns %s together with a date and an amount column",
+			strings.Join(fields, ", "))
+	}
+	return 0, nil, ErrNoCSVHeader
+}
+
+// csvColumns maps fields to columns of a candidate header row: mapped fields
+// by their given header name, the others by the first matching variant.
+func csvColumns(row []string, mapping map[string]string) map[string]int {
+	byName := make(map[string]int, len(row))
+	for col, name := range row {
+		if n := normalizeHeader(name); n != "" {
+			if _, dup := byName[n]; !dup {
+				byName[n] = col
+			}
+		}
+	}
+	columns := map[string]int{}
+	used := map[int]bool{}
+	for _, field := range CSVFields {
+		name, ok := mapping[field]
+		if !ok {
+			continue
+		}
+		if col, ok := byName[normalizeHeader(name)]; ok {
+			columns[field] = col
+			used[col] = true
+		}
+	}
+	for _, field := range CSVFields {
+		if _, ok := mapping[field]; ok {
+			continue
+		}
+		for _, variant := range csvHeaderVariants[field] {
+			if col, ok := byName[variant]; ok && !used[col] {
+				columns[field] = col
+				used[col] = true
+				break
+			}
+		}
+	}
+	return columns
+}
+
+// normalizeHeader lowercases a header name and drops everything but letters
+// and digits, so "Withdrawal Amt." becomes "withdrawalamt".
+func normalizeHeader(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// blankRecord reports whether every cell of rec is empty.
+func blankRecord(rec []string) bool {
+	for _, v := range rec {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// csvLine converts one data row into a statement line in currency; credits
+// are positive and debits negative.
+func csvLine(row int, rec []string, columns map[string]int, currency string) (models.StatementLine, error) {
+	cell := func(field string) string {
+		col, ok := columns[field]
+		if !ok || col >= len(rec) {
+			return ""
+		}
+		return strings.TrimSpace(rec[col])
+	}
+
+	date, err := parseCSVDate(cell("date"))
+	if err != nil {
+		return models.StatementLine{}, fmt.Errorf("date: %w", err)
+	}
+	var amount models.Money
+	if _, ok := columns["amount"]; ok {
+		if amount, err = parseCSVAmount(cell("amount"), currency); err != nil {
+			return models.StatementLine{}, fmt.Errorf("amount: %w", err)
+		}
+	}
+	credit, err := parseCSVAmount(cell("credit"), currency)
+	if err != nil {
+		return models.StatementLine{}, fmt.Errorf("credit: %w", err)
+	}
+	debit, err := parseCSVAmount(cell("debit"), currency)
+	if err != nil {
+		return models.StatementLine{}, fmt.Errorf("debit: %w", err)
+	}
+	// Debit columns hold positive amounts; a signed one is taken as written.
+	if debit > 0 {
+		debit = -debit
+	}
+	amount += credit + debit
+	if amount == 0 {
+		return models.StatementLine{}, errors.New("amount is zero or missing")
+	}
+
+	return models.StatementLine{
+		Row:         row,
+		Date:        date,
+		Amount:      amount,
+		Description: cell("description"),
+		Reference:   cell("reference"),
+	}, nil
+}
+
+// parseCSVDate converts a date in one of csvDateLayouts to YYYY-MM-DD.
+func parseCSVDate(v string) (string, error) {
+	if v == "" {
+		return "", errors.New("missing date")
+	}
+	// Some exports append a time to the date.
+	if d, _, ok := strings.Cut(v, " "); ok && len(d) >= 8 && strings.ContainsAny(d, "-/.") {
+		v = d
+	}
+	for _, layout := range csvDateLayouts {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t.Format("2006-01-02"), nil
+		}
+	}
+	return "", fmt.Errorf("invalid date %q", v)
+}
+
+// parseCSVAmount converts an amount such as "1,23,456.78", "₹ 50", or "(12.00)"
+// to the smallest unit of currency, paise for INR; an empty currency keeps
+// AmountMinorUnits decimal places. An empty cell is zero.
+func parseCSVAmount(v, currency string) (models.Money, error) {
+	s := strings.NewReplacer(",", "", "₹", "", "INR", "", " ", "").Replace(v)
+	if s == "" || s == "-" {
+		return 0, nil
+	}
+	negative := false
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		negative, s = true, s[1:len(s)-1]
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", v)
+	}
+	if negative {
+		f = -f
+	}
+	return models.FromFloatIn(f, currency), nil
+}
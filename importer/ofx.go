@@ -0,0 +1,136 @@
+// Package importer parses bank statement files into statement lines that the
+// store can import as transactions.
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/satheeshds/portal/models"
+)
+
+// ErrNoOFXTransactions is returned when the input contains no STMTTRN records.
+var ErrNoOFXTransactions = errors.New("no OFX transactions found")
+
+// ParseOFX reads STMTTRN records from an OFX or QFX file. Both the SGML (OFX 1.x,
+// unclosed leaf tags) and XML (OFX 2.x) dialects are accepted. Records with a
+// missing or unreadable date or amount are returned as errors rather than lines.
+// Amounts are read in currency, the statement account's.
+func ParseOFX(r io.Reader, currency string) ([]models.StatementLine, []models.ImportError, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var records []map[string]string
+	var current map[string]string
+	for _, token := range strings.Split(string(data), "<")[1:] {
+		tag, value, ok := strings.Cut(token, ">")
+		if !ok {
+			continue
+		}
+		tag = strings.ToUpper(strings.TrimSpace(tag))
+		switch {
+		case tag == "STMTTRN":
+			if current != nil {
+				records = append(records, current)
+			}
+			current = map[string]string{}
+		case tag == "/STMTTRN":
+			if current != nil {
+				records = append(records, current)
+				current = nil
+			}
+		case current != nil && !strings.HasPrefix(tag, "/"):
+			if v := strings.TrimSpace(html.UnescapeString(value)); v != "" {
+				current[tag] = v
+			}
+		}
+	}
+	if current != nil {
+		records = append(records, current)
+	}
+	if len(records) == 0 {
+		return nil, nil, ErrNoOFXTransactions
+	}
+
+	var lines []models.StatementLine
+	var errs []models.ImportError
+	for i, rec := range records {
+		line, err := ofxLine(i+1, rec, currency)
+		if err != nil {
+			errs = append(errs, models.ImportError{Row: i + 1, ExternalID: rec["FITID"], Error: err.Error()})
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, errs, nil
+}
+
+// ofxLine converts one STMTTRN record into a statement line in currency.
+func ofxLine(row int, rec map[string]string, currency string) (models.StatementLine, error) {
+	date, err := parseOFXDate(rec["DTPOSTED"])
+	if err != nil {
+		return models.StatementLine{}, fmt.Errorf("DTPOSTED: %w", err)
+	}
+	amount, err := parseOFXAmount(rec["TRNAMT"], currency)
+	if err != nil {
+		return models.StatementLine{}, fmt.Errorf("TRNAMT: %w", err)
+	}
+	if amount == 0 {
+		return models.StatementLine{}, errors.New("TRNAMT: amount is zero")
+	}
+
+	description := rec["NAME"]
+	if memo := rec["MEMO"]; memo != "" && memo != description {
+		if description != "" {
+			description += " - "
+		}
+		description += memo
+	}
+	reference := rec["REFNUM"]
+	if reference == "" {
+		reference = rec["CHECKNUM"]
+	}
+
+	return models.StatementLine{
+		Row:         row,
+		ExternalID:  rec["FITID"],
+		Date:        date,
+		Amount:      amount,
+		Description: description,
+		Reference:   reference,
+	}, nil
+}
+
+// parseOFXDate converts an OFX datetime (YYYYMMDD[HHMMSS[.XXX]][[TZ]]) to
+// YYYY-MM-DD. Only the date part is used.
+func parseOFXDate(v string) (string, error) {
+	if len(v) < 8 {
+		return "", fmt.Errorf("invalid date %q", v)
+	}
+	t, err := time.Parse("20060102", v[:8])
+	if err != nil {
+		return "", fmt.Errorf("invalid date %q", v)
+	}
+	return t.Format("2006-01-02"), nil
+}
+
+// parseOFXAmount converts a signed decimal amount to the smallest unit of
+// currency, paise for INR. A comma is accepted as the decimal separator, as
+// some banks emit one.
+func parseOFXAmount(v, currency string) (models.Money, error) {
+	if v == "" {
+		return 0, errors.New("missing amount")
+	}
+	f, err := strconv.ParseFloat(strings.Replace(v, ",", ".", 1), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", v)
+	}
+	return models.FromFloatIn(f, currency), nil
+}
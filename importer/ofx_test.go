@@ -0,0 +1,91 @@
+package importer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/satheeshds/portal/models"
+)
+
+const sgmlOFX = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+
+<OFX>
+<BANKMSGSRSV1><STMTTRNRS><STMTRS>
+<BANKTRANLIST>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20240115120000.000[+5.30:IST]
+<TRNAMT>1500.50
+<FITID>F1
+<NAME>SWIGGY BUNDL
+<MEMO>NEFT settlement
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20240116
+<TRNAMT>-250,00
+<FITID>F2
+<NAME>Electricity &amp; Water
+<CHECKNUM>000123
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>2024
+<TRNAMT>-10.00
+<FITID>F3
+</STMTTRN>
+</BANKTRANLIST>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>`
+
+const xmlOFX = `<?xml version="1.0" encoding="UTF-8"?>
+<?OFX OFXHEADER="200" VERSION="220"?>
+<OFX><BANKMSGSRSV1><STMTTRNRS><STMTRS><BANKTRANLIST>
+<STMTTRN><TRNTYPE>CREDIT</TRNTYPE><DTPOSTED>20240201</DTPOSTED><TRNAMT>99.99</TRNAMT><FITID>X1</FITID><MEMO>Interest</MEMO><REFNUM>R9</REFNUM></STMTTRN>
+</BANKTRANLIST></STMTRS></STMTTRNRS></BANKMSGSRSV1></OFX>`
+
+func TestParseOFX_SGML(t *testing.T) {
+	lines, errs, err := ParseOFX(strings.NewReader(sgmlOFX), "INR")
+	if err != nil {
+		t.Fatalf("ParseOFX: %v", err)
+	}
+	want := []models.StatementLine{
+		{Row: 1, ExternalID: "F1", Date: "2024-01-15", Amount: 150050, Description: "SWIGGY BUNDL - NEFT settlement"},
+		{Row: 2, ExternalID: "F2", Date: "2024-01-16", Amount: -25000, Description: "Electricity & Water", Reference: "000123"},
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines, want %d: %+v", len(lines), len(want), lines)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d = %+v, want %+v", i, lines[i], want[i])
+		}
+	}
+	if len(errs) != 1 || errs[0].Row != 3 || errs[0].ExternalID != "F3" {
+		t.Errorf("expected one error for row 3 (F3), got %+v", errs)
+	}
+}
+
+func TestParseOFX_XML(t *testing.T) {
+	lines, errs, err := ParseOFX(strings.NewReader(xmlOFX), "INR")
+	if err != nil {
+		t.Fatalf("ParseOFX: %v", err)
+	}
+	if len(errs) != 0 {
+		t.Errorf("unexpected errors: %+v", errs)
+	}
+	want := models.StatementLine{Row: 1, ExternalID: "X1", Date: "2024-02-01", Amount: 9999, Description: "Interest", Reference: "R9"}
+	if len(lines) != 1 || lines[0] != want {
+		t.Errorf("got %+v, want [%+v]", lines, want)
+	}
+}
+
+func TestParseOFX_NoTransactions(t *testing.T) {
+	_, _, err := ParseOFX(strings.NewReader("date,amount\n2024-01-01,10"), "INR")
+	if !errors.Is(err, ErrNoOFXTransactions) {
+		t.Errorf("expected ErrNoOFXTransactions, got %v", err)
+	}
+}
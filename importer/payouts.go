@@ -0,0 +1,336 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/satheeshds/portal/models"
+)
+
+// payoutFields are the columns of a platform settlement report a payout is
+// read from: the outlet, its period (as one "from - to" column or as start
+// and end columns), settlement date, order count, amounts, and bank UTR.
+// TCS and TDS may be reported apart and are added up. order_date dates the
+// rows of an order-level report without a period.
+var payoutFields = []string{"outlet", "period", "period_start", "period_end", "settlement_date", "order_date",
+	"total_orders", "gross_sales", "restaurant_discount", "commission", "tcs", "tds", "marketing_ads", "final_payout", "utr"}
+
+// payoutKeyFields are the fields whose cells tell the payouts of an
+// order-level report apart.
+var payoutKeyFields = []string{"outlet", "period", "period_start", "period_end", "settlement_date", "utr"}
+
+// payoutReport describes the settlement report of one platform.
+type payoutReport struct {
+	name     string              // as written in error messages, e.g. Swiggy
+	platform string              // the payouts' platform, e.g. swiggy
+	variants map[string][]string // header names for each of payoutFields, normalized by normalizeHeader, most preferred first
+	// byOrder marks a report with a row per order rather than per payout;
+	// see mergePayoutLines.
+	byOrder bool
+}
+
+// payoutDateLayouts are the dates accepted in settlement reports beyond
+// csvDateLayouts, as partner portals spell out months.
+var payoutDateLayouts = []string{"2 Jan 2006", "02 Jan, 2006", "2 Jan, 2006", "Jan 2, 2006", "02-Jan-2006 15:04:05"}
+
+// parsePayoutReport reads payouts from a CSV or XLSX settlement report laid
+// out as report describes: the header row is found among the first rows of
+// the file and must have a net payout column and a period or settlement date
+// column. outletName stands in for the outlet of a report without an outlet
+// column. Totals rows and blank rows are skipped; rows are numbered by their
+// line or sheet row. Deductions are taken as positive amounts however the
+// report signs them. The rows of an order-level report are merged into
+// payouts by mergePayoutLines. Lines are passed on for PayoutInput.Validate
+// to check.
+func parsePayoutReport(r io.Reader, report payoutReport, outletName string) ([]models.PayoutLine, []models.ImportError, error) {
+	records, lineNumbers, err := readSpreadsheet(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	header := -1
+	var columns map[string]int
+	for i := 0; i < len(records) && i <= maxCSVPreamble; i++ {
+		columns = payoutColumns(records[i], report.variants)
+		_, hasPayout := columns["final_payout"]
+		_, hasPeriod := columns["period"]
+		_, hasStart := columns["period_start"]
+		_, hasSettled := columns["settlement_date"]
+		_, hasOrderDate := columns["order_date"]
+		if hasPayout && (hasPeriod || hasStart || hasSettled || report.byOrder && hasOrderDate) {
+			header = i
+			break
+		}
+	}
+	if header < 0 {
+		return nil, nil, fmt.Errorf("no %s settlement report header with a net payout and a period or settlement date column found", report.name)
+	}
+	if _, ok := columns["outlet"]; !ok && strings.TrimSpace(outletName) == "" {
+		return nil, nil, errors.New("the report has no outlet column; set outlet_name")
+	}
+
+	var lines []models.PayoutLine
+	var errs []models.ImportError
+	keys := map[int]string{}
+	for i := header + 1; i < len(records); i++ {
+		rec := records[i]
+		if blankRecord(rec) || totalsRecord(rec) {
+			continue
+		}
+		if report.byOrder {
+			keys[lineNumbers[i]] = payoutKey(rec, columns, outletName)
+		}
+		input, err := payoutLine(rec, columns, report.platform, outletName)
+		if err != nil {
+			errs = append(errs, models.ImportError{Row: lineNumbers[i], Error: err.Error()})
+			continue
+		}
+		lines = append(lines, models.PayoutLine{Row: lineNumbers[i], Input: input})
+	}
+	if report.byOrder {
+		_, countsOrders := columns["total_orders"]
+		lines, errs = mergePayoutLines(lines, errs, keys, countsOrders)
+	}
+	return lines, errs, nil
+}
+
+// payoutKey identifies the payout a row of an order-level report belongs to
+// by its payoutKeyFields cells, compared ignoring case.
+func payoutKey(rec []string, columns map[string]int, outletName string) string {
+	cells := make([]string, len(payoutKeyFields))
+	for i, field := range payoutKeyFields {
+		if col, ok := columns[field]; ok && col < len(rec) {
+			cells[i] = strings.ToLower(strings.TrimSpace(rec[col]))
+		}
+	}
+	if cells[0] == "" {
+		cells[0] = strings.ToLower(strings.TrimSpace(outletName))
+	}
+	return strings.Join(cells, "\x1f")
+}
+
+// mergePayoutLines merges the rows of an order-level report into one payout
+// per outlet and period, or settlement date and UTR without one, as told
+// apart by keys, the payoutKey of each row by its line. Amounts are added up,
+// each row counts as one order unless the report has an order count column,
+// and a payout dated by its orders runs from the first to the last. A payout
+// with an invalid row is not imported short of it: its other rows are
+// reported on the first of them instead.
+func mergePayoutLines(lines []models.PayoutLine, errs []models.ImportError, keys map[int]string, countsOrders bool) ([]models.PayoutLine, []models.ImportError) {
+	invalid := map[string]int{} // key → first invalid row
+	for _, e := range errs {
+		if _, ok := invalid[keys[e.Row]]; !ok {
+			invalid[keys[e.Row]] = e.Row
+		}
+	}
+
+	var merged []models.PayoutLine
+	at := map[string]int{}
+	for _, line := range lines {
+		key := keys[line.Row]
+		in := line.Input
+		if !countsOrders {
+			in.TotalOrders = 1
+		}
+		i, ok := at[key]
+		if !ok {
+			at[key] = len(merged)
+			merged = append(merged, models.PayoutLine{Row: line.Row, Input: in})
+			continue
+		}
+		p := &merged[i].Input
+		p.TotalOrders += in.TotalOrders
+		p.GrossSalesAmt += in.GrossSalesAmt
+		p.RestaurantDiscountAmt += in.RestaurantDiscountAmt
+		p.PlatformCommissionAmt += in.PlatformCommissionAmt
+		p.TaxesTcsTdsAmt += in.TaxesTcsTdsAmt
+		p.MarketingAdsAmt += in.MarketingAdsAmt
+		p.FinalPayoutAmt += in.FinalPayoutAmt
+		if in.PeriodStart != nil && (p.PeriodStart == nil || *in.PeriodStart < *p.PeriodStart) {
+			p.PeriodStart = in.PeriodStart
+		}
+		if in.PeriodEnd != nil && (p.PeriodEnd == nil || *in.PeriodEnd > *p.PeriodEnd) {
+			p.PeriodEnd = in.PeriodEnd
+		}
+	}
+
+	valid := merged[:0]
+	for _, line := range merged {
+		if row, bad := invalid[keys[line.Row]]; bad {
+			errs = append(errs, models.ImportError{Row: line.Row, Error: fmt.Sprintf("payout not imported: row %d of it is invalid", row)})
+			continue
+		}
+		valid = append(valid, line)
+	}
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Row < errs[j].Row })
+	return valid, errs
+}
+
+// payoutColumns maps payoutFields to the columns of a candidate header row by
+// the first matching variant.
+func payoutColumns(row []string, variants map[string][]string) map[string]int {
+	byName := make(map[string]int, len(row))
+	for col, name := range row {
+		if n := normalizeHeader(name); n != "" {
+			if _, dup := byName[n]; !dup {
+				byName[n] = col
+			}
+		}
+	}
+	columns := map[string]int{}
+	used := map[int]bool{}
+	for _, field := range payoutFields {
+		for _, variant := range variants[field] {
+			if col, ok := byName[variant]; ok && !used[col] {
+				columns[field] = col
+				used[col] = true
+				break
+			}
+		}
+	}
+	return columns
+}
+
+// totalsRecord reports whether rec is a totals row, whose first filled cell
+// reads Total or Grand Total.
+func totalsRecord(rec []string) bool {
+	for _, v := range rec {
+		if v = normalizeHeader(v); v != "" {
+			return v == "total" || v == "totals" || v == "grandtotal"
+		}
+	}
+	return false
+}
+
+// payoutLine converts one data row of a settlement report into a payout.
+func payoutLine(rec []string, columns map[string]int, platform, outletName string) (models.PayoutInput, error) {
+	cell := func(field string) string {
+		col, ok := columns[field]
+		if !ok || col >= len(rec) {
+			return ""
+		}
+		return strings.TrimSpace(rec[col])
+	}
+	amount := func(field string) (models.Money, error) {
+		m, err := parseCSVAmount(cell(field), "")
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", strings.ReplaceAll(field, "_", " "), err)
+		}
+		return m, nil
+	}
+	deduction := func(field string) (models.Money, error) {
+		m, err := amount(field)
+		return max(m, -m), err
+	}
+	date := func(field, v string) (*string, error) {
+		if v == "" {
+			return nil, nil
+		}
+		d, err := parsePayoutDate(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", strings.ReplaceAll(field, "_", " "), err)
+		}
+		return &d, nil
+	}
+
+	in := models.PayoutInput{Platform: platform, OutletName: cell("outlet"), UtrNumber: cell("utr")}
+	if in.OutletName == "" {
+		in.OutletName = strings.TrimSpace(outletName)
+	}
+
+	start, end := cell("period_start"), cell("period_end")
+	if period := cell("period"); period != "" && start == "" && end == "" {
+		var ok bool
+		if start, end, ok = splitPeriod(period); !ok {
+			return in, fmt.Errorf("period: %q is not a from - to date range", period)
+		}
+	}
+	startField, endField := "period_start", "period_end"
+	// The orders of an order-level report date its payout.
+	if start == "" && end == "" {
+		start, end = cell("order_date"), cell("order_date")
+		startField, endField = "order_date", "order_date"
+	}
+	var err error
+	if in.PeriodStart, err = date(startField, start); err != nil {
+		return in, err
+	}
+	if in.PeriodEnd, err = date(endField, end); err != nil {
+		return in, err
+	}
+	if in.SettlementDate, err = date("settlement_date", cell("settlement_date")); err != nil {
+		return in, err
+	}
+	if in.PeriodStart == nil && in.PeriodEnd == nil && in.SettlementDate == nil {
+		return in, errors.New("period or settlement date is missing")
+	}
+
+	if v := strings.ReplaceAll(cell("total_orders"), ",", ""); v != "" {
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil || n < 0 || n != float64(int(n)) {
+			return in, fmt.Errorf("total orders: invalid count %q", v)
+		}
+		in.TotalOrders = int(n)
+	}
+	if cell("final_payout") == "" {
+		return in, errors.New("final payout is missing")
+	}
+	if in.FinalPayoutAmt, err = amount("final_payout"); err != nil {
+		return in, err
+	}
+	if in.GrossSalesAmt, err = amount("gross_sales"); err != nil {
+		return in, err
+	}
+	if in.RestaurantDiscountAmt, err = deduction("restaurant_discount"); err != nil {
+		return in, err
+	}
+	if in.PlatformCommissionAmt, err = deduction("commission"); err != nil {
+		return in, err
+	}
+	tcs, err := deduction("tcs")
+	if err != nil {
+		return in, err
+	}
+	tds, err := deduction("tds")
+	if err != nil {
+		return in, err
+	}
+	in.TaxesTcsTdsAmt = tcs + tds
+	if in.MarketingAdsAmt, err = deduction("marketing_ads"); err != nil {
+		return in, err
+	}
+	return in, nil
+}
+
+// splitPeriod splits a period such as "01-Jan-2024 - 07-Jan-2024" or
+// "01/01/2024 to 07/01/2024" into its dates.
+func splitPeriod(v string) (start, end string, ok bool) {
+	for _, sep := range []string{" to ", " - ", " – ", " — "} {
+		if start, end, ok = strings.Cut(v, sep); ok {
+			return strings.TrimSpace(start), strings.TrimSpace(end), true
+		}
+	}
+	return "", "", false
+}
+
+// parsePayoutDate converts a settlement report date to YYYY-MM-DD: an Excel
+// serial date, one of csvDateLayouts, or one of payoutDateLayouts.
+func parsePayoutDate(v string) (string, error) {
+	if d, ok := parseSerialDate(v); ok {
+		return d, nil
+	}
+	if d, err := parseCSVDate(v); err == nil {
+		return d, nil
+	}
+	for _, layout := range payoutDateLayouts {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t.Format("2006-01-02"), nil
+		}
+	}
+	return "", fmt.Errorf("invalid date %q", v)
+}
@@ -0,0 +1,41 @@
+package importer
+
+import (
+	"io"
+
+	"github.com/satheeshds/portal/models"
+)
+
+// swiggyReport is the weekly settlement report exported from the Swiggy
+// partner portal, as CSV or XLSX.
+var swiggyReport = payoutReport{
+	name:     "Swiggy",
+	platform: "swiggy",
+	variants: map[string][]string{
+		"outlet":          {"restaurantname", "outletname", "restaurant", "outlet", "storename"},
+		"period":          {"payoutperiod", "payoutcycle", "settlementperiod", "billingperiod", "period"},
+		"period_start":    {"payoutperiodstart", "periodstart", "cyclestartdate", "fromdate", "startdate", "orderdatefrom"},
+		"period_end":      {"payoutperiodend", "periodend", "cycleenddate", "todate", "enddate", "orderdateto"},
+		"settlement_date": {"settlementdate", "payoutdate", "paymentdate", "transferdate", "creditedon", "paiddate"},
+		"total_orders":    {"totalorders", "nooforders", "deliveredorders", "orderscount", "orders"},
+		"gross_sales":     {"grosssales", "grossordervalue", "itemtotal", "totalsales", "ordervalue"},
+		"restaurant_discount": {"restaurantdiscount", "restaurantdiscountshare", "discountsharebyrestaurant",
+			"merchantdiscount", "discounts"},
+		"commission": {"swiggycommission", "commission", "platformcommission", "servicefee", "servicefees",
+			"platformfee", "platformfees"},
+		"tcs":           {"tcs", "tcsdeducted", "tcsamount", "taxcollectedatsource", "tcstds", "taxestcstds"},
+		"tds":           {"tds", "tdsdeducted", "tdsamount", "tds194o"},
+		"marketing_ads": {"adsfee", "adcharges", "ads", "marketingfee", "marketing", "advertisement", "advertisementfee"},
+		"final_payout": {"netpayout", "netpayoutamount", "finalpayout", "payoutamount", "netpayable", "amounttransferred",
+			"amountcredited", "netamount"},
+		"utr": {"utr", "utrno", "utrnumber", "bankutr", "bankreference", "transactionreference", "referenceno"},
+	},
+}
+
+// ParseSwiggyPayouts reads payouts from a Swiggy weekly settlement report.
+// Columns are matched by the names the partner portal uses (e.g. "Net
+// Payout" is the final payout, "Swiggy Commission" the commission); see
+// parsePayoutReport for the rest.
+func ParseSwiggyPayouts(r io.Reader, outletName string) ([]models.PayoutLine, []models.ImportError, error) {
+	return parsePayoutReport(r, swiggyReport, outletName)
+}
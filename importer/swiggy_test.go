@@ -0,0 +1,127 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const swiggyCSV = `Swiggy Partner Payout Report
+Restaurant ID: 12345
+
+Restaurant Name,Payout Period,Settlement Date,Total Orders,Item Total,Restaurant Discount Share,Swiggy Commission,TCS,TDS,Ads Fee,Net Payout,UTR No
+Koramangala,01-Jan-2024 - 07-Jan-2024,10/01/2024,120,"50,000.00",-1500.00,-9000.00,-250.00,-50.00,-500.00,"38,700.00",UTR0001
+Koramangala,08-Jan-2024 - 14-Jan-2024,bad,90,1000,0,0,0,0,0,1000,UTR0002
+Indiranagar,08-Jan-2024 to 14-Jan-2024,17-01-2024,,2000,0,300,10,0,0,1690,
+Total,,,210,"53,000.00",,,,,,"41,390.00",
+`
+
+// TestParseSwiggyPayouts_CSV verifies that a Swiggy report's header is found
+// below its preamble, amounts are read in paise with deductions made
+// positive, TCS and TDS are added up, and a bad row is reported.
+func TestParseSwiggyPayouts_CSV(t *testing.T) {
+	lines, errs, err := ParseSwiggyPayouts(strings.NewReader(swiggyCSV), "")
+	if err != nil {
+		t.Fatalf("ParseSwiggyPayouts: %v", err)
+	}
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 payouts, got %+v", lines)
+	}
+	if len(errs) != 1 || errs[0].Row != 6 || !strings.HasPrefix(errs[0].Error, "settlement date:") {
+		t.Errorf("expected the bad settlement date on line 6 reported, got %+v", errs)
+	}
+
+	p := lines[0].Input
+	if lines[0].Row != 5 || p.Platform != "swiggy" || p.OutletName != "Koramangala" || p.UtrNumber != "UTR0001" {
+		t.Errorf("unexpected first payout: row %d, %+v", lines[0].Row, p)
+	}
+	if *p.PeriodStart != "2024-01-01" || *p.PeriodEnd != "2024-01-07" || *p.SettlementDate != "2024-01-10" {
+		t.Errorf("unexpected first payout dates: %s to %s, settled %s", *p.PeriodStart, *p.PeriodEnd, *p.SettlementDate)
+	}
+	if p.TotalOrders != 120 || p.GrossSalesAmt != 5000000 || p.RestaurantDiscountAmt != 150000 || p.PlatformCommissionAmt != 900000 ||
+		p.TaxesTcsTdsAmt != 30000 || p.MarketingAdsAmt != 50000 || p.FinalPayoutAmt != 3870000 {
+		t.Errorf("unexpected first payout amounts: %+v", p)
+	}
+	if p := lines[1].Input; *p.PeriodStart != "2024-01-08" || p.TotalOrders != 0 || p.TaxesTcsTdsAmt != 1000 || p.UtrNumber != "" {
+		t.Errorf("unexpected second payout: %+v", p)
+	}
+}
+
+// TestParseSwiggyPayouts_NoHeader verifies that a file that is not a
+// settlement report, or lacks an outlet, is rejected.
+func TestParseSwiggyPayouts_NoHeader(t *testing.T) {
+	if _, _, err := ParseSwiggyPayouts(strings.NewReader("Date,Amount\n2024-01-01,100\n"), ""); err == nil {
+		t.Error("expected an error for a file without a settlement report header")
+	}
+	const noOutlet = "Payout Date,Net Payout\n15/01/2024,100\n"
+	if _, _, err := ParseSwiggyPayouts(strings.NewReader(noOutlet), ""); err == nil {
+		t.Error("expected an error for a report without an outlet column or outlet_name")
+	}
+	lines, _, err := ParseSwiggyPayouts(strings.NewReader(noOutlet), "HSR Layout")
+	if err != nil || len(lines) != 1 || lines[0].Input.OutletName != "HSR Layout" {
+		t.Errorf("outlet_name: expected one payout for HSR Layout, got %+v, %v", lines, err)
+	}
+}
+
+// testXLSX builds a one-sheet workbook whose rows are given as cell XML.
+func testXLSX(t *testing.T, shared []string, rows ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	add := func(name, content string) {
+		f, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	add("xl/workbook.xml", `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+		xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+		<sheets><sheet name="Payouts" sheetId="1" r:id="rId1"/></sheets></workbook>`)
+	add("xl/_rels/workbook.xml.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+		<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+		</Relationships>`)
+	var sst strings.Builder
+	for _, s := range shared {
+		fmt.Fprintf(&sst, "<si><t>%s</t></si>", s)
+	}
+	add("xl/sharedStrings.xml", `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`+sst.String()+`</sst>`)
+	add("xl/worksheets/sheet1.xml", `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`+
+		strings.Join(rows, "")+`</sheetData></worksheet>`)
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// TestParseSwiggyPayouts_XLSX verifies that an XLSX report is read from its
+// first sheet, with shared and inline strings, serial dates, and cells placed
+// by reference.
+func TestParseSwiggyPayouts_XLSX(t *testing.T) {
+	data := testXLSX(t, []string{"Outlet Name", "Period Start", "Period End", "Payout Date", "Net Payout", "UTR", "Koramangala"},
+		`<row r="2"><c r="A2" t="s"><v>0</v></c><c r="B2" t="s"><v>1</v></c><c r="C2" t="s"><v>2</v></c>`+
+			`<c r="D2" t="s"><v>3</v></c><c r="E2" t="s"><v>4</v></c><c r="F2" t="s"><v>5</v></c></row>`,
+		`<row r="3"><c r="A3" t="s"><v>6</v></c><c r="B3"><v>45292</v></c><c r="C3"><v>45298</v></c>`+
+			`<c r="D3" t="inlineStr"><is><t>10 Jan 2024</t></is></c><c r="E3"><v>1234.5</v></c><c r="F3" t="str"><v>UTR9</v></c></row>`,
+		`<row r="4"><c r="A4" t="s"><v>6</v></c><c r="B4"><v>45299</v></c><c r="E4"><v>10</v></c></row>`)
+
+	lines, errs, err := ParseSwiggyPayouts(bytes.NewReader(data), "")
+	if err != nil {
+		t.Fatalf("ParseSwiggyPayouts: %v", err)
+	}
+	if len(lines) != 2 || len(errs) != 0 {
+		t.Fatalf("expected 2 payouts and no errors, got %+v, %+v", lines, errs)
+	}
+	p := lines[0].Input
+	if lines[0].Row != 3 || p.OutletName != "Koramangala" || *p.PeriodStart != "2024-01-01" || *p.PeriodEnd != "2024-01-07" ||
+		*p.SettlementDate != "2024-01-10" || p.FinalPayoutAmt != 123450 || p.UtrNumber != "UTR9" {
+		t.Errorf("unexpected first payout: row %d, %+v", lines[0].Row, p)
+	}
+	if p := lines[1].Input; *p.PeriodStart != "2024-01-08" || p.PeriodEnd != nil || p.FinalPayoutAmt != 1000 {
+		t.Errorf("unexpected second payout: %+v", p)
+	}
+}
@@ -0,0 +1,245 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotXLSX is returned for a file that is not an XLSX workbook.
+var ErrNotXLSX = errors.New("not an XLSX workbook")
+
+// xlsxMagic starts every XLSX file, which is a zip archive.
+var xlsxMagic = []byte("PK\x03\x04")
+
+// xlsxMaxColumns is the number of columns a worksheet can have, A to XFD.
+const xlsxMaxColumns = 16384
+
+// xlsxMaxPartSize caps how much of each part of a workbook is decompressed,
+// so a small upload cannot expand into gigabytes of XML.
+const xlsxMaxPartSize = 64 << 20
+
+// xlsxMaxCells caps the cells the rows of a worksheet are padded out to, so
+// rows of far-right cells cannot exhaust memory.
+const xlsxMaxCells = 4 << 20
+
+// isXLSX reports whether data starts like an XLSX file.
+func isXLSX(data []byte) bool {
+	return bytes.HasPrefix(data, xlsxMagic)
+}
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		RID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// xlsxText is a shared or inline string: plain text, or rich text runs.
+type xlsxText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.T
+	}
+	var b strings.Builder
+	for _, r := range t.Runs {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			Ref    string   `xml:"r,attr"`
+			Type   string   `xml:"t,attr"`
+			Value  string   `xml:"v"`
+			Inline xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// readXLSX reads every row of the first worksheet of an XLSX workbook as
+// text, with the sheet row number of each, as readCSV does for a CSV file.
+// Cells are placed by their reference, so skipped empty cells keep later
+// ones in their column. Numbers, including dates, are returned as written in
+// the file: Excel stores a date as its serial day number, see
+// parseSerialDate.
+func readXLSX(data []byte) ([][]string, []int, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, nil, ErrNotXLSX
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+	decode := func(name string, v any) error {
+		f, ok := files[name]
+		if !ok {
+			return fmt.Errorf("%w: %s is missing", ErrNotXLSX, name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		lr := &io.LimitedReader{R: rc, N: xlsxMaxPartSize + 1}
+		err = xml.NewDecoder(lr).Decode(v)
+		if lr.N == 0 {
+			return fmt.Errorf("%w: %s is larger than %d MB", ErrNotXLSX, name, xlsxMaxPartSize>>20)
+		}
+		return err
+	}
+
+	var wb xlsxWorkbook
+	if err := decode("xl/workbook.xml", &wb); err != nil {
+		return nil, nil, err
+	}
+	if len(wb.Sheets) == 0 {
+		return nil, nil, fmt.Errorf("%w: no worksheets", ErrNotXLSX)
+	}
+	var rels xlsxRelationships
+	if err := decode("xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, nil, err
+	}
+	sheetPath := ""
+	for _, rel := range rels.Relationships {
+		if rel.ID == wb.Sheets[0].RID {
+			sheetPath = rel.Target
+		}
+	}
+	if sheetPath == "" {
+		return nil, nil, fmt.Errorf("%w: first worksheet not found", ErrNotXLSX)
+	}
+	if strings.HasPrefix(sheetPath, "/") {
+		sheetPath = strings.TrimPrefix(sheetPath, "/")
+	} else {
+		sheetPath = path.Join("xl", sheetPath)
+	}
+
+	var shared []string
+	if _, ok := files["xl/sharedStrings.xml"]; ok {
+		var sst struct {
+			Items []xlsxText `xml:"si"`
+		}
+		if err := decode("xl/sharedStrings.xml", &sst); err != nil {
+			return nil, nil, err
+		}
+		for _, si := range sst.Items {
+			shared = append(shared, si.String())
+		}
+	}
+
+	var sheet xlsxSheet
+	if err := decode(sheetPath, &sheet); err != nil {
+		return nil, nil, err
+	}
+	var records [][]string
+	var lineNumbers []int
+	cells := 0
+	for i, row := range sheet.Rows {
+		var rec []string
+		for _, c := range row.Cells {
+			col := len(rec)
+			if c.Ref != "" {
+				if col, err = xlsxColumn(c.Ref); err != nil {
+					return nil, nil, err
+				}
+			}
+			v := c.Value
+			switch c.Type {
+			case "s":
+				n, err := strconv.Atoi(v)
+				if err != nil || n < 0 || n >= len(shared) {
+					return nil, nil, fmt.Errorf("%w: cell %s refers to a missing shared string", ErrNotXLSX, c.Ref)
+				}
+				v = shared[n]
+			case "inlineStr":
+				v = c.Inline.String()
+			case "b":
+				v = map[string]string{"0": "FALSE", "1": "TRUE"}[v]
+			}
+			if col >= len(rec) {
+				if cells += col + 1 - len(rec); cells > xlsxMaxCells {
+					return nil, nil, fmt.Errorf("%w: worksheet has more than %d cells", ErrNotXLSX, xlsxMaxCells)
+				}
+			}
+			for len(rec) <= col {
+				rec = append(rec, "")
+			}
+			rec[col] = v
+		}
+		line := row.R
+		if line == 0 {
+			line = i + 1
+		}
+		records = append(records, rec)
+		lineNumbers = append(lineNumbers, line)
+	}
+	return records, lineNumbers, nil
+}
+
+// xlsxColumn returns the zero-based column of a cell reference such as AB12.
+// References beyond column XFD are rejected.
+func xlsxColumn(ref string) (int, error) {
+	col := 0
+	i := 0
+	for ; i < len(ref) && ref[i] >= 'A' && ref[i] <= 'Z'; i++ {
+		col = col*26 + int(ref[i]-'A') + 1
+		if col > xlsxMaxColumns {
+			return 0, fmt.Errorf("%w: cell %.12s is beyond column XFD", ErrNotXLSX, ref)
+		}
+	}
+	if i == 0 {
+		return 0, fmt.Errorf("%w: invalid cell reference %q", ErrNotXLSX, ref)
+	}
+	return col - 1, nil
+}
+
+// readSpreadsheet reads the rows of an uploaded CSV or XLSX file, telling
+// them apart by content.
+func readSpreadsheet(r io.Reader) ([][]string, []int, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	if isXLSX(data) {
+		return readXLSX(data)
+	}
+	return readCSV(bytes.NewReader(data))
+}
+
+// excelEpoch is day 0 of Excel's serial dates, which count 1900 as a leap
+// year; from 1 March 1900 on, counting from 30 December 1899 is exact.
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// parseSerialDate converts an Excel serial date such as 45292 (2024-01-01),
+// with any time of day as its fraction, to YYYY-MM-DD.
+func parseSerialDate(v string) (string, bool) {
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f < 61 || f > 2958465 {
+		return "", false
+	}
+	return excelEpoch.AddDate(0, 0, int(f)).Format("2006-01-02"), true
+}
@@ -0,0 +1,52 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestXLSXColumn verifies that references are read up to column XFD and that
+// longer ones are rejected rather than overflowing.
+func TestXLSXColumn(t *testing.T) {
+	for ref, want := range map[string]int{"A1": 0, "Z9": 25, "AB12": 27, "XFD1048576": 16383} {
+		if got, err := xlsxColumn(ref); err != nil || got != want {
+			t.Errorf("xlsxColumn(%q) = %d, %v; want %d", ref, got, err, want)
+		}
+	}
+	for _, ref := range []string{"XFE1", "ZZZZZZZZ1", strings.Repeat("Z", 40) + "1", "12"} {
+		if _, err := xlsxColumn(ref); !errors.Is(err, ErrNotXLSX) {
+			t.Errorf("xlsxColumn(%q): expected ErrNotXLSX, got %v", ref, err)
+		}
+	}
+}
+
+// TestReadXLSX_Limits verifies that worksheets padding out to too many cells
+// and parts that decompress past xlsxMaxPartSize are rejected.
+func TestReadXLSX_Limits(t *testing.T) {
+	rows := make([]string, xlsxMaxCells/xlsxMaxColumns+1)
+	for i := range rows {
+		rows[i] = `<row><c r="XFD1"><v>1</v></c></row>`
+	}
+	if _, _, err := readXLSX(testXLSX(t, nil, rows...)); !errors.Is(err, ErrNotXLSX) {
+		t.Errorf("too many cells: expected ErrNotXLSX, got %v", err)
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	f, err := zw.Create("xl/workbook.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("<workbook>" + strings.Repeat(" ", xlsxMaxPartSize) + "</workbook>")); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := readXLSX(buf.Bytes()); err == nil || !strings.Contains(err.Error(), "larger than") {
+		t.Errorf("oversized part: expected a size error, got %v", err)
+	}
+}
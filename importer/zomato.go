@@ -0,0 +1,43 @@
+package importer
+
+import (
+	"io"
+
+	"github.com/satheeshds/portal/models"
+)
+
+// zomatoReport is the payout annexure downloaded from the Zomato restaurant
+// partner dashboard: a row per order, for one or more outlets, with the
+// cycle's payout date and UTR.
+var zomatoReport = payoutReport{
+	name:     "Zomato",
+	platform: "zomato",
+	byOrder:  true,
+	variants: map[string][]string{
+		"outlet":          {"resname", "restaurantname", "outletname", "restaurant"},
+		"period":          {"payoutcycle", "payoutperiod", "settlementcycle"},
+		"period_start":    {"payoutcyclestart", "payoutperiodstart", "periodstart", "cyclestartdate", "fromdate", "startdate"},
+		"period_end":      {"payoutcycleend", "payoutperiodend", "periodend", "cycleenddate", "todate", "enddate"},
+		"settlement_date": {"payoutdate", "payoutsettlementdate", "settlementdate", "paymentdate", "transferdate"},
+		"order_date":      {"orderdate", "orderplacedat", "orderdatetime"},
+		"total_orders":    {"totalorders", "nooforders"},
+		"gross_sales":     {"subtotalitemstotal", "subtotal", "billsubtotal", "itemstotal", "ordervalue", "grosssales"},
+		"restaurant_discount": {"restaurantdiscountpromo", "restaurantdiscount", "merchantdiscount",
+			"discountbyrestaurant"},
+		"commission":    {"commissionvalue", "commissionamount", "zomatocommission", "commission", "servicefee", "platformfee"},
+		"tcs":           {"tcs", "tcsamount", "taxcollectedatsource"},
+		"tds":           {"tds194o", "tds", "tdsamount"},
+		"marketing_ads": {"adsdeduction", "adsfee", "ads", "adcharges", "marketingfee"},
+		"final_payout": {"orderlevelpayout", "netpayout", "finalpayout", "payoutamount", "netpayable", "netreceivable",
+			"netamount"},
+		"utr": {"utrno", "utr", "utrnumber", "bankutr"},
+	},
+}
+
+// ParseZomatoPayouts reads payouts from a Zomato payout annexure. Its order
+// rows are merged into one payout per outlet per payout cycle, or per payout
+// date and UTR when the annexure has no cycle columns, dated by the first and
+// last order; see parsePayoutReport for the rest.
+func ParseZomatoPayouts(r io.Reader, outletName string) ([]models.PayoutLine, []models.ImportError, error) {
+	return parsePayoutReport(r, zomatoReport, outletName)
+}
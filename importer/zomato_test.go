@@ -0,0 +1,51 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+)
+
+const zomatoCSV = `Res. ID,Res. name,Order ID,Order date,Subtotal (items total),Restaurant discount (promo),Commission value,TCS,TDS 194O,Order level Payout,Payout date,UTR no.
+101,Koramangala,Z1,2024-01-02 12:30:00,500.00,-50.00,-90.00,-2.25,-0.45,357.30,10/01/2024,ZUTR1
+102,Indiranagar,Z2,2024-01-03 13:00:00,300.00,0,-54.00,-1.50,-0.30,244.20,10/01/2024,ZUTR2
+101,koramangala,Z3,2024-01-06 20:15:00,200.00,0,-36.00,-1.00,-0.20,162.80,10/01/2024,ZUTR1
+101,Koramangala,Z4,2024-01-09 20:15:00,100.00,0,-18.00,-0.50,-0.10,81.40,17/01/2024,ZUTR3
+102,Indiranagar,Z5,2024-01-10 09:00:00,100.00,0,-18.00,-0.50,-0.10,81.40,17/01/2024,ZUTR4
+102,Indiranagar,Z6,bad,100.00,0,-18.00,-0.50,-0.10,81.40,17/01/2024,ZUTR4
+`
+
+// TestParseZomatoPayouts verifies that the order rows of a multi-outlet
+// annexure are merged into one payout per outlet per cycle, dated by their
+// orders, and that a payout with an invalid order row is held back.
+func TestParseZomatoPayouts(t *testing.T) {
+	lines, errs, err := ParseZomatoPayouts(strings.NewReader(zomatoCSV), "")
+	if err != nil {
+		t.Fatalf("ParseZomatoPayouts: %v", err)
+	}
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 payouts, got %+v", lines)
+	}
+
+	p := lines[0].Input
+	if lines[0].Row != 2 || p.Platform != "zomato" || p.OutletName != "Koramangala" || p.UtrNumber != "ZUTR1" || p.TotalOrders != 2 {
+		t.Errorf("unexpected Koramangala payout: row %d, %+v", lines[0].Row, p)
+	}
+	if *p.PeriodStart != "2024-01-02" || *p.PeriodEnd != "2024-01-06" || *p.SettlementDate != "2024-01-10" {
+		t.Errorf("unexpected Koramangala dates: %s to %s, settled %s", *p.PeriodStart, *p.PeriodEnd, *p.SettlementDate)
+	}
+	if p.GrossSalesAmt != 70000 || p.RestaurantDiscountAmt != 5000 || p.PlatformCommissionAmt != 12600 ||
+		p.TaxesTcsTdsAmt != 390 || p.FinalPayoutAmt != 52010 {
+		t.Errorf("unexpected Koramangala amounts: %+v", p)
+	}
+	if p := lines[1].Input; p.OutletName != "Indiranagar" || p.TotalOrders != 1 || p.FinalPayoutAmt != 24420 {
+		t.Errorf("unexpected Indiranagar payout: %+v", p)
+	}
+	if p := lines[2].Input; p.UtrNumber != "ZUTR3" || *p.PeriodStart != "2024-01-09" {
+		t.Errorf("unexpected second Koramangala payout: %+v", p)
+	}
+
+	if len(errs) != 2 || errs[0].Row != 6 || errs[1].Row != 7 || !strings.HasPrefix(errs[1].Error, "order date:") ||
+		!strings.Contains(errs[0].Error, "row 7") {
+		t.Errorf("expected the bad order and its held-back payout reported, got %+v", errs)
+	}
+}
@@ -4,15 +4,17 @@ package main
 
 import (
 	"embed"
+	"encoding/json"
 	"fmt"
 	"io/fs"
 	"log/slog"
 	"net/http"
 	"os"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
-	_ "github.com/satheeshds/portal/docs"
+	"github.com/satheeshds/portal/docs"
 	"github.com/satheeshds/portal/handlers"
 	httpSwagger "github.com/swaggo/http-swagger/v2"
 )
@@ -40,100 +42,37 @@ func main() {
 	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: level})))
 
 	// Read all configuration from environment variables once at startup.
-	handlers.Configure(handlers.ConfigFromEnv())
+	cfg := handlers.ConfigFromEnv()
+	handlers.Configure(cfg)
 
 	// Router setup
 	r := chi.NewRouter()
+	// EventSource cannot set headers, so the /events stream also takes its
+	// token as ?access_token=; TokenFromQuery moves it out of the URL before
+	// the request is logged.
+	r.Use(handlers.TokenFromQuery)
+	if cfg.TrustProxy {
+		// Behind a reverse proxy the connection comes from the proxy; take the
+		// client IP, used by logs and the sign-in lockout, from its headers.
+		r.Use(middleware.RealIP)
+	}
+	r.Use(middleware.RequestID)
 	r.Use(middleware.Logger)
+	r.Use(handlers.Compress)
 	r.Use(handlers.RequestLogger)
 	r.Use(middleware.Recoverer)
 
 	// Public auth routes (proxy to Nexus gateway)
 	r.Post("/api/v1/auth/register", handlers.Register)
 	r.Post("/api/v1/auth/login", handlers.Login)
+	r.Post("/api/v1/auth/refresh", handlers.Refresh)
+	r.Post("/api/v1/auth/logout", handlers.Logout)
 
 	// API routes with bearer token / basic auth
 	r.Route("/api/v1", func(r chi.Router) {
 		r.Use(handlers.BearerAuth)
 		r.Use(handlers.DBRequired)
-
-		// Accounts
-		r.Get("/accounts", handlers.ListAccounts)
-		r.Post("/accounts", handlers.CreateAccount)
-		r.Get("/accounts/{id}", handlers.GetAccount)
-		r.Put("/accounts/{id}", handlers.UpdateAccount)
-		r.Delete("/accounts/{id}", handlers.DeleteAccount)
-
-		// Contacts
-		r.Get("/contacts", handlers.ListContacts)
-		r.Post("/contacts", handlers.CreateContact)
-		r.Get("/contacts/{id}", handlers.GetContact)
-		r.Put("/contacts/{id}", handlers.UpdateContact)
-		r.Delete("/contacts/{id}", handlers.DeleteContact)
-
-		// Bills
-		r.Get("/bills", handlers.ListBills)
-		r.Post("/bills", handlers.CreateBill)
-		r.Get("/bills/{id}", handlers.GetBill)
-		r.Put("/bills/{id}", handlers.UpdateBill)
-		r.Delete("/bills/{id}", handlers.DeleteBill)
-		r.Get("/bills/{id}/links", handlers.GetBillLinks)
-		r.Get("/bills/{id}/match-suggestions", handlers.SuggestTransactionsForBill)
-		r.Get("/bills/{id}/items", handlers.ListBillItems)
-		r.Post("/bills/{id}/items", handlers.CreateBillItem)
-		r.Put("/bills/{id}/items/{itemId}", handlers.UpdateBillItem)
-		r.Delete("/bills/{id}/items/{itemId}", handlers.DeleteBillItem)
-
-		// Invoices
-		r.Get("/invoices", handlers.ListInvoices)
-		r.Post("/invoices", handlers.CreateInvoice)
-		r.Get("/invoices/{id}", handlers.GetInvoice)
-		r.Put("/invoices/{id}", handlers.UpdateInvoice)
-		r.Delete("/invoices/{id}", handlers.DeleteInvoice)
-		r.Get("/invoices/{id}/links", handlers.GetInvoiceLinks)
-		r.Get("/invoices/{id}/match-suggestions", handlers.SuggestTransactionsForInvoice)
-		r.Get("/invoices/{id}/items", handlers.ListInvoiceItems)
-		r.Post("/invoices/{id}/items", handlers.CreateInvoiceItem)
-		r.Put("/invoices/{id}/items/{itemId}", handlers.UpdateInvoiceItem)
-		r.Delete("/invoices/{id}/items/{itemId}", handlers.DeleteInvoiceItem)
-
-		// Transactions
-		r.Get("/transactions", handlers.ListTransactions)
-		r.Post("/transactions", handlers.CreateTransaction)
-		r.Get("/transactions/{id}", handlers.GetTransaction)
-		r.Put("/transactions/{id}", handlers.UpdateTransaction)
-		r.Delete("/transactions/{id}", handlers.DeleteTransaction)
-
-		// Transaction document links
-		r.Get("/transactions/{id}/links", handlers.ListTransactionLinks)
-		r.Post("/transactions/{id}/links", handlers.CreateTransactionLink)
-		r.Delete("/transactions/{id}/links/{linkId}", handlers.DeleteTransactionLink)
-
-		// Payment matching
-		r.Get("/transactions/{id}/match-suggestions", handlers.SuggestMatches)
-		r.Post("/transactions/{id}/auto-match", handlers.AutoMatch)
-
-		// Payouts
-		r.Get("/payouts", handlers.ListPayouts)
-		r.Post("/payouts", handlers.CreatePayout)
-		r.Get("/payouts/{id}", handlers.GetPayout)
-		r.Put("/payouts/{id}", handlers.UpdatePayout)
-		r.Delete("/payouts/{id}", handlers.DeletePayout)
-		r.Get("/payouts/{id}/links", handlers.GetPayoutLinks)
-		r.Get("/payouts/{id}/match-suggestions", handlers.SuggestTransactionsForPayout)
-
-		// Recurring Payments
-		r.Get("/recurring-payments", handlers.ListRecurringPayments)
-		r.Post("/recurring-payments", handlers.CreateRecurringPayment)
-		r.Get("/recurring-payments/{id}", handlers.GetRecurringPayment)
-		r.Put("/recurring-payments/{id}", handlers.UpdateRecurringPayment)
-		r.Delete("/recurring-payments/{id}", handlers.DeleteRecurringPayment)
-		r.Get("/recurring-payments/{id}/links", handlers.GetRecurringPaymentLinks)
-		r.Get("/recurring-payments/{id}/occurrences", handlers.GetRecurringPaymentOccurrences)
-		r.Get("/recurring-payments/{id}/match-suggestions", handlers.SuggestTransactionsForRecurringPayment)
-
-		// Dashboard
-		r.Get("/dashboard", handlers.GetDashboard)
+		handlers.APIRoutes(r)
 	})
 
 	// Serve static files (UI)
@@ -141,13 +80,21 @@ func main() {
 	r.Handle("/*", http.FileServer(http.FS(staticFS)))
 
 	// Swagger UI
+	docs.SwaggerInfo.BasePath = cfg.BasePath + "/api/v1"
+	spec := swaggerSpecWithBasePath(swaggerSpec, docs.SwaggerInfo.BasePath)
 	r.Get("/swagger/doc.json", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
-		if _, err := w.Write(swaggerSpec); err != nil {
+		if _, err := w.Write(spec); err != nil {
 			http.Error(w, "failed to serve swagger spec", http.StatusInternalServerError)
 		}
 	})
-	r.Get("/swagger/*", httpSwagger.Handler(httpSwagger.URL("/swagger/doc.json")))
+	r.Get("/swagger/*", httpSwagger.Handler(httpSwagger.URL(cfg.BasePath+"/swagger/doc.json")))
+
+	// Mount everything under BASE_PATH when running behind a gateway.
+	var handler http.Handler = r
+	if cfg.BasePath != "" {
+		handler = mountBasePath(cfg.BasePath, r)
+	}
 
 	// Start server
 	port := os.Getenv("PORT")
@@ -155,9 +102,42 @@ func main() {
 		port = "8080"
 	}
 	addr := fmt.Sprintf(":%s", port)
-	slog.Info("server starting", "address", addr)
-	if err := http.ListenAndServe(addr, r); err != nil {
+	slog.Info("server starting", "address", addr, "base_path", cfg.BasePath)
+	if err := http.ListenAndServe(addr, handler); err != nil {
 		slog.Error("server failed", "error", err)
 		os.Exit(1)
 	}
 }
+
+// swaggerSpecWithBasePath returns the embedded spec with its basePath replaced,
+// falling back to the spec unchanged if it cannot be parsed.
+func swaggerSpecWithBasePath(spec []byte, basePath string) []byte {
+	var doc map[string]any
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		slog.Warn("failed to parse swagger spec", "error", err)
+		return spec
+	}
+	doc["basePath"] = basePath
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return spec
+	}
+	return out
+}
+
+// mountBasePath serves h under prefix, stripping it from the request path so
+// the router and static file server see the same paths as at the root. The UI
+// uses relative URLs, so the bare prefix redirects to its trailing-slash form.
+func mountBasePath(prefix string, h http.Handler) http.Handler {
+	stripped := http.StripPrefix(prefix, h)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == prefix:
+			http.Redirect(w, r, prefix+"/", http.StatusMovedPermanently)
+		case strings.HasPrefix(r.URL.Path, prefix+"/"):
+			stripped.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
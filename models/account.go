@@ -1,21 +1,96 @@
 package models
 
-// Account represents a bank account, cash, or credit card.
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// DefaultCurrency is the currency of accounts created without one.
+const DefaultCurrency = "INR"
+
+// Account represents a bank account, cash, credit card, or loan.
 type Account struct {
 	ID             int       `json:"id"`
 	Name           string    `json:"name"`
-	Type           string    `json:"type"` // bank, cash, credit_card
+	Type           string    `json:"type"`        // bank, cash, credit_card, loan
+	Currency       string    `json:"currency"`    // ISO 4217 code, e.g. INR
+	MinorUnits     int       `json:"minor_units"` // decimal places of Currency; amounts are in its smallest unit
 	OpeningBalance Money     `json:"opening_balance"`
-	Balance        Money     `json:"balance"` // Computed
+	Balance        Money     `json:"balance"`         // Computed: opening + income - expense ± adjustments
+	BalanceType    string    `json:"balance_type"`    // asset or liability, derived from Type
+	DisplayBalance Money     `json:"display_balance"` // Balance as the holder sees it; for liabilities, the amount owed
 	CreatedAt      Timestamp `json:"created_at"`
 	UpdatedAt      Timestamp `json:"updated_at"`
+
+	// ClearedBalance leaves out pending (uncleared) transactions, and
+	// ProjectedBalance, equal to Balance, includes them.
+	ClearedBalance   Money `json:"cleared_balance"`
+	ProjectedBalance Money `json:"projected_balance"`
+}
+
+// BalanceType returns "liability" for account types that represent money owed
+// (credit cards and loans) and "asset" otherwise.
+func BalanceType(accountType string) string {
+	if accountType == "credit_card" || accountType == "loan" {
+		return "liability"
+	}
+	return "asset"
+}
+
+// SetBalanceFields fills BalanceType and DisplayBalance from Type and Balance.
+// Balance stays signed from the asset side so it can be summed into net worth;
+// DisplayBalance flips the sign for liabilities so a positive value is owed.
+func (a *Account) SetBalanceFields() {
+	a.BalanceType = BalanceType(a.Type)
+	a.DisplayBalance = a.Balance
+	if a.BalanceType == "liability" {
+		a.DisplayBalance = -a.Balance
+	}
 }
 
 // AccountInput is used for creating/updating accounts.
+//
+// OpeningBalance is signed from the asset side, like Account.Balance: money
+// held is positive and money owed is negative. A credit card or loan that
+// starts with an outstanding amount therefore has a negative opening balance,
+// and an overdrawn bank account may too. It is in the account's currency, as
+// are the amounts of all its transactions; balances are never converted.
 type AccountInput struct {
 	Name           string `json:"name"`
 	Type           string `json:"type"`
+	Currency       string `json:"currency"` // ISO 4217 code; empty keeps the stored currency, or INR for a new account
 	OpeningBalance Money  `json:"opening_balance"`
+
+	wholeOpening *float64 // opening_balance as sent, for SetCurrency
+}
+
+// UnmarshalJSON decodes a and keeps opening_balance in whole units as sent,
+// for SetCurrency.
+func (a *AccountInput) UnmarshalJSON(data []byte) error {
+	type plain AccountInput
+	if err := json.Unmarshal(data, (*plain)(a)); err != nil {
+		return err
+	}
+	var whole struct {
+		OpeningBalance json.RawMessage `json:"opening_balance"`
+	}
+	if err := json.Unmarshal(data, &whole); err != nil {
+		return err
+	}
+	var err error
+	a.wholeOpening, err = wholeAmount(whole.OpeningBalance)
+	return err
+}
+
+// SetCurrency re-reads OpeningBalance, which JSON decoding keeps to
+// AmountMinorUnits decimal places, in the smallest unit of currency, the
+// account's, so 1000 is 1000 yen in a JPY account and 1000000 fils in a
+// KWD one. An input not decoded from JSON is left as it is.
+func (a *AccountInput) SetCurrency(currency string) {
+	if a.wholeOpening != nil {
+		a.OpeningBalance = FromFloatIn(*a.wholeOpening, currency)
+	}
 }
 
 func (a *AccountInput) Validate() string {
@@ -23,9 +98,39 @@ func (a *AccountInput) Validate() string {
 		return "name is required"
 	}
 	switch a.Type {
-	case "bank", "cash", "credit_card":
+	case "bank", "cash", "credit_card", "loan":
 	default:
-		return "type must be one of: bank, cash, credit_card"
+		return "type must be one of: bank, cash, credit_card, loan"
+	}
+	a.Currency = strings.ToUpper(strings.TrimSpace(a.Currency))
+	if a.Currency != "" && !IsCurrencyCode(a.Currency) {
+		return "currency must be a three-letter ISO 4217 code"
 	}
 	return ""
 }
+
+// Warnings returns notes about a valid input that is accepted but may be a
+// mistake: a negative opening balance on an asset account is an overdraft,
+// which is legitimate for a bank account but more often a credit card or loan
+// entered with the wrong type.
+func (a *AccountInput) Warnings() []string {
+	var warnings []string
+	if a.OpeningBalance < 0 && BalanceType(a.Type) == "asset" {
+		warnings = append(warnings, fmt.Sprintf(
+			"opening_balance is negative for a %s account; this records an overdraft, use type credit_card or loan for money owed on a card or loan", a.Type))
+	}
+	return warnings
+}
+
+// IsCurrencyCode reports whether code is three uppercase ASCII letters.
+func IsCurrencyCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for i := 0; i < len(code); i++ {
+		if code[i] < 'A' || code[i] > 'Z' {
+			return false
+		}
+	}
+	return true
+}
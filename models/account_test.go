@@ -0,0 +1,87 @@
+package models
+
+import "testing"
+
+func TestAccount_SetBalanceFields(t *testing.T) {
+	tests := []struct {
+		name        string
+		accountType string
+		balance     Money
+		wantType    string
+		wantDisplay Money
+	}{
+		{"bank in credit", "bank", 5000, "asset", 5000},
+		{"cash overdrawn", "cash", -100, "asset", -100},
+		{"credit card with spend", "credit_card", -2500, "liability", 2500},
+		{"credit card overpaid", "credit_card", 300, "liability", -300},
+		{"loan outstanding", "loan", -100000, "liability", 100000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := Account{Type: tt.accountType, Balance: tt.balance}
+			a.SetBalanceFields()
+			if a.BalanceType != tt.wantType {
+				t.Errorf("BalanceType = %q, want %q", a.BalanceType, tt.wantType)
+			}
+			if a.DisplayBalance != tt.wantDisplay {
+				t.Errorf("DisplayBalance = %d, want %d", a.DisplayBalance, tt.wantDisplay)
+			}
+		})
+	}
+}
+
+func TestAccountInput_ValidateCurrency(t *testing.T) {
+	tests := []struct {
+		currency string
+		want     string // normalized currency, or "" when invalid
+		valid    bool
+	}{
+		{"", "", true},
+		{"INR", "INR", true},
+		{" usd ", "USD", true},
+		{"US", "", false},
+		{"US1", "", false},
+		{"RUPEE", "", false},
+		{"JPY", "JPY", true},
+		{"kwd", "KWD", true},
+	}
+
+	for _, tt := range tests {
+		a := AccountInput{Name: "Current", Type: "bank", Currency: tt.currency}
+		msg := a.Validate()
+		if (msg == "") != tt.valid {
+			t.Errorf("Validate(%q) = %q, want valid=%v", tt.currency, msg, tt.valid)
+			continue
+		}
+		if tt.valid && a.Currency != tt.want {
+			t.Errorf("Validate(%q) normalized currency to %q, want %q", tt.currency, a.Currency, tt.want)
+		}
+	}
+}
+
+func TestAccountInput_Warnings(t *testing.T) {
+	tests := []struct {
+		accountType string
+		opening     Money
+		warn        bool
+	}{
+		{"bank", 1000, false},
+		{"bank", -1000, true},
+		{"cash", -1, true},
+		{"credit_card", -50000, false},
+		{"loan", -500000, false},
+		{"bank", 0, false},
+	}
+
+	for _, tt := range tests {
+		a := AccountInput{Name: "Account", Type: tt.accountType, OpeningBalance: tt.opening}
+		if msg := a.Validate(); msg != "" {
+			t.Errorf("Validate(%s, %d) = %q, want valid", tt.accountType, tt.opening, msg)
+			continue
+		}
+		if got := len(a.Warnings()) > 0; got != tt.warn {
+			t.Errorf("Warnings(%s, %d) = %v, want warning=%v", tt.accountType, tt.opening, a.Warnings(), tt.warn)
+		}
+	}
+}
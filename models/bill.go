@@ -2,12 +2,14 @@ package models
 
 import (
 	"fmt"
+	"strings"
 )
 
 // Bill represents a payable bill from a vendor.
 type Bill struct {
 	ID         int       `json:"id"`
 	ContactID  *int      `json:"contact_id"`
+	CategoryID *int      `json:"category_id"`
 	BillNumber string    `json:"bill_number"`
 	IssueDate  Date      `json:"issue_date"`
 	DueDate    Date      `json:"due_date"`
@@ -18,15 +20,19 @@ type Bill struct {
 	CreatedAt  Timestamp `json:"created_at"`
 	UpdatedAt  Timestamp `json:"updated_at"`
 	// Computed fields
-	ContactName *string    `json:"contact_name,omitempty"`
-	Allocated   Money      `json:"allocated"`   // sum of linked transaction_documents amounts
-	Unallocated Money      `json:"unallocated"` // amount - allocated
-	Items       []BillItem `json:"items"`
+	ContactName  *string    `json:"contact_name,omitempty"`
+	CategoryName *string    `json:"category_name,omitempty"`
+	Allocated    Money      `json:"allocated"`     // sum of linked transaction_documents amounts
+	Unallocated  Money      `json:"unallocated"`   // amount - allocated
+	AllocatedPct float64    `json:"allocated_pct"` // allocated / amount * 100, 0 for a zero amount
+	DaysToDue    *int       `json:"days_to_due"`   // days from today to due_date, negative when overdue, null without a due date
+	Items        []BillItem `json:"items"`
 }
 
 // BillInput is used for creating/updating bills.
 type BillInput struct {
 	ContactID  *int            `json:"contact_id"`
+	CategoryID *int            `json:"category_id"` // an expense category; omitting it on update clears it
 	BillNumber string          `json:"bill_number"`
 	IssueDate  *string         `json:"issue_date"`
 	DueDate    *string         `json:"due_date"`
@@ -49,6 +55,11 @@ func (b *BillInput) Validate() string {
 	if b.Status == "" {
 		b.Status = "draft"
 	}
+	b.BillNumber = strings.TrimSpace(b.BillNumber)
+	trimToNil(&b.IssueDate)
+	trimToNil(&b.DueDate)
+	trimToNil(&b.FileURL)
+	trimToNil(&b.Notes)
 	if err := NormalizeDate(b.IssueDate); err != nil {
 		return "issue_date: " + err.Error()
 	}
@@ -95,6 +106,7 @@ func (b *BillItemInput) Validate() string {
 	if b.UnitPrice < 0 {
 		return "unit_price must be non-negative"
 	}
+	trimToNil(&b.Unit)
 	if b.Amount <= 0 {
 		return "amount must be positive"
 	}
@@ -0,0 +1,36 @@
+package models
+
+import "strings"
+
+// Category is an income or expense heading, such as rent, ingredients,
+// salaries, or utilities, that transactions and bills are tagged with.
+type Category struct {
+	ID           int       `json:"id"`
+	Name         string    `json:"name"`
+	Type         string    `json:"type"`         // expense or income
+	Transactions int       `json:"transactions"` // Computed: number of transactions tagged with the category
+	Bills        int       `json:"bills"`        // Computed: number of bills tagged with the category
+	CreatedAt    Timestamp `json:"created_at"`
+	UpdatedAt    Timestamp `json:"updated_at"`
+}
+
+// CategoryInput is used for creating/updating categories.
+type CategoryInput struct {
+	Name string `json:"name"`
+	Type string `json:"type"` // expense (the default) or income
+}
+
+func (c *CategoryInput) Validate() string {
+	c.Name = strings.TrimSpace(c.Name)
+	if c.Name == "" {
+		return "name is required"
+	}
+	switch c.Type {
+	case "":
+		c.Type = "expense"
+	case "expense", "income":
+	default:
+		return "type must be one of: expense, income"
+	}
+	return ""
+}
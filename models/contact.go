@@ -7,6 +7,9 @@ type Contact struct {
 	Type            string    `json:"type"` // vendor, customer
 	Email           *string   `json:"email"`
 	Phone           *string   `json:"phone"`
+	GSTIN           *string   `json:"gstin"`            // set for GST-registered businesses
+	PAN             *string   `json:"pan"`              // characters 3-12 of GSTIN when both are set
+	CreditLimit     *Money    `json:"credit_limit"`     // most a customer may owe on open invoices; null for no limit
 	TotalAmount     Money     `json:"total_amount"`     // Computed: Sum of bills/invoices
 	AllocatedAmount Money     `json:"allocated_amount"` // Computed: Sum of payments
 	Balance         Money     `json:"balance"`          // Computed: Total - Allocated
@@ -20,6 +23,12 @@ type ContactInput struct {
 	Type  string  `json:"type"`
 	Email *string `json:"email"`
 	Phone *string `json:"phone"`
+	GSTIN *string `json:"gstin"`
+	PAN   *string `json:"pan"`
+	// CreditLimit caps the outstanding balance of a customer: creating an
+	// invoice that would take the balance above it is warned about, or
+	// refused when CREDIT_LIMIT_BLOCK is set. Null means no limit.
+	CreditLimit *Money `json:"credit_limit"`
 }
 
 func (c *ContactInput) Validate() string {
@@ -31,5 +40,25 @@ func (c *ContactInput) Validate() string {
 	default:
 		return "type must be one of: vendor, customer"
 	}
+	if c.CreditLimit != nil && *c.CreditLimit < 0 {
+		return "credit_limit must not be negative"
+	}
+	trimToNil(&c.Email)
+	trimToNil(&c.Phone)
+	NormalizeGSTIN(&c.GSTIN)
+	if c.GSTIN != nil {
+		if msg := GSTINError(*c.GSTIN); msg != "" {
+			return "gstin: " + msg
+		}
+	}
+	NormalizePAN(&c.PAN)
+	if c.PAN != nil {
+		if msg := PANError(*c.PAN); msg != "" {
+			return "pan: " + msg
+		}
+		if c.GSTIN != nil && (*c.GSTIN)[2:12] != *c.PAN {
+			return "pan: does not match the PAN in gstin"
+		}
+	}
 	return ""
 }
@@ -0,0 +1,135 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultMinorUnits is the number of decimal places of currencies missing from
+// currencyMinorUnits, which covers INR and most other currencies.
+const DefaultMinorUnits = 2
+
+// currencyMinorUnits holds the ISO 4217 minor-unit exponent of the currencies
+// that do not use two decimal places.
+var currencyMinorUnits = map[string]int{
+	"BHD": 3, "CLP": 0, "IQD": 3, "ISK": 0, "JOD": 3, "JPY": 0,
+	"KRW": 0, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3, "VND": 0,
+}
+
+// amountMinorUnits is the number of decimal places the deployment keeps
+// amounts to, set by SetMinorUnits: 2 stores INR in paise, 0 in whole rupees.
+var amountMinorUnits = DefaultMinorUnits
+
+// SetMinorUnits sets the number of decimal places amounts are kept to, which
+// Money's JSON decoding, ToFloat, and the formatting of INR and other
+// two-decimal currencies follow. It must be called at startup, before any
+// amount is stored: changing it reinterprets every stored amount.
+func SetMinorUnits(n int) {
+	amountMinorUnits = n
+}
+
+// AmountMinorUnits returns the number of decimal places amounts are kept to.
+func AmountMinorUnits() int {
+	return amountMinorUnits
+}
+
+// MinorUnits returns the number of decimal places of a currency, e.g. 2 for
+// INR and 0 for JPY. Unknown and empty codes get AmountMinorUnits, which is
+// DefaultMinorUnits unless SetMinorUnits changed it.
+func MinorUnits(currency string) int {
+	if n, ok := currencyMinorUnits[strings.ToUpper(currency)]; ok {
+		return n
+	}
+	return amountMinorUnits
+}
+
+// FixedMinorUnits returns the decimal places of a currency listed in
+// currencyMinorUnits, such as 0 for JPY, and false for those that follow
+// AmountMinorUnits.
+func FixedMinorUnits(currency string) (int, bool) {
+	n, ok := currencyMinorUnits[strings.ToUpper(currency)]
+	return n, ok
+}
+
+// FixedMinorUnitsTable returns a copy of the currencies with their own number
+// of decimal places, keyed by ISO 4217 code.
+func FixedMinorUnitsTable() map[string]int {
+	t := make(map[string]int, len(currencyMinorUnits))
+	for c, n := range currencyMinorUnits {
+		t[c] = n
+	}
+	return t
+}
+
+// UnitScale returns 10^units, the number of smallest units in one whole unit
+// of a currency with that many decimal places.
+func UnitScale(units int) int64 {
+	scale := int64(1)
+	for i := 0; i < units; i++ {
+		scale *= 10
+	}
+	return scale
+}
+
+// WholeUnits returns n whole rupees as Money, e.g. 500 is 50000 when amounts
+// are kept in paise.
+func WholeUnits(n int64) Money {
+	return Money(n * UnitScale(amountMinorUnits))
+}
+
+// InCurrency converts m, kept to AmountMinorUnits decimal places like a
+// configured threshold or tolerance, to the smallest unit of currency,
+// rounding half away from zero when the currency has fewer decimal places
+// (e.g. 10000 paise is 100 in JPY and 100000 in KWD).
+func (m Money) InCurrency(currency string) Money {
+	from, to := amountMinorUnits, MinorUnits(currency)
+	if to >= from {
+		return m * Money(UnitScale(to-from))
+	}
+	scale := Money(UnitScale(from - to))
+	if m < 0 {
+		return -((-m + scale/2) / scale)
+	}
+	return (m + scale/2) / scale
+}
+
+// Format renders m, held in the currency's smallest unit, as a decimal amount
+// with that currency's decimal places (e.g. 123456 is "1234.56" in INR and
+// 1234 is "1234" in JPY). An empty currency is formatted as INR.
+func (m Money) Format(currency string) string {
+	units := MinorUnits(currency)
+	sign, v := "", int64(m)
+	if v < 0 {
+		sign, v = "-", -v
+	}
+	if units == 0 {
+		return fmt.Sprintf("%s%d", sign, v)
+	}
+	scale := UnitScale(units)
+	return fmt.Sprintf("%s%d.%0*d", sign, v/scale, units, v%scale)
+}
+
+// FormatIndian renders m like Format but groups the whole part the Indian
+// way, in thousands and then lakhs and crores (e.g. 1234567890 is
+// "1,23,45,678.90" in INR).
+func (m Money) FormatIndian(currency string) string {
+	s := m.Format(currency)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	whole, frac, hasFrac := strings.Cut(s, ".")
+	if len(whole) > 3 {
+		head, tail := whole[:len(whole)-3], whole[len(whole)-3:]
+		var groups []string
+		for len(head) > 2 {
+			groups = append([]string{head[len(head)-2:]}, groups...)
+			head = head[:len(head)-2]
+		}
+		whole = strings.Join(append(append([]string{head}, groups...), tail), ",")
+	}
+	if hasFrac {
+		return sign + whole + "." + frac
+	}
+	return sign + whole
+}
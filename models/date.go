@@ -94,6 +94,19 @@ func (d Date) String() string {
 	return d.Time.Format("2006-01-02")
 }
 
+// DaysUntil returns the number of calendar days from the date of today, in
+// today's location, to d: negative once d has passed, nil when d is absent.
+func (d Date) DaysUntil(today time.Time) *int {
+	if d.IsZero() {
+		return nil
+	}
+	y, m, day := today.Date()
+	from := time.Date(y, m, day, 0, 0, 0, 0, time.UTC)
+	to := time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.UTC)
+	days := int(to.Sub(from).Hours() / 24)
+	return &days
+}
+
 // NormalizeDate converts a date string from DD-MM-YYYY or DD/MM/YYYY to YYYY-MM-DD.
 // Strings already in YYYY-MM-DD format are returned unchanged.
 // A nil pointer is returned unchanged. An error is returned for unrecognised formats.
@@ -201,6 +201,32 @@ func TestDate_String(t *testing.T) {
 	}
 }
 
+func TestDate_DaysUntil(t *testing.T) {
+	ist := time.FixedZone("IST", 5*3600+1800)
+	due := Date{Time: time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)}
+	tests := []struct {
+		name  string
+		today time.Time
+		want  int
+	}{
+		{"due in future", time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC), 9},
+		{"due today", time.Date(2024, 3, 10, 23, 59, 0, 0, time.UTC), 0},
+		{"overdue", time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC), -5},
+		{"local date is already the next day", time.Date(2024, 3, 11, 0, 30, 0, 0, ist), -1},
+		{"across a month end", time.Date(2024, 2, 28, 12, 0, 0, 0, time.UTC), 11},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := due.DaysUntil(tt.today); got == nil || *got != tt.want {
+				t.Errorf("DaysUntil() = %v, want %d", got, tt.want)
+			}
+		})
+	}
+	if got := (Date{}).DaysUntil(time.Now()); got != nil {
+		t.Errorf("DaysUntil() of an absent date = %d, want nil", *got)
+	}
+}
+
 func TestDate_ScanTimeTime_FromGateway(t *testing.T) {
 	// Simulate the Nexus gateway returning time.Time for a DATE column
 	// (e.g. "2026-02-26 00:00:00 +0000 UTC")
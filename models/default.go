@@ -0,0 +1,127 @@
+package models
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// defaultAccountPrefix starts the keys of per-type default accounts, e.g.
+// default_account.expense.
+const defaultAccountPrefix = "default_account."
+
+// PayoutClearingAccountKey is the default holding the account POST
+// /payouts/{id}/post books platform sales and deductions through when the
+// request names none.
+const PayoutClearingAccountKey = "payout.clearing_account"
+
+// payoutCategoryPrefix starts the keys of the categories POST
+// /payouts/{id}/post tags each entry with, e.g. payout.category.commission.
+const payoutCategoryPrefix = "payout.category."
+
+// payoutEntryTypes are the entries a posted payout books on the clearing
+// account, with the transaction type of each.
+var payoutEntryTypes = map[string]string{
+	"gross": "income", "discount": "expense", "commission": "expense",
+	"taxes": "expense", "ads": "expense", "variance": "expense",
+}
+
+// fxRatePrefix starts the keys of stored exchange rates, e.g. fx_rate.USD,
+// whose value is how many units of the books currency one unit of that
+// currency is worth.
+const fxRatePrefix = "fx_rate."
+
+// Default is a user-configured default value.
+type Default struct {
+	Key       string    `json:"key"`
+	Value     string    `json:"value"`
+	UpdatedAt Timestamp `json:"updated_at"`
+}
+
+// DefaultInput is used for setting a default.
+type DefaultInput struct {
+	Value string `json:"value"`
+}
+
+// DefaultAccountKey returns the key holding the default account for
+// transactions of txnType.
+func DefaultAccountKey(txnType string) string {
+	return defaultAccountPrefix + txnType
+}
+
+// DefaultAccountType returns the transaction type whose default account key
+// is key, or false when key is not a default account key.
+func DefaultAccountType(key string) (string, bool) {
+	txnType, ok := strings.CutPrefix(key, defaultAccountPrefix)
+	switch txnType {
+	case "income", "expense", "transfer", "adjustment":
+		return txnType, ok
+	}
+	return "", false
+}
+
+// PayoutCategoryKey returns the key holding the category of the payout entry
+// entry, such as commission.
+func PayoutCategoryKey(entry string) string {
+	return payoutCategoryPrefix + entry
+}
+
+// PayoutCategoryEntry returns the payout entry whose category key is key and
+// the transaction type it is booked as, or false when key is not a payout
+// category key.
+func PayoutCategoryEntry(key string) (string, string, bool) {
+	entry, ok := strings.CutPrefix(key, payoutCategoryPrefix)
+	txnType, known := payoutEntryTypes[entry]
+	return entry, txnType, ok && known
+}
+
+// FXRateKey returns the key holding the stored exchange rate of currency.
+func FXRateKey(currency string) string {
+	return fxRatePrefix + currency
+}
+
+// FXRateCurrency returns the currency whose exchange rate key is key, or false
+// when key is not an exchange rate key.
+func FXRateCurrency(key string) (string, bool) {
+	currency, ok := strings.CutPrefix(key, fxRatePrefix)
+	if !ok || !IsCurrencyCode(currency) {
+		return "", false
+	}
+	return currency, true
+}
+
+// Validate checks the value for key and returns the account id it names, or 0
+// for keys that do not hold an account. The category a payout category key
+// names is left for the caller to look up.
+func (d *DefaultInput) Validate(key string) (int, string) {
+	d.Value = strings.TrimSpace(d.Value)
+	if key == BusinessGSTINKey {
+		d.Value = strings.ToUpper(d.Value)
+		if msg := GSTINError(d.Value); msg != "" {
+			return 0, "value: GSTIN " + msg
+		}
+		return 0, ""
+	}
+	if _, ok := FXRateCurrency(key); ok {
+		if rate, err := strconv.ParseFloat(d.Value, 64); err != nil || !(rate > 0) || math.IsInf(rate, 0) {
+			return 0, "value must be a positive exchange rate"
+		}
+		return 0, ""
+	}
+	if _, _, ok := PayoutCategoryEntry(key); ok {
+		if id, err := strconv.Atoi(d.Value); err != nil || id <= 0 {
+			return 0, "value must be a category id"
+		}
+		return 0, ""
+	}
+	if _, ok := DefaultAccountType(key); !ok && key != PayoutClearingAccountKey {
+		return 0, "unknown default: key must be default_account.<income|expense|transfer|adjustment>, " +
+			PayoutClearingAccountKey + ", " + payoutCategoryPrefix + "<gross|discount|commission|taxes|ads|variance>, " +
+			BusinessGSTINKey + ", or " + fxRatePrefix + "<CURRENCY>"
+	}
+	id, err := strconv.Atoi(d.Value)
+	if err != nil || id <= 0 {
+		return 0, "value must be an account id"
+	}
+	return id, ""
+}
@@ -0,0 +1,130 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BusinessGSTINKey is the default holding the GSTIN the business itself files
+// GST returns under.
+const BusinessGSTINKey = "business.gstin"
+
+// gstinCharset orders the characters of a GSTIN by the value they carry in
+// its check character calculation.
+const gstinCharset = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// gstStates maps GST state codes to the state or union territory they stand
+// for. 25 and 28 belong to states since merged or split but remain on older
+// registrations; 97 covers other territories and 99 the Centre's jurisdiction.
+var gstStates = map[string]string{
+	"01": "Jammu and Kashmir", "02": "Himachal Pradesh", "03": "Punjab", "04": "Chandigarh",
+	"05": "Uttarakhand", "06": "Haryana", "07": "Delhi", "08": "Rajasthan", "09": "Uttar Pradesh",
+	"10": "Bihar", "11": "Sikkim", "12": "Arunachal Pradesh", "13": "Nagaland", "14": "Manipur",
+	"15": "Mizoram", "16": "Tripura", "17": "Meghalaya", "18": "Assam", "19": "West Bengal",
+	"20": "Jharkhand", "21": "Odisha", "22": "Chhattisgarh", "23": "Madhya Pradesh", "24": "Gujarat",
+	"25": "Daman and Diu", "26": "Dadra and Nagar Haveli and Daman and Diu", "27": "Maharashtra",
+	"28": "Andhra Pradesh (before reorganisation)", "29": "Karnataka", "30": "Goa", "31": "Lakshadweep",
+	"32": "Kerala", "33": "Tamil Nadu", "34": "Puducherry", "35": "Andaman and Nicobar Islands",
+	"36": "Telangana", "37": "Andhra Pradesh", "38": "Ladakh", "97": "Other Territory",
+	"99": "Centre Jurisdiction",
+}
+
+// panHolderTypes are the letters the fourth character of a PAN takes, naming
+// the kind of holder (P for an individual, C for a company, and so on).
+const panHolderTypes = "ABCFGHJLPT"
+
+// NormalizeGSTIN trims and uppercases a GSTIN in place, setting it to nil when
+// blank.
+func NormalizeGSTIN(p **string) {
+	trimToNil(p)
+	if *p != nil {
+		v := strings.ToUpper(**p)
+		*p = &v
+	}
+}
+
+// NormalizePAN trims and uppercases a PAN in place, setting it to nil when
+// blank.
+func NormalizePAN(p **string) {
+	NormalizeGSTIN(p)
+}
+
+// ValidGSTIN reports whether s is a well-formed GSTIN with a correct check
+// character.
+func ValidGSTIN(s string) bool {
+	return GSTINError(s) == ""
+}
+
+// GSTINError explains what is wrong with the GSTIN s, or returns "" when it is
+// valid. A GSTIN is a known two-digit state code, the holder's PAN, an entity
+// number, the letter Z, and a check character computed over the first 14.
+func GSTINError(s string) string {
+	if len(s) != 15 {
+		return "must be 15 characters"
+	}
+	if !ValidStateCode(s[:2]) {
+		return fmt.Sprintf("unknown state code %q", s[:2])
+	}
+	if msg := PANError(s[2:12]); msg != "" {
+		return "characters 3-12 must be the holder's PAN: PAN " + msg
+	}
+	if strings.IndexByte(gstinCharset, s[12]) < 0 || s[12] == '0' {
+		return "character 13 must be an entity number 1-9 or A-Z"
+	}
+	if s[13] != 'Z' {
+		return "character 14 must be Z"
+	}
+	if want := gstinCheckChar(s[:14]); s[14] != want {
+		return fmt.Sprintf("check character is %c, expected %c", s[14], want)
+	}
+	return ""
+}
+
+// gstinCheckChar computes the check character of the first 14 characters of
+// a GSTIN: each character's value is weighted alternately by 1 and 2, the
+// base-36 digits of each product are summed, and the check character brings
+// the sum to a multiple of 36.
+func gstinCheckChar(s string) byte {
+	sum := 0
+	for i := 0; i < len(s); i++ {
+		product := strings.IndexByte(gstinCharset, s[i]) * (1 + i%2)
+		sum += product/36 + product%36
+	}
+	return gstinCharset[(36-sum%36)%36]
+}
+
+// ValidPAN reports whether s is a well-formed PAN.
+func ValidPAN(s string) bool {
+	return PANError(s) == ""
+}
+
+// PANError explains what is wrong with the PAN s, or returns "" when it is
+// valid: five letters (the fourth naming the holder type), four digits, and a
+// letter.
+func PANError(s string) string {
+	if len(s) != 10 {
+		return "must be 10 characters"
+	}
+	for i := 0; i < 10; i++ {
+		c := s[i]
+		letter := c >= 'A' && c <= 'Z'
+		digit := c >= '0' && c <= '9'
+		if i >= 5 && i < 9 {
+			if !digit {
+				return "characters 6-9 must be digits"
+			}
+		} else if !letter {
+			return "characters 1-5 and 10 must be letters"
+		}
+	}
+	if strings.IndexByte(panHolderTypes, s[3]) < 0 {
+		return fmt.Sprintf("character 4 must be a holder type (one of %s)", panHolderTypes)
+	}
+	return ""
+}
+
+// ValidStateCode reports whether code is a known two-digit GST state code.
+func ValidStateCode(code string) bool {
+	_, ok := gstStates[code]
+	return ok
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGSTINError(t *testing.T) {
+	tests := []struct {
+		name  string
+		gstin string
+		want  string // substring of the error, "" for a valid GSTIN
+	}{
+		{"valid", "27AAPFU0939F1ZV", ""},
+		{"valid other state", "29AAGCR4375J1ZU", ""},
+		{"short", "27AAPFU0939F1Z", "15 characters"},
+		{"unknown state", "42AAPFU0939F1ZV", "state code"},
+		{"bad PAN digits", "27AAPFUX939F1ZV", "PAN"},
+		{"bad holder type", "27AAPXU0939F1ZV", "holder type"},
+		{"missing Z", "27AAPFU0939F1YV", "must be Z"},
+		{"wrong check character", "27AAPFU0939F1ZW", "expected V"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GSTINError(tt.gstin)
+			if tt.want == "" {
+				if got != "" {
+					t.Errorf("GSTINError(%q) = %q, want valid", tt.gstin, got)
+				}
+				return
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("GSTINError(%q) = %q, want it to mention %q", tt.gstin, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContactInputValidateTaxIDs(t *testing.T) {
+	tests := []struct {
+		name      string
+		gstin     *string
+		pan       *string
+		wantError string
+	}{
+		{"both blank", strPtr(" "), strPtr(""), ""},
+		{"lowercase normalized", strPtr("27aapfu0939f1zv"), strPtr("aapfu0939f"), ""},
+		{"pan only", nil, strPtr("ABCPE1234F"), ""},
+		{"invalid gstin", strPtr("27AAPFU0939F1ZW"), nil, "gstin: check character"},
+		{"invalid pan", nil, strPtr("ABCDE1234"), "pan: must be 10 characters"},
+		{"mismatched pan", strPtr("27AAPFU0939F1ZV"), strPtr("ABCPE1234F"), "pan: does not match"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := ContactInput{Name: "Acme", Type: "customer", GSTIN: tt.gstin, PAN: tt.pan}
+			got := c.Validate()
+			if tt.wantError == "" {
+				if got != "" {
+					t.Fatalf("Validate() = %q, want no error", got)
+				}
+				if c.GSTIN != nil && *c.GSTIN != strings.ToUpper(*c.GSTIN) {
+					t.Errorf("gstin = %q, want it uppercased", *c.GSTIN)
+				}
+				if c.PAN != nil && *c.PAN != strings.ToUpper(*c.PAN) {
+					t.Errorf("pan = %q, want it uppercased", *c.PAN)
+				}
+				return
+			}
+			if !strings.HasPrefix(got, tt.wantError) {
+				t.Errorf("Validate() = %q, want prefix %q", got, tt.wantError)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package models
+
+// StatementLine is one entry parsed from a bank statement file, ready to be
+// imported as a transaction on the statement's account.
+type StatementLine struct {
+	Row         int    // 1-based position in the file, for error reporting
+	ExternalID  string // bank-assigned id (e.g. OFX FITID) used to skip re-imports
+	Date        string // YYYY-MM-DD
+	Amount      Money  // signed: positive is a credit (income), negative a debit (expense)
+	Description string
+	Reference   string
+}
+
+// ImportError describes a statement entry that could not be imported.
+type ImportError struct {
+	Row        int    `json:"row"`
+	ExternalID string `json:"external_id,omitempty"`
+	Error      string `json:"error"`
+}
+
+// ContactLine is one row parsed from a contact import file.
+type ContactLine struct {
+	Row   int // 1-based line in the file, for error reporting
+	Input ContactInput
+}
+
+// PayoutLine is one payout parsed from a platform settlement report.
+type PayoutLine struct {
+	Row   int // 1-based line in the file, for error reporting
+	Input PayoutInput
+}
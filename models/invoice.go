@@ -2,6 +2,8 @@ package models
 
 import (
 	"fmt"
+	"math"
+	"strings"
 )
 
 // Invoice represents a receivable invoice to a customer.
@@ -11,17 +13,34 @@ type Invoice struct {
 	InvoiceNumber string    `json:"invoice_number"`
 	IssueDate     Date      `json:"issue_date"`
 	DueDate       Date      `json:"due_date"`
-	Amount        Money     `json:"amount"`
+	Amount        Money     `json:"amount"` // the total of the items when the invoice has any
 	Status        string    `json:"status"`
 	FileURL       *string   `json:"file_url"`
 	Notes         *string   `json:"notes"`
+	SentAt        Timestamp `json:"sent_at"`         // last time the invoice was sent to the customer, null if never
+	TaxRate       *float64  `json:"tax_rate"`        // GST rate in percent, null when not recorded
+	TaxAmount     Money     `json:"tax_amount"`      // GST included in Amount
+	PlaceOfSupply *string   `json:"place_of_supply"` // two-digit GST state code
 	CreatedAt     Timestamp `json:"created_at"`
 	UpdatedAt     Timestamp `json:"updated_at"`
 	// Computed fields
-	ContactName *string       `json:"contact_name,omitempty"`
-	Allocated   Money         `json:"allocated"`
-	Unallocated Money         `json:"unallocated"`
-	Items       []InvoiceItem `json:"items"`
+	ContactName  *string       `json:"contact_name,omitempty"`
+	Allocated    Money         `json:"allocated"`
+	Unallocated  Money         `json:"unallocated"`
+	AllocatedPct float64       `json:"allocated_pct"` // allocated / amount * 100, 0 for a zero amount
+	DaysToDue    *int          `json:"days_to_due"`   // days from today to due_date, negative when overdue, null without a due date
+	Items        []InvoiceItem `json:"items"`
+}
+
+// ItemTaxRates reports whether any of the invoice's items has its own tax
+// rate, in which case the items' rates, not the invoice's, apply.
+func (i Invoice) ItemTaxRates() bool {
+	for _, item := range i.Items {
+		if item.TaxRate != nil {
+			return true
+		}
+	}
+	return false
 }
 
 // InvoiceInput is used for creating/updating invoices.
@@ -34,13 +53,23 @@ type InvoiceInput struct {
 	Status        string             `json:"status"`
 	FileURL       *string            `json:"file_url"`
 	Notes         *string            `json:"notes"`
-	Items         []InvoiceItemInput `json:"items"`
+	Items         []InvoiceItemInput `json:"items"` // with any, Amount and TaxAmount come from them; see ApplyItems
+	// TaxRate is the GST rate in percent and TaxAmount the GST included in
+	// Amount. With a rate and no amount, TaxAmount is worked out from Amount.
+	TaxRate       *float64 `json:"tax_rate"`
+	TaxAmount     Money    `json:"tax_amount"`
+	PlaceOfSupply *string  `json:"place_of_supply"`
 }
 
 func (i *InvoiceInput) Validate() string {
 	if i.Amount < 0 {
 		return "amount must be non-negative"
 	}
+	for idx := range i.Items {
+		if msg := i.Items[idx].Validate(); msg != "" {
+			return fmt.Sprintf("items[%d]: %s", idx, msg)
+		}
+	}
 	switch i.Status {
 	case "", "draft", "partial", "sent", "paid", "received", "overdue", "cancelled":
 	default:
@@ -49,18 +78,84 @@ func (i *InvoiceInput) Validate() string {
 	if i.Status == "" {
 		i.Status = "draft"
 	}
+	i.InvoiceNumber = strings.TrimSpace(i.InvoiceNumber)
+	trimToNil(&i.IssueDate)
+	trimToNil(&i.DueDate)
+	trimToNil(&i.FileURL)
+	trimToNil(&i.Notes)
 	if err := NormalizeDate(i.IssueDate); err != nil {
 		return "issue_date: " + err.Error()
 	}
 	if err := NormalizeDate(i.DueDate); err != nil {
 		return "due_date: " + err.Error()
 	}
+	if i.TaxRate != nil && !validTaxRate(*i.TaxRate) {
+		return "tax_rate must be between 0 and 100"
+	}
+	if len(i.Items) > 0 {
+		i.ApplyItems()
+	} else if i.TaxRate != nil && i.TaxAmount == 0 {
+		i.TaxAmount = IncludedTax(i.Amount, *i.TaxRate)
+	}
+	if i.TaxAmount < 0 {
+		return "tax_amount must be non-negative"
+	}
+	if i.TaxAmount > i.Amount {
+		return "tax_amount must not exceed amount"
+	}
+	trimToNil(&i.PlaceOfSupply)
+	if i.PlaceOfSupply != nil && !ValidStateCode(*i.PlaceOfSupply) {
+		return "place_of_supply must be a known two-digit GST state code"
+	}
+	return ""
+}
+
+// ApplyItems sets Amount to the total of the items, whose amounts include
+// their GST. When any item has its own tax_rate, items without one take the
+// invoice's, TaxAmount is the sum of the GST of each item, and TaxRate is the
+// items' common rate, or nil when their rates differ. Otherwise the invoice's
+// TaxRate applies to the whole amount, as for an invoice without items.
+func (i *InvoiceInput) ApplyItems() {
+	var total Money
+	itemRates := false
+	for _, item := range i.Items {
+		total += item.Amount
+		itemRates = itemRates || item.TaxRate != nil
+	}
+	i.Amount = total
+	if !itemRates {
+		if i.TaxRate != nil {
+			i.TaxAmount = IncludedTax(total, *i.TaxRate)
+		}
+		return
+	}
+
+	var tax Money
 	for idx := range i.Items {
-		if msg := i.Items[idx].Validate(); msg != "" {
-			return fmt.Sprintf("items[%d]: %s", idx, msg)
+		item := &i.Items[idx]
+		if item.TaxRate == nil && i.TaxRate != nil {
+			rate := *i.TaxRate
+			item.TaxRate = &rate
 		}
+		tax += item.Tax()
 	}
-	return ""
+	i.TaxAmount = tax
+	i.TaxRate = i.Items[0].TaxRate
+	for _, item := range i.Items[1:] {
+		if item.TaxRate == nil || i.TaxRate == nil || *item.TaxRate != *i.TaxRate {
+			i.TaxRate = nil
+			break
+		}
+	}
+}
+
+// IncludedTax is the GST included in amount at rate percent.
+func IncludedTax(amount Money, rate float64) Money {
+	return Money(math.Round(float64(amount) * rate / (100 + rate)))
+}
+
+func validTaxRate(rate float64) bool {
+	return !math.IsNaN(rate) && rate >= 0 && rate <= 100
 }
 
 // InvoiceItem represents a line item within an invoice.
@@ -71,9 +166,13 @@ type InvoiceItem struct {
 	Quantity    float64   `json:"quantity"`
 	Unit        *string   `json:"unit"`
 	UnitPrice   Money     `json:"unit_price"`
-	Amount      Money     `json:"amount"`
+	Amount      Money     `json:"amount"`   // including GST
+	TaxRate     *float64  `json:"tax_rate"` // GST rate in percent; null when the invoice's tax_rate covers the item
+	HSNCode     *string   `json:"hsn_code"` // HSN code, or SAC for a service
 	CreatedAt   Timestamp `json:"created_at"`
 	UpdatedAt   Timestamp `json:"updated_at"`
+	// Computed fields
+	TaxAmount Money `json:"tax_amount"` // GST included in Amount at TaxRate, 0 without one
 }
 
 // InvoiceItemInput is used for creating/updating invoice line items.
@@ -82,7 +181,10 @@ type InvoiceItemInput struct {
 	Quantity    float64 `json:"quantity"`
 	Unit        *string `json:"unit"`
 	UnitPrice   Money   `json:"unit_price"`
-	Amount      Money   `json:"amount"`
+	// Amount includes GST; omitted, it is Quantity times UnitPrice.
+	Amount  Money    `json:"amount"`
+	TaxRate *float64 `json:"tax_rate"`
+	HSNCode *string  `json:"hsn_code"` // 4, 6, or 8 digits
 }
 
 func (i *InvoiceItemInput) Validate() string {
@@ -95,8 +197,43 @@ func (i *InvoiceItemInput) Validate() string {
 	if i.UnitPrice < 0 {
 		return "unit_price must be non-negative"
 	}
+	trimToNil(&i.Unit)
+	if i.Amount == 0 {
+		i.Amount = Money(math.Round(i.Quantity * float64(i.UnitPrice)))
+	}
 	if i.Amount <= 0 {
 		return "amount must be positive"
 	}
+	if i.TaxRate != nil && !validTaxRate(*i.TaxRate) {
+		return "tax_rate must be between 0 and 100"
+	}
+	trimToNil(&i.HSNCode)
+	if i.HSNCode != nil && !validHSNCode(*i.HSNCode) {
+		return "hsn_code must be 4, 6, or 8 digits"
+	}
 	return ""
 }
+
+// Tax is the GST included in the item's amount at its tax rate, 0 without one.
+func (i InvoiceItemInput) Tax() Money {
+	if i.TaxRate == nil {
+		return 0
+	}
+	return IncludedTax(i.Amount, *i.TaxRate)
+}
+
+// validHSNCode reports whether code looks like an HSN code or SAC: 4, 6, or
+// 8 digits.
+func validHSNCode(code string) bool {
+	switch len(code) {
+	case 4, 6, 8:
+	default:
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
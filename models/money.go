@@ -8,35 +8,61 @@ import (
 	"strconv"
 )
 
-// Money represents a monetary value in paise (the smallest currency unit).
-// It can be unmarshaled from JSON as a number (integer or float) or a string.
+// Money represents a monetary value in the smallest unit of its currency
+// (paise for INR; see MinorUnits).
+// It can be unmarshaled from JSON as a number (integer or float) or a string
+// of whole units, which are kept to AmountMinorUnits decimal places. Inputs
+// holding amounts in an account's currency re-read them at that currency's
+// decimal places once the account is known (see TransactionInput.SetCurrency).
 type Money int64
 
 // UnmarshalJSON implements the json.Unmarshaler interface.
 func (m *Money) UnmarshalJSON(data []byte) error {
+	f, err := parseWholeUnits(data)
+	if err != nil {
+		return err
+	}
+	// Convert decimal to paise (e.g., 12.34 -> 1234)
+	*m = FromFloat(f)
+	return nil
+}
+
+// parseWholeUnits reads a JSON amount in whole units: a number, a string
+// holding one, or null for zero.
+func parseWholeUnits(data []byte) (float64, error) {
 	var v interface{}
 	if err := json.Unmarshal(data, &v); err != nil {
-		return err
+		return 0, err
 	}
 
 	switch val := v.(type) {
 	case float64:
-		// Convert decimal to paise (e.g., 12.34 -> 1234)
-		*m = Money(math.Round(val * 100))
+		return val, nil
 	case string:
 		// Attempt to parse string as float
 		f, err := strconv.ParseFloat(val, 64)
 		if err != nil {
-			return fmt.Errorf("invalid money string: %s", val)
+			return 0, fmt.Errorf("invalid money string: %s", val)
 		}
-		*m = Money(math.Round(f * 100))
+		return f, nil
 	case nil:
-		*m = 0
+		return 0, nil
 	default:
-		return fmt.Errorf("invalid money type: %T", val)
+		return 0, fmt.Errorf("invalid money type: %T", val)
 	}
+}
 
-	return nil
+// wholeAmount returns the whole-unit amount in raw, or nil when raw is empty
+// because the field was absent.
+func wholeAmount(raw json.RawMessage) (*float64, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	f, err := parseWholeUnits(raw)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
 }
 
 // MarshalJSON implements the json.Marshaler interface.
@@ -93,7 +119,32 @@ func (m Money) Value() (driver.Value, error) {
 	return int64(m), nil
 }
 
-// ToFloat returns the value as a float (e.g., 1234 -> 12.34).
+// ToFloat returns the value as a float of whole units (e.g., 1234 -> 12.34
+// when amounts are kept in paise).
 func (m Money) ToFloat() float64 {
-	return float64(m) / 100.0
+	return float64(m) / float64(UnitScale(amountMinorUnits))
+}
+
+// FromFloat converts an amount in whole units to Money, rounding to
+// AmountMinorUnits decimal places (e.g., 12.34 -> 1234 when amounts are kept
+// in paise, 12 in whole rupees).
+func FromFloat(f float64) Money {
+	return Money(math.Round(f * float64(UnitScale(amountMinorUnits))))
+}
+
+// FromFloatIn converts an amount in whole units of currency to Money in its
+// smallest unit, rounding to its decimal places (e.g., 1234.5 -> 1235 in JPY,
+// 1234500 in KWD).
+func FromFloatIn(f float64, currency string) Money {
+	return Money(math.Round(f * float64(UnitScale(MinorUnits(currency)))))
+}
+
+// AllocatedPct returns allocated as a percentage of amount, rounded to two
+// decimal places. It is 0 when amount is not positive, so documents with no
+// amount never divide by zero.
+func AllocatedPct(allocated, amount Money) float64 {
+	if amount <= 0 {
+		return 0
+	}
+	return math.Round(float64(allocated)*10000/float64(amount)) / 100
 }
@@ -46,3 +46,138 @@ func TestMoney_ToFloat(t *testing.T) {
 		t.Errorf("Money.ToFloat() = %v, want %v", got, want)
 	}
 }
+
+func TestMoney_Format(t *testing.T) {
+	tests := []struct {
+		m        Money
+		currency string
+		want     string
+	}{
+		{123456, "INR", "1234.56"},
+		{123456, "", "1234.56"},
+		{-5, "INR", "-0.05"},
+		{1234, "JPY", "1234"},
+		{1234, "jpy", "1234"},
+		{-1234, "JPY", "-1234"},
+		{1234, "KWD", "1.234"},
+		{100, "USD", "1.00"},
+	}
+	for _, tt := range tests {
+		if got := tt.m.Format(tt.currency); got != tt.want {
+			t.Errorf("Money(%d).Format(%q) = %q, want %q", tt.m, tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestMoney_InCurrency(t *testing.T) {
+	tests := []struct {
+		m        Money
+		currency string
+		want     Money
+	}{
+		{10000, "INR", 10000},
+		{10000, "JPY", 100},
+		{10050, "JPY", 101},
+		{-10050, "JPY", -101},
+		{10049, "JPY", 100},
+		{10000, "KWD", 100000},
+	}
+	for _, tt := range tests {
+		if got := tt.m.InCurrency(tt.currency); got != tt.want {
+			t.Errorf("Money(%d).InCurrency(%q) = %d, want %d", tt.m, tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestTransactionInput_SetCurrency(t *testing.T) {
+	tests := []struct {
+		currency string
+		amount   Money
+		fee      Money
+	}{
+		{"INR", 123457, 50},
+		{"JPY", 1235, 1},
+		{"KWD", 1234567, 500},
+	}
+	for _, tt := range tests {
+		var input TransactionInput
+		if err := json.Unmarshal([]byte(`{"amount": 1234.567, "fee_amount": "0.5"}`), &input); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+		input.SetCurrency(tt.currency)
+		if input.Amount != tt.amount || input.FeeAmount != tt.fee {
+			t.Errorf("SetCurrency(%q): amount %d fee %d, want %d and %d", tt.currency, input.Amount, input.FeeAmount, tt.amount, tt.fee)
+		}
+	}
+
+	// An input built in code is already in the account's currency.
+	input := TransactionInput{Amount: 500}
+	input.SetCurrency("JPY")
+	if input.Amount != 500 {
+		t.Errorf("SetCurrency changed an amount set in code to %d", input.Amount)
+	}
+}
+
+func TestMoney_FormatIndian(t *testing.T) {
+	tests := []struct {
+		m        Money
+		currency string
+		want     string
+	}{
+		{5, "INR", "0.05"},
+		{99999, "INR", "999.99"},
+		{100000, "INR", "1,000.00"},
+		{12345678, "INR", "1,23,456.78"},
+		{1234567890, "INR", "1,23,45,678.90"},
+		{-1234567890, "", "-1,23,45,678.90"},
+		{1234567, "JPY", "12,34,567"},
+	}
+	for _, tt := range tests {
+		if got := tt.m.FormatIndian(tt.currency); got != tt.want {
+			t.Errorf("Money(%d).FormatIndian(%q) = %q, want %q", tt.m, tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestMoney_WholeRupeeMode(t *testing.T) {
+	SetMinorUnits(0)
+	defer SetMinorUnits(DefaultMinorUnits)
+
+	var m Money
+	if err := json.Unmarshal([]byte(`"2122.90"`), &m); err != nil || m != 2123 {
+		t.Errorf("UnmarshalJSON(2122.90) = %v, %v; want 2123", m, err)
+	}
+	if got := Money(2123).ToFloat(); got != 2123 {
+		t.Errorf("ToFloat() = %v, want 2123", got)
+	}
+	if got := Money(2123).Format("INR"); got != "2123" {
+		t.Errorf("Format(INR) = %q, want 2123", got)
+	}
+	if got := Money(1234).Format("KWD"); got != "1.234" {
+		t.Errorf("Format(KWD) = %q, want 1.234", got)
+	}
+	if got := WholeUnits(500); got != 500 {
+		t.Errorf("WholeUnits(500) = %d, want 500", got)
+	}
+}
+
+func TestAllocatedPct(t *testing.T) {
+	tests := []struct {
+		allocated, amount Money
+		want              float64
+	}{
+		{0, 10000, 0},
+		{5000, 10000, 50},
+		{10000, 10000, 100},
+		{1, 3, 33.33},
+		{2, 3, 66.67},
+		{12000, 10000, 120},
+		{500, 0, 0},
+		{500, -100, 0},
+	}
+	for _, tt := range tests {
+		if got := AllocatedPct(tt.allocated, tt.amount); got != tt.want {
+			t.Errorf("AllocatedPct(%d, %d) = %v, want %v", tt.allocated, tt.amount, got, tt.want)
+		}
+	}
+}
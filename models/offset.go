@@ -0,0 +1,49 @@
+package models
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// OffsetInput nets a bill against an invoice of the same party, for a contact
+// who is both a vendor and a customer. No cash moves: one offset transaction
+// settles Amount of each document.
+type OffsetInput struct {
+	BillID    int `json:"bill_id"`
+	InvoiceID int `json:"invoice_id"`
+	// AccountID is the account the offset transaction is recorded under. Its
+	// balance is unaffected.
+	AccountID int `json:"account_id"`
+	// Amount is how much of each document to settle. Zero settles the smaller
+	// of the two unallocated amounts.
+	Amount Money   `json:"amount"`
+	Date   *string `json:"date"` // required, and must fall in an open period
+	Note   *string `json:"note"`
+}
+
+func (o *OffsetInput) Validate() string {
+	if o.BillID <= 0 {
+		return "bill_id is required"
+	}
+	if o.InvoiceID <= 0 {
+		return "invoice_id is required"
+	}
+	if o.AccountID <= 0 {
+		return "account_id is required"
+	}
+	if o.Amount < 0 {
+		return "amount must not be negative"
+	}
+	trimToNil(&o.Date)
+	if o.Date == nil {
+		return "date is required"
+	}
+	if err := NormalizeDate(o.Date); err != nil {
+		return "date: " + err.Error()
+	}
+	trimToNil(&o.Note)
+	if o.Note != nil && utf8.RuneCountInString(*o.Note) > MaxLinkNoteLength {
+		return fmt.Sprintf("note must be at most %d characters", MaxLinkNoteLength)
+	}
+	return ""
+}
@@ -0,0 +1,25 @@
+package models
+
+import "strings"
+
+// Outlet is a restaurant outlet that platform payouts are settled for.
+type Outlet struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Payouts   int       `json:"payouts"` // Computed: number of payouts referencing the outlet
+	CreatedAt Timestamp `json:"created_at"`
+	UpdatedAt Timestamp `json:"updated_at"`
+}
+
+// OutletInput is used for creating/updating outlets.
+type OutletInput struct {
+	Name string `json:"name"`
+}
+
+func (o *OutletInput) Validate() string {
+	o.Name = strings.TrimSpace(o.Name)
+	if o.Name == "" {
+		return "name is required"
+	}
+	return ""
+}
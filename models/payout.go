@@ -7,8 +7,9 @@ import (
 // Payout represents a platform payout record.
 type Payout struct {
 	ID                    int       `json:"id"`
-	OutletName            string    `json:"outlet_name"`
-	Platform              string    `json:"platform"` // swiggy, zomato, swiggy-dineout
+	OutletID              *int      `json:"outlet_id"`
+	OutletName            string    `json:"outlet_name"` // Denormalized from the outlet for display
+	Platform              string    `json:"platform"`    // swiggy, zomato, swiggy-dineout
 	PeriodStart           Date      `json:"period_start"`
 	PeriodEnd             Date      `json:"period_end"`
 	SettlementDate        Date      `json:"settlement_date"`
@@ -20,15 +21,21 @@ type Payout struct {
 	MarketingAdsAmt       Money     `json:"marketing_ads_amt"`
 	FinalPayoutAmt        Money     `json:"final_payout_amt"`
 	UtrNumber             string    `json:"utr_number"`
+	VarianceAmount        Money     `json:"variance_amount"` // shortfall written off when settling, see PayoutSettleInput
+	VarianceReason        string    `json:"variance_reason"`
 	CreatedAt             Timestamp `json:"created_at"`
+	UpdatedAt             Timestamp `json:"updated_at"`
 	// Computed fields
-	Allocated   Money `json:"allocated"`
-	Unallocated Money `json:"unallocated"`
+	Allocated    Money   `json:"allocated"`
+	Unallocated  Money   `json:"unallocated"`   // final_payout_amt less allocated and variance_amount
+	AllocatedPct float64 `json:"allocated_pct"` // allocated / final_payout_amt * 100, 0 for a zero payout
 }
 
 // PayoutInput is used for creating/updating payout records.
 type PayoutInput struct {
-	OutletName            string  `json:"outlet_name"`
+	OutletID              *int    `json:"outlet_id"`     // Preferred; must reference an existing outlet
+	OutletName            string  `json:"outlet_name"`   // Used to find the outlet when outlet_id is omitted
+	CreateOutlet          bool    `json:"create_outlet"` // Register outlet_name as a new outlet when no outlet matches it
 	Platform              string  `json:"platform"`
 	PeriodStart           *string `json:"period_start"`
 	PeriodEnd             *string `json:"period_end"`
@@ -44,8 +51,9 @@ type PayoutInput struct {
 }
 
 func (p *PayoutInput) Validate() string {
-	if p.OutletName == "" {
-		return "outlet_name is required"
+	p.OutletName = strings.TrimSpace(p.OutletName)
+	if p.OutletID == nil && p.OutletName == "" {
+		return "outlet_id or outlet_name is required"
 	}
 
 	// Normalize to lowercase
@@ -56,6 +64,10 @@ func (p *PayoutInput) Validate() string {
 	default:
 		return "platform must be one of: swiggy, zomato, swiggy-dineout"
 	}
+	p.UtrNumber = strings.TrimSpace(p.UtrNumber)
+	trimToNil(&p.PeriodStart)
+	trimToNil(&p.PeriodEnd)
+	trimToNil(&p.SettlementDate)
 	if err := NormalizeDate(p.PeriodStart); err != nil {
 		return "period_start: " + err.Error()
 	}
@@ -67,3 +79,28 @@ func (p *PayoutInput) Validate() string {
 	}
 	return ""
 }
+
+// PayoutSettleInput records the variance between a payout and the bank
+// credits linked to it, such as a platform adjustment or chargeback, so the
+// shortfall is explained rather than left unallocated.
+type PayoutSettleInput struct {
+	VarianceAmount *Money `json:"variance_amount"` // defaults to the unallocated amount; 0 clears a recorded variance
+	VarianceReason string `json:"variance_reason"`
+}
+
+func (p *PayoutSettleInput) Validate() string {
+	p.VarianceReason = strings.TrimSpace(p.VarianceReason)
+	if p.VarianceAmount != nil && *p.VarianceAmount < 0 {
+		return "variance_amount must not be negative"
+	}
+	if p.VarianceReason == "" && (p.VarianceAmount == nil || *p.VarianceAmount != 0) {
+		return "variance_reason is required"
+	}
+	return ""
+}
+
+// PayoutPostInput is used for posting a payout to the ledger.
+type PayoutPostInput struct {
+	ClearingAccountID *int `json:"clearing_account_id"` // defaults to the payout.clearing_account default
+	BankTransactionID *int `json:"bank_transaction_id"` // defaults to the one transaction linked to the payout
+}
@@ -0,0 +1,101 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PayoutOrder is one order settled by a payout, kept for disputes.
+type PayoutOrder struct {
+	ID            int       `json:"id"`
+	PayoutID      int       `json:"payout_id"`
+	OrderID       string    `json:"order_id"` // the platform's order id
+	OrderDate     Date      `json:"order_date"`
+	GrossAmt      Money     `json:"gross_amt"`
+	CommissionAmt Money     `json:"commission_amt"`
+	NetAmt        Money     `json:"net_amt"`
+	CreatedAt     Timestamp `json:"created_at"`
+	UpdatedAt     Timestamp `json:"updated_at"`
+}
+
+// PayoutOrderInput is one order in a bulk upload of payout orders.
+type PayoutOrderInput struct {
+	OrderID       string  `json:"order_id"`
+	OrderDate     *string `json:"order_date"`
+	GrossAmt      Money   `json:"gross_amt"`
+	CommissionAmt Money   `json:"commission_amt"`
+	NetAmt        Money   `json:"net_amt"`
+}
+
+func (o *PayoutOrderInput) Validate() string {
+	o.OrderID = strings.TrimSpace(o.OrderID)
+	if o.OrderID == "" {
+		return "order_id is required"
+	}
+	if err := NormalizeDate(o.OrderDate); err != nil {
+		return "order_date: " + err.Error()
+	}
+	if o.GrossAmt < 0 {
+		return "gross_amt must be non-negative"
+	}
+	if o.CommissionAmt < 0 {
+		return "commission_amt must be non-negative"
+	}
+	return ""
+}
+
+// PayoutOrdersInput is the body of a bulk payout order upload.
+type PayoutOrdersInput struct {
+	Orders []PayoutOrderInput `json:"orders"`
+}
+
+func (p *PayoutOrdersInput) Validate() string {
+	if len(p.Orders) == 0 {
+		return "orders is required"
+	}
+	seen := make(map[string]bool, len(p.Orders))
+	for i := range p.Orders {
+		if msg := p.Orders[i].Validate(); msg != "" {
+			return fmt.Sprintf("orders[%d]: %s", i, msg)
+		}
+		if seen[p.Orders[i].OrderID] {
+			return fmt.Sprintf("orders[%d]: duplicate order_id %q", i, p.Orders[i].OrderID)
+		}
+		seen[p.Orders[i].OrderID] = true
+	}
+	return ""
+}
+
+// PayoutOrderCheck compares one payout header amount with the sum of its orders.
+type PayoutOrderCheck struct {
+	Field      string `json:"field"` // the payout header field checked
+	Header     Money  `json:"header"`
+	Orders     Money  `json:"orders"`
+	Difference Money  `json:"difference"` // orders - header
+	OK         bool   `json:"ok"`         // within the tolerance
+}
+
+// ReconcilePayoutOrders checks that the orders' gross, commission, and net
+// amounts sum to the payout's gross_sales_amt, platform_commission_amt, and
+// final_payout_amt within tolerance. It reports whether all three agree.
+func ReconcilePayoutOrders(p Payout, orders []PayoutOrderInput, tolerance Money) ([]PayoutOrderCheck, bool) {
+	var gross, commission, net Money
+	for _, o := range orders {
+		gross += o.GrossAmt
+		commission += o.CommissionAmt
+		net += o.NetAmt
+	}
+	checks := []PayoutOrderCheck{
+		{Field: "gross_sales_amt", Header: p.GrossSalesAmt, Orders: gross},
+		{Field: "platform_commission_amt", Header: p.PlatformCommissionAmt, Orders: commission},
+		{Field: "final_payout_amt", Header: p.FinalPayoutAmt, Orders: net},
+	}
+	ok := true
+	for i := range checks {
+		c := &checks[i]
+		c.Difference = c.Orders - c.Header
+		c.OK = c.Difference <= tolerance && -c.Difference <= tolerance
+		ok = ok && c.OK
+	}
+	return checks, ok
+}
@@ -0,0 +1,27 @@
+package models
+
+// ClosedPeriod records that the books are closed up to and including ClosedThrough.
+type ClosedPeriod struct {
+	ID            int       `json:"id"`
+	ClosedThrough Date      `json:"closed_through"`
+	Notes         *string   `json:"notes"`
+	CreatedAt     Timestamp `json:"created_at"`
+}
+
+// PeriodInput is used for closing and reopening periods.
+type PeriodInput struct {
+	Date  *string `json:"date"`
+	Notes *string `json:"notes"`
+}
+
+func (p *PeriodInput) Validate() string {
+	trimToNil(&p.Date)
+	trimToNil(&p.Notes)
+	if p.Date == nil {
+		return "date is required"
+	}
+	if err := NormalizeDate(p.Date); err != nil {
+		return "date: " + err.Error()
+	}
+	return ""
+}
@@ -87,6 +87,11 @@ func (r *RecurringPaymentInput) Validate() string {
 	if err := NormalizeDate(&r.StartDate); err != nil {
 		return "start_date: " + err.Error()
 	}
+	trimToNil(&r.EndDate)
+	trimToNil(&r.NextDueDate)
+	trimToNil(&r.LastGeneratedDate)
+	trimToNil(&r.Description)
+	trimToNil(&r.Reference)
 	if err := NormalizeDate(r.EndDate); err != nil {
 		return "end_date: " + err.Error()
 	}
@@ -1,22 +1,41 @@
 package models
 
-// Transaction represents a bank transaction (income, expense, or transfer).
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Transaction represents a bank transaction (income, expense, transfer, or
+// adjustment), or an offset netting a bill against an invoice.
 type Transaction struct {
 	ID                int       `json:"id"`
 	AccountID         int       `json:"account_id"`
-	Type              string    `json:"type"` // income, expense, transfer
+	Type              string    `json:"type"` // income, expense, transfer, adjustment, offset
 	Amount            Money     `json:"amount"`
 	TransactionDate   Date      `json:"transaction_date"`
 	Description       *string   `json:"description"`
 	Reference         *string   `json:"reference"`
 	TransferAccountID *int      `json:"transfer_account_id"`
 	ContactID         *int      `json:"contact_id"`
+	CategoryID        *int      `json:"category_id"`
+	Reconciled        bool      `json:"reconciled"`
+	Locked            bool      `json:"locked"`       // frozen against edits and deletion until unlocked
+	Cleared           bool      `json:"cleared"`      // false while the money is still in transit, e.g. an uncleared cheque
+	ClearedDate       Date      `json:"cleared_date"` // when it cleared, if recorded
+	ExternalID        *string   `json:"external_id"`
+	Source            *string   `json:"source"`
+	ExchangeRate      *float64  `json:"exchange_rate"` // transfer legs between currencies: destination units per source unit
+	Sign              *int      `json:"sign"`          // adjustments only: 1 adds to the balance, -1 subtracts
 	CreatedAt         Timestamp `json:"created_at"`
 	UpdatedAt         Timestamp `json:"updated_at"`
 	// Computed fields
 	AccountName         *string `json:"account_name,omitempty"`
+	Currency            string  `json:"currency,omitempty"` // the account's currency, which Amount is in
 	TransferAccountName *string `json:"transfer_account_name,omitempty"`
 	ContactName         *string `json:"contact_name,omitempty"`
+	CategoryName        *string `json:"category_name,omitempty"`
 	Allocated           Money   `json:"allocated"`
 	Unallocated         Money   `json:"unallocated"`
 }
@@ -26,11 +45,80 @@ type TransactionInput struct {
 	AccountID         int     `json:"account_id"`
 	Type              string  `json:"type"`
 	Amount            Money   `json:"amount"`
+	Currency          string  `json:"currency"` // optional ISO 4217 code of Amount; must be the account's (the source account's for a transfer)
 	TransactionDate   *string `json:"transaction_date"`
 	Description       *string `json:"description"`
 	Reference         *string `json:"reference"`
 	TransferAccountID *int    `json:"transfer_account_id"`
 	ContactID         *int    `json:"contact_id"`
+	// CategoryID tags an income or expense with a category of the same type
+	// for reporting. Omitting it on update clears the category.
+	CategoryID *int `json:"category_id"`
+	// ExternalID identifies the record in an external system (bank API, POS).
+	// It is unique per account and Source: creating a transaction with an
+	// existing (account_id, source, external_id) triple updates that
+	// transaction instead. On update, omitting either field keeps the stored
+	// value.
+	ExternalID *string `json:"external_id"`
+	Source     *string `json:"source"`
+	// FeeAmount is an optional charge lost on a transfer. When creating a
+	// transfer it is posted as a separate expense on the source account and
+	// the paired legs move Amount - FeeAmount.
+	FeeAmount Money `json:"fee_amount"`
+	// ExchangeRate converts a transfer between accounts of different
+	// currencies: units of the destination currency per unit of the source
+	// currency. It is required exactly when the currencies differ.
+	ExchangeRate *float64 `json:"exchange_rate"`
+	// Sign gives the direction of an adjustment: 1 adds Amount to the account
+	// balance and -1 subtracts it. Adjustments correct a balance (e.g. a wrong
+	// opening balance) without being income or expense, so they are left out
+	// of income and expense figures. It is required for adjustments and not
+	// allowed otherwise.
+	Sign *int `json:"sign"`
+	// ConfirmedLarge acknowledges an amount above LARGE_TXN_THRESHOLD.
+	ConfirmedLarge bool `json:"confirmed_large"`
+	// Cleared marks whether the money has moved; false records a pending
+	// cheque or payment. Omitted means cleared on create and keeps the stored
+	// value on update. ClearedDate is when it cleared and implies Cleared.
+	Cleared     *bool   `json:"cleared"`
+	ClearedDate *string `json:"cleared_date"`
+
+	wholeAmount, wholeFee *float64 // amount and fee_amount as sent, for SetCurrency
+}
+
+// UnmarshalJSON decodes t and keeps amount and fee_amount in whole units as
+// sent, for SetCurrency.
+func (t *TransactionInput) UnmarshalJSON(data []byte) error {
+	type plain TransactionInput
+	if err := json.Unmarshal(data, (*plain)(t)); err != nil {
+		return err
+	}
+	var whole struct {
+		Amount    json.RawMessage `json:"amount"`
+		FeeAmount json.RawMessage `json:"fee_amount"`
+	}
+	if err := json.Unmarshal(data, &whole); err != nil {
+		return err
+	}
+	var err error
+	if t.wholeAmount, err = wholeAmount(whole.Amount); err != nil {
+		return err
+	}
+	t.wholeFee, err = wholeAmount(whole.FeeAmount)
+	return err
+}
+
+// SetCurrency re-reads Amount and FeeAmount, which JSON decoding keeps to
+// AmountMinorUnits decimal places, in the smallest unit of currency, the
+// account's (the source account's for a transfer). An input not decoded from
+// JSON is left as it is.
+func (t *TransactionInput) SetCurrency(currency string) {
+	if t.wholeAmount != nil {
+		t.Amount = FromFloatIn(*t.wholeAmount, currency)
+	}
+	if t.wholeFee != nil {
+		t.FeeAmount = FromFloatIn(*t.wholeFee, currency)
+	}
 }
 
 func (t *TransactionInput) Validate() string {
@@ -41,9 +129,23 @@ func (t *TransactionInput) Validate() string {
 		return "amount must be positive"
 	}
 	switch t.Type {
-	case "income", "expense", "transfer":
+	case "income", "expense", "transfer", "adjustment":
 	default:
-		return "type must be one of: income, expense, transfer"
+		return "type must be one of: income, expense, transfer, adjustment"
+	}
+	t.Currency = strings.ToUpper(strings.TrimSpace(t.Currency))
+	if t.Currency != "" && !IsCurrencyCode(t.Currency) {
+		return "currency must be a three-letter ISO 4217 code"
+	}
+	if t.Type == "adjustment" {
+		if t.Sign == nil || (*t.Sign != 1 && *t.Sign != -1) {
+			return "sign must be 1 or -1 for adjustments"
+		}
+	} else if t.Sign != nil {
+		return "sign is only allowed for adjustments"
+	}
+	if t.CategoryID != nil && t.Type != "income" && t.Type != "expense" {
+		return "category_id is only allowed for income and expense transactions"
 	}
 	if t.Type == "transfer" && (t.TransferAccountID == nil || *t.TransferAccountID <= 0) {
 		return "transfer_account_id is required for transfers"
@@ -51,8 +153,98 @@ func (t *TransactionInput) Validate() string {
 	if t.Type == "transfer" && t.TransferAccountID != nil && *t.TransferAccountID == t.AccountID {
 		return "transfer_account_id must differ from account_id"
 	}
+	trimToNil(&t.TransactionDate)
+	trimToNil(&t.Description)
+	trimToNil(&t.Reference)
+	trimToNil(&t.ExternalID)
+	trimToNil(&t.Source)
+	if t.ExternalID != nil && t.Type == "transfer" {
+		return "external_id is not supported for transfers"
+	}
+	if t.FeeAmount < 0 {
+		return "fee_amount must not be negative"
+	}
+	if t.FeeAmount > 0 && t.Type != "transfer" {
+		return "fee_amount is only allowed for transfers"
+	}
+	if t.FeeAmount > 0 && t.FeeAmount >= t.Amount {
+		return "fee_amount must be less than amount"
+	}
+	if t.ExchangeRate != nil {
+		if t.Type != "transfer" {
+			return "exchange_rate is only allowed for transfers"
+		}
+		if math.IsNaN(*t.ExchangeRate) || math.IsInf(*t.ExchangeRate, 0) || *t.ExchangeRate <= 0 {
+			return "exchange_rate must be positive"
+		}
+	}
 	if err := NormalizeDate(t.TransactionDate); err != nil {
 		return "transaction_date: " + err.Error()
 	}
+	trimToNil(&t.ClearedDate)
+	if t.ClearedDate != nil {
+		if t.Cleared != nil && !*t.Cleared {
+			return "cleared_date is not allowed when cleared is false"
+		}
+		if err := NormalizeDate(t.ClearedDate); err != nil {
+			return "cleared_date: " + err.Error()
+		}
+		cleared := true
+		t.Cleared = &cleared
+	}
+	return ""
+}
+
+// IsCleared reports whether a new transaction is recorded as cleared, which
+// it is unless Cleared is explicitly false.
+func (t *TransactionInput) IsCleared() bool {
+	return t.Cleared == nil || *t.Cleared
+}
+
+// TransferAmounts returns the amounts moved by the two legs of a transfer
+// from an account in currency from to one in currency to: the source leg
+// carries Amount less any fee, and the destination leg that net amount
+// converted at ExchangeRate, which is quoted per whole unit, into the
+// destination currency's smallest unit, rounded. Without a rate both legs
+// carry the same amount.
+func (t *TransactionInput) TransferAmounts(from, to string) (source, destination Money) {
+	source = t.Amount - t.FeeAmount
+	if t.ExchangeRate == nil {
+		return source, source
+	}
+	scale := float64(UnitScale(MinorUnits(to))) / float64(UnitScale(MinorUnits(from)))
+	return source, Money(math.Round(float64(source) * *t.ExchangeRate * scale))
+}
+
+// trimToNil trims *p and sets p to nil when the result is empty.
+func trimToNil(p **string) {
+	if *p == nil {
+		return
+	}
+	v := strings.TrimSpace(**p)
+	if v == "" {
+		*p = nil
+		return
+	}
+	*p = &v
+}
+
+// ReconcileByReferenceInput lists bank references whose transactions should be
+// marked reconciled. AccountID optionally restricts matching to one account,
+// which keeps both legs of a transfer from matching the same reference.
+type ReconcileByReferenceInput struct {
+	AccountID  *int     `json:"account_id"`
+	References []string `json:"references"`
+}
+
+func (r *ReconcileByReferenceInput) Validate() string {
+	if len(r.References) == 0 {
+		return "references is required"
+	}
+	for i, ref := range r.References {
+		if ref == "" {
+			return fmt.Sprintf("references[%d] must not be empty", i)
+		}
+	}
 	return ""
 }
@@ -1,5 +1,14 @@
 package models
 
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxLinkNoteLength is the longest note, in characters, accepted on a link.
+const MaxLinkNoteLength = 500
+
 // TransactionDocument links a transaction to a bill, invoice, payout or recurring payment occurrence with an allocated amount.
 type TransactionDocument struct {
 	ID            int       `json:"id"`
@@ -7,14 +16,16 @@ type TransactionDocument struct {
 	DocumentType  string    `json:"document_type"` // bill, invoice, payout or recurring_payment_occurrence
 	DocumentID    int       `json:"document_id"`
 	Amount        Money     `json:"amount"`
+	Note          *string   `json:"note"` // why this amount was allocated, e.g. a retention held back
 	CreatedAt     Timestamp `json:"created_at"`
 }
 
 // TransactionDocumentInput is used for linking transactions to bills, invoices, payouts or recurring payment occurrences.
 type TransactionDocumentInput struct {
-	DocumentType string `json:"document_type"`
-	DocumentID   int    `json:"document_id"`
-	Amount       Money  `json:"amount"`
+	DocumentType string  `json:"document_type"`
+	DocumentID   int     `json:"document_id"`
+	Amount       Money   `json:"amount"`
+	Note         *string `json:"note"`
 }
 
 func (td *TransactionDocumentInput) Validate() string {
@@ -29,5 +40,16 @@ func (td *TransactionDocumentInput) Validate() string {
 	if td.Amount <= 0 {
 		return "amount must be positive"
 	}
+	if td.Note != nil {
+		note := strings.TrimSpace(*td.Note)
+		if utf8.RuneCountInString(note) > MaxLinkNoteLength {
+			return fmt.Sprintf("note must be at most %d characters", MaxLinkNoteLength)
+		}
+		if note == "" {
+			td.Note = nil
+		} else {
+			td.Note = &note
+		}
+	}
 	return ""
 }
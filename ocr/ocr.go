@@ -0,0 +1,101 @@
+// Package ocr extracts bill details from receipt images. Extraction is
+// delegated to an Extractor so deployments can plug in an OCR service;
+// Manual leaves every field for the user to fill in.
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/satheeshds/portal/models"
+)
+
+// Receipt holds the bill details read from a receipt. Fields the extractor
+// could not read are left empty.
+type Receipt struct {
+	Vendor     string       `json:"vendor"`
+	Date       string       `json:"date"` // as printed; callers normalise it
+	Amount     models.Money `json:"amount"`
+	BillNumber string       `json:"bill_number"`
+}
+
+// Extractor reads a receipt image.
+type Extractor interface {
+	// Name identifies the extractor in responses, e.g. "manual".
+	Name() string
+	Extract(ctx context.Context, image []byte, contentType string) (Receipt, error)
+}
+
+// Manual is the fallback extractor used when no OCR service is configured. It
+// reads nothing, so the returned draft is filled in by hand.
+type Manual struct{}
+
+func (Manual) Name() string { return "manual" }
+
+func (Manual) Extract(context.Context, []byte, string) (Receipt, error) {
+	return Receipt{}, nil
+}
+
+// HTTP posts the image to an extraction service and expects a JSON reply of
+// the form {"vendor", "date", "amount", "bill_number"}, with amount in rupees.
+type HTTP struct {
+	URL    string
+	APIKey string // sent as a bearer token when set
+	Client *http.Client
+}
+
+// NewHTTP returns an HTTP extractor for url with the given request timeout.
+func NewHTTP(url, apiKey string, timeout time.Duration) *HTTP {
+	return &HTTP{URL: url, APIKey: apiKey, Client: &http.Client{Timeout: timeout}}
+}
+
+func (h *HTTP) Name() string { return "http" }
+
+// httpReply is the response body of an extraction service.
+type httpReply struct {
+	Vendor     string   `json:"vendor"`
+	Date       string   `json:"date"`
+	Amount     *float64 `json:"amount"`
+	BillNumber string   `json:"bill_number"`
+}
+
+func (h *HTTP) Extract(ctx context.Context, image []byte, contentType string) (Receipt, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(image))
+	if err != nil {
+		return Receipt{}, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if h.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+h.APIKey)
+	}
+	resp, err := h.Client.Do(req)
+	if err != nil {
+		return Receipt{}, fmt.Errorf("ocr service: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return Receipt{}, fmt.Errorf("ocr service returned %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	var reply httpReply
+	if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+		return Receipt{}, errors.New("ocr service returned invalid JSON")
+	}
+	rec := Receipt{
+		Vendor:     strings.TrimSpace(reply.Vendor),
+		Date:       strings.TrimSpace(reply.Date),
+		BillNumber: strings.TrimSpace(reply.BillNumber),
+	}
+	if reply.Amount != nil && *reply.Amount > 0 {
+		rec.Amount = models.FromFloat(*reply.Amount)
+	}
+	return rec, nil
+}
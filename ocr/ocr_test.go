@@ -0,0 +1,46 @@
+package ocr
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHTTPExtract(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer key" {
+			t.Errorf("Authorization = %q", got)
+		}
+		if got := r.Header.Get("Content-Type"); got != "image/png" {
+			t.Errorf("Content-Type = %q", got)
+		}
+		if body, _ := io.ReadAll(r.Body); string(body) != "img" {
+			t.Errorf("body = %q", body)
+		}
+		w.Write([]byte(`{"vendor": " Acme Supplies ", "date": "05-01-2024", "amount": 1234.565, "bill_number": "INV-9"}`))
+	}))
+	defer srv.Close()
+
+	rec, err := NewHTTP(srv.URL, "key", time.Second).Extract(context.Background(), []byte("img"), "image/png")
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	want := Receipt{Vendor: "Acme Supplies", Date: "05-01-2024", Amount: 123457, BillNumber: "INV-9"}
+	if rec != want {
+		t.Errorf("got %+v, want %+v", rec, want)
+	}
+}
+
+func TestHTTPExtract_ServiceError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unreadable image", http.StatusUnprocessableEntity)
+	}))
+	defer srv.Close()
+
+	if _, err := NewHTTP(srv.URL, "", time.Second).Extract(context.Background(), []byte("img"), "image/png"); err == nil {
+		t.Error("expected an error for a non-200 reply")
+	}
+}
@@ -1,16 +1,35 @@
 // ===== Auth State =====
 const AUTH_TOKEN_KEY = 'portal_auth_token';
+const REFRESH_TOKEN_KEY = 'portal_refresh_token';
 
 function getToken() {
     return localStorage.getItem(AUTH_TOKEN_KEY);
 }
 
-function setToken(token) {
+function setToken(token, refreshToken) {
     localStorage.setItem(AUTH_TOKEN_KEY, token);
+    if (refreshToken) localStorage.setItem(REFRESH_TOKEN_KEY, refreshToken);
 }
 
 function clearToken() {
     localStorage.removeItem(AUTH_TOKEN_KEY);
+    localStorage.removeItem(REFRESH_TOKEN_KEY);
+}
+
+// Exchanges the stored refresh token for a new access token. Returns false
+// when there is none or the session has ended.
+async function refreshToken() {
+    const refresh = localStorage.getItem(REFRESH_TOKEN_KEY);
+    if (!refresh) return false;
+    const res = await fetch(API + '/auth/refresh', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ refresh_token: refresh }),
+    });
+    if (!res.ok) return false;
+    const json = await res.json();
+    setToken(json.data.token, json.data.refresh_token);
+    return true;
 }
 
 function isAuthenticated() {
@@ -18,15 +37,19 @@ function isAuthenticated() {
 }
 
 // ===== API Client =====
-const API = '/api/v1';
+// Relative so the UI keeps working when mounted under BASE_PATH.
+const API = 'api/v1';
 
-async function api(path, options = {}) {
+async function api(path, options = {}, retried = false) {
     const token = getToken();
     const authHeaders = token ? { 'Authorization': 'Bearer ' + token } : {};
     const res = await fetch(API + path, {
         headers: { 'Content-Type': 'application/json', ...authHeaders, ...options.headers },
         ...options,
     });
+    if (res.status === 401 && !retried && await refreshToken()) {
+        return api(path, options, true);
+    }
     if (res.status === 401) {
         clearToken();
         showAuthOverlay();
@@ -48,11 +71,41 @@ function esc(str) {
 }
 
 // ===== Money Helpers =====
-function formatMoney(paise) {
-    return '₹' + (paise / 100).toLocaleString('en-IN', { minimumFractionDigits: 2, maximumFractionDigits: 2 });
+// Amounts are integers in their currency's smallest unit. GET /config says how
+// many decimal places that is: minor_units for the books currency and most
+// others, currency_minor_units for the currencies with their own.
+let moneyConfig = null;
+async function loadMoneyConfig() {
+    if (!moneyConfig) moneyConfig = await api('/config');
+}
+function booksCurrency() {
+    return moneyConfig ? moneyConfig.books_currency : 'INR';
+}
+function minorUnitsOf(currency) {
+    if (!moneyConfig) return 2;
+    const own = moneyConfig.currency_minor_units[(currency || booksCurrency()).toUpperCase()];
+    return own === undefined ? moneyConfig.minor_units : own;
+}
+function unitScale(currency) {
+    return Math.pow(10, minorUnitsOf(currency));
+}
+function formatMoney(amount, currency, minorUnits) {
+    currency = currency || booksCurrency();
+    if (minorUnits === undefined) minorUnits = minorUnitsOf(currency);
+    const value = amount / Math.pow(10, minorUnits);
+    const digits = { minimumFractionDigits: minorUnits, maximumFractionDigits: minorUnits };
+    if (currency === 'INR') {
+        return '₹' + value.toLocaleString('en-IN', digits);
+    }
+    return value.toLocaleString('en-IN', { style: 'currency', currency, ...digits });
+}
+// toWhole renders an amount in whole units, for the value of an amount input.
+function toWhole(amount, currency) {
+    return (amount / unitScale(currency)).toFixed(minorUnitsOf(currency));
 }
-function toRupees(paise) {
-    return (paise / 100).toFixed(2);
+// amountStep is the step of an amount input in currency: its smallest unit.
+function amountStep(currency) {
+    return String(1 / unitScale(currency));
 }
 function formatDate(dateStr) {
     if (!dateStr) return '—';
@@ -121,6 +174,7 @@ const el = () => document.getElementById('section-content');
 async function renderSection(section, params) {
     setActiveNav(section);
     try {
+        await loadMoneyConfig();
         switch (section) {
             case 'dashboard': await renderDashboard(); break;
             case 'bills': await renderBills(params); break;
@@ -137,8 +191,25 @@ async function renderSection(section, params) {
     }
 }
 
+// ===== Live Updates =====
+// A single EventSource refreshes the dashboard whenever data changes.
+let eventSource = null;
+let refreshTimer = null;
+
+function subscribeEvents() {
+    if (eventSource || !window.EventSource) return;
+    const token = getToken();
+    eventSource = new EventSource(API + '/events' + (token ? '?access_token=' + encodeURIComponent(token) : ''));
+    eventSource.onmessage = () => {
+        if (getSection().section !== 'dashboard') return;
+        clearTimeout(refreshTimer);
+        refreshTimer = setTimeout(renderDashboard, 500);
+    };
+}
+
 // ===== Dashboard =====
 async function renderDashboard() {
+    subscribeEvents();
     const d = await api('/dashboard');
     el().innerHTML = `
         <div class="section-header"><h1>Dashboard</h1></div>
@@ -178,7 +249,7 @@ async function renderDashboard() {
                         <td><span class="badge badge-${t.type}">${t.type}</span></td>
                         <td>${t.account_name || '—'}</td>
                         <td>${t.description || '—'}</td>
-                        <td class="money ${t.type === 'income' ? 'money-income' : 'money-expense'}">${formatMoney(t.amount)}</td>
+                        <td class="money ${t.type === 'income' || t.sign === 1 ? 'money-income' : 'money-expense'}">${formatMoney(t.amount, t.currency)}</td>
                     </tr>`).join('')}
                 </tbody>
             </table>
@@ -206,9 +277,9 @@ async function renderAccounts(params) {
             accounts.map(a => `<tr>
                         <td>${a.name}</td>
                         <td><span class="badge badge-${a.type}">${a.type.replace('_', ' ')}</span></td>
-                        <td class="money">${formatMoney(a.opening_balance)}</td>
+                        <td class="money">${formatMoney(a.opening_balance, a.currency, a.minor_units)}</td>
                         <td>
-                            <strong class="money">${formatMoney(a.balance)}</strong>
+                            <strong class="money">${formatMoney(a.display_balance, a.currency, a.minor_units)}</strong>${a.balance_type === 'liability' ? ' <small>owed</small>' : ''}
                             <br><button class="btn-link" onclick="navigate('transactions', {account_id: ${a.id}})">View Txns</button>
                         </td>
                         <td class="actions-cell">
@@ -223,7 +294,7 @@ async function renderAccounts(params) {
 }
 
 async function showAccountForm(id) {
-    let data = { name: '', type: 'bank', opening_balance: 0 };
+    let data = { name: '', type: 'bank', currency: 'INR', opening_balance: 0 };
     if (id) {
         data = await api(`/accounts/${id}`);
     }
@@ -240,11 +311,16 @@ async function showAccountForm(id) {
                         <option value="bank" ${data.type === 'bank' ? 'selected' : ''}>Bank</option>
                         <option value="cash" ${data.type === 'cash' ? 'selected' : ''}>Cash</option>
                         <option value="credit_card" ${data.type === 'credit_card' ? 'selected' : ''}>Credit Card</option>
+                        <option value="loan" ${data.type === 'loan' ? 'selected' : ''}>Loan</option>
                     </select>
                 </div>
                 <div class="form-group">
-                    <label>Opening Balance (₹)</label>
-                    <input class="form-control" name="opening_balance" type="number" step="0.01" value="${toRupees(data.opening_balance)}">
+                    <label>Currency</label>
+                    <input class="form-control" name="currency" maxlength="3" value="${data.currency || 'INR'}" oninput="this.form.opening_balance.step = amountStep(this.value)">
+                </div>
+                <div class="form-group">
+                    <label>Opening Balance</label>
+                    <input class="form-control" name="opening_balance" type="number" step="${amountStep(data.currency || 'INR')}" value="${toWhole(data.opening_balance, data.currency || 'INR')}">
                 </div>
             </div>
             <div class="form-actions">
@@ -261,6 +337,7 @@ async function saveAccount(e, id) {
     const body = JSON.stringify({
         name: form.name.value,
         type: form.type.value,
+        currency: form.currency.value,
         opening_balance: parseFloat(form.opening_balance.value || 0),
     });
     if (id) {
@@ -410,12 +487,13 @@ async function renderBills(params) {
                         <td class="money">${formatMoney(b.amount)}</td>
                         <td>
                             <span class="money">${formatMoney(b.allocated)}</span>
-                            ${b.amount > 0 ? `<div class="alloc-bar"><div class="alloc-bar-fill ${b.allocated >= b.amount ? 'full' : ''}" style="width:${Math.min(100, (b.allocated / b.amount) * 100)}%"></div></div>` : ''}
+                            ${b.amount > 0 ? `<div class="alloc-bar"><div class="alloc-bar-fill ${b.allocated >= b.amount ? 'full' : ''}" style="width:${Math.min(100, b.allocated_pct)}%"></div></div>` : ''}
                         </td>
                         <td><span class="badge badge-${b.status}">${b.status}</span></td>
                         <td><button class="btn-link" onclick="showDocumentLinks('bill', ${b.id})">View Links</button></td>
                         <td class="actions-cell">
                             <button class="btn btn-ghost btn-sm" onclick="showBillForm(${b.id})">Edit</button>
+                            ${b.status !== 'cancelled' ? `<button class="btn btn-ghost btn-sm" onclick="voidBill(${b.id})">Void</button>` : ''}
                             <button class="btn btn-danger btn-sm" onclick="deleteBill(${b.id})">Delete</button>
                         </td>
                     </tr>`).join('')}
@@ -478,24 +556,25 @@ async function showDocumentLinks(type, id) {
     `);
 }
 
-async function postTransactionLink(txnId, docType, docId, amountPaise, onSuccess) {
+async function postTransactionLink(txnId, docType, docId, amount, onSuccess) {
     try {
-        await api(`/transactions/${txnId}/links`, {
+        const link = await api(`/transactions/${txnId}/links`, {
             method: 'POST',
             body: JSON.stringify({
                 document_type: docType,
                 document_id: docId,
-                amount: amountPaise / 100,
+                amount: amount / unitScale(),
             }),
         });
+        if (link.warnings) alert(link.warnings.join('\n'));
         onSuccess();
     } catch (err) {
         alert('Error: ' + err.message);
     }
 }
 
-async function linkDocumentToTransaction(docType, docId, txnId, amountPaise) {
-    await postTransactionLink(txnId, docType, docId, amountPaise, () => showDocumentLinks(docType, docId));
+async function linkDocumentToTransaction(docType, docId, txnId, amount) {
+    await postTransactionLink(txnId, docType, docId, amount, () => showDocumentLinks(docType, docId));
 }
 
 async function showBillForm(id) {
@@ -530,7 +609,7 @@ async function showBillForm(id) {
             <div class="form-row">
                 <div class="form-group">
                     <label>Amount (₹)</label>
-                    <input class="form-control" name="amount" type="number" step="0.01" value="${toRupees(data.amount)}" required>
+                    <input class="form-control" name="amount" type="number" step="${amountStep()}" value="${toWhole(data.amount)}" required>
                 </div>
                 <div class="form-group">
                     <label>Status</label>
@@ -574,6 +653,16 @@ async function saveBill(e, id) {
     renderBills();
 }
 
+async function voidBill(id) {
+    if (!confirm('Void this bill? It is kept but marked cancelled.')) return;
+    try {
+        await api(`/bills/${id}/void`, { method: 'POST' });
+    } catch (e) {
+        alert(e.message);
+    }
+    renderBills();
+}
+
 async function deleteBill(id) {
     if (!confirm('Delete this bill?')) return;
     await api(`/bills/${id}`, { method: 'DELETE' });
@@ -613,12 +702,14 @@ async function renderInvoices(params) {
                         <td class="money">${formatMoney(inv.amount)}</td>
                         <td>
                             <span class="money">${formatMoney(inv.allocated)}</span>
-                            ${inv.amount > 0 ? `<div class="alloc-bar"><div class="alloc-bar-fill ${inv.allocated >= inv.amount ? 'full' : ''}" style="width:${Math.min(100, (inv.allocated / inv.amount) * 100)}%"></div></div>` : ''}
+                            ${inv.amount > 0 ? `<div class="alloc-bar"><div class="alloc-bar-fill ${inv.allocated >= inv.amount ? 'full' : ''}" style="width:${Math.min(100, inv.allocated_pct)}%"></div></div>` : ''}
                         </td>
                         <td><span class="badge badge-${inv.status}">${inv.status}</span></td>
                         <td><button class="btn-link" onclick="showDocumentLinks('invoice', ${inv.id})">View Links</button></td>
                         <td class="actions-cell">
                             <button class="btn btn-ghost btn-sm" onclick="showInvoiceForm(${inv.id})">Edit</button>
+                            ${inv.status === 'draft' ? `<button class="btn btn-ghost btn-sm" onclick="sendInvoice(${inv.id})">Mark Sent</button>` : ''}
+                            ${inv.status !== 'cancelled' ? `<button class="btn btn-ghost btn-sm" onclick="voidInvoice(${inv.id})">Void</button>` : ''}
                             <button class="btn btn-danger btn-sm" onclick="deleteInvoice(${inv.id})">Delete</button>
                         </td>
                     </tr>`).join('')}
@@ -660,7 +751,7 @@ async function showInvoiceForm(id) {
             <div class="form-row">
                 <div class="form-group">
                     <label>Amount (₹)</label>
-                    <input class="form-control" name="amount" type="number" step="0.01" value="${toRupees(data.amount)}" required>
+                    <input class="form-control" name="amount" type="number" step="${amountStep()}" value="${toWhole(data.amount)}" required>
                 </div>
                 <div class="form-group">
                     <label>Status</label>
@@ -704,6 +795,25 @@ async function saveInvoice(e, id) {
     renderInvoices();
 }
 
+async function sendInvoice(id) {
+    try {
+        await api(`/invoices/${id}/send`, { method: 'POST' });
+    } catch (e) {
+        alert(e.message);
+    }
+    renderInvoices();
+}
+
+async function voidInvoice(id) {
+    if (!confirm('Void this invoice? It is kept but marked cancelled.')) return;
+    try {
+        await api(`/invoices/${id}/void`, { method: 'POST' });
+    } catch (e) {
+        alert(e.message);
+    }
+    renderInvoices();
+}
+
 async function deleteInvoice(id) {
     if (!confirm('Delete this invoice?')) return;
     await api(`/invoices/${id}`, { method: 'DELETE' });
@@ -746,7 +856,7 @@ async function renderPayouts(params) {
                         <td class="money money-income">${formatMoney(p.final_payout_amt)}</td>
                         <td>
                             <span class="money">${formatMoney(p.allocated)}</span>
-                            ${p.final_payout_amt > 0 ? `<div class="alloc-bar"><div class="alloc-bar-fill ${p.allocated >= p.final_payout_amt ? 'full' : ''}" style="width:${Math.min(100, (p.allocated / p.final_payout_amt) * 100)}%"></div></div>` : ''}
+                            ${p.final_payout_amt > 0 ? `<div class="alloc-bar"><div class="alloc-bar-fill ${p.allocated >= p.final_payout_amt ? 'full' : ''}" style="width:${Math.min(100, p.allocated_pct)}%"></div></div>` : ''}
                         </td>
                         <td><small>${p.utr_number || '—'}</small></td>
                         <td><button class="btn-link" onclick="showDocumentLinks('payout', ${p.id})">View Links</button></td>
@@ -768,12 +878,16 @@ async function showPayoutForm(id) {
         taxes_tcs_tds_amt: 0, marketing_ads_amt: 0, final_payout_amt: 0, utr_number: ''
     };
     if (id) data = await api(`/payouts/${id}`);
+    const outlets = await api('/outlets');
     openModal(id ? 'Edit Payout' : 'New Payout', `
         <form onsubmit="savePayout(event, ${id || 'null'})">
             <div class="form-row">
                 <div class="form-group">
                     <label>Outlet Name</label>
-                    <input class="form-control" name="outlet_name" value="${data.outlet_name}" required>
+                    <input class="form-control" name="outlet_name" value="${data.outlet_name}" list="outlet-options" required>
+                    <datalist id="outlet-options">
+                        ${outlets.map(o => `<option value="${esc(o.name)}">`).join('')}
+                    </datalist>
                 </div>
                 <div class="form-group">
                     <label>Platform</label>
@@ -810,31 +924,31 @@ async function showPayoutForm(id) {
             <div class="form-row">
                 <div class="form-group">
                     <label>Gross Sales (₹)</label>
-                    <input class="form-control" name="gross_sales_amt" type="number" step="0.01" value="${toRupees(data.gross_sales_amt)}" required>
+                    <input class="form-control" name="gross_sales_amt" type="number" step="${amountStep()}" value="${toWhole(data.gross_sales_amt)}" required>
                 </div>
                 <div class="form-group">
                     <label>Restaurant Discount (₹)</label>
-                    <input class="form-control" name="restaurant_discount_amt" type="number" step="0.01" value="${toRupees(data.restaurant_discount_amt)}">
+                    <input class="form-control" name="restaurant_discount_amt" type="number" step="${amountStep()}" value="${toWhole(data.restaurant_discount_amt)}">
                 </div>
             </div>
             <div class="form-row">
                 <div class="form-group">
                     <label>Commission (₹)</label>
-                    <input class="form-control" name="platform_commission_amt" type="number" step="0.01" value="${toRupees(data.platform_commission_amt)}">
+                    <input class="form-control" name="platform_commission_amt" type="number" step="${amountStep()}" value="${toWhole(data.platform_commission_amt)}">
                 </div>
                 <div class="form-group">
                     <label>Taxes/TCS/TDS (₹)</label>
-                    <input class="form-control" name="taxes_tcs_tds_amt" type="number" step="0.01" value="${toRupees(data.taxes_tcs_tds_amt)}">
+                    <input class="form-control" name="taxes_tcs_tds_amt" type="number" step="${amountStep()}" value="${toWhole(data.taxes_tcs_tds_amt)}">
                 </div>
             </div>
             <div class="form-row">
                 <div class="form-group">
                     <label>Marketing/Ads (₹)</label>
-                    <input class="form-control" name="marketing_ads_amt" type="number" step="0.01" value="${toRupees(data.marketing_ads_amt)}">
+                    <input class="form-control" name="marketing_ads_amt" type="number" step="${amountStep()}" value="${toWhole(data.marketing_ads_amt)}">
                 </div>
                 <div class="form-group">
                     <label>Final Payout (₹)</label>
-                    <input class="form-control" name="final_payout_amt" type="number" step="0.01" value="${toRupees(data.final_payout_amt)}" required>
+                    <input class="form-control" name="final_payout_amt" type="number" step="${amountStep()}" value="${toWhole(data.final_payout_amt)}" required>
                 </div>
             </div>
             <div class="form-actions">
@@ -848,8 +962,13 @@ async function showPayoutForm(id) {
 async function savePayout(e, id) {
     e.preventDefault();
     const f = e.target;
+    const name = f.outlet_name.value.trim();
+    const outlets = await api('/outlets');
+    const known = outlets.some(o => o.name.toLowerCase() === name.toLowerCase());
+    if (!known && !confirm(`Outlet "${name}" is not registered. Register it as a new outlet?`)) return;
     const body = JSON.stringify({
-        outlet_name: f.outlet_name.value,
+        outlet_name: name,
+        create_outlet: !known,
         platform: f.platform.value,
         period_start: f.period_start.value || null,
         period_end: f.period_end.value || null,
@@ -907,9 +1026,9 @@ async function renderTransactions(params) {
                         <td><span class="badge badge-${t.type}">${t.type}</span></td>
                         <td>${t.account_name || '—'}${t.type === 'expense' && t.transfer_account_name ? ' → ' + t.transfer_account_name : ''}</td>
                         <td>${t.description || '—'}${t.contact_name ? '<br><small style="color:var(--text-muted)">' + t.contact_name + '</small>' : ''}</td>
-                        <td class="money ${t.type === 'income' ? 'money-income' : 'money-expense'}">${formatMoney(t.amount)}</td>
+                        <td class="money ${t.type === 'income' ? 'money-income' : 'money-expense'}">${formatMoney(t.amount, t.currency)}</td>
                         <td>
-                            <span class="money">${formatMoney(t.allocated)}</span>
+                            <span class="money">${formatMoney(t.allocated, t.currency)}</span>
                             ${t.amount > 0 ? `<div class="alloc-bar"><div class="alloc-bar-fill ${t.allocated >= t.amount ? 'full' : ''}" style="width:${Math.min(100, (t.allocated / t.amount) * 100)}%"></div></div>` : ''}
                         </td>
                         <td><button class="btn-link" onclick="showTransactionLinks(${t.id})">Manage</button></td>
@@ -939,19 +1058,27 @@ async function showTransactionForm(id) {
                         <option value="income" ${data.type === 'income' ? 'selected' : ''}>Income</option>
                         <option value="expense" ${data.type === 'expense' ? 'selected' : ''}>Expense</option>
                         <option value="transfer" ${data.type === 'transfer' ? 'selected' : ''}>Transfer</option>
+                        <option value="adjustment" ${data.type === 'adjustment' ? 'selected' : ''}>Adjustment</option>
+                    </select>
+                </div>
+                <div class="form-group" id="adjustment-sign-group" style="display:${data.type === 'adjustment' ? 'block' : 'none'}">
+                    <label>Direction</label>
+                    <select class="form-control" name="sign">
+                        <option value="1" ${data.sign !== -1 ? 'selected' : ''}>Increase balance</option>
+                        <option value="-1" ${data.sign === -1 ? 'selected' : ''}>Decrease balance</option>
                     </select>
                 </div>
                 <div class="form-group">
                     <label>Amount (₹)</label>
-                    <input class="form-control" name="amount" type="number" step="0.01" value="${toRupees(data.amount)}" required>
+                    <input class="form-control" name="amount" type="number" step="${amountStep(data.currency)}" value="${toWhole(data.amount, data.currency)}" required>
                 </div>
             </div>
             <div class="form-row">
                 <div class="form-group">
                     <label>Account</label>
-                    <select class="form-control" name="account_id" required>
+                    <select class="form-control" name="account_id" required onchange="setTransactionAmountStep(this)">
                         <option value="">Select account</option>
-                        ${accounts.map(a => `<option value="${a.id}" ${data.account_id == a.id ? 'selected' : ''}>${a.name}</option>`).join('')}
+                        ${accounts.map(a => `<option value="${a.id}" data-currency="${esc(a.currency)}" ${data.account_id == a.id ? 'selected' : ''}>${a.name}</option>`).join('')}
                     </select>
                 </div>
                 <div class="form-group" id="transfer-account-group" style="display:${data.type === 'transfer' ? 'block' : 'none'}">
@@ -962,6 +1089,11 @@ async function showTransactionForm(id) {
                     </select>
                 </div>
             </div>
+            ${id ? '' : `
+            <div class="form-group" id="transfer-fee-group" style="display:${data.type === 'transfer' ? 'block' : 'none'}">
+                <label>Transfer Fee (₹, optional)</label>
+                <input class="form-control" name="fee_amount" type="number" step="${amountStep(data.currency)}" min="0" value="">
+            </div>`}
             <div class="form-row">
                 <div class="form-group">
                     <label>Date</label>
@@ -991,11 +1123,23 @@ async function showTransactionForm(id) {
     `);
 }
 
+// setTransactionAmountStep matches the amount inputs' step to the currency of
+// the account chosen in select.
+function setTransactionAmountStep(select) {
+    const option = select.selectedOptions[0];
+    const step = amountStep(option && option.dataset.currency);
+    select.form.amount.step = step;
+    if (select.form.fee_amount) select.form.fee_amount.step = step;
+}
+
 function toggleTransferField(type) {
     document.getElementById('transfer-account-group').style.display = type === 'transfer' ? 'block' : 'none';
+    const feeGroup = document.getElementById('transfer-fee-group');
+    if (feeGroup) feeGroup.style.display = type === 'transfer' ? 'block' : 'none';
+    document.getElementById('adjustment-sign-group').style.display = type === 'adjustment' ? 'block' : 'none';
 }
 
-async function saveTransaction(e, id) {
+async function saveTransaction(e, id, confirmedLarge = false) {
     e.preventDefault();
     const f = e.target;
     const body = JSON.stringify({
@@ -1007,6 +1151,9 @@ async function saveTransaction(e, id) {
         reference: f.reference.value || null,
         transfer_account_id: f.transfer_account_id.value ? parseInt(f.transfer_account_id.value) : null,
         contact_id: f.contact_id.value ? parseInt(f.contact_id.value) : null,
+        fee_amount: f.fee_amount && f.type.value === 'transfer' ? parseFloat(f.fee_amount.value || 0) : 0,
+        sign: f.type.value === 'adjustment' ? parseInt(f.sign.value) : null,
+        confirmed_large: confirmedLarge,
     });
     try {
         if (id) await api(`/transactions/${id}`, { method: 'PUT', body });
@@ -1014,6 +1161,9 @@ async function saveTransaction(e, id) {
         closeModal();
         renderTransactions();
     } catch (err) {
+        if (!confirmedLarge && err.message.includes('confirmed_large') && confirm(err.message.split(';')[0] + '. Save anyway?')) {
+            return saveTransaction(e, id, true);
+        }
         alert('Error: ' + err.message);
     }
 }
@@ -1039,20 +1189,21 @@ async function showTransactionLinks(txnId) {
 
     openModal(`Links — Transaction #${txnId}`, `
         <div style="margin-bottom:1rem">
-            <span class="money">${formatMoney(txn.amount)}</span> total ·
-            <span class="money money-income">${formatMoney(txn.allocated)}</span> allocated ·
-            <span class="money" style="color:var(--warning)">${formatMoney(unallocated)}</span> unallocated
+            <span class="money">${formatMoney(txn.amount, txn.currency)}</span> total ·
+            <span class="money money-income">${formatMoney(txn.allocated, txn.currency)}</span> allocated ·
+            <span class="money" style="color:var(--warning)">${formatMoney(unallocated, txn.currency)}</span> unallocated
             <div class="alloc-bar" style="margin-top:0.5rem"><div class="alloc-bar-fill ${txn.allocated >= txn.amount ? 'full' : ''}" style="width:${txn.amount > 0 ? Math.min(100, (txn.allocated / txn.amount) * 100) : 0}%"></div></div>
         </div>
 
         ${links.length > 0 ? `
         <table style="margin-bottom:1rem">
-            <thead><tr><th>Type</th><th>Document</th><th>Amount</th><th></th></tr></thead>
+            <thead><tr><th>Type</th><th>Document</th><th>Amount</th><th>Note</th><th></th></tr></thead>
             <tbody>
                 ${links.map(l => `<tr>
                     <td><span class="badge badge-${l.document_type}">${l.document_type}</span></td>
                     <td>#${l.document_id}</td>
                     <td class="money">${formatMoney(l.amount)}</td>
+                    <td>${esc(l.note || '')}</td>
                     <td><button class="btn btn-danger btn-sm" onclick="unlinkTransaction(${txnId}, ${l.id})">Remove</button></td>
                 </tr>`).join('')}
             </tbody>
@@ -1098,8 +1249,12 @@ async function showTransactionLinks(txnId) {
                 </div>
             </div>
             <div class="form-group">
-                <label>Amount (₹) — max ${formatMoney(unallocated)}</label>
-                <input class="form-control" name="amount" type="number" step="0.01" max="${toRupees(unallocated)}" required>
+                <label>Amount (₹) — max ${formatMoney(unallocated, txn.currency)}</label>
+                <input class="form-control" name="amount" type="number" step="${amountStep(txn.currency)}" max="${toWhole(unallocated, txn.currency)}" required>
+            </div>
+            <div class="form-group">
+                <label>Note</label>
+                <input class="form-control" name="note" maxlength="500" placeholder="e.g. holding back 10% retention">
             </div>
             <div class="form-actions">
                 <button type="submit" class="btn btn-primary btn-sm">Link</button>
@@ -1115,8 +1270,8 @@ async function showTransactionLinks(txnId) {
     `);
 }
 
-async function quickLinkTransaction(txnId, docType, docId, amountPaise) {
-    await postTransactionLink(txnId, docType, docId, amountPaise, () => showTransactionLinks(txnId));
+async function quickLinkTransaction(txnId, docType, docId, amount) {
+    await postTransactionLink(txnId, docType, docId, amount, () => showTransactionLinks(txnId));
 }
 
 function updateDocOptions(type) {
@@ -1139,14 +1294,16 @@ async function linkTransaction(e, txnId) {
     e.preventDefault();
     const f = e.target;
     try {
-        await api(`/transactions/${txnId}/links`, {
+        const link = await api(`/transactions/${txnId}/links`, {
             method: 'POST',
             body: JSON.stringify({
                 document_type: f.document_type.value,
                 document_id: parseInt(f.document_id.value),
                 amount: parseFloat(f.amount.value || 0),
+                note: f.note.value || null,
             }),
         });
+        if (link.warnings) alert(link.warnings.join('\n'));
         showTransactionLinks(txnId);
     } catch (err) {
         alert('Error: ' + err.message);
@@ -1253,7 +1410,7 @@ async function showRecurringPaymentForm(id) {
                 </div>
                 <div class="form-group">
                     <label>Amount (₹)</label>
-                    <input class="form-control" name="amount" type="number" step="0.01" value="${esc(toRupees(data.amount))}" required>
+                    <input class="form-control" name="amount" type="number" step="${amountStep()}" value="${esc(toWhole(data.amount))}" required>
                 </div>
             </div>
             <div class="form-row">
@@ -1413,7 +1570,7 @@ async function handleLogin(event) {
     btn.disabled = true;
     btn.textContent = 'Signing in…';
     try {
-        const res = await fetch('/api/v1/auth/login', {
+        const res = await fetch(API + '/auth/login', {
             method: 'POST',
             headers: { 'Content-Type': 'application/json' },
             body: JSON.stringify({
@@ -1427,7 +1584,7 @@ async function handleLogin(event) {
         }
         const token = json.data?.token || json.token;
         if (!token) throw new Error('No token received from server');
-        setToken(token);
+        setToken(token, json.data?.refresh_token);
         hideAuthOverlay();
         const { section, params } = getSection();
         renderSection(section, params);
@@ -1448,7 +1605,7 @@ async function handleRegister(event) {
     btn.disabled = true;
     btn.textContent = 'Creating account…';
     try {
-        const res = await fetch('/api/v1/auth/register', {
+        const res = await fetch(API + '/auth/register', {
             method: 'POST',
             headers: { 'Content-Type': 'application/json' },
             body: JSON.stringify({
@@ -1479,6 +1636,14 @@ async function handleRegister(event) {
 }
 
 function logout() {
+    const refresh = localStorage.getItem(REFRESH_TOKEN_KEY);
+    if (refresh) {
+        fetch(API + '/auth/logout', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ refresh_token: refresh }),
+        }).catch(() => {});
+    }
     clearToken();
     showAuthOverlay();
 }
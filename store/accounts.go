@@ -2,20 +2,30 @@ package store
 
 import (
 	"database/sql"
+	"errors"
 
 	"github.com/satheeshds/portal/models"
 )
 
-const accountSelectQuery = `SELECT id, name, type, opening_balance, created_at, updated_at,
+const accountSelectQuery = `SELECT id, name, type, COALESCE(currency, 'INR'), opening_balance, created_at, updated_at,
 	(opening_balance + 
 	 COALESCE((SELECT SUM(amount) FROM transactions WHERE account_id = accounts.id AND type = 'income'), 0) -
-	 COALESCE((SELECT SUM(amount) FROM transactions WHERE account_id = accounts.id AND type = 'expense'), 0)
-	) as balance
+	 COALESCE((SELECT SUM(amount) FROM transactions WHERE account_id = accounts.id AND type = 'expense'), 0) +
+	 COALESCE((SELECT SUM(amount * sign) FROM transactions WHERE account_id = accounts.id AND type = 'adjustment'), 0)
+	) as balance,
+	COALESCE((SELECT SUM(CASE type WHEN 'income' THEN amount WHEN 'expense' THEN -amount ELSE amount * sign END)
+		FROM transactions WHERE account_id = accounts.id AND type IN ('income', 'expense', 'adjustment')
+		AND NOT COALESCE(cleared, true)), 0) as pending
 	FROM accounts`
 
 func scanAccount(scanner interface{ Scan(...any) error }) (models.Account, error) {
 	var a models.Account
-	err := scanner.Scan(&a.ID, &a.Name, &a.Type, &a.OpeningBalance, &a.CreatedAt, &a.UpdatedAt, &a.Balance)
+	var pending models.Money
+	err := scanner.Scan(&a.ID, &a.Name, &a.Type, &a.Currency, &a.OpeningBalance, &a.CreatedAt, &a.UpdatedAt, &a.Balance, &pending)
+	a.SetBalanceFields()
+	a.ProjectedBalance = a.Balance
+	a.ClearedBalance = a.Balance - pending
+	a.MinorUnits = models.MinorUnits(a.Currency)
 	return a, err
 }
 
@@ -61,21 +71,32 @@ func (s *Store) GetAccount(id int) (models.Account, error) {
 	return scanAccount(s.db.QueryRow(accountSelectQuery+" WHERE accounts.id = ?", id))
 }
 
-// CreateAccount inserts a new account and returns the created record.
+// CreateAccount inserts a new account and returns the created record. An
+// empty currency defaults to models.DefaultCurrency.
 func (s *Store) CreateAccount(input models.AccountInput) (models.Account, error) {
-	var id int
-	err := s.db.QueryRow("INSERT INTO accounts (name, type, opening_balance) VALUES (?, ?, ?) RETURNING id",
-		input.Name, input.Type, input.OpeningBalance).Scan(&id)
+	id, err := insertAccount(s.db, input)
 	if err != nil {
 		return models.Account{}, err
 	}
 	return s.getAccountByID(id)
 }
 
+// insertAccount inserts the account CreateAccount creates and returns its id.
+func insertAccount(q rowQuerier, input models.AccountInput) (int, error) {
+	currency := input.Currency
+	if currency == "" {
+		currency = models.DefaultCurrency
+	}
+	return insertReturningID(q, "INSERT INTO accounts (name, type, currency, opening_balance) VALUES (?, ?, ?, ?)",
+		input.Name, input.Type, currency, input.OpeningBalance)
+}
+
 // UpdateAccount updates an existing account. Returns sql.ErrNoRows if not found.
+// An empty currency keeps the stored value.
 func (s *Store) UpdateAccount(id int, input models.AccountInput) (models.Account, error) {
-	res, err := s.db.Exec("UPDATE accounts SET name = ?, type = ?, opening_balance = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?",
-		input.Name, input.Type, input.OpeningBalance, id)
+	res, err := s.db.Exec(`UPDATE accounts SET name = ?, type = ?, currency = COALESCE(NULLIF(?, ''), currency, 'INR'),
+		opening_balance = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
+		input.Name, input.Type, input.Currency, input.OpeningBalance, id)
 	if err != nil {
 		return models.Account{}, err
 	}
@@ -94,7 +115,78 @@ func (s *Store) DeleteAccount(id int) error {
 	if n, _ := res.RowsAffected(); n == 0 {
 		return sql.ErrNoRows
 	}
-	return nil
+	return recordDeletion(s.db, "account", id)
+}
+
+// AccountStats summarises an account's transactions over a period. Transfers
+// between accounts are counted apart from inflow and outflow, so moving money
+// between the business's own accounts does not read as earning or spending it.
+type AccountStats struct {
+	AccountID        int                 `json:"account_id"`
+	From             string              `json:"from,omitempty"`
+	To               string              `json:"to,omitempty"`
+	Inflow           models.Money        `json:"inflow"`        // income, excluding transfers in
+	Outflow          models.Money        `json:"outflow"`       // expenses, excluding transfers out
+	Net              models.Money        `json:"net"`           // inflow minus outflow
+	TransfersIn      models.Money        `json:"transfers_in"`  // income legs of transfers from other accounts
+	TransfersOut     models.Money        `json:"transfers_out"` // expense legs of transfers to other accounts
+	Adjustments      models.Money        `json:"adjustments"`   // net of signed adjustments
+	TransactionCount int                 `json:"transaction_count"`
+	LargestIncome    *models.Transaction `json:"largest_income"`  // null when there is none
+	LargestExpense   *models.Transaction `json:"largest_expense"` // null when there is none
 }
 
+// GetAccountStats returns the stats of account id for transactions dated from
+// to to, either of which may be empty for an open range. Income and expenses
+// with a transfer account are counted as transfers and never as the largest
+// income or expense. Returns sql.ErrNoRows if the account does not exist.
+func (s *Store) GetAccountStats(id int, from, to string) (AccountStats, error) {
+	stats := AccountStats{AccountID: id, From: from, To: to}
+	if _, err := s.getAccountByID(id); err != nil {
+		return stats, err
+	}
 
+	where := "account_id = ?"
+	args := []any{id}
+	if from != "" {
+		where += " AND transaction_date >= ?"
+		args = append(args, from)
+	}
+	if to != "" {
+		where += " AND transaction_date <= ?"
+		args = append(args, to)
+	}
+
+	err := s.db.QueryRow(`SELECT
+		COALESCE(SUM(CASE WHEN type = 'income' AND transfer_account_id IS NULL THEN amount END), 0),
+		COALESCE(SUM(CASE WHEN type = 'expense' AND transfer_account_id IS NULL THEN amount END), 0),
+		COALESCE(SUM(CASE WHEN type = 'income' AND transfer_account_id IS NOT NULL THEN amount END), 0),
+		COALESCE(SUM(CASE WHEN type = 'expense' AND transfer_account_id IS NOT NULL THEN amount END), 0),
+		COALESCE(SUM(CASE WHEN type = 'adjustment' THEN amount * sign END), 0),
+		COUNT(*)
+		FROM transactions WHERE `+where, args...).Scan(&stats.Inflow, &stats.Outflow, &stats.TransfersIn, &stats.TransfersOut,
+		&stats.Adjustments, &stats.TransactionCount)
+	if err != nil {
+		return stats, err
+	}
+	stats.Net = stats.Inflow - stats.Outflow
+
+	largest := func(typ string) (*models.Transaction, error) {
+		var txnID int
+		err := s.db.QueryRow(`SELECT id FROM transactions WHERE `+where+` AND type = ? AND transfer_account_id IS NULL
+			ORDER BY amount DESC, id LIMIT 1`, append(args, typ)...).Scan(&txnID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		t, err := s.getTransactionByID(txnID)
+		return &t, err
+	}
+	if stats.LargestIncome, err = largest("income"); err != nil {
+		return stats, err
+	}
+	stats.LargestExpense, err = largest("expense")
+	return stats, err
+}
@@ -0,0 +1,142 @@
+package store
+
+import "github.com/satheeshds/portal/models"
+
+// AllocationBucket counts the records in one allocation state and totals what
+// is left to allocate on them, or by how much they are over-allocated.
+type AllocationBucket struct {
+	Count int          `json:"count"`
+	Total models.Money `json:"total"`
+}
+
+// CurrencyAllocationBucket is an AllocationBucket of transactions on accounts
+// in one currency.
+type CurrencyAllocationBucket struct {
+	Currency string `json:"currency"`
+	AllocationBucket
+}
+
+// OverAllocation is a record whose links allocate more than its amount.
+type OverAllocation struct {
+	Type      string       `json:"type"` // bill, invoice, payout, or transaction
+	ID        int          `json:"id"`
+	Reference string       `json:"reference"` // bill or invoice number, UTR, or bank reference
+	Amount    models.Money `json:"amount"`    // what can be allocated: a payout's net of its variance, twice an offset's amount
+	Allocated models.Money `json:"allocated"`
+	Excess    models.Money `json:"excess"`
+}
+
+// AllocationHealth is the allocation work outstanding across the books.
+type AllocationHealth struct {
+	UnallocatedTransactions []CurrencyAllocationBucket `json:"unallocated_transactions"`
+	PartialBills            AllocationBucket           `json:"partial_bills"`
+	PartialInvoices         AllocationBucket           `json:"partial_invoices"`
+	UnsettledPayouts        AllocationBucket           `json:"unsettled_payouts"`
+	OverAllocated           AllocationBucket           `json:"over_allocated"`
+	OverAllocatedItems      []OverAllocation           `json:"over_allocated_items"`
+}
+
+// linkedToDocument sums the links to the document of docType aliased d.
+func linkedToDocument(docType string) string {
+	return `COALESCE((SELECT SUM(td.amount) FROM transaction_documents td
+			WHERE td.document_type = '` + docType + `' AND td.document_id = d.id), 0)`
+}
+
+// allocationHealthDocuments selects the kind, id, reference, allocatable
+// amount, and allocated amount of every bill, invoice, and payout that is not
+// cancelled.
+var allocationHealthDocuments = `SELECT 'bill' AS kind, d.id, COALESCE(d.bill_number, '') AS ref, d.amount, ` + linkedToDocument("bill") + ` AS allocated
+		FROM bills d WHERE d.status <> 'cancelled'
+	UNION ALL SELECT 'invoice', d.id, COALESCE(d.invoice_number, ''), d.amount, ` + linkedToDocument("invoice") + `
+		FROM invoices d WHERE d.status <> 'cancelled'
+	UNION ALL SELECT 'payout', d.id, COALESCE(d.utr_number, ''), d.final_payout_amt - COALESCE(d.variance_amount, 0), ` + linkedToDocument("payout") + `
+		FROM payouts d`
+
+// allocationHealthTransactions selects the id, reference, allocatable amount,
+// allocated amount, and account currency of every transaction settled against
+// documents: income and expense other than transfer legs and payout postings,
+// and offsets, which settle their amount on both a bill and an invoice.
+const allocationHealthTransactions = `SELECT t.id, COALESCE(t.reference, '') AS ref, t.type,
+		CASE t.type WHEN 'offset' THEN 2 * t.amount ELSE t.amount END AS amount,
+		COALESCE((SELECT SUM(td.amount) FROM transaction_documents td WHERE td.transaction_id = t.id), 0) AS allocated,
+		COALESCE(a.currency, 'INR') AS currency
+	FROM transactions t LEFT JOIN accounts a ON a.id = t.account_id
+	WHERE (t.type IN ('income', 'expense') AND t.transfer_account_id IS NULL AND COALESCE(t.source, '') <> '` + payoutPostingSource + `')
+		OR t.type = 'offset'`
+
+// GetAllocationHealth summarizes what allocation work is outstanding:
+// transactions with an unallocated balance, per account currency; bills and
+// invoices part paid; payouts not fully settled by bank credits and any
+// recorded variance; and records allocated beyond their amount, which the
+// link checks prevent but older data may hold. Differences within
+// AllocationTolerance are treated as settled. Cancelled documents are left
+// out.
+func (s *Store) GetAllocationHealth() (AllocationHealth, error) {
+	h := AllocationHealth{UnallocatedTransactions: []CurrencyAllocationBucket{}, OverAllocatedItems: []OverAllocation{}}
+
+	rows, err := s.db.Query(`SELECT kind, COUNT(*), COALESCE(SUM(amount - allocated), 0)
+		FROM (`+allocationHealthDocuments+`) x
+		WHERE amount - allocated > ? AND (kind = 'payout' OR allocated > 0)
+		GROUP BY kind`, AllocationTolerance)
+	if err != nil {
+		return h, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var kind string
+		var b AllocationBucket
+		if err := rows.Scan(&kind, &b.Count, &b.Total); err != nil {
+			return h, err
+		}
+		switch kind {
+		case "bill":
+			h.PartialBills = b
+		case "invoice":
+			h.PartialInvoices = b
+		case "payout":
+			h.UnsettledPayouts = b
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return h, err
+	}
+
+	rows, err = s.db.Query(`SELECT currency, COUNT(*), COALESCE(SUM(amount - allocated), 0)
+		FROM (`+allocationHealthTransactions+`) x
+		WHERE type <> 'offset' AND amount - allocated > ?
+		GROUP BY currency ORDER BY currency`, AllocationTolerance)
+	if err != nil {
+		return h, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var b CurrencyAllocationBucket
+		if err := rows.Scan(&b.Currency, &b.Count, &b.Total); err != nil {
+			return h, err
+		}
+		h.UnallocatedTransactions = append(h.UnallocatedTransactions, b)
+	}
+	if err := rows.Err(); err != nil {
+		return h, err
+	}
+
+	rows, err = s.db.Query(`SELECT kind, id, ref, amount, allocated FROM (`+allocationHealthDocuments+`
+		UNION ALL SELECT 'transaction', id, ref, amount, allocated FROM (`+allocationHealthTransactions+`) t) x
+		WHERE allocated > amount + ?
+		ORDER BY kind, id`, AllocationTolerance)
+	if err != nil {
+		return h, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var o OverAllocation
+		if err := rows.Scan(&o.Type, &o.ID, &o.Reference, &o.Amount, &o.Allocated); err != nil {
+			return h, err
+		}
+		o.Excess = o.Allocated - o.Amount
+		h.OverAllocatedItems = append(h.OverAllocatedItems, o)
+		h.OverAllocated.Count++
+		h.OverAllocated.Total += o.Excess
+	}
+	return h, rows.Err()
+}
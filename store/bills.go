@@ -3,17 +3,20 @@ package store
 import (
 	"database/sql"
 	"strings"
+	"time"
 
 	"github.com/satheeshds/portal/db"
 	"github.com/satheeshds/portal/models"
 )
 
-const billSelectQuery = `SELECT b.id, b.contact_id, b.bill_number, b.issue_date, b.due_date, b.amount,
+const billSelectQuery = `SELECT b.id, b.contact_id, b.category_id, COALESCE(b.bill_number, ''), b.issue_date, b.due_date, b.amount,
 		b.status, b.file_url, b.notes, b.created_at, b.updated_at,
 		c.name,
+		cat.name,
 		COALESCE((SELECT SUM(td.amount) FROM transaction_documents td WHERE td.document_type = 'bill' AND td.document_id = b.id), 0)
 		FROM bills b
-		LEFT JOIN contacts c ON b.contact_id = c.id`
+		LEFT JOIN contacts c ON b.contact_id = c.id
+		LEFT JOIN categories cat ON b.category_id = cat.id`
 
 // BillLink represents a linked transaction payment for a bill.
 type BillLink struct {
@@ -26,11 +29,13 @@ type BillLink struct {
 
 func scanBill(scanner interface{ Scan(...any) error }) (models.Bill, error) {
 	var b models.Bill
-	err := scanner.Scan(&b.ID, &b.ContactID, &b.BillNumber, &b.IssueDate, &b.DueDate,
+	err := scanner.Scan(&b.ID, &b.ContactID, &b.CategoryID, &b.BillNumber, &b.IssueDate, &b.DueDate,
 		&b.Amount, &b.Status, &b.FileURL, &b.Notes, &b.CreatedAt, &b.UpdatedAt,
-		&b.ContactName, &b.Allocated)
+		&b.ContactName, &b.CategoryName, &b.Allocated)
 	if err == nil {
 		b.Unallocated = models.Money(int64(b.Amount) - int64(b.Allocated))
+		b.AllocatedPct = models.AllocatedPct(b.Allocated, b.Amount)
+		b.DaysToDue = b.DueDate.DaysUntil(time.Now())
 	}
 	return b, err
 }
@@ -61,7 +66,23 @@ func insertBillItems(tx *db.PortalTx, billID int, items []models.BillItemInput)
 }
 
 // ListBills returns bills filtered by the provided parameters (all may be empty).
-func (s *Store) ListBills(status, contactID, from, to, search string) ([]models.Bill, error) {
+// Cancelled bills are left out unless includeCancelled is set or status asks for them.
+func (s *Store) ListBills(status, contactID, categoryID, from, to, search string, includeCancelled bool) ([]models.Bill, error) {
+	bills := []models.Bill{}
+	err := s.EachBill(status, contactID, categoryID, from, to, search, includeCancelled, func(b models.Bill) error {
+		bills = append(bills, b)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bills, nil
+}
+
+// EachBill calls fn for each bill matching the same filters as ListBills,
+// in the same order, without holding the whole result in memory. It stops at
+// the first error returned by fn.
+func (s *Store) EachBill(status, contactID, categoryID, from, to, search string, includeCancelled bool, fn func(models.Bill) error) error {
 	query := billSelectQuery
 	var conditions []string
 	var args []any
@@ -69,11 +90,17 @@ func (s *Store) ListBills(status, contactID, from, to, search string) ([]models.
 	if status != "" {
 		conditions = append(conditions, "b.status = ?")
 		args = append(args, status)
+	} else if !includeCancelled {
+		conditions = append(conditions, "b.status <> 'cancelled'")
 	}
 	if contactID != "" {
 		conditions = append(conditions, "b.contact_id = ?")
 		args = append(args, contactID)
 	}
+	if categoryID != "" {
+		conditions = append(conditions, "b.category_id = ?")
+		args = append(args, categoryID)
+	}
 	if from != "" {
 		conditions = append(conditions, "b.issue_date >= ?")
 		args = append(args, from)
@@ -95,23 +122,21 @@ func (s *Store) ListBills(status, contactID, from, to, search string) ([]models.
 
 	rows, err := s.db.Query(query, args...)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
 
-	var bills []models.Bill
 	for rows.Next() {
 		b, err := scanBill(rows)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		b.Items = []models.BillItem{}
-		bills = append(bills, b)
-	}
-	if bills == nil {
-		bills = []models.Bill{}
+		if err := fn(b); err != nil {
+			return err
+		}
 	}
-	return bills, nil
+	return rows.Err()
 }
 
 // GetBill returns a single bill by ID, including its line items. Returns sql.ErrNoRows if not found.
@@ -127,25 +152,33 @@ func (s *Store) CreateBill(input models.BillInput) (models.Bill, error) {
 	}
 	defer func() { _ = tx.Rollback() }()
 
-	var id int
-	err = tx.QueryRow(`INSERT INTO bills (contact_id, bill_number, issue_date, due_date, amount, status, file_url, notes)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?) RETURNING id`,
-		input.ContactID, input.BillNumber, input.IssueDate, input.DueDate,
-		input.Amount, input.Status, input.FileURL, input.Notes).Scan(&id)
+	id, err := insertBill(tx, input)
 	if err != nil {
 		return models.Bill{}, err
 	}
 
-	if err := insertBillItems(tx, id, input.Items); err != nil {
-		return models.Bill{}, err
-	}
-
 	if err := tx.Commit(); err != nil {
 		return models.Bill{}, err
 	}
 	return s.getBillByID(id)
 }
 
+// insertBill inserts the bill CreateBill creates, with its items, refreshes
+// its contact's balance, and returns its id.
+func insertBill(tx *db.PortalTx, input models.BillInput) (int, error) {
+	id, err := insertReturningID(tx, `INSERT INTO bills (contact_id, category_id, bill_number, issue_date, due_date, amount, status, file_url, notes)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		input.ContactID, input.CategoryID, nullIfEmpty(input.BillNumber), input.IssueDate, input.DueDate,
+		input.Amount, input.Status, input.FileURL, input.Notes)
+	if err != nil {
+		return 0, err
+	}
+	if err := insertBillItems(tx, id, input.Items); err != nil {
+		return 0, err
+	}
+	return id, refreshContactBalances(tx, input.ContactID)
+}
+
 // UpdateBill updates an existing bill and its items. Returns sql.ErrNoRows if not found.
 func (s *Store) UpdateBill(id int, input models.BillInput) (models.Bill, error) {
 	tx, err := s.db.Begin()
@@ -154,9 +187,13 @@ func (s *Store) UpdateBill(id int, input models.BillInput) (models.Bill, error)
 	}
 	defer func() { _ = tx.Rollback() }()
 
-	res, err := tx.Exec(`UPDATE bills SET contact_id = ?, bill_number = ?, issue_date = ?, due_date = ?,
+	previousContactID, err := documentContactID(tx, "bills", id)
+	if err != nil {
+		return models.Bill{}, err
+	}
+	res, err := tx.Exec(`UPDATE bills SET contact_id = ?, category_id = ?, bill_number = ?, issue_date = ?, due_date = ?,
 		amount = ?, status = ?, file_url = ?, notes = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
-		input.ContactID, input.BillNumber, input.IssueDate, input.DueDate,
+		input.ContactID, input.CategoryID, nullIfEmpty(input.BillNumber), input.IssueDate, input.DueDate,
 		input.Amount, input.Status, input.FileURL, input.Notes, id)
 	if err != nil {
 		return models.Bill{}, err
@@ -173,6 +210,9 @@ func (s *Store) UpdateBill(id int, input models.BillInput) (models.Bill, error)
 			return models.Bill{}, err
 		}
 	}
+	if err := refreshContactBalances(tx, previousContactID, input.ContactID); err != nil {
+		return models.Bill{}, err
+	}
 
 	if err := tx.Commit(); err != nil {
 		return models.Bill{}, err
@@ -180,6 +220,20 @@ func (s *Store) UpdateBill(id int, input models.BillInput) (models.Bill, error)
 	return s.getBillByID(id)
 }
 
+// VoidBill marks a bill as cancelled, keeping it and its items for the record.
+// Returns sql.ErrNoRows if not found.
+func (s *Store) VoidBill(id int) error {
+	res, err := s.db.Exec("UPDATE bills SET status = 'cancelled', updated_at = CURRENT_TIMESTAMP WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	s.refreshDocumentContact("bills", id)
+	return nil
+}
+
 // DeleteBill removes a bill and its items/links. Returns sql.ErrNoRows if not found.
 func (s *Store) DeleteBill(id int) error {
 	tx, err := s.db.Begin()
@@ -188,6 +242,13 @@ func (s *Store) DeleteBill(id int) error {
 	}
 	defer func() { _ = tx.Rollback() }()
 
+	contactID, err := documentContactID(tx, "bills", id)
+	if err != nil {
+		return err
+	}
+	if err := touchLinkedTransactions(tx, "bill", id); err != nil {
+		return err
+	}
 	if _, err := tx.Exec("DELETE FROM transaction_documents WHERE document_type = 'bill' AND document_id = ?", id); err != nil {
 		return err
 	}
@@ -202,18 +263,25 @@ func (s *Store) DeleteBill(id int) error {
 	if n, _ := res.RowsAffected(); n == 0 {
 		return sql.ErrNoRows
 	}
+	if err := recordDeletion(tx, "bill", id); err != nil {
+		return err
+	}
+	if err := refreshContactBalances(tx, contactID); err != nil {
+		return err
+	}
 
 	return tx.Commit()
 }
 
-// GetBillLinks returns all transaction links for the given bill.
-func (s *Store) GetBillLinks(id int) ([]BillLink, error) {
-	rows, err := s.db.Query(`SELECT td.id, td.transaction_id, td.document_type, td.document_id, td.amount, td.created_at,
+// GetBillLinks returns transaction links for the given bill, ordered and paginated by page.
+func (s *Store) GetBillLinks(id int, page LinkPage) ([]BillLink, error) {
+	suffix, args := page.clause("td")
+	rows, err := s.db.Query(`SELECT td.id, td.transaction_id, td.document_type, td.document_id, td.amount, td.note, td.created_at,
 		COALESCE(t.transaction_date, ''), COALESCE(t.description, ''), COALESCE(t.reference, ''), a.name as account_name
 		FROM transaction_documents td
 		JOIN transactions t ON td.transaction_id = t.id
 		JOIN accounts a ON t.account_id = a.id
-		WHERE td.document_type = 'bill' AND td.document_id = ?`, id)
+		WHERE td.document_type = 'bill' AND td.document_id = ?`+suffix, append([]any{id}, args...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -222,7 +290,7 @@ func (s *Store) GetBillLinks(id int) ([]BillLink, error) {
 	var links []BillLink
 	for rows.Next() {
 		var l BillLink
-		if err := rows.Scan(&l.ID, &l.TransactionID, &l.DocumentType, &l.DocumentID, &l.Amount, &l.CreatedAt,
+		if err := rows.Scan(&l.ID, &l.TransactionID, &l.DocumentType, &l.DocumentID, &l.Amount, &l.Note, &l.CreatedAt,
 			&l.TransactionDate, &l.Description, &l.Reference, &l.AccountName); err != nil {
 			return nil, err
 		}
@@ -270,10 +338,9 @@ func (s *Store) ListBillItems(billID int) ([]models.BillItem, error) {
 
 // CreateBillItem inserts a new line item for a bill and returns it.
 func (s *Store) CreateBillItem(billID int, input models.BillItemInput) (models.BillItem, error) {
-	var itemID int
-	err := s.db.QueryRow(`INSERT INTO bill_items (bill_id, description, quantity, unit, unit_price, amount)
-		VALUES (?, ?, ?, ?, ?, ?) RETURNING id`,
-		billID, input.Description, input.Quantity, input.Unit, input.UnitPrice, input.Amount).Scan(&itemID)
+	itemID, err := insertReturningID(s.db, `INSERT INTO bill_items (bill_id, description, quantity, unit, unit_price, amount)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		billID, input.Description, input.Quantity, input.Unit, input.UnitPrice, input.Amount)
 	if err != nil {
 		return models.BillItem{}, err
 	}
@@ -0,0 +1,100 @@
+package store
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/satheeshds/portal/models"
+)
+
+const categorySelectQuery = `SELECT id, name, type, created_at, updated_at,
+	(SELECT COUNT(*) FROM transactions t WHERE t.category_id = categories.id),
+	(SELECT COUNT(*) FROM bills b WHERE b.category_id = categories.id)
+	FROM categories`
+
+func scanCategory(scanner interface{ Scan(...any) error }) (models.Category, error) {
+	var c models.Category
+	err := scanner.Scan(&c.ID, &c.Name, &c.Type, &c.CreatedAt, &c.UpdatedAt, &c.Transactions, &c.Bills)
+	return c, err
+}
+
+// ListCategories returns categories ordered by name, optionally filtered by
+// type and a search term.
+func (s *Store) ListCategories(categoryType, search string) ([]models.Category, error) {
+	query := categorySelectQuery
+	var conditions []string
+	var args []any
+	if categoryType != "" {
+		conditions = append(conditions, "type = ?")
+		args = append(args, categoryType)
+	}
+	if search != "" {
+		conditions = append(conditions, "name LIKE ?")
+		args = append(args, "%"+search+"%")
+	}
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY name"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []models.Category{}
+	for rows.Next() {
+		c, err := scanCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, c)
+	}
+	return categories, rows.Err()
+}
+
+// GetCategory returns a single category by ID. Returns sql.ErrNoRows if not found.
+func (s *Store) GetCategory(id int) (models.Category, error) {
+	return scanCategory(s.db.QueryRow(categorySelectQuery+" WHERE id = ?", id))
+}
+
+// FindCategoryByName returns the category with the given name, ignoring case.
+// Returns sql.ErrNoRows if there is none.
+func (s *Store) FindCategoryByName(name string) (models.Category, error) {
+	return scanCategory(s.db.QueryRow(categorySelectQuery+" WHERE LOWER(name) = LOWER(?) ORDER BY id LIMIT 1", name))
+}
+
+// CreateCategory inserts a new category and returns the created record.
+func (s *Store) CreateCategory(input models.CategoryInput) (models.Category, error) {
+	id, err := insertReturningID(s.db, "INSERT INTO categories (name, type) VALUES (?, ?)", input.Name, input.Type)
+	if err != nil {
+		return models.Category{}, err
+	}
+	return s.GetCategory(id)
+}
+
+// UpdateCategory renames or retypes a category. Returns sql.ErrNoRows if not found.
+func (s *Store) UpdateCategory(id int, input models.CategoryInput) (models.Category, error) {
+	res, err := s.db.Exec("UPDATE categories SET name = ?, type = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?",
+		input.Name, input.Type, id)
+	if err != nil {
+		return models.Category{}, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.Category{}, sql.ErrNoRows
+	}
+	return s.GetCategory(id)
+}
+
+// DeleteCategory removes a category. Returns sql.ErrNoRows if not found.
+func (s *Store) DeleteCategory(id int) error {
+	res, err := s.db.Exec("DELETE FROM categories WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return recordDeletion(s.db, "category", id)
+}
@@ -0,0 +1,134 @@
+package store
+
+import "github.com/satheeshds/portal/models"
+
+// changeTimeLayout formats a since cursor the way TIMESTAMP columns compare,
+// in UTC without a zone.
+const changeTimeLayout = "2006-01-02 15:04:05.999999"
+
+// recordDeletion leaves a tombstone for resource id in the deletions table,
+// which GET /changes reports to sync clients.
+func recordDeletion(e execer, resource string, id int) error {
+	_, err := e.Exec("INSERT INTO deletions (resource, resource_id, deleted_at) VALUES (?, ?, CURRENT_TIMESTAMP)", resource, id)
+	return err
+}
+
+// touchTransaction bumps updated_at on transaction id after its links change,
+// so GET /changes reports its new allocated amount.
+func touchTransaction(e execer, id int) error {
+	_, err := e.Exec("UPDATE transactions SET updated_at = CURRENT_TIMESTAMP WHERE id = ?", id)
+	return err
+}
+
+// touchLinkedTransactions bumps updated_at on the transactions linked to
+// document docID of docType, ahead of its links being removed.
+func touchLinkedTransactions(e execer, docType string, docID int) error {
+	_, err := e.Exec(`UPDATE transactions SET updated_at = CURRENT_TIMESTAMP
+		WHERE id IN (SELECT transaction_id FROM transaction_documents WHERE document_type = ? AND document_id = ?)`, docType, docID)
+	return err
+}
+
+// Deletion is the tombstone of a deleted record.
+type Deletion struct {
+	Resource  string           `json:"resource"` // account, contact, outlet, category, bill, invoice, transaction, payout, or recurring_payment
+	ID        int              `json:"id"`
+	DeletedAt models.Timestamp `json:"deleted_at"`
+}
+
+// Changes is every record created, updated, or deleted after Since, grouped
+// by type, each group ordered by updated_at.
+type Changes struct {
+	Since             *models.Timestamp         `json:"since"`       // null for a full sync
+	ServerTime        models.Timestamp          `json:"server_time"` // pass as since on the next call
+	Accounts          []models.Account          `json:"accounts"`
+	Contacts          []models.Contact          `json:"contacts"`
+	Outlets           []models.Outlet           `json:"outlets"`
+	Categories        []models.Category         `json:"categories"`
+	Bills             []models.Bill             `json:"bills"`
+	Invoices          []models.Invoice          `json:"invoices"`
+	Transactions      []models.Transaction      `json:"transactions"`
+	Payouts           []models.Payout           `json:"payouts"`
+	RecurringPayments []models.RecurringPayment `json:"recurring_payments"`
+	Deleted           []Deletion                `json:"deleted"`
+}
+
+// changedRows runs selectQuery for the rows whose updated column is after
+// since (every row when since is empty), ordered by it then by idColumn.
+func changedRows[T any](s *Store, selectQuery, updated, idColumn, since string, scan func(interface{ Scan(...any) error }) (T, error)) ([]T, error) {
+	query := selectQuery
+	var args []any
+	if since != "" {
+		query += " WHERE " + updated + " > CAST(? AS TIMESTAMP)"
+		args = append(args, since)
+	}
+	query += " ORDER BY " + updated + ", " + idColumn
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	list := []T{}
+	for rows.Next() {
+		v, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+	}
+	return list, rows.Err()
+}
+
+// ListChanges returns the records changed after since, or all of them when
+// since is nil, plus tombstones of those deleted. ServerTime is read from the
+// database clock before any rows. A write is stamped with the time its
+// database transaction began, not when it committed, so one still open when
+// ServerTime is read can commit with an earlier stamp. Reading from
+// ChangesOverlap before since catches such writes as long as they commit
+// within that window; records changed inside it are reported again.
+func (s *Store) ListChanges(since *models.Timestamp) (Changes, error) {
+	c := Changes{Since: since}
+	if err := s.db.QueryRow("SELECT CAST(CURRENT_TIMESTAMP AS TIMESTAMP)").Scan(&c.ServerTime); err != nil {
+		return c, err
+	}
+	var cursor string
+	if since != nil {
+		cursor = since.UTC().Add(-ChangesOverlap).Format(changeTimeLayout)
+	}
+
+	var err error
+	if c.Accounts, err = changedRows(s, accountSelectQuery, "accounts.updated_at", "accounts.id", cursor, scanAccount); err != nil {
+		return c, err
+	}
+	if c.Contacts, err = changedRows(s, contactSelectQuery, "contacts.updated_at", "contacts.id", cursor, scanContact); err != nil {
+		return c, err
+	}
+	if c.Outlets, err = changedRows(s, outletSelectQuery, "outlets.updated_at", "outlets.id", cursor, scanOutlet); err != nil {
+		return c, err
+	}
+	if c.Categories, err = changedRows(s, categorySelectQuery, "categories.updated_at", "categories.id", cursor, scanCategory); err != nil {
+		return c, err
+	}
+	if c.Bills, err = changedRows(s, billSelectQuery, "b.updated_at", "b.id", cursor, scanBill); err != nil {
+		return c, err
+	}
+	if c.Invoices, err = changedRows(s, invoiceSelectQuery, "i.updated_at", "i.id", cursor, scanInvoice); err != nil {
+		return c, err
+	}
+	if c.Transactions, err = changedRows(s, txnSelectQuery, "t.updated_at", "t.id", cursor, scanTransaction); err != nil {
+		return c, err
+	}
+	if c.Payouts, err = changedRows(s, payoutSelectQuery, "COALESCE(payouts.updated_at, payouts.created_at)", "payouts.id", cursor, scanPayout); err != nil {
+		return c, err
+	}
+	if c.RecurringPayments, err = changedRows(s, recurringPaymentSelectQuery, "r.updated_at", "r.id", cursor, scanRecurringPayment); err != nil {
+		return c, err
+	}
+	c.Deleted, err = changedRows(s, "SELECT resource, resource_id, deleted_at FROM deletions", "deleted_at", "id", cursor,
+		func(scanner interface{ Scan(...any) error }) (Deletion, error) {
+			var d Deletion
+			err := scanner.Scan(&d.Resource, &d.ID, &d.DeletedAt)
+			return d, err
+		})
+	return c, err
+}
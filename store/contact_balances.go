@@ -0,0 +1,73 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"strings"
+)
+
+// contactBalanceUpdate recomputes the cached balance columns of contacts from
+// their documents, bumping updated_at on those whose balances change so GET
+// /changes reports them.
+const contactBalanceUpdate = `UPDATE contacts SET
+	cached_allocated = ` + contactAllocatedExpr + `,
+	cached_balance = (` + contactTotalExpr + `) - (` + contactAllocatedExpr + `),
+	updated_at = CASE WHEN cached_allocated IS DISTINCT FROM (` + contactAllocatedExpr + `)
+		OR cached_balance IS DISTINCT FROM (` + contactTotalExpr + `) - (` + contactAllocatedExpr + `)
+		THEN CURRENT_TIMESTAMP ELSE updated_at END`
+
+// refreshContactBalances recomputes the cached balances of the given
+// contacts, skipping nil ids. Writes that change a bill or invoice amount,
+// status, or contact, or the links allocated to one, call it for the
+// contacts involved.
+func refreshContactBalances(e execer, contactIDs ...*int) error {
+	var args []any
+	for _, id := range contactIDs {
+		if id != nil {
+			args = append(args, *id)
+		}
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", ")
+	_, err := e.Exec(contactBalanceUpdate+" WHERE id IN ("+placeholders+")", args...)
+	return err
+}
+
+// documentContactID returns the contact_id of row id of table (bills or
+// invoices), or nil when it has none or does not exist.
+func documentContactID(q rowQuerier, table string, id int) (*int, error) {
+	var contactID *int
+	err := q.QueryRow("SELECT contact_id FROM "+table+" WHERE id = ?", id).Scan(&contactID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	return contactID, nil
+}
+
+// refreshDocumentContact refreshes the cached balances of the contact of a
+// bill or invoice outside any transaction, logging rather than failing, as
+// the write it follows has already committed. POST /admin/recalc-contacts
+// repairs a refresh that failed.
+func (s *Store) refreshDocumentContact(table string, id int) {
+	contactID, err := documentContactID(s.db, table, id)
+	if err == nil {
+		err = refreshContactBalances(s.db, contactID)
+	}
+	if err != nil {
+		slog.Warn("failed to refresh contact balances", "table", table, "id", id, "error", err)
+	}
+}
+
+// RecalcContactBalances rebuilds the cached balances of every contact and
+// returns how many contacts were updated.
+func (s *Store) RecalcContactBalances() (int, error) {
+	res, err := s.db.Exec(contactBalanceUpdate)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
@@ -0,0 +1,101 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/satheeshds/portal/models"
+)
+
+// ContactImportRow is the outcome of one row of a contact import.
+type ContactImportRow struct {
+	Row       int    `json:"row"`
+	Name      string `json:"name"`
+	Status    string `json:"status"`               // created, merged, skipped, or error
+	ContactID *int   `json:"contact_id,omitempty"` // the created contact, or the existing one it matched
+	Error     string `json:"error,omitempty"`
+}
+
+// ContactImportResult summarises a contact import.
+type ContactImportResult struct {
+	Created int                `json:"created"`
+	Merged  int                `json:"merged"`  // existing contacts whose blank fields were filled in
+	Skipped int                `json:"skipped"` // rows matching an existing contact with nothing to add
+	Errors  int                `json:"errors"`
+	Rows    []ContactImportRow `json:"rows"` // every data row, in file order
+}
+
+// Add appends row to the result and counts it under its status.
+func (r *ContactImportResult) Add(row ContactImportRow) {
+	switch row.Status {
+	case "created":
+		r.Created++
+	case "merged":
+		r.Merged++
+	case "skipped":
+		r.Skipped++
+	case "error":
+		r.Errors++
+	}
+	r.Rows = append(r.Rows, row)
+}
+
+// ImportContacts creates a contact for each line, which must already be
+// validated. A line whose name (ignoring case and surrounding space) and type
+// match an existing contact, including one created earlier in the same
+// import, is a duplicate: it is skipped, or with merge its email, phone,
+// gstin, and pan fill those the existing contact lacks. All writes run in one
+// transaction, so a database error leaves nothing imported.
+func (s *Store) ImportContacts(lines []models.ContactLine, merge bool) ([]ContactImportRow, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	rows := make([]ContactImportRow, 0, len(lines))
+	for _, line := range lines {
+		in := line.Input
+		row := ContactImportRow{Row: line.Row, Name: in.Name}
+
+		var id int
+		var email, phone, gstin, pan *string
+		err := tx.QueryRow(`SELECT id, email, phone, gstin, pan FROM contacts
+			WHERE lower(trim(name)) = ? AND type = ? ORDER BY id LIMIT 1`,
+			strings.ToLower(strings.TrimSpace(in.Name)), in.Type).Scan(&id, &email, &phone, &gstin, &pan)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			id, err = insertReturningID(tx, "INSERT INTO contacts (name, type, email, phone, gstin, pan, credit_limit) VALUES (?, ?, ?, ?, ?, ?, ?)",
+				in.Name, in.Type, in.Email, in.Phone, in.GSTIN, in.PAN, in.CreditLimit)
+			if err != nil {
+				return nil, err
+			}
+			row.Status = "created"
+		case err != nil:
+			return nil, err
+		case merge && (fills(email, in.Email) || fills(phone, in.Phone) || fills(gstin, in.GSTIN) || fills(pan, in.PAN)):
+			if _, err := tx.Exec(`UPDATE contacts SET email = COALESCE(email, ?), phone = COALESCE(phone, ?),
+				gstin = COALESCE(gstin, ?), pan = COALESCE(pan, ?), updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
+				in.Email, in.Phone, in.GSTIN, in.PAN, id); err != nil {
+				return nil, err
+			}
+			row.Status = "merged"
+		default:
+			row.Status = "skipped"
+		}
+		row.ContactID = &id
+		rows = append(rows, row)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// fills reports whether merging value into a stored field would change it:
+// the field is empty and value is not.
+func fills(stored, value *string) bool {
+	return stored == nil && value != nil
+}
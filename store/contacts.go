@@ -7,29 +7,58 @@ import (
 	"github.com/satheeshds/portal/models"
 )
 
-const contactSelectQuery = `SELECT id, name, type, email, phone, created_at, updated_at,
-	CASE 
-		WHEN type = 'vendor' THEN COALESCE((SELECT SUM(amount) FROM bills WHERE contact_id = contacts.id), 0)
-		WHEN type = 'customer' THEN COALESCE((SELECT SUM(amount) FROM invoices WHERE contact_id = contacts.id), 0)
+// contactTotalExpr is a contact's total across its non-cancelled bills (for a
+// vendor) or invoices (for a customer), and contactAllocatedExpr the payments
+// allocated to them. Both are correlated on contacts.id.
+const contactTotalExpr = `CASE 
+		WHEN type = 'vendor' THEN COALESCE((SELECT SUM(amount) FROM bills WHERE contact_id = contacts.id AND status <> 'cancelled'), 0)
+		WHEN type = 'customer' THEN COALESCE((SELECT SUM(amount) FROM invoices WHERE contact_id = contacts.id AND status <> 'cancelled'), 0)
 		ELSE 0
-	END as total_amount,
-	CASE 
+	END`
+
+const contactAllocatedExpr = `CASE 
 		WHEN type = 'vendor' THEN COALESCE((SELECT SUM(td.amount) FROM transaction_documents td JOIN bills b ON td.document_id = b.id WHERE td.document_type = 'bill' AND b.contact_id = contacts.id), 0)
 		WHEN type = 'customer' THEN COALESCE((SELECT SUM(td.amount) FROM transaction_documents td JOIN invoices i ON td.document_id = i.id WHERE td.document_type = 'invoice' AND i.contact_id = contacts.id), 0)
 		ELSE 0
-	END as allocated_amount
+	END`
+
+const contactSelectQuery = `SELECT id, name, type, email, phone, gstin, pan, credit_limit, created_at, updated_at,
+	` + contactTotalExpr + ` as total_amount,
+	` + contactAllocatedExpr + ` as allocated_amount
 	FROM contacts`
 
+// contactCachedSelectQuery reads the same columns as contactSelectQuery from
+// the balances cached by refreshContactBalances. A contact whose balances
+// were never refreshed has no documents yet, so NULLs read as zero.
+const contactCachedSelectQuery = `SELECT id, name, type, email, phone, gstin, pan, credit_limit, created_at, updated_at,
+	COALESCE(cached_balance, 0) + COALESCE(cached_allocated, 0), COALESCE(cached_allocated, 0)
+	FROM contacts`
+
+// ContactDocument is a bill or invoice attached to a contact.
+type ContactDocument struct {
+	DocumentType string       `json:"document_type"` // bill, invoice
+	ID           int          `json:"id"`
+	Number       string       `json:"number"`
+	Amount       models.Money `json:"amount"`
+}
+
 func scanContact(scanner interface{ Scan(...any) error }) (models.Contact, error) {
 	var c models.Contact
-	err := scanner.Scan(&c.ID, &c.Name, &c.Type, &c.Email, &c.Phone, &c.CreatedAt, &c.UpdatedAt, &c.TotalAmount, &c.AllocatedAmount)
+	if err := scanner.Scan(&c.ID, &c.Name, &c.Type, &c.Email, &c.Phone, &c.GSTIN, &c.PAN, &c.CreditLimit, &c.CreatedAt, &c.UpdatedAt, &c.TotalAmount, &c.AllocatedAmount); err != nil {
+		return models.Contact{}, err
+	}
 	c.Balance = c.TotalAmount - c.AllocatedAmount
-	return c, err
+	return c, nil
 }
 
 // ListContacts returns contacts, optionally filtered by type and/or search term.
-func (s *Store) ListContacts(typeFilter, search string) ([]models.Contact, error) {
-	query := contactSelectQuery
+// Balances come from the cached columns unless live is set, which sums each
+// contact's documents instead.
+func (s *Store) ListContacts(typeFilter, search string, live bool) ([]models.Contact, error) {
+	query := contactCachedSelectQuery
+	if live {
+		query = contactSelectQuery
+	}
 	var args []any
 	var conditions []string
 
@@ -74,28 +103,113 @@ func (s *Store) GetContact(id int) (models.Contact, error) {
 
 // CreateContact inserts a new contact and returns the created record.
 func (s *Store) CreateContact(input models.ContactInput) (models.Contact, error) {
-	var id int
-	err := s.db.QueryRow("INSERT INTO contacts (name, type, email, phone) VALUES (?, ?, ?, ?) RETURNING id",
-		input.Name, input.Type, input.Email, input.Phone).Scan(&id)
+	id, err := insertContact(s.db, input)
 	if err != nil {
 		return models.Contact{}, err
 	}
 	return scanContact(s.db.QueryRow(contactSelectQuery+" WHERE id = ?", id))
 }
 
+// insertContact inserts the contact CreateContact creates and returns its id.
+func insertContact(q rowQuerier, input models.ContactInput) (int, error) {
+	return insertReturningID(q, "INSERT INTO contacts (name, type, email, phone, gstin, pan, credit_limit) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		input.Name, input.Type, input.Email, input.Phone, input.GSTIN, input.PAN, input.CreditLimit)
+}
+
 // UpdateContact updates an existing contact. Returns sql.ErrNoRows if not found.
 func (s *Store) UpdateContact(id int, input models.ContactInput) (models.Contact, error) {
-	res, err := s.db.Exec("UPDATE contacts SET name = ?, type = ?, email = ?, phone = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?",
-		input.Name, input.Type, input.Email, input.Phone, id)
+	res, err := s.db.Exec("UPDATE contacts SET name = ?, type = ?, email = ?, phone = ?, gstin = ?, pan = ?, credit_limit = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?",
+		input.Name, input.Type, input.Email, input.Phone, input.GSTIN, input.PAN, input.CreditLimit, id)
 	if err != nil {
 		return models.Contact{}, err
 	}
 	if n, _ := res.RowsAffected(); n == 0 {
 		return models.Contact{}, sql.ErrNoRows
 	}
+	// The balance sums bills or invoices depending on the type.
+	if err := refreshContactBalances(s.db, &id); err != nil {
+		return models.Contact{}, err
+	}
 	return scanContact(s.db.QueryRow(contactSelectQuery+" WHERE id = ?", id))
 }
 
+// IncompatibleContactDocuments returns the documents attached to a contact that
+// would no longer match it if its type became newType: bills for a customer,
+// invoices for a vendor.
+func (s *Store) IncompatibleContactDocuments(id int, newType string) ([]ContactDocument, error) {
+	var query string
+	switch newType {
+	case "customer":
+		query = "SELECT 'bill', id, COALESCE(bill_number, ''), amount FROM bills WHERE contact_id = ? ORDER BY id"
+	case "vendor":
+		query = "SELECT 'invoice', id, COALESCE(invoice_number, ''), amount FROM invoices WHERE contact_id = ? ORDER BY id"
+	default:
+		return []ContactDocument{}, nil
+	}
+
+	rows, err := s.db.Query(query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var docs []ContactDocument
+	for rows.Next() {
+		var d ContactDocument
+		if err := rows.Scan(&d.DocumentType, &d.ID, &d.Number, &d.Amount); err != nil {
+			return nil, err
+		}
+		docs = append(docs, d)
+	}
+	if docs == nil {
+		docs = []ContactDocument{}
+	}
+	return docs, rows.Err()
+}
+
+// ContactPayment is a link from a transaction to one of a contact's bills or
+// invoices.
+type ContactPayment struct {
+	models.TransactionDocument
+	DocumentNumber  string       `json:"document_number"`
+	DocumentDate    *models.Date `json:"document_date"` // issue date of the bill or invoice
+	TransactionDate models.Date  `json:"transaction_date"`
+	TransactionType string       `json:"transaction_type"`
+	Description     *string      `json:"description"`
+	AccountName     *string      `json:"account_name"`
+}
+
+// ListContactPayments returns every transaction link to the bills and
+// invoices of contact id, oldest transaction first, so a contact that is both
+// a customer and a vendor has one payment history.
+func (s *Store) ListContactPayments(id int) ([]ContactPayment, error) {
+	rows, err := s.db.Query(`SELECT td.id, td.transaction_id, td.document_type, td.document_id, td.amount, td.note, td.created_at,
+		d.number, d.issue_date, t.transaction_date, t.type, t.description, a.name
+		FROM transaction_documents td
+		JOIN (SELECT 'bill' AS document_type, id, COALESCE(bill_number, '') AS number, issue_date FROM bills WHERE contact_id = ?
+			UNION ALL
+			SELECT 'invoice', id, COALESCE(invoice_number, ''), issue_date FROM invoices WHERE contact_id = ?) d
+			ON td.document_type = d.document_type AND td.document_id = d.id
+		JOIN transactions t ON td.transaction_id = t.id
+		LEFT JOIN accounts a ON t.account_id = a.id
+		ORDER BY t.transaction_date, td.id`, id, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payments := []ContactPayment{}
+	for rows.Next() {
+		var p ContactPayment
+		if err := rows.Scan(&p.ID, &p.TransactionID, &p.DocumentType, &p.DocumentID, &p.Amount, &p.Note, &p.CreatedAt,
+			&p.DocumentNumber, &p.DocumentDate, &p.TransactionDate, &p.TransactionType, &p.Description, &p.AccountName); err != nil {
+			return nil, err
+		}
+		payments = append(payments, p)
+	}
+	return payments, rows.Err()
+}
+
 // DeleteContact removes a contact. Returns sql.ErrNoRows if not found.
 func (s *Store) DeleteContact(id int) error {
 	res, err := s.db.Exec("DELETE FROM contacts WHERE id = ?", id)
@@ -105,5 +219,5 @@ func (s *Store) DeleteContact(id int) error {
 	if n, _ := res.RowsAffected(); n == 0 {
 		return sql.ErrNoRows
 	}
-	return nil
+	return recordDeletion(s.db, "contact", id)
 }
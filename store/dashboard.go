@@ -1,5 +1,11 @@
 package store
 
+import (
+	"fmt"
+
+	"github.com/satheeshds/portal/models"
+)
+
 // DashboardData holds aggregate statistics for the dashboard.
 type DashboardData struct {
 	TotalAccounts     int `json:"total_accounts"`
@@ -16,13 +22,99 @@ type DashboardData struct {
 	OverdueBills    int `json:"overdue_bills"`
 	OverdueInvoices int `json:"overdue_invoices"`
 
+	DocumentsByStatus DocumentsByStatus `json:"documents_by_status"`
+
+	Reconciliation []AccountReconciliation `json:"reconciliation"`
+
 	RecentTransactions []map[string]any `json:"recent_transactions"`
 }
 
-// GetDashboard retrieves aggregate dashboard statistics.
-func (s *Store) GetDashboard() (DashboardData, error) {
+// StatusTotals counts the documents in one status and sums their amounts and
+// the payments allocated to them.
+type StatusTotals struct {
+	Count     int          `json:"count"`
+	Amount    models.Money `json:"amount"`
+	Allocated models.Money `json:"allocated"`
+}
+
+// DocumentsByStatus breaks bills and invoices down by status, keyed by status
+// name. Only statuses in use appear.
+type DocumentsByStatus struct {
+	Bills    map[string]StatusTotals `json:"bills"`
+	Invoices map[string]StatusTotals `json:"invoices"`
+}
+
+// AccountReconciliation is one account's reconciliation workload: its
+// transactions not yet marked reconciled, and the date of the latest one that
+// is. Amounts are in the account's currency.
+type AccountReconciliation struct {
+	AccountID          int          `json:"account_id"`
+	AccountName        string       `json:"account_name"`
+	Currency           string       `json:"currency"`
+	Unreconciled       int          `json:"unreconciled"`
+	UnreconciledAmount models.Money `json:"unreconciled_amount"` // summed regardless of direction
+	LastReconciled     models.Date  `json:"last_reconciled"`     // null when nothing is reconciled yet
+}
+
+// reconciliationByAccount summarises reconciliation per account, ordered by
+// account name. Accounts without transactions are included with zero counts.
+func (s *Store) reconciliationByAccount() ([]AccountReconciliation, error) {
+	rows, err := s.db.Query(`SELECT a.id, a.name, COALESCE(a.currency, ''),
+		COALESCE(SUM(CASE WHEN t.id IS NOT NULL AND NOT COALESCE(t.reconciled, false) THEN 1 ELSE 0 END), 0),
+		COALESCE(SUM(CASE WHEN NOT COALESCE(t.reconciled, false) THEN t.amount ELSE 0 END), 0),
+		MAX(CASE WHEN t.reconciled THEN t.transaction_date END)
+		FROM accounts a LEFT JOIN transactions t ON t.account_id = a.id
+		GROUP BY a.id, a.name, a.currency
+		ORDER BY a.name, a.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []AccountReconciliation{}
+	for rows.Next() {
+		var a AccountReconciliation
+		if err := rows.Scan(&a.AccountID, &a.AccountName, &a.Currency, &a.Unreconciled, &a.UnreconciledAmount, &a.LastReconciled); err != nil {
+			return nil, err
+		}
+		list = append(list, a)
+	}
+	return list, rows.Err()
+}
+
+// documentStatusTotals groups table (bills or invoices) by status. docType is
+// the matching transaction_documents.document_type.
+func (s *Store) documentStatusTotals(table, docType string) (map[string]StatusTotals, error) {
+	rows, err := s.db.Query(fmt.Sprintf(`SELECT COALESCE(status, ''), COUNT(*), COALESCE(SUM(amount), 0),
+		COALESCE(SUM((SELECT COALESCE(SUM(td.amount), 0) FROM transaction_documents td WHERE td.document_type = ? AND td.document_id = %[1]s.id)), 0)
+		FROM %[1]s GROUP BY 1`, table), docType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	totals := map[string]StatusTotals{}
+	for rows.Next() {
+		var status string
+		var t StatusTotals
+		if err := rows.Scan(&status, &t.Count, &t.Amount, &t.Allocated); err != nil {
+			return nil, err
+		}
+		totals[status] = t
+	}
+	return totals, rows.Err()
+}
+
+// GetDashboard retrieves aggregate dashboard statistics. Cancelled bills and
+// invoices count towards payables/receivables only when includeCancelled is set.
+func (s *Store) GetDashboard(includeCancelled bool) (DashboardData, error) {
 	var d DashboardData
 
+	billOpen, invoiceOpen := "'paid', 'cancelled'", "'paid', 'received', 'cancelled'"
+	if includeCancelled {
+		billOpen, invoiceOpen = "'paid'", "'paid', 'received'"
+	}
+
 	if err := s.db.QueryRow("SELECT COUNT(*) FROM accounts").Scan(&d.TotalAccounts); err != nil {
 		return DashboardData{}, err
 	}
@@ -43,11 +135,11 @@ func (s *Store) GetDashboard() (DashboardData, error) {
 	}
 
 	if err := s.db.QueryRow(`SELECT COALESCE(SUM(amount - (SELECT COALESCE(SUM(td.amount), 0) FROM transaction_documents td WHERE td.document_type = 'bill' AND td.document_id = bills.id)), 0) 
-		FROM bills WHERE status NOT IN ('paid', 'cancelled')`).Scan(&d.BillsPayable); err != nil {
+		FROM bills WHERE status NOT IN (` + billOpen + `)`).Scan(&d.BillsPayable); err != nil {
 		return DashboardData{}, err
 	}
 	if err := s.db.QueryRow(`SELECT COALESCE(SUM(amount - (SELECT COALESCE(SUM(td.amount), 0) FROM transaction_documents td WHERE td.document_type = 'invoice' AND td.document_id = invoices.id)), 0) 
-		FROM invoices WHERE status NOT IN ('paid', 'received', 'cancelled')`).Scan(&d.InvoicesReceivable); err != nil {
+		FROM invoices WHERE status NOT IN (` + invoiceOpen + `)`).Scan(&d.InvoicesReceivable); err != nil {
 		return DashboardData{}, err
 	}
 	if err := s.db.QueryRow("SELECT COALESCE(SUM(final_payout_amt), 0) FROM payouts").Scan(&d.PayoutsReceived); err != nil {
@@ -61,7 +153,22 @@ func (s *Store) GetDashboard() (DashboardData, error) {
 		return DashboardData{}, err
 	}
 
-	rows, err := s.db.Query(`SELECT t.id, t.type, t.amount, t.transaction_date, t.description, a.name as account_name
+	byStatus, err := s.documentStatusTotals("bills", "bill")
+	if err != nil {
+		return DashboardData{}, err
+	}
+	d.DocumentsByStatus.Bills = byStatus
+	if byStatus, err = s.documentStatusTotals("invoices", "invoice"); err != nil {
+		return DashboardData{}, err
+	}
+	d.DocumentsByStatus.Invoices = byStatus
+
+	if d.Reconciliation, err = s.reconciliationByAccount(); err != nil {
+		return DashboardData{}, err
+	}
+
+	rows, err := s.db.Query(`SELECT t.id, t.type, t.amount, t.transaction_date, t.description, a.name as account_name,
+		COALESCE(a.currency, 'INR')
 		FROM transactions t LEFT JOIN accounts a ON t.account_id = a.id
 		ORDER BY t.created_at DESC LIMIT 5`)
 	if err != nil {
@@ -73,7 +180,8 @@ func (s *Store) GetDashboard() (DashboardData, error) {
 		var id int
 		var tp, desc, date, acct *string
 		var amount int
-		if err := rows.Scan(&id, &tp, &amount, &date, &desc, &acct); err != nil {
+		var currency string
+		if err := rows.Scan(&id, &tp, &amount, &date, &desc, &acct, &currency); err != nil {
 			return DashboardData{}, err
 		}
 		d.RecentTransactions = append(d.RecentTransactions, map[string]any{
@@ -83,6 +191,7 @@ func (s *Store) GetDashboard() (DashboardData, error) {
 			"transaction_date": date,
 			"description":      desc,
 			"account_name":     acct,
+			"currency":         currency, // the account's, which amount is in
 		})
 	}
 	if err := rows.Err(); err != nil {
@@ -0,0 +1,62 @@
+package store
+
+import (
+	"database/sql"
+
+	"github.com/satheeshds/portal/models"
+)
+
+// ListDefaults returns every configured default, ordered by key.
+func (s *Store) ListDefaults() ([]models.Default, error) {
+	rows, err := s.db.Query("SELECT key, value, updated_at FROM defaults ORDER BY key")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	defaults := []models.Default{}
+	for rows.Next() {
+		var d models.Default
+		if err := rows.Scan(&d.Key, &d.Value, &d.UpdatedAt); err != nil {
+			return nil, err
+		}
+		defaults = append(defaults, d)
+	}
+	return defaults, rows.Err()
+}
+
+// GetDefault returns the default stored under key. Returns sql.ErrNoRows if
+// it is not set.
+func (s *Store) GetDefault(key string) (models.Default, error) {
+	var d models.Default
+	err := s.db.QueryRow("SELECT key, value, updated_at FROM defaults WHERE key = ? ORDER BY id LIMIT 1", key).
+		Scan(&d.Key, &d.Value, &d.UpdatedAt)
+	return d, err
+}
+
+// SetDefault stores value under key, replacing any previous value.
+func (s *Store) SetDefault(key, value string) (models.Default, error) {
+	res, err := s.db.Exec("UPDATE defaults SET value = ?, updated_at = CURRENT_TIMESTAMP WHERE key = ?", value, key)
+	if err != nil {
+		return models.Default{}, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		if _, err := s.db.Exec("INSERT INTO defaults (key, value) VALUES (?, ?)", key, value); err != nil {
+			return models.Default{}, err
+		}
+	}
+	return s.GetDefault(key)
+}
+
+// DeleteDefault removes the default stored under key. Returns sql.ErrNoRows
+// if it is not set.
+func (s *Store) DeleteDefault(key string) error {
+	res, err := s.db.Exec("DELETE FROM defaults WHERE key = ?", key)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,220 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/satheeshds/portal/db"
+	"github.com/satheeshds/portal/models"
+)
+
+// demoTables are the tables that must all be empty before demo data is seeded.
+var demoTables = []string{"accounts", "contacts", "outlets", "bills", "invoices", "transactions", "payouts"}
+
+// demoSeed carries the first error met while seeding, so the steps of
+// SeedDemoData read as a script and stop having effect after a failure. Every
+// step runs in tx, so a failure leaves the database as it was.
+type demoSeed struct {
+	tx    *db.PortalTx
+	today time.Time
+	err   error
+	links []docRef // documents whose status follows from the links made
+}
+
+// invalid records a validation message as the seed's error. The demo data is
+// fixed, so this only fails when validation rules change under it.
+func (d *demoSeed) invalid(msg string) bool {
+	if msg != "" {
+		d.err = fmt.Errorf("demo data: %s", msg)
+	}
+	return d.err != nil
+}
+
+// date returns the day days after today (negative for the past), as YYYY-MM-DD.
+func (d *demoSeed) date(days int) *string {
+	v := d.today.AddDate(0, 0, days).Format("2006-01-02")
+	return &v
+}
+
+func (d *demoSeed) account(name, typ string, opening models.Money) int {
+	if d.err != nil {
+		return 0
+	}
+	input := models.AccountInput{Name: name, Type: typ, OpeningBalance: opening}
+	if d.invalid(input.Validate()) {
+		return 0
+	}
+	id, err := insertAccount(d.tx, input)
+	d.err = err
+	return id
+}
+
+func (d *demoSeed) contact(name, typ string, gstin *string) *int {
+	if d.err != nil {
+		return nil
+	}
+	input := models.ContactInput{Name: name, Type: typ, GSTIN: gstin}
+	if d.invalid(input.Validate()) {
+		return nil
+	}
+	id, err := insertContact(d.tx, input)
+	d.err = err
+	return &id
+}
+
+func (d *demoSeed) outlet(name string) *int {
+	if d.err != nil {
+		return nil
+	}
+	id, err := insertOutlet(d.tx, models.OutletInput{Name: name})
+	d.err = err
+	return &id
+}
+
+func (d *demoSeed) bill(input models.BillInput) int {
+	if d.err != nil || d.invalid(input.Validate()) {
+		return 0
+	}
+	id, err := insertBill(d.tx, input)
+	d.err = err
+	return id
+}
+
+func (d *demoSeed) invoice(input models.InvoiceInput) int {
+	if d.err != nil || d.invalid(input.Validate()) {
+		return 0
+	}
+	id, err := insertInvoice(d.tx, input)
+	d.err = err
+	return id
+}
+
+func (d *demoSeed) payout(input models.PayoutInput) int {
+	if d.err != nil || d.invalid(input.Validate()) {
+		return 0
+	}
+	id, err := insertPayout(d.tx, input)
+	d.err = err
+	return id
+}
+
+func (d *demoSeed) transaction(input models.TransactionInput) int {
+	if d.err != nil || d.invalid(input.Validate()) {
+		return 0
+	}
+	id, err := insertTransaction(d.tx, input)
+	d.err = err
+	return id
+}
+
+// link allocates amount of transaction txnID to a document. The document's
+// status is refreshed once the seed is committed.
+func (d *demoSeed) link(txnID int, docType string, docID int, amount models.Money) {
+	if d.err != nil {
+		return
+	}
+	_, d.err = insertReturningID(d.tx, "INSERT INTO transaction_documents (transaction_id, document_type, document_id, amount) VALUES (?, ?, ?, ?)",
+		txnID, docType, docID, amount)
+	if d.err == nil {
+		d.links = append(d.links, docRef{docType, docID})
+	}
+}
+
+// SeedDemoData fills an empty database with a small restaurant business: bank,
+// cash, and card accounts, vendors and customers, bills and invoices in
+// various states of payment, delivery platform payouts for one outlet, and the
+// transactions that settle them, dated in the weeks before today. It does
+// nothing and returns false when any of demoTables already holds a row, so it
+// is safe to call on every start. All inserts run in one transaction.
+func (s *Store) SeedDemoData(today time.Time) (bool, error) {
+	for _, table := range demoTables {
+		var n int
+		if err := s.db.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&n); err != nil {
+			return false, err
+		}
+		if n > 0 {
+			return false, nil
+		}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	d := &demoSeed{tx: tx, today: today}
+	str := func(v string) *string { return &v }
+	rupees := models.WholeUnits
+
+	bank := d.account("HDFC Current Account", "bank", rupees(250000))
+	cash := d.account("Petty Cash", "cash", rupees(5000))
+	card := d.account("Business Credit Card", "credit_card", 0)
+
+	produce := d.contact("Fresh Farms Produce", "vendor", nil)
+	packaging := d.contact("Metro Packaging Supplies", "vendor", nil)
+	power := d.contact("City Power Distribution", "vendor", nil)
+	acme := d.contact("Acme Technologies", "customer", str("27AAPFU0939F1ZV"))
+	bluebird := d.contact("Bluebird Events", "customer", nil)
+
+	outletID := d.outlet("Koramangala Kitchen")
+
+	produceBill := d.bill(models.BillInput{ContactID: produce, BillNumber: "FF-2291", IssueDate: d.date(-40), DueDate: d.date(-10),
+		Amount: rupees(18500), Status: "received", Notes: str("Vegetables and dairy, fortnightly order"),
+		Items: []models.BillItemInput{
+			{Description: "Vegetables", Quantity: 1, UnitPrice: rupees(11200), Amount: rupees(11200)},
+			{Description: "Dairy", Quantity: 1, UnitPrice: rupees(7300), Amount: rupees(7300)},
+		}})
+	packagingBill := d.bill(models.BillInput{ContactID: packaging, BillNumber: "MPS-0417", IssueDate: d.date(-20), DueDate: d.date(10),
+		Amount: rupees(7200), Status: "received"})
+	d.bill(models.BillInput{ContactID: power, BillNumber: "CPD-88213", IssueDate: d.date(-5), DueDate: d.date(25),
+		Amount: rupees(4850), Status: "received", Notes: str("Electricity, last month")})
+
+	rate := 18.0
+	acmeInvoice := d.invoice(models.InvoiceInput{ContactID: acme, InvoiceNumber: "INV-0001", IssueDate: d.date(-35), DueDate: d.date(-5),
+		Amount: rupees(45000), Status: "sent", TaxRate: &rate, TaxAmount: models.IncludedTax(rupees(45000), rate), Notes: str("Office lunch catering, 30 days"),
+		Items: []models.InvoiceItemInput{{Description: "Lunch buffet", Quantity: 150, UnitPrice: rupees(300), Amount: rupees(45000)}}})
+	d.invoice(models.InvoiceInput{ContactID: bluebird, InvoiceNumber: "INV-0002", IssueDate: d.date(-15), DueDate: d.date(15),
+		Amount: rupees(28000), Status: "sent", TaxRate: &rate, TaxAmount: models.IncludedTax(rupees(28000), rate), Notes: str("Wedding reception dessert counter")})
+	d.invoice(models.InvoiceInput{ContactID: acme, InvoiceNumber: "INV-0003", IssueDate: d.date(-3), DueDate: d.date(27),
+		Amount: rupees(12500), Status: "draft", TaxRate: &rate, TaxAmount: models.IncludedTax(rupees(12500), rate)})
+
+	swiggy := d.payout(models.PayoutInput{OutletID: outletID, OutletName: "Koramangala Kitchen", Platform: "swiggy",
+		PeriodStart: d.date(-21), PeriodEnd: d.date(-15), SettlementDate: d.date(-13), TotalOrders: 148,
+		GrossSalesAmt: rupees(62000), RestaurantDiscountAmt: rupees(2400), PlatformCommissionAmt: rupees(11160),
+		TaxesTcsTdsAmt: rupees(620), MarketingAdsAmt: rupees(1500), FinalPayoutAmt: rupees(46320), UtrNumber: "HDFCN52024061312345"})
+	zomato := d.payout(models.PayoutInput{OutletID: outletID, OutletName: "Koramangala Kitchen", Platform: "zomato",
+		PeriodStart: d.date(-14), PeriodEnd: d.date(-8), SettlementDate: d.date(-6), TotalOrders: 117,
+		GrossSalesAmt: rupees(48500), RestaurantDiscountAmt: rupees(1800), PlatformCommissionAmt: rupees(9700),
+		TaxesTcsTdsAmt: rupees(485), MarketingAdsAmt: rupees(1000), FinalPayoutAmt: rupees(35515), UtrNumber: "ICICR52024062098765"})
+	d.payout(models.PayoutInput{OutletID: outletID, OutletName: "Koramangala Kitchen", Platform: "swiggy",
+		PeriodStart: d.date(-7), PeriodEnd: d.date(-1), TotalOrders: 131,
+		GrossSalesAmt: rupees(55000), RestaurantDiscountAmt: rupees(2100), PlatformCommissionAmt: rupees(9900),
+		TaxesTcsTdsAmt: rupees(550), MarketingAdsAmt: rupees(1200), FinalPayoutAmt: rupees(41250)})
+
+	txn := func(account int, typ string, amount models.Money, days int, description string) int {
+		return d.transaction(models.TransactionInput{AccountID: account, Type: typ, Amount: amount,
+			TransactionDate: d.date(days), Description: str(description)})
+	}
+	d.link(txn(bank, "income", rupees(46320), -13, "Swiggy settlement"), "payout", swiggy, rupees(46320))
+	d.link(txn(bank, "income", rupees(35515), -6, "Zomato settlement"), "payout", zomato, rupees(35515))
+	d.link(txn(bank, "expense", rupees(18500), -12, "Fresh Farms Produce FF-2291"), "bill", produceBill, rupees(18500))
+	d.link(txn(bank, "expense", rupees(3000), -4, "Metro Packaging part payment"), "bill", packagingBill, rupees(3000))
+	d.link(txn(bank, "income", rupees(45000), -7, "Acme Technologies NEFT"), "invoice", acmeInvoice, rupees(45000))
+	txn(bank, "expense", rupees(35000), -28, "Shop rent")
+	txn(cash, "expense", rupees(1200), -2, "Staff tea and snacks")
+	txn(card, "expense", rupees(2999), -9, "POS software subscription")
+	d.transaction(models.TransactionInput{AccountID: bank, Type: "transfer", Amount: rupees(5000), TransactionDate: d.date(-10),
+		Description: str("Cash withdrawal for petty cash"), TransferAccountID: &cash})
+
+	if d.err != nil {
+		return false, d.err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	for _, dr := range d.links {
+		s.UpdateDocumentStatus(dr.docType, dr.docID)
+	}
+	return true, nil
+}
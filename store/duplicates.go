@@ -0,0 +1,158 @@
+package store
+
+import (
+	"github.com/satheeshds/portal/models"
+)
+
+// DuplicateGroup is a set of transactions that look like the same bank entry
+// posted more than once, oldest first.
+type DuplicateGroup struct {
+	AccountID       int                  `json:"account_id"`
+	Type            string               `json:"type"`
+	Amount          models.Money         `json:"amount"`
+	TransactionDate models.Date          `json:"transaction_date"`
+	Reference       *string              `json:"reference,omitempty"` // set only when references are matched
+	Transactions    []models.Transaction `json:"transactions"`
+}
+
+// ListDuplicateTransactions groups income and expense transactions that share
+// an account, type, amount, and date, and when matchReference is set also a
+// reference (a missing reference matching only another missing one). Only
+// groups with more than one transaction are returned; within a group the
+// earliest created comes first. Undated transactions and transfer legs are
+// never treated as duplicates.
+func (s *Store) ListDuplicateTransactions(matchReference bool) ([]DuplicateGroup, error) {
+	sameRef := ""
+	order := ""
+	if matchReference {
+		sameRef = " AND COALESCE(d.reference, '') = COALESCE(t.reference, '')"
+		order = ", COALESCE(t.reference, '')"
+	}
+	rows, err := s.db.Query(txnSelectQuery + ` WHERE t.type IN ('income', 'expense') AND t.transfer_account_id IS NULL
+		AND t.transaction_date IS NOT NULL
+		AND EXISTS (SELECT 1 FROM transactions d WHERE d.id <> t.id AND d.account_id = t.account_id
+			AND d.type = t.type AND d.amount = t.amount AND d.transaction_date = t.transaction_date
+			AND d.transfer_account_id IS NULL` + sameRef + `)
+		ORDER BY t.account_id, t.transaction_date, t.type, t.amount` + order + `, t.created_at, t.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := []DuplicateGroup{}
+	for rows.Next() {
+		t, err := scanTransaction(rows)
+		if err != nil {
+			return nil, err
+		}
+		if n := len(groups); n > 0 && sameDuplicateKey(groups[n-1], t, matchReference) {
+			groups[n-1].Transactions = append(groups[n-1].Transactions, t)
+			continue
+		}
+		g := DuplicateGroup{AccountID: t.AccountID, Type: t.Type, Amount: t.Amount,
+			TransactionDate: t.TransactionDate, Transactions: []models.Transaction{t}}
+		if matchReference {
+			ref := ""
+			if t.Reference != nil {
+				ref = *t.Reference
+			}
+			g.Reference = &ref
+		}
+		groups = append(groups, g)
+	}
+	return groups, rows.Err()
+}
+
+// sameDuplicateKey reports whether t belongs in group g.
+func sameDuplicateKey(g DuplicateGroup, t models.Transaction, matchReference bool) bool {
+	if g.AccountID != t.AccountID || g.Type != t.Type || g.Amount != t.Amount ||
+		g.TransactionDate.String() != t.TransactionDate.String() {
+		return false
+	}
+	if !matchReference {
+		return true
+	}
+	ref := ""
+	if t.Reference != nil {
+		ref = *t.Reference
+	}
+	return *g.Reference == ref
+}
+
+// Survivor returns the index of the transaction in g that resolving keeps:
+// the one that is linked to documents, reconciled, or locked, or the earliest
+// created when none is. ok is false when more than one is, since deleting any
+// of them would drop payment allocations or undo review work.
+func (g DuplicateGroup) Survivor() (i int, ok bool) {
+	i, ok = 0, true
+	pinned := 0
+	for j, t := range g.Transactions {
+		if t.Allocated > 0 || t.Reconciled || t.Locked {
+			i = j
+			pinned++
+		}
+	}
+	return i, pinned <= 1
+}
+
+// ResolveDuplicates deletes every transaction but each group's Survivor,
+// together with its document links, in one transaction. Groups without a
+// single survivor are skipped; callers should refuse them first. The statuses
+// of documents that lost an allocation are then recomputed. It returns the
+// deleted transactions.
+func (s *Store) ResolveDuplicates(groups []DuplicateGroup) ([]models.Transaction, error) {
+	removed := []models.Transaction{}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var affected []docRef
+	for _, g := range groups {
+		keep, ok := g.Survivor()
+		if !ok {
+			continue
+		}
+		for i, t := range g.Transactions {
+			if i == keep {
+				continue
+			}
+			rows, err := tx.Query("SELECT document_type, document_id FROM transaction_documents WHERE transaction_id = ?", t.ID)
+			if err != nil {
+				return nil, err
+			}
+			for rows.Next() {
+				var dr docRef
+				if err := rows.Scan(&dr.docType, &dr.docID); err != nil {
+					rows.Close()
+					return nil, err
+				}
+				affected = append(affected, dr)
+			}
+			rows.Close()
+			if err := rows.Err(); err != nil {
+				return nil, err
+			}
+
+			if _, err := tx.Exec("DELETE FROM transaction_documents WHERE transaction_id = ?", t.ID); err != nil {
+				return nil, err
+			}
+			if _, err := tx.Exec("DELETE FROM transactions WHERE id = ?", t.ID); err != nil {
+				return nil, err
+			}
+			if err := recordDeletion(tx, "transaction", t.ID); err != nil {
+				return nil, err
+			}
+			removed = append(removed, t)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	for _, dr := range affected {
+		s.UpdateDocumentStatus(dr.docType, dr.docID)
+	}
+	return removed, nil
+}
@@ -0,0 +1,208 @@
+package store
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/satheeshds/portal/models"
+)
+
+// EInvoiceVersion is the version of the IRP e-invoice schema EInvoice follows.
+const EInvoiceVersion = "1.1"
+
+// EInvoiceTransaction is the TranDtls block of an e-invoice.
+type EInvoiceTransaction struct {
+	TaxScheme     string `json:"TaxSch"` // always GST
+	SupplyType    string `json:"SupTyp"` // B2B
+	ReverseCharge string `json:"RegRev"` // Y or N
+}
+
+// EInvoiceDocument is the DocDtls block of an e-invoice.
+type EInvoiceDocument struct {
+	Type   string `json:"Typ"` // INV
+	Number string `json:"No"`
+	Date   string `json:"Dt"` // DD/MM/YYYY
+}
+
+// EInvoiceParty is the SellerDtls or BuyerDtls block of an e-invoice. The
+// books hold no addresses, so Addr1, Loc, and Pin are left empty for the
+// generator to fill in.
+type EInvoiceParty struct {
+	GSTIN         string `json:"Gstin"`
+	LegalName     string `json:"LglNm"`
+	PlaceOfSupply string `json:"Pos,omitempty"` // buyer only: two-digit state code
+	Address       string `json:"Addr1"`
+	Location      string `json:"Loc"`
+	Pin           int    `json:"Pin"`
+	StateCode     string `json:"Stcd"`
+}
+
+// EInvoiceItem is one entry of the ItemList of an e-invoice. Amounts are in
+// rupees and exclusive of GST unless named as a total.
+type EInvoiceItem struct {
+	Serial           string  `json:"SlNo"`
+	Description      string  `json:"PrdDesc"`
+	IsService        string  `json:"IsServc"` // Y or N
+	HSN              string  `json:"HsnCd"`
+	Quantity         float64 `json:"Qty"`
+	Unit             string  `json:"Unit"`
+	UnitPrice        float64 `json:"UnitPrice"`
+	TotalAmount      float64 `json:"TotAmt"`
+	AssessableAmount float64 `json:"AssAmt"`
+	Rate             float64 `json:"GstRt"`
+	IGST             float64 `json:"IgstAmt"`
+	CGST             float64 `json:"CgstAmt"`
+	SGST             float64 `json:"SgstAmt"`
+	TotalItemValue   float64 `json:"TotItemVal"`
+}
+
+// EInvoiceValues is the ValDtls block of an e-invoice.
+type EInvoiceValues struct {
+	AssessableValue float64 `json:"AssVal"`
+	CGST            float64 `json:"CgstVal"`
+	SGST            float64 `json:"SgstVal"`
+	IGST            float64 `json:"IgstVal"`
+	TotalValue      float64 `json:"TotInvVal"`
+}
+
+// EInvoice is an invoice in the JSON schema accepted by e-invoice
+// generators for registration with the Invoice Registration Portal.
+type EInvoice struct {
+	Version     string              `json:"Version"`
+	Transaction EInvoiceTransaction `json:"TranDtls"`
+	Document    EInvoiceDocument    `json:"DocDtls"`
+	Seller      EInvoiceParty       `json:"SellerDtls"`
+	Buyer       EInvoiceParty       `json:"BuyerDtls"`
+	Items       []EInvoiceItem      `json:"ItemList"`
+	Values      EInvoiceValues      `json:"ValDtls"`
+}
+
+// EInvoiceExport is the e-invoice JSON of one invoice with the mandatory
+// fields the books cannot supply.
+type EInvoiceExport struct {
+	InvoiceID int      `json:"invoice_id"`
+	EInvoice  EInvoice `json:"einvoice"`
+	Missing   []string `json:"missing"` // paths of mandatory fields left empty, e.g. SellerDtls.Addr1
+	IRN       *string  `json:"irn"`     // the IRP's reference once registered; not tracked yet, so always null
+}
+
+// EInvoiceFor assembles the e-invoice of inv, which must have a tax_rate or
+// items with their own (see Invoice.ItemTaxRates), issued by the business registered as sellerGSTIN to buyer, which must have
+// a GSTIN. The place of supply is the invoice's, else the state of the
+// buyer's GSTIN; a supply within the seller's state is taxed as CGST and
+// SGST, any other as IGST. Items with their own tax rates are taxed at them;
+// otherwise the invoice amount and taxes are spread over its items by item
+// amount (an invoice without items is one item), the last item taking any
+// rounding. An item is a service when its code is a SAC (starting 99).
+func EInvoiceFor(inv models.Invoice, buyer models.Contact, sellerGSTIN string) EInvoiceExport {
+	buyerGSTIN := *buyer.GSTIN
+	pos := buyerGSTIN[:2]
+	if inv.PlaceOfSupply != nil && *inv.PlaceOfSupply != "" {
+		pos = *inv.PlaceOfSupply
+	}
+	intra := pos == sellerGSTIN[:2]
+	export := EInvoiceExport{InvoiceID: inv.ID, Missing: []string{"SellerDtls.LglNm", "SellerDtls.Addr1", "SellerDtls.Loc",
+		"SellerDtls.Pin", "BuyerDtls.Addr1", "BuyerDtls.Loc", "BuyerDtls.Pin"}}
+	e := EInvoice{
+		Version:     EInvoiceVersion,
+		Transaction: EInvoiceTransaction{TaxScheme: "GST", SupplyType: "B2B", ReverseCharge: "N"},
+		Document:    EInvoiceDocument{Type: "INV", Number: inv.InvoiceNumber},
+		Seller:      EInvoiceParty{GSTIN: sellerGSTIN, StateCode: sellerGSTIN[:2]},
+		Buyer:       EInvoiceParty{GSTIN: buyerGSTIN, LegalName: buyer.Name, PlaceOfSupply: pos, StateCode: buyerGSTIN[:2]},
+		Items:       []EInvoiceItem{},
+	}
+	if !inv.IssueDate.IsZero() {
+		e.Document.Date = inv.IssueDate.Format("02/01/2006")
+	} else {
+		export.Missing = append(export.Missing, "DocDtls.Dt")
+	}
+
+	items := inv.Items
+	var weight models.Money
+	itemRates := false
+	for _, item := range items {
+		weight += item.Amount
+		itemRates = itemRates || item.TaxRate != nil
+	}
+	if len(items) == 0 || weight <= 0 {
+		items = []models.InvoiceItem{{Description: "Invoice " + inv.InvoiceNumber, Quantity: 1, Amount: inv.Amount}}
+		weight = inv.Amount
+	}
+
+	// The invoice's tax is split once, as in GSTR-1, an odd paisa going to
+	// SGST, and each part is then spread over the items.
+	var cgstLeft, sgstLeft, igstLeft models.Money
+	if intra {
+		cgstLeft = inv.TaxAmount / 2
+		sgstLeft = inv.TaxAmount - cgstLeft
+	} else {
+		igstLeft = inv.TaxAmount
+	}
+	grossLeft, weightLeft := inv.Amount, weight
+	var cgstTotal, sgstTotal, igstTotal, assessableTotal models.Money
+	for i, item := range items {
+		// Each item takes its share of what is left, so the last takes the rest.
+		last := i == len(items)-1 || weightLeft <= 0
+		share := func(left *models.Money) models.Money {
+			part := *left
+			if !last {
+				part = models.Money(int64(*left) * int64(item.Amount) / int64(weightLeft))
+			}
+			*left -= part
+			return part
+		}
+		var gross, cgst, sgst, igst models.Money
+		var rate float64
+		if itemRates {
+			// Each item carries its own tax, split the same way.
+			gross = item.Amount
+			if intra {
+				cgst = item.TaxAmount / 2
+				sgst = item.TaxAmount - cgst
+			} else {
+				igst = item.TaxAmount
+			}
+			if item.TaxRate != nil {
+				rate = *item.TaxRate
+			}
+		} else {
+			gross, cgst, sgst, igst = share(&grossLeft), share(&cgstLeft), share(&sgstLeft), share(&igstLeft)
+			rate = *inv.TaxRate
+		}
+		weightLeft -= item.Amount
+		assessable := gross - cgst - sgst - igst
+
+		qty := item.Quantity
+		if qty <= 0 {
+			qty = 1
+		}
+		unit := "NOS"
+		if item.Unit != nil && strings.TrimSpace(*item.Unit) != "" {
+			unit = strings.ToUpper(strings.TrimSpace(*item.Unit))
+		}
+		hsn, service := "", "N"
+		if item.HSNCode != nil {
+			hsn = *item.HSNCode
+		}
+		if strings.HasPrefix(hsn, "99") {
+			service = "Y"
+		}
+		e.Items = append(e.Items, EInvoiceItem{
+			Serial: fmt.Sprint(i + 1), Description: item.Description, IsService: service, HSN: hsn, Quantity: qty, Unit: unit,
+			UnitPrice: math.Round(assessable.ToFloat()/qty*1000) / 1000, TotalAmount: assessable.ToFloat(), AssessableAmount: assessable.ToFloat(),
+			Rate: rate, IGST: igst.ToFloat(), CGST: cgst.ToFloat(), SGST: sgst.ToFloat(), TotalItemValue: gross.ToFloat(),
+		})
+		if hsn == "" {
+			export.Missing = append(export.Missing, fmt.Sprintf("ItemList[%d].HsnCd", i))
+		}
+		assessableTotal += assessable
+		cgstTotal += cgst
+		sgstTotal += sgst
+		igstTotal += igst
+	}
+	e.Values = EInvoiceValues{AssessableValue: assessableTotal.ToFloat(), CGST: cgstTotal.ToFloat(), SGST: sgstTotal.ToFloat(),
+		IGST: igstTotal.ToFloat(), TotalValue: inv.Amount.ToFloat()}
+	export.EInvoice = e
+	return export
+}
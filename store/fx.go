@@ -0,0 +1,133 @@
+package store
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/satheeshds/portal/models"
+)
+
+// FXReportRow revalues the balance of one foreign-currency account into the
+// books currency. Rates are books-currency units per unit of Currency.
+type FXReportRow struct {
+	AccountID     int          `json:"account_id"`
+	AccountName   string       `json:"account_name"`
+	Currency      string       `json:"currency"`
+	Balance       models.Money `json:"balance"`        // in Currency
+	RecordedRate  *float64     `json:"recorded_rate"`  // average rate of transfers to and from the books currency; null without any
+	BookValue     models.Money `json:"book_value"`     // Balance at RecordedRate, or at Rate without one
+	Rate          *float64     `json:"rate"`           // null when no rate is supplied or stored
+	RateSource    string       `json:"rate_source"`    // supplied, stored, or empty without a rate
+	RevaluedValue models.Money `json:"revalued_value"` // Balance at Rate
+	GainLoss      models.Money `json:"gain_loss"`      // RevaluedValue - BookValue; positive is a gain
+}
+
+// FXReport is the unrealized exchange gain or loss on foreign-currency
+// account balances on AsOf.
+type FXReport struct {
+	AsOf          string        `json:"as_of"`
+	BooksCurrency string        `json:"books_currency"`
+	Rows          []FXReportRow `json:"rows"`
+	TotalGainLoss models.Money  `json:"total_gain_loss"` // in BooksCurrency
+	Missing       []string      `json:"missing"`         // currencies without a rate, whose accounts are not revalued
+}
+
+// storedFXRates returns the exchange rates held in fx_rate.<CURRENCY>
+// defaults, skipping values that are not positive numbers.
+func (s *Store) storedFXRates() (map[string]float64, error) {
+	defaults, err := s.ListDefaults()
+	if err != nil {
+		return nil, err
+	}
+	rates := map[string]float64{}
+	for _, d := range defaults {
+		currency, ok := models.FXRateCurrency(d.Key)
+		if !ok {
+			continue
+		}
+		if rate, err := strconv.ParseFloat(strings.TrimSpace(d.Value), 64); err == nil && rate > 0 {
+			rates[currency] = rate
+		}
+	}
+	return rates, nil
+}
+
+// GetFXReport revalues the balance on asOf (YYYY-MM-DD) of every account not
+// in BooksCurrency at the rate in supplied, or else at the stored
+// fx_rate.<CURRENCY> default, and compares it with its value at the rate the
+// account's money was recorded at: the amount-weighted average exchange_rate
+// of its transfers to and from books-currency accounts dated by then. An
+// account without such transfers has no recorded rate and so no gain or loss;
+// one whose currency has no rate is listed but not revalued, and its currency
+// is reported under Missing.
+func (s *Store) GetFXReport(asOf string, supplied map[string]float64) (FXReport, error) {
+	report := FXReport{AsOf: asOf, BooksCurrency: BooksCurrency, Rows: []FXReportRow{}, Missing: []string{}}
+	stored, err := s.storedFXRates()
+	if err != nil {
+		return report, err
+	}
+
+	// A transfer leg's exchange_rate converts the source amount into the
+	// destination's, so books money arriving in a foreign account was worth
+	// amount / rate and foreign money leaving for a books account amount * rate,
+	// both still to be scaled from the foreign currency's smallest unit below.
+	rows, err := s.db.Query(`SELECT a.id, a.name, COALESCE(a.currency, 'INR'), `+accountBalanceAsOfExpr+`,
+			COALESCE(fx.foreign_amount, 0), COALESCE(fx.books_amount, 0)
+		FROM accounts a
+		LEFT JOIN (SELECT t.account_id, SUM(t.amount) AS foreign_amount,
+				SUM(CASE t.type WHEN 'income' THEN t.amount / t.exchange_rate ELSE t.amount * t.exchange_rate END) AS books_amount
+			FROM transactions t JOIN accounts c ON c.id = t.transfer_account_id
+			WHERE t.exchange_rate IS NOT NULL AND t.exchange_rate > 0 AND COALESCE(c.currency, 'INR') = ?
+			AND COALESCE(t.transaction_date, CAST(t.created_at AS DATE)) <= ?
+			GROUP BY t.account_id) fx ON fx.account_id = a.id
+		WHERE COALESCE(a.currency, 'INR') <> ?
+		ORDER BY COALESCE(a.currency, 'INR'), a.name, a.id`, asOf, BooksCurrency, asOf, BooksCurrency)
+	if err != nil {
+		return report, err
+	}
+	defer rows.Close()
+
+	missing := map[string]bool{}
+	for rows.Next() {
+		var row FXReportRow
+		var foreignAmount, booksAmount float64
+		if err := rows.Scan(&row.AccountID, &row.AccountName, &row.Currency, &row.Balance, &foreignAmount, &booksAmount); err != nil {
+			return report, err
+		}
+		// Rates are quoted per whole unit; amounts are in each currency's
+		// smallest unit.
+		scale := float64(models.UnitScale(models.MinorUnits(row.Currency))) / float64(models.UnitScale(models.MinorUnits(BooksCurrency)))
+		booksAmount /= scale
+
+		rate, ok := supplied[row.Currency]
+		row.RateSource = "supplied"
+		if !ok {
+			rate, ok = stored[row.Currency]
+			row.RateSource = "stored"
+		}
+		if !ok {
+			row.RateSource = ""
+			if !missing[row.Currency] {
+				missing[row.Currency] = true
+				report.Missing = append(report.Missing, row.Currency)
+			}
+		} else {
+			row.Rate = &rate
+			row.RevaluedValue = models.Money(math.Round(float64(row.Balance) * rate / scale))
+			row.BookValue = row.RevaluedValue
+		}
+
+		if foreignAmount > 0 {
+			recorded := math.Round(booksAmount/foreignAmount*scale*1e6) / 1e6
+			row.RecordedRate = &recorded
+			row.BookValue = models.Money(math.Round(float64(row.Balance) * booksAmount / foreignAmount))
+		}
+		if row.Rate != nil {
+			row.GainLoss = row.RevaluedValue - row.BookValue
+			report.TotalGainLoss += row.GainLoss
+		}
+		report.Rows = append(report.Rows, row)
+	}
+	return report, rows.Err()
+}
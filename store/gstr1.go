@@ -0,0 +1,272 @@
+package store
+
+import (
+	"strings"
+	"time"
+
+	"github.com/satheeshds/portal/models"
+)
+
+// b2clThreshold is the invoice value above which an inter-state supply to an
+// unregistered buyer is reported invoice by invoice (B2CL) rather than in the
+// B2CS summary. It was lowered from ₹2,50,000 to ₹1,00,000 from August 2024.
+func b2clThreshold(d models.Date) models.Money {
+	if d.String() < "2024-08-01" {
+		return models.WholeUnits(250000)
+	}
+	return models.WholeUnits(100000)
+}
+
+// GSTR1Row is one invoice of the sales register.
+type GSTR1Row struct {
+	InvoiceID     int          `json:"invoice_id"`
+	InvoiceNumber string       `json:"invoice_number"`
+	InvoiceDate   models.Date  `json:"invoice_date"`
+	ContactID     *int         `json:"contact_id"`
+	ContactName   string       `json:"contact_name"`
+	GSTIN         *string      `json:"gstin"`           // the buyer's, null for unregistered buyers
+	PlaceOfSupply string       `json:"place_of_supply"` // two-digit state code
+	SupplyType    string       `json:"supply_type"`     // INTRA or INTER
+	Section       string       `json:"section"`         // b2b, b2cl, or b2cs
+	InvoiceValue  models.Money `json:"invoice_value"`
+	TaxableValue  models.Money `json:"taxable_value"`
+	TaxRate       *float64     `json:"tax_rate"`
+	TaxAmount     models.Money `json:"tax_amount"`
+	IGST          models.Money `json:"igst"`
+	CGST          models.Money `json:"cgst"`
+	SGST          models.Money `json:"sgst"`
+}
+
+// GSTR1ItemDetail is the tax of one rate on an invoice, in the GSTR-1 schema.
+// Amounts are in rupees.
+type GSTR1ItemDetail struct {
+	TaxableValue float64 `json:"txval"`
+	Rate         float64 `json:"rt"`
+	IGST         float64 `json:"iamt"`
+	CGST         float64 `json:"camt"`
+	SGST         float64 `json:"samt"`
+	Cess         float64 `json:"csamt"`
+}
+
+// GSTR1Item numbers an item detail within an invoice.
+type GSTR1Item struct {
+	Num    int             `json:"num"`
+	Detail GSTR1ItemDetail `json:"itm_det"`
+}
+
+// GSTR1Invoice is an invoice listed in the b2b or b2cl section.
+type GSTR1Invoice struct {
+	Number        string      `json:"inum"`
+	Date          string      `json:"idt"` // DD-MM-YYYY
+	Value         float64     `json:"val"`
+	PlaceOfSupply string      `json:"pos"`
+	ReverseCharge string      `json:"rchrg,omitempty"`
+	InvoiceType   string      `json:"inv_typ,omitempty"`
+	Items         []GSTR1Item `json:"itms"`
+}
+
+// GSTR1B2B lists the invoices issued to one registered buyer.
+type GSTR1B2B struct {
+	CTIN     string         `json:"ctin"`
+	Invoices []GSTR1Invoice `json:"inv"`
+}
+
+// GSTR1B2CL lists large inter-state invoices to unregistered buyers for one
+// place of supply.
+type GSTR1B2CL struct {
+	PlaceOfSupply string         `json:"pos"`
+	Invoices      []GSTR1Invoice `json:"inv"`
+}
+
+// GSTR1B2CS sums the remaining supplies to unregistered buyers per supply
+// type, place of supply, and rate.
+type GSTR1B2CS struct {
+	SupplyType    string  `json:"sply_ty"`
+	PlaceOfSupply string  `json:"pos"`
+	Type          string  `json:"typ"`
+	TaxableValue  float64 `json:"txval"`
+	Rate          float64 `json:"rt"`
+	IGST          float64 `json:"iamt"`
+	CGST          float64 `json:"camt"`
+	SGST          float64 `json:"samt"`
+	Cess          float64 `json:"csamt"`
+}
+
+// GSTR1Return is the register arranged in the sections of the GSTR-1 JSON
+// schema.
+type GSTR1Return struct {
+	GSTIN  string      `json:"gstin"`
+	Period string      `json:"fp,omitempty"` // MMYYYY, set when the range is one calendar month
+	B2B    []GSTR1B2B  `json:"b2b"`
+	B2CL   []GSTR1B2CL `json:"b2cl"`
+	B2CS   []GSTR1B2CS `json:"b2cs"`
+}
+
+// GSTR1Report is the sales register for a date range.
+type GSTR1Report struct {
+	From              string       `json:"from"`
+	To                string       `json:"to"`
+	Return            GSTR1Return  `json:"gstr1"`
+	Register          []GSTR1Row   `json:"register"`
+	Excluded          []GSTR1Row   `json:"excluded"` // invoices with no tax_rate, left out of the return
+	TotalTaxableValue models.Money `json:"total_taxable_value"`
+	TotalTax          models.Money `json:"total_tax"`
+}
+
+// GetGSTR1 builds the sales register of invoices issued between from and to
+// (YYYY-MM-DD, either may be empty) by the business registered as gstin.
+// Draft, cancelled, and undated invoices are left out, and invoices without a
+// tax_rate are listed under Excluded. The place of supply is the invoice's,
+// else the state of the buyer's GSTIN, else the business's own state; a supply
+// within the business's state is taxed as CGST and SGST, any other as IGST.
+func (s *Store) GetGSTR1(from, to, gstin string) (GSTR1Report, error) {
+	report := GSTR1Report{From: from, To: to, Register: []GSTR1Row{}, Excluded: []GSTR1Row{},
+		Return: GSTR1Return{GSTIN: gstin, Period: gstr1Period(from, to)}}
+
+	conditions := []string{"i.status NOT IN ('draft', 'cancelled')", "i.issue_date IS NOT NULL"}
+	var args []any
+	if from != "" {
+		conditions = append(conditions, "i.issue_date >= ?")
+		args = append(args, from)
+	}
+	if to != "" {
+		conditions = append(conditions, "i.issue_date <= ?")
+		args = append(args, to)
+	}
+	rows, err := s.db.Query(`SELECT i.id, COALESCE(i.invoice_number, ''), i.issue_date, i.contact_id, COALESCE(c.name, ''),
+		c.gstin, COALESCE(i.place_of_supply, ''), i.amount, i.tax_rate, COALESCE(i.tax_amount, 0)
+		FROM invoices i
+		LEFT JOIN contacts c ON c.id = i.contact_id
+		WHERE `+strings.Join(conditions, " AND ")+`
+		ORDER BY i.issue_date, i.id`, args...)
+	if err != nil {
+		return report, err
+	}
+	defer rows.Close()
+
+	home := gstin[:2]
+	for rows.Next() {
+		var row GSTR1Row
+		if err := rows.Scan(&row.InvoiceID, &row.InvoiceNumber, &row.InvoiceDate, &row.ContactID, &row.ContactName,
+			&row.GSTIN, &row.PlaceOfSupply, &row.InvoiceValue, &row.TaxRate, &row.TaxAmount); err != nil {
+			return report, err
+		}
+		if row.PlaceOfSupply == "" {
+			row.PlaceOfSupply = home
+			if row.GSTIN != nil && len(*row.GSTIN) >= 2 {
+				row.PlaceOfSupply = (*row.GSTIN)[:2]
+			}
+		}
+		row.TaxableValue = row.InvoiceValue - row.TaxAmount
+		if row.PlaceOfSupply == home {
+			// An odd paisa goes to SGST so the halves add up.
+			row.SupplyType = "INTRA"
+			row.CGST = row.TaxAmount / 2
+			row.SGST = row.TaxAmount - row.CGST
+		} else {
+			row.SupplyType = "INTER"
+			row.IGST = row.TaxAmount
+		}
+		switch {
+		case row.GSTIN != nil:
+			row.Section = "b2b"
+		case row.SupplyType == "INTER" && row.InvoiceValue > b2clThreshold(row.InvoiceDate):
+			row.Section = "b2cl"
+		default:
+			row.Section = "b2cs"
+		}
+		if row.TaxRate == nil {
+			report.Excluded = append(report.Excluded, row)
+			continue
+		}
+		report.Register = append(report.Register, row)
+		report.TotalTaxableValue += row.TaxableValue
+		report.TotalTax += row.TaxAmount
+	}
+	if err := rows.Err(); err != nil {
+		return report, err
+	}
+
+	report.Return.B2B, report.Return.B2CL, report.Return.B2CS = gstr1Sections(report.Register)
+	return report, nil
+}
+
+// gstr1Sections arranges register rows into the b2b, b2cl, and b2cs sections
+// of the return, keeping the order in which buyers, places, and rates first
+// appear. B2CS sums are kept in paise and converted once.
+func gstr1Sections(register []GSTR1Row) ([]GSTR1B2B, []GSTR1B2CL, []GSTR1B2CS) {
+	b2b, b2cl := []GSTR1B2B{}, []GSTR1B2CL{}
+	b2bIndex, b2clIndex := map[string]int{}, map[string]int{}
+	type b2csKey struct {
+		supplyType, pos string
+		rate            float64
+	}
+	var b2csKeys []b2csKey
+	b2csSums := map[b2csKey]*GSTR1Row{}
+
+	for _, row := range register {
+		inv := GSTR1Invoice{Number: row.InvoiceNumber, Date: row.InvoiceDate.Format("02-01-2006"),
+			Value: row.InvoiceValue.ToFloat(), PlaceOfSupply: row.PlaceOfSupply,
+			Items: []GSTR1Item{{Num: 1, Detail: gstr1Detail(row)}}}
+		switch row.Section {
+		case "b2b":
+			inv.ReverseCharge, inv.InvoiceType = "N", "R"
+			i, ok := b2bIndex[*row.GSTIN]
+			if !ok {
+				i = len(b2b)
+				b2bIndex[*row.GSTIN] = i
+				b2b = append(b2b, GSTR1B2B{CTIN: *row.GSTIN})
+			}
+			b2b[i].Invoices = append(b2b[i].Invoices, inv)
+		case "b2cl":
+			i, ok := b2clIndex[row.PlaceOfSupply]
+			if !ok {
+				i = len(b2cl)
+				b2clIndex[row.PlaceOfSupply] = i
+				b2cl = append(b2cl, GSTR1B2CL{PlaceOfSupply: row.PlaceOfSupply})
+			}
+			b2cl[i].Invoices = append(b2cl[i].Invoices, inv)
+		default:
+			key := b2csKey{row.SupplyType, row.PlaceOfSupply, *row.TaxRate}
+			sum, ok := b2csSums[key]
+			if !ok {
+				sum = &GSTR1Row{SupplyType: row.SupplyType, PlaceOfSupply: row.PlaceOfSupply, TaxRate: row.TaxRate}
+				b2csSums[key] = sum
+				b2csKeys = append(b2csKeys, key)
+			}
+			sum.TaxableValue += row.TaxableValue
+			sum.IGST += row.IGST
+			sum.CGST += row.CGST
+			sum.SGST += row.SGST
+		}
+	}
+
+	b2cs := make([]GSTR1B2CS, 0, len(b2csKeys))
+	for _, key := range b2csKeys {
+		sum := b2csSums[key]
+		d := gstr1Detail(*sum)
+		b2cs = append(b2cs, GSTR1B2CS{SupplyType: sum.SupplyType, PlaceOfSupply: sum.PlaceOfSupply, Type: "OE",
+			TaxableValue: d.TaxableValue, Rate: d.Rate, IGST: d.IGST, CGST: d.CGST, SGST: d.SGST})
+	}
+	return b2b, b2cl, b2cs
+}
+
+// gstr1Detail converts the taxable value and taxes of row to rupees.
+func gstr1Detail(row GSTR1Row) GSTR1ItemDetail {
+	return GSTR1ItemDetail{TaxableValue: row.TaxableValue.ToFloat(), Rate: *row.TaxRate,
+		IGST: row.IGST.ToFloat(), CGST: row.CGST.ToFloat(), SGST: row.SGST.ToFloat()}
+}
+
+// gstr1Period returns the MMYYYY return period when from and to span exactly
+// one calendar month, or "".
+func gstr1Period(from, to string) string {
+	start, err := time.Parse("2006-01-02", from)
+	if err != nil || start.Day() != 1 {
+		return ""
+	}
+	end, err := time.Parse("2006-01-02", to)
+	if err != nil || !end.Equal(start.AddDate(0, 1, -1)) {
+		return ""
+	}
+	return start.Format("012006")
+}
@@ -0,0 +1,55 @@
+package store
+
+import (
+	"strconv"
+)
+
+// ContactDeleteImpact counts the rows that reference a contact. Deleting the
+// contact does not cascade, so these rows would be left without their contact.
+type ContactDeleteImpact struct {
+	Bills             int `json:"bills"`
+	Invoices          int `json:"invoices"`
+	Transactions      int `json:"transactions"`
+	RecurringPayments int `json:"recurring_payments"`
+	Total             int `json:"total"`
+}
+
+// AccountDeleteImpact counts the rows that reference an account. Deleting the
+// account does not cascade, so these rows would be left without their account.
+type AccountDeleteImpact struct {
+	Transactions      int `json:"transactions"`       // posted on the account
+	TransferLegs      int `json:"transfer_legs"`      // legs on other accounts naming it as the transfer counterparty
+	RecurringPayments int `json:"recurring_payments"` // paid from the account
+	Defaults          int `json:"defaults"`           // default accounts pointing at it
+	Total             int `json:"total"`
+}
+
+// GetContactDeleteImpact counts the bills, invoices, transactions, and
+// recurring payments that reference contact id. It does not check that the
+// contact exists.
+func (s *Store) GetContactDeleteImpact(id int) (ContactDeleteImpact, error) {
+	var impact ContactDeleteImpact
+	err := s.db.QueryRow(`SELECT
+		(SELECT COUNT(*) FROM bills WHERE contact_id = ?),
+		(SELECT COUNT(*) FROM invoices WHERE contact_id = ?),
+		(SELECT COUNT(*) FROM transactions WHERE contact_id = ?),
+		(SELECT COUNT(*) FROM recurring_payments WHERE contact_id = ?)`, id, id, id, id).
+		Scan(&impact.Bills, &impact.Invoices, &impact.Transactions, &impact.RecurringPayments)
+	impact.Total = impact.Bills + impact.Invoices + impact.Transactions + impact.RecurringPayments
+	return impact, err
+}
+
+// GetAccountDeleteImpact counts the transactions, recurring payments, and
+// defaults that reference account id. It does not check that the account
+// exists.
+func (s *Store) GetAccountDeleteImpact(id int) (AccountDeleteImpact, error) {
+	var impact AccountDeleteImpact
+	err := s.db.QueryRow(`SELECT
+		(SELECT COUNT(*) FROM transactions WHERE account_id = ?),
+		(SELECT COUNT(*) FROM transactions WHERE transfer_account_id = ?),
+		(SELECT COUNT(*) FROM recurring_payments WHERE account_id = ?),
+		(SELECT COUNT(*) FROM defaults WHERE key LIKE 'default_account.%' AND value = ?)`, id, id, id, strconv.Itoa(id)).
+		Scan(&impact.Transactions, &impact.TransferLegs, &impact.RecurringPayments, &impact.Defaults)
+	impact.Total = impact.Transactions + impact.TransferLegs + impact.RecurringPayments + impact.Defaults
+	return impact, err
+}
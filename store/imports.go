@@ -0,0 +1,81 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/satheeshds/portal/models"
+)
+
+// ImportResult summarises a statement import.
+type ImportResult struct {
+	Imported       int                  `json:"imported"`          // transactions created
+	Skipped        int                  `json:"skipped"`           // lines already imported, matched by external_id
+	TransactionIDs []int                `json:"transaction_ids"`   // IDs of the created transactions
+	Errors         []models.ImportError `json:"errors"`            // lines that could not be imported
+	Columns        map[string]string    `json:"columns,omitempty"` // CSV only: the header each field was read from
+}
+
+// ImportStatement creates a transaction on accountID for each statement line:
+// credits become income and debits expense. Lines whose external_id already
+// exists on the account from the same source, or repeats earlier in the same
+// file, are skipped so re-importing a statement is idempotent. Created
+// transactions record source (e.g. "ofx"). All inserts run in one
+// transaction.
+func (s *Store) ImportStatement(accountID int, source string, lines []models.StatementLine) (ImportResult, error) {
+	result := ImportResult{TransactionIDs: []int{}, Errors: []models.ImportError{}}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return result, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	seen := make(map[string]bool, len(lines))
+	for _, line := range lines {
+		if line.ExternalID != "" {
+			if seen[line.ExternalID] {
+				result.Skipped++
+				continue
+			}
+			seen[line.ExternalID] = true
+
+			var existing int
+			err := tx.QueryRow("SELECT id FROM transactions WHERE account_id = ? AND COALESCE(source, '') = ? AND external_id = ? LIMIT 1",
+				accountID, source, line.ExternalID).Scan(&existing)
+			if err == nil {
+				result.Skipped++
+				continue
+			}
+			if !errors.Is(err, sql.ErrNoRows) {
+				return result, err
+			}
+		}
+
+		txnType, amount := "income", line.Amount
+		if amount < 0 {
+			txnType, amount = "expense", -amount
+		}
+		id, err := insertReturningID(tx, `INSERT INTO transactions (account_id, type, amount, transaction_date, description, reference, external_id, source)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			accountID, txnType, amount, line.Date, nullIfEmpty(line.Description), nullIfEmpty(line.Reference), nullIfEmpty(line.ExternalID), source)
+		if err != nil {
+			return result, err
+		}
+		result.Imported++
+		result.TransactionIDs = append(result.TransactionIDs, id)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// nullIfEmpty returns nil for an empty string so it is stored as NULL.
+func nullIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
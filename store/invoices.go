@@ -2,14 +2,20 @@ package store
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
 
 	"github.com/satheeshds/portal/db"
 	"github.com/satheeshds/portal/models"
 )
 
-const invoiceSelectQuery = `SELECT i.id, i.contact_id, i.invoice_number, i.issue_date, i.due_date, i.amount,
-		i.status, i.file_url, i.notes, i.created_at, i.updated_at,
+const invoiceSelectQuery = `SELECT i.id, i.contact_id, COALESCE(i.invoice_number, ''), i.issue_date, i.due_date, i.amount,
+		i.status, i.file_url, i.notes, i.sent_at, i.created_at, i.updated_at,
+		i.tax_rate, COALESCE(i.tax_amount, 0), i.place_of_supply,
 		c.name,
 		COALESCE((SELECT SUM(td.amount) FROM transaction_documents td WHERE td.document_type = 'invoice' AND td.document_id = i.id), 0)
 		FROM invoices i
@@ -27,10 +33,13 @@ type InvoiceLink struct {
 func scanInvoice(scanner interface{ Scan(...any) error }) (models.Invoice, error) {
 	var inv models.Invoice
 	err := scanner.Scan(&inv.ID, &inv.ContactID, &inv.InvoiceNumber, &inv.IssueDate, &inv.DueDate,
-		&inv.Amount, &inv.Status, &inv.FileURL, &inv.Notes, &inv.CreatedAt, &inv.UpdatedAt,
+		&inv.Amount, &inv.Status, &inv.FileURL, &inv.Notes, &inv.SentAt, &inv.CreatedAt, &inv.UpdatedAt,
+		&inv.TaxRate, &inv.TaxAmount, &inv.PlaceOfSupply,
 		&inv.ContactName, &inv.Allocated)
 	if err == nil {
 		inv.Unallocated = models.Money(int64(inv.Amount) - int64(inv.Allocated))
+		inv.AllocatedPct = models.AllocatedPct(inv.Allocated, inv.Amount)
+		inv.DaysToDue = inv.DueDate.DaysUntil(time.Now())
 	}
 	return inv, err
 }
@@ -44,24 +53,108 @@ func (s *Store) getInvoiceByID(id int) (models.Invoice, error) {
 	return inv, err
 }
 
+const invoiceItemSelectQuery = `SELECT id, invoice_id, description, quantity, unit, unit_price, amount, tax_rate, hsn_code,
+	created_at, updated_at
+	FROM invoice_items`
+
+func scanInvoiceItem(scanner interface{ Scan(...any) error }) (models.InvoiceItem, error) {
+	var item models.InvoiceItem
+	err := scanner.Scan(&item.ID, &item.InvoiceID, &item.Description, &item.Quantity, &item.Unit, &item.UnitPrice,
+		&item.Amount, &item.TaxRate, &item.HSNCode, &item.CreatedAt, &item.UpdatedAt)
+	if err == nil && item.TaxRate != nil {
+		item.TaxAmount = models.IncludedTax(item.Amount, *item.TaxRate)
+	}
+	return item, err
+}
+
 func insertInvoiceItems(tx *db.PortalTx, invoiceID int, items []models.InvoiceItemInput) error {
-	stmt, err := tx.Prepare(`INSERT INTO invoice_items (invoice_id, description, quantity, unit, unit_price, amount)
-		VALUES (?, ?, ?, ?, ?, ?)`)
+	stmt, err := tx.Prepare(`INSERT INTO invoice_items (invoice_id, description, quantity, unit, unit_price, amount, tax_rate, hsn_code)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`)
 	if err != nil {
 		return err
 	}
 	defer stmt.Close()
 
 	for _, item := range items {
-		if _, err := stmt.Exec(invoiceID, item.Description, item.Quantity, item.Unit, item.UnitPrice, item.Amount); err != nil {
+		if _, err := stmt.Exec(invoiceID, item.Description, item.Quantity, item.Unit, item.UnitPrice, item.Amount,
+			item.TaxRate, item.HSNCode); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// syncInvoiceTotals recomputes the amount and tax of invoice id from its
+// stored items as models.InvoiceInput.ApplyItems does, saving any tax rate an
+// item takes from the invoice, and refreshes the customer's balances. An
+// invoice without items is left as it is.
+func syncInvoiceTotals(tx *db.PortalTx, id int) error {
+	var input models.InvoiceInput
+	if err := tx.QueryRow("SELECT contact_id, tax_rate, COALESCE(tax_amount, 0) FROM invoices WHERE id = ?", id).Scan(
+		&input.ContactID, &input.TaxRate, &input.TaxAmount); err != nil {
+		return err
+	}
+	rows, err := tx.Query("SELECT id, amount, tax_rate FROM invoice_items WHERE invoice_id = ? ORDER BY id", id)
+	if err != nil {
+		return err
+	}
+	var itemIDs []int
+	for rows.Next() {
+		var itemID int
+		var item models.InvoiceItemInput
+		if err := rows.Scan(&itemID, &item.Amount, &item.TaxRate); err != nil {
+			rows.Close()
+			return err
+		}
+		itemIDs = append(itemIDs, itemID)
+		input.Items = append(input.Items, item)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(input.Items) == 0 {
+		return nil
+	}
+
+	unrated := make([]bool, len(input.Items))
+	for idx, item := range input.Items {
+		unrated[idx] = item.TaxRate == nil
+	}
+	input.ApplyItems()
+	for idx, item := range input.Items {
+		if unrated[idx] && item.TaxRate != nil {
+			if _, err := tx.Exec("UPDATE invoice_items SET tax_rate = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?",
+				item.TaxRate, itemIDs[idx]); err != nil {
+				return err
+			}
+		}
+	}
+	if _, err := tx.Exec("UPDATE invoices SET amount = ?, tax_rate = ?, tax_amount = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?",
+		input.Amount, input.TaxRate, input.TaxAmount, id); err != nil {
+		return err
+	}
+	return refreshContactBalances(tx, input.ContactID)
+}
+
 // ListInvoices returns invoices filtered by the provided parameters (all may be empty).
-func (s *Store) ListInvoices(status, contactID, from, to, search string) ([]models.Invoice, error) {
+// Cancelled invoices are left out unless includeCancelled is set or status asks for them.
+func (s *Store) ListInvoices(status, contactID, from, to, search string, includeCancelled bool) ([]models.Invoice, error) {
+	invoices := []models.Invoice{}
+	err := s.EachInvoice(status, contactID, from, to, search, includeCancelled, func(inv models.Invoice) error {
+		invoices = append(invoices, inv)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return invoices, nil
+}
+
+// EachInvoice calls fn for each invoice matching the same filters as ListInvoices,
+// in the same order, without holding the whole result in memory. It stops at
+// the first error returned by fn.
+func (s *Store) EachInvoice(status, contactID, from, to, search string, includeCancelled bool, fn func(models.Invoice) error) error {
 	query := invoiceSelectQuery
 	var conditions []string
 	var args []any
@@ -69,6 +162,8 @@ func (s *Store) ListInvoices(status, contactID, from, to, search string) ([]mode
 	if status != "" {
 		conditions = append(conditions, "i.status = ?")
 		args = append(args, status)
+	} else if !includeCancelled {
+		conditions = append(conditions, "i.status <> 'cancelled'")
 	}
 	if contactID != "" {
 		conditions = append(conditions, "i.contact_id = ?")
@@ -95,23 +190,21 @@ func (s *Store) ListInvoices(status, contactID, from, to, search string) ([]mode
 
 	rows, err := s.db.Query(query, args...)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
 
-	var invoices []models.Invoice
 	for rows.Next() {
 		inv, err := scanInvoice(rows)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		inv.Items = []models.InvoiceItem{}
-		invoices = append(invoices, inv)
-	}
-	if invoices == nil {
-		invoices = []models.Invoice{}
+		if err := fn(inv); err != nil {
+			return err
+		}
 	}
-	return invoices, nil
+	return rows.Err()
 }
 
 // GetInvoice returns a single invoice by ID, including its line items. Returns sql.ErrNoRows if not found.
@@ -127,25 +220,34 @@ func (s *Store) CreateInvoice(input models.InvoiceInput) (models.Invoice, error)
 	}
 	defer func() { _ = tx.Rollback() }()
 
-	var id int
-	err = tx.QueryRow(`INSERT INTO invoices (contact_id, invoice_number, issue_date, due_date, amount, status, file_url, notes)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?) RETURNING id`,
-		input.ContactID, input.InvoiceNumber, input.IssueDate, input.DueDate,
-		input.Amount, input.Status, input.FileURL, input.Notes).Scan(&id)
+	id, err := insertInvoice(tx, input)
 	if err != nil {
 		return models.Invoice{}, err
 	}
 
-	if err := insertInvoiceItems(tx, id, input.Items); err != nil {
-		return models.Invoice{}, err
-	}
-
 	if err := tx.Commit(); err != nil {
 		return models.Invoice{}, err
 	}
 	return s.getInvoiceByID(id)
 }
 
+// insertInvoice inserts the invoice CreateInvoice creates, with its items,
+// refreshes its contact's balance, and returns its id.
+func insertInvoice(tx *db.PortalTx, input models.InvoiceInput) (int, error) {
+	id, err := insertReturningID(tx, `INSERT INTO invoices (contact_id, invoice_number, issue_date, due_date, amount, status, file_url, notes,
+		tax_rate, tax_amount, place_of_supply)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		input.ContactID, nullIfEmpty(input.InvoiceNumber), input.IssueDate, input.DueDate,
+		input.Amount, input.Status, input.FileURL, input.Notes, input.TaxRate, input.TaxAmount, input.PlaceOfSupply)
+	if err != nil {
+		return 0, err
+	}
+	if err := insertInvoiceItems(tx, id, input.Items); err != nil {
+		return 0, err
+	}
+	return id, refreshContactBalances(tx, input.ContactID)
+}
+
 // UpdateInvoice updates an existing invoice and its items. Returns sql.ErrNoRows if not found.
 func (s *Store) UpdateInvoice(id int, input models.InvoiceInput) (models.Invoice, error) {
 	tx, err := s.db.Begin()
@@ -154,10 +256,15 @@ func (s *Store) UpdateInvoice(id int, input models.InvoiceInput) (models.Invoice
 	}
 	defer func() { _ = tx.Rollback() }()
 
+	previousContactID, err := documentContactID(tx, "invoices", id)
+	if err != nil {
+		return models.Invoice{}, err
+	}
 	res, err := tx.Exec(`UPDATE invoices SET contact_id = ?, invoice_number = ?, issue_date = ?, due_date = ?,
-		amount = ?, status = ?, file_url = ?, notes = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
-		input.ContactID, input.InvoiceNumber, input.IssueDate, input.DueDate,
-		input.Amount, input.Status, input.FileURL, input.Notes, id)
+		amount = ?, status = ?, file_url = ?, notes = ?, tax_rate = ?, tax_amount = ?, place_of_supply = ?,
+		updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
+		input.ContactID, nullIfEmpty(input.InvoiceNumber), input.IssueDate, input.DueDate,
+		input.Amount, input.Status, input.FileURL, input.Notes, input.TaxRate, input.TaxAmount, input.PlaceOfSupply, id)
 	if err != nil {
 		return models.Invoice{}, err
 	}
@@ -172,6 +279,12 @@ func (s *Store) UpdateInvoice(id int, input models.InvoiceInput) (models.Invoice
 		if err := insertInvoiceItems(tx, id, input.Items); err != nil {
 			return models.Invoice{}, err
 		}
+	} else if err := syncInvoiceTotals(tx, id); err != nil {
+		// The stored items, left in place, still decide the amount.
+		return models.Invoice{}, err
+	}
+	if err := refreshContactBalances(tx, previousContactID, input.ContactID); err != nil {
+		return models.Invoice{}, err
 	}
 
 	if err := tx.Commit(); err != nil {
@@ -180,6 +293,117 @@ func (s *Store) UpdateInvoice(id int, input models.InvoiceInput) (models.Invoice
 	return s.getInvoiceByID(id)
 }
 
+// CloneInvoice copies invoice id and its line items into a new draft issued
+// on issueDate (YYYY-MM-DD). The due date keeps the original's payment term,
+// the number continues the original's sequence (see nextInvoiceNumber), and
+// the copy starts unsent with no allocations. Returns sql.ErrNoRows if not
+// found.
+func (s *Store) CloneInvoice(id int, issueDate string) (models.Invoice, error) {
+	src, err := s.getInvoiceByID(id)
+	if err != nil {
+		return models.Invoice{}, err
+	}
+	number, err := s.nextInvoiceNumber(src.InvoiceNumber)
+	if err != nil {
+		return models.Invoice{}, err
+	}
+	input := models.InvoiceInput{
+		ContactID:     src.ContactID,
+		InvoiceNumber: number,
+		IssueDate:     &issueDate,
+		Amount:        src.Amount,
+		Status:        "draft",
+		FileURL:       src.FileURL,
+		Notes:         src.Notes,
+		Items:         make([]models.InvoiceItemInput, 0, len(src.Items)),
+		TaxRate:       src.TaxRate,
+		TaxAmount:     src.TaxAmount,
+		PlaceOfSupply: src.PlaceOfSupply,
+	}
+	if !src.IssueDate.IsZero() && !src.DueDate.IsZero() {
+		issued, err := time.Parse("2006-01-02", issueDate)
+		if err != nil {
+			return models.Invoice{}, err
+		}
+		due := issued.Add(src.DueDate.Sub(src.IssueDate.Time)).Format("2006-01-02")
+		input.DueDate = &due
+	}
+	for _, item := range src.Items {
+		input.Items = append(input.Items, models.InvoiceItemInput{Description: item.Description, Quantity: item.Quantity,
+			Unit: item.Unit, UnitPrice: item.UnitPrice, Amount: item.Amount, TaxRate: item.TaxRate, HSNCode: item.HSNCode})
+	}
+	return s.CreateInvoice(input)
+}
+
+// nextInvoiceNumber returns the number following after in its sequence: the
+// highest existing number sharing after's prefix, plus one, keeping the width
+// of after's trailing digits ("INV-0042" becomes "INV-0043", or later if
+// taken). A number without trailing digits starts a "-2" sequence, and an
+// empty one continues the INV- sequence.
+func (s *Store) nextInvoiceNumber(after string) (string, error) {
+	prefix := strings.TrimRightFunc(after, unicode.IsDigit)
+	digits := after[len(prefix):]
+	switch {
+	case after == "":
+		prefix, digits = "INV-", "0000"
+	case digits == "":
+		prefix, digits = after+"-", "1"
+	}
+	next, _ := strconv.Atoi(digits)
+
+	rows, err := s.db.Query("SELECT invoice_number FROM invoices WHERE invoice_number LIKE ?", prefix+"%")
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var number string
+		if err := rows.Scan(&number); err != nil {
+			return "", err
+		}
+		suffix, ok := strings.CutPrefix(number, prefix)
+		if !ok || suffix == "" || strings.TrimLeftFunc(suffix, unicode.IsDigit) != "" {
+			continue
+		}
+		if n, err := strconv.Atoi(suffix); err == nil && n > next {
+			next = n
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s%0*d", prefix, len(digits), next+1), nil
+}
+
+// VoidInvoice marks an invoice as cancelled, keeping it and its items for the record.
+// Returns sql.ErrNoRows if not found.
+func (s *Store) VoidInvoice(id int) error {
+	res, err := s.db.Exec("UPDATE invoices SET status = 'cancelled', updated_at = CURRENT_TIMESTAMP WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	s.refreshDocumentContact("invoices", id)
+	return nil
+}
+
+// SendInvoice records that an invoice was sent now. A draft moves to sent;
+// other statuses are kept, so re-sending only refreshes sent_at. Returns
+// sql.ErrNoRows if not found.
+func (s *Store) SendInvoice(id int) error {
+	res, err := s.db.Exec(`UPDATE invoices SET status = CASE WHEN status = 'draft' THEN 'sent' ELSE status END,
+		sent_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
 // DeleteInvoice removes an invoice and its items/links. Returns sql.ErrNoRows if not found.
 func (s *Store) DeleteInvoice(id int) error {
 	tx, err := s.db.Begin()
@@ -187,6 +411,15 @@ func (s *Store) DeleteInvoice(id int) error {
 		return err
 	}
 
+	contactID, err := documentContactID(tx, "invoices", id)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err := touchLinkedTransactions(tx, "invoice", id); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
 	if _, err := tx.Exec("DELETE FROM transaction_documents WHERE document_type = 'invoice' AND document_id = ?", id); err != nil {
 		_ = tx.Rollback()
 		return err
@@ -210,18 +443,27 @@ func (s *Store) DeleteInvoice(id int) error {
 		_ = tx.Rollback()
 		return sql.ErrNoRows
 	}
+	if err := recordDeletion(tx, "invoice", id); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err := refreshContactBalances(tx, contactID); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
 
 	return tx.Commit()
 }
 
-// GetInvoiceLinks returns all transaction links for the given invoice.
-func (s *Store) GetInvoiceLinks(id int) ([]InvoiceLink, error) {
-	rows, err := s.db.Query(`SELECT td.id, td.transaction_id, td.document_type, td.document_id, td.amount, td.created_at,
+// GetInvoiceLinks returns transaction links for the given invoice, ordered and paginated by page.
+func (s *Store) GetInvoiceLinks(id int, page LinkPage) ([]InvoiceLink, error) {
+	suffix, args := page.clause("td")
+	rows, err := s.db.Query(`SELECT td.id, td.transaction_id, td.document_type, td.document_id, td.amount, td.note, td.created_at,
 		COALESCE(t.transaction_date, ''), COALESCE(t.description, ''), COALESCE(t.reference, ''), a.name as account_name
 		FROM transaction_documents td
 		JOIN transactions t ON td.transaction_id = t.id
 		JOIN accounts a ON t.account_id = a.id
-		WHERE td.document_type = 'invoice' AND td.document_id = ?`, id)
+		WHERE td.document_type = 'invoice' AND td.document_id = ?`+suffix, append([]any{id}, args...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -230,7 +472,7 @@ func (s *Store) GetInvoiceLinks(id int) ([]InvoiceLink, error) {
 	var links []InvoiceLink
 	for rows.Next() {
 		var l InvoiceLink
-		if err := rows.Scan(&l.ID, &l.TransactionID, &l.DocumentType, &l.DocumentID, &l.Amount, &l.CreatedAt,
+		if err := rows.Scan(&l.ID, &l.TransactionID, &l.DocumentType, &l.DocumentID, &l.Amount, &l.Note, &l.CreatedAt,
 			&l.TransactionDate, &l.Description, &l.Reference, &l.AccountName); err != nil {
 			return nil, err
 		}
@@ -251,8 +493,7 @@ func (s *Store) InvoiceExists(id int) (bool, error) {
 
 // ListInvoiceItems returns all line items for an invoice.
 func (s *Store) ListInvoiceItems(invoiceID int) ([]models.InvoiceItem, error) {
-	rows, err := s.db.Query(`SELECT id, invoice_id, description, quantity, unit, unit_price, amount, created_at, updated_at
-		FROM invoice_items WHERE invoice_id = ? ORDER BY id ASC`, invoiceID)
+	rows, err := s.db.Query(invoiceItemSelectQuery+" WHERE invoice_id = ? ORDER BY id ASC", invoiceID)
 	if err != nil {
 		return nil, err
 	}
@@ -260,9 +501,8 @@ func (s *Store) ListInvoiceItems(invoiceID int) ([]models.InvoiceItem, error) {
 
 	var items []models.InvoiceItem
 	for rows.Next() {
-		var item models.InvoiceItem
-		if err := rows.Scan(&item.ID, &item.InvoiceID, &item.Description, &item.Quantity,
-			&item.Unit, &item.UnitPrice, &item.Amount, &item.CreatedAt, &item.UpdatedAt); err != nil {
+		item, err := scanInvoiceItem(rows)
+		if err != nil {
 			return nil, err
 		}
 		items = append(items, item)
@@ -276,52 +516,247 @@ func (s *Store) ListInvoiceItems(invoiceID int) ([]models.InvoiceItem, error) {
 	return items, nil
 }
 
-// CreateInvoiceItem inserts a new line item for an invoice and returns it.
+// CreateInvoiceItem inserts a new line item for an invoice, recomputes the
+// invoice's amount and tax from its items and its status from the new amount,
+// and returns the item.
 func (s *Store) CreateInvoiceItem(invoiceID int, input models.InvoiceItemInput) (models.InvoiceItem, error) {
-	var itemID int
-	err := s.db.QueryRow(`INSERT INTO invoice_items (invoice_id, description, quantity, unit, unit_price, amount)
-		VALUES (?, ?, ?, ?, ?, ?) RETURNING id`,
-		invoiceID, input.Description, input.Quantity, input.Unit, input.UnitPrice, input.Amount).Scan(&itemID)
+	tx, err := s.db.Begin()
 	if err != nil {
 		return models.InvoiceItem{}, err
 	}
+	defer func() { _ = tx.Rollback() }()
 
-	var item models.InvoiceItem
-	err = s.db.QueryRow(`SELECT id, invoice_id, description, quantity, unit, unit_price, amount, created_at, updated_at
-		FROM invoice_items WHERE id = ?`, itemID).Scan(
-		&item.ID, &item.InvoiceID, &item.Description, &item.Quantity,
-		&item.Unit, &item.UnitPrice, &item.Amount, &item.CreatedAt, &item.UpdatedAt)
-	return item, err
+	itemID, err := insertReturningID(tx, `INSERT INTO invoice_items (invoice_id, description, quantity, unit, unit_price, amount, tax_rate, hsn_code)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		invoiceID, input.Description, input.Quantity, input.Unit, input.UnitPrice, input.Amount, input.TaxRate, input.HSNCode)
+	if err != nil {
+		return models.InvoiceItem{}, err
+	}
+	if err := syncInvoiceTotals(tx, invoiceID); err != nil {
+		return models.InvoiceItem{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.InvoiceItem{}, err
+	}
+	s.UpdateDocumentStatus("invoice", invoiceID)
+	return scanInvoiceItem(s.db.QueryRow(invoiceItemSelectQuery+" WHERE id = ?", itemID))
 }
 
-// UpdateInvoiceItem updates a line item. Returns sql.ErrNoRows if not found.
+// UpdateInvoiceItem updates a line item and recomputes the invoice's amount
+// and tax from its items and its status from the new amount. Returns
+// sql.ErrNoRows if not found.
 func (s *Store) UpdateInvoiceItem(invoiceID, itemID int, input models.InvoiceItemInput) (models.InvoiceItem, error) {
-	res, err := s.db.Exec(`UPDATE invoice_items SET description = ?, quantity = ?, unit = ?, unit_price = ?, amount = ?,
-		updated_at = CURRENT_TIMESTAMP WHERE id = ? AND invoice_id = ?`,
-		input.Description, input.Quantity, input.Unit, input.UnitPrice, input.Amount, itemID, invoiceID)
+	tx, err := s.db.Begin()
+	if err != nil {
+		return models.InvoiceItem{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.Exec(`UPDATE invoice_items SET description = ?, quantity = ?, unit = ?, unit_price = ?, amount = ?,
+		tax_rate = ?, hsn_code = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND invoice_id = ?`,
+		input.Description, input.Quantity, input.Unit, input.UnitPrice, input.Amount, input.TaxRate, input.HSNCode, itemID, invoiceID)
 	if err != nil {
 		return models.InvoiceItem{}, err
 	}
 	if n, _ := res.RowsAffected(); n == 0 {
 		return models.InvoiceItem{}, sql.ErrNoRows
 	}
+	if err := syncInvoiceTotals(tx, invoiceID); err != nil {
+		return models.InvoiceItem{}, err
+	}
 
-	var item models.InvoiceItem
-	err = s.db.QueryRow(`SELECT id, invoice_id, description, quantity, unit, unit_price, amount, created_at, updated_at
-		FROM invoice_items WHERE id = ?`, itemID).Scan(
-		&item.ID, &item.InvoiceID, &item.Description, &item.Quantity,
-		&item.Unit, &item.UnitPrice, &item.Amount, &item.CreatedAt, &item.UpdatedAt)
-	return item, err
+	if err := tx.Commit(); err != nil {
+		return models.InvoiceItem{}, err
+	}
+	s.UpdateDocumentStatus("invoice", invoiceID)
+	return scanInvoiceItem(s.db.QueryRow(invoiceItemSelectQuery+" WHERE id = ?", itemID))
 }
 
-// DeleteInvoiceItem removes a line item. Returns sql.ErrNoRows if not found.
+// DeleteInvoiceItem removes a line item and recomputes the invoice's amount
+// and tax from the items left, and its status from the new amount; removing
+// the last item leaves the amount as it was. Returns sql.ErrNoRows if not
+// found.
 func (s *Store) DeleteInvoiceItem(invoiceID, itemID int) error {
-	res, err := s.db.Exec("DELETE FROM invoice_items WHERE id = ? AND invoice_id = ?", itemID, invoiceID)
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.Exec("DELETE FROM invoice_items WHERE id = ? AND invoice_id = ?", itemID, invoiceID)
 	if err != nil {
 		return err
 	}
 	if n, _ := res.RowsAffected(); n == 0 {
 		return sql.ErrNoRows
 	}
+	if err := syncInvoiceTotals(tx, invoiceID); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	s.UpdateDocumentStatus("invoice", invoiceID)
 	return nil
 }
+
+// InvoiceLedgerEntry is one event in the history of an invoice. Amount is its
+// effect on the outstanding balance: the invoice amount on creation, negative
+// for allocations, and zero for events that move no money.
+type InvoiceLedgerEntry struct {
+	Date          models.Date  `json:"date"`
+	Event         string       `json:"event"` // created, sent, payment, or adjustment
+	Description   *string      `json:"description"`
+	TransactionID *int         `json:"transaction_id,omitempty"`
+	LinkID        *int         `json:"link_id,omitempty"`
+	Amount        models.Money `json:"amount"`
+	Outstanding   models.Money `json:"outstanding"` // after this entry
+}
+
+// InvoiceLedger is the chronological history of an invoice with its running
+// outstanding balance.
+type InvoiceLedger struct {
+	InvoiceID     int                  `json:"invoice_id"`
+	InvoiceNumber string               `json:"invoice_number"`
+	Status        string               `json:"status"`
+	Amount        models.Money         `json:"amount"`
+	Outstanding   models.Money         `json:"outstanding"`
+	Entries       []InvoiceLedgerEntry `json:"entries"`
+}
+
+// GetInvoiceLedger returns the ledger of invoice id: its creation on the issue
+// date, its last send, and each transaction allocated to it in transaction
+// date order. Allocations from offsets and adjustments are reported as
+// adjustments, the rest as payments. Returns sql.ErrNoRows if the invoice does
+// not exist.
+func (s *Store) GetInvoiceLedger(id int) (InvoiceLedger, error) {
+	inv, err := scanInvoice(s.db.QueryRow(invoiceSelectQuery+" WHERE i.id = ?", id))
+	if err != nil {
+		return InvoiceLedger{}, err
+	}
+	ledger := InvoiceLedger{InvoiceID: id, InvoiceNumber: inv.InvoiceNumber, Status: inv.Status, Amount: inv.Amount}
+
+	created := inv.IssueDate
+	if created.IsZero() {
+		created = models.Date{Time: inv.CreatedAt.Local()}
+	}
+	entries := []InvoiceLedgerEntry{{Date: created, Event: "created", Amount: inv.Amount}}
+
+	rows, err := s.db.Query(`SELECT td.id, td.transaction_id, td.amount, t.type, t.transaction_date, COALESCE(td.note, t.description)
+		FROM transaction_documents td
+		JOIN transactions t ON td.transaction_id = t.id
+		WHERE td.document_type = 'invoice' AND td.document_id = ?
+		ORDER BY t.transaction_date, td.id`, id)
+	if err != nil {
+		return ledger, err
+	}
+	defer rows.Close()
+	var links []InvoiceLedgerEntry
+	for rows.Next() {
+		var e InvoiceLedgerEntry
+		var linkID, txnID int
+		var amount models.Money
+		var txnType string
+		if err := rows.Scan(&linkID, &txnID, &amount, &txnType, &e.Date, &e.Description); err != nil {
+			return ledger, err
+		}
+		e.LinkID, e.TransactionID, e.Amount = &linkID, &txnID, -amount
+		e.Event = "payment"
+		if txnType == "offset" || txnType == "adjustment" {
+			e.Event = "adjustment"
+		}
+		links = append(links, e)
+	}
+	if err := rows.Err(); err != nil {
+		return ledger, err
+	}
+
+	// The send sits among the allocations by date, ahead of any on the same day.
+	sent := !inv.SentAt.IsZero()
+	sentDate := models.Date{Time: inv.SentAt.Local()}
+	for _, e := range links {
+		if sent && e.Date.String() >= sentDate.String() {
+			entries = append(entries, InvoiceLedgerEntry{Date: sentDate, Event: "sent"})
+			sent = false
+		}
+		entries = append(entries, e)
+	}
+	if sent {
+		entries = append(entries, InvoiceLedgerEntry{Date: sentDate, Event: "sent"})
+	}
+
+	for i := range entries {
+		ledger.Outstanding += entries[i].Amount
+		entries[i].Outstanding = ledger.Outstanding
+	}
+	ledger.Entries = entries
+	return ledger, nil
+}
+
+// DueSoonCustomer is a customer with invoices falling due shortly.
+type DueSoonCustomer struct {
+	ContactID   *int             `json:"contact_id"`
+	ContactName *string          `json:"contact_name"`
+	Email       *string          `json:"email"`
+	Phone       *string          `json:"phone"`
+	Outstanding models.Money     `json:"outstanding"` // unallocated across Invoices
+	Invoices    []models.Invoice `json:"invoices"`    // soonest due first
+}
+
+// ListInvoicesDueSoon returns the sent and partly paid invoices due from today
+// through days after it that still have more than AllocationTolerance
+// outstanding, grouped by customer, customers with the soonest due invoice
+// first. Invoices without a contact form one group with a nil ContactID.
+func (s *Store) ListInvoicesDueSoon(today time.Time, days int) ([]DueSoonCustomer, error) {
+	from := today.Format("2006-01-02")
+	through := today.AddDate(0, 0, days).Format("2006-01-02")
+	rows, err := s.db.Query(invoiceSelectQuery+` WHERE i.status IN ('sent', 'partial', 'overdue')
+		AND i.due_date >= ? AND i.due_date <= ? ORDER BY i.due_date, i.id`, from, through)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	customers := []DueSoonCustomer{}
+	index := map[int]int{} // contact id, or 0 for none, to its position in customers
+	for rows.Next() {
+		inv, err := scanInvoice(rows)
+		if err != nil {
+			return nil, err
+		}
+		if inv.Unallocated <= AllocationTolerance {
+			continue
+		}
+		key := 0
+		if inv.ContactID != nil {
+			key = *inv.ContactID
+		}
+		i, ok := index[key]
+		if !ok {
+			i = len(customers)
+			index[key] = i
+			customers = append(customers, DueSoonCustomer{ContactID: inv.ContactID, ContactName: inv.ContactName})
+		}
+		customers[i].Outstanding += inv.Unallocated
+		customers[i].Invoices = append(customers[i].Invoices, inv)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range customers {
+		if customers[i].ContactID == nil {
+			continue
+		}
+		c, err := s.GetContact(*customers[i].ContactID)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		customers[i].Email, customers[i].Phone = c.Email, c.Phone
+	}
+	return customers, nil
+}
@@ -0,0 +1,158 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/satheeshds/portal/models"
+)
+
+// LinkPage controls ordering and pagination of transaction link listings.
+// The zero value returns every link, oldest first.
+type LinkPage struct {
+	Limit  int // 0 means no limit
+	Offset int
+	Desc   bool // newest first
+}
+
+// clause returns the ORDER BY / LIMIT / OFFSET suffix for a query over
+// transaction_documents aliased as alias, plus its arguments.
+func (p LinkPage) clause(alias string) (string, []any) {
+	dir := "ASC"
+	if p.Desc {
+		dir = "DESC"
+	}
+	q := fmt.Sprintf(" ORDER BY %[1]s.created_at %[2]s, %[1]s.id %[2]s", alias, dir)
+	var args []any
+	if p.Limit > 0 {
+		q += " LIMIT ?"
+		args = append(args, p.Limit)
+	}
+	if p.Offset > 0 {
+		q += " OFFSET ?"
+		args = append(args, p.Offset)
+	}
+	return q, args
+}
+
+// LinkTotals summarises all links of a transaction or document, regardless of pagination.
+type LinkTotals struct {
+	Count     int
+	Allocated models.Money
+}
+
+// DocumentLinkTotals returns the number of links and total amount allocated to a document.
+func (s *Store) DocumentLinkTotals(docType string, docID int) (LinkTotals, error) {
+	var t LinkTotals
+	err := s.db.QueryRow(`SELECT COUNT(*), COALESCE(SUM(amount), 0) FROM transaction_documents
+		WHERE document_type = ? AND document_id = ?`, docType, docID).Scan(&t.Count, &t.Allocated)
+	return t, err
+}
+
+// TransactionLinkTotals returns the number of links and total amount allocated from a transaction.
+func (s *Store) TransactionLinkTotals(txnID int) (LinkTotals, error) {
+	var t LinkTotals
+	err := s.db.QueryRow(`SELECT COUNT(*), COALESCE(SUM(amount), 0) FROM transaction_documents
+		WHERE transaction_id = ?`, txnID).Scan(&t.Count, &t.Allocated)
+	return t, err
+}
+
+// AllocationGraph is a transaction with every document it pays and, for each
+// document, the other transactions allocated to it.
+type AllocationGraph struct {
+	Transaction models.Transaction    `json:"transaction"`
+	Links       []AllocationGraphLink `json:"links"`
+}
+
+// AllocationGraphLink is one link of the transaction and the document behind it.
+type AllocationGraphLink struct {
+	models.TransactionDocument
+	Document   AllocationGraphDocument `json:"document"`
+	OtherLinks []AllocationGraphPeer   `json:"other_links"` // links from other transactions to the same document
+}
+
+// AllocationGraphDocument summarises a linked document. Missing is set when
+// the link points at a document that no longer exists.
+type AllocationGraphDocument struct {
+	Amount    models.Money `json:"amount"`
+	Allocated models.Money `json:"allocated"` // across all transactions
+	Status    string       `json:"status"`
+	Missing   bool         `json:"missing,omitempty"`
+}
+
+// AllocationGraphPeer is a link from another transaction to a document.
+type AllocationGraphPeer struct {
+	models.TransactionDocument
+	TransactionDate models.Date `json:"transaction_date"`
+	Description     *string     `json:"description"`
+	AccountName     *string     `json:"account_name"`
+}
+
+// GetAllocationGraph returns the allocation graph of a transaction. Returns
+// sql.ErrNoRows if the transaction does not exist.
+func (s *Store) GetAllocationGraph(txnID int) (AllocationGraph, error) {
+	var g AllocationGraph
+	txn, err := s.getTransactionByID(txnID)
+	if err != nil {
+		return g, err
+	}
+	g.Transaction = txn
+
+	links, err := s.ListTransactionLinks(txnID, LinkPage{})
+	if err != nil {
+		return g, err
+	}
+
+	type docKey struct {
+		docType string
+		docID   int
+	}
+	peers := map[docKey][]AllocationGraphPeer{}
+	rows, err := s.db.Query(`SELECT td.id, td.transaction_id, td.document_type, td.document_id, td.amount, td.note, td.created_at,
+		t.transaction_date, t.description, a.name
+		FROM transaction_documents td
+		JOIN transactions t ON td.transaction_id = t.id
+		LEFT JOIN accounts a ON t.account_id = a.id
+		WHERE td.transaction_id <> ? AND EXISTS (SELECT 1 FROM transaction_documents x
+			WHERE x.transaction_id = ? AND x.document_type = td.document_type AND x.document_id = td.document_id)
+		ORDER BY td.created_at, td.id`, txnID, txnID)
+	if err != nil {
+		return g, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var p AllocationGraphPeer
+		if err := rows.Scan(&p.ID, &p.TransactionID, &p.DocumentType, &p.DocumentID, &p.Amount, &p.Note, &p.CreatedAt,
+			&p.TransactionDate, &p.Description, &p.AccountName); err != nil {
+			return g, err
+		}
+		key := docKey{p.DocumentType, p.DocumentID}
+		peers[key] = append(peers[key], p)
+	}
+	if err := rows.Err(); err != nil {
+		return g, err
+	}
+
+	g.Links = make([]AllocationGraphLink, 0, len(links))
+	for _, td := range links {
+		link := AllocationGraphLink{TransactionDocument: td, OtherLinks: peers[docKey{td.DocumentType, td.DocumentID}]}
+		if link.OtherLinks == nil {
+			link.OtherLinks = []AllocationGraphPeer{}
+		}
+		amount, allocated, err := s.GetDocumentAmountAndAllocated(td.DocumentType, td.DocumentID)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			link.Document.Missing = true
+		case err != nil:
+			return g, err
+		default:
+			link.Document.Amount, link.Document.Allocated = amount, allocated
+			if link.Document.Status, err = s.GetDocumentStatus(td.DocumentType, td.DocumentID); err != nil {
+				return g, err
+			}
+		}
+		g.Links = append(g.Links, link)
+	}
+	return g, nil
+}
@@ -1,6 +1,7 @@
 package store
 
 import (
+	"database/sql"
 	"time"
 
 	"github.com/satheeshds/portal/models"
@@ -187,7 +188,7 @@ func (s *Store) SuggestPayouts(amount models.Money, txnDate time.Time, txnSearch
 			WHERE document_type = 'payout'
 			GROUP BY document_id
 		) a ON a.document_id = p.id
-		WHERE p.final_payout_amt > COALESCE(a.total_allocated, 0)
+		WHERE p.final_payout_amt - COALESCE(p.variance_amount, 0) > COALESCE(a.total_allocated, 0)
 	`)
 	if err != nil {
 		return nil, err
@@ -247,20 +248,52 @@ func (s *Store) GetDocumentAllocated(docType string, docID int) (models.Money, e
 
 // CreateTransactionDocumentLink inserts a new transaction_documents row and returns it.
 func (s *Store) CreateTransactionDocumentLink(txnID int, docType string, docID int, amount models.Money) (models.TransactionDocument, error) {
-	var id int
-	err := s.db.QueryRow(`INSERT INTO transaction_documents (transaction_id, document_type, document_id, amount)
-		VALUES (?, ?, ?, ?) RETURNING id`, txnID, docType, docID, amount).Scan(&id)
+	id, err := insertReturningID(s.db, `INSERT INTO transaction_documents (transaction_id, document_type, document_id, amount)
+		VALUES (?, ?, ?, ?)`, txnID, docType, docID, amount)
 	if err != nil {
 		return models.TransactionDocument{}, err
 	}
+	if err := touchTransaction(s.db, txnID); err != nil {
+		return models.TransactionDocument{}, err
+	}
+	return s.GetTransactionDocument(id)
+}
+
+// MatchPayout links bank transaction txnID to payout payoutID for amount and
+// marks the transaction reconciled, and locked when LockReconciled is set.
+// The link and the reconcile run in one transaction. Returns sql.ErrNoRows if
+// the transaction is not found.
+func (s *Store) MatchPayout(txnID, payoutID int, amount models.Money) (models.TransactionDocument, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return models.TransactionDocument{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	id, err := insertReturningID(tx, `INSERT INTO transaction_documents (transaction_id, document_type, document_id, amount)
+		VALUES (?, 'payout', ?, ?)`, txnID, payoutID, amount)
+	if err != nil {
+		return models.TransactionDocument{}, err
+	}
+	res, err := tx.Exec(reconcileUpdate, LockReconciled, txnID)
+	if err != nil {
+		return models.TransactionDocument{}, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.TransactionDocument{}, sql.ErrNoRows
+	}
+	if err := tx.Commit(); err != nil {
+		return models.TransactionDocument{}, err
+	}
+	s.UpdateDocumentStatus("payout", payoutID)
 	return s.GetTransactionDocument(id)
 }
 
 // GetTransactionDocument returns a single transaction_documents row by ID.
 func (s *Store) GetTransactionDocument(id int) (models.TransactionDocument, error) {
 	var td models.TransactionDocument
-	err := s.db.QueryRow("SELECT id, transaction_id, document_type, document_id, amount, created_at FROM transaction_documents WHERE id = ?", id).
-		Scan(&td.ID, &td.TransactionID, &td.DocumentType, &td.DocumentID, &td.Amount, &td.CreatedAt)
+	err := s.db.QueryRow("SELECT id, transaction_id, document_type, document_id, amount, note, created_at FROM transaction_documents WHERE id = ?", id).
+		Scan(&td.ID, &td.TransactionID, &td.DocumentType, &td.DocumentID, &td.Amount, &td.Note, &td.CreatedAt)
 	return td, err
 }
 
@@ -300,6 +333,49 @@ func (s *Store) SuggestTransactionsForDocument(txnType, docType string, docID in
 	return candidates, nil
 }
 
+// PayoutAutoMatchCandidates returns unreconciled income transactions on bank
+// accounts in BooksCurrency whose unallocated amount is within tolerance of
+// target. When from and to (YYYY-MM-DD) are set, only transactions dated in
+// that range are returned.
+func (s *Store) PayoutAutoMatchCandidates(target, tolerance models.Money, from, to string) ([]TransactionCandidate, error) {
+	query := `SELECT t.id, t.amount, t.transaction_date, COALESCE(t.description, ''), COALESCE(t.reference, ''),
+			COALESCE(al.total_allocated, 0)
+		FROM transactions t
+		JOIN accounts a ON t.account_id = a.id
+		LEFT JOIN (
+			SELECT transaction_id, SUM(amount) AS total_allocated
+			FROM transaction_documents
+			GROUP BY transaction_id
+		) al ON al.transaction_id = t.id
+		WHERE t.type = 'income'
+		  AND a.type = 'bank'
+		  AND COALESCE(a.currency, 'INR') = ?
+		  AND COALESCE(t.reconciled, false) = false
+		  AND t.amount - COALESCE(al.total_allocated, 0) BETWEEN ? AND ?`
+	args := []any{BooksCurrency, target - tolerance, target + tolerance}
+	if from != "" && to != "" {
+		query += " AND t.transaction_date BETWEEN ? AND ?"
+		args = append(args, from, to)
+	}
+	query += " ORDER BY t.transaction_date, t.id"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []TransactionCandidate
+	for rows.Next() {
+		var c TransactionCandidate
+		if err := rows.Scan(&c.ID, &c.Amount, &c.Date, &c.Description, &c.Reference, &c.Allocated); err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, rows.Err()
+}
+
 // GetBillForMatching returns the data needed to suggest transactions for a bill.
 func (s *Store) GetBillForMatching(id int) (BillMatchInfo, error) {
 	var info BillMatchInfo
@@ -0,0 +1,76 @@
+package store
+
+import (
+	"fmt"
+
+	"github.com/satheeshds/portal/models"
+)
+
+// OffsetResult is an offset transaction with the two links it created and
+// the statuses of the documents it settled.
+type OffsetResult struct {
+	Transaction   models.Transaction           `json:"transaction"`
+	Links         []models.TransactionDocument `json:"links"` // the bill link, then the invoice link
+	BillStatus    string                       `json:"bill_status"`
+	InvoiceStatus string                       `json:"invoice_status"`
+}
+
+// CreateOffset records an offset transaction of input.Amount against
+// input.AccountID and links it to both input.BillID and input.InvoiceID for
+// that amount, in one database transaction. The caller checks the documents
+// and resolves the amount; contactID tags the transaction. Document statuses
+// are updated afterwards.
+func (s *Store) CreateOffset(input models.OffsetInput, contactID *int, description string) (OffsetResult, error) {
+	var result OffsetResult
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return result, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	id, err := insertReturningID(tx, `INSERT INTO transactions (account_id, type, amount, transaction_date, description, contact_id)
+		VALUES (?, 'offset', ?, ?, ?, ?)`, input.AccountID, input.Amount, input.Date, description, contactID)
+	if err != nil {
+		return result, err
+	}
+	if _, err := tx.Exec("UPDATE transactions SET reference = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?", fmt.Sprintf("OFS-%d", id), id); err != nil {
+		return result, err
+	}
+
+	var linkIDs []int
+	for _, doc := range []struct {
+		docType string
+		docID   int
+	}{{"bill", input.BillID}, {"invoice", input.InvoiceID}} {
+		linkID, err := insertReturningID(tx, `INSERT INTO transaction_documents (transaction_id, document_type, document_id, amount, note)
+			VALUES (?, ?, ?, ?, ?)`, id, doc.docType, doc.docID, input.Amount, input.Note)
+		if err != nil {
+			return result, err
+		}
+		linkIDs = append(linkIDs, linkID)
+	}
+	if err := tx.Commit(); err != nil {
+		return result, err
+	}
+
+	s.UpdateDocumentStatus("bill", input.BillID)
+	s.UpdateDocumentStatus("invoice", input.InvoiceID)
+
+	if result.Transaction, err = s.getTransactionByID(id); err != nil {
+		return result, err
+	}
+	for _, linkID := range linkIDs {
+		var td models.TransactionDocument
+		if err := s.db.QueryRow("SELECT id, transaction_id, document_type, document_id, amount, note, created_at FROM transaction_documents WHERE id = ?", linkID).
+			Scan(&td.ID, &td.TransactionID, &td.DocumentType, &td.DocumentID, &td.Amount, &td.Note, &td.CreatedAt); err != nil {
+			return result, err
+		}
+		result.Links = append(result.Links, td)
+	}
+	if result.BillStatus, err = s.GetDocumentStatus("bill", input.BillID); err != nil {
+		return result, err
+	}
+	result.InvoiceStatus, err = s.GetDocumentStatus("invoice", input.InvoiceID)
+	return result, err
+}
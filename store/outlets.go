@@ -0,0 +1,107 @@
+package store
+
+import (
+	"database/sql"
+
+	"github.com/satheeshds/portal/models"
+)
+
+const outletSelectQuery = `SELECT id, name, created_at, updated_at,
+	(SELECT COUNT(*) FROM payouts p WHERE p.outlet_id = outlets.id)
+	FROM outlets`
+
+func scanOutlet(scanner interface{ Scan(...any) error }) (models.Outlet, error) {
+	var o models.Outlet
+	err := scanner.Scan(&o.ID, &o.Name, &o.CreatedAt, &o.UpdatedAt, &o.Payouts)
+	return o, err
+}
+
+// ListOutlets returns outlets ordered by name, optionally filtered by a search term.
+func (s *Store) ListOutlets(search string) ([]models.Outlet, error) {
+	query := outletSelectQuery
+	var args []any
+	if search != "" {
+		query += " WHERE name LIKE ?"
+		args = append(args, "%"+search+"%")
+	}
+	query += " ORDER BY name"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	outlets := []models.Outlet{}
+	for rows.Next() {
+		o, err := scanOutlet(rows)
+		if err != nil {
+			return nil, err
+		}
+		outlets = append(outlets, o)
+	}
+	return outlets, rows.Err()
+}
+
+// GetOutlet returns a single outlet by ID. Returns sql.ErrNoRows if not found.
+func (s *Store) GetOutlet(id int) (models.Outlet, error) {
+	return scanOutlet(s.db.QueryRow(outletSelectQuery+" WHERE id = ?", id))
+}
+
+// FindOutletByName returns the outlet with the given name, ignoring case.
+// Returns sql.ErrNoRows if there is none.
+func (s *Store) FindOutletByName(name string) (models.Outlet, error) {
+	return scanOutlet(s.db.QueryRow(outletSelectQuery+" WHERE LOWER(name) = LOWER(?) ORDER BY id LIMIT 1", name))
+}
+
+// CreateOutlet inserts a new outlet and returns the created record.
+func (s *Store) CreateOutlet(input models.OutletInput) (models.Outlet, error) {
+	id, err := insertOutlet(s.db, input)
+	if err != nil {
+		return models.Outlet{}, err
+	}
+	return s.GetOutlet(id)
+}
+
+// insertOutlet inserts the outlet CreateOutlet creates and returns its id.
+func insertOutlet(q rowQuerier, input models.OutletInput) (int, error) {
+	return insertReturningID(q, "INSERT INTO outlets (name) VALUES (?)", input.Name)
+}
+
+// UpdateOutlet renames an outlet and the outlet_name denormalized onto its
+// payouts. Returns sql.ErrNoRows if not found.
+func (s *Store) UpdateOutlet(id int, input models.OutletInput) (models.Outlet, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return models.Outlet{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.Exec("UPDATE outlets SET name = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?", input.Name, id)
+	if err != nil {
+		return models.Outlet{}, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.Outlet{}, sql.ErrNoRows
+	}
+	if _, err := tx.Exec("UPDATE payouts SET outlet_name = ?, updated_at = CURRENT_TIMESTAMP WHERE outlet_id = ?", input.Name, id); err != nil {
+		return models.Outlet{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Outlet{}, err
+	}
+	return s.GetOutlet(id)
+}
+
+// DeleteOutlet removes an outlet. Returns sql.ErrNoRows if not found.
+func (s *Store) DeleteOutlet(id int) error {
+	res, err := s.db.Exec("DELETE FROM outlets WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return recordDeletion(s.db, "outlet", id)
+}
@@ -0,0 +1,119 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/satheeshds/portal/models"
+)
+
+// PayoutImportRow is the outcome of one row of a payout import.
+type PayoutImportRow struct {
+	Row        int    `json:"row"`
+	OutletName string `json:"outlet_name"`
+	UtrNumber  string `json:"utr_number,omitempty"`
+	Status     string `json:"status"`              // created, skipped, or error
+	PayoutID   *int   `json:"payout_id,omitempty"` // the created payout, or the existing one it matched
+	Error      string `json:"error,omitempty"`
+}
+
+// PayoutImportResult summarises a payout import.
+type PayoutImportResult struct {
+	Created int               `json:"created"`
+	Skipped int               `json:"skipped"` // rows matching a payout already recorded
+	Errors  int               `json:"errors"`
+	Rows    []PayoutImportRow `json:"rows"` // every data row, in file order
+}
+
+// Add appends row to the result and counts it under its status.
+func (r *PayoutImportResult) Add(row PayoutImportRow) {
+	switch row.Status {
+	case "created":
+		r.Created++
+	case "skipped":
+		r.Skipped++
+	case "error":
+		r.Errors++
+	}
+	r.Rows = append(r.Rows, row)
+}
+
+// ImportPayouts creates a payout for each line, which must already be
+// validated, finding its outlet by name. A line whose outlet is not found is
+// reported as an error unless its CreateOutlet is set, in which case the
+// outlet is registered. A line matching a payout already recorded on its platform, by UTR
+// or, without one, by outlet, period, and settlement date, is skipped, as is
+// a repeat of an earlier line, so re-importing a settlement report is
+// idempotent. All inserts run in one transaction.
+func (s *Store) ImportPayouts(lines []models.PayoutLine) ([]PayoutImportRow, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	rows := make([]PayoutImportRow, 0, len(lines))
+	for _, line := range lines {
+		in := line.Input
+		row := PayoutImportRow{Row: line.Row, OutletName: in.OutletName, UtrNumber: in.UtrNumber}
+
+		var outletID int
+		err := tx.QueryRow("SELECT id, name FROM outlets WHERE LOWER(name) = LOWER(?) ORDER BY id LIMIT 1",
+			in.OutletName).Scan(&outletID, &in.OutletName)
+		if errors.Is(err, sql.ErrNoRows) {
+			if !in.CreateOutlet {
+				row.Status, row.Error = "error", fmt.Sprintf("outlet %q not found; register it under /outlets or pass create_outlets=true", in.OutletName)
+				rows = append(rows, row)
+				continue
+			}
+			outletID, err = insertReturningID(tx, "INSERT INTO outlets (name) VALUES (?)", in.OutletName)
+		}
+		if err != nil {
+			return nil, err
+		}
+		row.OutletName = in.OutletName
+
+		var id int
+		if in.UtrNumber != "" {
+			err = tx.QueryRow("SELECT id FROM payouts WHERE platform = ? AND utr_number = ? ORDER BY id LIMIT 1",
+				in.Platform, in.UtrNumber).Scan(&id)
+		} else {
+			str := func(p *string) string {
+				if p == nil {
+					return ""
+				}
+				return *p
+			}
+			err = tx.QueryRow(`SELECT id FROM payouts WHERE platform = ? AND outlet_id = ?
+				AND COALESCE(CAST(period_start AS VARCHAR), '') = ? AND COALESCE(CAST(period_end AS VARCHAR), '') = ?
+				AND COALESCE(settlement_date, '') = ? ORDER BY id LIMIT 1`,
+				in.Platform, outletID, str(in.PeriodStart), str(in.PeriodEnd), str(in.SettlementDate)).Scan(&id)
+		}
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			id, err = insertReturningID(tx, `INSERT INTO payouts (outlet_id, outlet_name, platform, period_start, period_end, settlement_date,
+				total_orders, gross_sales_amt, restaurant_discount_amt, platform_commission_amt,
+				taxes_tcs_tds_amt, marketing_ads_amt, final_payout_amt, utr_number)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				outletID, in.OutletName, in.Platform, in.PeriodStart, in.PeriodEnd, in.SettlementDate,
+				in.TotalOrders, in.GrossSalesAmt, in.RestaurantDiscountAmt, in.PlatformCommissionAmt,
+				in.TaxesTcsTdsAmt, in.MarketingAdsAmt, in.FinalPayoutAmt, nullIfEmpty(in.UtrNumber))
+			if err != nil {
+				return nil, err
+			}
+			row.Status = "created"
+		case err != nil:
+			return nil, err
+		default:
+			row.Status = "skipped"
+		}
+		row.PayoutID = &id
+		rows = append(rows, row)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
@@ -0,0 +1,55 @@
+package store
+
+import (
+	"github.com/satheeshds/portal/models"
+)
+
+// ListPayoutOrders returns the orders attached to a payout, by order date.
+func (s *Store) ListPayoutOrders(payoutID int) ([]models.PayoutOrder, error) {
+	rows, err := s.db.Query(`SELECT id, payout_id, order_id, order_date, gross_amt, commission_amt, net_amt, created_at, updated_at
+		FROM payout_orders WHERE payout_id = ? ORDER BY order_date, id`, payoutID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := []models.PayoutOrder{}
+	for rows.Next() {
+		var o models.PayoutOrder
+		if err := rows.Scan(&o.ID, &o.PayoutID, &o.OrderID, &o.OrderDate, &o.GrossAmt, &o.CommissionAmt, &o.NetAmt,
+			&o.CreatedAt, &o.UpdatedAt); err != nil {
+			return nil, err
+		}
+		orders = append(orders, o)
+	}
+	return orders, rows.Err()
+}
+
+// ReplacePayoutOrders replaces every order attached to a payout with orders,
+// in one transaction, and returns the stored orders.
+func (s *Store) ReplacePayoutOrders(payoutID int, orders []models.PayoutOrderInput) ([]models.PayoutOrder, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec("DELETE FROM payout_orders WHERE payout_id = ?", payoutID); err != nil {
+		return nil, err
+	}
+	stmt, err := tx.Prepare(`INSERT INTO payout_orders (payout_id, order_id, order_date, gross_amt, commission_amt, net_amt)
+		VALUES (?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	for _, o := range orders {
+		if _, err := stmt.Exec(payoutID, o.OrderID, o.OrderDate, o.GrossAmt, o.CommissionAmt, o.NetAmt); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return s.ListPayoutOrders(payoutID)
+}
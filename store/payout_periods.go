@@ -0,0 +1,205 @@
+package store
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/satheeshds/portal/models"
+)
+
+// payoutMonthShare is the part of a payout that falls in one calendar month:
+// the days of its period up to the start of the month (before) and up to the
+// end of it (through), out of total.
+type payoutMonthShare struct {
+	Month                  string // YYYY-MM, empty for an undated payout
+	before, through, total int
+}
+
+// of returns the share of amount. Shares are taken from the running total of
+// days, so the shares of a whole period add up to amount exactly.
+func (sh payoutMonthShare) of(amount models.Money) models.Money {
+	at := func(days int) float64 {
+		return math.Round(float64(amount) * float64(days) / float64(sh.total))
+	}
+	return models.Money(at(sh.through) - at(sh.before))
+}
+
+// payoutMonthShares apportions a payout across the months its period covers,
+// by days, keeping the days between from and to (YYYY-MM-DD, either may be
+// empty). A payout without a valid period falls wholly in the month of its
+// report date, when that is in range.
+func payoutMonthShares(start, end, reported models.Date, from, to string) []payoutMonthShare {
+	lo, _ := time.Parse("2006-01-02", from)
+	hi, _ := time.Parse("2006-01-02", to)
+	inRange := func(d time.Time) bool {
+		return (from == "" || !d.Before(lo)) && (to == "" || !d.After(hi))
+	}
+	if start.IsZero() || end.IsZero() || end.Before(start.Time) {
+		if reported.IsZero() {
+			if from != "" || to != "" {
+				return nil
+			}
+			return []payoutMonthShare{{through: 1, total: 1}}
+		}
+		if !inRange(reported.Time) {
+			return nil
+		}
+		return []payoutMonthShare{{Month: reported.Format("2006-01"), through: 1, total: 1}}
+	}
+
+	total := int(end.Sub(start.Time).Hours()/24) + 1
+	var shares []payoutMonthShare
+	for day := 0; day < total; {
+		d := start.AddDate(0, 0, day)
+		next := time.Date(d.Year(), d.Month()+1, 1, 0, 0, 0, 0, d.Location())
+		days := min(int(next.Sub(d).Hours()/24), total-day)
+		// Clip the month's run of days to the requested range.
+		first, last := day, day+days
+		for first < last && !inRange(start.AddDate(0, 0, first)) {
+			first++
+		}
+		for last > first && !inRange(start.AddDate(0, 0, last-1)) {
+			last--
+		}
+		if last > first {
+			shares = append(shares, payoutMonthShare{Month: d.Format("2006-01"), before: first, through: last, total: total})
+		}
+		day += days
+	}
+	return shares
+}
+
+// getTDSReportByPeriod is GetTDSReport with each payout apportioned across
+// the months of its period. A payout is counted in every month it has a share
+// in.
+func (s *Store) getTDSReportByPeriod(from, to, platform string) (TDSReport, error) {
+	report := TDSReport{Rows: []TDSReportRow{}, ByPlatform: map[string]models.Money{}}
+
+	var conditions []string
+	var args []any
+	if from != "" {
+		conditions = append(conditions, "(p.period_end >= ? OR "+payoutReportDate+" >= ?)")
+		args = append(args, from, from)
+	}
+	if to != "" {
+		conditions = append(conditions, "(p.period_start <= ? OR "+payoutReportDate+" <= ?)")
+		args = append(args, to, to)
+	}
+	if platform != "" {
+		conditions = append(conditions, "p.platform = ?")
+		args = append(args, platform)
+	}
+	query := `SELECT p.period_start, p.period_end, ` + payoutReportDate + `, p.platform, COALESCE(p.outlet_name, ''),
+		COALESCE(p.gross_sales_amt, 0), COALESCE(p.taxes_tcs_tds_amt, 0)
+		FROM payouts p`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return report, err
+	}
+	defer rows.Close()
+
+	byKey := map[[3]string]*TDSReportRow{}
+	for rows.Next() {
+		var start, end, reported models.Date
+		var p TDSReportRow
+		if err := rows.Scan(&start, &end, &reported, &p.Platform, &p.OutletName, &p.GrossSalesAmt, &p.TaxesTcsTdsAmt); err != nil {
+			return report, err
+		}
+		for _, sh := range payoutMonthShares(start, end, reported, from, to) {
+			key := [3]string{sh.Month, p.Platform, p.OutletName}
+			row := byKey[key]
+			if row == nil {
+				row = &TDSReportRow{Month: sh.Month, Platform: p.Platform, OutletName: p.OutletName}
+				byKey[key] = row
+			}
+			row.Payouts++
+			row.GrossSalesAmt += sh.of(p.GrossSalesAmt)
+			row.TaxesTcsTdsAmt += sh.of(p.TaxesTcsTdsAmt)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return report, err
+	}
+
+	for _, row := range byKey {
+		report.Rows = append(report.Rows, *row)
+		report.ByPlatform[row.Platform] += row.TaxesTcsTdsAmt
+		report.Total += row.TaxesTcsTdsAmt
+	}
+	sort.Slice(report.Rows, func(i, j int) bool {
+		a, b := report.Rows[i], report.Rows[j]
+		if a.Month != b.Month {
+			return a.Month < b.Month
+		}
+		if a.Platform != b.Platform {
+			return a.Platform < b.Platform
+		}
+		return a.OutletName < b.OutletName
+	})
+	return report, nil
+}
+
+// getCommissionTrendByPeriod is GetCommissionTrend with each payout
+// apportioned across the months of its period. A payout is counted in every
+// month it has a share in.
+func (s *Store) getCommissionTrendByPeriod(since, platform, outletID string) ([]CommissionTrendRow, error) {
+	conditions := []string{"(p.period_end >= ? OR " + payoutReportDate + " >= ?)"}
+	args := []any{since, since}
+	if platform != "" {
+		conditions = append(conditions, "p.platform = ?")
+		args = append(args, platform)
+	}
+	if outletID != "" {
+		conditions = append(conditions, "p.outlet_id = ?")
+		args = append(args, outletID)
+	}
+
+	rows, err := s.db.Query(`SELECT p.period_start, p.period_end, `+payoutReportDate+`,
+		COALESCE(p.gross_sales_amt, 0), COALESCE(p.platform_commission_amt, 0), COALESCE(p.marketing_ads_amt, 0)
+		FROM payouts p
+		WHERE `+strings.Join(conditions, " AND "), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byMonth := map[string]*CommissionTrendRow{}
+	for rows.Next() {
+		var start, end, reported models.Date
+		var p CommissionTrendRow
+		if err := rows.Scan(&start, &end, &reported, &p.GrossSalesAmt, &p.PlatformCommissionAmt, &p.MarketingAdsAmt); err != nil {
+			return nil, err
+		}
+		for _, sh := range payoutMonthShares(start, end, reported, since, "") {
+			row := byMonth[sh.Month]
+			if row == nil {
+				row = &CommissionTrendRow{Month: sh.Month}
+				byMonth[sh.Month] = row
+			}
+			row.Payouts++
+			row.GrossSalesAmt += sh.of(p.GrossSalesAmt)
+			row.PlatformCommissionAmt += sh.of(p.PlatformCommissionAmt)
+			row.MarketingAdsAmt += sh.of(p.MarketingAdsAmt)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	trend := []CommissionTrendRow{}
+	for _, row := range byMonth {
+		if row.GrossSalesAmt > 0 {
+			pct := float64(row.PlatformCommissionAmt+row.MarketingAdsAmt) * 100 / float64(row.GrossSalesAmt)
+			row.TakeRatePct = math.Round(pct*100) / 100
+		}
+		trend = append(trend, *row)
+	}
+	sort.Slice(trend, func(i, j int) bool { return trend[i].Month < trend[j].Month })
+	return trend, nil
+}
@@ -0,0 +1,176 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/satheeshds/portal/models"
+)
+
+// payoutPostingSource is the source of the transactions PostPayout generates.
+// Their external ids are "payout-<id>-<entry>", so a payout is posted at most
+// once.
+const payoutPostingSource = "payout_post"
+
+// PayoutPosting is the ledger entries generated for a payout.
+type PayoutPosting struct {
+	PayoutID          int `json:"payout_id"`
+	ClearingAccountID int `json:"clearing_account_id"`
+	BankTransactionID int `json:"bank_transaction_id"` // the bank credit, now the income leg of the settlement transfer
+	// Transactions are the entries on the clearing account: gross sales as
+	// income, each deduction as an expense, and the expense leg of the
+	// transfer settling the net to the bank.
+	Transactions []models.Transaction `json:"transactions"`
+}
+
+// payoutEntry is one income or expense PostPayout books on the clearing account.
+type payoutEntry struct {
+	key, txnType, label string
+	amount              models.Money
+}
+
+// PayoutBalances reports whether a payout's gross less its deductions equals
+// its final payout amount, which posting requires.
+func PayoutBalances(p models.Payout) bool {
+	return p.GrossSalesAmt-p.RestaurantDiscountAmt-p.PlatformCommissionAmt-p.TaxesTcsTdsAmt-p.MarketingAdsAmt == p.FinalPayoutAmt
+}
+
+// PayoutPosted reports whether PostPayout has already run for payout id.
+func (s *Store) PayoutPosted(id int) (bool, error) {
+	var n int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM transactions WHERE source = ? AND external_id LIKE ?",
+		payoutPostingSource, fmt.Sprintf("payout-%d-%%", id)).Scan(&n)
+	return n > 0, err
+}
+
+// PostingConflict is returned by PostPayout when the payout is already posted
+// or the bank credit can no longer become a transfer leg. Its message is meant
+// for the client.
+type PostingConflict string
+
+func (c PostingConflict) Error() string { return string(c) }
+
+// PostPayout turns payout p into ledger entries on the clearing account: its
+// gross sales as income and its restaurant discount, platform commission,
+// taxes, marketing charges, and any recorded variance as expenses, dated on
+// the settlement date. The bank credit bank, which must carry the final
+// payout amount less the variance, becomes the income leg of a transfer of
+// the net from the clearing account, so the clearing account nets to zero and
+// the bank balance is unchanged. bank is linked to p for the net unless
+// already linked. Everything runs in one transaction, which also refuses with
+// a PostingConflict a payout that is already posted or a bank credit that is
+// reconciled, locked, or already a transfer. categories maps entries such as
+// commission to the category each is tagged with; entries missing from it are
+// left untagged. The caller checks that p balances and that the categories
+// exist.
+func (s *Store) PostPayout(p models.Payout, clearingAccountID int, bank models.Transaction, categories map[string]int) (PayoutPosting, error) {
+	posting := PayoutPosting{PayoutID: p.ID, ClearingAccountID: clearingAccountID, BankTransactionID: bank.ID}
+	date := p.SettlementDate
+	if date.IsZero() {
+		date = bank.TransactionDate
+	}
+	reference := fmt.Sprintf("PAYOUT-%d", p.ID)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return posting, err
+	}
+	defer tx.Rollback()
+
+	var posted int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM transactions WHERE source = ? AND external_id LIKE ?",
+		payoutPostingSource, fmt.Sprintf("payout-%d-%%", p.ID)).Scan(&posted); err != nil {
+		return posting, err
+	}
+	if posted > 0 {
+		return posting, PostingConflict("payout is already posted")
+	}
+	var reconciled, locked bool
+	var transfer sql.NullInt64
+	if err := tx.QueryRow("SELECT COALESCE(reconciled, false), COALESCE(locked, false), transfer_account_id FROM transactions WHERE id = ?",
+		bank.ID).Scan(&reconciled, &locked, &transfer); err != nil {
+		return posting, err
+	}
+	switch {
+	case locked:
+		return posting, PostingConflict(fmt.Sprintf("transaction %d is locked; unlock it before changing it", bank.ID))
+	case reconciled:
+		return posting, PostingConflict(fmt.Sprintf("transaction %d is reconciled and cannot become a transfer leg", bank.ID))
+	case transfer.Valid:
+		return posting, PostingConflict(fmt.Sprintf("transaction %d is already a transfer", bank.ID))
+	}
+
+	entries := []payoutEntry{
+		{"gross", "income", "gross sales", p.GrossSalesAmt},
+		{"discount", "expense", "restaurant discount", p.RestaurantDiscountAmt},
+		{"commission", "expense", "platform commission", p.PlatformCommissionAmt},
+		{"taxes", "expense", "TCS/TDS", p.TaxesTcsTdsAmt},
+		{"ads", "expense", "marketing and ads", p.MarketingAdsAmt},
+		{"variance", "expense", "variance (" + p.VarianceReason + ")", p.VarianceAmount},
+	}
+	var ids []int
+	for _, e := range entries {
+		if e.amount == 0 {
+			continue
+		}
+		var category *int
+		if id, ok := categories[e.key]; ok {
+			category = &id
+		}
+		id, err := insertReturningID(tx, `INSERT INTO transactions (account_id, type, amount, transaction_date, description, reference, category_id, external_id, source, cleared)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, true)`,
+			clearingAccountID, e.txnType, e.amount, date, fmt.Sprintf("%s payout %d: %s", p.Platform, p.ID, e.label), reference,
+			category, fmt.Sprintf("payout-%d-%s", p.ID, e.key), payoutPostingSource)
+		if err != nil {
+			return posting, err
+		}
+		ids = append(ids, id)
+	}
+
+	id, err := insertReturningID(tx, `INSERT INTO transactions (account_id, type, amount, transaction_date, description, reference, transfer_account_id, external_id, source, cleared)
+		VALUES (?, 'expense', ?, ?, ?, ?, ?, ?, ?, true)`,
+		clearingAccountID, bank.Amount, bank.TransactionDate, fmt.Sprintf("%s payout %d: settlement", p.Platform, p.ID), reference,
+		bank.AccountID, fmt.Sprintf("payout-%d-settlement", p.ID), payoutPostingSource)
+	if err != nil {
+		return posting, err
+	}
+	ids = append(ids, id)
+	res, err := tx.Exec(`UPDATE transactions SET transfer_account_id = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND transfer_account_id IS NULL AND NOT COALESCE(reconciled, false) AND NOT COALESCE(locked, false)`,
+		clearingAccountID, bank.ID)
+	if err != nil {
+		return posting, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return posting, PostingConflict(fmt.Sprintf("transaction %d changed while the payout was being posted", bank.ID))
+	}
+
+	var linked int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM transaction_documents WHERE transaction_id = ? AND document_type = 'payout' AND document_id = ?",
+		bank.ID, p.ID).Scan(&linked); err != nil {
+		return posting, err
+	}
+	if linked == 0 {
+		if _, err := tx.Exec("INSERT INTO transaction_documents (transaction_id, document_type, document_id, amount) VALUES (?, 'payout', ?, ?)",
+			bank.ID, p.ID, bank.Amount); err != nil {
+			return posting, err
+		}
+	}
+	if _, err := tx.Exec("UPDATE payouts SET updated_at = CURRENT_TIMESTAMP WHERE id = ?", p.ID); err != nil {
+		return posting, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return posting, err
+	}
+
+	posting.Transactions = make([]models.Transaction, 0, len(ids))
+	for _, id := range ids {
+		t, err := s.getTransactionByID(id)
+		if err != nil {
+			return posting, err
+		}
+		posting.Transactions = append(posting.Transactions, t)
+	}
+	return posting, nil
+}
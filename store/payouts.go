@@ -8,9 +8,10 @@ import (
 	"github.com/satheeshds/portal/models"
 )
 
-const payoutSelectQuery = `SELECT id, outlet_name, platform, period_start, period_end, settlement_date,
+const payoutSelectQuery = `SELECT id, outlet_id, outlet_name, platform, period_start, period_end, settlement_date,
 		total_orders, gross_sales_amt, restaurant_discount_amt, platform_commission_amt,
-		taxes_tcs_tds_amt, marketing_ads_amt, final_payout_amt, utr_number, created_at,
+		taxes_tcs_tds_amt, marketing_ads_amt, final_payout_amt, COALESCE(utr_number, ''),
+		COALESCE(variance_amount, 0), COALESCE(variance_reason, ''), created_at, COALESCE(updated_at, created_at),
 		COALESCE((SELECT SUM(td.amount) FROM transaction_documents td WHERE td.document_type = 'payout' AND td.document_id = payouts.id), 0)
 		FROM payouts`
 
@@ -23,13 +24,21 @@ type PayoutLink struct {
 	AccountName     string `json:"account_name"`
 }
 
+// PayoutDetail is a payout together with the transactions allocated to it.
+type PayoutDetail struct {
+	models.Payout
+	Payments []PayoutLink `json:"payments"`
+}
+
 func scanPayout(scanner interface{ Scan(...any) error }) (models.Payout, error) {
 	var p models.Payout
-	err := scanner.Scan(&p.ID, &p.OutletName, &p.Platform, &p.PeriodStart, &p.PeriodEnd, &p.SettlementDate,
+	err := scanner.Scan(&p.ID, &p.OutletID, &p.OutletName, &p.Platform, &p.PeriodStart, &p.PeriodEnd, &p.SettlementDate,
 		&p.TotalOrders, &p.GrossSalesAmt, &p.RestaurantDiscountAmt, &p.PlatformCommissionAmt,
-		&p.TaxesTcsTdsAmt, &p.MarketingAdsAmt, &p.FinalPayoutAmt, &p.UtrNumber, &p.CreatedAt, &p.Allocated)
+		&p.TaxesTcsTdsAmt, &p.MarketingAdsAmt, &p.FinalPayoutAmt, &p.UtrNumber,
+		&p.VarianceAmount, &p.VarianceReason, &p.CreatedAt, &p.UpdatedAt, &p.Allocated)
 	if err == nil {
-		p.Unallocated = models.Money(int64(p.FinalPayoutAmt) - int64(p.Allocated))
+		p.Unallocated = models.Money(int64(p.FinalPayoutAmt) - int64(p.Allocated) - int64(p.VarianceAmount))
+		p.AllocatedPct = models.AllocatedPct(p.Allocated, p.FinalPayoutAmt)
 	}
 	return p, err
 }
@@ -39,7 +48,10 @@ func (s *Store) getPayoutByID(id int) (models.Payout, error) {
 }
 
 // ListPayouts returns payouts filtered by the provided parameters (all may be empty).
-func (s *Store) ListPayouts(platform, outletName, from, to string) ([]models.Payout, error) {
+// With unmatched, only payouts whose allocated amount plus any recorded
+// variance falls short of final_payout_amt by more than AllocationTolerance
+// are returned.
+func (s *Store) ListPayouts(platform, outletID, outletName, from, to string, unmatched bool) ([]models.Payout, error) {
 	query := payoutSelectQuery
 	var conditions []string
 	var args []any
@@ -48,6 +60,10 @@ func (s *Store) ListPayouts(platform, outletName, from, to string) ([]models.Pay
 		conditions = append(conditions, "platform = ?")
 		args = append(args, platform)
 	}
+	if outletID != "" {
+		conditions = append(conditions, "outlet_id = ?")
+		args = append(args, outletID)
+	}
 	if outletName != "" {
 		conditions = append(conditions, "outlet_name LIKE ?")
 		args = append(args, "%"+outletName+"%")
@@ -60,6 +76,11 @@ func (s *Store) ListPayouts(platform, outletName, from, to string) ([]models.Pay
 		conditions = append(conditions, "settlement_date <= ?")
 		args = append(args, to)
 	}
+	if unmatched {
+		conditions = append(conditions, `COALESCE((SELECT SUM(td.amount) FROM transaction_documents td
+			WHERE td.document_type = 'payout' AND td.document_id = payouts.id), 0) + COALESCE(variance_amount, 0) + ? < final_payout_amt`)
+		args = append(args, AllocationTolerance)
+	}
 
 	if len(conditions) > 0 {
 		query += " WHERE " + strings.Join(conditions, " AND ")
@@ -86,36 +107,49 @@ func (s *Store) ListPayouts(platform, outletName, from, to string) ([]models.Pay
 	return payouts, nil
 }
 
-// GetPayout returns a single payout by ID. Returns sql.ErrNoRows if not found.
-func (s *Store) GetPayout(id int) (models.Payout, error) {
-	return s.getPayoutByID(id)
+// GetPayout returns a single payout by ID along with its linked payments.
+// Returns sql.ErrNoRows if not found.
+func (s *Store) GetPayout(id int) (PayoutDetail, error) {
+	p, err := s.getPayoutByID(id)
+	if err != nil {
+		return PayoutDetail{}, err
+	}
+	payments, err := s.GetPayoutLinks(id, LinkPage{})
+	if err != nil {
+		return PayoutDetail{}, err
+	}
+	return PayoutDetail{Payout: p, Payments: payments}, nil
 }
 
 // CreatePayout inserts a new payout record and returns it.
 func (s *Store) CreatePayout(input models.PayoutInput) (models.Payout, error) {
-	var id int
-	err := s.db.QueryRow(`INSERT INTO payouts (outlet_name, platform, period_start, period_end, settlement_date,
-		total_orders, gross_sales_amt, restaurant_discount_amt, platform_commission_amt,
-		taxes_tcs_tds_amt, marketing_ads_amt, final_payout_amt, utr_number)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id`,
-		input.OutletName, input.Platform, input.PeriodStart, input.PeriodEnd, input.SettlementDate,
-		input.TotalOrders, input.GrossSalesAmt, input.RestaurantDiscountAmt, input.PlatformCommissionAmt,
-		input.TaxesTcsTdsAmt, input.MarketingAdsAmt, input.FinalPayoutAmt, input.UtrNumber).Scan(&id)
+	id, err := insertPayout(s.db, input)
 	if err != nil {
 		return models.Payout{}, err
 	}
 	return s.getPayoutByID(id)
 }
 
+// insertPayout inserts the payout CreatePayout creates and returns its id.
+func insertPayout(q rowQuerier, input models.PayoutInput) (int, error) {
+	return insertReturningID(q, `INSERT INTO payouts (outlet_id, outlet_name, platform, period_start, period_end, settlement_date,
+		total_orders, gross_sales_amt, restaurant_discount_amt, platform_commission_amt,
+		taxes_tcs_tds_amt, marketing_ads_amt, final_payout_amt, utr_number)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		input.OutletID, input.OutletName, input.Platform, input.PeriodStart, input.PeriodEnd, input.SettlementDate,
+		input.TotalOrders, input.GrossSalesAmt, input.RestaurantDiscountAmt, input.PlatformCommissionAmt,
+		input.TaxesTcsTdsAmt, input.MarketingAdsAmt, input.FinalPayoutAmt, nullIfEmpty(input.UtrNumber))
+}
+
 // UpdatePayout updates an existing payout. Returns sql.ErrNoRows if not found.
 func (s *Store) UpdatePayout(id int, input models.PayoutInput) (models.Payout, error) {
-	res, err := s.db.Exec(`UPDATE payouts SET outlet_name = ?, platform = ?, period_start = ?, period_end = ?,
+	res, err := s.db.Exec(`UPDATE payouts SET outlet_id = ?, outlet_name = ?, platform = ?, period_start = ?, period_end = ?,
 		settlement_date = ?, total_orders = ?, gross_sales_amt = ?, restaurant_discount_amt = ?,
 		platform_commission_amt = ?, taxes_tcs_tds_amt = ?, marketing_ads_amt = ?, final_payout_amt = ?,
-		utr_number = ? WHERE id = ?`,
-		input.OutletName, input.Platform, input.PeriodStart, input.PeriodEnd, input.SettlementDate,
+		utr_number = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
+		input.OutletID, input.OutletName, input.Platform, input.PeriodStart, input.PeriodEnd, input.SettlementDate,
 		input.TotalOrders, input.GrossSalesAmt, input.RestaurantDiscountAmt, input.PlatformCommissionAmt,
-		input.TaxesTcsTdsAmt, input.MarketingAdsAmt, input.FinalPayoutAmt, input.UtrNumber, id)
+		input.TaxesTcsTdsAmt, input.MarketingAdsAmt, input.FinalPayoutAmt, nullIfEmpty(input.UtrNumber), id)
 	if err != nil {
 		return models.Payout{}, err
 	}
@@ -125,17 +159,39 @@ func (s *Store) UpdatePayout(id int, input models.PayoutInput) (models.Payout, e
 	return s.getPayoutByID(id)
 }
 
-// DeletePayout removes a payout and its transaction links. Returns sql.ErrNoRows if not found.
+// SetPayoutVariance records amount as the variance of payout id, explained by
+// reason. An amount of zero clears it. Returns sql.ErrNoRows if not found.
+func (s *Store) SetPayoutVariance(id int, amount models.Money, reason string) (models.Payout, error) {
+	res, err := s.db.Exec("UPDATE payouts SET variance_amount = ?, variance_reason = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?",
+		amount, nullIfEmpty(reason), id)
+	if err != nil {
+		return models.Payout{}, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.Payout{}, sql.ErrNoRows
+	}
+	return s.getPayoutByID(id)
+}
+
+// DeletePayout removes a payout, its orders, and its transaction links. Returns sql.ErrNoRows if not found.
 func (s *Store) DeletePayout(ctx context.Context, id int) error {
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 
+	if err := touchLinkedTransactions(tx, "payout", id); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
 	if _, err := tx.Exec("DELETE FROM transaction_documents WHERE document_type = 'payout' AND document_id = ?", id); err != nil {
 		_ = tx.Rollback()
 		return err
 	}
+	if _, err := tx.Exec("DELETE FROM payout_orders WHERE payout_id = ?", id); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
 
 	res, err := tx.Exec("DELETE FROM payouts WHERE id = ?", id)
 	if err != nil {
@@ -152,18 +208,23 @@ func (s *Store) DeletePayout(ctx context.Context, id int) error {
 		_ = tx.Rollback()
 		return sql.ErrNoRows
 	}
+	if err := recordDeletion(tx, "payout", id); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
 
 	return tx.Commit()
 }
 
-// GetPayoutLinks returns all transaction links for the given payout.
-func (s *Store) GetPayoutLinks(id int) ([]PayoutLink, error) {
-	rows, err := s.db.Query(`SELECT td.id, td.transaction_id, td.document_type, td.document_id, td.amount, td.created_at,
+// GetPayoutLinks returns transaction links for the given payout, ordered and paginated by page.
+func (s *Store) GetPayoutLinks(id int, page LinkPage) ([]PayoutLink, error) {
+	suffix, args := page.clause("td")
+	rows, err := s.db.Query(`SELECT td.id, td.transaction_id, td.document_type, td.document_id, td.amount, td.note, td.created_at,
 		COALESCE(t.transaction_date, ''), COALESCE(t.description, ''), COALESCE(t.reference, ''), a.name as account_name
 		FROM transaction_documents td
 		JOIN transactions t ON td.transaction_id = t.id
 		JOIN accounts a ON t.account_id = a.id
-		WHERE td.document_type = 'payout' AND td.document_id = ?`, id)
+		WHERE td.document_type = 'payout' AND td.document_id = ?`+suffix, append([]any{id}, args...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -172,7 +233,7 @@ func (s *Store) GetPayoutLinks(id int) ([]PayoutLink, error) {
 	var links []PayoutLink
 	for rows.Next() {
 		var l PayoutLink
-		if err := rows.Scan(&l.ID, &l.TransactionID, &l.DocumentType, &l.DocumentID, &l.Amount, &l.CreatedAt,
+		if err := rows.Scan(&l.ID, &l.TransactionID, &l.DocumentType, &l.DocumentID, &l.Amount, &l.Note, &l.CreatedAt,
 			&l.TransactionDate, &l.Description, &l.Reference, &l.AccountName); err != nil {
 			return nil, err
 		}
@@ -0,0 +1,80 @@
+package store
+
+import (
+	"time"
+
+	"github.com/satheeshds/portal/models"
+)
+
+// ClosedThrough returns the latest closed-through date, or a zero Date when no
+// period has been closed.
+func (s *Store) ClosedThrough() (models.Date, error) {
+	var d models.Date
+	err := s.db.QueryRow("SELECT MAX(closed_through) FROM closed_periods").Scan(&d)
+	return d, err
+}
+
+// ListClosedPeriods returns all period closes, latest first.
+func (s *Store) ListClosedPeriods() ([]models.ClosedPeriod, error) {
+	rows, err := s.db.Query("SELECT id, closed_through, notes, created_at FROM closed_periods ORDER BY closed_through DESC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var periods []models.ClosedPeriod
+	for rows.Next() {
+		var p models.ClosedPeriod
+		if err := rows.Scan(&p.ID, &p.ClosedThrough, &p.Notes, &p.CreatedAt); err != nil {
+			return nil, err
+		}
+		periods = append(periods, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if periods == nil {
+		periods = []models.ClosedPeriod{}
+	}
+	return periods, nil
+}
+
+// ClosePeriod closes the books up to and including input.Date.
+func (s *Store) ClosePeriod(input models.PeriodInput) (models.ClosedPeriod, error) {
+	id, err := insertReturningID(s.db, "INSERT INTO closed_periods (closed_through, notes) VALUES (?, ?)", input.Date, input.Notes)
+	if err != nil {
+		return models.ClosedPeriod{}, err
+	}
+	var p models.ClosedPeriod
+	err = s.db.QueryRow("SELECT id, closed_through, notes, created_at FROM closed_periods WHERE id = ?", id).
+		Scan(&p.ID, &p.ClosedThrough, &p.Notes, &p.CreatedAt)
+	return p, err
+}
+
+// ReopenPeriod makes from (YYYY-MM-DD) and every later date writable again.
+// Closes covering from are removed and replaced by one ending the day before,
+// so earlier dates stay closed.
+func (s *Store) ReopenPeriod(from string, notes *string) error {
+	start, err := time.Parse("2006-01-02", from)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.Exec("DELETE FROM closed_periods WHERE closed_through >= ?", from)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		if _, err := tx.Exec("INSERT INTO closed_periods (closed_through, notes) VALUES (?, ?)",
+			start.AddDate(0, 0, -1).Format("2006-01-02"), notes); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,92 @@
+package store
+
+// orphanQueries delete rows whose parent no longer exists, keyed by table.
+// Occurrences go first so links to the occurrences they remove are caught by
+// the link query.
+var orphanQueries = []struct {
+	table string
+	query string
+}{
+	{"recurring_payment_occurrences", `DELETE FROM recurring_payment_occurrences
+		WHERE NOT EXISTS (SELECT 1 FROM recurring_payments rp WHERE rp.id = recurring_payment_occurrences.recurring_payment_id)`},
+	{"bill_items", `DELETE FROM bill_items WHERE NOT EXISTS (SELECT 1 FROM bills b WHERE b.id = bill_items.bill_id)`},
+	{"invoice_items", `DELETE FROM invoice_items WHERE NOT EXISTS (SELECT 1 FROM invoices i WHERE i.id = invoice_items.invoice_id)`},
+	{"payout_orders", `DELETE FROM payout_orders WHERE NOT EXISTS (SELECT 1 FROM payouts p WHERE p.id = payout_orders.payout_id)`},
+	{"transaction_documents", "DELETE FROM transaction_documents WHERE " + orphanLinkCondition},
+}
+
+// orphanLinkCondition matches links whose transaction or document is gone.
+const orphanLinkCondition = `NOT EXISTS (SELECT 1 FROM transactions t WHERE t.id = transaction_documents.transaction_id)
+	OR (document_type = 'bill' AND NOT EXISTS (SELECT 1 FROM bills b WHERE b.id = transaction_documents.document_id))
+	OR (document_type = 'invoice' AND NOT EXISTS (SELECT 1 FROM invoices i WHERE i.id = transaction_documents.document_id))
+	OR (document_type = 'payout' AND NOT EXISTS (SELECT 1 FROM payouts p WHERE p.id = transaction_documents.document_id))
+	OR (document_type = 'recurring_payment_occurrence' AND NOT EXISTS
+		(SELECT 1 FROM recurring_payment_occurrences o WHERE o.id = transaction_documents.document_id))`
+
+// PurgeOrphans hard-deletes rows left pointing at records that no longer
+// exist: occurrences of deleted recurring payments, items of deleted bills
+// and invoices, orders of deleted payouts, and links whose transaction or
+// document is gone. Removing a link changes what its document has been paid,
+// so the status and contact balance of each document still present are
+// worked out again, as when a link is deleted, and the transactions still
+// present are marked changed. Tombstones in deletions older than
+// DeletionsRetentionDays are dropped too, so a sync cursor older than that
+// needs a full sync. It returns the number of rows removed per table. All
+// deletes run in one transaction.
+func (s *Store) PurgeOrphans() (map[string]int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var affected []docRef
+	purged := make(map[string]int, len(orphanQueries))
+	for _, q := range orphanQueries {
+		if q.table == "transaction_documents" {
+			rows, err := tx.Query("SELECT DISTINCT document_type, document_id FROM transaction_documents WHERE " + orphanLinkCondition)
+			if err != nil {
+				return nil, err
+			}
+			for rows.Next() {
+				var dr docRef
+				if err := rows.Scan(&dr.docType, &dr.docID); err != nil {
+					rows.Close()
+					return nil, err
+				}
+				affected = append(affected, dr)
+			}
+			rows.Close()
+			if err := rows.Err(); err != nil {
+				return nil, err
+			}
+			if _, err := tx.Exec(`UPDATE transactions SET updated_at = CURRENT_TIMESTAMP
+				WHERE id IN (SELECT transaction_id FROM transaction_documents WHERE ` + orphanLinkCondition + ")"); err != nil {
+				return nil, err
+			}
+		}
+		res, err := tx.Exec(q.query)
+		if err != nil {
+			return nil, err
+		}
+		n, _ := res.RowsAffected()
+		purged[q.table] = int(n)
+	}
+	purged["deletions"] = 0
+	if DeletionsRetentionDays > 0 {
+		res, err := tx.Exec("DELETE FROM deletions WHERE deleted_at < CAST(CURRENT_TIMESTAMP AS TIMESTAMP) - to_days(CAST(? AS INTEGER))", DeletionsRetentionDays)
+		if err != nil {
+			return nil, err
+		}
+		n, _ := res.RowsAffected()
+		purged["deletions"] = int(n)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	// A document that is gone is skipped by UpdateDocumentStatus.
+	for _, dr := range affected {
+		s.UpdateDocumentStatus(dr.docType, dr.docID)
+	}
+	return purged, nil
+}
@@ -92,13 +92,12 @@ func (s *Store) GetRecurringPayment(id int) (models.RecurringPayment, error) {
 
 // CreateRecurringPayment inserts a new recurring payment and returns it.
 func (s *Store) CreateRecurringPayment(input models.RecurringPaymentInput) (models.RecurringPayment, error) {
-	var id int
-	err := s.db.QueryRow(`INSERT INTO recurring_payments
+	id, err := insertReturningID(s.db, `INSERT INTO recurring_payments
 		(name, type, amount, account_id, contact_id, frequency, interval, start_date, end_date, next_due_date, status, description, reference)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id`,
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		input.Name, input.Type, input.Amount, input.AccountID, input.ContactID,
 		input.Frequency, input.Interval, input.StartDate, input.EndDate, input.NextDueDate,
-		input.Status, input.Description, input.Reference).Scan(&id)
+		input.Status, input.Description, input.Reference)
 	if err != nil {
 		return models.RecurringPayment{}, err
 	}
@@ -133,13 +132,13 @@ func (s *Store) DeleteRecurringPayment(id int) error {
 	if n, _ := res.RowsAffected(); n == 0 {
 		return sql.ErrNoRows
 	}
-	return nil
+	return recordDeletion(s.db, "recurring_payment", id)
 }
 
 // GetRecurringPaymentLinks returns all transaction links for the given recurring payment.
 func (s *Store) GetRecurringPaymentLinks(id int) ([]RecurringPaymentLink, error) {
 	rows, err := s.db.Query(`
-		SELECT td.id, td.transaction_id, td.document_type, td.document_id, td.amount, td.created_at,
+		SELECT td.id, td.transaction_id, td.document_type, td.document_id, td.amount, td.note, td.created_at,
 			COALESCE(t.transaction_date, ''), COALESCE(t.description, ''), COALESCE(t.reference, ''), a.name,
 			rpo.due_date, rpo.status
 		FROM transaction_documents td
@@ -157,7 +156,7 @@ func (s *Store) GetRecurringPaymentLinks(id int) ([]RecurringPaymentLink, error)
 	var links []RecurringPaymentLink
 	for rows.Next() {
 		var l RecurringPaymentLink
-		if err := rows.Scan(&l.ID, &l.TransactionID, &l.DocumentType, &l.DocumentID, &l.Amount, &l.CreatedAt,
+		if err := rows.Scan(&l.ID, &l.TransactionID, &l.DocumentType, &l.DocumentID, &l.Amount, &l.Note, &l.CreatedAt,
 			&l.TransactionDate, &l.Description, &l.Reference, &l.AccountName,
 			&l.OccurrenceDueDate, &l.OccurrenceStatus); err != nil {
 			return nil, err
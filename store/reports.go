@@ -0,0 +1,461 @@
+package store
+
+import (
+	"math"
+	"strings"
+
+	"github.com/satheeshds/portal/models"
+)
+
+// payoutReportDate is the date a payout is reported under: its settlement date,
+// or the end of its period when it has not settled yet. settlement_date is
+// stored as text, so it is cast to match period_end.
+const payoutReportDate = "COALESCE(CAST(NULLIF(p.settlement_date, '') AS DATE), p.period_end)"
+
+// TDSReportRow is the TDS/TCS withheld for one platform and outlet in one month.
+type TDSReportRow struct {
+	Month          string       `json:"month"` // YYYY-MM, empty for undated payouts
+	Platform       string       `json:"platform"`
+	OutletName     string       `json:"outlet_name"`
+	Payouts        int          `json:"payouts"`
+	GrossSalesAmt  models.Money `json:"gross_sales_amt"`
+	TaxesTcsTdsAmt models.Money `json:"taxes_tcs_tds_amt"`
+}
+
+// TDSReport summarises TDS/TCS withheld from payouts.
+type TDSReport struct {
+	Rows       []TDSReportRow          `json:"rows"`
+	ByPlatform map[string]models.Money `json:"by_platform"`
+	Total      models.Money            `json:"total_taxes_tcs_tds_amt"`
+}
+
+// GetTDSReport sums taxes_tcs_tds_amt per month, platform, and outlet. from and
+// to (YYYY-MM-DD) bound the payout's settlement date, falling back to its
+// period end; platform optionally restricts the report to one platform. With
+// byPeriod, each payout is instead apportioned by days across the months its
+// period covers, and from and to bound those days.
+func (s *Store) GetTDSReport(from, to, platform string, byPeriod bool) (TDSReport, error) {
+	if byPeriod {
+		return s.getTDSReportByPeriod(from, to, platform)
+	}
+	report := TDSReport{Rows: []TDSReportRow{}, ByPlatform: map[string]models.Money{}}
+
+	var conditions []string
+	var args []any
+	if from != "" {
+		conditions = append(conditions, payoutReportDate+" >= ?")
+		args = append(args, from)
+	}
+	if to != "" {
+		conditions = append(conditions, payoutReportDate+" <= ?")
+		args = append(args, to)
+	}
+	if platform != "" {
+		conditions = append(conditions, "p.platform = ?")
+		args = append(args, platform)
+	}
+
+	query := `SELECT COALESCE(strftime(` + payoutReportDate + `, '%Y-%m'), '') AS month, p.platform, p.outlet_name,
+		COUNT(*), COALESCE(SUM(p.gross_sales_amt), 0), COALESCE(SUM(p.taxes_tcs_tds_amt), 0)
+		FROM payouts p`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " GROUP BY month, p.platform, p.outlet_name ORDER BY month, p.platform, p.outlet_name"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return report, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row TDSReportRow
+		if err := rows.Scan(&row.Month, &row.Platform, &row.OutletName, &row.Payouts, &row.GrossSalesAmt, &row.TaxesTcsTdsAmt); err != nil {
+			return report, err
+		}
+		report.Rows = append(report.Rows, row)
+		report.ByPlatform[row.Platform] += row.TaxesTcsTdsAmt
+		report.Total += row.TaxesTcsTdsAmt
+	}
+	return report, rows.Err()
+}
+
+// PayoutVarianceRow is one payout settled with a variance.
+type PayoutVarianceRow struct {
+	PayoutID       int          `json:"payout_id"`
+	Date           models.Date  `json:"date"` // settlement date, or period end when unsettled
+	Platform       string       `json:"platform"`
+	OutletName     string       `json:"outlet_name"`
+	UtrNumber      string       `json:"utr_number"`
+	FinalPayoutAmt models.Money `json:"final_payout_amt"`
+	VarianceAmount models.Money `json:"variance_amount"`
+	VarianceReason string       `json:"variance_reason"`
+}
+
+// PayoutVarianceReport lists payout variances and totals them per platform.
+type PayoutVarianceReport struct {
+	Rows       []PayoutVarianceRow     `json:"rows"`
+	ByPlatform map[string]models.Money `json:"by_platform"`
+	Total      models.Money            `json:"total_variance_amount"`
+}
+
+// GetPayoutVarianceReport lists the payouts with a recorded variance, oldest
+// first. from, to, and platform filter as in GetTDSReport.
+func (s *Store) GetPayoutVarianceReport(from, to, platform string) (PayoutVarianceReport, error) {
+	report := PayoutVarianceReport{Rows: []PayoutVarianceRow{}, ByPlatform: map[string]models.Money{}}
+
+	conditions := []string{"COALESCE(p.variance_amount, 0) <> 0"}
+	var args []any
+	if from != "" {
+		conditions = append(conditions, payoutReportDate+" >= ?")
+		args = append(args, from)
+	}
+	if to != "" {
+		conditions = append(conditions, payoutReportDate+" <= ?")
+		args = append(args, to)
+	}
+	if platform != "" {
+		conditions = append(conditions, "p.platform = ?")
+		args = append(args, platform)
+	}
+
+	rows, err := s.db.Query(`SELECT p.id, `+payoutReportDate+`, p.platform, COALESCE(p.outlet_name, ''), COALESCE(p.utr_number, ''),
+		p.final_payout_amt, p.variance_amount, COALESCE(p.variance_reason, '')
+		FROM payouts p WHERE `+strings.Join(conditions, " AND ")+`
+		ORDER BY 2, p.id`, args...)
+	if err != nil {
+		return report, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row PayoutVarianceRow
+		if err := rows.Scan(&row.PayoutID, &row.Date, &row.Platform, &row.OutletName, &row.UtrNumber,
+			&row.FinalPayoutAmt, &row.VarianceAmount, &row.VarianceReason); err != nil {
+			return report, err
+		}
+		report.Rows = append(report.Rows, row)
+		report.ByPlatform[row.Platform] += row.VarianceAmount
+		report.Total += row.VarianceAmount
+	}
+	return report, rows.Err()
+}
+
+// OutletReportRow sums the payouts of one outlet.
+type OutletReportRow struct {
+	OutletID              *int         `json:"outlet_id"` // nil for payouts not linked to an outlet
+	OutletName            string       `json:"outlet_name"`
+	Payouts               int          `json:"payouts"`
+	TotalOrders           int          `json:"total_orders"`
+	GrossSalesAmt         models.Money `json:"gross_sales_amt"`
+	RestaurantDiscountAmt models.Money `json:"restaurant_discount_amt"`
+	PlatformCommissionAmt models.Money `json:"platform_commission_amt"`
+	TaxesTcsTdsAmt        models.Money `json:"taxes_tcs_tds_amt"`
+	MarketingAdsAmt       models.Money `json:"marketing_ads_amt"`
+	FinalPayoutAmt        models.Money `json:"final_payout_amt"`
+}
+
+// GetOutletReport sums payouts per outlet. Payouts are grouped by outlet_id,
+// so spelling differences in their outlet_name do not split an outlet, and
+// each row carries the outlet's current name. from, to, and platform filter
+// as in GetTDSReport.
+func (s *Store) GetOutletReport(from, to, platform string) ([]OutletReportRow, error) {
+	var conditions []string
+	var args []any
+	if from != "" {
+		conditions = append(conditions, payoutReportDate+" >= ?")
+		args = append(args, from)
+	}
+	if to != "" {
+		conditions = append(conditions, payoutReportDate+" <= ?")
+		args = append(args, to)
+	}
+	if platform != "" {
+		conditions = append(conditions, "p.platform = ?")
+		args = append(args, platform)
+	}
+
+	query := `SELECT p.outlet_id, COALESCE(MAX(o.name), MAX(p.outlet_name), ''), COUNT(*),
+		COALESCE(SUM(p.total_orders), 0), COALESCE(SUM(p.gross_sales_amt), 0), COALESCE(SUM(p.restaurant_discount_amt), 0),
+		COALESCE(SUM(p.platform_commission_amt), 0), COALESCE(SUM(p.taxes_tcs_tds_amt), 0),
+		COALESCE(SUM(p.marketing_ads_amt), 0), COALESCE(SUM(p.final_payout_amt), 0)
+		FROM payouts p
+		LEFT JOIN outlets o ON p.outlet_id = o.id`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " GROUP BY p.outlet_id ORDER BY 2"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	report := []OutletReportRow{}
+	for rows.Next() {
+		var row OutletReportRow
+		if err := rows.Scan(&row.OutletID, &row.OutletName, &row.Payouts, &row.TotalOrders, &row.GrossSalesAmt,
+			&row.RestaurantDiscountAmt, &row.PlatformCommissionAmt, &row.TaxesTcsTdsAmt, &row.MarketingAdsAmt,
+			&row.FinalPayoutAmt); err != nil {
+			return nil, err
+		}
+		report = append(report, row)
+	}
+	return report, rows.Err()
+}
+
+// VendorSpendRow is what was billed by and paid to one vendor.
+type VendorSpendRow struct {
+	ContactID   int          `json:"contact_id"`
+	ContactName string       `json:"contact_name"`
+	Bills       int          `json:"bills"`
+	Billed      models.Money `json:"billed"`
+	Paid        models.Money `json:"paid"`
+	PctOfTotal  float64      `json:"pct_of_total"` // share of the total billed, in percent
+}
+
+// VendorSpendReport ranks vendors by the amount they billed.
+type VendorSpendReport struct {
+	Rows        []VendorSpendRow `json:"rows"`
+	TotalBilled models.Money     `json:"total_billed"`
+	TotalPaid   models.Money     `json:"total_paid"`
+}
+
+// GetVendorSpendReport sums bills and the payments allocated to them per
+// vendor contact, largest spend first. from and to (YYYY-MM-DD) bound the
+// bill's issue date for billed amounts and the transaction date for payments,
+// so paid reflects cash that left in the range. Cancelled bills are excluded
+// from billed amounts.
+func (s *Store) GetVendorSpendReport(from, to string) (VendorSpendReport, error) {
+	report := VendorSpendReport{Rows: []VendorSpendRow{}}
+
+	billConditions := []string{"status <> 'cancelled'"}
+	var paidConditions []string
+	var billArgs, paidArgs []any
+	if from != "" {
+		billConditions = append(billConditions, "issue_date >= ?")
+		billArgs = append(billArgs, from)
+		paidConditions = append(paidConditions, "t.transaction_date >= ?")
+		paidArgs = append(paidArgs, from)
+	}
+	if to != "" {
+		billConditions = append(billConditions, "issue_date <= ?")
+		billArgs = append(billArgs, to)
+		paidConditions = append(paidConditions, "t.transaction_date <= ?")
+		paidArgs = append(paidArgs, to)
+	}
+
+	paidWhere := ""
+	if len(paidConditions) > 0 {
+		paidWhere = " WHERE " + strings.Join(paidConditions, " AND ")
+	}
+	query := `SELECT c.id, c.name, COALESCE(b.bills, 0), COALESCE(b.billed, 0), COALESCE(p.paid, 0)
+		FROM contacts c
+		LEFT JOIN (SELECT contact_id, COUNT(*) AS bills, SUM(amount) AS billed FROM bills
+			WHERE ` + strings.Join(billConditions, " AND ") + ` GROUP BY contact_id) b ON b.contact_id = c.id
+		LEFT JOIN (SELECT pb.contact_id, SUM(td.amount) AS paid FROM transaction_documents td
+			JOIN bills pb ON td.document_type = 'bill' AND td.document_id = pb.id
+			JOIN transactions t ON t.id = td.transaction_id` + paidWhere + ` GROUP BY pb.contact_id) p ON p.contact_id = c.id
+		WHERE c.type = 'vendor' AND (b.billed IS NOT NULL OR p.paid IS NOT NULL)
+		ORDER BY 4 DESC, 5 DESC, c.name`
+
+	rows, err := s.db.Query(query, append(billArgs, paidArgs...)...)
+	if err != nil {
+		return report, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row VendorSpendRow
+		if err := rows.Scan(&row.ContactID, &row.ContactName, &row.Bills, &row.Billed, &row.Paid); err != nil {
+			return report, err
+		}
+		report.Rows = append(report.Rows, row)
+		report.TotalBilled += row.Billed
+		report.TotalPaid += row.Paid
+	}
+	if err := rows.Err(); err != nil {
+		return report, err
+	}
+
+	if report.TotalBilled != 0 {
+		for i := range report.Rows {
+			pct := float64(report.Rows[i].Billed) * 100 / float64(report.TotalBilled)
+			report.Rows[i].PctOfTotal = math.Round(pct*100) / 100
+		}
+	}
+	return report, nil
+}
+
+// CommissionTrendRow is what a platform kept from one month's payouts.
+type CommissionTrendRow struct {
+	Month                 string       `json:"month"` // YYYY-MM
+	Payouts               int          `json:"payouts"`
+	GrossSalesAmt         models.Money `json:"gross_sales_amt"`
+	PlatformCommissionAmt models.Money `json:"platform_commission_amt"`
+	MarketingAdsAmt       models.Money `json:"marketing_ads_amt"`
+	TakeRatePct           float64      `json:"take_rate_pct"` // (commission + ads) / gross sales, in percent
+}
+
+// GetCommissionTrend sums commission and ads against gross sales per month
+// from since (YYYY-MM-DD) onwards, oldest month first. platform and outletID
+// optionally restrict the payouts; months without payouts are omitted. The
+// take rate is 0 for a month with no gross sales. byPeriod apportions
+// payouts as in GetTDSReport.
+func (s *Store) GetCommissionTrend(since, platform, outletID string, byPeriod bool) ([]CommissionTrendRow, error) {
+	if byPeriod {
+		return s.getCommissionTrendByPeriod(since, platform, outletID)
+	}
+	conditions := []string{payoutReportDate + " >= ?"}
+	args := []any{since}
+	if platform != "" {
+		conditions = append(conditions, "p.platform = ?")
+		args = append(args, platform)
+	}
+	if outletID != "" {
+		conditions = append(conditions, "p.outlet_id = ?")
+		args = append(args, outletID)
+	}
+
+	rows, err := s.db.Query(`SELECT strftime(`+payoutReportDate+`, '%Y-%m') AS month, COUNT(*),
+		COALESCE(SUM(p.gross_sales_amt), 0), COALESCE(SUM(p.platform_commission_amt), 0), COALESCE(SUM(p.marketing_ads_amt), 0)
+		FROM payouts p
+		WHERE `+strings.Join(conditions, " AND ")+`
+		GROUP BY month ORDER BY month`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	trend := []CommissionTrendRow{}
+	for rows.Next() {
+		var row CommissionTrendRow
+		if err := rows.Scan(&row.Month, &row.Payouts, &row.GrossSalesAmt, &row.PlatformCommissionAmt, &row.MarketingAdsAmt); err != nil {
+			return nil, err
+		}
+		if row.GrossSalesAmt > 0 {
+			pct := float64(row.PlatformCommissionAmt+row.MarketingAdsAmt) * 100 / float64(row.GrossSalesAmt)
+			row.TakeRatePct = math.Round(pct*100) / 100
+		}
+		trend = append(trend, row)
+	}
+	return trend, rows.Err()
+}
+
+// TrialBalanceRow is one line of the trial balance. A positive balance is a
+// debit and a negative one a credit.
+type TrialBalanceRow struct {
+	AccountID *int         `json:"account_id"` // nil for the derived receivables, payables, and equity lines
+	Name      string       `json:"name"`
+	Kind      string       `json:"kind"` // account, receivables, payables, or equity
+	Currency  string       `json:"currency"`
+	Debit     models.Money `json:"debit"`
+	Credit    models.Money `json:"credit"`
+}
+
+// TrialBalance lists account balances and the derived control accounts on one
+// date, with debit and credit totals.
+type TrialBalance struct {
+	AsOf        string            `json:"as_of"`
+	Rows        []TrialBalanceRow `json:"rows"`
+	Excluded    []TrialBalanceRow `json:"excluded"` // accounts in other currencies, left out of the totals
+	TotalDebit  models.Money      `json:"total_debit"`
+	TotalCredit models.Money      `json:"total_credit"`
+	Difference  models.Money      `json:"difference"` // total_debit - total_credit
+}
+
+// trialBalanceLine sets the debit or credit of row from a signed balance.
+func trialBalanceLine(row TrialBalanceRow, balance models.Money) TrialBalanceRow {
+	if balance >= 0 {
+		row.Debit = balance
+	} else {
+		row.Credit = -balance
+	}
+	return row
+}
+
+// documentOutstandingAsOf sums what was still owed on asOf across the
+// non-cancelled documents of table issued by then, counting only allocations
+// from transactions dated by then. Undated rows are dated by their creation.
+func (s *Store) documentOutstandingAsOf(table, docType, asOf string) (models.Money, error) {
+	var total models.Money
+	err := s.db.QueryRow(`SELECT COALESCE(SUM(d.amount - COALESCE((SELECT SUM(td.amount) FROM transaction_documents td
+			JOIN transactions t ON t.id = td.transaction_id
+			WHERE td.document_type = ? AND td.document_id = d.id
+			AND COALESCE(t.transaction_date, CAST(t.created_at AS DATE)) <= ?), 0)), 0)
+		FROM `+table+` d
+		WHERE d.status <> 'cancelled' AND COALESCE(d.issue_date, CAST(d.created_at AS DATE)) <= ?`,
+		docType, asOf, asOf).Scan(&total)
+	return total, err
+}
+
+// accountBalanceAsOfExpr is the balance of account a on the date bound to its
+// placeholder: the opening balance plus the transactions dated by then.
+const accountBalanceAsOfExpr = `a.opening_balance +
+		COALESCE((SELECT SUM(CASE t.type WHEN 'income' THEN t.amount WHEN 'expense' THEN -t.amount
+			WHEN 'adjustment' THEN t.amount * t.sign ELSE 0 END)
+			FROM transactions t WHERE t.account_id = a.id
+			AND COALESCE(t.transaction_date, CAST(t.created_at AS DATE)) <= ?), 0)`
+
+// GetTrialBalance synthesizes a trial balance on asOf (YYYY-MM-DD) from the
+// single-entry books: each account's balance from its opening balance and the
+// transactions dated by then, receivables outstanding on invoices (a debit),
+// and payables outstanding on bills (a credit). Equity is the balancing line
+// that the single-entry model leaves implicit, so the totals always agree.
+// Only accounts in BooksCurrency are summed; accounts in other
+// currencies are listed under Excluded.
+func (s *Store) GetTrialBalance(asOf string) (TrialBalance, error) {
+	tb := TrialBalance{AsOf: asOf, Rows: []TrialBalanceRow{}, Excluded: []TrialBalanceRow{}}
+
+	rows, err := s.db.Query(`SELECT a.id, a.name, COALESCE(a.currency, 'INR'), `+accountBalanceAsOfExpr+`
+		FROM accounts a ORDER BY a.name, a.id`, asOf)
+	if err != nil {
+		return tb, err
+	}
+	defer rows.Close()
+
+	var net models.Money
+	for rows.Next() {
+		var id int
+		var balance models.Money
+		row := TrialBalanceRow{Kind: "account"}
+		if err := rows.Scan(&id, &row.Name, &row.Currency, &balance); err != nil {
+			return tb, err
+		}
+		row.AccountID = &id
+		row = trialBalanceLine(row, balance)
+		if row.Currency != BooksCurrency {
+			tb.Excluded = append(tb.Excluded, row)
+			continue
+		}
+		tb.Rows = append(tb.Rows, row)
+		net += balance
+	}
+	if err := rows.Err(); err != nil {
+		return tb, err
+	}
+
+	receivable, err := s.documentOutstandingAsOf("invoices", "invoice", asOf)
+	if err != nil {
+		return tb, err
+	}
+	payable, err := s.documentOutstandingAsOf("bills", "bill", asOf)
+	if err != nil {
+		return tb, err
+	}
+	tb.Rows = append(tb.Rows,
+		trialBalanceLine(TrialBalanceRow{Name: "Accounts receivable", Kind: "receivables", Currency: BooksCurrency}, receivable),
+		trialBalanceLine(TrialBalanceRow{Name: "Accounts payable", Kind: "payables", Currency: BooksCurrency}, -payable))
+	net += receivable - payable
+	tb.Rows = append(tb.Rows,
+		trialBalanceLine(TrialBalanceRow{Name: "Equity (derived)", Kind: "equity", Currency: BooksCurrency}, -net))
+
+	for _, row := range tb.Rows {
+		tb.TotalDebit += row.Debit
+		tb.TotalCredit += row.Credit
+	}
+	tb.Difference = tb.TotalDebit - tb.TotalCredit
+	return tb, nil
+}
@@ -0,0 +1,97 @@
+package store
+
+import "github.com/satheeshds/portal/models"
+
+// StatusRecount counts the documents of one type checked and changed by
+// RecomputeDocumentStatuses.
+type StatusRecount struct {
+	Checked int `json:"checked"`
+	Changed int `json:"changed"`
+}
+
+// StatusRecomputeResult summarises RecomputeDocumentStatuses per document type.
+type StatusRecomputeResult struct {
+	Bills    StatusRecount `json:"bills"`
+	Invoices StatusRecount `json:"invoices"`
+	// Payouts carry no stored status; they are counted but never changed.
+	Payouts StatusRecount `json:"payouts"`
+}
+
+// RecomputeDocumentStatuses re-derives the status of every bill and invoice
+// from its allocations, as UpdateDocumentStatus does after a link changes, and
+// stores the ones that drifted. Documents are read and updated batchSize at a
+// time, each batch in its own transaction, so no single transaction spans the
+// whole table.
+func (s *Store) RecomputeDocumentStatuses(batchSize int) (StatusRecomputeResult, error) {
+	var result StatusRecomputeResult
+	var err error
+	if result.Bills, err = s.recomputeStatuses("bill", "bills", "paid", batchSize); err != nil {
+		return result, err
+	}
+	if result.Invoices, err = s.recomputeStatuses("invoice", "invoices", "received", batchSize); err != nil {
+		return result, err
+	}
+	err = s.db.QueryRow("SELECT COUNT(*) FROM payouts").Scan(&result.Payouts.Checked)
+	return result, err
+}
+
+// recomputeStatuses walks table in id order, batchSize rows at a time.
+func (s *Store) recomputeStatuses(docType, table, fullStatus string, batchSize int) (StatusRecount, error) {
+	var count StatusRecount
+	type change struct {
+		id     int
+		status string
+	}
+
+	lastID := 0
+	for {
+		rows, err := s.db.Query(`SELECT d.id, d.amount, COALESCE(d.status, ''),
+			COALESCE((SELECT SUM(td.amount) FROM transaction_documents td WHERE td.document_type = ? AND td.document_id = d.id), 0)
+			FROM `+table+` d WHERE d.id > ? ORDER BY d.id LIMIT ?`, docType, lastID, batchSize)
+		if err != nil {
+			return count, err
+		}
+		var changes []change
+		n := 0
+		for rows.Next() {
+			var id int
+			var current string
+			var total, allocated models.Money
+			if err := rows.Scan(&id, &total, &current, &allocated); err != nil {
+				rows.Close()
+				return count, err
+			}
+			n++
+			lastID = id
+			if status := derivedStatus(current, fullStatus, total, allocated); status != current {
+				changes = append(changes, change{id, status})
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return count, err
+		}
+		count.Checked += n
+
+		if len(changes) > 0 {
+			tx, err := s.db.Begin()
+			if err != nil {
+				return count, err
+			}
+			for _, c := range changes {
+				if _, err := tx.Exec("UPDATE "+table+" SET status = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?", c.status, c.id); err != nil {
+					_ = tx.Rollback()
+					return count, err
+				}
+			}
+			if err := tx.Commit(); err != nil {
+				return count, err
+			}
+			count.Changed += len(changes)
+		}
+
+		if n < batchSize {
+			return count, nil
+		}
+	}
+}
@@ -1,6 +1,35 @@
 package store
 
-import "github.com/satheeshds/portal/db"
+import (
+	"database/sql"
+	"time"
+
+	"github.com/satheeshds/portal/db"
+	"github.com/satheeshds/portal/models"
+)
+
+// AllocationTolerance is the rounding slack, in the smallest unit of
+// BooksCurrency, within which a document counts as fully allocated. It is set
+// from configuration at startup.
+var AllocationTolerance models.Money
+
+// BooksCurrency is the currency the books are kept in: the trial balance sums
+// only accounts in it, and GET /reports/fx revalues the others into it. It is
+// set from configuration at startup.
+var BooksCurrency = models.DefaultCurrency
+
+// LockReconciled makes reconciling a transaction also lock it against edits
+// and deletion. It is set from configuration at startup.
+var LockReconciled bool
+
+// ChangesOverlap is how far before a since cursor ListChanges starts
+// reading. It is set from configuration at startup.
+var ChangesOverlap time.Duration
+
+// DeletionsRetentionDays is how many days PurgeOrphans keeps the tombstones
+// GET /changes reports; zero keeps them forever. It is set from configuration
+// at startup.
+var DeletionsRetentionDays int
 
 // Store is the data access layer that wraps a database connection.
 type Store struct {
@@ -11,3 +40,22 @@ type Store struct {
 func New(d *db.PortalDB) *Store {
 	return &Store{db: d}
 }
+
+// rowQuerier is satisfied by both *db.PortalDB and *db.PortalTx.
+type rowQuerier interface {
+	QueryRow(query string, args ...any) *db.Row
+}
+
+// execer is satisfied by both *db.PortalDB and *db.PortalTx.
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// insertReturningID runs an INSERT statement and returns the id of the new row.
+// Ids are assigned by the gateway and read back with RETURNING, since the
+// PostgreSQL wire protocol has no LastInsertId.
+func insertReturningID(q rowQuerier, query string, args ...any) (int, error) {
+	var id int
+	err := q.QueryRow(query+" RETURNING id", args...).Scan(&id)
+	return id, err
+}
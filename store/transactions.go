@@ -2,33 +2,47 @@ package store
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
+	"time"
 
+	"github.com/satheeshds/portal/db"
 	"github.com/satheeshds/portal/models"
 )
 
 const txnSelectQuery = `SELECT t.id, t.account_id, t.type, t.amount, t.transaction_date,
-	t.description, t.reference, t.transfer_account_id, t.contact_id,
-	t.created_at, t.updated_at,
+	t.description, t.reference, t.transfer_account_id, t.contact_id, t.category_id,
+	t.created_at, t.updated_at, COALESCE(t.reconciled, false), t.external_id, t.source, t.exchange_rate, t.sign,
+	COALESCE(t.cleared, true), t.cleared_date, COALESCE(t.locked, false),
 	a.name,
+	COALESCE(a.currency, 'INR'),
 	ta.name,
 	c.name,
+	cat.name,
 	COALESCE((SELECT SUM(td.amount) FROM transaction_documents td WHERE td.transaction_id = t.id), 0)
 	FROM transactions t
 	LEFT JOIN accounts a ON t.account_id = a.id
 	LEFT JOIN accounts ta ON t.transfer_account_id = ta.id
-	LEFT JOIN contacts c ON t.contact_id = c.id`
+	LEFT JOIN contacts c ON t.contact_id = c.id
+	LEFT JOIN categories cat ON t.category_id = cat.id`
 
 func scanTransaction(scanner interface{ Scan(...any) error }) (models.Transaction, error) {
 	var t models.Transaction
-	err := scanner.Scan(&t.ID, &t.AccountID, &t.Type, &t.Amount, &t.TransactionDate,
-		&t.Description, &t.Reference, &t.TransferAccountID, &t.ContactID,
-		&t.CreatedAt, &t.UpdatedAt,
-		&t.AccountName, &t.TransferAccountName, &t.ContactName, &t.Allocated)
+	if err := scanner.Scan(&t.ID, &t.AccountID, &t.Type, &t.Amount, &t.TransactionDate,
+		&t.Description, &t.Reference, &t.TransferAccountID, &t.ContactID, &t.CategoryID,
+		&t.CreatedAt, &t.UpdatedAt, &t.Reconciled, &t.ExternalID, &t.Source, &t.ExchangeRate, &t.Sign,
+		&t.Cleared, &t.ClearedDate, &t.Locked,
+		&t.AccountName, &t.Currency, &t.TransferAccountName, &t.ContactName, &t.CategoryName, &t.Allocated); err != nil {
+		return models.Transaction{}, err
+	}
 	t.Unallocated = models.Money(int64(t.Amount) - int64(t.Allocated))
-	return t, err
+	if t.Type == "offset" {
+		// An offset settles its amount on both a bill and an invoice.
+		t.Unallocated = models.Money(2*int64(t.Amount) - int64(t.Allocated))
+	}
+	return t, nil
 }
 
 func (s *Store) getTransactionByID(id int) (models.Transaction, error) {
@@ -36,7 +50,23 @@ func (s *Store) getTransactionByID(id int) (models.Transaction, error) {
 }
 
 // ListTransactions returns transactions filtered by the provided parameters (all may be empty).
-func (s *Store) ListTransactions(txnType, accountID, from, to string) ([]models.Transaction, error) {
+// reference must match exactly.
+func (s *Store) ListTransactions(txnType, accountID, contactID, categoryID, from, to, externalID, source, reference string) ([]models.Transaction, error) {
+	txns := []models.Transaction{}
+	err := s.EachTransaction(txnType, accountID, contactID, categoryID, from, to, externalID, source, reference, func(t models.Transaction) error {
+		txns = append(txns, t)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return txns, nil
+}
+
+// EachTransaction calls fn for each transaction matching the same filters as ListTransactions,
+// in the same order, without holding the whole result in memory. It stops at
+// the first error returned by fn.
+func (s *Store) EachTransaction(txnType, accountID, contactID, categoryID, from, to, externalID, source, reference string, fn func(models.Transaction) error) error {
 	query := txnSelectQuery
 	var conditions []string
 	var args []any
@@ -49,6 +79,14 @@ func (s *Store) ListTransactions(txnType, accountID, from, to string) ([]models.
 		conditions = append(conditions, "t.account_id = ?")
 		args = append(args, accountID)
 	}
+	if contactID != "" {
+		conditions = append(conditions, "t.contact_id = ?")
+		args = append(args, contactID)
+	}
+	if categoryID != "" {
+		conditions = append(conditions, "t.category_id = ?")
+		args = append(args, categoryID)
+	}
 	if from != "" {
 		conditions = append(conditions, "t.transaction_date >= ?")
 		args = append(args, from)
@@ -57,32 +95,146 @@ func (s *Store) ListTransactions(txnType, accountID, from, to string) ([]models.
 		conditions = append(conditions, "t.transaction_date <= ?")
 		args = append(args, to)
 	}
+	if externalID != "" {
+		conditions = append(conditions, "t.external_id = ?")
+		args = append(args, externalID)
+	}
+	if source != "" {
+		conditions = append(conditions, "t.source = ?")
+		args = append(args, source)
+	}
+	if reference != "" {
+		conditions = append(conditions, "t.reference = ?")
+		args = append(args, reference)
+	}
 	if len(conditions) > 0 {
 		query += " WHERE " + strings.Join(conditions, " AND ")
 	}
 	query += " ORDER BY t.created_at DESC"
 
 	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		t, err := scanTransaction(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(t); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// SuspenseTransaction is a transaction awaiting allocation, with its age.
+type SuspenseTransaction struct {
+	models.Transaction
+	AgeDays int `json:"age_days"` // days since the transaction date (or creation, when undated)
+}
+
+// ListSuspenseTransactions returns income and expense transactions with no
+// linked documents that are at least minAgeDays old on asOf, oldest first.
+// Transfer legs are excluded, as they settle against each other rather than
+// against documents. Undated transactions are aged from their creation.
+func (s *Store) ListSuspenseTransactions(asOf time.Time, minAgeDays int) ([]SuspenseTransaction, error) {
+	cutoff := asOf.AddDate(0, 0, -minAgeDays).Format("2006-01-02")
+	rows, err := s.db.Query(txnSelectQuery+` WHERE t.type IN ('income', 'expense') AND t.transfer_account_id IS NULL
+		AND NOT EXISTS (SELECT 1 FROM transaction_documents td WHERE td.transaction_id = t.id)
+		AND COALESCE(t.transaction_date, CAST(t.created_at AS DATE)) <= ?
+		ORDER BY COALESCE(t.transaction_date, CAST(t.created_at AS DATE)), t.id`, cutoff)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var txns []models.Transaction
+	today := time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, time.UTC)
+	txns := []SuspenseTransaction{}
 	for rows.Next() {
 		t, err := scanTransaction(rows)
 		if err != nil {
 			return nil, err
 		}
-		txns = append(txns, t)
+		from := t.TransactionDate.Time
+		if from.IsZero() {
+			from = t.CreatedAt.Time
+		}
+		from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+		txns = append(txns, SuspenseTransaction{Transaction: t, AgeDays: int(today.Sub(from).Hours() / 24)})
+	}
+	return txns, rows.Err()
+}
+
+// CalendarDay sums one day's transactions. Transfer legs are kept apart from
+// income and expense, so moving money between accounts does not show as
+// activity; adjustments are left out.
+type CalendarDay struct {
+	Date         models.Date  `json:"date"`
+	Count        int          `json:"count"` // income and expense transactions
+	Income       models.Money `json:"income"`
+	Expense      models.Money `json:"expense"`
+	Net          models.Money `json:"net"` // income less expense
+	Transfers    int          `json:"transfers"`
+	TransfersIn  models.Money `json:"transfers_in"`
+	TransfersOut models.Money `json:"transfers_out"`
+}
+
+// GetTransactionCalendar sums transactions per day over the month starting at
+// month, for one account or, when accountID is empty, for every account in
+// currency, so amounts in different currencies are never added together. It
+// returns every day of the month in order, including days without
+// transactions.
+func (s *Store) GetTransactionCalendar(month time.Time, accountID, currency string) ([]CalendarDay, error) {
+	first := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	next := first.AddDate(0, 1, 0)
+
+	where := "transaction_date >= ? AND transaction_date < ?"
+	args := []any{first.Format("2006-01-02"), next.Format("2006-01-02")}
+	if accountID != "" {
+		where += " AND account_id = ?"
+		args = append(args, accountID)
+	} else {
+		where += " AND account_id IN (SELECT id FROM accounts WHERE COALESCE(currency, 'INR') = ?)"
+		args = append(args, currency)
+	}
+	rows, err := s.db.Query(`SELECT transaction_date,
+		COALESCE(SUM(CASE WHEN type IN ('income', 'expense') AND transfer_account_id IS NULL THEN 1 ELSE 0 END), 0),
+		COALESCE(SUM(CASE WHEN type = 'income' AND transfer_account_id IS NULL THEN amount END), 0),
+		COALESCE(SUM(CASE WHEN type = 'expense' AND transfer_account_id IS NULL THEN amount END), 0),
+		COALESCE(SUM(CASE WHEN transfer_account_id IS NOT NULL THEN 1 ELSE 0 END), 0),
+		COALESCE(SUM(CASE WHEN type = 'income' AND transfer_account_id IS NOT NULL THEN amount END), 0),
+		COALESCE(SUM(CASE WHEN type = 'expense' AND transfer_account_id IS NOT NULL THEN amount END), 0)
+		FROM transactions WHERE `+where+` GROUP BY transaction_date`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byDate := map[string]CalendarDay{}
+	for rows.Next() {
+		var d CalendarDay
+		if err := rows.Scan(&d.Date, &d.Count, &d.Income, &d.Expense, &d.Transfers, &d.TransfersIn, &d.TransfersOut); err != nil {
+			return nil, err
+		}
+		d.Net = d.Income - d.Expense
+		byDate[d.Date.Format("2006-01-02")] = d
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
-	if txns == nil {
-		txns = []models.Transaction{}
+
+	days := make([]CalendarDay, 0, 31)
+	for day := first; day.Before(next); day = day.AddDate(0, 0, 1) {
+		d, ok := byDate[day.Format("2006-01-02")]
+		if !ok {
+			d = CalendarDay{Date: models.Date{Time: day}}
+		}
+		days = append(days, d)
 	}
-	return txns, nil
+	return days, nil
 }
 
 // GetTransaction returns a single transaction by ID. Returns sql.ErrNoRows if not found.
@@ -90,66 +242,237 @@ func (s *Store) GetTransaction(id int) (models.Transaction, error) {
 	return s.getTransactionByID(id)
 }
 
+// FindTransactionByExternalID returns the transaction on accountID with the
+// given external_id from source (empty for none). Returns sql.ErrNoRows if
+// there is none.
+func (s *Store) FindTransactionByExternalID(accountID int, source, externalID string) (models.Transaction, error) {
+	return findTransactionByExternalID(s.db, accountID, source, externalID)
+}
+
+func findTransactionByExternalID(q rowQuerier, accountID int, source, externalID string) (models.Transaction, error) {
+	return scanTransaction(q.QueryRow(txnSelectQuery+" WHERE t.account_id = ? AND COALESCE(t.source, '') = ? AND t.external_id = ? ORDER BY t.id LIMIT 1",
+		accountID, source, externalID))
+}
+
+// accountCurrency returns the currency of account id.
+func accountCurrency(q rowQuerier, id int) (string, error) {
+	var currency string
+	err := q.QueryRow("SELECT COALESCE(currency, 'INR') FROM accounts WHERE id = ?", id).Scan(&currency)
+	return currency, err
+}
+
 // CreateTransaction inserts a new transaction (handling transfer pairs) and returns the created record.
 func (s *Store) CreateTransaction(input models.TransactionInput) (models.Transaction, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return models.Transaction{}, err
+	}
+	defer tx.Rollback()
+
+	id, err := insertTransaction(tx, input)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return models.Transaction{}, err
+	}
+	return s.getTransactionByID(id)
+}
+
+// CreateExternalTransaction creates input unless its account already has a
+// transaction with the same source and external_id, which is returned instead
+// with created false. The lookup and insert run in one transaction so
+// concurrent re-syncs of a record create it only once. Without an external_id
+// it behaves like CreateTransaction.
+func (s *Store) CreateExternalTransaction(input models.TransactionInput) (t models.Transaction, created bool, err error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return models.Transaction{}, false, err
+	}
+	defer tx.Rollback()
+
+	if input.ExternalID != nil {
+		source := ""
+		if input.Source != nil {
+			source = *input.Source
+		}
+		existing, err := findTransactionByExternalID(tx, input.AccountID, source, *input.ExternalID)
+		if err == nil {
+			return existing, false, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return models.Transaction{}, false, err
+		}
+	}
+	id, err := insertTransaction(tx, input)
+	if err != nil {
+		return models.Transaction{}, false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return models.Transaction{}, false, err
+	}
+	t, err = s.getTransactionByID(id)
+	return t, true, err
+}
+
+// insertTransaction inserts the transaction CreateTransaction creates and
+// returns its id. A transfer inserts both legs, and any fee, and returns the
+// id of the source leg.
+func insertTransaction(tx *db.PortalTx, input models.TransactionInput) (int, error) {
 	if input.Type == "transfer" {
-		tx, err := s.db.Begin()
+		// Both legs carry the net amount, converted at the exchange rate on the
+		// destination leg; any fee is booked separately below.
+		from, err := accountCurrency(tx, input.AccountID)
 		if err != nil {
-			return models.Transaction{}, err
+			return 0, err
 		}
-		defer tx.Rollback()
-
-		ref := input.Reference
-		if ref == nil {
-			autoRef := fmt.Sprintf("TRF-%d", 0)
-			ref = &autoRef
+		to, err := accountCurrency(tx, *input.TransferAccountID)
+		if err != nil {
+			return 0, err
 		}
+		net, destNet := input.TransferAmounts(from, to)
 
-		var id1 int
-		err = tx.QueryRow(`INSERT INTO transactions (account_id, type, amount, transaction_date, description, reference, transfer_account_id, contact_id)
-			VALUES (?, 'expense', ?, ?, ?, ?, ?, ?) RETURNING id`,
-			input.AccountID, input.Amount, input.TransactionDate, input.Description, ref, input.TransferAccountID, input.ContactID).Scan(&id1)
+		id1, err := insertReturningID(tx, `INSERT INTO transactions (account_id, type, amount, transaction_date, description, reference, transfer_account_id, contact_id, exchange_rate, cleared, cleared_date)
+			VALUES (?, 'expense', ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			input.AccountID, net, input.TransactionDate, input.Description, input.Reference, input.TransferAccountID, input.ContactID, input.ExchangeRate,
+			input.IsCleared(), input.ClearedDate)
 		if err != nil {
-			return models.Transaction{}, err
+			return 0, err
 		}
 
-		_, err = tx.Exec(`INSERT INTO transactions (account_id, type, amount, transaction_date, description, reference, transfer_account_id, contact_id)
-			VALUES (?, 'income', ?, ?, ?, ?, ?, ?)`,
-			*input.TransferAccountID, input.Amount, input.TransactionDate, input.Description, ref, &input.AccountID, input.ContactID)
+		id2, err := insertReturningID(tx, `INSERT INTO transactions (account_id, type, amount, transaction_date, description, reference, transfer_account_id, contact_id, exchange_rate, cleared, cleared_date)
+			VALUES (?, 'income', ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			*input.TransferAccountID, destNet, input.TransactionDate, input.Description, input.Reference, &input.AccountID, input.ContactID, input.ExchangeRate,
+			input.IsCleared(), input.ClearedDate)
 		if err != nil {
-			return models.Transaction{}, err
+			return 0, err
 		}
 
-		if input.Reference == nil {
-			autoRef := fmt.Sprintf("TRF-%d", id1)
-			if _, err := tx.Exec("UPDATE transactions SET reference = ? WHERE reference = ?", autoRef, *ref); err != nil {
-				return models.Transaction{}, err
-			}
+		// Without a bank reference the legs share one derived from the first leg's id.
+		ref := fmt.Sprintf("TRF-%d", id1)
+		if input.Reference != nil {
+			ref = *input.Reference
+		} else if _, err := tx.Exec("UPDATE transactions SET reference = ?, updated_at = CURRENT_TIMESTAMP WHERE id IN (?, ?)", ref, id1, id2); err != nil {
+			return 0, err
 		}
 
-		if err := tx.Commit(); err != nil {
-			return models.Transaction{}, err
+		if input.FeeAmount > 0 {
+			_, err = tx.Exec(`INSERT INTO transactions (account_id, type, amount, transaction_date, description, reference)
+				VALUES (?, 'expense', ?, ?, ?, ?)`,
+				input.AccountID, input.FeeAmount, input.TransactionDate, "Bank charges: transfer fee", ref+"-FEE")
+			if err != nil {
+				return 0, err
+			}
 		}
-		return s.getTransactionByID(id1)
+		return id1, nil
 	}
 
-	var id int
-	err := s.db.QueryRow(`INSERT INTO transactions (account_id, type, amount, transaction_date, description, reference, transfer_account_id, contact_id)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?) RETURNING id`,
+	return insertReturningID(tx, `INSERT INTO transactions (account_id, type, amount, transaction_date, description, reference, transfer_account_id, contact_id, category_id, external_id, source, sign, cleared, cleared_date)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		input.AccountID, input.Type, input.Amount, input.TransactionDate,
-		input.Description, input.Reference, input.TransferAccountID, input.ContactID).Scan(&id)
-	if err != nil {
-		return models.Transaction{}, err
-	}
-	return s.getTransactionByID(id)
+		input.Description, input.Reference, input.TransferAccountID, input.ContactID, input.CategoryID, input.ExternalID, input.Source, input.Sign,
+		input.IsCleared(), input.ClearedDate)
 }
 
 // UpdateTransaction updates an existing transaction. Returns sql.ErrNoRows if not found.
+// A nil ExternalID, Source, Cleared, or ClearedDate keeps the stored value;
+// marking a transaction pending clears its cleared_date.
 func (s *Store) UpdateTransaction(id int, input models.TransactionInput) (models.Transaction, error) {
 	res, err := s.db.Exec(`UPDATE transactions SET account_id = ?, type = ?, amount = ?, transaction_date = ?,
-		description = ?, reference = ?, transfer_account_id = ?, contact_id = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
+		description = ?, reference = ?, transfer_account_id = ?, contact_id = ?, category_id = ?, sign = ?,
+		external_id = COALESCE(?, external_id), source = COALESCE(?, source),
+		cleared = COALESCE(?, cleared), cleared_date = CASE WHEN COALESCE(?, true) THEN COALESCE(?, cleared_date) END,
+		updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
 		input.AccountID, input.Type, input.Amount, input.TransactionDate,
-		input.Description, input.Reference, input.TransferAccountID, input.ContactID, id)
+		input.Description, input.Reference, input.TransferAccountID, input.ContactID, input.CategoryID, input.Sign, input.ExternalID, input.Source,
+		input.Cleared, input.Cleared, input.ClearedDate, id)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.Transaction{}, sql.ErrNoRows
+	}
+	return s.getTransactionByID(id)
+}
+
+// ReconcileResult reports the outcome of a reconcile-by-reference batch.
+type ReconcileResult struct {
+	Reconciled []int            `json:"reconciled"` // IDs of transactions marked reconciled
+	Unmatched  []string         `json:"unmatched"`  // references with no transaction
+	Ambiguous  map[string][]int `json:"ambiguous"`  // references matching several transactions
+}
+
+// ReconcileByReference marks the single transaction matching each reference as
+// reconciled, and locked when LockReconciled is set. References that match
+// nothing or more than one transaction are reported and left untouched.
+// accountID, when set, limits matching to that account.
+func (s *Store) ReconcileByReference(accountID *int, references []string) (ReconcileResult, error) {
+	result := ReconcileResult{Reconciled: []int{}, Unmatched: []string{}, Ambiguous: map[string][]int{}}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return result, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	seen := make(map[string]bool, len(references))
+	for _, ref := range references {
+		if seen[ref] {
+			continue
+		}
+		seen[ref] = true
+
+		query := "SELECT id FROM transactions WHERE reference = ?"
+		args := []any{ref}
+		if accountID != nil {
+			query += " AND account_id = ?"
+			args = append(args, *accountID)
+		}
+		rows, err := tx.Query(query+" ORDER BY id", args...)
+		if err != nil {
+			return result, err
+		}
+		var ids []int
+		for rows.Next() {
+			var id int
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return result, err
+			}
+			ids = append(ids, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return result, err
+		}
+
+		switch len(ids) {
+		case 0:
+			result.Unmatched = append(result.Unmatched, ref)
+		case 1:
+			if _, err := tx.Exec(reconcileUpdate, LockReconciled, ids[0]); err != nil {
+				return result, err
+			}
+			result.Reconciled = append(result.Reconciled, ids[0])
+		default:
+			result.Ambiguous[ref] = ids
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// reconcileUpdate marks the transaction with the id bound to its second
+// placeholder reconciled, locking it too when the first is true.
+const reconcileUpdate = "UPDATE transactions SET reconciled = true, locked = COALESCE(locked, false) OR ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?"
+
+// SetTransactionLocked locks or unlocks a transaction. Returns sql.ErrNoRows
+// if not found.
+func (s *Store) SetTransactionLocked(id int, locked bool) (models.Transaction, error) {
+	res, err := s.db.Exec("UPDATE transactions SET locked = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?", locked, id)
 	if err != nil {
 		return models.Transaction{}, err
 	}
@@ -167,10 +490,6 @@ func (s *Store) DeleteTransaction(id int) error {
 		return err
 	}
 
-	type docRef struct {
-		docType string
-		docID   int
-	}
 	var affected []docRef
 
 	rows, err := tx.Query("SELECT document_type, document_id FROM transaction_documents WHERE transaction_id = ?", id)
@@ -210,6 +529,10 @@ func (s *Store) DeleteTransaction(id int) error {
 		_ = tx.Rollback()
 		return sql.ErrNoRows
 	}
+	if err := recordDeletion(tx, "transaction", id); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
 
 	if err := tx.Commit(); err != nil {
 		return err
@@ -222,10 +545,11 @@ func (s *Store) DeleteTransaction(id int) error {
 	return nil
 }
 
-// ListTransactionLinks returns all document links for a transaction.
-func (s *Store) ListTransactionLinks(txnID int) ([]models.TransactionDocument, error) {
-	rows, err := s.db.Query(`SELECT id, transaction_id, document_type, document_id, amount, created_at
-		FROM transaction_documents WHERE transaction_id = ? ORDER BY created_at`, txnID)
+// ListTransactionLinks returns document links for a transaction, ordered and paginated by page.
+func (s *Store) ListTransactionLinks(txnID int, page LinkPage) ([]models.TransactionDocument, error) {
+	suffix, args := page.clause("td")
+	rows, err := s.db.Query(`SELECT td.id, td.transaction_id, td.document_type, td.document_id, td.amount, td.note, td.created_at
+		FROM transaction_documents td WHERE td.transaction_id = ?`+suffix, append([]any{txnID}, args...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -234,7 +558,7 @@ func (s *Store) ListTransactionLinks(txnID int) ([]models.TransactionDocument, e
 	var docs []models.TransactionDocument
 	for rows.Next() {
 		var td models.TransactionDocument
-		if err := rows.Scan(&td.ID, &td.TransactionID, &td.DocumentType, &td.DocumentID, &td.Amount, &td.CreatedAt); err != nil {
+		if err := rows.Scan(&td.ID, &td.TransactionID, &td.DocumentType, &td.DocumentID, &td.Amount, &td.Note, &td.CreatedAt); err != nil {
 			return nil, err
 		}
 		docs = append(docs, td)
@@ -250,6 +574,7 @@ func (s *Store) ListTransactionLinks(txnID int) ([]models.TransactionDocument, e
 
 // GetDocumentAmountAndAllocated returns the total amount and already-allocated amount of a document.
 // docType must be one of "bill", "invoice", "payout", or "recurring_payment_occurrence".
+// A payout's amount is its final payout amount less any recorded variance.
 // Returns sql.ErrNoRows if the document does not exist.
 func (s *Store) GetDocumentAmountAndAllocated(docType string, docID int) (amount, allocated models.Money, err error) {
 	var table, amountField string
@@ -259,7 +584,7 @@ func (s *Store) GetDocumentAmountAndAllocated(docType string, docID int) (amount
 	case "invoice":
 		table, amountField = "invoices", "amount"
 	case "payout":
-		table, amountField = "payouts", "final_payout_amt"
+		table, amountField = "payouts", "final_payout_amt - COALESCE(variance_amount, 0)"
 	case "recurring_payment_occurrence":
 		table, amountField = "recurring_payment_occurrences", "amount"
 	default:
@@ -279,16 +604,18 @@ func (s *Store) GetDocumentAmountAndAllocated(docType string, docID int) (amount
 
 // CreateTransactionLink creates a link between a transaction and a document and returns it.
 func (s *Store) CreateTransactionLink(txnID int, input models.TransactionDocumentInput) (models.TransactionDocument, error) {
-	var id int
-	err := s.db.QueryRow(`INSERT INTO transaction_documents (transaction_id, document_type, document_id, amount)
-		VALUES (?, ?, ?, ?) RETURNING id`, txnID, input.DocumentType, input.DocumentID, input.Amount).Scan(&id)
+	id, err := insertReturningID(s.db, `INSERT INTO transaction_documents (transaction_id, document_type, document_id, amount, note)
+		VALUES (?, ?, ?, ?, ?)`, txnID, input.DocumentType, input.DocumentID, input.Amount, input.Note)
 	if err != nil {
 		return models.TransactionDocument{}, err
 	}
+	if err := touchTransaction(s.db, txnID); err != nil {
+		return models.TransactionDocument{}, err
+	}
 
 	var td models.TransactionDocument
-	err = s.db.QueryRow("SELECT id, transaction_id, document_type, document_id, amount, created_at FROM transaction_documents WHERE id = ?", id).
-		Scan(&td.ID, &td.TransactionID, &td.DocumentType, &td.DocumentID, &td.Amount, &td.CreatedAt)
+	err = s.db.QueryRow("SELECT id, transaction_id, document_type, document_id, amount, note, created_at FROM transaction_documents WHERE id = ?", id).
+		Scan(&td.ID, &td.TransactionID, &td.DocumentType, &td.DocumentID, &td.Amount, &td.Note, &td.CreatedAt)
 	if err != nil {
 		return models.TransactionDocument{}, err
 	}
@@ -311,6 +638,9 @@ func (s *Store) DeleteTransactionLink(txnID, linkID int) error {
 	if n, _ := res.RowsAffected(); n == 0 {
 		return sql.ErrNoRows
 	}
+	if err := touchTransaction(s.db, txnID); err != nil {
+		return err
+	}
 
 	if docType != "" {
 		s.UpdateDocumentStatus(docType, docID)
@@ -318,8 +648,55 @@ func (s *Store) DeleteTransactionLink(txnID, linkID int) error {
 	return nil
 }
 
+// GetDocumentStatus returns the stored status of a bill, invoice, or recurring
+// payment occurrence. Payouts carry no status, so "" is returned for them.
+func (s *Store) GetDocumentStatus(docType string, docID int) (string, error) {
+	var table string
+	switch docType {
+	case "bill":
+		table = "bills"
+	case "invoice":
+		table = "invoices"
+	case "recurring_payment_occurrence":
+		table = "recurring_payment_occurrences"
+	default:
+		return "", nil
+	}
+	var status string
+	err := s.db.QueryRow(fmt.Sprintf("SELECT COALESCE(status, '') FROM %s WHERE id = ?", table), docID).Scan(&status)
+	return status, err
+}
+
+// derivedStatus returns the status a bill or invoice should have given its
+// amount and what has been allocated to it; fullStatus is the settled status
+// ("paid" for bills, "received" for invoices). Voided (cancelled) documents
+// keep their status, and so do unpaid ones already marked sent or overdue.
+func derivedStatus(current, fullStatus string, total, allocated models.Money) string {
+	switch {
+	case current == "cancelled":
+		return current
+	case total <= 0 || allocated <= 0:
+		if current == "sent" || current == "overdue" {
+			return current
+		}
+		return "draft"
+	case allocated+AllocationTolerance < total:
+		return "partial"
+	default:
+		return fullStatus
+	}
+}
+
+// docRef names a document whose links changed, for UpdateDocumentStatus to
+// refresh once the change is committed.
+type docRef struct {
+	docType string
+	docID   int
+}
+
 // UpdateDocumentStatus recalculates and updates the status field of a bill, invoice, or recurring_payment_occurrence
-// based on how much has been allocated via transaction_documents.
+// based on how much has been allocated via transaction_documents. Allocations within AllocationTolerance
+// of the total count as fully paid. A payout has no status; only its updated_at is bumped.
 func (s *Store) UpdateDocumentStatus(docType string, docID int) {
 	var total, allocated models.Money
 	var table, fullStatus, amountField string
@@ -333,6 +710,10 @@ func (s *Store) UpdateDocumentStatus(docType string, docID int) {
 		fullStatus = "received"
 		amountField = "amount"
 	case "payout":
+		// Payouts carry no status, but their allocation changed.
+		if _, err := s.db.Exec("UPDATE payouts SET updated_at = CURRENT_TIMESTAMP WHERE id = ?", docID); err != nil {
+			slog.Warn("UpdateDocumentStatus: failed to touch payout", "docID", docID, "error", err)
+		}
 		return
 	case "recurring_payment":
 		return
@@ -344,30 +725,29 @@ func (s *Store) UpdateDocumentStatus(docType string, docID int) {
 		return
 	}
 
-	err := s.db.QueryRow(fmt.Sprintf("SELECT %s, (SELECT COALESCE(SUM(amount), 0) FROM transaction_documents WHERE document_type = ? AND document_id = ?) FROM %s WHERE id = ?", amountField, table),
-		docType, docID, docID).Scan(&total, &allocated)
+	var current string
+	err := s.db.QueryRow(fmt.Sprintf("SELECT %s, COALESCE(status, ''), (SELECT COALESCE(SUM(amount), 0) FROM transaction_documents WHERE document_type = ? AND document_id = ?) FROM %s WHERE id = ?", amountField, table),
+		docType, docID, docID).Scan(&total, &current, &allocated)
 	if err != nil {
 		return
 	}
 
 	var newStatus string
 	if docType == "recurring_payment_occurrence" {
-		if allocated >= total && total > 0 {
+		if allocated+AllocationTolerance >= total && total > 0 {
 			newStatus = "paid"
 		} else {
 			newStatus = "pending"
 		}
 	} else {
-		if total <= 0 || allocated <= 0 {
-			newStatus = "draft"
-		} else if allocated < total {
-			newStatus = "partial"
-		} else {
-			newStatus = fullStatus
-		}
+		newStatus = derivedStatus(current, fullStatus, total, allocated)
 	}
 
 	if _, err := s.db.Exec(fmt.Sprintf("UPDATE %s SET status = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?", table), newStatus, docID); err != nil {
 		slog.Warn("UpdateDocumentStatus: failed to update status", "docType", docType, "docID", docID, "error", err)
 	}
+	// Every link write ends here, so this also keeps contact balances current.
+	if docType == "bill" || docType == "invoice" {
+		s.refreshDocumentContact(table, docID)
+	}
 }
@@ -0,0 +1,104 @@
+package store
+
+import (
+	"fmt"
+
+	"github.com/satheeshds/portal/models"
+)
+
+// BalanceIssue is a transaction that the stored balance may not account for
+// correctly.
+type BalanceIssue struct {
+	TransactionID int    `json:"transaction_id"`
+	Problem       string `json:"problem"`
+}
+
+// AccountVerification compares an account's balance as displayed with one
+// recomputed transaction by transaction.
+type AccountVerification struct {
+	AccountID        int            `json:"account_id"`
+	OpeningBalance   models.Money   `json:"opening_balance"`
+	DisplayedBalance models.Money   `json:"displayed_balance"` // Balance as returned by GET /accounts/{id}
+	ComputedBalance  models.Money   `json:"computed_balance"`  // opening balance plus TransactionSum
+	Discrepancy      models.Money   `json:"discrepancy"`       // displayed minus computed
+	Consistent       bool           `json:"consistent"`        // no discrepancy and no issues
+	TransactionCount int            `json:"transaction_count"` // transactions posted on the account
+	TransactionSum   models.Money   `json:"transaction_sum"`   // their net effect on the balance
+	Issues           []BalanceIssue `json:"issues"`
+}
+
+// VerifyAccountBalance recomputes the balance of account id from its opening
+// balance and each of its transactions, independently of the aggregate query
+// behind the displayed balance, and flags transactions that cannot be counted
+// (unknown types, adjustments without a sign) and transfer legs whose
+// counterpart leg on the other account is missing. Returns sql.ErrNoRows if
+// the account does not exist.
+func (s *Store) VerifyAccountBalance(id int) (AccountVerification, error) {
+	a, err := s.GetAccount(id)
+	if err != nil {
+		return AccountVerification{}, err
+	}
+	v := AccountVerification{AccountID: id, OpeningBalance: a.OpeningBalance, DisplayedBalance: a.Balance, Issues: []BalanceIssue{}}
+
+	rows, err := s.db.Query(`SELECT id, type, amount, sign FROM transactions WHERE account_id = ? ORDER BY id`, id)
+	if err != nil {
+		return v, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var txnID int
+		var typ string
+		var amount models.Money
+		var sign *int
+		if err := rows.Scan(&txnID, &typ, &amount, &sign); err != nil {
+			return v, err
+		}
+		v.TransactionCount++
+		switch typ {
+		case "income":
+			v.TransactionSum += amount
+		case "expense":
+			v.TransactionSum -= amount
+		case "adjustment":
+			if sign == nil || (*sign != 1 && *sign != -1) {
+				v.Issues = append(v.Issues, BalanceIssue{TransactionID: txnID, Problem: "adjustment has no sign of 1 or -1"})
+				continue
+			}
+			v.TransactionSum += amount * models.Money(*sign)
+		default:
+			v.Issues = append(v.Issues, BalanceIssue{TransactionID: txnID, Problem: fmt.Sprintf("unknown type %q", typ)})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return v, err
+	}
+
+	// A transfer is an expense leg on the source and an income leg on the
+	// destination, each naming the other account.
+	legs, err := s.db.Query(`SELECT t.id FROM transactions t
+		WHERE t.account_id = ? AND t.transfer_account_id IS NOT NULL AND t.type IN ('income', 'expense')
+		AND NOT EXISTS (SELECT 1 FROM transactions o WHERE o.id <> t.id
+			AND o.account_id = t.transfer_account_id AND o.transfer_account_id = t.account_id
+			AND o.type IN ('income', 'expense') AND o.type <> t.type
+			AND o.transaction_date IS NOT DISTINCT FROM t.transaction_date)
+		ORDER BY t.id`, id)
+	if err != nil {
+		return v, err
+	}
+	defer legs.Close()
+	for legs.Next() {
+		var txnID int
+		if err := legs.Scan(&txnID); err != nil {
+			return v, err
+		}
+		v.Issues = append(v.Issues, BalanceIssue{TransactionID: txnID, Problem: "transfer leg has no matching leg on the other account"})
+	}
+	if err := legs.Err(); err != nil {
+		return v, err
+	}
+
+	v.ComputedBalance = v.OpeningBalance + v.TransactionSum
+	v.Discrepancy = v.DisplayedBalance - v.ComputedBalance
+	v.Consistent = v.Discrepancy == 0 && len(v.Issues) == 0
+	return v, nil
+}